@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"rockets/internal/bench"
+)
+
+// runBench runs the throughput benchmark against the in-process service and prints its summary.
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	opts := bench.Options{}
+	fs.IntVar(&opts.Concurrency, "concurrency", 8, "Number of parallel producers")
+	fs.IntVar(&opts.Rockets, "rockets", 1000, "Number of rockets")
+	fs.IntVar(&opts.Messages, "messages", 100, "Messages per rocket")
+	fs.StringVar(&opts.Store, "store", bench.StoreMemory, "Store backend: memory or file")
+	fs.DurationVar(&opts.CommitWindow, "commit-window", 0, "Group commit window of the file store, 0 writes every update")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	summary, err := bench.Run(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	summary.Print(os.Stdout)
+	return nil
+}
@@ -2,13 +2,55 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"github.com/rs/zerolog/log"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"golang.org/x/sync/errgroup"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"rockets/internal/auth"
+	"rockets/internal/buildinfo"
+	"rockets/internal/capture"
+	"rockets/internal/cdc"
+	"rockets/internal/config"
+	"rockets/internal/consumer"
+	"rockets/internal/export"
+	"rockets/internal/fleet"
+	"rockets/internal/health"
 	"rockets/internal/http"
+	"rockets/internal/incident"
+	"rockets/internal/kube"
+	"rockets/internal/latency"
+	"rockets/internal/leader"
+	"rockets/internal/logging"
+	"rockets/internal/maintenance"
+	"rockets/internal/metrics"
+	"rockets/internal/names"
+	"rockets/internal/netacl"
+	"rockets/internal/notify"
+	"rockets/internal/otlp"
+	"rockets/internal/partition"
+	"rockets/internal/report"
+	"rockets/internal/retransmit"
+	"rockets/internal/retry"
 	"rockets/internal/rocket"
+	"rockets/internal/secrets"
+	"rockets/internal/systemd"
+	"rockets/internal/tracing"
+	"rockets/internal/tsdb"
+	"rockets/internal/ui"
+	"rockets/internal/usage"
+	"rockets/internal/warehouse"
+	"rockets/internal/watchdog"
+	"rockets/internal/watchlist"
+	"strconv"
+	"syscall"
+	"time"
 )
 
 // run initializes the HTTP server and starts listening for requests.
@@ -16,41 +58,728 @@ func run() error {
 	portPtr := flag.Int("port", 8088, "HTTP Server Port")
 	flag.Parse()
 
-	ctx := context.Background()
-	echo := http.NewEcho()
-	logger, err := zap.NewProduction()
+	resolver := secrets.FromEnv()
+	cfg, err := config.Load(resolver)
 	if err != nil {
 		return err
 	}
 
-	// Initialize the Rocket service with an in-memory store
+	// Stop gracefully on SIGTERM (systemd, Kubernetes) and Ctrl+C, so the lease is released, the pending writes of
+	// the store are synced and the listeners are closed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	levels, err := logging.NewLevels(cfg.Log.Level)
+	if err != nil {
+		return err
+	}
+	for component, level := range cfg.Log.Levels {
+		levels.SetLevel(component, level)
+	}
+	logger, err := logging.New(levels, logging.Format(cfg.Log.Format), logging.Sampling{
+		Initial:    cfg.Log.SamplingInitial,
+		Thereafter: cfg.Log.SamplingThereafter,
+	})
+	if err != nil {
+		return err
+	}
+	// Export the service's own logs, metrics and traces to an OpenTelemetry collector
+	var exporter *otlp.Exporter
+	var tracer *tracing.Tracer
+	if cfg.OTLP.Endpoint != "" {
+		exporter = otlp.NewExporter(cfg.OTLP)
+		if cfg.OTLP.Logs {
+			logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+				// the component levels apply to the exported entries as well
+				return logging.Tee(core, exporter.Core(zapcore.DebugLevel))
+			}))
+		}
+		if cfg.OTLP.Traces {
+			tracer = tracing.NewTracer(exporter)
+		}
+	}
+	defer func() { _ = logger.Sync() }()
+	build := buildinfo.Get()
+	logger = logger.With(zap.String("version", build.Version))
+	logger.Info("Starting service", zap.String("commit", build.Commit), zap.Time("build_time", build.BuildTime), zap.String("go_version", build.GoVersion))
+	httpLogger := logger.Named(logging.ComponentHTTP)
+	storeLogger := logger.Named(logging.ComponentStore)
+	registry := metrics.NewRegistry()
+	registry.Gauge("rockets_build_info", "Build of the running instance, always 1.", "version", "commit", "go_version").
+		Set(1, build.Version, build.Commit, build.GoVersion)
+	retrier := retry.New(retry.Policy{
+		MaxAttempts:    cfg.Retry.MaxAttempts,
+		InitialBackoff: cfg.Retry.InitialBackoff,
+		MaxBackoff:     cfg.Retry.MaxBackoff,
+		Jitter:         cfg.Retry.Jitter,
+	}, registry)
+	if exporter != nil {
+		exporter.UseRetry(retrier)
+	}
+	var notifier notify.Notifier
+	if cfg.Alerts.WebhookURL != "" {
+		webhook := notify.NewWebhook(cfg.Alerts.WebhookURL)
+		webhook.UseRetry(retrier)
+		notifier = webhook
+	}
+	echo := http.NewEcho(httpLogger, registry, notifier)
+
+	// Alerts about rockets (explosions and lost rockets) also page through PagerDuty and Opsgenie
+	rocketNotifier := notifier
+	var pagers notify.Multi
+	if cfg.Alerts.PagerDutyRoutingKey != "" {
+		pagerDuty := notify.NewPagerDuty(cfg.Alerts.PagerDutyURL, cfg.Alerts.PagerDutyRoutingKey)
+		pagerDuty.UseRetry(retrier)
+		pagers = append(pagers, pagerDuty)
+	}
+	if cfg.Alerts.OpsgenieAPIKey != "" {
+		opsgenie := notify.NewOpsgenie(cfg.Alerts.OpsgenieURL, cfg.Alerts.OpsgenieAPIKey)
+		opsgenie.UseRetry(retrier)
+		pagers = append(pagers, opsgenie)
+	}
+	if len(pagers) > 0 {
+		if notifier != nil {
+			pagers = append(notify.Multi{notifier}, pagers...)
+		}
+		rocketNotifier = pagers
+	}
+
+	// Rocket alerts are suppressed during the maintenance windows of their missions and channels
+	windows := maintenance.NewRegistry()
+	if cfg.Store.MaintenanceFile != "" {
+		if windows, err = maintenance.Open(cfg.Store.MaintenanceFile); err != nil {
+			return err
+		}
+	}
+	suppressor := maintenance.NewSuppressor(windows, rocketNotifier, logger.Named(logging.ComponentRocket))
+
+	acl, err := netacl.New(map[netacl.Group][]string{
+		netacl.GroupIngest: cfg.Network.AllowIngest,
+		netacl.GroupAPI:    cfg.Network.AllowAPI,
+		netacl.GroupAdmin:  cfg.Network.AllowAdmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := auth.NewKeys(cfg.Auth.APIKeys)
+	for producer, v := range cfg.Auth.Scopes {
+		scope, err := auth.ParseScope(v)
+		if err != nil {
+			return fmt.Errorf("invalid ROCKETS_API_SCOPES scope of %s: %w", producer, err)
+		}
+		if !keys.Restrict(producer, scope) {
+			return fmt.Errorf("invalid ROCKETS_API_SCOPES: producer %s has no API key", producer)
+		}
+	}
+	var signingKeys *auth.SigningKeys
+	if len(cfg.Auth.SigningKeys) > 0 {
+		signingKeys, err = auth.ParseSigningKeys(cfg.Auth.SigningKeys)
+		if err != nil {
+			return fmt.Errorf("invalid ROCKETS_SIGNING_KEYS: %w", err)
+		}
+	}
+
+	// Ingest only the channels of the owned partitions
+	var partitions partition.Owner
+	if cfg.Partition.Static != nil {
+		partitions = cfg.Partition.Static
+	}
+	// or spread them over the live replicas
+	var (
+		membership    *partition.Membership
+		handoffSecret []byte
+	)
+	if cfg.Partition.Ring != "" {
+		api, pod, err := kube.InCluster()
+		if err != nil {
+			return err
+		}
+		ring := partition.NewRing(partition.Member{ID: pod.Name, URL: cfg.Partition.URL})
+		membership = partition.NewMembership(partition.MembershipConfig{
+			API:       api,
+			Namespace: pod.Namespace,
+			Ring:      cfg.Partition.Ring,
+			Duration:  cfg.Partition.RingDuration,
+		}, ring, logger.Named(logging.ComponentPartition))
+		if err := membership.Sync(ctx); err != nil {
+			logger.Warn("Can't join the ring, ingesting every channel until the next update", zap.Error(err))
+		}
+		partitions = ring
+		handoffSecret = []byte(cfg.Partition.Secret)
+	}
+
+	var channels []uuid.UUID
+	for _, s := range cfg.Capture.Channels {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid ROCKETS_CAPTURE_CHANNELS item %q: %w", s, err)
+		}
+		channels = append(channels, id)
+	}
+
+	// Compete for leadership with a standby instance
+	var elector *leader.Elector
+	if cfg.Leader.LockFile != "" {
+		elector = leader.New(cfg.Leader.LockFile, cfg.Leader.Retry, logger.Named(logging.ComponentLeader))
+	}
+	// Run the singleton background jobs on one replica of a Kubernetes deployment
+	var lease *leader.Lease
+	if cfg.Leader.Lease != "" {
+		if lease, err = leader.InClusterLease(cfg.Leader.Lease, cfg.Leader.LeaseNamespace, cfg.Leader.LeaseDuration, logger.Named(logging.ComponentLeader)); err != nil {
+			return err
+		}
+	}
+
+	// Rockets of long-duration missions kept from the eviction of quiet ones
+	var pinnedChannels []uuid.UUID
+	for _, s := range cfg.History.PinnedChannels {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid ROCKETS_PINNED_CHANNELS item %q: %w", s, err)
+		}
+		pinnedChannels = append(pinnedChannels, id)
+	}
+	var pinnedMissions []rocket.Mission
+	for _, s := range cfg.History.PinnedMissions {
+		pinnedMissions = append(pinnedMissions, rocket.Mission(s))
+	}
+	pins := rocket.NewPins(pinnedChannels, pinnedMissions, time.Now())
+	if cfg.Store.PinsFile != "" {
+		if pins, err = rocket.OpenPins(cfg.Store.PinsFile, pinnedChannels, pinnedMissions, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	// Rockets registered ahead of their telemetry
+	registrations := rocket.NewRegistrations()
+	if cfg.Store.RegistrationsFile != "" {
+		if registrations, err = rocket.OpenRegistrations(cfg.Store.RegistrationsFile); err != nil {
+			return err
+		}
+	}
+
+	// Incidents opened by explosions and handled by the operators
+	incidents := incident.NewRegistry(logger.Named(logging.ComponentIncident))
+	if cfg.Store.IncidentsFile != "" {
+		if incidents, err = incident.Open(cfg.Store.IncidentsFile, logger.Named(logging.ComponentIncident)); err != nil {
+			return err
+		}
+	}
+	incidents.UseNotifier(suppressor)
+
+	// Alert the rockets going silent in flight
+	var lostRockets *watchdog.Watchdog
+	if cfg.Alerts.LostAfter > 0 {
+		lostRockets = watchdog.NewWatchdog(cfg.Alerts.LostAfter, pins, suppressor, logger.Named(logging.ComponentRocket))
+		if partitions != nil {
+			lostRockets.UseOwner(partitions.Owns)
+		}
+	}
+
+	// Initialize the Rocket service with an in-memory or file-backed store
 	var rocketSvc rocket.Service
+	var serviceImpl *rocket.ServiceImpl
+	var fileStore *rocket.FileRocketStore
+	var warmUp func()
+	var hotHistory = rocket.NewInMemoryHistoryStore()
+	var history rocket.HistoryStore = hotHistory
+	var tieredHistory *rocket.TieredHistoryStore
+	var collector = report.NewCollector()
+	var feed = report.NewFeed(50)
+	var rates = rocket.NewMessageRates()
+	// Measure how far ingestion lags behind the producers
+	var latencies = latency.NewTracker(cfg.Ingest.LatencyWindow, logger.Named(logging.ComponentRocket))
+	latencies.UseBudget(cfg.Ingest.LatencyBudget, notifier)
+	latencies.UseMetrics(registry)
+	// Publish the state changes as Debezium change events
+	var changes *cdc.Publisher
+	if cfg.CDC.URL != "" {
+		changes = cdc.NewPublisher(cfg.CDC, logger.Named(logging.ComponentCDC))
+		changes.UseRetry(retrier)
+	}
+	// Load the applied events into the warehouse
+	var sink *warehouse.Sink
+	if cfg.Warehouse.Project != "" {
+		bigQuery := warehouse.NewBigQuery(cfg.Warehouse)
+		bigQuery.UseRetry(retrier)
+		sink = warehouse.NewSink(bigQuery, cfg.Warehouse.BatchSize, logger.Named(logging.ComponentWarehouse))
+	}
+	// Keep the recent history in memory and move the older events to the cold tier
+	if cfg.History.ColdDir != "" {
+		cold, err := rocket.OpenDirColdHistory(cfg.History.ColdDir)
+		if err != nil {
+			return err
+		}
+		tieredHistory = rocket.NewTieredHistoryStore(hotHistory, cold, cfg.History.HotAge, logger.Named(logging.ComponentRocket))
+		tieredHistory.UsePins(pins)
+		history = tieredHistory
+	}
 	{
-		var store = rocket.NewInMemoryRocketStore(logger)
-		rocketSvc = rocket.NewRocketService(store, logger)
+		memStore := rocket.NewInMemoryRocketStore(storeLogger)
+		memStore.LogWrites(cfg.Log.StoreWrites)
+		var store rocket.Store = memStore
+		if cfg.Store.File != "" {
+			// a standby must not compact the file the leader appends to
+			open := rocket.OpenFileRocketStore
+			if elector != nil {
+				open = rocket.OpenStandbyFileRocketStore
+			}
+			fileStore, err = open(cfg.Store.File, cfg.Store.Cipher, storeLogger)
+			if err != nil {
+				return err
+			}
+			fileStore.LogWrites(cfg.Log.StoreWrites)
+			if cfg.Store.CommitWindow > 0 {
+				fileStore.UseGroupCommit(cfg.Store.CommitWindow, cfg.Store.CommitWait)
+			}
+			defer func() {
+				if err := fileStore.Close(); err != nil {
+					logger.Error("Can't close store", zap.Error(err))
+				}
+			}()
+			store = fileStore
+		}
+		svc := rocket.NewRocketService(store, logger.Named(logging.ComponentRocket))
+		svc.UseHistory(history)
+		if cfg.History.Heartbeats {
+			svc.UseHeartbeatHistory()
+		}
+		svc.AutoQuarantine(cfg.Ingest.QuarantineAfterFailures)
+		svc.UseDebugTraces(cfg.Ingest.DebugTraceMax, cfg.Ingest.DebugTraceTTL)
+		svc.UseDeadLetters(cfg.Ingest.DeadLetters)
+		svc.UseRegistrations(registrations)
+		svc.UsePins(pins)
+		if partitions != nil {
+			svc.UseOwner(partitions.Owns)
+		}
+		if cfg.Ingest.ReorderWindow > 0 {
+			svc.UseReorderBuffer(cfg.Ingest.ReorderWindow, cfg.Ingest.ReorderMaxWait)
+		}
+		if cfg.Ingest.Actors {
+			svc.UseActors(cfg.Ingest.ActorMailbox, cfg.Ingest.ActorIdleTimeout)
+		}
+		svc.UseUnderflowPolicy(cfg.Ingest.SpeedUnderflow)
+		dedupPolicy := rocket.DedupPolicy(cfg.Ingest.DedupPolicy)
+		if err := dedupPolicy.Validate(); err != nil {
+			return fmt.Errorf("invalid ROCKETS_DEDUP_POLICY: %w", err)
+		}
+		dedupRejections := registry.Counter("rockets_dedup_rejections_total", "Messages not applied by the dedup policy, by class: stale, duplicate, gap or conflict.", "policy", "class")
+		svc.UseDedupPolicy(dedupPolicy, cfg.Ingest.DedupWindow, func(class rocket.DedupClass) {
+			dedupRejections.Inc(string(dedupPolicy), string(class))
+		})
+		if cfg.Ingest.ProvisionalState {
+			svc.UseProvisionalState()
+		}
+		svc.UseTracer(tracer)
+		if len(cfg.Ingest.UnnumberedChannels) > 0 || len(cfg.Ingest.UnnumberedProducers) > 0 {
+			svc.UseUnnumbered(rocket.NewUnnumbered(cfg.Ingest.UnnumberedChannels, cfg.Ingest.UnnumberedProducers))
+		}
+		if cfg.Ingest.RetransmitURL != "" {
+			webhook := retransmit.NewWebhook(cfg.Ingest.RetransmitURL)
+			webhook.UseRetry(retrier)
+			svc.UseRetransmission(webhook, cfg.Ingest.RetransmitAfter)
+		}
+		svc.AddListener(feed)
+		svc.UseMessageRates(rates)
+		svc.AddListener(latencies)
+		svc.AddListener(incidents)
+		if lostRockets != nil {
+			svc.AddListener(lostRockets)
+		}
+		if cfg.Ingest.Shadow {
+			divergences := registry.Counter("rockets_shadow_divergences_total", "State transitions of the shadow state machine differing from the service.", "msg_type", "field")
+			svc.AddListener(rocket.NewShadow(
+				rocket.NewProcessor(cfg.Ingest.ShadowSpeedUnderflow, cfg.Ingest.ShadowProvisionalState),
+				logger.Named(logging.ComponentRocket).Named("shadow"),
+				func(d rocket.Divergence) {
+					if d.Panic != "" {
+						divergences.Inc(string(d.MessageType), "panic")
+					}
+					for _, f := range d.Fields {
+						divergences.Inc(string(d.MessageType), string(f))
+					}
+				},
+			))
+		}
+		if cfg.Reports.Enabled {
+			svc.AddListener(collector)
+		}
+		if sink != nil {
+			svc.AddListener(sink)
+		}
+		if changes != nil {
+			svc.AddListener(changes)
+		}
+
+		// Verify the persisted states before accepting messages
+		violations := registry.Counter("rockets_consistency_violations_total", "Inconsistent rocket states found.", "check", "repaired")
+		warmUp = func() {
+			for _, v := range svc.WarmUp(ctx, cfg.Store.Repair).Violations {
+				violations.Inc(v.Check, strconv.FormatBool(v.Repaired))
+			}
+		}
+		if fileStore != nil && elector == nil {
+			warmUp()
+		}
+		rocketSvc = svc
+		serviceImpl = svc
+	}
+
+	rocketNames := names.NewRegistry()
+	if cfg.Store.NamesFile != "" {
+		if rocketNames, err = names.Open(cfg.Store.NamesFile); err != nil {
+			return err
+		}
+	}
+
+	fleets := fleet.NewRegistry()
+	if cfg.Store.FleetsFile != "" {
+		if fleets, err = fleet.Open(cfg.Store.FleetsFile); err != nil {
+			return err
+		}
+	}
+
+	watchlists := watchlist.NewRegistry()
+	if cfg.Store.WatchlistsFile != "" {
+		if watchlists, err = watchlist.Open(cfg.Store.WatchlistsFile); err != nil {
+			return err
+		}
+	}
+
+	// Export the event history to Parquet files for analytics
+	var historyExport *export.Exporter
+	if cfg.Export.Dir != "" {
+		historyExport = export.NewExporter(rocketSvc, history, cfg.Export.Dir, logger.Named(logging.ComponentExport))
+	}
+
+	// Serve the listings of hot dashboards from a snapshot
+	var snapshot *rocket.Snapshot
+	if cfg.Listing.MaxStaleness > 0 {
+		snapshot = rocket.NewSnapshot(rocketSvc, cfg.Listing.MaxStaleness, logger.Named(logging.ComponentRocket))
+	}
+
+	collation, err := rocket.NewCollation(cfg.Sort.Locale, cfg.Sort.CaseSensitive)
+	if err != nil {
+		return fmt.Errorf("invalid ROCKETS_SORT_LOCALE: %w", err)
 	}
 
 	opts := http.ServerOpts{
-		Echo:   echo,
-		Rocket: rocketSvc,
+		Echo:        echo,
+		Logger:      httpLogger,
+		Rocket:      rocketSvc,
+		Missions:    report.NewMissionReporter(rocketSvc, history),
+		History:     history,
+		Feed:        feed,
+		Keys:        keys,
+		SigningKeys: signingKeys,
+		Usage: usage.NewMeter(usage.Quota{
+			Messages: cfg.Quota.DailyMessages,
+			Bytes:    cfg.Quota.DailyBytes,
+		}),
+		ACL:     acl,
+		Capture: capture.NewRecorder(cfg.Capture.Size, cfg.Capture.SampleRate, channels),
+		Metrics: registry,
+		Leader:  elector,
+		Tracer:  tracer,
+		Levels:  levels,
+		Names:   rocketNames,
+		Fleets:  fleets,
+		Export:  historyExport,
+		Sink:    sink,
+
+		Snapshot:      snapshot,
+		Incidents:     incidents,
+		Watchlists:    watchlists,
+		Maintenance:   windows,
+		Suppressor:    suppressor,
+		Registrations: registrations,
+		Pins:          pins,
+		Latency:       latencies,
+		Partitions:    partitions,
+		HandoffSecret: handoffSecret,
+		Cache:         http.NewMicroCache(cfg.Cache.TTL, cfg.Cache.MaxAge),
+		Collation:     collation,
+		Rates:         rates,
+		NoisyRate:     cfg.Ingest.NoisyRate,
+		AuthReads:     cfg.Auth.Reads,
+		Public:        cfg.Auth.Public,
+		Redact:        cfg.Auth.PublicRedact,
+		BasePath:      cfg.Listen.BasePath,
+		MaxBodyBytes:  cfg.Ingest.MaxBodyBytes,
+	}
+	if cfg.UI.Enabled {
+		opts.Dashboard = ui.Assets()
 	}
 	_, e := http.NewServer(&opts)
+
+	// Serve the sockets passed by systemd socket activation, otherwise open the configured listeners before
+	// starting, so a busy address fails the startup
+	listeners, err := systemd.Listeners()
+	if err != nil {
+		return err
+	}
+	if len(listeners) > 0 {
+		logger.Info("Using sockets passed by systemd", zap.Int("sockets", len(listeners)))
+	} else {
+		addrs := cfg.Listen.Addrs
+		if len(addrs) == 0 {
+			addrs = []string{fmt.Sprintf(":%d", *portPtr)}
+		}
+		mode, _ := strconv.ParseUint(cfg.Listen.UnixSocketMode, 8, 9) // validated by config
+		listeners, err = http.Listen(http.Listeners{
+			Addrs:          addrs,
+			UnixSocket:     cfg.Listen.UnixSocket,
+			UnixSocketMode: fs.FileMode(mode),
+		})
+		if err != nil {
+			return err
+		}
+	}
 	g, ctx := errgroup.WithContext(ctx)
 
-	// Start the HTTP server
-	g.Go(http.ListenEchoServer(ctx, echo, fmt.Sprintf(":%d", *portPtr)))
-	g.Go(http.ShutDownEchoServer(ctx, e))
+	// singleton runs the job on the replica holding the lease, on every replica without one
+	singleton := func(job func(context.Context) error) func() error {
+		if lease == nil {
+			return func() error { return job(ctx) }
+		}
+		return func() error { return lease.RunSingleton(ctx, job) }
+	}
+	if lease != nil {
+		g.Go(func() error {
+			return lease.Run(ctx)
+		})
+	}
+	if membership != nil {
+		if serviceImpl != nil {
+			membership.UseHandoff(partition.NewHandoff(serviceImpl, handoffSecret, logger.Named(logging.ComponentPartition)))
+		}
+		g.Go(func() error {
+			return membership.Run(ctx)
+		})
+	}
+
+	// Keep the Vault token and the leases of the secrets alive
+	if cfg.Secrets.RenewInterval > 0 {
+		resolver.UseLogger(logger)
+		g.Go(func() error {
+			return resolver.Run(ctx, cfg.Secrets.RenewInterval)
+		})
+	}
+
+	// Watch the IP denylist
+	if cfg.Network.DenylistFile != "" {
+		g.Go(func() error {
+			return acl.WatchDenylist(ctx, cfg.Network.DenylistFile, cfg.Network.DenylistReload, httpLogger)
+		})
+	}
+
+	// Take over the store file once elected, refresh it and the registries while in standby
+	if elector != nil {
+		registries := []struct {
+			what     string
+			registry interface{ Refresh() error }
+		}{
+			{"names", rocketNames},
+			{"fleets", fleets},
+			{"watchlists", watchlists},
+			{"registrations", registrations},
+			{"maintenance windows", windows},
+			{"incidents", incidents},
+			{"pins", pins},
+		}
+		refreshRegistries := func() {
+			for _, r := range registries {
+				if err := r.registry.Refresh(); err != nil {
+					logger.Error("Can't refresh standby registry", zap.String("registry", r.what), zap.Error(err))
+				}
+			}
+		}
+		g.Go(func() error {
+			return elector.Run(ctx, func() error {
+				// changes saved by the previous leader since the last refresh
+				refreshRegistries()
+				if fileStore == nil {
+					return nil
+				}
+				if err := fileStore.Promote(); err != nil {
+					return err
+				}
+				warmUp()
+				return nil
+			}, func() {
+				refreshRegistries()
+				if fileStore == nil {
+					return
+				}
+				if err := fileStore.Refresh(); err != nil {
+					logger.Error("Can't refresh standby store", zap.Error(err))
+				}
+			})
+		})
+	}
+
+	// Skip gaps in message sequences that stayed open for too long
+	if cfg.Ingest.ReorderWindow > 0 {
+		g.Go(func() error {
+			return serviceImpl.RunReorderJanitor(ctx, cfg.Ingest.ReorderMaxWait/2)
+		})
+	}
+
+	// Report the channels with missing messages
+	if cfg.Ingest.GapReportInterval > 0 {
+		g.Go(func() error {
+			return serviceImpl.RunGapReport(ctx, cfg.Ingest.GapReportInterval, cfg.Ingest.GapRetention)
+		})
+	}
+
+	// Save the incidents opened by explosions
+	g.Go(func() error {
+		return incidents.Run(ctx)
+	})
+
+	// Check the ingestion latency against the budget
+	g.Go(func() error {
+		return latencies.Run(ctx, cfg.Ingest.LatencyCheckInterval)
+	})
+
+	// Check for lost rockets
+	if lostRockets != nil {
+		g.Go(func() error {
+			return lostRockets.Run(ctx, cfg.Alerts.LostAfter/4)
+		})
+	}
+
+	// Refresh the snapshot of the listings
+	if snapshot != nil {
+		g.Go(func() error {
+			return snapshot.Run(ctx)
+		})
+	}
+
+	// Move old history to the cold tier
+	if tieredHistory != nil {
+		g.Go(func() error {
+			return tieredHistory.Run(ctx, cfg.History.TierInterval)
+		})
+	}
+
+	// Consume the telemetry topic, pausing while the store or the ingestion stalls or the instance is a standby
+	if cfg.Consumer.URL != "" {
+		var checks []health.Check
+		if fileStore != nil {
+			checks = append(checks, health.Check{Name: "store", Check: func() error {
+				return fileStore.Stalled(cfg.Health.StallAfter)
+			}})
+		}
+		checks = append(checks, health.Check{Name: "rockets", Check: func() error {
+			return serviceImpl.Stalled(cfg.Health.StallAfter)
+		}})
+		if elector != nil {
+			checks = append(checks, health.Check{Name: "leader", Check: func() error {
+				if !elector.IsLeader() {
+					return errors.New("standby instance")
+				}
+				return nil
+			}})
+		}
+		monitor := health.NewMonitor(checks, logger.Named(logging.ComponentHealth))
+		monitor.UseMetrics(registry)
+		monitor.Check()
+		g.Go(func() error {
+			return monitor.Run(ctx, cfg.Health.Interval)
+		})
+		kafka := consumer.NewKafka(cfg.Consumer, rocketSvc, monitor, logger.Named(logging.ComponentConsumer))
+		g.Go(func() error {
+			return kafka.Run(ctx)
+		})
+	}
+
+	// Start the HTTP server on every listener
+	for _, l := range listeners {
+		g.Go(http.ServeEchoServer(e, l, httpLogger))
+	}
+	g.Go(http.ShutDownEchoServer(ctx, e, httpLogger))
+
+	if exporter != nil {
+		g.Go(func() error {
+			return exporter.Run(ctx, registry, logger.Named(logging.ComponentOTLP))
+		})
+	}
+
+	// Push rocket samples to the time-series database
+	if cfg.TSDB.URL != "" {
+		exporter := tsdb.NewExporter(rocketSvc, cfg.TSDB, logger.Named(logging.ComponentTSDB))
+		exporter.UseRetry(retrier)
+		g.Go(singleton(exporter.Run))
+	}
+
+	// Export the event history on schedule
+	if historyExport != nil {
+		g.Go(singleton(func(ctx context.Context) error {
+			return historyExport.Run(ctx, cfg.Export.Interval)
+		}))
+	}
+
+	// Publish the state changes
+	if changes != nil {
+		g.Go(singleton(changes.Run))
+	}
+
+	// Load the applied events into the warehouse on schedule
+	if sink != nil {
+		g.Go(singleton(func(ctx context.Context) error {
+			return sink.Run(ctx, cfg.Warehouse.Interval)
+		}))
+	}
+
+	// Start the mission digest scheduler
+	if cfg.Reports.Enabled {
+		sender := report.NewSMTPSender(cfg.SMTP)
+		sender.UseRetry(retrier)
+		scheduler := report.NewScheduler(collector, sender, cfg.Reports, logger.Named(logging.ComponentReports))
+		g.Go(singleton(scheduler.Run))
+	}
+
+	// Tell systemd the service is up and keep its watchdog fed
+	if _, err := systemd.Notify(systemd.StateReady); err != nil {
+		logger.Warn("Can't notify systemd", zap.Error(err))
+	}
+	if timeout, ok := systemd.WatchdogInterval(); ok {
+		// the ping stops once a rocket update or a store write hangs for the timeout
+		healthy := func() error {
+			if fileStore != nil {
+				if err := fileStore.Stalled(timeout); err != nil {
+					return err
+				}
+			}
+			return serviceImpl.Stalled(timeout)
+		}
+		g.Go(func() error {
+			return systemd.RunWatchdog(ctx, timeout, healthy, logger)
+		})
+	}
 	err = g.Wait()
+	if _, err := systemd.Notify(systemd.StateStopping); err != nil {
+		logger.Warn("Can't notify systemd", zap.Error(err))
+	}
+	serviceImpl.FlushHeldMessages(context.Background())
 	if err != nil {
 		return err
 	}
-	log.Info().Msg("Service is down gracefully")
+	logger.Info("Service is down gracefully")
 	return nil
 }
 
 func main() {
-	err := run()
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		err = runBench(os.Args[2:])
+	} else if len(os.Args) > 1 && os.Args[1] == "tsgen" {
+		err = runTSGen(os.Args[2:])
+	} else {
+		err = run()
+	}
 	if err != nil {
-		log.Err(err).Msg("error")
+		log.Fatalf("error: %s", err)
 	}
 }
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"rockets/internal/apischema"
+)
+
+// runTSGen writes the TypeScript client generated from the OpenAPI spec, run by go generate ./internal/apischema.
+func runTSGen(args []string) error {
+	fs := flag.NewFlagSet("tsgen", flag.ExitOnError)
+	out := fs.String("out", "clients/typescript/rockets.ts", "File to write the client to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	client, err := apischema.TypeScript()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		return fmt.Errorf("can't create client directory: %w", err)
+	}
+	if err := os.WriteFile(*out, client, 0o644); err != nil {
+		return fmt.Errorf("can't write client: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package apischema publishes the wire types of the API described by the OpenAPI spec embedded in the gen
+// package: as JSON Schemas served under /schemas, and as the TypeScript client generated into clients/typescript.
+// Both are derived from the same spec the Go server is generated from, so they can't drift from it.
+package apischema
+
+//go:generate go run rockets/cmd tsgen -out ../../clients/typescript/rockets.ts
+
+import (
+	"encoding/json"
+	"fmt"
+	"rockets/internal/http/gen"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Draft - JSON Schema dialect of the published schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// refPrefix - prefix of the references to the schemas of the spec
+const refPrefix = "#/components/schemas/"
+
+// Schemas returns the JSON Schemas of the wire types by name. A reference to another type points at its schema
+// file, e.g. RocketState.json, so the schemas resolve against the location they are served from.
+var Schemas = sync.OnceValues(func() (map[string]map[string]any, error) {
+	doc, err := spec()
+	if err != nil {
+		return nil, err
+	}
+	components, _ := doc["components"].(map[string]any)
+	schemas, _ := components["schemas"].(map[string]any)
+	out := make(map[string]map[string]any, len(schemas))
+	for name, schema := range schemas {
+		s, ok := schema.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("schema %s is not an object", name)
+		}
+		converted := convert(s)
+		converted["$schema"] = Draft
+		converted["title"] = name
+		out[name] = converted
+	}
+	return out, nil
+})
+
+// Names returns the names of the schemas, sorted
+func Names(schemas map[string]map[string]any) []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// spec returns the embedded OpenAPI spec decoded into plain JSON values
+func spec() (map[string]any, error) {
+	swagger, err := gen.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("can't load the OpenAPI spec: %w", err)
+	}
+	data, err := json.Marshal(swagger)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode the OpenAPI spec: %w", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("can't decode the OpenAPI spec: %w", err)
+	}
+	return doc, nil
+}
+
+// convert turns an OpenAPI 3.0 schema into a JSON Schema: references point at schema files, nullable types
+// permit null, examples become a list and the extensions of the code generator are dropped. Values of other
+// keywords, e.g. enums and defaults, are data and kept as they are.
+func convert(schema map[string]any) map[string]any {
+	out := make(map[string]any, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "$ref":
+			ref, _ := value.(string)
+			out[key] = strings.TrimPrefix(ref, refPrefix) + ".json"
+		case "example":
+			out["examples"] = []any{value}
+		case "nullable":
+		case "properties":
+			properties, _ := value.(map[string]any)
+			converted := make(map[string]any, len(properties))
+			for name, property := range properties {
+				converted[name] = convertAny(property)
+			}
+			out[key] = converted
+		case "items", "additionalProperties", "not":
+			out[key] = convertAny(value)
+		case "allOf", "anyOf", "oneOf":
+			schemas, _ := value.([]any)
+			converted := make([]any, len(schemas))
+			for i, s := range schemas {
+				converted[i] = convertAny(s)
+			}
+			out[key] = converted
+		default:
+			if !strings.HasPrefix(key, "x-") {
+				out[key] = value
+			}
+		}
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		if typ, ok := out["type"].(string); ok {
+			out["type"] = []any{typ, "null"}
+		}
+		if enum, ok := out["enum"].([]any); ok {
+			out["enum"] = append(enum, nil)
+		}
+	}
+	return out
+}
+
+// convertAny converts a schema, leaving boolean schemas, e.g. of additionalProperties, as they are
+func convertAny(v any) any {
+	if schema, ok := v.(map[string]any); ok {
+		return convert(schema)
+	}
+	return v
+}
@@ -0,0 +1,47 @@
+package apischema
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSchemas(t *testing.T) {
+	schemas, err := Schemas()
+	if err != nil {
+		t.Fatalf("Schemas failed: %v", err)
+	}
+	state, ok := schemas["RocketState"]
+	if !ok {
+		t.Fatalf("Expected a RocketState schema, got %v", Names(schemas))
+	}
+	if state["$schema"] != Draft || state["title"] != "RocketState" {
+		t.Errorf("Expected the dialect and the title set, got %v, %v", state["$schema"], state["title"])
+	}
+	properties, _ := state["properties"].(map[string]any)
+	reason, _ := properties["reason"].(map[string]any)
+	if !reflect.DeepEqual(reason["type"], []any{"string", "null"}) || reason["nullable"] != nil {
+		t.Errorf("Expected the nullable reason to permit null, got %v", reason)
+	}
+	if explosion, _ := properties["explosionReason"].(map[string]any); explosion["$ref"] != "ExplosionReason.json" {
+		t.Errorf("Expected the reference to point at the schema file, got %v", explosion)
+	}
+	if id, _ := properties["id"].(map[string]any); id["example"] != nil || len(id["examples"].([]any)) != 1 {
+		t.Errorf("Expected the example turned into examples, got %v", id)
+	}
+}
+
+func TestTypeScript_UpToDate(t *testing.T) {
+	client, err := TypeScript()
+	if err != nil {
+		t.Fatalf("TypeScript failed: %v", err)
+	}
+	committed, err := os.ReadFile("../../clients/typescript/rockets.ts")
+	if err != nil {
+		t.Fatalf("Can't read the committed client: %v", err)
+	}
+	if !bytes.Equal(committed, client) {
+		t.Errorf("Expected the committed client generated from the current spec, run go generate ./internal/apischema")
+	}
+}
@@ -0,0 +1,452 @@
+package apischema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tsHeader - first line of the generated client, telling it must not be edited by hand
+const tsHeader = "// Code generated by `go run rockets/cmd tsgen` from api/openapi.yaml. DO NOT EDIT.\n"
+
+// tsRuntime - the part of the client not depending on the spec: the errors, the options and the request helper
+const tsRuntime = `/** Error answered by the API, with the body when it is an ErrorResponse. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body?: ErrorResponse,
+  ) {
+    super(body?.message ?? ` + "`HTTP ${status}`" + `);
+    this.name = "ApiError";
+  }
+}
+
+export interface ClientOptions {
+  /** Base URL of the API, e.g. https://rockets.example.com, empty for the origin of the page. */
+  baseUrl: string;
+  /** API key sent in the X-API-Key header. */
+  apiKey?: string;
+  /** fetch implementation, the global one by default. */
+  fetch?: typeof fetch;
+}
+
+interface RequestOptions {
+  query?: Record<string, unknown>;
+  headers?: Record<string, string | undefined>;
+  body?: unknown;
+}
+
+export class RocketsClient {
+  private readonly baseUrl: string;
+  private readonly apiKey?: string;
+  private readonly fetch: typeof fetch;
+
+  constructor(options: ClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/+$/, "");
+    this.apiKey = options.apiKey;
+    this.fetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+  }
+
+  private async request(method: string, path: string, init: RequestOptions = {}): Promise<unknown> {
+    const search = new URLSearchParams();
+    for (const [name, value] of Object.entries(init.query ?? {})) {
+      if (value !== undefined) {
+        search.append(name, String(value));
+      }
+    }
+    const headers: Record<string, string> = { Accept: "application/json" };
+    for (const [name, value] of Object.entries(init.headers ?? {})) {
+      if (value !== undefined) {
+        headers[name] = value;
+      }
+    }
+    if (this.apiKey !== undefined) {
+      headers["X-API-Key"] = this.apiKey;
+    }
+    if (init.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    const query = search.toString();
+    const response = await this.fetch(this.baseUrl + path + (query ? "?" + query : ""), {
+      method,
+      headers,
+      body: init.body === undefined ? undefined : JSON.stringify(init.body),
+    });
+    const type = response.headers.get("Content-Type") ?? "";
+    if (!response.ok) {
+      throw new ApiError(response.status, type.includes("json") ? await response.json() : undefined);
+    }
+    if (response.status === 204) {
+      return undefined;
+    }
+    if (type.includes("json")) {
+      return response.json();
+    }
+    if (type.startsWith("text/")) {
+      return response.text();
+    }
+    return response.blob();
+  }
+`
+
+var (
+	identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	pathParam  = regexp.MustCompile(`\{([^}]+)\}`)
+)
+
+// param - parameter of an operation
+type param struct {
+	Name     string
+	In       string
+	Required bool
+	Schema   map[string]any
+}
+
+// TypeScript returns the TypeScript client of the API: a type per schema, named as in the gen package, and a
+// RocketsClient with a method per operation, named by its operationId.
+func TypeScript() ([]byte, error) {
+	doc, err := spec()
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString(tsHeader)
+
+	components, _ := doc["components"].(map[string]any)
+	schemas, _ := components["schemas"].(map[string]any)
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema, _ := schemas[name].(map[string]any)
+		b.WriteString("\n")
+		writeDoc(&b, "", str(schema["description"]))
+		if properties, ok := schema["properties"].(map[string]any); ok && len(properties) > 0 {
+			fmt.Fprintf(&b, "export interface %s ", name)
+			writeProperties(&b, "", schema)
+			b.WriteString("\n")
+		} else if typ := tsType(schema, ""); len(typ) > 100 && strings.Contains(typ, " | ") {
+			fmt.Fprintf(&b, "export type %s =\n  | %s;\n", name, strings.ReplaceAll(typ, " | ", "\n  | "))
+		} else {
+			fmt.Fprintf(&b, "export type %s = %s;\n", name, typ)
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(tsRuntime)
+	paths, _ := doc["paths"].(map[string]any)
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+	for _, path := range pathNames {
+		item, _ := paths[path].(map[string]any)
+		methods := make([]string, 0, len(item))
+		for method := range item {
+			if _, ok := item[method].(map[string]any); ok && method != "parameters" {
+				methods = append(methods, method)
+			}
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			op, _ := item[method].(map[string]any)
+			if err := writeOperation(&b, path, strings.ToUpper(method), op); err != nil {
+				return nil, err
+			}
+		}
+	}
+	b.WriteString("}\n")
+	return b.Bytes(), nil
+}
+
+// writeOperation writes the client method of the operation. The path parameters and the body are its
+// arguments, the query and the header parameters are the fields of a params argument.
+func writeOperation(b *bytes.Buffer, path, method string, op map[string]any) error {
+	// the embedded spec has the operationIds capitalized like the methods of the gen package
+	name := str(op["operationId"])
+	if !identifier.MatchString(name) {
+		return fmt.Errorf("operation %s %s has no valid operationId", method, path)
+	}
+	name = strings.ToLower(name[:1]) + name[1:]
+	var (
+		args    []string
+		inPath  = map[string]string{}
+		options []param
+	)
+	rawParams, _ := op["parameters"].([]any)
+	for _, raw := range rawParams {
+		p, _ := raw.(map[string]any)
+		schema, _ := p["schema"].(map[string]any)
+		required, _ := p["required"].(bool)
+		prm := param{Name: str(p["name"]), In: str(p["in"]), Required: required, Schema: schema}
+		switch prm.In {
+		case "path":
+			typ := tsType(schema, "    ")
+			args = append(args, fmt.Sprintf("%s: %s", prm.Name, typ))
+			value := prm.Name
+			if typ != "string" {
+				value = "String(" + value + ")"
+			}
+			inPath[prm.Name] = "${encodeURIComponent(" + value + ")}"
+		case "query", "header":
+			options = append(options, prm)
+		}
+	}
+	hasBody := false
+	if body, ok := op["requestBody"].(map[string]any); ok {
+		schema := jsonSchema(body["content"])
+		required, _ := body["required"].(bool)
+		arg := "body: " + tsType(schema, "    ")
+		if !required {
+			arg = "body?: " + tsType(schema, "    ")
+		}
+		args = append(args, arg)
+		hasBody = true
+	}
+	if len(options) > 0 {
+		var o strings.Builder
+		o.WriteString("params: {")
+		optional := true
+		for i, p := range options {
+			if i > 0 {
+				o.WriteString(";")
+			}
+			mark := "?"
+			if p.Required {
+				mark, optional = "", false
+			}
+			fmt.Fprintf(&o, " %s%s: %s", quoteName(p.Name), mark, tsType(p.Schema, "    "))
+		}
+		o.WriteString(" }")
+		if optional {
+			o.WriteString(" = {}")
+		}
+		args = append(args, o.String())
+	}
+
+	result := responseType(op)
+	b.WriteString("\n")
+	summary := str(op["summary"])
+	if description := str(op["description"]); description != "" {
+		summary = strings.TrimRight(summary, ".") + ".\n\n" + description
+	}
+	writeDoc(b, "  ", summary)
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", name, strings.Join(args, ", "), result)
+
+	target := pathParam.ReplaceAllStringFunc(path, func(s string) string {
+		return inPath[s[1:len(s)-1]]
+	})
+	var init []string
+	for _, in := range []string{"query", "header"} {
+		var fields []string
+		for _, p := range options {
+			if p.In == in {
+				value := "params." + p.Name
+				if !identifier.MatchString(p.Name) {
+					value = "params[" + quoteName(p.Name) + "]"
+				}
+				fields = append(fields, fmt.Sprintf("%s: %s", quoteName(p.Name), value))
+			}
+		}
+		if len(fields) > 0 {
+			key := in
+			if in == "header" {
+				key = "headers"
+			}
+			init = append(init, fmt.Sprintf("%s: { %s }", key, strings.Join(fields, ", ")))
+		}
+	}
+	if hasBody {
+		init = append(init, "body")
+	}
+	call := fmt.Sprintf("this.request(%q, `%s`", method, target)
+	if len(init) > 0 {
+		call += ", { " + strings.Join(init, ", ") + " }"
+	}
+	fmt.Fprintf(b, "    return %s) as Promise<%s>;\n  }\n", call, result)
+	return nil
+}
+
+// responseType returns the union of the types of the successful responses of the operation: their JSON schema,
+// string for text and Blob for other content
+func responseType(op map[string]any) string {
+	responses, _ := op["responses"].(map[string]any)
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	var types []string
+	for _, code := range codes {
+		response, _ := responses[code].(map[string]any)
+		content, _ := response["content"].(map[string]any)
+		mediaTypes := make([]string, 0, len(content))
+		for mediaType := range content {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+		sort.Strings(mediaTypes)
+		for _, mediaType := range mediaTypes {
+			switch {
+			case strings.Contains(mediaType, "json"):
+				media, _ := content[mediaType].(map[string]any)
+				schema, _ := media["schema"].(map[string]any)
+				types = appendUnique(types, tsType(schema, "  "))
+			case strings.HasPrefix(mediaType, "text/"):
+				types = appendUnique(types, "string")
+			default:
+				types = appendUnique(types, "Blob")
+			}
+		}
+	}
+	if len(types) == 0 {
+		return "void"
+	}
+	return strings.Join(types, " | ")
+}
+
+// tsType returns the TypeScript type of the schema, indenting the fields of inline objects by the indent
+func tsType(schema map[string]any, indent string) string {
+	if schema == nil {
+		return "unknown"
+	}
+	var typ string
+	switch {
+	case schema["$ref"] != nil:
+		typ = strings.TrimPrefix(str(schema["$ref"]), refPrefix)
+	case schema["enum"] != nil:
+		values, _ := schema["enum"].([]any)
+		literals := make([]string, 0, len(values))
+		for _, v := range values {
+			if v == nil {
+				continue
+			}
+			literal, _ := json.Marshal(v)
+			literals = append(literals, string(literal))
+		}
+		typ = strings.Join(literals, " | ")
+	default:
+		switch schema["type"] {
+		case "string":
+			typ = "string"
+			if schema["format"] == "binary" {
+				typ = "Blob"
+			}
+		case "integer", "number":
+			typ = "number"
+		case "boolean":
+			typ = "boolean"
+		case "array":
+			items, _ := schema["items"].(map[string]any)
+			typ = tsType(items, indent)
+			if strings.Contains(typ, " | ") {
+				typ = "(" + typ + ")"
+			}
+			typ += "[]"
+		case "object":
+			if properties, ok := schema["properties"].(map[string]any); ok && len(properties) > 0 {
+				var b bytes.Buffer
+				writeProperties(&b, indent, schema)
+				typ = b.String()
+			} else if values, ok := schema["additionalProperties"].(map[string]any); ok {
+				typ = "Record<string, " + tsType(values, indent) + ">"
+			} else {
+				typ = "Record<string, unknown>"
+			}
+		default:
+			typ = "unknown"
+		}
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		typ += " | null"
+	}
+	return typ
+}
+
+// writeProperties writes the properties of the object schema as the body of an interface or an object type,
+// the ones not required optional
+func writeProperties(b *bytes.Buffer, indent string, schema map[string]any) {
+	properties, _ := schema["properties"].(map[string]any)
+	required := map[string]bool{}
+	list, _ := schema["required"].([]any)
+	for _, name := range list {
+		required[str(name)] = true
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.WriteString("{\n")
+	for _, name := range names {
+		property, _ := properties[name].(map[string]any)
+		writeDoc(b, indent+"  ", str(property["description"]))
+		mark := "?"
+		if required[name] {
+			mark = ""
+		}
+		fmt.Fprintf(b, "%s  %s%s: %s;\n", indent, quoteName(name), mark, tsType(property, indent+"  "))
+	}
+	b.WriteString(indent + "}")
+}
+
+// writeDoc writes the text as a JSDoc comment, nothing if it is empty
+func writeDoc(b *bytes.Buffer, indent, text string) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "*/", "*\\/"))
+	if text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, text)
+		return
+	}
+	b.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s%s\n", indent, strings.TrimRight(" * "+line, " "))
+	}
+	b.WriteString(indent + " */\n")
+}
+
+// jsonSchema returns the schema of the JSON media type of the content
+func jsonSchema(content any) map[string]any {
+	media, _ := content.(map[string]any)
+	for mediaType, m := range media {
+		if strings.Contains(mediaType, "json") {
+			m, _ := m.(map[string]any)
+			schema, _ := m["schema"].(map[string]any)
+			return schema
+		}
+	}
+	return nil
+}
+
+// quoteName quotes the property name unless it is a valid identifier
+func quoteName(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+	quoted, _ := json.Marshal(name)
+	return string(quoted)
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
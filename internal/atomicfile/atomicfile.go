@@ -0,0 +1,38 @@
+// Package atomicfile replaces files durably: the new content is synced before it is renamed over the old file
+// and the directory after, so a crash leaves either version whole and the rename survives a power loss.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Replace syncs and closes the temporary file, renames it over the file and syncs the directory holding them.
+// The temporary file is closed whatever happens, and must be in the directory of the file.
+func Replace(tmp *os.File, file string) error {
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("can't sync %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("can't close %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("can't rename %s: %w", tmp.Name(), err)
+	}
+	return SyncDir(filepath.Dir(file))
+}
+
+// SyncDir syncs the directory, so the entries renamed or created in it survive a power loss
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("can't open directory %s: %w", dir, err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("can't sync directory %s: %w", dir, err)
+	}
+	return nil
+}
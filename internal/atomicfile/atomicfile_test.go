@@ -0,0 +1,30 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "names.json")
+	if err := os.WriteFile(file, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmp, err := os.Create(file + ".tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = tmp.WriteString("new")
+
+	if err := Replace(tmp, file); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "new" {
+		t.Errorf("Expected the file replaced, got %q", data)
+	}
+	if _, err := os.Stat(file + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected the temporary file renamed away, got %v", err)
+	}
+}
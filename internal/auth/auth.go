@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+)
+
+// Principal - authenticated caller of the API
+type Principal struct {
+	// Producer - name of the telemetry producer the API key was issued to
+	Producer string `json:"producer"`
+	// Scope - rockets the producer may read and ingest, all rockets when empty
+	Scope Scope `json:"scope"`
+}
+
+// Scope - rockets of the given missions or channels, letting e.g. partner launch providers see only their
+// own rockets on a shared instance. The zero scope allows all rockets.
+type Scope struct {
+	Missions []string    `json:"missions,omitempty"`
+	Channels []uuid.UUID `json:"channels,omitempty"`
+}
+
+// ParseScope parses a scope of "|"-separated "mission:NAME" and "channel:UUID" items.
+func ParseScope(v string) (Scope, error) {
+	var scope Scope
+	for _, item := range strings.Split(v, "|") {
+		kind, value, _ := strings.Cut(strings.TrimSpace(item), ":")
+		switch {
+		case kind == "mission" && value != "":
+			scope.Missions = append(scope.Missions, value)
+		case kind == "channel":
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return Scope{}, fmt.Errorf("invalid channel %q: %w", value, err)
+			}
+			scope.Channels = append(scope.Channels, id)
+		default:
+			return Scope{}, fmt.Errorf("expected mission:NAME or channel:UUID, got %q", item)
+		}
+	}
+	return scope, nil
+}
+
+// Restricted reports whether the scope limits the rockets, false for the zero scope
+func (s Scope) Restricted() bool {
+	return len(s.Missions) > 0 || len(s.Channels) > 0
+}
+
+// AllowsMission reports whether the rockets of the mission are in the scope
+func (s Scope) AllowsMission(mission string) bool {
+	return !s.Restricted() || slices.Contains(s.Missions, mission)
+}
+
+// Allows reports whether the rocket of the channel, currently on the mission, is in the scope.
+// The mission is empty when it is not known yet.
+func (s Scope) Allows(channel uuid.UUID, mission string) bool {
+	return !s.Restricted() || slices.Contains(s.Channels, channel) || (mission != "" && slices.Contains(s.Missions, mission))
+}
+
+// Anonymous - principal of all callers when authentication is disabled
+var Anonymous = Principal{Producer: "anonymous"}
+
+// IsAnonymous reports whether the caller presented no API key
+func (p Principal) IsAnonymous() bool {
+	return p.Producer == Anonymous.Producer
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of the context carrying the principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in the context, Anonymous if there is none.
+func FromContext(ctx context.Context) Principal {
+	if p, ok := ctx.Value(principalKey{}).(Principal); ok {
+		return p
+	}
+	return Anonymous
+}
+
+// Keys - registry of API keys issued to producers
+type Keys struct {
+	keys map[string]Principal
+}
+
+// NewKeys creates a registry from a map of API key to producer name.
+func NewKeys(apiKeys map[string]string) *Keys {
+	keys := make(map[string]Principal, len(apiKeys))
+	for key, producer := range apiKeys {
+		keys[key] = Principal{Producer: producer}
+	}
+	return &Keys{keys: keys}
+}
+
+// Enabled reports whether any API key is configured; authentication is disabled otherwise.
+func (k *Keys) Enabled() bool {
+	return len(k.keys) > 0
+}
+
+// Restrict limits the principals of the producer's API keys to the scope, returning false if the producer
+// has no key
+func (k *Keys) Restrict(producer string, scope Scope) bool {
+	found := false
+	for key, p := range k.keys {
+		if p.Producer == producer {
+			p.Scope = scope
+			k.keys[key] = p
+			found = true
+		}
+	}
+	return found
+}
+
+// Authenticate returns the principal the API key was issued to
+func (k *Keys) Authenticate(key string) (Principal, bool) {
+	var found Principal
+	var ok bool
+	// compare against every key to not leak the position of a match through timing
+	for candidate, p := range k.keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			found, ok = p, true
+		}
+	}
+	return found, ok
+}
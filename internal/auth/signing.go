@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+)
+
+// SigningKeys - ed25519 public keys of the producers signing their telemetry payloads. A nil set has no keys.
+type SigningKeys struct {
+	byProducer map[string]ed25519.PublicKey
+}
+
+// ParseSigningKeys parses the base64 encoded public keys by producer name.
+func ParseSigningKeys(keys map[string]string) (*SigningKeys, error) {
+	k := &SigningKeys{byProducer: make(map[string]ed25519.PublicKey, len(keys))}
+	for producer, v := range keys {
+		key, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key of %s: %w", producer, err)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid key of %s: expected %d bytes, got %d", producer, ed25519.PublicKeySize, len(key))
+		}
+		k.byProducer[producer] = key
+	}
+	return k, nil
+}
+
+// Has reports whether the producer registered a key, i.e. its payloads are verified
+func (k *SigningKeys) Has(producer string) bool {
+	if k == nil {
+		return false
+	}
+	_, ok := k.byProducer[producer]
+	return ok
+}
+
+// Verify reports whether the base64 encoded signature is the producer's ed25519 signature of the payload
+func (k *SigningKeys) Verify(producer string, payload []byte, signature string) bool {
+	if k == nil {
+		return false
+	}
+	key, ok := k.byProducer[producer]
+	if !ok {
+		return false
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(key, payload, sig)
+}
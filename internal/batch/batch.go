@@ -0,0 +1,105 @@
+// Package batch queues items between deliveries and delivers them in batches on a schedule, e.g. the rows of
+// the warehouse sink and the change events of the CDC publisher
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout - how long the last flush of Run may take once its context is done
+const shutdownTimeout = 5 * time.Second
+
+// Queue - items waiting for delivery in the order they were added, the oldest are dropped above the limit
+// while the receiving end is unreachable
+type Queue[T any] struct {
+	size  int
+	limit int
+
+	// flush - held while delivering, so batches are delivered in the order of the items and a triggered
+	// flush never overlaps the scheduled one
+	flush sync.Mutex
+
+	mu      sync.Mutex
+	pending []T
+	dropped int64
+}
+
+// NewQueue creates a queue delivering up to size items per batch and keeping up to limit items.
+func NewQueue[T any](size, limit int) *Queue[T] {
+	return &Queue[T]{size: size, limit: limit}
+}
+
+// Add queues the item, dropping the oldest items above the limit
+func (q *Queue[T]) Add(item T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, item)
+	q.trim()
+}
+
+// trim drops the oldest pending items above the limit
+func (q *Queue[T]) trim() {
+	if over := len(q.pending) - q.limit; over > 0 {
+		q.pending = q.pending[over:]
+		q.dropped += int64(over)
+	}
+}
+
+// Len returns the number of items waiting for delivery
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Dropped returns the number of items dropped above the limit since the queue was created
+func (q *Queue[T]) Dropped() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// Flush delivers the pending items in batches, oldest first, until none is left. A batch failing to deliver
+// is put back in front of the items added meanwhile and its error returned, so the next flush delivers the
+// items in their order again.
+func (q *Queue[T]) Flush(ctx context.Context, deliver func(ctx context.Context, batch []T) error) error {
+	q.flush.Lock()
+	defer q.flush.Unlock()
+	for {
+		q.mu.Lock()
+		batch := q.pending[:min(len(q.pending), q.size)]
+		q.pending = q.pending[len(batch):]
+		q.mu.Unlock()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := deliver(ctx, batch); err != nil {
+			q.mu.Lock()
+			q.pending = append(batch[:len(batch):len(batch)], q.pending...)
+			q.trim()
+			q.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// Run calls flush every interval until the context is done, then once more with a moment of its own, so the
+// items queued while the service goes down are delivered too.
+func Run(ctx context.Context, interval time.Duration, flush func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			flush(ctx)
+			cancel()
+			return
+		case <-ticker.C:
+			flush(ctx)
+		}
+	}
+}
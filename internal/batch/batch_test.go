@@ -0,0 +1,50 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestQueue_Flush(t *testing.T) {
+	q := NewQueue[int](2, 4)
+	for i := 1; i <= 5; i++ {
+		q.Add(i)
+	}
+	if q.Len() != 4 || q.Dropped() != 1 {
+		t.Fatalf("Expected 4 items pending and 1 dropped, got %d and %d", q.Len(), q.Dropped())
+	}
+
+	// the second batch fails, it stays pending in front of the item added meanwhile
+	var delivered [][]int
+	err := q.Flush(context.Background(), func(_ context.Context, batch []int) error {
+		if len(delivered) == 1 {
+			q.Add(6)
+			return errors.New("unreachable")
+		}
+		delivered = append(delivered, slices.Clone(batch))
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("Expected the flush to fail")
+	}
+	if q.Len() != 3 {
+		t.Errorf("Expected 3 items pending, got %d", q.Len())
+	}
+
+	err = q.Flush(context.Background(), func(_ context.Context, batch []int) error {
+		delivered = append(delivered, slices.Clone(batch))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	expected := [][]int{{2, 3}, {4, 5}, {6}}
+	if !slices.EqualFunc(delivered, expected, slices.Equal[[]int]) {
+		t.Errorf("Expected batches %v, got %v", expected, delivered)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Expected nothing pending, got %d", q.Len())
+	}
+}
@@ -0,0 +1,187 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"rockets/internal/rocket"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store backends the benchmark can run against
+const (
+	StoreMemory = "memory"
+	StoreFile   = "file"
+)
+
+// Options - parameters of a benchmark run
+type Options struct {
+	// Concurrency - number of producers sending messages in parallel
+	Concurrency int
+	// Rockets - number of rockets, spread over the producers
+	Rockets int
+	// Messages - number of messages sent per rocket, the first one launches it
+	Messages int
+	// Store - store backend, StoreMemory or StoreFile
+	Store string
+	// CommitWindow - group commit window of the file store, 0 writes every update
+	CommitWindow time.Duration
+}
+
+// Validate checks the options
+func (o Options) Validate() error {
+	if o.Concurrency <= 0 || o.Rockets <= 0 || o.Messages <= 0 {
+		return fmt.Errorf("concurrency, rockets and messages must be positive")
+	}
+	if o.CommitWindow < 0 {
+		return fmt.Errorf("commit window must not be negative")
+	}
+	if o.Store != StoreMemory && o.Store != StoreFile {
+		return fmt.Errorf("unknown store %q, expected %s or %s", o.Store, StoreMemory, StoreFile)
+	}
+	return nil
+}
+
+// Summary - outcome of a benchmark run
+type Summary struct {
+	Options  Options
+	Messages int
+	Errors   int
+	Duration time.Duration
+	// Latencies of ProcessMessage calls
+	P50, P90, P99, Max time.Duration
+}
+
+// Throughput returns processed messages per second
+func (s Summary) Throughput() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Messages) / s.Duration.Seconds()
+}
+
+// Print writes the summary in a stable, line-oriented format that can be compared release-over-release
+func (s Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "store:        %s\n", s.Options.Store)
+	fmt.Fprintf(w, "commit:       %s\n", s.Options.CommitWindow)
+	fmt.Fprintf(w, "concurrency:  %d\n", s.Options.Concurrency)
+	fmt.Fprintf(w, "rockets:      %d\n", s.Options.Rockets)
+	fmt.Fprintf(w, "messages:     %d\n", s.Messages)
+	fmt.Fprintf(w, "errors:       %d\n", s.Errors)
+	fmt.Fprintf(w, "duration:     %s\n", s.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "throughput:   %.0f msg/s\n", s.Throughput())
+	fmt.Fprintf(w, "latency p50:  %s\n", s.P50)
+	fmt.Fprintf(w, "latency p90:  %s\n", s.P90)
+	fmt.Fprintf(w, "latency p99:  %s\n", s.P99)
+	fmt.Fprintf(w, "latency max:  %s\n", s.Max)
+}
+
+// Run sends the generated messages to a new in-process service and measures throughput and latency.
+func Run(ctx context.Context, opts Options) (Summary, error) {
+	if err := opts.Validate(); err != nil {
+		return Summary{}, err
+	}
+
+	logger := zap.NewNop()
+	var store rocket.Store = rocket.NewInMemoryRocketStore(logger)
+	if opts.Store == StoreFile {
+		dir, err := os.MkdirTemp("", "rockets-bench")
+		if err != nil {
+			return Summary{}, fmt.Errorf("can't create bench directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		fileStore, err := rocket.OpenFileRocketStore(filepath.Join(dir, "rockets.jsonl"), nil, logger)
+		if err != nil {
+			return Summary{}, err
+		}
+		defer fileStore.Close()
+		if opts.CommitWindow > 0 {
+			fileStore.UseGroupCommit(opts.CommitWindow, true)
+		}
+		store = fileStore
+	}
+	svc := rocket.NewRocketService(store, logger)
+
+	// every producer owns its rockets, so their messages arrive in order
+	streams := make([][]rocket.TelemetryMessage, opts.Concurrency)
+	start := time.Now().UTC()
+	for r := 0; r < opts.Rockets; r++ {
+		id := uuid.New()
+		p := r % opts.Concurrency
+		for n := 1; n <= opts.Messages; n++ {
+			streams[p] = append(streams[p], message(id, n, start))
+		}
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		latencies = make([]time.Duration, 0, opts.Rockets*opts.Messages)
+		errors    int
+	)
+	began := time.Now()
+	for _, stream := range streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]time.Duration, 0, len(stream))
+			failed := 0
+			for _, msg := range stream {
+				t := time.Now()
+				if _, err := svc.ProcessMessage(ctx, msg); err != nil {
+					failed++
+				}
+				local = append(local, time.Since(t))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			latencies = append(latencies, local...)
+			errors += failed
+		}()
+	}
+	wg.Wait()
+
+	summary := Summary{
+		Options:  opts,
+		Messages: len(latencies),
+		Errors:   errors,
+		Duration: time.Since(began),
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	summary.P50 = percentile(latencies, 0.50)
+	summary.P90 = percentile(latencies, 0.90)
+	summary.P99 = percentile(latencies, 0.99)
+	summary.Max = percentile(latencies, 1)
+	return summary, nil
+}
+
+// message builds the n-th message of the rocket: a launch followed by speed changes
+func message(id uuid.UUID, n int, start time.Time) rocket.TelemetryMessage {
+	md := rocket.MessageMetadata{Channel: id, MessageNumber: int64(n), MessageTime: start.Add(time.Duration(n) * time.Second)}
+	if n == 1 {
+		md.MessageType = rocket.MessageTypeLaunched
+		rocketType, speed, mission := rocket.RocketType("Falcon-9"), rocket.Speed(500), rocket.Mission("ARTEMIS")
+		return rocket.TelemetryMessage{Metadata: md, Message: rocket.Message{Type: &rocketType, LaunchSpeed: &speed, Mission: &mission}}
+	}
+	by := rocket.Speed(100)
+	md.MessageType = rocket.MessageTypeSpeedIncreased
+	if n%2 == 0 {
+		md.MessageType = rocket.MessageTypeSpeedDecreased
+	}
+	return rocket.TelemetryMessage{Metadata: md, Message: rocket.Message{By: &by}}
+}
+
+// percentile returns the q-th quantile of the sorted durations
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(q*float64(len(sorted))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
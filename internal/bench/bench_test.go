@@ -0,0 +1,39 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	for _, store := range []string{StoreMemory, StoreFile} {
+		summary, err := Run(context.Background(), Options{Concurrency: 3, Rockets: 5, Messages: 10, Store: store})
+		if err != nil {
+			t.Fatalf("Run with %s store failed: %v", store, err)
+		}
+		if summary.Messages != 50 || summary.Errors != 0 {
+			t.Errorf("Expected 50 messages without errors with %s store, got %+v", store, summary)
+		}
+		if summary.P50 > summary.P99 || summary.P99 > summary.Max {
+			t.Errorf("Expected ordered percentiles, got %+v", summary)
+		}
+	}
+
+	if _, err := Run(context.Background(), Options{Concurrency: 1, Rockets: 1, Messages: 1, Store: "postgres"}); err == nil {
+		t.Errorf("Expected an error for an unknown store")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if got := percentile(sorted, 0.5); got != 5 {
+		t.Errorf("Expected p50 of 5, got %d", got)
+	}
+	if got := percentile(sorted, 1); got != 10 {
+		t.Errorf("Expected max of 10, got %d", got)
+	}
+	if got := percentile(nil, 0.9); got != 0 {
+		t.Errorf("Expected 0 for no samples, got %d", got)
+	}
+}
@@ -0,0 +1,66 @@
+// Package buildinfo tells what the running binary is: its version, the commit it was built from and when.
+// Release builds set them with linker flags:
+//
+//	go build -ldflags "-X rockets/internal/buildinfo.version=1.4.0 \
+//	  -X rockets/internal/buildinfo.commit=$(git rev-parse HEAD) \
+//	  -X rockets/internal/buildinfo.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd
+//
+// Otherwise the commit and its time are taken from the VCS information the go command embeds.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// devVersion - version of binaries built without a version
+const devVersion = "dev"
+
+// Set by -ldflags "-X ..." at build time
+var (
+	version   string
+	commit    string
+	buildTime string
+)
+
+// Info - build of the running binary
+type Info struct {
+	// Version - release version, "dev" for development builds
+	Version string
+	// Commit - VCS revision, empty if unknown
+	Commit string
+	// BuildTime - when the binary was built, or the commit time for builds without it; zero if unknown
+	BuildTime time.Time
+	// GoVersion - Go toolchain the binary was built with
+	GoVersion string
+}
+
+// Get returns the build of the running binary
+var Get = sync.OnceValue(func() Info {
+	info := Info{
+		Version:   version,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+	}
+	info.BuildTime, _ = time.Parse(time.RFC3339, buildTime)
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildTime.IsZero():
+				info.BuildTime, _ = time.Parse(time.RFC3339, s.Value)
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = devVersion
+	}
+	return info
+})
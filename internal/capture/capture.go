@@ -0,0 +1,191 @@
+package capture
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxBodySize - bodies longer than this are truncated in captured exchanges
+const maxBodySize = 64 << 10
+
+// redacted - replacement of secret values
+const redacted = "[REDACTED]"
+
+// secretHeaders - headers never stored in captured exchanges
+var secretHeaders = []string{"Authorization", "X-Api-Key", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// secretFields - substrings of JSON field names whose values are redacted, compared case-insensitively
+var secretFields = []string{"password", "secret", "token", "apikey", "api_key", "authorization"}
+
+// Exchange - captured HTTP request and response
+type Exchange struct {
+	Time            time.Time   `json:"time"`
+	Method          string      `json:"method"`
+	Path            string      `json:"path"`
+	RemoteAddr      string      `json:"remoteAddr"`
+	Channel         *uuid.UUID  `json:"channel,omitempty"`
+	Status          int         `json:"status"`
+	Duration        string      `json:"duration"`
+	RequestHeaders  http.Header `json:"requestHeaders"`
+	RequestBody     string      `json:"requestBody,omitempty"`
+	ResponseHeaders http.Header `json:"responseHeaders"`
+	ResponseBody    string      `json:"responseBody,omitempty"`
+}
+
+// Recorder decides which requests are captured and keeps the latest captured exchanges
+type Recorder struct {
+	rate     float64
+	channels map[uuid.UUID]struct{}
+
+	mu        sync.Mutex
+	exchanges []Exchange
+	next      int
+	full      bool
+}
+
+// NewRecorder creates a recorder keeping up to size exchanges. Requests are captured with the given
+// sample rate (0..1) and always when they concern one of the channels.
+func NewRecorder(size int, rate float64, channels []uuid.UUID) *Recorder {
+	r := &Recorder{
+		rate:      rate,
+		channels:  make(map[uuid.UUID]struct{}, len(channels)),
+		exchanges: make([]Exchange, size),
+	}
+	for _, id := range channels {
+		r.channels[id] = struct{}{}
+	}
+	return r
+}
+
+// Enabled reports whether any request can be captured, nil recorder is disabled
+func (r *Recorder) Enabled() bool {
+	return r != nil && len(r.exchanges) > 0 && (r.rate > 0 || len(r.channels) > 0)
+}
+
+// Sample decides whether the request concerning the channel is captured, channel may be nil
+func (r *Recorder) Sample(channel *uuid.UUID) bool {
+	if !r.Enabled() {
+		return false
+	}
+	if channel != nil {
+		if _, ok := r.channels[*channel]; ok {
+			return true
+		}
+	}
+	return r.rate >= 1 || rand.Float64() < r.rate
+}
+
+// Record redacts secrets of the exchange and stores it, overwriting the oldest one when full
+func (r *Recorder) Record(e Exchange) {
+	e.RequestHeaders = RedactHeaders(e.RequestHeaders)
+	e.ResponseHeaders = RedactHeaders(e.ResponseHeaders)
+	e.RequestBody = RedactBody(e.RequestBody)
+	e.ResponseBody = RedactBody(e.ResponseBody)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges[r.next] = e
+	r.next = (r.next + 1) % len(r.exchanges)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Recent returns the captured exchanges, newest first
+func (r *Recorder) Recent() []Exchange {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.exchanges)
+	}
+	exchanges := make([]Exchange, 0, n)
+	for i := 1; i <= n; i++ {
+		exchanges = append(exchanges, r.exchanges[(r.next-i+len(r.exchanges))%len(r.exchanges)])
+	}
+	return exchanges
+}
+
+// Clear drops all captured exchanges
+func (r *Recorder) Clear() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.exchanges)
+	r.next = 0
+	r.full = false
+}
+
+// RedactHeaders returns a copy of the headers with values of secret headers replaced
+func RedactHeaders(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	out := h.Clone()
+	for _, name := range secretHeaders {
+		if _, ok := out[name]; ok {
+			out[name] = []string{redacted}
+		}
+	}
+	return out
+}
+
+// RedactBody truncates the body and replaces values of secret fields when it is a JSON document
+func RedactBody(body string) string {
+	if len(body) > maxBodySize {
+		body = body[:maxBodySize] + "...(truncated)"
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return body
+	}
+	if !redactValue(doc) {
+		return body
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return string(out)
+}
+
+// redactValue replaces secret fields in place and reports whether anything was replaced
+func redactValue(v any) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if isSecretField(k) {
+				v[k] = redacted
+				changed = true
+				continue
+			}
+			changed = redactValue(item) || changed
+		}
+	case []any:
+		for _, item := range v {
+			changed = redactValue(item) || changed
+		}
+	}
+	return changed
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range secretFields {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
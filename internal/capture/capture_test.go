@@ -0,0 +1,73 @@
+package capture
+
+import (
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRecorder_Sample(t *testing.T) {
+	watched := uuid.New()
+	other := uuid.New()
+
+	r := NewRecorder(10, 0, []uuid.UUID{watched})
+	if !r.Sample(&watched) {
+		t.Errorf("Expected requests of a watched channel to be captured")
+	}
+	if r.Sample(&other) || r.Sample(nil) {
+		t.Errorf("Expected other requests not to be captured with zero sample rate")
+	}
+
+	if !NewRecorder(10, 1, nil).Sample(nil) {
+		t.Errorf("Expected every request to be captured with sample rate 1")
+	}
+
+	var disabled *Recorder
+	if disabled.Enabled() || disabled.Sample(&watched) {
+		t.Errorf("Expected nil recorder to be disabled")
+	}
+}
+
+func TestRecorder_Record(t *testing.T) {
+	r := NewRecorder(2, 1, nil)
+	for _, path := range []string{"/a", "/b", "/c"} {
+		r.Record(Exchange{
+			Path:           path,
+			RequestHeaders: http.Header{"X-Api-Key": {"k1"}, "Content-Type": {"application/json"}},
+			RequestBody:    `{"metadata":{"channel":"x"},"auth":{"token":"abc"}}`,
+		})
+	}
+
+	recent := r.Recent()
+	if len(recent) != 2 || recent[0].Path != "/c" || recent[1].Path != "/b" {
+		t.Fatalf("Expected the 2 latest exchanges newest first, got %+v", recent)
+	}
+	if got := recent[0].RequestHeaders.Get("X-Api-Key"); got != redacted {
+		t.Errorf("Expected API key header to be redacted, got %q", got)
+	}
+	if got := recent[0].RequestHeaders.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected Content-Type to be kept, got %q", got)
+	}
+	if strings.Contains(recent[0].RequestBody, "abc") || !strings.Contains(recent[0].RequestBody, `"channel":"x"`) {
+		t.Errorf("Expected only the token to be redacted, got %s", recent[0].RequestBody)
+	}
+
+	r.Clear()
+	if len(r.Recent()) != 0 {
+		t.Errorf("Expected no exchanges after Clear")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	if got := RedactBody("not json password=1"); got != "not json password=1" {
+		t.Errorf("Expected non-JSON body to be kept, got %q", got)
+	}
+	if got := RedactBody(`[{"Password":"p"}]`); got != `[{"Password":"[REDACTED]"}]` {
+		t.Errorf("Expected nested password to be redacted, got %q", got)
+	}
+	long := RedactBody(strings.Repeat("a", maxBodySize+10))
+	if !strings.HasSuffix(long, "...(truncated)") {
+		t.Errorf("Expected long body to be truncated")
+	}
+}
@@ -0,0 +1,153 @@
+// Package cdc publishes the state changes of the rockets as change data capture events in the Debezium envelope,
+// so consumers built for Debezium topics ingest rocket state without adapters.
+package cdc
+
+import (
+	"rockets/internal/buildinfo"
+	"rockets/internal/rocket"
+	"time"
+)
+
+// Debezium operations
+const (
+	OpCreate = "c"
+	OpUpdate = "u"
+	OpDelete = "d"
+)
+
+// connector - name of the connector in the source block, tells consumers where the events come from
+const connector = "rockets"
+
+// Row - rocket state as a row of the rockets table, the before and after images of the envelope.
+// Timestamps are milliseconds since the epoch, like Debezium's io.debezium.time.Timestamp.
+type Row struct {
+	ID                         string  `json:"id"`
+	Type                       string  `json:"type"`
+	Mission                    string  `json:"mission"`
+	Status                     string  `json:"status"`
+	CurrentSpeed               int64   `json:"current_speed"`
+	Reason                     *string `json:"reason"`
+	ReasonCategory             *string `json:"reason_category"`
+	ReasonSeverity             *string `json:"reason_severity"`
+	Anomaly                    *string `json:"anomaly"`
+	LastUpdateTime             int64   `json:"last_update_time"`
+	LastProcessedMessageNumber int64   `json:"last_processed_message_number"`
+	Version                    int64   `json:"version"`
+}
+
+// Source - the source block of the envelope, describing where and when the change happened
+type Source struct {
+	Version   string `json:"version"`
+	Connector string `json:"connector"`
+	// Name - logical name of the source, the topic prefix of Debezium connectors
+	Name string `json:"name"`
+	// TsMs - time of the message that changed the state
+	TsMs     int64  `json:"ts_ms"`
+	Snapshot string `json:"snapshot"`
+	DB       string `json:"db"`
+	Table    string `json:"table"`
+	// MessageNumber - number of the telemetry message that changed the state, the position in the rocket's log
+	MessageNumber int64 `json:"message_number"`
+	// Rewrite - why the state was written without applying a message, e.g. rollback, absent for messages
+	Rewrite string `json:"rewrite,omitempty"`
+}
+
+// Envelope - Debezium change event value, as written by the JSON converter without schemas
+type Envelope struct {
+	// Before - state before the change, nil when the rocket was created by it
+	Before *Row   `json:"before"`
+	After  *Row   `json:"after"`
+	Source Source `json:"source"`
+	Op     string `json:"op"`
+	// TsMs - time the change was captured
+	TsMs int64 `json:"ts_ms"`
+}
+
+// Key - Debezium change event key, the primary key of the row
+type Key struct {
+	ID string `json:"id"`
+}
+
+// NewEnvelope describes the change of a rocket state by the message, prev is zero for a new rocket
+func NewEnvelope(name string, msg rocket.TelemetryMessage, prev, next rocket.State, now time.Time) Envelope {
+	e := Envelope{
+		After: ptr(newRow(next)),
+		Source: Source{
+			Version:       buildinfo.Get().Version,
+			Connector:     connector,
+			Name:          name,
+			TsMs:          msg.Metadata.MessageTime.UnixMilli(),
+			Snapshot:      "false",
+			DB:            "rockets",
+			Table:         "rockets",
+			MessageNumber: msg.Metadata.MessageNumber,
+		},
+		Op:   OpCreate,
+		TsMs: now.UnixMilli(),
+	}
+	if prev.Version != 0 {
+		e.Before = ptr(newRow(prev))
+		e.Op = OpUpdate
+	}
+	return e
+}
+
+// NewRewriteEnvelope describes the state written without applying a message, e.g. by a rollback: prev is zero for
+// a new rocket and next is zero for a removed one, deleted by a d event. The source carries the last processed
+// message of the written state and the cause of the rewrite.
+func NewRewriteEnvelope(name string, cause rocket.Rewrite, prev, next rocket.State, now time.Time) Envelope {
+	written := next
+	if next.Version == 0 {
+		written = prev
+	}
+	e := Envelope{
+		Source: Source{
+			Version:       buildinfo.Get().Version,
+			Connector:     connector,
+			Name:          name,
+			TsMs:          written.LastUpdateTime.UnixMilli(),
+			Snapshot:      "false",
+			DB:            "rockets",
+			Table:         "rockets",
+			MessageNumber: written.LastProcessedMessageNumber,
+			Rewrite:       string(cause),
+		},
+		TsMs: now.UnixMilli(),
+	}
+	switch {
+	case next.Version == 0:
+		e.Before = ptr(newRow(prev))
+		e.Op = OpDelete
+	case prev.Version == 0:
+		e.After = ptr(newRow(next))
+		e.Op = OpCreate
+	default:
+		e.Before, e.After = ptr(newRow(prev)), ptr(newRow(next))
+		e.Op = OpUpdate
+	}
+	return e
+}
+
+func newRow(s rocket.State) Row {
+	row := Row{
+		ID:                         s.ID.String(),
+		Type:                       string(s.Type),
+		Mission:                    string(s.Mission),
+		Status:                     string(s.Status),
+		CurrentSpeed:               int64(s.CurrentSpeed),
+		Anomaly:                    s.Anomaly,
+		LastUpdateTime:             s.LastUpdateTime.UnixMilli(),
+		LastProcessedMessageNumber: s.LastProcessedMessageNumber,
+		Version:                    s.Version,
+	}
+	if s.Reason != nil {
+		row.Reason = &s.Reason.Text
+		row.ReasonCategory = ptr(string(s.Reason.Category))
+		row.ReasonSeverity = ptr(string(s.Reason.Severity))
+	}
+	return row
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,178 @@
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"rockets/internal/batch"
+	"rockets/internal/clock"
+	"rockets/internal/config"
+	"rockets/internal/logging"
+	"rockets/internal/retry"
+	"rockets/internal/rocket"
+	"strings"
+	"time"
+)
+
+// maxPendingRecords - change events kept between publishes, the oldest are dropped while the proxy is unreachable
+const maxPendingRecords = 100000
+
+// contentType - embedded JSON format of the Kafka REST Proxy v2 API
+const contentType = "application/vnd.kafka.json.v2+json"
+
+// Record - change event as produced to the topic
+type Record struct {
+	Key   Key      `json:"key"`
+	Value Envelope `json:"value"`
+}
+
+var _ rocket.RewriteListener = (*Publisher)(nil)
+
+// Publisher produces the state changes to a Kafka topic through a Kafka REST Proxy, batching the changes
+// applied within an interval. Records are keyed by the rocket, so the changes of a rocket keep their order.
+type Publisher struct {
+	cfg     config.CDC
+	client  *http.Client
+	retrier *retry.Retrier
+	clock   clock.Clock
+	logger  *zap.Logger
+	records *batch.Queue[Record]
+	// reported - records dropped up to the last publish, only accessed by Run
+	reported int64
+}
+
+// NewPublisher creates a publisher producing to the topic in the settings.
+func NewPublisher(cfg config.CDC, logger *zap.Logger) *Publisher {
+	return &Publisher{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		clock:   clock.Real{},
+		logger:  logger,
+		records: batch.NewQueue[Record](cfg.BatchSize, maxPendingRecords),
+	}
+}
+
+// UseRetry retries failed publishes with the retrier. Must be called before the publisher runs.
+func (p *Publisher) UseRetry(r *retry.Retrier) {
+	p.retrier = r
+}
+
+// UseClock replaces the system clock timestamping the envelopes, e.g. with a fake one in tests.
+// Must be called before the publisher is used.
+func (p *Publisher) UseClock(c clock.Clock) {
+	p.clock = c
+}
+
+// StateChanged queues the change for the next publish
+func (p *Publisher) StateChanged(_ context.Context, msg rocket.TelemetryMessage, prev, next rocket.State) {
+	record := Record{
+		Key:   Key{ID: next.ID.String()},
+		Value: NewEnvelope(p.cfg.Topic, msg, prev, next, p.clock.Now()),
+	}
+
+	p.records.Add(record)
+}
+
+// StateRewritten queues the state written without applying a message, so the topic follows rollbacks, merges
+// and the other rewrites of the store
+func (p *Publisher) StateRewritten(_ context.Context, cause rocket.Rewrite, prev, next rocket.State) {
+	id := next.ID
+	if next.Version == 0 {
+		id = prev.ID
+	}
+	p.records.Add(Record{
+		Key:   Key{ID: id.String()},
+		Value: NewRewriteEnvelope(p.cfg.Topic, cause, prev, next, p.clock.Now()),
+	})
+}
+
+// Run publishes the pending changes every interval until the context is done, then publishes once more.
+// Failures are logged.
+func (p *Publisher) Run(ctx context.Context) error {
+	batch.Run(ctx, p.cfg.Interval, p.publish)
+	return nil
+}
+
+func (p *Publisher) publish(ctx context.Context) {
+	if dropped := p.records.Dropped(); dropped > p.reported {
+		p.logger.Warn("Change events dropped before publishing", zap.Int64("dropped", dropped-p.reported))
+		p.reported = dropped
+	}
+	if err := p.Flush(ctx); err != nil {
+		p.logger.Error("Can't publish change events", logging.Event(logging.EventDeliveryError), zap.String("topic", p.cfg.Topic), zap.Error(err))
+	}
+}
+
+// Flush produces the pending changes in batches. The records of a failed batch and the ones after it stay
+// pending for the next publish, so a rocket's changes are never produced out of order.
+func (p *Publisher) Flush(ctx context.Context) error {
+	return p.records.Flush(ctx, func(ctx context.Context, records []Record) error {
+		if err := p.send(ctx, records); err != nil {
+			return fmt.Errorf("can't publish %d change events: %w", len(records), err)
+		}
+		return nil
+	})
+}
+
+// send produces the records, with retries when configured
+func (p *Publisher) send(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(struct {
+		Records []Record `json:"records"`
+	}{records})
+	if err != nil {
+		return fmt.Errorf("can't marshal change events: %w", err)
+	}
+	if p.retrier == nil {
+		return p.post(ctx, body)
+	}
+	return p.retrier.Do(ctx, "cdc", func(ctx context.Context) error {
+		return p.post(ctx, body)
+	})
+}
+
+// produceResponse - result of a produce request, a record failed if its offset has an error
+type produceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// post makes one produce attempt, client errors other than 429 are permanent. A batch with a failed record
+// is produced again whole, consumers of Debezium topics expect at-least-once delivery anyway.
+func (p *Publisher) post(ctx context.Context, body []byte) error {
+	target := strings.TrimSuffix(p.cfg.URL, "/") + "/topics/" + url.PathEscape(p.cfg.Topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("can't create produce request: %w", err))
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't produce: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("can't produce: unexpected status %s", resp.Status)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	var produced produceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("can't decode produce response: %w", err)
+	}
+	for _, o := range produced.Offsets {
+		if o.ErrorCode != nil {
+			return fmt.Errorf("can't produce: error %d: %s", *o.ErrorCode, o.Error)
+		}
+	}
+	return nil
+}
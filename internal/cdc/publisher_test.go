@@ -0,0 +1,137 @@
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/batch"
+	"rockets/internal/clock"
+	"rockets/internal/config"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+func TestPublisher(t *testing.T) {
+	var produced []Record
+	status := http.StatusOK
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/rockets.rockets" || r.Header.Get("Content-Type") != contentType {
+			t.Errorf("Unexpected produce request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		var req struct {
+			Records []Record `json:"records"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		produced = append(produced, req.Records...)
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":1,"error_code":null,"error":null}]}`))
+	}))
+	defer proxy.Close()
+
+	now := time.Date(2022, 2, 2, 20, 0, 0, 0, time.UTC)
+	publisher := NewPublisher(config.CDC{URL: proxy.URL, Topic: "rockets.rockets", Interval: time.Second, BatchSize: 1}, zap.NewNop())
+	publisher.UseClock(clock.NewFake(now))
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	svc.AddListener(publisher)
+
+	id := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	at := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+	send := func(number int64, msgType rocket.MessageType, msg rocket.Message) {
+		t.Helper()
+		_, err := svc.ProcessMessage(context.Background(), rocket.TelemetryMessage{
+			Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: number, MessageTime: at.Add(time.Duration(number) * time.Second), MessageType: msgType},
+			Message:  msg,
+		})
+		if err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	speed, mission, typ := rocket.Speed(500), rocket.Mission("ARTEMIS"), rocket.RocketType("Falcon-9")
+	send(1, rocket.MessageTypeLaunched, rocket.Message{Type: &typ, LaunchSpeed: &speed, Mission: &mission})
+
+	// a failed publish keeps the change for the next one
+	status = http.StatusServiceUnavailable
+	if err := publisher.Flush(context.Background()); err == nil {
+		t.Fatalf("Expected the publish to fail")
+	}
+	status = http.StatusOK
+	by := rocket.Speed(1000)
+	send(2, rocket.MessageTypeSpeedIncreased, rocket.Message{By: &by})
+	if err := publisher.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if len(produced) != 2 {
+		t.Fatalf("Expected both changes produced, got %+v", produced)
+	}
+	created, updated := produced[0], produced[1]
+	if created.Key.ID != id.String() || created.Value.Op != OpCreate || created.Value.Before != nil || created.Value.After.CurrentSpeed != 500 {
+		t.Errorf("Expected the create of the rocket, got %+v", created)
+	}
+	if updated.Value.Op != OpUpdate || updated.Value.Before.CurrentSpeed != 500 || updated.Value.After.CurrentSpeed != 1500 {
+		t.Errorf("Expected the update with the before and after images, got %+v", updated)
+	}
+	source := updated.Value.Source
+	if source.Name != "rockets.rockets" || source.TsMs != at.Add(2*time.Second).UnixMilli() || source.MessageNumber != 2 || updated.Value.TsMs != now.UnixMilli() {
+		t.Errorf("Expected the source of the change, got %+v at %d", source, updated.Value.TsMs)
+	}
+}
+
+func TestPublisher_Rewrites(t *testing.T) {
+	publisher := NewPublisher(config.CDC{Topic: "rockets.rockets", Interval: time.Second, BatchSize: 10}, zap.NewNop())
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	svc.UseHistory(rocket.NewInMemoryHistoryStore())
+	svc.AddListener(publisher)
+
+	id, other := uuid.New(), uuid.New()
+	at := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+	speed, mission, typ, by := rocket.Speed(500), rocket.Mission("ARTEMIS"), rocket.RocketType("Falcon-9"), rocket.Speed(100)
+	for _, channel := range []uuid.UUID{id, other} {
+		for number := int64(1); number <= 2; number++ {
+			msg := rocket.TelemetryMessage{
+				Metadata: rocket.MessageMetadata{Channel: channel, MessageNumber: number, MessageTime: at.Add(time.Duration(number) * time.Second), MessageType: rocket.MessageTypeSpeedIncreased},
+				Message:  rocket.Message{By: &by},
+			}
+			if number == 1 {
+				msg.Metadata.MessageType, msg.Message = rocket.MessageTypeLaunched, rocket.Message{Type: &typ, LaunchSpeed: &speed, Mission: &mission}
+			}
+			if _, err := svc.ProcessMessage(context.Background(), msg); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+		}
+	}
+	publisher.records = batch.NewQueue[Record](10, maxPendingRecords)
+
+	if _, err := svc.RollbackRocket(context.Background(), id, 2); err != nil {
+		t.Fatalf("RollbackRocket failed: %v", err)
+	}
+	if _, err := svc.MergeChannels(context.Background(), id, other); err != nil {
+		t.Fatalf("MergeChannels failed: %v", err)
+	}
+
+	var pending []Record
+	_ = publisher.records.Flush(context.Background(), func(_ context.Context, records []Record) error {
+		pending = append(pending, records...)
+		return nil
+	})
+	if len(pending) != 3 {
+		t.Fatalf("Expected the rollback and both sides of the merge queued, got %+v", pending)
+	}
+	rollback, merged, deleted := pending[0].Value, pending[1].Value, pending[2].Value
+	if rollback.Op != OpUpdate || rollback.Source.Rewrite != "rollback" || rollback.Before.CurrentSpeed != 600 || rollback.After.CurrentSpeed != 500 {
+		t.Errorf("Expected the rollback as an update, got %+v", rollback)
+	}
+	if merged.Op != OpUpdate || merged.Source.Rewrite != "merge" || merged.After.ID != id.String() {
+		t.Errorf("Expected the merged rocket updated, got %+v", merged)
+	}
+	if deleted.Op != OpDelete || deleted.After != nil || deleted.Before.ID != other.String() || pending[2].Key.ID != other.String() {
+		t.Errorf("Expected the merged channel deleted, got %+v", deleted)
+	}
+}
@@ -0,0 +1,56 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock - source of the current time, injected so time-dependent behaviour can be tested deterministically
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+var _ Clock = Real{}
+
+// Real - clock reading the system time
+type Real struct{}
+
+// Now returns the system time
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+var _ Clock = (*Fake)(nil)
+
+// Fake - clock that only moves when advanced, for tests and simulations
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake creates a fake clock stopped at the given time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the time the clock is stopped at
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the clock forward by d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Set moves the clock to the given time
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
@@ -0,0 +1,1057 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"rockets/internal/encrypt"
+	"rockets/internal/logging"
+	"rockets/internal/partition"
+	"rockets/internal/rocket"
+	"rockets/internal/secrets"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config - service configuration, loaded from ROCKETS_* environment variables
+type Config struct {
+	Log       Log
+	Listen    Listen
+	Network   Network
+	Store     Store
+	History   History
+	Export    Export
+	Leader    Leader
+	Partition Partition
+	Auth      Auth
+	Quota     Quota
+	Ingest    Ingest
+	Sort      Sort
+	Listing   Listing
+	Cache     Cache
+	SMTP      SMTP
+	Reports   Reports
+	UI        UI
+	Capture   Capture
+	Alerts    Alerts
+	Retry     Retry
+	TSDB      TSDB
+	OTLP      OTLP
+	Warehouse Warehouse
+	CDC       CDC
+	Consumer  Consumer
+	Health    Health
+	Secrets   Secrets
+}
+
+// Log - logging of the whole binary
+type Log struct {
+	// Level - debug, info, warn or error
+	Level string
+	// Levels - level overrides by component (http, rocket, store, ...), adjustable at runtime like Level, parsed
+	// from the component=level pairs
+	Levels map[string]zapcore.Level
+	// Format - json or console
+	Format string
+	// SamplingInitial, SamplingThereafter - per second, the first SamplingInitial entries with the same
+	// message are logged and then every SamplingThereafter-th; SamplingInitial 0 disables sampling
+	SamplingInitial    int
+	SamplingThereafter int
+	// StoreWrites - log a change summary of every state written to the store
+	StoreWrites bool
+
+	levels map[string]string
+}
+
+// Listen - addresses the HTTP server accepts connections on
+type Listen struct {
+	// Addrs - TCP addresses, empty listens on the port of the -port flag
+	Addrs []string
+	// UnixSocket - path of a Unix domain socket, e.g. for a sidecar ingesting on the same host, empty for none
+	UnixSocket string
+	// UnixSocketMode - octal permissions of the socket file
+	UnixSocketMode string
+	// BasePath - prefix of the API routes and the dashboard, e.g. /rockets, empty serves them at the root
+	BasePath string
+}
+
+// Network - network-level access control
+type Network struct {
+	// AllowIngest, AllowAPI, AllowAdmin - CIDR allowlists per route group, empty means open
+	AllowIngest []string
+	AllowAPI    []string
+	AllowAdmin  []string
+	// DenylistFile - file with denied CIDRs, reloaded when it changes
+	DenylistFile   string
+	DenylistReload time.Duration
+}
+
+// Store - rocket state storage
+type Store struct {
+	// File - append-only file persisting rocket states, empty keeps them in memory only
+	File string
+	// Repair - fix repairable inconsistencies found by the startup warm-up
+	Repair bool
+	// Cipher - encrypts the records of File with the keys of ROCKETS_STORE_ENCRYPTION_KEYS, comma-separated and
+	// base64 encoded, or of the file ROCKETS_STORE_ENCRYPTION_KEY_FILE, one per line; the first one encrypts and
+	// all decrypt. Nil writes them as plain JSON.
+	Cipher *encrypt.Cipher
+	// EncryptionMigrate - plain records of File are read and encrypted by the compaction instead of failing the
+	// start, to encrypt a file written without keys
+	EncryptionMigrate bool
+	// CommitWindow - writes within the window are coalesced into one write and sync, 0 writes every update
+	CommitWindow time.Duration
+	// CommitWait - saves wait for the sync of their batch, otherwise up to a window of updates may be lost
+	CommitWait bool
+	// NamesFile - file persisting the names of the rockets, empty keeps them in memory only
+	NamesFile string
+	// FleetsFile - file persisting the fleets, empty keeps them in memory only
+	FleetsFile string
+	// PinsFile - file persisting the pins added at runtime, empty keeps them in memory only
+	PinsFile string
+	// WatchlistsFile - file persisting the watchlists, empty keeps them in memory only
+	WatchlistsFile string
+	// IncidentsFile - file persisting the incidents, empty keeps them in memory only
+	IncidentsFile string
+	// MaintenanceFile - file persisting the maintenance windows, empty keeps them in memory only
+	MaintenanceFile string
+	// RegistrationsFile - file persisting the rockets registered ahead of their telemetry, empty keeps them in
+	// memory only
+	RegistrationsFile string
+
+	encryptionKeys    string
+	encryptionKeyFile string
+}
+
+// Leader - hot/standby election between instances sharing a lock file
+type Leader struct {
+	// LockFile - file locked by the leader, empty runs the instance as the only leader
+	LockFile string
+	// Retry - how often a standby tries to take over and refreshes its store
+	Retry time.Duration
+	// Lease - Kubernetes Lease the replicas compete for to run the singleton background jobs, empty runs them
+	// on every replica
+	Lease          string
+	LeaseNamespace string
+	// LeaseDuration - how long the lease is held without being renewed
+	LeaseDuration time.Duration
+}
+
+// Partition - share of the channel hash space ingested by the replica
+type Partition struct {
+	// Count - partitions the channels are hashed into, 0 ingests every channel on every replica
+	Count int
+	// Static - owner of the partitions the replica ingests, with the base URLs of the replicas owning the others,
+	// nil when Count is 0
+	Static *partition.Static
+	// Ring - name of the consistent hashing ring the replicas of a Kubernetes deployment spread the channels
+	// over, instead of configured partitions; empty disables the ring
+	Ring string
+	// URL - base URL the other replicas of the ring forward messages to
+	URL string
+	// RingDuration - how long a replica stays on the ring without renewing its membership
+	RingDuration time.Duration
+	// Secret - secret the replicas of the ring sign their handoffs with
+	Secret string
+
+	// owned - partitions the replica ingests, comma-separated numbers and ranges like 0-3,8, or "ordinal" for the
+	// ordinal of the StatefulSet pod
+	owned string
+	// peers - partitions and ranges to the base URL of the replica owning them, messages of channels owned by
+	// another replica are forwarded there; {partition} in a URL stands for the partition number
+	peers map[string]string
+}
+
+// Auth - API key authentication of producers, disabled when no keys are configured
+type Auth struct {
+	// APIKeys - API key to producer name
+	APIKeys map[string]string
+	// Scopes - producer name to the rockets its keys are limited to, "|"-separated mission:NAME and channel:UUID
+	Scopes map[string]string
+	// SigningKeys - producer name to the base64 encoded ed25519 public key its payloads are signed with
+	SigningKeys map[string]string
+	// Reads - the read API requires an API key as well
+	Reads bool
+	// Public - public read-only mode, callers without an API key read rockets with the PublicRedact fields removed
+	Public       bool
+	PublicRedact []string
+}
+
+// Quota - daily limits per producer, 0 means unlimited
+type Quota struct {
+	DailyMessages int64
+	DailyBytes    int64
+}
+
+// History - tiering of the per-rocket event history
+type History struct {
+	// ColdDir - directory the events older than HotAge are moved to, empty keeps the whole history in memory
+	ColdDir string
+	HotAge  time.Duration
+	// TierInterval - how often old events are moved to ColdDir
+	TierInterval time.Duration
+	// Heartbeats - record the heartbeat messages in the history, which leaves them out by default
+	Heartbeats bool
+	// PinnedChannels, PinnedMissions - rockets whose events stay hot and whose gaps wait without a time limit
+	PinnedChannels []string
+	PinnedMissions []string
+}
+
+// Export - periodic Parquet export of the event history for analytics
+type Export struct {
+	// Dir - directory the partitions are written to, e.g. a mounted bucket, empty disables the export
+	Dir      string
+	Interval time.Duration
+}
+
+// Ingest - telemetry processing settings
+type Ingest struct {
+	// QuarantineAfterFailures - consecutive validation failures before a channel is quarantined, 0 disables it
+	QuarantineAfterFailures int
+	// ReorderWindow - messages held per channel while waiting for a gap in the sequence, 0 disables reordering
+	ReorderWindow int
+	// ReorderMaxWait - how long a gap may stay open before the held messages are applied anyway
+	ReorderMaxWait time.Duration
+	// Actors - serialize the updates of every rocket on its own goroutine instead of locking
+	Actors bool
+	// ActorMailbox - updates queued per rocket actor before senders block
+	ActorMailbox int
+	// ActorIdleTimeout - how long an actor stays idle before it exits
+	ActorIdleTimeout time.Duration
+	// ProvisionalState - apply messages arriving before the launch to a PARTIAL state, back-filled by a late launch
+	ProvisionalState bool
+	// SpeedUnderflow - handling of a speed decrease below zero: clamp, reject or anomalous
+	SpeedUnderflow rocket.UnderflowPolicy
+	// DedupPolicy - check of the message numbers: gap-tolerant, strict or windowed-exact
+	DedupPolicy string
+	// DedupWindow - numbers below the highest one of a rocket tracked by the windowed-exact policy
+	DedupWindow int
+	// Shadow - run a candidate state machine alongside the service and report where it diverges
+	Shadow bool
+	// ShadowSpeedUnderflow - speed underflow policy of the candidate state machine
+	ShadowSpeedUnderflow rocket.UnderflowPolicy
+	// ShadowProvisionalState - the candidate state machine applies messages before the launch to a PARTIAL state
+	ShadowProvisionalState bool
+	// DebugTraceMax - channels whose processing can be traced at the same time
+	DebugTraceMax int
+	// DebugTraceTTL - default and longest time a channel is traced
+	DebugTraceTTL time.Duration
+	// MaxBodyBytes - largest request body accepted once decompressed
+	MaxBodyBytes int64
+	// DeadLetters - messages that could not be applied kept for redriving, 0 disables the dead-letter queue
+	DeadLetters int
+	// NoisyRate - messages per minute over the last minute above which a rocket is listed as noisy
+	NoisyRate float64
+	// LatencyBudget - p99 latency from the message time to the applied state above which an alert is sent, 0
+	// disables the alert
+	LatencyBudget time.Duration
+	// LatencyWindow - span of the messages the latency percentiles are computed over
+	LatencyWindow time.Duration
+	// LatencyCheckInterval - how often the latency is checked against the budget
+	LatencyCheckInterval time.Duration
+	// GapReportInterval - how often the channels with missing messages are logged, 0 disables the report
+	GapReportInterval time.Duration
+	// GapRetention - how long a gap is reported before it is dropped as not expected to be filled anymore
+	GapRetention time.Duration
+	// RetransmitURL - producer endpoint asked to send the messages of lasting gaps again, empty disables the requests
+	RetransmitURL string
+	// RetransmitAfter - how long a gap stays open before its retransmission is requested
+	RetransmitAfter time.Duration
+	// UnnumberedChannels, UnnumberedProducers - channels and producers sending messages without numbers, to the
+	// window duplicates of their messages are detected within, parsed from the channel=10m pairs
+	UnnumberedChannels  map[uuid.UUID]time.Duration
+	UnnumberedProducers map[string]time.Duration
+
+	unnumberedChannels  map[string]string
+	unnumberedProducers map[string]string
+}
+
+// SMTP - outgoing mail server settings
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Reports - scheduled mission digest settings
+type Reports struct {
+	Enabled bool
+	Daily   bool
+	Weekly  bool
+	// Hour - hour of the day (UTC) when digests are sent
+	Hour int
+}
+
+// Sort - ordering of the rocket listings
+type Sort struct {
+	// Locale - BCP 47 locale the types and missions are collated by, e.g. en or sv
+	Locale string
+	// CaseSensitive - sort "artemis" apart from "ARTEMIS" instead of together
+	CaseSensitive bool
+}
+
+// Listing - read path of the rocket listings
+type Listing struct {
+	// MaxStaleness - how old a listing may be, served from a periodically refreshed snapshot instead of the live
+	// store; 0 lists the live store
+	MaxStaleness time.Duration
+}
+
+// Cache - caching of the rocket listing and the fleet stats polled by the dashboards
+type Cache struct {
+	// TTL - how long the in-process micro-cache keeps a response, 0 disables it
+	TTL time.Duration
+	// MaxAge - max-age of the Cache-Control header, how long clients may reuse a response
+	MaxAge time.Duration
+}
+
+// UI - embedded dashboard settings
+type UI struct {
+	Enabled bool
+}
+
+// Capture - debug capture of full request and response bodies
+type Capture struct {
+	// SampleRate - share of requests captured, 0..1
+	SampleRate float64
+	// Channels - rocket channels whose requests are always captured
+	Channels []string
+	// Size - number of latest exchanges kept
+	Size int
+}
+
+// Alerts - delivery of alerts to the on-call engineer
+type Alerts struct {
+	// WebhookURL - endpoint receiving alerts as JSON, empty disables alerting
+	WebhookURL string
+	// PagerDutyRoutingKey - integration key of the PagerDuty service the rocket alerts (explosions and lost
+	// rockets) open incidents in, empty disables PagerDuty
+	PagerDutyRoutingKey string
+	// PagerDutyURL - endpoint of the PagerDuty Events API v2
+	PagerDutyURL string
+	// OpsgenieAPIKey - key of the Opsgenie API integration the rocket alerts create alerts with, empty disables
+	// Opsgenie
+	OpsgenieAPIKey string
+	// OpsgenieURL - base URL of the Opsgenie API, of the region of the account
+	OpsgenieURL string
+	// LostAfter - how long a rocket that has not exploded may send no telemetry before it is alerted as lost, 0
+	// disables the alerts
+	LostAfter time.Duration
+}
+
+// Retry - retries of failed calls to outbound integrations (alert webhook, pager services, SMTP, time-series database, retransmission requests)
+type Retry struct {
+	// MaxAttempts - calls including the first one
+	MaxAttempts int
+	// InitialBackoff - wait before the first retry, doubled for every next one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter - randomized share (0..1) of every wait
+	Jitter float64
+}
+
+// TSDB - periodic push of rocket samples to a time-series database
+type TSDB struct {
+	// URL - InfluxDB line protocol write endpoint, empty disables the push
+	URL string
+	// Token - InfluxDB API token, sent as "Authorization: Token <token>"
+	Token    string
+	Interval time.Duration
+}
+
+// OTLP - export of the service's own metrics, logs and traces to an OpenTelemetry collector
+type OTLP struct {
+	// Endpoint - base URL of the collector's OTLP/HTTP receiver, empty disables the export
+	Endpoint string
+	// Headers - extra request headers, e.g. for authentication
+	Headers  map[string]string
+	Interval time.Duration
+	Metrics  bool
+	Logs     bool
+	// Traces - record spans of ingested messages, continuing the producer's traceparent
+	Traces bool
+}
+
+// Warehouse - scheduled loads of the applied events into a BigQuery table
+type Warehouse struct {
+	// Project - Google Cloud project of the table, empty disables the sink
+	Project string
+	Dataset string
+	Table   string
+	// TokenFile - file with the OAuth access token, re-read on every request so an external process can refresh it
+	TokenFile string
+	// Endpoint - base URL of the BigQuery API, e.g. of an emulator
+	Endpoint  string
+	Interval  time.Duration
+	BatchSize int
+}
+
+// CDC - change data capture of the rocket states, produced to a Kafka topic through a Kafka REST Proxy
+type CDC struct {
+	// URL - base URL of the Kafka REST Proxy, empty disables the publishing
+	URL string
+	// Topic - topic the change events are produced to, also the source name in the envelopes
+	Topic     string
+	Interval  time.Duration
+	BatchSize int
+}
+
+// Consumer - ingestion of telemetry messages from a Kafka topic through a Kafka REST Proxy
+type Consumer struct {
+	// URL - base URL of the Kafka REST Proxy, empty disables the consumer
+	URL string
+	// Topic - topic of the telemetry messages, in the format of POST /messages
+	Topic string
+	// Group - consumer group of the replicas, every message is applied by one of them
+	Group string
+	// PollTimeout - how long a fetch of records waits for new ones
+	PollTimeout time.Duration
+}
+
+// Health - checks of the store and the ingestion, the consumers pause while they fail
+type Health struct {
+	// Interval - how often the checks run
+	Interval time.Duration
+	// StallAfter - how long a store write or a rocket update may run before the checks fail
+	StallAfter time.Duration
+}
+
+// Secrets - secrets referenced by the values of the variables
+type Secrets struct {
+	// RenewInterval - how often the Vault token and the leases of dynamic secrets are renewed, 0 never renews them
+	RenewInterval time.Duration
+}
+
+// Load reads the configuration from the environment, falling back to defaults for unset variables. Values
+// referencing a secret, e.g. "vault:secret/data/rockets#smtp_password", are replaced by the secret fetched by
+// the resolver.
+func Load(resolver *secrets.Resolver) (*Config, error) {
+	l := loader{resolver: resolver}
+	cfg := &Config{
+		Log: Log{
+			Level:  l.string("ROCKETS_LOG_LEVEL", "info"),
+			levels: l.mapping("ROCKETS_LOG_LEVELS"),
+			Format: l.string("ROCKETS_LOG_FORMAT", "json"),
+
+			SamplingInitial:    l.int("ROCKETS_LOG_SAMPLING_INITIAL", 100),
+			SamplingThereafter: l.int("ROCKETS_LOG_SAMPLING_THEREAFTER", 100),
+			StoreWrites:        l.bool("ROCKETS_LOG_STORE_WRITES", false),
+		},
+		Listen: Listen{
+			Addrs:          l.list("ROCKETS_LISTEN_ADDRS"),
+			UnixSocket:     l.string("ROCKETS_LISTEN_UNIX_SOCKET", ""),
+			UnixSocketMode: l.string("ROCKETS_LISTEN_UNIX_SOCKET_MODE", "0660"),
+			BasePath:       l.string("ROCKETS_API_BASE_PATH", ""),
+		},
+		Network: Network{
+			AllowIngest:    l.list("ROCKETS_ALLOW_INGEST"),
+			AllowAPI:       l.list("ROCKETS_ALLOW_API"),
+			AllowAdmin:     l.list("ROCKETS_ALLOW_ADMIN"),
+			DenylistFile:   l.string("ROCKETS_DENYLIST_FILE", ""),
+			DenylistReload: l.duration("ROCKETS_DENYLIST_RELOAD", 10*time.Second),
+		},
+		Store: Store{
+			File:   l.string("ROCKETS_STORE_FILE", ""),
+			Repair: l.bool("ROCKETS_STORE_REPAIR", false),
+
+			EncryptionMigrate: l.bool("ROCKETS_STORE_ENCRYPTION_MIGRATE", false),
+			encryptionKeys:    l.string("ROCKETS_STORE_ENCRYPTION_KEYS", ""),
+			encryptionKeyFile: l.string("ROCKETS_STORE_ENCRYPTION_KEY_FILE", ""),
+
+			CommitWindow: l.duration("ROCKETS_STORE_COMMIT_WINDOW", 0),
+			CommitWait:   l.bool("ROCKETS_STORE_COMMIT_WAIT", true),
+			NamesFile:    l.string("ROCKETS_NAMES_FILE", ""),
+			FleetsFile:   l.string("ROCKETS_FLEETS_FILE", ""),
+			PinsFile:     l.string("ROCKETS_PINS_FILE", ""),
+
+			RegistrationsFile: l.string("ROCKETS_REGISTRATIONS_FILE", ""),
+			IncidentsFile:     l.string("ROCKETS_INCIDENTS_FILE", ""),
+			MaintenanceFile:   l.string("ROCKETS_MAINTENANCE_FILE", ""),
+			WatchlistsFile:    l.string("ROCKETS_WATCHLISTS_FILE", ""),
+		},
+		History: History{
+			ColdDir:      l.string("ROCKETS_HISTORY_COLD_DIR", ""),
+			HotAge:       l.duration("ROCKETS_HISTORY_HOT_AGE", 7*24*time.Hour),
+			TierInterval: l.duration("ROCKETS_HISTORY_TIER_INTERVAL", time.Hour),
+			Heartbeats:   l.bool("ROCKETS_HISTORY_HEARTBEATS", false),
+
+			PinnedChannels: l.list("ROCKETS_PINNED_CHANNELS"),
+			PinnedMissions: l.list("ROCKETS_PINNED_MISSIONS"),
+		},
+		Export: Export{
+			Dir:      l.string("ROCKETS_EXPORT_DIR", ""),
+			Interval: l.duration("ROCKETS_EXPORT_INTERVAL", time.Hour),
+		},
+		Leader: Leader{
+			LockFile: l.string("ROCKETS_LEADER_LOCK_FILE", ""),
+			Retry:    l.duration("ROCKETS_LEADER_RETRY", time.Second),
+
+			Lease:          l.string("ROCKETS_K8S_LEASE", ""),
+			LeaseNamespace: l.string("ROCKETS_K8S_LEASE_NAMESPACE", ""),
+			LeaseDuration:  l.duration("ROCKETS_K8S_LEASE_DURATION", 15*time.Second),
+		},
+		Partition: Partition{
+			Count: l.int("ROCKETS_PARTITION_COUNT", 0),
+			owned: l.string("ROCKETS_PARTITION_OWNED", ""),
+			peers: l.mapping("ROCKETS_PARTITION_PEERS"),
+
+			Ring:         l.string("ROCKETS_PARTITION_RING", ""),
+			URL:          l.string("ROCKETS_PARTITION_URL", ""),
+			RingDuration: l.duration("ROCKETS_PARTITION_RING_DURATION", 15*time.Second),
+			Secret:       l.string("ROCKETS_PARTITION_SECRET", ""),
+		},
+		Auth: Auth{
+			APIKeys:     l.mapping("ROCKETS_API_KEYS"),
+			Scopes:      l.mapping("ROCKETS_API_SCOPES"),
+			SigningKeys: l.mapping("ROCKETS_SIGNING_KEYS"),
+			Reads:       l.bool("ROCKETS_AUTH_READS", false),
+
+			Public:       l.bool("ROCKETS_PUBLIC", false),
+			PublicRedact: l.list("ROCKETS_PUBLIC_REDACT"),
+		},
+		Quota: Quota{
+			DailyMessages: int64(l.int("ROCKETS_QUOTA_DAILY_MESSAGES", 0)),
+			DailyBytes:    int64(l.int("ROCKETS_QUOTA_DAILY_BYTES", 0)),
+		},
+		Ingest: Ingest{
+			QuarantineAfterFailures: l.int("ROCKETS_QUARANTINE_AFTER_FAILURES", 5),
+			ReorderWindow:           l.int("ROCKETS_REORDER_WINDOW", 0),
+			ReorderMaxWait:          l.duration("ROCKETS_REORDER_MAX_WAIT", 5*time.Second),
+			Actors:                  l.bool("ROCKETS_ACTORS", false),
+			ActorMailbox:            l.int("ROCKETS_ACTOR_MAILBOX", 64),
+			ActorIdleTimeout:        l.duration("ROCKETS_ACTOR_IDLE_TIMEOUT", time.Minute),
+			ProvisionalState:        l.bool("ROCKETS_PROVISIONAL_STATE", false),
+			SpeedUnderflow:          rocket.UnderflowPolicy(l.string("ROCKETS_SPEED_UNDERFLOW", "clamp")),
+			DedupPolicy:             l.string("ROCKETS_DEDUP_POLICY", "gap-tolerant"),
+			DedupWindow:             l.int("ROCKETS_DEDUP_WINDOW", 1024),
+			Shadow:                  l.bool("ROCKETS_SHADOW", false),
+			ShadowSpeedUnderflow:    rocket.UnderflowPolicy(l.string("ROCKETS_SHADOW_SPEED_UNDERFLOW", "clamp")),
+			ShadowProvisionalState:  l.bool("ROCKETS_SHADOW_PROVISIONAL_STATE", false),
+			DebugTraceMax:           l.int("ROCKETS_DEBUG_TRACE_MAX", 16),
+			DebugTraceTTL:           l.duration("ROCKETS_DEBUG_TRACE_TTL", 15*time.Minute),
+			DeadLetters:             l.int("ROCKETS_DEAD_LETTERS", 1000),
+			MaxBodyBytes:            int64(l.int("ROCKETS_MAX_BODY_BYTES", 1<<20)),
+			NoisyRate:               l.float("ROCKETS_NOISY_RATE", 60),
+			LatencyBudget:           l.duration("ROCKETS_LATENCY_BUDGET", 0),
+			LatencyWindow:           l.duration("ROCKETS_LATENCY_WINDOW", 5*time.Minute),
+			LatencyCheckInterval:    l.duration("ROCKETS_LATENCY_CHECK_INTERVAL", 30*time.Second),
+			GapReportInterval:       l.duration("ROCKETS_GAP_REPORT_INTERVAL", 5*time.Minute),
+			GapRetention:            l.duration("ROCKETS_GAP_RETENTION", 24*time.Hour),
+			RetransmitURL:           l.string("ROCKETS_RETRANSMIT_URL", ""),
+			RetransmitAfter:         l.duration("ROCKETS_RETRANSMIT_AFTER", time.Minute),
+			unnumberedChannels:      l.mapping("ROCKETS_UNNUMBERED_CHANNELS"),
+			unnumberedProducers:     l.mapping("ROCKETS_UNNUMBERED_PRODUCERS"),
+		},
+		SMTP: SMTP{
+			Host:     l.string("ROCKETS_SMTP_HOST", ""),
+			Port:     l.int("ROCKETS_SMTP_PORT", 587),
+			Username: l.string("ROCKETS_SMTP_USERNAME", ""),
+			Password: l.string("ROCKETS_SMTP_PASSWORD", ""),
+			From:     l.string("ROCKETS_SMTP_FROM", ""),
+			To:       l.list("ROCKETS_SMTP_TO"),
+		},
+		Reports: Reports{
+			Enabled: l.bool("ROCKETS_REPORTS_ENABLED", false),
+			Daily:   l.bool("ROCKETS_REPORTS_DAILY", true),
+			Weekly:  l.bool("ROCKETS_REPORTS_WEEKLY", true),
+			Hour:    l.int("ROCKETS_REPORTS_HOUR", 6),
+		},
+		Sort: Sort{
+			Locale:        l.string("ROCKETS_SORT_LOCALE", "und"),
+			CaseSensitive: l.bool("ROCKETS_SORT_CASE_SENSITIVE", false),
+		},
+		Listing: Listing{
+			MaxStaleness: l.duration("ROCKETS_LIST_MAX_STALENESS", 0),
+		},
+		Cache: Cache{
+			TTL:    l.duration("ROCKETS_CACHE_TTL", 0),
+			MaxAge: l.duration("ROCKETS_CACHE_MAX_AGE", 0),
+		},
+		UI: UI{
+			Enabled: l.bool("ROCKETS_UI_ENABLED", true),
+		},
+		Capture: Capture{
+			SampleRate: l.float("ROCKETS_CAPTURE_SAMPLE_RATE", 0),
+			Channels:   l.list("ROCKETS_CAPTURE_CHANNELS"),
+			Size:       l.int("ROCKETS_CAPTURE_SIZE", 100),
+		},
+		Alerts: Alerts{
+			WebhookURL: l.string("ROCKETS_ALERT_WEBHOOK_URL", ""),
+
+			PagerDutyRoutingKey: l.string("ROCKETS_PAGERDUTY_ROUTING_KEY", ""),
+			PagerDutyURL:        l.string("ROCKETS_PAGERDUTY_URL", "https://events.pagerduty.com/v2/enqueue"),
+			OpsgenieAPIKey:      l.string("ROCKETS_OPSGENIE_API_KEY", ""),
+			OpsgenieURL:         l.string("ROCKETS_OPSGENIE_URL", "https://api.opsgenie.com"),
+			LostAfter:           l.duration("ROCKETS_LOST_AFTER", 0),
+		},
+		Retry: Retry{
+			MaxAttempts:    l.int("ROCKETS_RETRY_MAX_ATTEMPTS", 3),
+			InitialBackoff: l.duration("ROCKETS_RETRY_INITIAL_BACKOFF", 500*time.Millisecond),
+			MaxBackoff:     l.duration("ROCKETS_RETRY_MAX_BACKOFF", 30*time.Second),
+			Jitter:         l.float("ROCKETS_RETRY_JITTER", 0.2),
+		},
+		TSDB: TSDB{
+			URL:      l.string("ROCKETS_TSDB_URL", ""),
+			Token:    l.string("ROCKETS_TSDB_TOKEN", ""),
+			Interval: l.duration("ROCKETS_TSDB_INTERVAL", 10*time.Second),
+		},
+		OTLP: OTLP{
+			Endpoint: l.string("ROCKETS_OTLP_ENDPOINT", ""),
+			Headers:  l.mapping("ROCKETS_OTLP_HEADERS"),
+			Interval: l.duration("ROCKETS_OTLP_INTERVAL", 10*time.Second),
+			Metrics:  l.bool("ROCKETS_OTLP_METRICS", true),
+			Logs:     l.bool("ROCKETS_OTLP_LOGS", true),
+			Traces:   l.bool("ROCKETS_OTLP_TRACES", true),
+		},
+		Warehouse: Warehouse{
+			Project:   l.string("ROCKETS_WAREHOUSE_PROJECT", ""),
+			Dataset:   l.string("ROCKETS_WAREHOUSE_DATASET", "rockets"),
+			Table:     l.string("ROCKETS_WAREHOUSE_TABLE", "events"),
+			TokenFile: l.string("ROCKETS_WAREHOUSE_TOKEN_FILE", ""),
+			Endpoint:  l.string("ROCKETS_WAREHOUSE_ENDPOINT", "https://bigquery.googleapis.com"),
+			Interval:  l.duration("ROCKETS_WAREHOUSE_INTERVAL", time.Minute),
+			BatchSize: l.int("ROCKETS_WAREHOUSE_BATCH_SIZE", 500),
+		},
+		CDC: CDC{
+			URL:       l.string("ROCKETS_CDC_URL", ""),
+			Topic:     l.string("ROCKETS_CDC_TOPIC", "rockets.rockets"),
+			Interval:  l.duration("ROCKETS_CDC_INTERVAL", time.Second),
+			BatchSize: l.int("ROCKETS_CDC_BATCH_SIZE", 500),
+		},
+		Consumer: Consumer{
+			URL:         l.string("ROCKETS_CONSUMER_URL", ""),
+			Topic:       l.string("ROCKETS_CONSUMER_TOPIC", "rockets.telemetry"),
+			Group:       l.string("ROCKETS_CONSUMER_GROUP", "rockets"),
+			PollTimeout: l.duration("ROCKETS_CONSUMER_POLL_TIMEOUT", time.Second),
+		},
+		Health: Health{
+			Interval:   l.duration("ROCKETS_HEALTH_INTERVAL", time.Second),
+			StallAfter: l.duration("ROCKETS_HEALTH_STALL_AFTER", 10*time.Second),
+		},
+		Secrets: Secrets{
+			RenewInterval: l.duration("ROCKETS_SECRETS_RENEW_INTERVAL", 0),
+		},
+	}
+	if l.err != nil {
+		return nil, l.err
+	}
+	if cfg.Auth.PublicRedact == nil {
+		cfg.Auth.PublicRedact = []string{"reason", "explosionReason", "anomaly", "lastProcessedMessageNumber"}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) validate() error {
+	if c.Log.SamplingInitial < 0 || c.Log.SamplingThereafter < 0 {
+		return fmt.Errorf("ROCKETS_LOG_SAMPLING_INITIAL and ROCKETS_LOG_SAMPLING_THEREAFTER must not be negative")
+	}
+	if _, err := strconv.ParseUint(c.Listen.UnixSocketMode, 8, 9); err != nil {
+		return fmt.Errorf("ROCKETS_LISTEN_UNIX_SOCKET_MODE must be octal permissions, got %q", c.Listen.UnixSocketMode)
+	}
+	if p := c.Listen.BasePath; p != "" && (!strings.HasPrefix(p, "/") || strings.HasSuffix(p, "/")) {
+		return fmt.Errorf("ROCKETS_API_BASE_PATH must start and not end with a slash, got %q", p)
+	}
+	if c.Network.DenylistReload <= 0 {
+		return fmt.Errorf("ROCKETS_DENYLIST_RELOAD must be positive, got %s", c.Network.DenylistReload)
+	}
+	c.Log.Levels = make(map[string]zapcore.Level, len(c.Log.levels))
+	for component, level := range c.Log.levels {
+		if !logging.ValidComponent(component) {
+			return fmt.Errorf("invalid ROCKETS_LOG_LEVELS component %q, must be one of %s", component, strings.Join(logging.Components, ", "))
+		}
+		lvl, err := zapcore.ParseLevel(level)
+		if err != nil || level == "" {
+			return fmt.Errorf("invalid ROCKETS_LOG_LEVELS level %q of %s: must be debug, info, warn or error", level, component)
+		}
+		c.Log.Levels[component] = lvl
+	}
+	if c.Store.encryptionKeys != "" && c.Store.encryptionKeyFile != "" {
+		return fmt.Errorf("ROCKETS_STORE_ENCRYPTION_KEYS and ROCKETS_STORE_ENCRYPTION_KEY_FILE are exclusive")
+	}
+	if c.Store.encryptionKeys != "" || c.Store.encryptionKeyFile != "" {
+		keys, err := encrypt.ParseKeys(c.Store.encryptionKeys)
+		if c.Store.encryptionKeyFile != "" {
+			keys, err = encrypt.ReadKeys(c.Store.encryptionKeyFile)
+		}
+		if err == nil {
+			c.Store.Cipher, err = encrypt.New(keys)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid store encryption keys: %w", err)
+		}
+	}
+	if c.Store.EncryptionMigrate {
+		if c.Store.Cipher == nil {
+			return fmt.Errorf("ROCKETS_STORE_ENCRYPTION_MIGRATE requires ROCKETS_STORE_ENCRYPTION_KEYS or ROCKETS_STORE_ENCRYPTION_KEY_FILE")
+		}
+		c.Store.Cipher.AcceptPlain()
+	}
+	if c.Leader.Lease != "" && c.Leader.LeaseDuration < 3*time.Second {
+		return fmt.Errorf("ROCKETS_K8S_LEASE_DURATION must be at least 3s, got %s", c.Leader.LeaseDuration)
+	}
+	if c.Partition.Count < 0 {
+		return fmt.Errorf("ROCKETS_PARTITION_COUNT must not be negative, got %d", c.Partition.Count)
+	}
+	if c.Partition.Count > 0 {
+		if c.Partition.owned == "" {
+			return fmt.Errorf("ROCKETS_PARTITION_OWNED is required with ROCKETS_PARTITION_COUNT")
+		}
+		owned, err := partition.ParseOwned(c.Partition.owned)
+		if err == nil {
+			c.Partition.Static, err = partition.NewStatic(c.Partition.Count, owned)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid ROCKETS_PARTITION_OWNED: %w", err)
+		}
+		if err := c.Partition.Static.UsePeers(c.Partition.peers); err != nil {
+			return fmt.Errorf("invalid ROCKETS_PARTITION_PEERS: %w", err)
+		}
+	}
+	if c.Partition.Ring != "" {
+		if c.Partition.Count > 0 {
+			return fmt.Errorf("ROCKETS_PARTITION_RING and ROCKETS_PARTITION_COUNT are exclusive")
+		}
+		if c.Partition.URL == "" {
+			return fmt.Errorf("ROCKETS_PARTITION_URL is required with ROCKETS_PARTITION_RING")
+		}
+		if c.Partition.RingDuration < 3*time.Second {
+			return fmt.Errorf("ROCKETS_PARTITION_RING_DURATION must be at least 3s, got %s", c.Partition.RingDuration)
+		}
+		if c.Partition.Secret == "" {
+			return fmt.Errorf("ROCKETS_PARTITION_SECRET is required with ROCKETS_PARTITION_RING, the replicas sign their handoffs with it")
+		}
+	}
+	if c.Leader.LockFile != "" && (c.Partition.Count > 0 || c.Partition.Ring != "") {
+		return fmt.Errorf("ROCKETS_LEADER_LOCK_FILE can't be combined with partitioning, a standby would be given channels it doesn't apply")
+	}
+	if c.Store.IncidentsFile == "" && (c.Leader.LockFile != "" || c.Partition.Count > 0 || c.Partition.Ring != "") {
+		return fmt.Errorf("ROCKETS_INCIDENTS_FILE is required with ROCKETS_LEADER_LOCK_FILE or partitioning, the incidents are kept by the instance applying the explosions")
+	}
+	if c.Alerts.LostAfter < 0 || (c.Alerts.LostAfter > 0 && c.Alerts.LostAfter < time.Second) {
+		return fmt.Errorf("ROCKETS_LOST_AFTER must be 0 or at least 1s, got %s", c.Alerts.LostAfter)
+	}
+	if c.Secrets.RenewInterval < 0 {
+		return fmt.Errorf("ROCKETS_SECRETS_RENEW_INTERVAL must not be negative, got %s", c.Secrets.RenewInterval)
+	}
+	if c.Store.CommitWindow < 0 {
+		return fmt.Errorf("ROCKETS_STORE_COMMIT_WINDOW must not be negative, got %s", c.Store.CommitWindow)
+	}
+	if c.Listing.MaxStaleness < 0 {
+		return fmt.Errorf("ROCKETS_LIST_MAX_STALENESS must not be negative, got %s", c.Listing.MaxStaleness)
+	}
+	if c.Cache.TTL < 0 || c.Cache.MaxAge < 0 {
+		return fmt.Errorf("ROCKETS_CACHE_TTL and ROCKETS_CACHE_MAX_AGE must not be negative")
+	}
+	if c.Leader.Retry <= 0 {
+		return fmt.Errorf("ROCKETS_LEADER_RETRY must be positive, got %s", c.Leader.Retry)
+	}
+	if c.Quota.DailyMessages < 0 || c.Quota.DailyBytes < 0 {
+		return fmt.Errorf("ROCKETS_QUOTA_DAILY_MESSAGES and ROCKETS_QUOTA_DAILY_BYTES must not be negative")
+	}
+	if c.Ingest.DebugTraceMax < 0 {
+		return fmt.Errorf("ROCKETS_DEBUG_TRACE_MAX must not be negative, got %d", c.Ingest.DebugTraceMax)
+	}
+	if c.Auth.Public && len(c.Auth.APIKeys) == 0 {
+		return fmt.Errorf("ROCKETS_PUBLIC requires ROCKETS_API_KEYS, otherwise anyone can ingest messages")
+	}
+	if c.Auth.Public && c.Auth.Reads {
+		return fmt.Errorf("ROCKETS_PUBLIC and ROCKETS_AUTH_READS exclude each other")
+	}
+	if c.Ingest.MaxBodyBytes <= 0 {
+		return fmt.Errorf("ROCKETS_MAX_BODY_BYTES must be positive, got %d", c.Ingest.MaxBodyBytes)
+	}
+	if c.History.ColdDir != "" && (c.History.HotAge <= 0 || c.History.TierInterval <= 0) {
+		return fmt.Errorf("ROCKETS_HISTORY_HOT_AGE and ROCKETS_HISTORY_TIER_INTERVAL must be positive")
+	}
+	if c.Export.Dir != "" && c.Export.Interval <= 0 {
+		return fmt.Errorf("ROCKETS_EXPORT_INTERVAL must be positive, got %s", c.Export.Interval)
+	}
+	if c.Ingest.NoisyRate <= 0 {
+		return fmt.Errorf("ROCKETS_NOISY_RATE must be positive, got %g", c.Ingest.NoisyRate)
+	}
+	if c.Ingest.LatencyBudget < 0 {
+		return fmt.Errorf("ROCKETS_LATENCY_BUDGET must not be negative, got %s", c.Ingest.LatencyBudget)
+	}
+	if c.Ingest.LatencyWindow <= 0 {
+		return fmt.Errorf("ROCKETS_LATENCY_WINDOW must be positive, got %s", c.Ingest.LatencyWindow)
+	}
+	if c.Ingest.LatencyCheckInterval <= 0 {
+		return fmt.Errorf("ROCKETS_LATENCY_CHECK_INTERVAL must be positive, got %s", c.Ingest.LatencyCheckInterval)
+	}
+	if c.Ingest.GapReportInterval < 0 {
+		return fmt.Errorf("ROCKETS_GAP_REPORT_INTERVAL must not be negative, got %s", c.Ingest.GapReportInterval)
+	}
+	if c.Ingest.GapRetention <= 0 {
+		return fmt.Errorf("ROCKETS_GAP_RETENTION must be positive, got %s", c.Ingest.GapRetention)
+	}
+	if c.Ingest.RetransmitAfter <= 0 {
+		return fmt.Errorf("ROCKETS_RETRANSMIT_AFTER must be positive, got %s", c.Ingest.RetransmitAfter)
+	}
+	if c.Ingest.RetransmitURL != "" && c.Ingest.GapReportInterval == 0 {
+		return fmt.Errorf("ROCKETS_RETRANSMIT_URL requires ROCKETS_GAP_REPORT_INTERVAL, the retransmissions are requested by the gap report")
+	}
+	if c.Ingest.DeadLetters < 0 {
+		return fmt.Errorf("ROCKETS_DEAD_LETTERS must not be negative, got %d", c.Ingest.DeadLetters)
+	}
+	if c.Ingest.DebugTraceTTL <= 0 {
+		return fmt.Errorf("ROCKETS_DEBUG_TRACE_TTL must be positive, got %s", c.Ingest.DebugTraceTTL)
+	}
+	if c.Ingest.QuarantineAfterFailures < 0 {
+		return fmt.Errorf("ROCKETS_QUARANTINE_AFTER_FAILURES must not be negative, got %d", c.Ingest.QuarantineAfterFailures)
+	}
+	if c.Ingest.DedupWindow <= 0 {
+		return fmt.Errorf("ROCKETS_DEDUP_WINDOW must be positive, got %d", c.Ingest.DedupWindow)
+	}
+	if err := c.Ingest.SpeedUnderflow.Validate(); err != nil {
+		return fmt.Errorf("invalid ROCKETS_SPEED_UNDERFLOW: %w", err)
+	}
+	if err := c.Ingest.ShadowSpeedUnderflow.Validate(); err != nil {
+		return fmt.Errorf("invalid ROCKETS_SHADOW_SPEED_UNDERFLOW: %w", err)
+	}
+	if c.Ingest.DedupPolicy == "strict" && c.Ingest.ReorderWindow > 0 {
+		return fmt.Errorf("ROCKETS_DEDUP_POLICY=strict rejects the messages the reorder buffer would hold, unset ROCKETS_REORDER_WINDOW")
+	}
+	if c.Ingest.ReorderWindow < 0 {
+		return fmt.Errorf("ROCKETS_REORDER_WINDOW must not be negative, got %d", c.Ingest.ReorderWindow)
+	}
+	if c.Ingest.ReorderMaxWait <= 0 {
+		return fmt.Errorf("ROCKETS_REORDER_MAX_WAIT must be positive, got %s", c.Ingest.ReorderMaxWait)
+	}
+	if c.Ingest.ActorMailbox < 0 {
+		return fmt.Errorf("ROCKETS_ACTOR_MAILBOX must not be negative, got %d", c.Ingest.ActorMailbox)
+	}
+	if c.Ingest.ActorIdleTimeout <= 0 {
+		return fmt.Errorf("ROCKETS_ACTOR_IDLE_TIMEOUT must be positive, got %s", c.Ingest.ActorIdleTimeout)
+	}
+	c.Ingest.UnnumberedChannels = make(map[uuid.UUID]time.Duration, len(c.Ingest.unnumberedChannels))
+	for s, w := range c.Ingest.unnumberedChannels {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid ROCKETS_UNNUMBERED_CHANNELS channel %q: %w", s, err)
+		}
+		window, err := time.ParseDuration(w)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid ROCKETS_UNNUMBERED_CHANNELS window %q of %s: must be a positive duration", w, s)
+		}
+		c.Ingest.UnnumberedChannels[id] = window
+	}
+	c.Ingest.UnnumberedProducers = make(map[string]time.Duration, len(c.Ingest.unnumberedProducers))
+	for producer, w := range c.Ingest.unnumberedProducers {
+		window, err := time.ParseDuration(w)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid ROCKETS_UNNUMBERED_PRODUCERS window %q of %s: must be a positive duration", w, producer)
+		}
+		c.Ingest.UnnumberedProducers[producer] = window
+	}
+	if c.Reports.Hour < 0 || c.Reports.Hour > 23 {
+		return fmt.Errorf("ROCKETS_REPORTS_HOUR must be in range 0..23, got %d", c.Reports.Hour)
+	}
+	if c.Capture.SampleRate < 0 || c.Capture.SampleRate > 1 {
+		return fmt.Errorf("ROCKETS_CAPTURE_SAMPLE_RATE must be in range 0..1, got %g", c.Capture.SampleRate)
+	}
+	if c.Capture.Size < 0 {
+		return fmt.Errorf("ROCKETS_CAPTURE_SIZE must not be negative, got %d", c.Capture.Size)
+	}
+	if c.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("ROCKETS_RETRY_MAX_ATTEMPTS must be at least 1, got %d", c.Retry.MaxAttempts)
+	}
+	if c.Retry.InitialBackoff <= 0 || c.Retry.MaxBackoff < c.Retry.InitialBackoff {
+		return fmt.Errorf("ROCKETS_RETRY_INITIAL_BACKOFF must be positive and not above ROCKETS_RETRY_MAX_BACKOFF, got %s and %s", c.Retry.InitialBackoff, c.Retry.MaxBackoff)
+	}
+	if c.Retry.Jitter < 0 || c.Retry.Jitter > 1 {
+		return fmt.Errorf("ROCKETS_RETRY_JITTER must be in range 0..1, got %g", c.Retry.Jitter)
+	}
+	if c.TSDB.URL != "" && c.TSDB.Interval <= 0 {
+		return fmt.Errorf("ROCKETS_TSDB_INTERVAL must be positive, got %s", c.TSDB.Interval)
+	}
+	if c.OTLP.Endpoint != "" && c.OTLP.Interval <= 0 {
+		return fmt.Errorf("ROCKETS_OTLP_INTERVAL must be positive, got %s", c.OTLP.Interval)
+	}
+	if c.Warehouse.Project != "" {
+		if c.Warehouse.Dataset == "" || c.Warehouse.Table == "" {
+			return fmt.Errorf("ROCKETS_WAREHOUSE_DATASET and ROCKETS_WAREHOUSE_TABLE are required with ROCKETS_WAREHOUSE_PROJECT")
+		}
+		if c.Warehouse.Interval <= 0 {
+			return fmt.Errorf("ROCKETS_WAREHOUSE_INTERVAL must be positive, got %s", c.Warehouse.Interval)
+		}
+		if c.Warehouse.BatchSize <= 0 {
+			return fmt.Errorf("ROCKETS_WAREHOUSE_BATCH_SIZE must be positive, got %d", c.Warehouse.BatchSize)
+		}
+	}
+	if c.CDC.URL != "" {
+		if c.CDC.Topic == "" {
+			return fmt.Errorf("ROCKETS_CDC_TOPIC is required with ROCKETS_CDC_URL")
+		}
+		if c.CDC.Interval <= 0 {
+			return fmt.Errorf("ROCKETS_CDC_INTERVAL must be positive, got %s", c.CDC.Interval)
+		}
+		if c.CDC.BatchSize <= 0 {
+			return fmt.Errorf("ROCKETS_CDC_BATCH_SIZE must be positive, got %d", c.CDC.BatchSize)
+		}
+	}
+	if c.Consumer.URL != "" {
+		if c.Consumer.Topic == "" || c.Consumer.Group == "" {
+			return fmt.Errorf("ROCKETS_CONSUMER_TOPIC and ROCKETS_CONSUMER_GROUP are required with ROCKETS_CONSUMER_URL")
+		}
+		if c.Consumer.PollTimeout <= 0 {
+			return fmt.Errorf("ROCKETS_CONSUMER_POLL_TIMEOUT must be positive, got %s", c.Consumer.PollTimeout)
+		}
+		if c.Health.Interval <= 0 || c.Health.StallAfter <= 0 {
+			return fmt.Errorf("ROCKETS_HEALTH_INTERVAL and ROCKETS_HEALTH_STALL_AFTER must be positive with ROCKETS_CONSUMER_URL")
+		}
+		if c.Partition.Count > 0 || c.Partition.Ring != "" {
+			return fmt.Errorf("ROCKETS_CONSUMER_URL can't be combined with partitioning, the consumer group spreads the messages by Kafka partition rather than by channel")
+		}
+	}
+	if c.Reports.Enabled {
+		if c.SMTP.Host == "" || c.SMTP.From == "" || len(c.SMTP.To) == 0 {
+			return fmt.Errorf("reports are enabled but ROCKETS_SMTP_HOST, ROCKETS_SMTP_FROM or ROCKETS_SMTP_TO is not set")
+		}
+	}
+
+	return nil
+}
+
+// loader reads typed values from the environment and keeps the first parse error
+type loader struct {
+	resolver *secrets.Resolver
+	// secret - variables whose value is a secret, kept out of the errors
+	secret map[string]bool
+	err    error
+}
+
+// lookup reads the variable, resolving a secret reference
+func (l *loader) lookup(key string) (string, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || !secrets.IsReference(v) {
+		return v, ok
+	}
+	if l.resolver == nil {
+		l.fail(key, v, fmt.Errorf("secret references are not supported"))
+		return "", false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	resolved, err := l.resolver.Resolve(ctx, v)
+	if err != nil {
+		l.fail(key, v, err)
+		return "", false
+	}
+	if l.secret == nil {
+		l.secret = make(map[string]bool)
+	}
+	l.secret[key] = true
+	return resolved, true
+}
+
+func (l *loader) string(key, def string) string {
+	if v, ok := l.lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+func (l *loader) int(key string, def int) int {
+	v, ok := l.lookup(key)
+	if !ok || v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		l.fail(key, v, err)
+		return def
+	}
+	return n
+}
+
+func (l *loader) bool(key string, def bool) bool {
+	v, ok := l.lookup(key)
+	if !ok || v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.fail(key, v, err)
+		return def
+	}
+	return b
+}
+
+func (l *loader) float(key string, def float64) float64 {
+	v, ok := l.lookup(key)
+	if !ok || v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		l.fail(key, v, err)
+		return def
+	}
+	return f
+}
+
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	v, ok := l.lookup(key)
+	if !ok || v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		l.fail(key, v, err)
+		return def
+	}
+	return d
+}
+
+// list reads a comma-separated list, skipping empty items
+func (l *loader) list(key string) []string {
+	v, _ := l.lookup(key)
+	if v == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// mapping reads a comma-separated list of key=value pairs
+func (l *loader) mapping(key string) map[string]string {
+	items := l.list(key)
+	if len(items) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(items))
+	for _, item := range items {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok || k == "" || v == "" {
+			l.fail(key, item, fmt.Errorf("expected key=value"))
+			continue
+		}
+		m[k] = v
+	}
+	return m
+}
+
+func (l *loader) fail(key, value string, err error) {
+	if l.secret[key] {
+		value = "<secret>"
+	}
+	if l.err == nil {
+		l.err = fmt.Errorf("invalid value %q for %s: %w", value, key, err)
+	}
+}
@@ -0,0 +1,290 @@
+// Package consumer ingests telemetry messages from message queues. A consumer pauses while the health checks
+// fail, so the messages wait in the queue instead of being rejected one after another.
+package consumer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"rockets/internal/config"
+	"rockets/internal/rocket"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Gate - holds the consumption while the instance can't apply messages, e.g. health.Monitor
+type Gate interface {
+	// Wait returns once the instance can apply messages, or with the error of the context
+	Wait(ctx context.Context, consumer string) error
+	// Err returns why the instance can't apply messages, nil when it can
+	Err() error
+}
+
+// Processor - applies the consumed messages, e.g. rocket.Service
+type Processor interface {
+	ProcessMessage(ctx context.Context, msg rocket.TelemetryMessage) (rocket.Result, error)
+}
+
+// name - name of the consumer in the paused time metric
+const name = "kafka"
+
+const (
+	// contentType - format of the requests managing the consumer instance
+	contentType = "application/vnd.kafka.v2+json"
+	// recordsType - embedded JSON format of the fetched records
+	recordsType = "application/vnd.kafka.json.v2+json"
+	// retryDelay - pause after a failed request to the proxy
+	retryDelay = time.Second
+)
+
+// errNotFound - the proxy doesn't know the consumer instance, e.g. it expired while the consumer was paused
+var errNotFound = errors.New("not found")
+
+// record - record fetched from the topic
+type record struct {
+	Topic     string          `json:"topic"`
+	Partition int32           `json:"partition"`
+	Offset    int64           `json:"offset"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// Kafka consumes the telemetry messages of a topic through a Kafka REST Proxy, as a member of a consumer group
+// the replicas share. Offsets are committed once the fetched messages are applied or rejected, so a message is
+// applied at least once and the redelivered ones are skipped as duplicates. While the health checks fail the
+// consumer fetches nothing, and a message it could not apply meanwhile is held and applied again once they
+// pass.
+type Kafka struct {
+	cfg     config.Consumer
+	rockets Processor
+	gate    Gate
+	client  *http.Client
+	logger  *zap.Logger
+	// instance - base URI of the consumer instance, empty until it joined the group; only accessed by Run
+	instance string
+}
+
+// NewKafka creates a consumer of the topic in the settings, applying its messages with the processor.
+func NewKafka(cfg config.Consumer, rockets Processor, gate Gate, logger *zap.Logger) *Kafka {
+	return &Kafka{
+		cfg:     cfg,
+		rockets: rockets,
+		gate:    gate,
+		client:  &http.Client{Timeout: cfg.PollTimeout + 10*time.Second},
+		logger:  logger,
+	}
+}
+
+// Run consumes the topic until the context is done, then leaves the group. Failed requests to the proxy are
+// logged and made again; an expired consumer instance is replaced and continues from the committed offsets.
+func (k *Kafka) Run(ctx context.Context) error {
+	defer k.leave()
+	for {
+		if err := k.gate.Wait(ctx, name); err != nil {
+			return nil
+		}
+		err := k.poll(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.Is(err, errNotFound) && k.instance != "":
+			k.logger.Warn("Consumer instance expired, joining the group again", zap.String("topic", k.cfg.Topic))
+			k.instance = ""
+		case err != nil:
+			k.logger.Error("Can't consume telemetry messages", zap.String("topic", k.cfg.Topic), zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+}
+
+// poll fetches the next records and applies them, joining the group first
+func (k *Kafka) poll(ctx context.Context) error {
+	if k.instance == "" {
+		if err := k.join(ctx); err != nil {
+			return err
+		}
+	}
+	var records []record
+	target := k.instance + "/records?timeout=" + strconv.FormatInt(k.cfg.PollTimeout.Milliseconds(), 10)
+	if err := k.do(ctx, http.MethodGet, target, nil, &records); err != nil {
+		return fmt.Errorf("can't fetch records: %w", err)
+	}
+	done, err := k.apply(ctx, records)
+	if done > 0 {
+		// the applied ones are committed even when the consumer stops in the middle of the records
+		commitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := k.commit(commitCtx, records[:done]); err != nil {
+			return fmt.Errorf("can't commit offsets: %w", err)
+		}
+	}
+	return err
+}
+
+// apply applies the records in their order, returning how many it is done with. A rejected message is logged
+// and skipped, like a rejected request of a producer.
+func (k *Kafka) apply(ctx context.Context, records []record) (int, error) {
+	for i, r := range records {
+		msg, err := decode(r.Value)
+		if err == nil {
+			err = k.process(ctx, msg)
+		}
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+		if err != nil {
+			k.logger.Warn("Telemetry message of the topic not applied", zap.String("topic", r.Topic),
+				zap.Int32("partition", r.Partition), zap.Int64("offset", r.Offset), zap.Error(err))
+		}
+	}
+	return len(records), nil
+}
+
+// process applies the message. A failure while the health checks fail is not the message's fault, the message
+// is applied again once they pass instead of being rejected.
+func (k *Kafka) process(ctx context.Context, msg rocket.TelemetryMessage) error {
+	for {
+		_, err := k.rockets.ProcessMessage(ctx, msg)
+		if err == nil || k.gate.Err() == nil {
+			return err
+		}
+		if err := k.gate.Wait(ctx, name); err != nil {
+			return err
+		}
+	}
+}
+
+// decode reads a message in the format of POST /messages and normalizes its names like the API does; the
+// service validates the rest
+func decode(value json.RawMessage) (rocket.TelemetryMessage, error) {
+	var msg rocket.TelemetryMessage
+	if err := json.Unmarshal(value, &msg); err != nil {
+		return msg, fmt.Errorf("%w: %s", rocket.ErrInvalidMessage, err)
+	}
+	// set by the service, not by the producer
+	msg.Metadata.Signature, msg.Metadata.Producer, msg.Metadata.Unnumbered = "", "", false
+	var err error
+	for _, mission := range []*rocket.Mission{msg.Message.Mission, msg.Message.NewMission} {
+		if mission != nil {
+			if *mission, err = rocket.NewMission(string(*mission)); err != nil {
+				return msg, err
+			}
+		}
+	}
+	if t := msg.Message.Type; t != nil {
+		if *t, err = rocket.NewRocketType(string(*t)); err != nil {
+			return msg, err
+		}
+	}
+	return msg, nil
+}
+
+// join creates a consumer instance in the group and subscribes it to the topic. Its offsets are committed by
+// the consumer; a group without committed offsets starts with the earliest record.
+func (k *Kafka) join(ctx context.Context) error {
+	var created struct {
+		BaseURI string `json:"base_uri"`
+	}
+	target := strings.TrimSuffix(k.cfg.URL, "/") + "/consumers/" + url.PathEscape(k.cfg.Group)
+	err := k.do(ctx, http.MethodPost, target, map[string]string{
+		"name":               "rockets-" + uuid.NewString(),
+		"format":             "json",
+		"auto.offset.reset":  "earliest",
+		"auto.commit.enable": "false",
+	}, &created)
+	if err != nil {
+		return fmt.Errorf("can't create consumer instance: %w", err)
+	}
+	k.instance = created.BaseURI
+	if err := k.do(ctx, http.MethodPost, k.instance+"/subscription", map[string][]string{"topics": {k.cfg.Topic}}, nil); err != nil {
+		k.leave()
+		return fmt.Errorf("can't subscribe to %s: %w", k.cfg.Topic, err)
+	}
+	k.logger.Info("Joined the consumer group", zap.String("group", k.cfg.Group), zap.String("topic", k.cfg.Topic))
+	return nil
+}
+
+// leave deletes the consumer instance, so the group hands its partitions over to the other replicas at once
+// instead of once the instance expires
+func (k *Kafka) leave() {
+	if k.instance == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := k.do(ctx, http.MethodDelete, k.instance, nil, nil); err != nil && !errors.Is(err, errNotFound) {
+		k.logger.Warn("Can't delete the consumer instance", zap.String("topic", k.cfg.Topic), zap.Error(err))
+	}
+	k.instance = ""
+}
+
+// commit commits the offsets of the last records of every partition
+func (k *Kafka) commit(ctx context.Context, records []record) error {
+	type offset struct {
+		Topic     string `json:"topic"`
+		Partition int32  `json:"partition"`
+		Offset    int64  `json:"offset"`
+	}
+	var offsets []offset
+	last := make(map[offset]int)
+	for _, r := range records {
+		key := offset{Topic: r.Topic, Partition: r.Partition}
+		if i, ok := last[key]; ok {
+			offsets[i].Offset = max(offsets[i].Offset, r.Offset)
+			continue
+		}
+		last[key] = len(offsets)
+		offsets = append(offsets, offset{Topic: r.Topic, Partition: r.Partition, Offset: r.Offset})
+	}
+	return k.do(ctx, http.MethodPost, k.instance+"/offsets", map[string][]offset{"offsets": offsets}, nil)
+}
+
+// do makes a request to the proxy with the body encoded as JSON, decoding the response into out unless nil
+func (k *Kafka) do(ctx context.Context, method, target string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("can't marshal request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("can't create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", recordsType+", "+contentType)
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("can't decode response: %w", err)
+	}
+	return nil
+}
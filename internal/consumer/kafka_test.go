@@ -0,0 +1,187 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/config"
+	"rockets/internal/health"
+	"rockets/internal/rocket"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeProxy - Kafka REST Proxy with one consumer instance, handing out the queued records once
+type fakeProxy struct {
+	mu        sync.Mutex
+	url       string
+	records   []record
+	fetches   int
+	committed map[int32]int64
+	deleted   bool
+}
+
+func (p *fakeProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	const instance = "/consumers/rockets/instances/a"
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/consumers/rockets":
+		_ = json.NewEncoder(w).Encode(map[string]string{"instance_id": "a", "base_uri": p.url + instance})
+	case r.Method == http.MethodPost && r.URL.Path == instance+"/subscription":
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == instance+"/records":
+		p.fetches++
+		records := p.records
+		p.records = nil
+		if records == nil {
+			records = []record{}
+		}
+		_ = json.NewEncoder(w).Encode(records)
+	case r.Method == http.MethodPost && r.URL.Path == instance+"/offsets":
+		var req struct {
+			Offsets []record `json:"offsets"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for _, o := range req.Offsets {
+			p.committed[o.Partition] = o.Offset
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete && r.URL.Path == instance:
+		p.deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (p *fakeProxy) state() (fetches int, committed map[int32]int64, deleted bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	committed = make(map[int32]int64, len(p.committed))
+	for k, v := range p.committed {
+		committed[k] = v
+	}
+	return p.fetches, committed, p.deleted
+}
+
+func startProxy(t *testing.T, values ...string) *fakeProxy {
+	t.Helper()
+	proxy := &fakeProxy{committed: make(map[int32]int64)}
+	for i, v := range values {
+		proxy.records = append(proxy.records, record{Topic: "rockets.telemetry", Partition: 0, Offset: int64(i), Value: json.RawMessage(v)})
+	}
+	server := httptest.NewServer(proxy)
+	t.Cleanup(server.Close)
+	proxy.url = server.URL
+	return proxy
+}
+
+func eventually(condition func() bool) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+const id = "193270a9-c9cf-404a-8f83-838e71d9ae67"
+
+func TestKafka(t *testing.T) {
+	proxy := startProxy(t,
+		`{"metadata":{"channel":"`+id+`","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"artemis"}}`,
+		`{"metadata":{"channel":"`+id+`","messageNumber":2,"messageTime":"2022-02-02T19:40:05Z","messageType":"RocketSpeedIncreased"},"message":{"by":-5}}`,
+		`{"metadata":{"channel":"`+id+`","messageNumber":2,"messageTime":"2022-02-02T19:40:05Z","messageType":"RocketSpeedIncreased"},"message":{"by":3000}}`,
+	)
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	consumer := NewKafka(config.Consumer{URL: proxy.url, Topic: "rockets.telemetry", Group: "rockets", PollTimeout: 10 * time.Millisecond},
+		svc, health.NewMonitor(nil, zap.NewNop()), zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- consumer.Run(ctx) }()
+
+	if !eventually(func() bool { _, committed, _ := proxy.state(); return committed[0] == 2 }) {
+		t.Fatalf("Expected the records to be committed up to offset 2")
+	}
+	// the invalid message is skipped, the names are normalized like the ones of the API
+	state, err := svc.GetRocketState(context.Background(), uuid.MustParse(id))
+	if err != nil || state.CurrentSpeed != 3500 || state.Mission != "ARTEMIS" {
+		t.Errorf("Expected ARTEMIS at speed 3500, got %+v", state)
+	}
+
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Errorf("Run failed: %v", err)
+	}
+	if _, _, deleted := proxy.state(); !deleted {
+		t.Errorf("Expected the consumer instance to be deleted on shutdown")
+	}
+}
+
+// stallingProcessor - fails the messages while the store stalls
+type stallingProcessor struct {
+	Processor
+	stalled *atomic.Bool
+	monitor *health.Monitor
+	calls   atomic.Int32
+}
+
+func (p *stallingProcessor) ProcessMessage(ctx context.Context, msg rocket.TelemetryMessage) (rocket.Result, error) {
+	if p.calls.Add(1) == 1 {
+		// the store stalls under the first message
+		p.stalled.Store(true)
+		p.monitor.Check()
+	}
+	if p.stalled.Load() {
+		return rocket.Result{}, errors.New("store write running for 12s")
+	}
+	return p.Processor.ProcessMessage(ctx, msg)
+}
+
+func TestKafka_PausedByHealthChecks(t *testing.T) {
+	proxy := startProxy(t,
+		`{"metadata":{"channel":"`+id+`","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`,
+	)
+	var stalled atomic.Bool
+	monitor := health.NewMonitor([]health.Check{{Name: "store", Check: func() error {
+		if stalled.Load() {
+			return errors.New("store write running for 12s")
+		}
+		return nil
+	}}}, zap.NewNop())
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	processor := &stallingProcessor{Processor: svc, stalled: &stalled, monitor: monitor}
+	consumer := NewKafka(config.Consumer{URL: proxy.url, Topic: "rockets.telemetry", Group: "rockets", PollTimeout: 10 * time.Millisecond},
+		processor, monitor, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = consumer.Run(ctx) }()
+
+	// the message failing while the checks fail is held, neither committed nor retried, and nothing is fetched
+	if !eventually(func() bool { return processor.calls.Load() == 1 }) {
+		t.Fatalf("Expected the message to be processed")
+	}
+	time.Sleep(100 * time.Millisecond)
+	fetches, committed, _ := proxy.state()
+	if processor.calls.Load() != 1 || fetches != 1 || len(committed) != 0 {
+		t.Fatalf("Expected the consumer to pause, got %d attempts, %d fetches and offsets %v", processor.calls.Load(), fetches, committed)
+	}
+
+	stalled.Store(false)
+	monitor.Check()
+	if !eventually(func() bool { _, committed, _ := proxy.state(); _, ok := committed[0]; return ok }) {
+		t.Fatalf("Expected the held message to be committed once the checks pass")
+	}
+	if state, err := svc.GetRocketState(context.Background(), uuid.MustParse(id)); err != nil || state.CurrentSpeed != 500 {
+		t.Errorf("Expected the held message to be applied, got %+v", state)
+	}
+}
@@ -0,0 +1,175 @@
+// Package encrypt encrypts records persisted at rest with AES-GCM.
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrDecrypt - the record was not encrypted with any of the keys, or it was altered
+var ErrDecrypt = errors.New("can't decrypt record")
+
+// Records are not sealed with the keys themselves but with record keys derived from them and a random salt of
+// saltSize bytes, carried by every record. A record key seals at most sealBudget records with random nonces,
+// keeping the chance of a nonce repeating negligible, before a new salt is drawn.
+const (
+	saltSize   = 16
+	sealBudget = 1 << 32
+)
+
+// Cipher encrypts records with the first of its keys and decrypts them with any of them, so a key can be
+// rotated by putting the new one first until every record has been rewritten.
+type Cipher struct {
+	keys [][]byte
+	// budget - records a record key seals before it is replaced
+	budget uint64
+	// plain - plain records are read as is, to encrypt a file written without a cipher
+	plain bool
+
+	mu sync.Mutex
+	// salt, aead, sealed - current record key of the first key and the records it sealed
+	salt   []byte
+	aead   cipher.AEAD
+	sealed uint64
+	// opened - record keys of the records opened, by key and salt
+	opened map[string]cipher.AEAD
+}
+
+// New creates a cipher of the AES keys of 16, 24 or 32 bytes, the first one encrypting.
+func New(keys [][]byte) (*Cipher, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption key")
+	}
+	for i, key := range keys {
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", i+1, err)
+		}
+	}
+	return &Cipher{keys: keys, budget: sealBudget, opened: make(map[string]cipher.AEAD)}, nil
+}
+
+// AcceptPlain makes the cipher read plain records as is, to encrypt a file written without a cipher. Must be
+// called before the cipher is used.
+func (c *Cipher) AcceptPlain() {
+	c.plain = true
+}
+
+// AcceptsPlain tells whether plain records are read as is rather than rejected
+func (c *Cipher) AcceptsPlain() bool {
+	return c.plain
+}
+
+// recordKey derives the record key of the key and the salt
+func recordKey(key, salt []byte) (cipher.AEAD, error) {
+	derived, err := hkdf.Key(sha256.New, key, salt, "rockets store record", len(key))
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ParseKeys decodes base64 encoded keys separated by commas or newlines, e.g. the content of a key file.
+func ParseKeys(v string) ([][]byte, error) {
+	var keys [][]byte
+	for _, item := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", len(keys)+1, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ReadKeys reads the keys of the file, e.g. a secret mounted from a key management service.
+func ReadKeys(file string) ([][]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("can't read key file: %w", err)
+	}
+	return ParseKeys(string(data))
+}
+
+// Seal encrypts the record with a random nonce under the current record key, drawing a new one once its budget
+// is spent, and returns the salt, nonce and ciphertext base64 encoded, so records stay lines of text
+func (c *Cipher) Seal(record []byte) ([]byte, error) {
+	c.mu.Lock()
+	if c.aead == nil || c.sealed >= c.budget {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			c.mu.Unlock()
+			return nil, fmt.Errorf("can't generate salt: %w", err)
+		}
+		aead, err := recordKey(c.keys[0], salt)
+		if err != nil {
+			c.mu.Unlock()
+			return nil, fmt.Errorf("can't derive record key: %w", err)
+		}
+		c.salt, c.aead, c.sealed = salt, aead, 0
+	}
+	c.sealed++
+	salt, aead := c.salt, c.aead
+	c.mu.Unlock()
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("can't generate nonce: %w", err)
+	}
+	sealed := aead.Seal(append(append([]byte{}, salt...), nonce...), nonce, record, nil)
+	out := make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(out, sealed)
+	return out, nil
+}
+
+// Open decrypts a record of Seal, trying the keys in order. It fails with ErrDecrypt.
+func (c *Cipher) Open(sealed []byte) ([]byte, error) {
+	data := make([]byte, base64.StdEncoding.DecodedLen(len(sealed)))
+	n, err := base64.StdEncoding.Decode(data, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDecrypt, err)
+	}
+	data = data[:n]
+	if len(data) < saltSize {
+		return nil, ErrDecrypt
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+	for i, key := range c.keys {
+		// record keys are derived once per salt, which is drawn once per cipher and budget, so few are kept
+		id := fmt.Sprintf("%d/%x", i, salt)
+		c.mu.Lock()
+		aead, ok := c.opened[id]
+		c.mu.Unlock()
+		if !ok {
+			if aead, err = recordKey(key, salt); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrDecrypt, err)
+			}
+		}
+		if len(data) < aead.NonceSize() {
+			break
+		}
+		if record, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil); err == nil {
+			c.mu.Lock()
+			c.opened[id] = aead
+			c.mu.Unlock()
+			return record, nil
+		}
+	}
+	return nil, ErrDecrypt
+}
@@ -0,0 +1,41 @@
+package encrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestCipher_RotatesRecordKeys(t *testing.T) {
+	c, err := New([][]byte{[]byte("0123456789abcdef0123456789abcdef")})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	c.budget = 2
+
+	salts := map[string]bool{}
+	var sealed [][]byte
+	for i := 0; i < 5; i++ {
+		record, err := c.Seal([]byte("record"))
+		if err != nil {
+			t.Fatalf("Seal failed: %v", err)
+		}
+		raw, _ := base64.StdEncoding.DecodeString(string(record))
+		salts[string(raw[:saltSize])] = true
+		sealed = append(sealed, record)
+	}
+	if len(salts) != 3 {
+		t.Errorf("Expected a new record key every 2 records, got %d", len(salts))
+	}
+	for _, record := range sealed {
+		if got, err := c.Open(record); err != nil || !bytes.Equal(got, []byte("record")) {
+			t.Errorf("Expected the record opened, got %q: %v", got, err)
+		}
+	}
+
+	other, _ := New([][]byte{[]byte("fedcba9876543210fedcba9876543210")})
+	if _, err := other.Open(sealed[0]); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Expected ErrDecrypt with another key, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package export
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/url"
+	"os"
+	"path/filepath"
+	"rockets/internal/atomicfile"
+	"rockets/internal/rocket"
+	"slices"
+	"sync"
+	"time"
+)
+
+// fileName - name of the Parquet file in every partition directory
+const fileName = "events.parquet"
+
+// Partition - Parquet file of the events of one message date and mission
+type Partition struct {
+	// Date - UTC date of the messages, YYYY-MM-DD
+	Date    string         `json:"date"`
+	Mission rocket.Mission `json:"mission"`
+	// Path - path of the file relative to the export directory
+	Path   string `json:"path"`
+	Events int    `json:"events"`
+}
+
+// partitionKey - message date and mission of a partition
+type partitionKey struct {
+	date    string
+	mission rocket.Mission
+}
+
+// exported - what the last export wrote of a rocket
+type exported struct {
+	// version - version of the state when its events were exported, every event and rollback changes it
+	version    int64
+	partitions []partitionKey
+}
+
+// Exporter writes the event history to Parquet files partitioned Hive-style by message date and mission,
+// date=2022-02-02/mission=ARTEMIS/events.parquet, e.g. into a mounted bucket the Spark pipelines read
+type Exporter struct {
+	// mu - held while exporting, so a triggered export never overlaps the scheduled one
+	mu      sync.Mutex
+	rockets rocket.Service
+	history rocket.HistoryStore
+	dir     string
+	logger  *zap.Logger
+	// exported - rockets written by the earlier exports of the process, the next export rewrites only the
+	// partitions of the rockets changed since
+	exported map[uuid.UUID]exported
+}
+
+// NewExporter creates an exporter writing into the directory.
+func NewExporter(rockets rocket.Service, history rocket.HistoryStore, dir string, logger *zap.Logger) *Exporter {
+	return &Exporter{
+		rockets:  rockets,
+		history:  history,
+		dir:      dir,
+		logger:   logger,
+		exported: make(map[uuid.UUID]exported),
+	}
+}
+
+// Run exports the history every interval until the context is done, failures are logged.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := e.Export(ctx); err != nil {
+				e.logger.Error("Can't export event history", zap.String("dir", e.dir), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Export writes the partitions with events of the rockets changed or removed since the previous export,
+// replacing their files, and returns the written partitions ordered by date and mission. The first export
+// of the process writes every partition the history has events of. Superseded events are exported with
+// superseded set, so the pipelines can tell rolled back messages apart.
+func (e *Exporter) Export(ctx context.Context) ([]Partition, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	states, err := e.rockets.ListAllRockets(ctx, rocket.SortedBy(rocket.SortByID, rocket.SortAsc))
+	if err != nil {
+		return nil, fmt.Errorf("can't list rockets: %w", err)
+	}
+
+	// partitions the events of the changed and removed rockets are in, before and after the change
+	dirty := make(map[partitionKey]bool)
+	listed := make(map[uuid.UUID][]rocket.Event)
+	next := make(map[uuid.UUID]exported, len(states))
+	for _, state := range states {
+		x, ok := e.exported[state.ID]
+		if ok && x.version == state.Version {
+			next[state.ID] = x
+			continue
+		}
+		for _, k := range x.partitions {
+			dirty[k] = true
+		}
+		listed[state.ID] = e.history.ListEvents(state.ID)
+		x = exported{version: state.Version}
+		for _, event := range listed[state.ID] {
+			if k := keyOf(event); !slices.Contains(x.partitions, k) {
+				x.partitions = append(x.partitions, k)
+				dirty[k] = true
+			}
+		}
+		next[state.ID] = x
+	}
+	for id, x := range e.exported {
+		if _, ok := next[id]; !ok {
+			for _, k := range x.partitions {
+				dirty[k] = true
+			}
+		}
+	}
+
+	// a file holds every rocket of its partition, so the unchanged rockets of the dirty partitions are read too
+	partitions := make(map[partitionKey][]rocket.Event, len(dirty))
+	for id, x := range next {
+		if _, ok := listed[id]; ok {
+			continue
+		}
+		for _, k := range x.partitions {
+			if dirty[k] {
+				listed[id] = e.history.ListEvents(id)
+				break
+			}
+		}
+	}
+	for _, list := range listed {
+		for _, event := range list {
+			if k := keyOf(event); dirty[k] {
+				partitions[k] = append(partitions[k], event)
+			}
+		}
+	}
+
+	written := make([]Partition, 0, len(partitions))
+	for k, events := range partitions {
+		slices.SortStableFunc(events, func(a, b rocket.Event) int {
+			return cmp.Or(
+				a.Message.Metadata.MessageTime.Compare(b.Message.Metadata.MessageTime),
+				cmp.Compare(a.State.ID.String(), b.State.ID.String()),
+				cmp.Compare(a.Message.Metadata.MessageNumber, b.Message.Metadata.MessageNumber),
+			)
+		})
+		p := Partition{
+			Date:    k.date,
+			Mission: k.mission,
+			Path:    filepath.Join("date="+k.date, "mission="+url.PathEscape(string(k.mission)), fileName),
+			Events:  len(events),
+		}
+		if err := e.write(p.Path, events); err != nil {
+			return nil, fmt.Errorf("can't export partition %s: %w", p.Path, err)
+		}
+		written = append(written, p)
+	}
+	e.exported = next
+	slices.SortFunc(written, func(a, b Partition) int {
+		return cmp.Or(cmp.Compare(a.Date, b.Date), cmp.Compare(a.Mission, b.Mission))
+	})
+	return written, nil
+}
+
+// write replaces the file at the path with the events, through a temporary file so readers never see half of it
+func (e *Exporter) write(path string, events []rocket.Event) error {
+	file := filepath.Join(e.dir, path)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("can't create partition directory: %w", err)
+	}
+	tmp := file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("can't create file: %w", err)
+	}
+	if err := writeParquet(f, len(events), eventColumns(events)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return atomicfile.Replace(f, file)
+}
+
+// keyOf returns the partition of the event
+func keyOf(event rocket.Event) partitionKey {
+	return partitionKey{event.Message.Metadata.MessageTime.UTC().Format(time.DateOnly), event.State.Mission}
+}
+
+// eventColumns lays the events out in the columns of the exported schema
+func eventColumns(events []rocket.Event) []parquetColumn {
+	n := len(events)
+	var (
+		rocketIDs      = make([]string, n)
+		messageNumbers = make([]int64, n)
+		messageTimes   = make([]time.Time, n)
+		messageTypes   = make([]string, n)
+		types          = make([]string, n)
+		missions       = make([]string, n)
+		speeds         = make([]int64, n)
+		statuses       = make([]string, n)
+		versions       = make([]int64, n)
+		superseded     = make([]bool, n)
+		signatures     = make([]string, n)
+	)
+	for i, e := range events {
+		rocketIDs[i] = e.State.ID.String()
+		messageNumbers[i] = e.Message.Metadata.MessageNumber
+		messageTimes[i] = e.Message.Metadata.MessageTime
+		messageTypes[i] = string(e.Message.Metadata.MessageType)
+		types[i] = string(e.State.Type)
+		missions[i] = string(e.State.Mission)
+		speeds[i] = int64(e.State.CurrentSpeed)
+		statuses[i] = string(e.State.Status)
+		versions[i] = e.State.Version
+		superseded[i] = e.Superseded
+		signatures[i] = string(e.Message.Metadata.Signature)
+	}
+	return []parquetColumn{
+		stringColumn("rocket_id", rocketIDs),
+		int64Column("message_number", messageNumbers),
+		timestampColumn("message_time", messageTimes),
+		stringColumn("message_type", messageTypes),
+		stringColumn("rocket_type", types),
+		stringColumn("mission", missions),
+		int64Column("speed", speeds),
+		stringColumn("status", statuses),
+		int64Column("version", versions),
+		boolColumn("superseded", superseded),
+		stringColumn("signature", signatures),
+	}
+}
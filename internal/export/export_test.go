@@ -0,0 +1,122 @@
+package export
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"reflect"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+func TestExporter_Export(t *testing.T) {
+	logger := zap.NewNop()
+	history := rocket.NewInMemoryHistoryStore()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	svc.UseHistory(history)
+
+	day := time.Date(2022, 2, 2, 23, 59, 0, 0, time.UTC)
+	artemis := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	gemini := uuid.MustParse("7a9d2e61-4b5c-4d3e-9f1a-2b3c4d5e6f70")
+	messages := []rocket.TelemetryMessage{
+		{
+			Metadata: rocket.MessageMetadata{Channel: artemis, MessageNumber: 1, MessageTime: day, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: artemis, MessageNumber: 2, MessageTime: day.Add(2 * time.Minute), MessageType: rocket.MessageTypeSpeedIncreased},
+			Message:  rocket.Message{By: ptr(rocket.Speed(1000))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: gemini, MessageNumber: 1, MessageTime: day, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Soyuz")), LaunchSpeed: ptr(rocket.Speed(300)), Mission: ptr(rocket.Mission("GEMINI 2"))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := svc.ProcessMessage(context.Background(), msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	exporter := NewExporter(svc, history, dir, logger)
+	partitions, err := exporter.Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	expected := []Partition{
+		{Date: "2022-02-02", Mission: "ARTEMIS", Path: "date=2022-02-02/mission=ARTEMIS/events.parquet", Events: 1},
+		{Date: "2022-02-02", Mission: "GEMINI 2", Path: "date=2022-02-02/mission=GEMINI%202/events.parquet", Events: 1},
+		{Date: "2022-02-03", Mission: "ARTEMIS", Path: "date=2022-02-03/mission=ARTEMIS/events.parquet", Events: 1},
+	}
+	if len(partitions) != len(expected) {
+		t.Fatalf("Expected %d partitions, got %+v", len(expected), partitions)
+	}
+	for i, p := range partitions {
+		if p != expected[i] {
+			t.Errorf("Expected partition %+v, got %+v", expected[i], p)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, expected[0].Path))
+	if err != nil {
+		t.Fatalf("Can't read the exported file: %v", err)
+	}
+	rows, columns, err := readParquet(data)
+	if err != nil {
+		t.Fatalf("Can't read the exported file: %v", err)
+	}
+	if rows != 1 {
+		t.Fatalf("Expected 1 row, got %d", rows)
+	}
+	row := map[string][]any{
+		"rocket_id":      {artemis.String()},
+		"message_number": {int64(1)},
+		"message_time":   {day.UnixMilli()},
+		"message_type":   {string(rocket.MessageTypeLaunched)},
+		"rocket_type":    {"Falcon-9"},
+		"mission":        {"ARTEMIS"},
+		"speed":          {int64(500)},
+		"status":         {string(rocket.StatusLaunched)},
+		"version":        {int64(1)},
+		"superseded":     {false},
+		"signature":      {""},
+	}
+	if !reflect.DeepEqual(columns, row) {
+		t.Errorf("Expected the row %v, got %v", row, columns)
+	}
+
+	partitions, err = exporter.Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(partitions) != 0 {
+		t.Errorf("Expected no partitions rewritten without changes, got %+v", partitions)
+	}
+
+	// a rollback rewrites the partitions of the rocket, the other ones are left as they are
+	if _, err := svc.RollbackRocket(context.Background(), artemis, 2); err != nil {
+		t.Fatalf("RollbackRocket failed: %v", err)
+	}
+	partitions, err = exporter.Export(context.Background())
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(partitions) != 2 || partitions[0] != expected[0] || partitions[1] != expected[2] {
+		t.Fatalf("Expected the partitions of the rolled back rocket rewritten, got %+v", partitions)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, expected[2].Path))
+	if err != nil {
+		t.Fatalf("Can't read the exported file: %v", err)
+	}
+	_, columns, err = readParquet(data)
+	if err != nil {
+		t.Fatalf("Can't read the exported file: %v", err)
+	}
+	if !reflect.DeepEqual(columns["superseded"], []any{true}) {
+		t.Errorf("Expected the rolled back event superseded, got %v", columns["superseded"])
+	}
+}
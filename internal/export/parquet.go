@@ -0,0 +1,248 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Parquet physical types, converted types and enums of the file metadata
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+
+	parquetRequired     = 0
+	parquetPlain        = 0
+	parquetRLE          = 3
+	parquetDataPage     = 0
+	parquetUncompressed = 0
+)
+
+// parquetMagic - starts and ends every Parquet file
+const parquetMagic = "PAR1"
+
+// parquetColumn - required column of a flat schema with its values plain encoded
+type parquetColumn struct {
+	name      string
+	typ       int32
+	converted *int32
+	data      []byte
+}
+
+func int64Column(name string, values []int64) parquetColumn {
+	data := make([]byte, 0, 8*len(values))
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint64(data, uint64(v))
+	}
+	return parquetColumn{name: name, typ: parquetInt64, data: data}
+}
+
+func timestampColumn(name string, values []time.Time) parquetColumn {
+	millis := make([]int64, len(values))
+	for i, v := range values {
+		millis[i] = v.UnixMilli()
+	}
+	c := int64Column(name, millis)
+	c.converted = ptr(int32(parquetTimestampMillis))
+	return c
+}
+
+func stringColumn(name string, values []string) parquetColumn {
+	var data []byte
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(v)))
+		data = append(data, v...)
+	}
+	return parquetColumn{name: name, typ: parquetByteArray, converted: ptr(int32(parquetUTF8)), data: data}
+}
+
+// boolColumn bit-packs the values, least significant bit first
+func boolColumn(name string, values []bool) parquetColumn {
+	data := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			data[i/8] |= 1 << (i % 8)
+		}
+	}
+	return parquetColumn{name: name, typ: parquetBoolean, data: data}
+}
+
+// writeParquet writes the columns of the rows as a Parquet file with a single row group, one uncompressed data
+// page per column and the metadata in the Thrift compact protocol. Only what Spark and other readers need for
+// a flat schema of required columns is written.
+func writeParquet(w io.Writer, rows int, columns []parquetColumn) error {
+	var out bytes.Buffer
+	out.WriteString(parquetMagic)
+
+	offsets := make([]int64, len(columns))
+	sizes := make([]int64, len(columns))
+	for i, col := range columns {
+		offsets[i] = int64(out.Len())
+		var page compactWriter
+		page.begin()
+		page.i32Field(1, parquetDataPage)
+		page.i32Field(2, int32(len(col.data)))
+		page.i32Field(3, int32(len(col.data)))
+		page.structField(5)
+		page.i32Field(1, int32(rows))
+		page.i32Field(2, parquetPlain)
+		page.i32Field(3, parquetRLE)
+		page.i32Field(4, parquetRLE)
+		page.end()
+		page.end()
+		out.Write(page.Bytes())
+		out.Write(col.data)
+		sizes[i] = int64(out.Len()) - offsets[i]
+	}
+
+	var meta compactWriter
+	meta.begin()
+	meta.i32Field(1, 1)
+	meta.listField(2, thriftStruct, len(columns)+1)
+	meta.begin()
+	meta.stringField(4, "schema")
+	meta.i32Field(5, int32(len(columns)))
+	meta.end()
+	for _, col := range columns {
+		meta.begin()
+		meta.i32Field(1, col.typ)
+		meta.i32Field(3, parquetRequired)
+		meta.stringField(4, col.name)
+		if col.converted != nil {
+			meta.i32Field(6, *col.converted)
+		}
+		meta.end()
+	}
+	meta.i64Field(3, int64(rows))
+	meta.listField(4, thriftStruct, 1)
+	meta.begin()
+	meta.listField(1, thriftStruct, len(columns))
+	var total int64
+	for i, col := range columns {
+		meta.begin()
+		meta.i64Field(2, offsets[i])
+		meta.structField(3)
+		meta.i32Field(1, col.typ)
+		meta.listField(2, thriftI32, 1)
+		meta.i32Elem(parquetPlain)
+		meta.listField(3, thriftBinary, 1)
+		meta.stringElem(col.name)
+		meta.i32Field(4, parquetUncompressed)
+		meta.i64Field(5, int64(rows))
+		meta.i64Field(6, sizes[i])
+		meta.i64Field(7, sizes[i])
+		meta.i64Field(9, offsets[i])
+		meta.end()
+		meta.end()
+		total += sizes[i]
+	}
+	meta.i64Field(2, total)
+	meta.i64Field(3, int64(rows))
+	meta.end()
+	meta.stringField(6, "rockets")
+	meta.end()
+
+	out.Write(meta.Bytes())
+	out.Write(binary.LittleEndian.AppendUint32(nil, uint32(meta.Len())))
+	out.WriteString(parquetMagic)
+	if _, err := w.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("can't write parquet file: %w", err)
+	}
+	return nil
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// compactWriter encodes Thrift structs in the compact protocol the Parquet metadata is written in
+type compactWriter struct {
+	bytes.Buffer
+	// fields - id of the last field written to each open struct, field headers are deltas of it
+	fields []int16
+}
+
+// begin opens a struct, e.g. the top-level one or an element of a list
+func (w *compactWriter) begin() {
+	w.fields = append(w.fields, 0)
+}
+
+// end writes the stop field of the innermost open struct
+func (w *compactWriter) end() {
+	w.WriteByte(0)
+	w.fields = w.fields[:len(w.fields)-1]
+}
+
+func (w *compactWriter) header(id int16, typ byte) {
+	last := &w.fields[len(w.fields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.WriteByte(typ)
+		w.varint(zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (w *compactWriter) structField(id int16) {
+	w.header(id, thriftStruct)
+	w.begin()
+}
+
+func (w *compactWriter) i32Field(id int16, v int32) {
+	w.header(id, thriftI32)
+	w.i32Elem(v)
+}
+
+func (w *compactWriter) i64Field(id int16, v int64) {
+	w.header(id, thriftI64)
+	w.varint(zigzag(v))
+}
+
+func (w *compactWriter) stringField(id int16, s string) {
+	w.header(id, thriftBinary)
+	w.stringElem(s)
+}
+
+// listField writes the header of a list of n elements, which are written next
+func (w *compactWriter) listField(id int16, elem byte, n int) {
+	w.header(id, thriftList)
+	if n < 15 {
+		w.WriteByte(byte(n)<<4 | elem)
+		return
+	}
+	w.WriteByte(0xf0 | elem)
+	w.varint(uint64(n))
+}
+
+func (w *compactWriter) i32Elem(v int32) {
+	w.varint(zigzag(int64(v)))
+}
+
+func (w *compactWriter) stringElem(s string) {
+	w.varint(uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (w *compactWriter) varint(v uint64) {
+	w.Write(binary.AppendUvarint(nil, v))
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,227 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteParquet(t *testing.T) {
+	launched := time.Date(2022, 2, 2, 19, 39, 5, 86_000_000, time.UTC)
+	var buf bytes.Buffer
+	err := writeParquet(&buf, 3, []parquetColumn{
+		stringColumn("name", []string{"Falcon-9", "", "Союз"}),
+		int64Column("speed", []int64{500, -1, 1 << 40}),
+		timestampColumn("time", []time.Time{launched, launched.Add(time.Hour), time.UnixMilli(0)}),
+		boolColumn("flag", []bool{true, false, true}),
+	})
+	if err != nil {
+		t.Fatalf("writeParquet failed: %v", err)
+	}
+
+	rows, columns, err := readParquet(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Can't read the written file: %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("Expected 3 rows, got %d", rows)
+	}
+	expected := map[string][]any{
+		"name":  {"Falcon-9", "", "Союз"},
+		"speed": {int64(500), int64(-1), int64(1 << 40)},
+		"time":  {launched.UnixMilli(), launched.Add(time.Hour).UnixMilli(), int64(0)},
+		"flag":  {true, false, true},
+	}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("Expected columns %v, got %v", expected, columns)
+	}
+}
+
+// readParquet reads back the files writeParquet writes: the footer is decoded from the Thrift compact
+// protocol, and the plain encoded page of every column chunk is located through its metadata and page header
+func readParquet(data []byte) (int64, map[string][]any, error) {
+	if len(data) < 12 || !bytes.HasPrefix(data, []byte(parquetMagic)) || !bytes.HasSuffix(data, []byte(parquetMagic)) {
+		return 0, nil, fmt.Errorf("not framed by %s", parquetMagic)
+	}
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if size > len(data)-12 {
+		return 0, nil, fmt.Errorf("footer length %d out of the file", size)
+	}
+	footer := &compactReader{data: data[len(data)-8-size : len(data)-8]}
+	meta := footer.readStruct()
+	if footer.err != nil {
+		return 0, nil, fmt.Errorf("can't read footer: %w", footer.err)
+	}
+
+	schema, _ := meta[2].([]any)
+	rows, _ := meta[3].(int64)
+	groups, _ := meta[4].([]any)
+	if len(schema) == 0 || len(groups) != 1 {
+		return 0, nil, fmt.Errorf("expected a schema and one row group, got %v", meta)
+	}
+	chunks, _ := groups[0].(map[int16]any)[1].([]any)
+	if len(chunks) != len(schema)-1 {
+		return 0, nil, fmt.Errorf("expected %d column chunks, got %d", len(schema)-1, len(chunks))
+	}
+
+	columns := make(map[string][]any)
+	for i, chunk := range chunks {
+		element := schema[i+1].(map[int16]any)
+		name, _ := element[4].(string)
+		chunkMeta, _ := chunk.(map[int16]any)[3].(map[int16]any)
+		typ, _ := chunkMeta[1].(int64)
+		if path, _ := chunkMeta[3].([]any); len(path) != 1 || path[0] != name {
+			return 0, nil, fmt.Errorf("column %s has path %v", name, path)
+		}
+		if element[1] != typ {
+			return 0, nil, fmt.Errorf("column %s has type %v in the schema and %d in the chunk", name, element[1], typ)
+		}
+		offset, _ := chunkMeta[9].(int64)
+		if offset <= 0 || offset >= int64(len(data)) {
+			return 0, nil, fmt.Errorf("column %s at offset %d out of the file", name, offset)
+		}
+		pages := &compactReader{data: data[offset:]}
+		header := pages.readStruct()
+		if pages.err != nil {
+			return 0, nil, fmt.Errorf("can't read page header of column %s: %w", name, pages.err)
+		}
+		pageSize, _ := header[3].(int64)
+		dataPage, _ := header[5].(map[int16]any)
+		if header[1] != int64(parquetDataPage) || dataPage[1] != rows || dataPage[2] != int64(parquetPlain) {
+			return 0, nil, fmt.Errorf("column %s has page header %v", name, header)
+		}
+		start := offset + int64(pages.pos)
+		if start+pageSize > int64(len(data)) {
+			return 0, nil, fmt.Errorf("page of column %s out of the file", name)
+		}
+		values, err := decodePlain(typ, int(rows), data[start:start+pageSize])
+		if err != nil {
+			return 0, nil, fmt.Errorf("can't decode column %s: %w", name, err)
+		}
+		columns[name] = values
+	}
+	return rows, columns, nil
+}
+
+func decodePlain(typ int64, n int, page []byte) ([]any, error) {
+	values := make([]any, 0, n)
+	switch typ {
+	case parquetInt64:
+		if len(page) != 8*n {
+			return nil, fmt.Errorf("expected %d bytes, got %d", 8*n, len(page))
+		}
+		for i := 0; i < n; i++ {
+			values = append(values, int64(binary.LittleEndian.Uint64(page[8*i:])))
+		}
+	case parquetByteArray:
+		for i := 0; i < n; i++ {
+			if len(page) < 4 || int(binary.LittleEndian.Uint32(page)) > len(page)-4 {
+				return nil, fmt.Errorf("value %d out of the page", i)
+			}
+			l := int(binary.LittleEndian.Uint32(page))
+			values = append(values, string(page[4:4+l]))
+			page = page[4+l:]
+		}
+		if len(page) != 0 {
+			return nil, fmt.Errorf("%d bytes left after the values", len(page))
+		}
+	case parquetBoolean:
+		if len(page) != (n+7)/8 {
+			return nil, fmt.Errorf("expected %d bytes, got %d", (n+7)/8, len(page))
+		}
+		for i := 0; i < n; i++ {
+			values = append(values, page[i/8]&(1<<(i%8)) != 0)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected type %d", typ)
+	}
+	return values, nil
+}
+
+// compactReader decodes Thrift compact protocol structs into maps of field ids to their values: integers
+// as int64, binaries as string, lists as []any and structs as map[int16]any
+type compactReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *compactReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for r.err == nil {
+		b := r.byte()
+		if b == 0 {
+			break
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		last = id
+		fields[id] = r.value(b & 0x0f)
+	}
+	return fields
+}
+
+func (r *compactReader) value(typ byte) any {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 3:
+		return int64(int8(r.byte()))
+	case 4, thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.varint())
+		if n > len(r.data)-r.pos {
+			r.err = fmt.Errorf("binary of %d bytes at %d out of the data", n, r.pos)
+			return ""
+		}
+		r.pos += n
+		return string(r.data[r.pos-n : r.pos])
+	case thriftList:
+		b := r.byte()
+		n := int(b >> 4)
+		if n == 15 {
+			n = int(r.varint())
+		}
+		var list []any
+		for i := 0; i < n && r.err == nil; i++ {
+			list = append(list, r.value(b&0x0f))
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		r.err = fmt.Errorf("unexpected type %d at %d", typ, r.pos)
+		return nil
+	}
+}
+
+func (r *compactReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.err = fmt.Errorf("unexpected end of data")
+		return 0
+	}
+	r.pos++
+	return r.data[r.pos-1]
+}
+
+func (r *compactReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.err = fmt.Errorf("bad varint at %d", r.pos)
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
@@ -0,0 +1,243 @@
+package fleet
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"rockets/internal/jsonfile"
+	"rockets/internal/rocket"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidFleet - the name is empty, too long or has characters other than letters, digits, '.', '_' and '-'
+	ErrInvalidFleet = errors.New("invalid fleet")
+	// ErrFleetExists - a fleet with the name already exists
+	ErrFleetExists = errors.New("fleet already exists")
+	// ErrFleetNotFound - no fleet has the name
+	ErrFleetNotFound = errors.New("fleet not found")
+)
+
+// Fleet - named group of rockets tracked as one unit, e.g. a booster family or a constellation deployment
+type Fleet struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Rockets     []uuid.UUID `json:"rockets"`
+}
+
+// Stats - aggregate of the states of the rockets of a fleet
+type Stats struct {
+	// Rockets - rockets of the fleet, Tracked - the ones that have sent a message
+	Rockets  int
+	Tracked  int
+	Launched int
+	Exploded int
+	// Other - rockets with another status, e.g. PARTIAL
+	Other        int
+	AverageSpeed rocket.Speed
+	MaxSpeed     rocket.Speed
+	// Missions - distinct missions of the tracked rockets, sorted
+	Missions []string
+	// LastUpdateTime - latest update of a rocket of the fleet, zero when none is tracked
+	LastUpdateTime time.Time
+}
+
+// Summarize aggregates the states of the rockets of the fleet; states of other rockets are ignored.
+func Summarize(f Fleet, states []rocket.State) Stats {
+	stats := Stats{Rockets: len(f.Rockets), Missions: []string{}}
+	var total rocket.Speed
+	for _, state := range states {
+		if !slices.Contains(f.Rockets, state.ID) {
+			continue
+		}
+		stats.Tracked++
+		switch state.Status {
+		case rocket.StatusLaunched:
+			stats.Launched++
+		case rocket.StatusExploded:
+			stats.Exploded++
+		default:
+			stats.Other++
+		}
+		total += state.CurrentSpeed
+		stats.MaxSpeed = max(stats.MaxSpeed, state.CurrentSpeed)
+		if state.Mission != "" && !slices.Contains(stats.Missions, string(state.Mission)) {
+			stats.Missions = append(stats.Missions, string(state.Mission))
+		}
+		if state.LastUpdateTime.After(stats.LastUpdateTime) {
+			stats.LastUpdateTime = state.LastUpdateTime
+		}
+	}
+	if stats.Tracked > 0 {
+		stats.AverageSpeed = total / rocket.Speed(stats.Tracked)
+	}
+	sort.Strings(stats.Missions)
+	return stats
+}
+
+// Registry - fleets by name, unique regardless of case. A rocket may belong to several fleets.
+type Registry struct {
+	mu sync.RWMutex
+	// fleets - fleets by the lower-cased name
+	fleets map[string]Fleet
+	// file - JSON file the fleets are saved to on every change, empty keeps them in memory only
+	file string
+}
+
+// NewRegistry creates a registry keeping the fleets in memory only.
+func NewRegistry() *Registry {
+	return &Registry{fleets: make(map[string]Fleet)}
+}
+
+// Open creates a registry saving the fleets to the file, loading the ones saved before if it exists.
+func Open(file string) (*Registry, error) {
+	r := NewRegistry()
+	r.file = file
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Refresh loads the fleets from the file again, picking up the changes saved by the leader while in standby.
+// A registry keeping them in memory only is left as it is.
+func (r *Registry) Refresh() error {
+	if r == nil || r.file == "" {
+		return nil
+	}
+	var fleets []Fleet
+	if _, err := jsonfile.Load(r.file, "fleets", &fleets); err != nil {
+		return err
+	}
+	byName := make(map[string]Fleet, len(fleets))
+	for _, f := range fleets {
+		byName[strings.ToLower(f.Name)] = f
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fleets = byName
+	return nil
+}
+
+// Create adds the fleet. It fails with ErrInvalidFleet or ErrFleetExists.
+func (r *Registry) Create(f Fleet) (Fleet, error) {
+	f, err := normalize(f)
+	if err != nil {
+		return Fleet{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(f.Name)
+	if _, ok := r.fleets[key]; ok {
+		return Fleet{}, fmt.Errorf("%w: %s", ErrFleetExists, f.Name)
+	}
+	r.fleets[key] = f
+	if err := r.saveLocked(); err != nil {
+		delete(r.fleets, key)
+		return Fleet{}, err
+	}
+	return f, nil
+}
+
+// Update replaces the description and the rockets of the fleet with the name, keeping the name.
+// It fails with ErrInvalidFleet or ErrFleetNotFound.
+func (r *Registry) Update(name string, f Fleet) (Fleet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(name)
+	prev, ok := r.fleets[key]
+	if !ok {
+		return Fleet{}, fmt.Errorf("%w: %s", ErrFleetNotFound, name)
+	}
+	f.Name = prev.Name
+	f, err := normalize(f)
+	if err != nil {
+		return Fleet{}, err
+	}
+	r.fleets[key] = f
+	if err := r.saveLocked(); err != nil {
+		r.fleets[key] = prev
+		return Fleet{}, err
+	}
+	return f, nil
+}
+
+// Delete drops the fleet with the name, returning false if there is none
+func (r *Registry) Delete(name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(name)
+	prev, ok := r.fleets[key]
+	if !ok {
+		return false, nil
+	}
+	delete(r.fleets, key)
+	if err := r.saveLocked(); err != nil {
+		r.fleets[key] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+// Get returns the fleet with the name, in any case
+func (r *Registry) Get(name string) (Fleet, bool) {
+	if r == nil {
+		return Fleet{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.fleets[strings.ToLower(name)]
+	return f, ok
+}
+
+// List returns the fleets sorted by name
+func (r *Registry) List() []Fleet {
+	if r == nil {
+		return []Fleet{}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.listLocked()
+}
+
+func (r *Registry) listLocked() []Fleet {
+	fleets := make([]Fleet, 0, len(r.fleets))
+	for _, f := range r.fleets {
+		fleets = append(fleets, f)
+	}
+	sort.Slice(fleets, func(i, j int) bool {
+		return fleets[i].Name < fleets[j].Name
+	})
+	return fleets
+}
+
+// normalize validates the fleet and sorts its rockets, dropping duplicates
+func normalize(f Fleet) (Fleet, error) {
+	if !jsonfile.ValidName.MatchString(f.Name) {
+		return Fleet{}, fmt.Errorf("%w: name %q", ErrInvalidFleet, f.Name)
+	}
+	if len(f.Description) > 256 {
+		return Fleet{}, fmt.Errorf("%w: description longer than 256 characters", ErrInvalidFleet)
+	}
+	rockets := slices.Clone(f.Rockets)
+	slices.SortFunc(rockets, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	f.Rockets = slices.Compact(rockets)
+	if f.Rockets == nil {
+		f.Rockets = []uuid.UUID{}
+	}
+	return f, nil
+}
+
+// saveLocked writes the fleets to the file
+func (r *Registry) saveLocked() error {
+	if r.file == "" {
+		return nil
+	}
+	return jsonfile.Save(r.file, "fleets", r.listLocked())
+}
@@ -0,0 +1,88 @@
+package fleet
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"path/filepath"
+	"reflect"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fleets.json")
+	r, err := Open(file)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first, second := uuid.New(), uuid.New()
+
+	if _, err := r.Create(Fleet{Name: "Falcon-boosters", Rockets: []uuid.UUID{first, first}}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := r.Create(Fleet{Name: "falcon-BOOSTERS"}); !errors.Is(err, ErrFleetExists) {
+		t.Errorf("Expected ErrFleetExists for a name taken in another case, got %v", err)
+	}
+	if _, err := r.Create(Fleet{Name: "no spaces"}); !errors.Is(err, ErrInvalidFleet) {
+		t.Errorf("Expected ErrInvalidFleet, got %v", err)
+	}
+	if _, err := r.Update("starlink", Fleet{}); !errors.Is(err, ErrFleetNotFound) {
+		t.Errorf("Expected ErrFleetNotFound, got %v", err)
+	}
+
+	updated, err := r.Update("falcon-boosters", Fleet{Name: "ignored", Description: "B10xx", Rockets: []uuid.UUID{second, first}})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Name != "Falcon-boosters" || len(updated.Rockets) != 2 {
+		t.Errorf("Expected the fleet updated under its name, got %+v", updated)
+	}
+
+	reopened, err := Open(file)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if f, ok := reopened.Get("FALCON-BOOSTERS"); !ok || !reflect.DeepEqual(f, updated) {
+		t.Errorf("Expected the fleet to survive a restart, got %+v", f)
+	}
+	if deleted, err := reopened.Delete("falcon-boosters"); !deleted || err != nil {
+		t.Errorf("Expected the fleet deleted, got %v, %v", deleted, err)
+	}
+	if list := reopened.List(); len(list) != 0 {
+		t.Errorf("Expected no fleets left, got %+v", list)
+	}
+
+	// a standby reading the file of the leader
+	if err := r.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if _, ok := r.Get("falcon-boosters"); ok {
+		t.Errorf("Expected the fleet deleted by the other registry to be gone after a refresh")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	first, second, third, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	at := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+	f := Fleet{Name: "starlink", Rockets: []uuid.UUID{first, second, third}}
+	states := []rocket.State{
+		{ID: first, Status: rocket.StatusLaunched, CurrentSpeed: 1000, Mission: "ARTEMIS", LastUpdateTime: at},
+		{ID: second, Status: rocket.StatusExploded, CurrentSpeed: 3000, Mission: "APOLLO", LastUpdateTime: at.Add(time.Minute)},
+		{ID: other, Status: rocket.StatusLaunched, CurrentSpeed: 9000, Mission: "GEMINI", LastUpdateTime: at.Add(time.Hour)},
+	}
+
+	expected := Stats{
+		Rockets:        3,
+		Tracked:        2,
+		Launched:       1,
+		Exploded:       1,
+		AverageSpeed:   2000,
+		MaxSpeed:       3000,
+		Missions:       []string{"APOLLO", "ARTEMIS"},
+		LastUpdateTime: at.Add(time.Minute),
+	}
+	if got := Summarize(f, states); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
@@ -0,0 +1,135 @@
+// Package health tells whether the instance can apply messages, from the checks of the store and the ingestion,
+// and lets the consumers of message queues pause while it can't instead of failing the messages they consume.
+package health
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"rockets/internal/metrics"
+	"sync"
+	"time"
+)
+
+// Check - named check of a component, failing while the instance can't apply messages
+type Check struct {
+	Name  string
+	Check func() error
+}
+
+// Monitor runs the checks on an interval and holds the consumers while one of them fails. The time the
+// consumers spend paused is counted in rockets_consumer_paused_seconds_total.
+type Monitor struct {
+	checks []Check
+	clock  clock.Clock
+	logger *zap.Logger
+	// paused, pausedNow - paused time and the consumers paused now, nil without metrics
+	paused    *metrics.Counter
+	pausedNow *metrics.Gauge
+
+	mu  sync.Mutex
+	err error
+	// changed - closed when the outcome of the checks changes
+	changed chan struct{}
+}
+
+// NewMonitor creates a monitor of the checks, healthy until they first run.
+func NewMonitor(checks []Check, logger *zap.Logger) *Monitor {
+	return &Monitor{
+		checks:  checks,
+		clock:   clock.Real{},
+		logger:  logger,
+		changed: make(chan struct{}),
+	}
+}
+
+// UseClock replaces the system clock the paused time is measured by. Must be called before the monitor is used.
+func (m *Monitor) UseClock(c clock.Clock) {
+	m.clock = c
+}
+
+// UseMetrics counts the paused time of the consumers in the registry. Must be called before the monitor is used.
+func (m *Monitor) UseMetrics(registry *metrics.Registry) {
+	m.paused = registry.Counter("rockets_consumer_paused_seconds_total", "Time the queue consumers spent paused while the health checks failed.", "consumer")
+	m.pausedNow = registry.Gauge("rockets_consumer_paused", "Whether the queue consumer is paused by failed health checks.", "consumer")
+}
+
+// Run runs the checks every interval until the context is done
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Check runs the checks now, returning the first failure. A change of the outcome is logged and wakes the
+// paused consumers.
+func (m *Monitor) Check() error {
+	var err error
+	for _, c := range m.checks {
+		if cerr := c.Check(); cerr != nil {
+			err = fmt.Errorf("%s: %w", c.Name, cerr)
+			break
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if (err == nil) != (m.err == nil) {
+		if err != nil {
+			m.logger.Warn("Health check failed, pausing the consumers", zap.Error(err))
+		} else {
+			m.logger.Info("Health checks passed again, resuming the consumers")
+		}
+		close(m.changed)
+		m.changed = make(chan struct{})
+	}
+	m.err = err
+	return err
+}
+
+// Err returns the failure of the last checks, nil while healthy
+func (m *Monitor) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// Wait holds the consumer while the checks fail, returning once they pass or with the error of the context.
+// The time it held the consumer is counted for it.
+func (m *Monitor) Wait(ctx context.Context, consumer string) error {
+	m.mu.Lock()
+	if m.err == nil {
+		m.mu.Unlock()
+		return nil
+	}
+	since := m.clock.Now()
+	if m.pausedNow != nil {
+		m.pausedNow.Set(1, consumer)
+	}
+	defer func() {
+		if m.paused != nil {
+			m.paused.Add(m.clock.Now().Sub(since).Seconds(), consumer)
+			m.pausedNow.Set(0, consumer)
+		}
+	}()
+	for m.err != nil {
+		changed := m.changed
+		m.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+		m.mu.Lock()
+	}
+	m.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"rockets/internal/metrics"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMonitor_Wait(t *testing.T) {
+	var failure atomic.Pointer[error]
+	now := clock.NewFake(time.Date(2022, 2, 2, 20, 0, 0, 0, time.UTC))
+	registry := metrics.NewRegistry()
+	m := NewMonitor([]Check{
+		{Name: "service", Check: func() error { return nil }},
+		{Name: "store", Check: func() error {
+			if err := failure.Load(); err != nil {
+				return *err
+			}
+			return nil
+		}},
+	}, zap.NewNop())
+	m.UseClock(now)
+	m.UseMetrics(registry)
+
+	if err := m.Wait(context.Background(), "kafka"); err != nil {
+		t.Fatalf("Expected a healthy monitor not to hold the consumer, got %v", err)
+	}
+
+	stalled := errors.New("store write running for 12s")
+	failure.Store(&stalled)
+	if err := m.Check(); !errors.Is(err, stalled) || err.Error() != "store: store write running for 12s" {
+		t.Fatalf("Expected the failure of the store check, got %v", err)
+	}
+	resumed := make(chan error, 1)
+	go func() { resumed <- m.Wait(context.Background(), "kafka") }()
+	select {
+	case err := <-resumed:
+		t.Fatalf("Expected the consumer to be held, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	now.Advance(3 * time.Second)
+	failure.Store(nil)
+	m.Check()
+	select {
+	case err := <-resumed:
+		if err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the consumer to resume once the checks pass")
+	}
+	if v := m.paused.Value("kafka"); v != 3 {
+		t.Errorf("Expected 3s paused, got %v", v)
+	}
+	if v := m.pausedNow.Value("kafka"); v != 0 {
+		t.Errorf("Expected the consumer not to be paused, got %v", v)
+	}
+
+	// a consumer stopping while paused is let go
+	failure.Store(&stalled)
+	m.Check()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.Wait(ctx, "kafka"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+}
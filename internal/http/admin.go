@@ -0,0 +1,1479 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"net/http"
+	"rockets/internal/capture"
+	"rockets/internal/export"
+	"rockets/internal/fleet"
+	"rockets/internal/http/gen"
+	"rockets/internal/incident"
+	"rockets/internal/latency"
+	"rockets/internal/logging"
+	"rockets/internal/maintenance"
+	"rockets/internal/names"
+	"rockets/internal/partition"
+	"rockets/internal/rocket"
+	"rockets/internal/warehouse"
+	"rockets/internal/watchlist"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// capturesPath - admin route of the captured exchanges, never captured itself
+const capturesPath = "/admin/captures"
+
+// AdminServer serves operational endpoints that are not part of the public API
+type AdminServer struct {
+	rocket  rocket.Service
+	capture *capture.Recorder
+	levels  *logging.Levels
+	logger  *zap.Logger
+	names   *names.Registry
+	fleets  *fleet.Registry
+	export  *export.Exporter
+	sink    *warehouse.Sink
+	// watchlists - watchlists of the operators, nil disables their endpoints
+	watchlists *watchlist.Registry
+	// incidents - incident records of the exploded rockets, nil disables the incident endpoints
+	incidents *incident.Registry
+	// maintenance, suppressor - maintenance windows, nil disables their endpoints, and the alerts they suppressed
+	maintenance *maintenance.Registry
+	suppressor  *maintenance.Suppressor
+	// partitions - channel ownership of the replica, nil when every replica ingests every channel
+	partitions partition.Owner
+	// handoffSecret - secret the handoffs of the other replicas are signed with, nil refuses them
+	handoffSecret []byte
+	pins          *rocket.Pins
+	latency       *latency.Tracker
+	// echo, basePath - server whose routes are listed and the base path of its API routes
+	echo     *echo.Echo
+	basePath string
+}
+
+// NewAdminServer creates the admin API handlers.
+func NewAdminServer(opts *ServerOpts) *AdminServer {
+	return &AdminServer{
+		rocket:  opts.Rocket,
+		capture: opts.Capture,
+		levels:  opts.Levels,
+		logger:  opts.Logger,
+		names:   opts.Names,
+		fleets:  opts.Fleets,
+		export:  opts.Export,
+		sink:    opts.Sink,
+
+		watchlists:  opts.Watchlists,
+		incidents:   opts.Incidents,
+		maintenance: opts.Maintenance,
+		suppressor:  opts.Suppressor,
+		partitions:  opts.Partitions,
+		pins:        opts.Pins,
+		latency:     opts.Latency,
+
+		handoffSecret: opts.HandoffSecret,
+
+		echo:     opts.Echo,
+		basePath: opts.BasePath,
+	}
+}
+
+// AttachAdminRoutes attaches the admin API routes to the provided router, usually the /admin group.
+func AttachAdminRoutes(router gen.EchoRouter, admin *AdminServer) {
+	router.POST(
+		"/rockets/:id/rollback",
+		admin.RollbackRocket,
+	)
+	router.POST(
+		"/rockets/:id/merge",
+		admin.MergeChannels,
+	)
+	router.POST(
+		"/rockets/:id/clone",
+		admin.CloneRocket,
+	)
+	router.GET(
+		"/quarantine",
+		admin.ListQuarantinedChannels,
+	)
+	router.PUT(
+		"/quarantine/:id",
+		admin.QuarantineChannel,
+	)
+	router.DELETE(
+		"/quarantine/:id",
+		admin.ReleaseChannel,
+	)
+	router.GET(
+		"/debug-traces",
+		admin.ListTracedChannels,
+	)
+	router.PUT(
+		"/debug-traces/:id",
+		admin.TraceChannel,
+	)
+	router.DELETE(
+		"/debug-traces/:id",
+		admin.UntraceChannel,
+	)
+	router.GET(
+		"/dead-letters",
+		admin.ListDeadLetters,
+	)
+	router.POST(
+		"/dead-letters/redrive",
+		admin.RedriveDeadLetters,
+	)
+	router.DELETE(
+		"/dead-letters",
+		admin.ClearDeadLetters,
+	)
+	router.GET(
+		"/gaps",
+		admin.ListGaps,
+	)
+	router.POST(
+		"/consistency-check",
+		admin.CheckConsistency,
+	)
+	router.GET(
+		"/captures",
+		admin.ListCaptures,
+	)
+	router.DELETE(
+		"/captures",
+		admin.ClearCaptures,
+	)
+	router.GET(
+		"/routes",
+		admin.ListRoutes,
+	)
+	if admin.names != nil {
+		router.GET(
+			"/names",
+			admin.ListNames,
+		)
+		router.PUT(
+			"/rockets/:id/name",
+			admin.AssignName,
+		)
+		router.DELETE(
+			"/rockets/:id/name",
+			admin.RemoveName,
+		)
+	}
+	if admin.fleets != nil {
+		router.POST(
+			"/fleets",
+			admin.CreateFleet,
+		)
+		router.PUT(
+			"/fleets/:name",
+			admin.UpdateFleet,
+		)
+		router.DELETE(
+			"/fleets/:name",
+			admin.DeleteFleet,
+		)
+	}
+	if admin.watchlists != nil {
+		router.POST(
+			"/watchlists",
+			admin.CreateWatchlist,
+		)
+		router.PUT(
+			"/watchlists/:name",
+			admin.UpdateWatchlist,
+		)
+		router.DELETE(
+			"/watchlists/:name",
+			admin.DeleteWatchlist,
+		)
+	}
+	if admin.incidents != nil {
+		router.GET(
+			"/incidents",
+			admin.ListIncidents,
+		)
+		router.POST(
+			"/incidents",
+			admin.CreateIncident,
+		)
+		router.GET(
+			"/incidents/:incident",
+			admin.GetIncident,
+		)
+		router.PATCH(
+			"/incidents/:incident",
+			admin.UpdateIncident,
+		)
+		router.DELETE(
+			"/incidents/:incident",
+			admin.DeleteIncident,
+		)
+	}
+	if admin.maintenance != nil {
+		router.GET(
+			"/maintenance",
+			admin.ListMaintenanceWindows,
+		)
+		router.POST(
+			"/maintenance",
+			admin.CreateMaintenanceWindow,
+		)
+		router.GET(
+			"/maintenance/suppressed",
+			admin.ListSuppressedAlerts,
+		)
+		router.GET(
+			"/maintenance/:window",
+			admin.GetMaintenanceWindow,
+		)
+		router.PUT(
+			"/maintenance/:window",
+			admin.UpdateMaintenanceWindow,
+		)
+		router.DELETE(
+			"/maintenance/:window",
+			admin.DeleteMaintenanceWindow,
+		)
+	}
+	if admin.export != nil {
+		router.POST(
+			"/export",
+			admin.ExportHistory,
+		)
+	}
+	if admin.sink != nil {
+		router.GET(
+			"/warehouse",
+			admin.GetWarehouseStatus,
+		)
+	}
+	if admin.partitions != nil {
+		router.GET(
+			"/partitions",
+			admin.GetPartitions,
+		)
+		if admin.handoffSecret != nil {
+			router.POST(
+				"/handoff",
+				admin.AcceptHandoff,
+			)
+		}
+	}
+	if admin.latency != nil {
+		router.GET(
+			"/latency",
+			admin.GetLatency,
+		)
+	}
+	if admin.pins != nil {
+		router.GET(
+			"/pins",
+			admin.ListPins,
+		)
+		router.PUT(
+			"/pins/channels/:id",
+			admin.PinChannel,
+		)
+		router.DELETE(
+			"/pins/channels/:id",
+			admin.UnpinChannel,
+		)
+		router.PUT(
+			"/pins/missions/:mission",
+			admin.PinMission,
+		)
+		router.DELETE(
+			"/pins/missions/:mission",
+			admin.UnpinMission,
+		)
+	}
+	if admin.levels != nil {
+		router.GET(
+			"/log-level",
+			admin.GetLogLevel,
+		)
+		router.PUT(
+			"/log-level",
+			admin.SetLogLevel,
+		)
+	}
+}
+
+// parseID parses the rocket id path parameter, writing a 400 response if it is malformed
+func parseID(c echo.Context) (uuid.UUID, bool, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, false, c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidId,
+			Message: fmt.Sprintf("invalid rocket id: %s", c.Param("id")),
+		})
+	}
+	return id, true, nil
+}
+
+// parseChannel parses the optional channel query parameter, uuid.Nil when it is absent, writing a 400 response
+// if it is malformed
+func parseChannel(c echo.Context) (uuid.UUID, bool, error) {
+	if c.QueryParam("channel") == "" {
+		return uuid.Nil, true, nil
+	}
+	id, err := uuid.Parse(c.QueryParam("channel"))
+	if err != nil {
+		return uuid.Nil, false, c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidId,
+			Message: fmt.Sprintf("invalid channel: %s", c.QueryParam("channel")),
+		})
+	}
+	return id, true, nil
+}
+
+// RollbackRequest - body of the rollback operation
+type RollbackRequest struct {
+	// MessageNumber - the first message to roll back, it and all later messages are superseded
+	MessageNumber int64 `json:"messageNumber"`
+}
+
+// RollbackRocket restores a rocket to the state prior to the given message.
+func (a *AdminServer) RollbackRocket(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	var req RollbackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	state, err := a.rocket.RollbackRocket(c.Request().Context(), id, req.MessageNumber)
+	switch {
+	case errors.Is(err, rocket.ErrRocketNotFound), errors.Is(err, rocket.ErrMessageNotFound):
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: err.Error(),
+		})
+	case errors.Is(err, rocket.ErrNoPriorState), errors.Is(err, rocket.ErrHistoryDisabled):
+		return c.JSON(http.StatusConflict, gen.ErrorResponse{
+			Code:    gen.ErrorCodeRollbackImpossible,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, stateToServer(state))
+}
+
+// MergeRequest - body of the merge operation
+type MergeRequest struct {
+	// Channel - the channel merged into the rocket, removed once merged
+	Channel uuid.UUID `json:"channel"`
+}
+
+// MergeResponse - the rocket recomputed by the merge and how the messages of the channels were combined
+type MergeResponse struct {
+	State  gen.RocketState     `json:"state"`
+	Merged int                 `json:"merged"`
+	Epochs []rocket.MergeEpoch `json:"epochs"`
+}
+
+// MergeChannels merges the history of another channel into the rocket, keeping the rocket's ID.
+func (a *AdminServer) MergeChannels(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	var req MergeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+	if req.Channel == uuid.Nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: "channel is required",
+		})
+	}
+
+	report, err := a.rocket.MergeChannels(c.Request().Context(), id, req.Channel)
+	switch {
+	case errors.Is(err, rocket.ErrSameChannel):
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidMerge,
+			Message: err.Error(),
+		})
+	case errors.Is(err, rocket.ErrRocketNotFound):
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: err.Error(),
+		})
+	case errors.Is(err, rocket.ErrHistoryDisabled), errors.Is(err, rocket.ErrHistoryTruncated):
+		return c.JSON(http.StatusConflict, gen.ErrorResponse{
+			Code:    gen.ErrorCodeMergeImpossible,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, MergeResponse{
+		State:  stateToServer(report.State),
+		Merged: report.Merged,
+		Epochs: report.Epochs,
+	})
+}
+
+// CloneRequest - body of the clone operation
+type CloneRequest struct {
+	// Channel - ID of the clone, a random one when absent
+	Channel *uuid.UUID `json:"channel,omitempty"`
+	// MessageNumber - the last message copied into the clone
+	MessageNumber int64 `json:"messageNumber"`
+}
+
+// CloneRocket copies the history of a rocket up to a message into a new rocket.
+func (a *AdminServer) CloneRocket(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	var req CloneRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+	clone := uuid.New()
+	if req.Channel != nil {
+		clone = *req.Channel
+	}
+
+	state, err := a.rocket.CloneRocket(c.Request().Context(), id, clone, req.MessageNumber)
+	switch {
+	case errors.Is(err, rocket.ErrSameChannel):
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidClone,
+			Message: err.Error(),
+		})
+	case errors.Is(err, rocket.ErrRocketNotFound), errors.Is(err, rocket.ErrMessageNotFound):
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: err.Error(),
+		})
+	case errors.Is(err, rocket.ErrRocketExists):
+		return c.JSON(http.StatusConflict, gen.ErrorResponse{
+			Code:    gen.ErrorCodeRocketExists,
+			Message: err.Error(),
+		})
+	case errors.Is(err, rocket.ErrHistoryDisabled):
+		return c.JSON(http.StatusConflict, gen.ErrorResponse{
+			Code:    gen.ErrorCodeCloneImpossible,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, stateToServer(state))
+}
+
+// NameRequest - body of the naming operation
+type NameRequest struct {
+	Name string `json:"name"`
+}
+
+// ListNames lists the names of the rockets.
+func (a *AdminServer) ListNames(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.names.List())
+}
+
+// AssignName names a rocket, replacing its previous name.
+func (a *AdminServer) AssignName(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	var req NameRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	entry, err := a.names.Assign(id, req.Name)
+	switch {
+	case errors.Is(err, names.ErrInvalidName):
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidName,
+			Message: err.Error(),
+		})
+	case errors.Is(err, names.ErrNameTaken):
+		return c.JSON(http.StatusConflict, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNameTaken,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+	a.logger.Info("Rocket named", zap.String("rocket_id", id.String()), zap.String("name", entry.Name))
+	return c.JSON(http.StatusOK, entry)
+}
+
+// RemoveName drops the name of a rocket.
+func (a *AdminServer) RemoveName(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	removed, err := a.names.Remove(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+	if !removed {
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("rocket %s has no name", id),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateFleet adds a fleet of rockets.
+func (a *AdminServer) CreateFleet(c echo.Context) error {
+	var req fleet.Fleet
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	f, err := a.fleets.Create(req)
+	if err != nil {
+		return fleetError(c, err)
+	}
+	a.logger.Info("Fleet created", zap.String("fleet", f.Name), zap.Int("rockets", len(f.Rockets)))
+	return c.JSON(http.StatusCreated, f)
+}
+
+// UpdateFleet replaces the description and the rockets of a fleet.
+func (a *AdminServer) UpdateFleet(c echo.Context) error {
+	var req fleet.Fleet
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	f, err := a.fleets.Update(c.Param("name"), req)
+	if err != nil {
+		return fleetError(c, err)
+	}
+	a.logger.Info("Fleet updated", zap.String("fleet", f.Name), zap.Int("rockets", len(f.Rockets)))
+	return c.JSON(http.StatusOK, f)
+}
+
+// DeleteFleet drops a fleet; its rockets are left as they are.
+func (a *AdminServer) DeleteFleet(c echo.Context) error {
+	deleted, err := a.fleets.Delete(c.Param("name"))
+	if err != nil {
+		return fleetError(c, err)
+	}
+	if !deleted {
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("fleet %s not found", c.Param("name")),
+		})
+	}
+	a.logger.Info("Fleet deleted", zap.String("fleet", c.Param("name")))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// fleetError answers a failed change of a fleet
+func fleetError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, fleet.ErrInvalidFleet):
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidFleet,
+			Message: err.Error(),
+		})
+	case errors.Is(err, fleet.ErrFleetExists):
+		return c.JSON(http.StatusConflict, gen.ErrorResponse{
+			Code:    gen.ErrorCodeFleetExists,
+			Message: err.Error(),
+		})
+	case errors.Is(err, fleet.ErrFleetNotFound):
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+		Code:    gen.ErrorCodeUnknown,
+		Message: err.Error(),
+	})
+}
+
+// CreateWatchlist adds a watchlist of rockets.
+func (a *AdminServer) CreateWatchlist(c echo.Context) error {
+	var req watchlist.Watchlist
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	w, err := a.watchlists.Create(req)
+	if err != nil {
+		return watchlistError(c, err)
+	}
+	a.logger.Info("Watchlist created", zap.String("watchlist", w.Name), zap.Int("rockets", len(w.Rockets)))
+	return c.JSON(http.StatusCreated, w)
+}
+
+// UpdateWatchlist replaces the description and the rockets of a watchlist.
+func (a *AdminServer) UpdateWatchlist(c echo.Context) error {
+	var req watchlist.Watchlist
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	w, err := a.watchlists.Update(c.Param("name"), req)
+	if err != nil {
+		return watchlistError(c, err)
+	}
+	a.logger.Info("Watchlist updated", zap.String("watchlist", w.Name), zap.Int("rockets", len(w.Rockets)))
+	return c.JSON(http.StatusOK, w)
+}
+
+// DeleteWatchlist drops a watchlist.
+func (a *AdminServer) DeleteWatchlist(c echo.Context) error {
+	deleted, err := a.watchlists.Delete(c.Param("name"))
+	if err != nil {
+		return watchlistError(c, err)
+	}
+	if !deleted {
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("watchlist %s not found", c.Param("name")),
+		})
+	}
+	a.logger.Info("Watchlist deleted", zap.String("watchlist", c.Param("name")))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// watchlistError answers a failed change of a watchlist
+func watchlistError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, watchlist.ErrInvalidWatchlist):
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidWatchlist,
+			Message: err.Error(),
+		})
+	case errors.Is(err, watchlist.ErrWatchlistExists):
+		return c.JSON(http.StatusConflict, gen.ErrorResponse{
+			Code:    gen.ErrorCodeWatchlistExists,
+			Message: err.Error(),
+		})
+	case errors.Is(err, watchlist.ErrWatchlistNotFound):
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+		Code:    gen.ErrorCodeUnknown,
+		Message: err.Error(),
+	})
+}
+
+// ListIncidents lists the incidents, the most recently opened first, optionally only the ones with a status or
+// of a rocket.
+func (a *AdminServer) ListIncidents(c echo.Context) error {
+	filter := incident.Filter{Status: incident.Status(c.QueryParam("status"))}
+	if filter.Status != "" && !filter.Status.Valid() {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidFilter,
+			Message: fmt.Sprintf("status must be open, acknowledged or closed, got %q", filter.Status),
+		})
+	}
+	if v := c.QueryParam("rocket"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+				Code:    gen.ErrorCodeInvalidId,
+				Message: fmt.Sprintf("invalid rocket id: %s", v),
+			})
+		}
+		filter.Rocket = id
+	}
+	return c.JSON(http.StatusOK, a.incidents.List(filter))
+}
+
+// CreateIncident opens an incident by hand, e.g. for a failure that did not end in an explosion.
+func (a *AdminServer) CreateIncident(c echo.Context) error {
+	var req incident.Draft
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	created, err := a.incidents.Create(req)
+	if err != nil {
+		return incidentError(c, err)
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// GetIncident returns an incident.
+func (a *AdminServer) GetIncident(c echo.Context) error {
+	id, ok, err := parseIncidentID(c)
+	if !ok {
+		return err
+	}
+	found, ok := a.incidents.Get(id)
+	if !ok {
+		return incidentError(c, fmt.Errorf("%w: %s", incident.ErrIncidentNotFound, id))
+	}
+	return c.JSON(http.StatusOK, found)
+}
+
+// UpdateIncident acknowledges, closes or reopens an incident, or changes its title, assignee or notes.
+func (a *AdminServer) UpdateIncident(c echo.Context) error {
+	id, ok, err := parseIncidentID(c)
+	if !ok {
+		return err
+	}
+	var req incident.Patch
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	updated, err := a.incidents.Update(id, req)
+	if err != nil {
+		return incidentError(c, err)
+	}
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteIncident drops an incident, e.g. one opened by mistake.
+func (a *AdminServer) DeleteIncident(c echo.Context) error {
+	id, ok, err := parseIncidentID(c)
+	if !ok {
+		return err
+	}
+	deleted, err := a.incidents.Delete(id)
+	if err != nil {
+		return incidentError(c, err)
+	}
+	if !deleted {
+		return incidentError(c, fmt.Errorf("%w: %s", incident.ErrIncidentNotFound, id))
+	}
+	a.logger.Info("Incident deleted", zap.String("incident_id", id.String()))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// parseIncidentID parses the incident id path parameter, writing a 400 response if it is malformed
+func parseIncidentID(c echo.Context) (uuid.UUID, bool, error) {
+	id, err := uuid.Parse(c.Param("incident"))
+	if err != nil {
+		return uuid.Nil, false, c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidId,
+			Message: fmt.Sprintf("invalid incident id: %s", c.Param("incident")),
+		})
+	}
+	return id, true, nil
+}
+
+// incidentError answers a failed operation on an incident
+func incidentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, incident.ErrInvalidIncident):
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidIncident,
+			Message: err.Error(),
+		})
+	case errors.Is(err, incident.ErrIncidentNotFound):
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+		Code:    gen.ErrorCodeUnknown,
+		Message: err.Error(),
+	})
+}
+
+// ListMaintenanceWindows lists the maintenance windows by start time, optionally only the ones active now.
+func (a *AdminServer) ListMaintenanceWindows(c echo.Context) error {
+	windows := a.maintenance.List()
+	if c.QueryParam("active") != "true" {
+		return c.JSON(http.StatusOK, windows)
+	}
+	now := time.Now()
+	active := make([]maintenance.Window, 0, len(windows))
+	for _, w := range windows {
+		if !now.Before(w.Start) && now.Before(w.End) {
+			active = append(active, w)
+		}
+	}
+	return c.JSON(http.StatusOK, active)
+}
+
+// CreateMaintenanceWindow adds a maintenance window.
+func (a *AdminServer) CreateMaintenanceWindow(c echo.Context) error {
+	var req maintenance.Window
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	w, err := a.maintenance.Create(req, time.Now())
+	if err != nil {
+		return maintenanceError(c, err)
+	}
+	a.logger.Info("Maintenance window created", zap.String("window", w.ID.String()), zap.String("name", w.Name), zap.Time("start", w.Start), zap.Time("end", w.End))
+	return c.JSON(http.StatusCreated, w)
+}
+
+// GetMaintenanceWindow returns a maintenance window.
+func (a *AdminServer) GetMaintenanceWindow(c echo.Context) error {
+	id, ok, err := parseWindowID(c)
+	if !ok {
+		return err
+	}
+	w, ok := a.maintenance.Get(id)
+	if !ok {
+		return maintenanceError(c, fmt.Errorf("%w: %s", maintenance.ErrWindowNotFound, id))
+	}
+	return c.JSON(http.StatusOK, w)
+}
+
+// UpdateMaintenanceWindow replaces a maintenance window, e.g. to extend a test campaign.
+func (a *AdminServer) UpdateMaintenanceWindow(c echo.Context) error {
+	id, ok, err := parseWindowID(c)
+	if !ok {
+		return err
+	}
+	var req maintenance.Window
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	w, err := a.maintenance.Update(id, req)
+	if err != nil {
+		return maintenanceError(c, err)
+	}
+	a.logger.Info("Maintenance window updated", zap.String("window", w.ID.String()), zap.String("name", w.Name), zap.Time("start", w.Start), zap.Time("end", w.End))
+	return c.JSON(http.StatusOK, w)
+}
+
+// DeleteMaintenanceWindow drops a maintenance window, the alerts it covered are delivered again.
+func (a *AdminServer) DeleteMaintenanceWindow(c echo.Context) error {
+	id, ok, err := parseWindowID(c)
+	if !ok {
+		return err
+	}
+	deleted, err := a.maintenance.Delete(id)
+	if err != nil {
+		return maintenanceError(c, err)
+	}
+	if !deleted {
+		return maintenanceError(c, fmt.Errorf("%w: %s", maintenance.ErrWindowNotFound, id))
+	}
+	a.logger.Info("Maintenance window deleted", zap.String("window", id.String()))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListSuppressedAlerts lists the alerts suppressed by the maintenance windows, newest first.
+func (a *AdminServer) ListSuppressedAlerts(c echo.Context) error {
+	if a.suppressor == nil {
+		return c.JSON(http.StatusOK, []maintenance.Suppressed{})
+	}
+	return c.JSON(http.StatusOK, a.suppressor.Suppressed())
+}
+
+// parseWindowID parses the maintenance window id path parameter, writing a 400 response if it is malformed
+func parseWindowID(c echo.Context) (uuid.UUID, bool, error) {
+	id, err := uuid.Parse(c.Param("window"))
+	if err != nil {
+		return uuid.Nil, false, c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidId,
+			Message: fmt.Sprintf("invalid maintenance window id: %s", c.Param("window")),
+		})
+	}
+	return id, true, nil
+}
+
+// maintenanceError answers a failed operation on a maintenance window
+func maintenanceError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, maintenance.ErrInvalidWindow):
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidMaintenanceWindow,
+			Message: err.Error(),
+		})
+	case errors.Is(err, maintenance.ErrWindowNotFound):
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+		Code:    gen.ErrorCodeUnknown,
+		Message: err.Error(),
+	})
+}
+
+// QuarantineRequest - body of the quarantine operation
+type QuarantineRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ListQuarantinedChannels lists quarantined channels.
+func (a *AdminServer) ListQuarantinedChannels(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.rocket.ListQuarantinedChannels(c.Request().Context()))
+}
+
+// QuarantineChannel quarantines a channel: its messages are accepted and logged but not applied.
+func (a *AdminServer) QuarantineChannel(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	var req QuarantineRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, a.rocket.QuarantineChannel(c.Request().Context(), id, req.Reason))
+}
+
+// ReleaseChannel releases a channel from quarantine.
+func (a *AdminServer) ReleaseChannel(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	if !a.rocket.ReleaseChannel(c.Request().Context(), id) {
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("channel %s is not quarantined", id),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// latencyChannels - slowest channels listed by the latency report
+const latencyChannels = 10
+
+// GetLatency reports the ingestion latency percentiles overall and of the slowest channels or, with the channel
+// query parameter, of that channel.
+func (a *AdminServer) GetLatency(c echo.Context) error {
+	channel, ok, err := parseChannel(c)
+	if !ok {
+		return err
+	}
+	if channel == uuid.Nil {
+		return c.JSON(http.StatusOK, a.latency.Report(latencyChannels))
+	}
+	stats, ok := a.latency.Channel(channel)
+	if !ok {
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("no messages of channel %s applied within the window", channel),
+		})
+	}
+	return c.JSON(http.StatusOK, latency.ChannelStats{Channel: channel, Stats: stats})
+}
+
+// GapReport - the open gaps of a channel with their sizes and ages
+type GapReport struct {
+	Channel uuid.UUID  `json:"channel"`
+	Missing int64      `json:"missing"`
+	Gaps    []GapEntry `json:"gaps"`
+}
+
+// GapEntry - range of missing message numbers
+type GapEntry struct {
+	From       int64     `json:"from"`
+	To         int64     `json:"to"`
+	Size       int64     `json:"size"`
+	DetectedAt time.Time `json:"detectedAt"`
+	// Age - time since the gap was detected, as a Go duration
+	Age string `json:"age"`
+	// RequestedAt - when the retransmission of the range was requested, nil before
+	RequestedAt *time.Time `json:"requestedAt,omitempty"`
+}
+
+// ListGaps lists the channels with message numbers that never arrived, optionally only the gaps open for at least
+// minAge.
+func (a *AdminServer) ListGaps(c echo.Context) error {
+	var minAge time.Duration
+	if v := c.QueryParam("minAge"); v != "" {
+		var err error
+		if minAge, err = time.ParseDuration(v); err != nil || minAge < 0 {
+			return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+				Code:    gen.ErrorCodeInvalidMinAge,
+				Message: fmt.Sprintf("minAge must be a non-negative duration, e.g. 10m, got %q", v),
+			})
+		}
+	}
+
+	channels := a.rocket.ListGaps(c.Request().Context(), minAge)
+	out := make([]GapReport, 0, len(channels))
+	for _, channel := range channels {
+		report := GapReport{Channel: channel.Channel, Missing: channel.Missing, Gaps: make([]GapEntry, 0, len(channel.Gaps))}
+		for _, g := range channel.Gaps {
+			report.Gaps = append(report.Gaps, GapEntry{
+				From:        g.From,
+				To:          g.To,
+				Size:        g.Size(),
+				DetectedAt:  g.DetectedAt,
+				Age:         g.Age.Truncate(time.Second).String(),
+				RequestedAt: g.RequestedAt,
+			})
+		}
+		out = append(out, report)
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+// PinRequest - body of the pin operations
+type PinRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ListPins lists the pinned channels and missions.
+func (a *AdminServer) ListPins(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.pins.List())
+}
+
+// PinChannel pins a channel: its history stays hot and its gaps wait without a time limit.
+func (a *AdminServer) PinChannel(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	var req PinRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	pin, err := a.pins.PinChannel(id, req.Reason, time.Now())
+	if err != nil {
+		return pinError(c, err)
+	}
+	return c.JSON(http.StatusOK, pin)
+}
+
+// UnpinChannel removes the pin of a channel.
+func (a *AdminServer) UnpinChannel(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	ok, err = a.pins.UnpinChannel(id)
+	if err != nil {
+		return pinError(c, err)
+	}
+	if !ok {
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("channel %s is not pinned", id),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PinMission pins the rockets of a mission.
+func (a *AdminServer) PinMission(c echo.Context) error {
+	var req PinRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+
+	pin, err := a.pins.PinMission(rocket.Mission(c.Param("mission")), req.Reason, time.Now())
+	if err != nil {
+		return pinError(c, err)
+	}
+	return c.JSON(http.StatusOK, pin)
+}
+
+// UnpinMission removes the pin of a mission.
+func (a *AdminServer) UnpinMission(c echo.Context) error {
+	mission := rocket.Mission(c.Param("mission"))
+	ok, err := a.pins.UnpinMission(mission)
+	if err != nil {
+		return pinError(c, err)
+	}
+	if !ok {
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("mission %s is not pinned", mission),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// pinError answers a pin operation whose change could not be saved
+func pinError(c echo.Context, err error) error {
+	return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+		Code:    gen.ErrorCodeUnknown,
+		Message: err.Error(),
+	})
+}
+
+// ListDeadLetters lists the messages that could not be applied, optionally of a single channel.
+func (a *AdminServer) ListDeadLetters(c echo.Context) error {
+	channel, ok, err := parseChannel(c)
+	if !ok {
+		return err
+	}
+	return c.JSON(http.StatusOK, a.rocket.ListDeadLetters(c.Request().Context(), channel))
+}
+
+// RedriveDeadLetters processes the dead letters again, optionally of a single channel.
+func (a *AdminServer) RedriveDeadLetters(c echo.Context) error {
+	channel, ok, err := parseChannel(c)
+	if !ok {
+		return err
+	}
+	return c.JSON(http.StatusOK, a.rocket.RedriveDeadLetters(c.Request().Context(), channel))
+}
+
+// ClearDeadLetters drops the dead letters, optionally of a single channel.
+func (a *AdminServer) ClearDeadLetters(c echo.Context) error {
+	channel, ok, err := parseChannel(c)
+	if !ok {
+		return err
+	}
+	a.rocket.ClearDeadLetters(c.Request().Context(), channel)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DebugTraceRequest - body of the debug trace operation
+type DebugTraceRequest struct {
+	// TTL - how long the channel is traced, e.g. "10m"; the configured default when empty
+	TTL string `json:"ttl"`
+}
+
+// ListTracedChannels lists channels whose processing is traced.
+func (a *AdminServer) ListTracedChannels(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.rocket.ListTracedChannels(c.Request().Context()))
+}
+
+// TraceChannel logs every processing decision of a channel at debug level until the trace expires.
+func (a *AdminServer) TraceChannel(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	var req DebugTraceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+				Code:    gen.ErrorCodeInvalidTtl,
+				Message: fmt.Sprintf("ttl must be a positive duration, got %q", req.TTL),
+			})
+		}
+	}
+
+	entry, err := a.rocket.TraceChannel(c.Request().Context(), id, ttl)
+	if errors.Is(err, rocket.ErrTooManyDebugTraces) {
+		return c.JSON(http.StatusConflict, gen.ErrorResponse{
+			Code:    gen.ErrorCodeTooManyTraces,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// UntraceChannel stops tracing a channel.
+func (a *AdminServer) UntraceChannel(c echo.Context) error {
+	id, ok, err := parseID(c)
+	if !ok {
+		return err
+	}
+
+	if !a.rocket.UntraceChannel(c.Request().Context(), id) {
+		return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("channel %s is not traced", id),
+		})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CheckConsistency compares stored rocket states with their invariants and event history.
+// Drifts and repairable violations are fixed when the fix query parameter is true.
+func (a *AdminServer) CheckConsistency(c echo.Context) error {
+	var fix bool
+	if v := c.QueryParam("fix"); v != "" {
+		var err error
+		fix, err = strconv.ParseBool(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+				Code:    gen.ErrorCodeInvalidFix,
+				Message: fmt.Sprintf("invalid fix parameter: %s", v),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, a.rocket.CheckConsistency(c.Request().Context(), fix))
+}
+
+// ExportHistory writes the event history to the Parquet partitions right away, instead of waiting for the
+// scheduled export.
+func (a *AdminServer) ExportHistory(c echo.Context) error {
+	partitions, err := a.export.Export(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, partitions)
+}
+
+// GetWarehouseStatus reports the progress of the warehouse sink and the rows the warehouse rejected.
+func (a *AdminServer) GetWarehouseStatus(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.sink.Status())
+}
+
+// PartitionsResponse - channel ownership of the replica, with the owner of the channel asked about
+type PartitionsResponse struct {
+	partition.Status
+	Channel *ChannelOwner `json:"channel,omitempty"`
+}
+
+// ChannelOwner - replica owning a channel
+type ChannelOwner struct {
+	ID    uuid.UUID `json:"id"`
+	Owned bool      `json:"owned"`
+	// Peer - base URL of the owner when it is another replica
+	Peer string `json:"peer,omitempty"`
+}
+
+// GetPartitions reports the partitions or the ring members of the replica and, with the channel query parameter,
+// which replica owns the channel.
+func (a *AdminServer) GetPartitions(c echo.Context) error {
+	channel, ok, err := parseChannel(c)
+	if !ok {
+		return err
+	}
+	resp := PartitionsResponse{Status: a.partitions.Status()}
+	if channel != uuid.Nil {
+		resp.Channel = &ChannelOwner{ID: channel, Owned: a.partitions.Owns(channel)}
+		if !resp.Channel.Owned {
+			resp.Channel.Peer = a.partitions.Peer(channel)
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// AcceptHandoff takes over the hot state of the channels another replica handed off, when they moved to this one.
+// The request must be signed with the secret of the ring; the channels the replica doesn't own are left out.
+func (a *AdminServer) AcceptHandoff(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: fmt.Sprintf("can't read request body: %s", err),
+		})
+	}
+	if !partition.VerifyHandoff(a.handoffSecret, body, c.Request().Header.Get(partition.HandoffSignatureHeader)) {
+		return c.JSON(http.StatusUnauthorized, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnauthorized,
+			Message: "the handoff is not signed with the secret of the ring",
+		})
+	}
+	var handoffs []rocket.Handoff
+	if err := json.Unmarshal(body, &handoffs); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+	owned := slices.DeleteFunc(slices.Clone(handoffs), func(h rocket.Handoff) bool { return !a.partitions.Owns(h.Channel) })
+	report := a.rocket.AcceptHandoff(c.Request().Context(), owned)
+	report.Foreign = len(handoffs) - len(owned)
+	return c.JSON(http.StatusOK, report)
+}
+
+// ListCaptures lists captured request/response exchanges, newest first.
+func (a *AdminServer) ListCaptures(c echo.Context) error {
+	exchanges := a.capture.Recent()
+	if exchanges == nil {
+		exchanges = []capture.Exchange{}
+	}
+	return c.JSON(http.StatusOK, exchanges)
+}
+
+// ClearCaptures drops all captured exchanges.
+func (a *AdminServer) ClearCaptures(c echo.Context) error {
+	a.capture.Clear()
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LogLevelRequest - body of the log level operation
+type LogLevelRequest struct {
+	// Component - component whose level is set, the global level when empty
+	Component string `json:"component,omitempty"`
+	// Level - debug, info, warn or error; empty drops the override of the component
+	Level string `json:"level"`
+}
+
+// LogLevelResponse - the global log level and the overrides by component
+type LogLevelResponse struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+// GetLogLevel returns the current log levels.
+func (a *AdminServer) GetLogLevel(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.logLevels())
+}
+
+// SetLogLevel changes the global log level or the level of a component without restarting the service.
+func (a *AdminServer) SetLogLevel(c echo.Context) error {
+	var req LogLevelRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidBody,
+			Message: err.Error(),
+		})
+	}
+	if req.Component != "" && !logging.ValidComponent(req.Component) {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidComponent,
+			Message: fmt.Sprintf("unknown component %q, must be one of %s", req.Component, strings.Join(logging.Components, ", ")),
+		})
+	}
+	// the global level has no override to drop
+	if req.Level == "" && req.Component == "" {
+		return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+			Code:    gen.ErrorCodeInvalidLevel,
+			Message: "level is required for the global level",
+		})
+	}
+
+	if req.Level == "" && req.Component != "" {
+		a.levels.ResetLevel(req.Component)
+	} else {
+		level, err := zapcore.ParseLevel(req.Level)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+				Code:    gen.ErrorCodeInvalidLevel,
+				Message: err.Error(),
+			})
+		}
+		a.levels.SetLevel(req.Component, level)
+	}
+	a.logger.Warn("Log level changed", logging.Event(logging.EventLogLevelChanged), zap.String("component", req.Component), zap.String("level", req.Level))
+
+	return c.JSON(http.StatusOK, a.logLevels())
+}
+
+// logLevels returns the current log levels as a response
+func (a *AdminServer) logLevels() LogLevelResponse {
+	global, components := a.levels.Level()
+	resp := LogLevelResponse{Level: global.String(), Components: make(map[string]string, len(components))}
+	for component, level := range components {
+		resp.Components[component] = level.String()
+	}
+	return resp
+}
+
+// ListRoutes lists the routes the server serves, with the OpenAPI operation of the API routes.
+func (a *AdminServer) ListRoutes(c echo.Context) error {
+	ops, err := specOperations(a.basePath)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, routeInventory(a.echo.Routes(), ops))
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPI_BackfillHistory(t *testing.T) {
+	logger := zap.NewNop()
+	history := rocket.NewInMemoryHistoryStore()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	svc.UseHistory(history)
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:    e,
+		Logger:  logger,
+		Rocket:  svc,
+		History: history,
+		Keys:    auth.NewKeys(nil),
+		Usage:   usage.NewMeter(usage.Quota{}),
+	})
+
+	id := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	at := time.Date(2022, 2, 2, 19, 39, 0, 0, time.UTC)
+	messages := []rocket.TelemetryMessage{
+		{
+			Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: at, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 4, MessageTime: at.Add(time.Minute), MessageType: rocket.MessageTypeSpeedIncreased},
+			Message:  rocket.Message{By: ptr(rocket.Speed(1000))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := svc.ProcessMessage(context.Background(), msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/backfill", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	message := func(channel uuid.UUID, number int, offset time.Duration, by int) string {
+		return `{"metadata": {"channel": "` + channel.String() + `", "messageNumber": ` + strconv.Itoa(number) +
+			`, "messageTime": "` + at.Add(offset).Format(time.RFC3339) + `", "messageType": "RocketSpeedIncreased"}, "message": {"by": ` + strconv.Itoa(by) + `}}`
+	}
+
+	rec := post(`{"channel": "` + id.String() + `", "messages": [` + message(id, 2, 20*time.Second, 300) + `, ` + message(id, 4, time.Minute, 1000) + `]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var result gen.BackfillResult
+	_ = json.Unmarshal(rec.Body.Bytes(), &result)
+	if result.Merged != 1 || result.Duplicates != 1 || result.Replayed != 2 {
+		t.Errorf("Expected 1 merged, 1 duplicate and 2 replayed, got %+v", result)
+	}
+	if result.State.CurrentSpeed != 1800 {
+		t.Errorf("Expected speed 1800, got %d", result.State.CurrentSpeed)
+	}
+
+	other := uuid.New()
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"other channel", `{"channel": "` + id.String() + `", "messages": [` + message(uuid.New(), 3, 30*time.Second, 1) + `]}`, http.StatusBadRequest},
+		{"unknown rocket", `{"channel": "` + other.String() + `", "messages": [` + message(other, 1, 0, 1) + `]}`, http.StatusNotFound},
+		{"no messages", `{"channel": "` + id.String() + `", "messages": []}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := post(tt.body); rec.Code != tt.code {
+				t.Errorf("Expected %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPI_BackfillQuota(t *testing.T) {
+	logger := zap.NewNop()
+	history := rocket.NewInMemoryHistoryStore()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	svc.UseHistory(history)
+	meter := usage.NewMeter(usage.Quota{Messages: 3})
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:    e,
+		Logger:  logger,
+		Rocket:  svc,
+		History: history,
+		Keys:    auth.NewKeys(nil),
+		Usage:   meter,
+	})
+
+	id := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	at := time.Date(2022, 2, 2, 19, 39, 0, 0, time.UTC)
+	_, err := svc.ProcessMessage(context.Background(), rocket.TelemetryMessage{
+		Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: at, MessageType: rocket.MessageTypeLaunched},
+		Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+	})
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	backfill := func(numbers ...int) *httptest.ResponseRecorder {
+		var messages []string
+		for _, n := range numbers {
+			messages = append(messages, `{"metadata": {"channel": "`+id.String()+`", "messageNumber": `+strconv.Itoa(n)+
+				`, "messageTime": "`+at.Add(time.Duration(n)*time.Second).Format(time.RFC3339)+`", "messageType": "RocketSpeedIncreased"}, "message": {"by": 100}}`)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/v1/backfill", strings.NewReader(`{"channel": "`+id.String()+`", "messages": [`+strings.Join(messages, ", ")+`]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := backfill(2, 3); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if u := meter.List(); len(u) != 1 || u[0].Messages != 2 {
+		t.Errorf("Expected the 2 messages of the batch accounted, got %+v", u)
+	}
+
+	// a batch going over the quota is rejected as a whole
+	rec := backfill(4, 5)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("X-Quota-Messages-Remaining") != "1" {
+		t.Errorf("Expected 1 message remaining, got %q", rec.Header().Get("X-Quota-Messages-Remaining"))
+	}
+	if rec := backfill(4); rec.Code != http.StatusOK {
+		t.Errorf("Expected the last message of the quota accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+)
+
+func TestAPI_BatchGetRockets(t *testing.T) {
+	logger := zap.NewNop()
+	keys := auth.NewKeys(map[string]string{"acme-key": "acme", "ops-key": "ops"})
+	keys.Restrict("acme", auth.Scope{Missions: []string{"ARTEMIS"}})
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: logger,
+		Rocket: rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger),
+		Keys:   keys,
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+	do := func(method, target, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	launch := func(channel, mission string) string {
+		return `{"metadata":{"channel":"` + channel + `","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},` +
+			`"message":{"type":"Falcon-9","launchSpeed":500,"mission":"` + mission + `"}}`
+	}
+	own, other, unknown := "193270a9-c9cf-404a-8f83-838e71d9ae67", "7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30", "0b7f4a52-3c1d-4e9b-8a6f-5d2c1e0f9a84"
+	for channel, mission := range map[string]string{own: "ARTEMIS", other: "GEMINI"} {
+		if rec := do(http.MethodPost, "/messages", "ops-key", launch(channel, mission)); rec.Code != http.StatusAccepted {
+			t.Fatalf("Expected the launch to be accepted, got %d: %s", rec.Code, rec.Body)
+		}
+	}
+
+	batch := `{"ids":["` + own + `","` + unknown + `","` + other + `","` + own + `"]}`
+	rec := do(http.MethodPost, "/v1/rockets/batch-get", "acme-key", batch)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the batch looked up, got %d: %s", rec.Code, rec.Body)
+	}
+	var lookups []map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &lookups)
+	want := []struct{ id, status string }{{own, "found"}, {unknown, "not_found"}, {other, "forbidden"}, {own, "found"}}
+	if len(lookups) != len(want) {
+		t.Fatalf("Expected a lookup per requested id, got %v", lookups)
+	}
+	for i, w := range want {
+		_, hasState := lookups[i]["state"]
+		if lookups[i]["id"] != w.id || lookups[i]["status"] != w.status || hasState != (w.status == "found") {
+			t.Errorf("Expected lookup %d of %s to be %s, got %v", i, w.id, w.status, lookups[i])
+		}
+	}
+	if state, _ := lookups[0]["state"].(map[string]any); state["mission"] != "ARTEMIS" {
+		t.Errorf("Expected the state of the own rocket, got %v", lookups[0])
+	}
+
+	_ = json.Unmarshal(do(http.MethodPost, "/v1/rockets/batch-get", "", batch).Body.Bytes(), &lookups)
+	if len(lookups) != 4 || lookups[2]["status"] != "found" {
+		t.Errorf("Expected anonymous batch reads unscoped like other reads, got %v", lookups)
+	}
+
+	for _, body := range []string{`{"ids":[]}`, `{"ids":[` + strings.Repeat(`"`+own+`",`, maxBatchGet) + `"` + own + `"]}`} {
+		rec := do(http.MethodPost, "/v1/rockets/batch-get", "acme-key", body)
+		var resp map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusBadRequest || resp["code"] != "invalid_batch" {
+			t.Errorf("Expected invalid_batch for %d bytes of ids, got %d: %s", len(body), rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"rockets/internal/auth"
+	"rockets/internal/clock"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries - responses kept by the micro-cache, new ones are not cached while it is full of fresh ones
+const maxCacheEntries = 1024
+
+// cachedRoutes - routes polled by the dashboards, the rocket listing, the fleet stats and the watchlist states
+var cachedRoutes = []string{"/v1/rockets", "/v1/fleets", "/v1/fleets/:name", "/v1/watchlists/:name"}
+
+// cachedResponse - response of a cacheable route as the handler wrote it
+type cachedResponse struct {
+	status int
+	// header - headers the handler set, the ones of the middlewares before the cache are not replayed
+	header http.Header
+	body   []byte
+	stored time.Time
+}
+
+// MicroCache keeps the responses of the hot read routes for a fraction of a second, so dozens of dashboards polling
+// at the same time cost one listing per TTL. Responses are cached per request URI and producer, the scope of an
+// API key changes what is listed; concurrent requests for a missing response wait for the one computing it.
+type MicroCache struct {
+	ttl time.Duration
+	// maxAge - max-age of the Cache-Control header of the cached routes
+	maxAge time.Duration
+	clock  clock.Clock
+
+	mu       sync.Mutex
+	entries  map[string]*cachedResponse
+	inflight map[string]chan struct{}
+}
+
+// NewMicroCache creates a cache of the responses for the TTL, 0 only sets the Cache-Control header.
+func NewMicroCache(ttl, maxAge time.Duration) *MicroCache {
+	return &MicroCache{
+		ttl:      ttl,
+		maxAge:   maxAge,
+		clock:    clock.Real{},
+		entries:  make(map[string]*cachedResponse),
+		inflight: make(map[string]chan struct{}),
+	}
+}
+
+// UseClock replaces the system clock the responses expire by. Must be called before the cache is used.
+func (m *MicroCache) UseClock(c clock.Clock) {
+	m.clock = c
+}
+
+// Cache serves the GET requests of the cached routes under the base path from the micro-cache, marking their
+// responses with "Cache-Control: private, max-age=N" and, when served from the cache, with their Age. A nil cache
+// lets every request through.
+func Cache(cache *MicroCache, basePath string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cache == nil || c.Request().Method != http.MethodGet || !slices.Contains(cachedRoutes, strings.TrimPrefix(c.Path(), basePath)) {
+				return next(c)
+			}
+			c.Response().Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(cache.maxAge.Seconds())))
+			if cache.ttl <= 0 {
+				return next(c)
+			}
+
+			key := auth.FromContext(c.Request().Context()).Producer + " " + c.Request().RequestURI
+			cached, err := cache.wait(c.Request().Context(), key)
+			if err != nil {
+				return err
+			}
+			if cached != nil {
+				return cache.replay(c, cached)
+			}
+			defer cache.done(key)
+
+			res := c.Response()
+			before := res.Header().Clone()
+			buffer := &bufferWriter{ResponseWriter: res.Writer, status: http.StatusOK}
+			res.Writer = buffer
+			err = next(c)
+			res.Writer = buffer.ResponseWriter
+			if err != nil {
+				return err
+			}
+
+			if buffer.status == http.StatusOK {
+				header := make(http.Header)
+				for k, v := range res.Header() {
+					if !slices.Equal(before[k], v) {
+						header[k] = slices.Clone(v)
+					}
+				}
+				cache.store(key, &cachedResponse{status: buffer.status, header: header, body: buffer.body.Bytes(), stored: cache.clock.Now()})
+			}
+			res.Writer.WriteHeader(buffer.status)
+			_, err = res.Writer.Write(buffer.body.Bytes())
+			return err
+		}
+	}
+}
+
+// wait returns the fresh response of the key, waiting while another request computes it. Without one, the
+// caller computes it and must call done. A request cancelled while waiting gets the error of its context.
+func (m *MicroCache) wait(ctx context.Context, key string) (*cachedResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		if e, ok := m.entries[key]; ok && m.clock.Now().Sub(e.stored) < m.ttl {
+			return e, nil
+		}
+		ch, ok := m.inflight[key]
+		if !ok {
+			m.inflight[key] = make(chan struct{})
+			return nil, nil
+		}
+		m.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			m.mu.Lock()
+			return nil, ctx.Err()
+		}
+		m.mu.Lock()
+	}
+}
+
+// done wakes the requests waiting for the response of the key
+func (m *MicroCache) done(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	close(m.inflight[key])
+	delete(m.inflight, key)
+}
+
+// store caches the response, dropping the expired ones when the cache is full
+func (m *MicroCache) store(key string, r *cachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.entries) >= maxCacheEntries {
+		for k, e := range m.entries {
+			if r.stored.Sub(e.stored) >= m.ttl {
+				delete(m.entries, k)
+			}
+		}
+		if len(m.entries) >= maxCacheEntries {
+			return
+		}
+	}
+	m.entries[key] = r
+}
+
+// replay writes the cached response with its age
+func (m *MicroCache) replay(c echo.Context, r *cachedResponse) error {
+	h := c.Response().Header()
+	for k, v := range r.header {
+		h[k] = slices.Clone(v)
+	}
+	h.Set("Age", strconv.Itoa(int(m.clock.Now().Sub(r.stored).Seconds())))
+	c.Response().WriteHeader(r.status)
+	_, err := c.Response().Write(r.body)
+	return err
+}
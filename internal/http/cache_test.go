@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/clock"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"testing"
+	"time"
+)
+
+func TestAPI_MicroCache(t *testing.T) {
+	fake := clock.NewFake(time.Date(2022, 2, 2, 19, 39, 0, 0, time.UTC))
+	cache := NewMicroCache(500*time.Millisecond, 2*time.Second)
+	cache.UseClock(fake)
+	svc := goldenService(t)
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: svc,
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+		Cache:  cache,
+	})
+	list := func() (*httptest.ResponseRecorder, []gen.RocketState) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/rockets?sortBy=speed", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var states []gen.RocketState
+		_ = json.Unmarshal(rec.Body.Bytes(), &states)
+		return rec, states
+	}
+
+	first, states := list()
+	if len(states) != 3 || first.Header().Get("Cache-Control") != "private, max-age=2" || first.Header().Get("Age") != "" {
+		t.Fatalf("Expected a fresh listing of 3 rockets, got %d with %v", len(states), first.Header())
+	}
+	_, err := svc.ProcessMessage(context.Background(), rocket.TelemetryMessage{
+		Metadata: rocket.MessageMetadata{Channel: uuid.New(), MessageNumber: 1, MessageTime: fake.Now(), MessageType: rocket.MessageTypeLaunched},
+		Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+	})
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	fake.Advance(400 * time.Millisecond)
+	cached, states := list()
+	if len(states) != 3 || cached.Header().Get("Age") != "0" || cached.Header().Get("X-Data-As-Of") != first.Header().Get("X-Data-As-Of") {
+		t.Errorf("Expected the cached listing, got %d rockets with %v", len(states), cached.Header())
+	}
+
+	fake.Advance(100 * time.Millisecond)
+	if fresh, states := list(); len(states) != 4 || fresh.Header().Get("Age") != "" {
+		t.Errorf("Expected the listing to expire, got %d rockets with %v", len(states), fresh.Header())
+	}
+
+	// other routes are neither cached nor marked
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/version", nil))
+	if rec.Header().Get("Cache-Control") != "" {
+		t.Errorf("Expected no Cache-Control on /v1/version, got %q", rec.Header().Get("Cache-Control"))
+	}
+}
+
+func TestMicroCache_WaitCancelled(t *testing.T) {
+	cache := NewMicroCache(time.Second, time.Second)
+	if cached, err := cache.wait(context.Background(), "key"); cached != nil || err != nil {
+		t.Fatalf("Expected the first request to compute the response, got %v, %v", cached, err)
+	}
+
+	// the request waiting for the one computing the response gives up with its context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cache.wait(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+
+	cache.done("key")
+	if cached, err := cache.wait(context.Background(), "key"); cached != nil || err != nil {
+		t.Errorf("Expected the next request to compute the response, got %v, %v", cached, err)
+	}
+}
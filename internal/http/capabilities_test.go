@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"slices"
+	"testing"
+)
+
+func TestAPI_Capabilities(t *testing.T) {
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:         e,
+		Logger:       zap.NewNop(),
+		Rocket:       goldenService(t),
+		Keys:         auth.NewKeys(nil),
+		Usage:        usage.NewMeter(usage.Quota{Messages: 1000}),
+		Rates:        rocket.NewMessageRates(),
+		AuthReads:    true,
+		MaxBodyBytes: 1 << 20,
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/capabilities", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var c gen.Capabilities
+	if err := json.Unmarshal(rec.Body.Bytes(), &c); err != nil {
+		t.Fatalf("Can't decode capabilities: %v", err)
+	}
+	if c.Streaming || c.BatchIngest || c.MaxBatchSize != 1 || c.MaxBodyBytes != 1<<20 {
+		t.Errorf("Expected single messages up to 1MiB, got %+v", c)
+	}
+	if !slices.Equal(c.Features, []string{"messageRates"}) || len(c.ReportFormats) != 0 {
+		t.Errorf("Expected only the message rates enabled, got %v and reports %v", c.Features, c.ReportFormats)
+	}
+	if !c.AuthenticatedReads || c.Public {
+		t.Errorf("Expected authenticated reads, got %+v", c)
+	}
+	if c.RateLimits.DailyMessages == nil || *c.RateLimits.DailyMessages != 1000 || c.RateLimits.DailyBytes != nil {
+		t.Errorf("Expected the daily message quota only, got %+v", c.RateLimits)
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/capture"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+)
+
+func TestCapture_SkipsRejectedCallers(t *testing.T) {
+	logger := zap.NewNop()
+	recorder := capture.NewRecorder(10, 1, nil)
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:    e,
+		Logger:  logger,
+		Rocket:  rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger),
+		Keys:    auth.NewKeys(map[string]string{"ops-key": "ops"}),
+		Usage:   usage.NewMeter(usage.Quota{}),
+		Capture: recorder,
+	})
+	post := func(key string) int {
+		body := `{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},` +
+			`"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`
+		req := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post(""); code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without a key, got %d", code)
+	}
+	if got := recorder.Recent(); len(got) != 0 {
+		t.Errorf("Expected the unauthenticated request not captured, got %+v", got)
+	}
+	if code := post("ops-key"); code != http.StatusAccepted {
+		t.Fatalf("Expected 202 with a key, got %d", code)
+	}
+	if got := recorder.Recent(); len(got) != 1 || got[0].Status != http.StatusAccepted {
+		t.Errorf("Expected the accepted request captured, got %+v", got)
+	}
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"rockets/internal/http/gen"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// envelopeParam - query parameter asking for the response envelope
+const envelopeParam = "envelope"
+
+// dataAsOfHeader - header of the time the data of a response is current as of
+const dataAsOfHeader = "X-Data-As-Of"
+
+// Envelope - response wrapped with its metadata, for clients that can't read the headers
+type Envelope struct {
+	// Data, Error - the response of a successful request, or the ErrorResponse of a failed one
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error json.RawMessage `json:"error,omitempty"`
+	Meta  EnvelopeMeta    `json:"meta"`
+}
+
+// EnvelopeMeta - metadata of the enveloped response
+type EnvelopeMeta struct {
+	RequestID string `json:"requestId"`
+	// DataAsOf - time the data is current as of, the X-Data-As-Of header when the route sets it, otherwise the
+	// time of the response
+	DataAsOf string `json:"dataAsOf"`
+	// Pagination - set for list responses
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Warnings   []string    `json:"warnings"`
+}
+
+// Pagination - size of a list response. Listings are not paged, the response holds every item.
+type Pagination struct {
+	Count int `json:"count"`
+}
+
+// warningsKey - context key of the warnings collected for the envelope
+type warningsKey struct{}
+
+// warnings - warnings about the request collected while it is served
+type warnings struct {
+	mu    sync.Mutex
+	items []string
+}
+
+// warn adds a warning to the envelope of the response; without an envelope the warning is dropped, the response
+// tells it another way, e.g. by a missing Preference-Applied header
+func warn(ctx context.Context, warning string) {
+	w, ok := ctx.Value(warningsKey{}).(*warnings)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.items = append(w.items, warning)
+}
+
+// EnvelopeResponses wraps the JSON responses of requests with ?envelope=true into an Envelope: the response
+// under data, or under error for non-2xx responses, and the request ID, the data-as-of time, the size of a list
+// and the warnings under meta. The parameter is removed from the request, so the routes and the cache serve the
+// request as they serve one without it, and other responses are served as they are.
+func EnvelopeResponses() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			query := req.URL.Query()
+			if !query.Has(envelopeParam) {
+				return next(c)
+			}
+			enabled, err := strconv.ParseBool(query.Get(envelopeParam))
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+					Code:    gen.ErrorCodeInvalidEnvelope,
+					Message: fmt.Sprintf("envelope must be true or false, got %q", query.Get(envelopeParam)),
+				})
+			}
+			query.Del(envelopeParam)
+			req.URL.RawQuery = query.Encode()
+			req.RequestURI = req.URL.RequestURI()
+			if !enabled {
+				return next(c)
+			}
+
+			collected := &warnings{}
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), warningsKey{}, collected)))
+			res := c.Response()
+			buffer := &bufferWriter{ResponseWriter: res.Writer, status: http.StatusOK}
+			res.Writer = buffer
+			err = next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			res.Writer = buffer.ResponseWriter
+
+			body := buffer.body.Bytes()
+			if strings.HasPrefix(res.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) && json.Valid(body) {
+				collected.mu.Lock()
+				meta := EnvelopeMeta{
+					RequestID: res.Header().Get(echo.HeaderXRequestID),
+					DataAsOf:  res.Header().Get(dataAsOfHeader),
+					Warnings:  append([]string{}, collected.items...),
+				}
+				collected.mu.Unlock()
+				if meta.DataAsOf == "" {
+					meta.DataAsOf = time.Now().UTC().Format(time.RFC3339Nano)
+				}
+				envelope := Envelope{Meta: meta}
+				if buffer.status >= 200 && buffer.status <= 299 {
+					envelope.Data = body
+					var items []json.RawMessage
+					if json.Unmarshal(body, &items) == nil {
+						envelope.Meta.Pagination = &Pagination{Count: len(items)}
+					}
+				} else {
+					envelope.Error = body
+				}
+				if out, err := json.Marshal(envelope); err == nil {
+					body = append(out, '\n')
+					res.Header().Del(echo.HeaderContentLength)
+				}
+			}
+			res.Writer.WriteHeader(buffer.status)
+			_, err = res.Writer.Write(body)
+			return err
+		}
+	}
+}
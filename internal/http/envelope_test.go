@@ -0,0 +1,73 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+)
+
+func TestAPI_Envelope(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.RequestID())
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: goldenService(t),
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+	do := func(method, target, body string) (*httptest.ResponseRecorder, Envelope) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		e.ServeHTTP(rec, req)
+		var envelope Envelope
+		_ = json.Unmarshal(rec.Body.Bytes(), &envelope)
+		return rec, envelope
+	}
+
+	rec, envelope := do(http.MethodGet, "/v1/rockets?sortBy=speed&envelope=true", "")
+	var states []gen.RocketState
+	if rec.Code != http.StatusOK || json.Unmarshal(envelope.Data, &states) != nil || len(states) != 3 {
+		t.Fatalf("Expected the listing of 3 rockets under data, got %d: %s", rec.Code, rec.Body.String())
+	}
+	meta := envelope.Meta
+	if meta.RequestID == "" || meta.RequestID != rec.Header().Get(echo.HeaderXRequestID) || meta.DataAsOf != rec.Header().Get(dataAsOfHeader) {
+		t.Errorf("Expected the request ID and the data-as-of time of the headers, got %+v", meta)
+	}
+	if meta.Pagination == nil || meta.Pagination.Count != 3 || meta.Warnings == nil {
+		t.Errorf("Expected a count of 3 and no warnings, got %+v", meta)
+	}
+
+	rec, envelope = do(http.MethodGet, "/v1/rockets/193270a9-c9cf-404a-8f83-838e71d9ae67?envelope=false", "")
+	var state gen.RocketState
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &state) != nil || state.Id.String() != "193270a9-c9cf-404a-8f83-838e71d9ae67" {
+		t.Errorf("Expected the plain state without the envelope, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec, envelope = do(http.MethodGet, "/v1/rockets/1a2b3c4d-0000-4000-8000-000000000000?envelope=true", "")
+	var errResp gen.ErrorResponse
+	if rec.Code != http.StatusNotFound || envelope.Data != nil || json.Unmarshal(envelope.Error, &errResp) != nil || errResp.Code != gen.ErrorCodeNotFound {
+		t.Errorf("Expected the not_found error under error, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	msg := `{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":3,"messageTime":"2022-02-02T19:41:05Z","messageType":"RocketSpeedDecreased"},"message":{"by":4000}}`
+	rec, envelope = do(http.MethodPost, "/messages?envelope=true", msg)
+	if rec.Code != http.StatusAccepted || len(envelope.Meta.Warnings) != 1 || envelope.Meta.Pagination != nil {
+		t.Errorf("Expected the underflow warning under meta, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec, _ = do(http.MethodGet, "/v1/rockets?envelope=maybe", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_envelope") {
+		t.Errorf("Expected 400 invalid_envelope, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
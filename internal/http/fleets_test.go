@@ -0,0 +1,75 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/fleet"
+	"rockets/internal/http/gen"
+	"rockets/internal/usage"
+	"testing"
+)
+
+func TestAPI_Fleets(t *testing.T) {
+	e := echo.New()
+	keys := auth.NewKeys(map[string]string{"ops-key": "ops", "apollo-key": "apollo"})
+	keys.Restrict("apollo", auth.Scope{Missions: []string{"APOLLO"}})
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: goldenService(t),
+		Keys:   keys,
+		Usage:  usage.NewMeter(usage.Quota{}),
+		Fleets: fleet.NewRegistry(),
+	})
+	do := func(method, target, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body := `{"name":"heavy","description":"test fleet","rockets":["7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30","c1e5f7a2-3b4d-4e6f-8a9b-0c1d2e3f4a5b","00000000-0000-0000-0000-000000000001"]}`
+	if rec := do(http.MethodPost, "/admin/fleets", "", body); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the fleet created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/admin/fleets", "", body); rec.Code != http.StatusConflict {
+		t.Errorf("Expected a second fleet with the name rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/admin/fleets/light", "", `{"rockets":[]}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown fleet not found, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodGet, "/v1/fleets/HEAVY", "ops-key", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the fleet, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var f gen.Fleet
+	_ = json.Unmarshal(rec.Body.Bytes(), &f)
+	if f.Stats.Rockets != 3 || f.Stats.Tracked != 2 || f.Stats.Exploded != 2 || f.Stats.Launched != 0 || len(f.Stats.Missions) != 2 {
+		t.Errorf("Expected the stats of the two tracked rockets, got %+v", f.Stats)
+	}
+
+	// the scoped key only sees the APOLLO rocket
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/fleets/heavy", "apollo-key", "").Body.Bytes(), &f)
+	if len(f.Rockets) != 1 || f.Stats.Tracked != 1 || f.Stats.Missions[0] != "APOLLO" {
+		t.Errorf("Expected the fleet narrowed to the scope, got %+v", f)
+	}
+
+	if rec := do(http.MethodDelete, "/admin/fleets/heavy", "", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the fleet deleted, got %d", rec.Code)
+	}
+	var fleets []gen.Fleet
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/fleets", "", "").Body.Bytes(), &fleets)
+	if len(fleets) != 0 {
+		t.Errorf("Expected no fleets left, got %+v", fleets)
+	}
+}
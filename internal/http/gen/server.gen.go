@@ -10,6 +10,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
@@ -23,19 +24,126 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for DryRunResultOutcome.
+const (
+	DryRunResultOutcomeApplied    DryRunResultOutcome = "applied"
+	DryRunResultOutcomeBackfilled DryRunResultOutcome = "backfilled"
+	DryRunResultOutcomeBuffered   DryRunResultOutcome = "buffered"
+	DryRunResultOutcomeDuplicate  DryRunResultOutcome = "duplicate"
+	DryRunResultOutcomeIgnored    DryRunResultOutcome = "ignored"
+)
+
+// Defines values for ErrorCode.
+const (
+	ErrorCodeCloneImpossible          ErrorCode = "clone_impossible"
+	ErrorCodeDuplicateMessage         ErrorCode = "duplicate_message"
+	ErrorCodeFleetExists              ErrorCode = "fleet_exists"
+	ErrorCodeForbidden                ErrorCode = "forbidden"
+	ErrorCodeHistoryDisabled          ErrorCode = "history_disabled"
+	ErrorCodeHistoryTruncated         ErrorCode = "history_truncated"
+	ErrorCodeInternalError            ErrorCode = "internal_error"
+	ErrorCodeInvalidAggregation       ErrorCode = "invalid_aggregation"
+	ErrorCodeInvalidBatch             ErrorCode = "invalid_batch"
+	ErrorCodeInvalidBody              ErrorCode = "invalid_body"
+	ErrorCodeInvalidClone             ErrorCode = "invalid_clone"
+	ErrorCodeInvalidComponent         ErrorCode = "invalid_component"
+	ErrorCodeInvalidEnvelope          ErrorCode = "invalid_envelope"
+	ErrorCodeInvalidFilter            ErrorCode = "invalid_filter"
+	ErrorCodeInvalidFix               ErrorCode = "invalid_fix"
+	ErrorCodeInvalidFleet             ErrorCode = "invalid_fleet"
+	ErrorCodeInvalidId                ErrorCode = "invalid_id"
+	ErrorCodeInvalidIncident          ErrorCode = "invalid_incident"
+	ErrorCodeInvalidLevel             ErrorCode = "invalid_level"
+	ErrorCodeInvalidMaintenanceWindow ErrorCode = "invalid_maintenance_window"
+	ErrorCodeInvalidMerge             ErrorCode = "invalid_merge"
+	ErrorCodeInvalidMessage           ErrorCode = "invalid_message"
+	ErrorCodeInvalidMinAge            ErrorCode = "invalid_min_age"
+	ErrorCodeInvalidName              ErrorCode = "invalid_name"
+	ErrorCodeInvalidPreference        ErrorCode = "invalid_preference"
+	ErrorCodeInvalidRegistration      ErrorCode = "invalid_registration"
+	ErrorCodeInvalidTtl               ErrorCode = "invalid_ttl"
+	ErrorCodeInvalidWatchlist         ErrorCode = "invalid_watchlist"
+	ErrorCodeInvalidWindow            ErrorCode = "invalid_window"
+	ErrorCodeMergeImpossible          ErrorCode = "merge_impossible"
+	ErrorCodeNameTaken                ErrorCode = "name_taken"
+	ErrorCodeNotFound                 ErrorCode = "not_found"
+	ErrorCodeNotLeader                ErrorCode = "not_leader"
+	ErrorCodeOwnerUnavailable         ErrorCode = "owner_unavailable"
+	ErrorCodePayloadTooLarge          ErrorCode = "payload_too_large"
+	ErrorCodeQuotaExceeded            ErrorCode = "quota_exceeded"
+	ErrorCodeRegistrationConflict     ErrorCode = "registration_conflict"
+	ErrorCodeRocketExists             ErrorCode = "rocket_exists"
+	ErrorCodeRollbackImpossible       ErrorCode = "rollback_impossible"
+	ErrorCodeSequenceGap              ErrorCode = "sequence_gap"
+	ErrorCodeTimeout                  ErrorCode = "timeout"
+	ErrorCodeTooManyTraces            ErrorCode = "too_many_traces"
+	ErrorCodeUnauthorized             ErrorCode = "unauthorized"
+	ErrorCodeUnknown                  ErrorCode = "unknown"
+	ErrorCodeUnknownFormat            ErrorCode = "unknown_format"
+	ErrorCodeUnknownMessageType       ErrorCode = "unknown_message_type"
+	ErrorCodeUnknownSortBy            ErrorCode = "unknown_sort_by"
+	ErrorCodeUnknownSortModifier      ErrorCode = "unknown_sort_modifier"
+	ErrorCodeUnknownSortOrder         ErrorCode = "unknown_sort_order"
+	ErrorCodeUnsupportedEncoding      ErrorCode = "unsupported_encoding"
+	ErrorCodeWatchlistExists          ErrorCode = "watchlist_exists"
+	ErrorCodeWrongPartition           ErrorCode = "wrong_partition"
+)
+
+// Defines values for ExplosionReasonCategory.
+const (
+	ELECTRICAL  ExplosionReasonCategory = "ELECTRICAL"
+	GUIDANCE    ExplosionReasonCategory = "GUIDANCE"
+	OTHER       ExplosionReasonCategory = "OTHER"
+	PROPULSION  ExplosionReasonCategory = "PROPULSION"
+	RANGESAFETY ExplosionReasonCategory = "RANGE_SAFETY"
+	STRUCTURAL  ExplosionReasonCategory = "STRUCTURAL"
+	THERMAL     ExplosionReasonCategory = "THERMAL"
+	WEATHER     ExplosionReasonCategory = "WEATHER"
+)
+
+// Defines values for ExplosionReasonSeverity.
+const (
+	CRITICAL ExplosionReasonSeverity = "CRITICAL"
+	MAJOR    ExplosionReasonSeverity = "MAJOR"
+)
+
+// Defines values for IngestResultDisposition.
+const (
+	IngestResultDispositionApplied    IngestResultDisposition = "applied"
+	IngestResultDispositionBackfilled IngestResultDisposition = "backfilled"
+	IngestResultDispositionBuffered   IngestResultDisposition = "buffered"
+	IngestResultDispositionDuplicate  IngestResultDisposition = "duplicate"
+	IngestResultDispositionIgnored    IngestResultDisposition = "ignored"
+)
+
 // Defines values for MessageMetadataMessageType.
 const (
 	RocketExploded       MessageMetadataMessageType = "RocketExploded"
+	RocketHeartbeat      MessageMetadataMessageType = "RocketHeartbeat"
 	RocketLaunched       MessageMetadataMessageType = "RocketLaunched"
 	RocketMissionChanged MessageMetadataMessageType = "RocketMissionChanged"
 	RocketSpeedDecreased MessageMetadataMessageType = "RocketSpeedDecreased"
 	RocketSpeedIncreased MessageMetadataMessageType = "RocketSpeedIncreased"
 )
 
+// Defines values for RocketLookupStatus.
+const (
+	RocketLookupStatusForbidden RocketLookupStatus = "forbidden"
+	RocketLookupStatusFound     RocketLookupStatus = "found"
+	RocketLookupStatusNotFound  RocketLookupStatus = "not_found"
+)
+
 // Defines values for RocketStateStatus.
 const (
-	EXPLODED RocketStateStatus = "EXPLODED"
-	LAUNCHED RocketStateStatus = "LAUNCHED"
+	RocketStateStatusEXPLODED RocketStateStatus = "EXPLODED"
+	RocketStateStatusLAUNCHED RocketStateStatus = "LAUNCHED"
+	RocketStateStatusPARTIAL  RocketStateStatus = "PARTIAL"
+)
+
+// Defines values for GetMissionReportParamsFormat.
+const (
+	Html GetMissionReportParamsFormat = "html"
+	Pdf  GetMissionReportParamsFormat = "pdf"
 )
 
 // Defines values for ListRocketsParamsSortBy.
@@ -53,15 +161,226 @@ const (
 	Desc ListRocketsParamsSortOrder = "desc"
 )
 
+// Defines values for ListRocketsParamsSortModifier.
+const (
+	Natural ListRocketsParamsSortModifier = "natural"
+)
+
+// Defines values for ListRocketsParamsStatus.
+const (
+	ListRocketsParamsStatusEXPLODED ListRocketsParamsStatus = "EXPLODED"
+	ListRocketsParamsStatusLAUNCHED ListRocketsParamsStatus = "LAUNCHED"
+	ListRocketsParamsStatusPARTIAL  ListRocketsParamsStatus = "PARTIAL"
+)
+
+// Defines values for GetRocketSpeedHistoryParamsAgg.
+const (
+	Avg GetRocketSpeedHistoryParamsAgg = "avg"
+	Max GetRocketSpeedHistoryParamsAgg = "max"
+	Min GetRocketSpeedHistoryParamsAgg = "min"
+)
+
+// BackfillRequest Historical messages of a rocket.
+type BackfillRequest struct {
+	// Channel The rocket the messages belong to, every message must address it.
+	Channel  openapi_types.UUID `json:"channel"`
+	Messages []TelemetryMessage `json:"messages"`
+}
+
+// BackfillResult What merging the historical messages did.
+type BackfillResult struct {
+	// Duplicates Messages skipped since their numbers are already in the history or repeated in the batch.
+	Duplicates int `json:"duplicates"`
+
+	// Merged Messages added to the history.
+	Merged int `json:"merged"`
+
+	// Replayed Events recomputed from the earliest merged message on, the merged ones included.
+	Replayed int `json:"replayed"`
+
+	// State The current aggregated state of a rocket.
+	State RocketState `json:"state"`
+}
+
+// BuildInfo Build of the running instance.
+type BuildInfo struct {
+	// BuildTime When the binary was built, absent if unknown.
+	BuildTime *time.Time `json:"buildTime,omitempty"`
+
+	// Commit VCS revision the binary was built from, absent if unknown.
+	Commit *string `json:"commit,omitempty"`
+
+	// GoVersion Go toolchain the binary was built with.
+	GoVersion string `json:"goVersion"`
+
+	// Version Release version, dev for development builds.
+	Version string `json:"version"`
+}
+
+// Capabilities Features enabled on the instance and the limits it enforces.
+type Capabilities struct {
+	// AuthenticatedReads Reading rockets requires an API key.
+	AuthenticatedReads bool `json:"authenticatedReads"`
+
+	// BatchIngest More than one message can be ingested per request.
+	BatchIngest bool `json:"batchIngest"`
+
+	// ContentEncodings Content encodings the request bodies may be compressed with.
+	ContentEncodings []string `json:"contentEncodings"`
+
+	// Features Optional features enabled on the instance: names, fleets, messageRates, speedHistory and
+	// missionReports.
+	Features []string `json:"features"`
+
+	// IngestFormats Content types of the ingested messages.
+	IngestFormats []string `json:"ingestFormats"`
+
+	// MaxBatchSize Most messages accepted per ingest request.
+	MaxBatchSize int `json:"maxBatchSize"`
+
+	// MaxBodyBytes Largest request body accepted once decompressed.
+	MaxBodyBytes int64 `json:"maxBodyBytes"`
+
+	// Public Callers without an API key read redacted rockets and are denied usage and mission reports.
+	Public bool `json:"public"`
+
+	// RateLimits Daily quota of every producer, a limit is absent when unlimited.
+	RateLimits RateLimits `json:"rateLimits"`
+
+	// ReportFormats Formats mission reports can be rendered in, empty when reports are disabled.
+	ReportFormats []string `json:"reportFormats"`
+
+	// Streaming State changes can be streamed to clients, otherwise they poll the rockets.
+	Streaming bool `json:"streaming"`
+}
+
+// DryRunResult What processing a message would do.
+type DryRunResult struct {
+	// Changes Fields the message would change.
+	Changes []FieldChange `json:"changes"`
+
+	// Current The current aggregated state of a rocket.
+	Current *RocketState `json:"current,omitempty"`
+
+	// Next The current aggregated state of a rocket.
+	Next *RocketState `json:"next,omitempty"`
+
+	// Outcome applied or backfilled (a late launch of a provisional state) when the message would change the state,
+	// duplicate for an old or duplicate message, buffered when it is ahead of a gap and the reorder buffer is on,
+	// ignored when the channel is quarantined.
+	Outcome DryRunResultOutcome `json:"outcome"`
+
+	// Underflow The message would decrease the speed below zero.
+	Underflow bool `json:"underflow"`
+}
+
+// DryRunResultOutcome applied or backfilled (a late launch of a provisional state) when the message would change the state,
+// duplicate for an old or duplicate message, buffered when it is ahead of a gap and the reorder buffer is on,
+// ignored when the channel is quarantined.
+type DryRunResultOutcome string
+
+// ErrorCode A machine-readable error code. Clients switch on the code, the message is meant for humans and may change.
+type ErrorCode string
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
-	// Code A unique error code.
-	Code string `json:"code"`
+	// Code A machine-readable error code. Clients switch on the code, the message is meant for humans and may change.
+	Code ErrorCode `json:"code"`
+
+	// Detail The original message, in English, when message is translated into the language of the Accept-Language header.
+	Detail *string `json:"detail,omitempty"`
+
+	// IncidentId Identifier of the incident recorded for an unexpected server error, to be quoted when reporting it.
+	IncidentId *string `json:"incidentId,omitempty"`
 
 	// Message A human-readable error message.
 	Message string `json:"message"`
 }
 
+// ExplosionReason Why the rocket exploded, the reported reason classified into a category and a severity by the words it is made of.
+type ExplosionReason struct {
+	// Category Cause of the explosion: PROPULSION (engines, propellants), STRUCTURAL (tanks, pressure vessels, airframe), GUIDANCE (guidance, navigation, control, software), ELECTRICAL, THERMAL, RANGE_SAFETY (flight terminated on command), WEATHER or OTHER when none matched.
+	Category ExplosionReasonCategory `json:"category"`
+
+	// Severity CRITICAL when the vehicle was lost to an uncontrolled failure, MAJOR when it was destroyed under control, e.g. by the flight termination system.
+	Severity ExplosionReasonSeverity `json:"severity"`
+
+	// Text The reason as reported by the producer.
+	Text string `json:"text"`
+}
+
+// ExplosionReasonCategory Cause of the explosion: PROPULSION (engines, propellants), STRUCTURAL (tanks, pressure vessels, airframe), GUIDANCE (guidance, navigation, control, software), ELECTRICAL, THERMAL, RANGE_SAFETY (flight terminated on command), WEATHER or OTHER when none matched.
+type ExplosionReasonCategory string
+
+// ExplosionReasonSeverity CRITICAL when the vehicle was lost to an uncontrolled failure, MAJOR when it was destroyed under control, e.g. by the flight termination system.
+type ExplosionReasonSeverity string
+
+// FieldChange Field of the rocket state changed by a message.
+type FieldChange struct {
+	Field string `json:"field"`
+
+	// From Value before the message, absent if unset.
+	From *interface{} `json:"from,omitempty"`
+
+	// To Value after the message, absent if unset.
+	To *interface{} `json:"to,omitempty"`
+}
+
+// Fleet A named group of rockets tracked as one unit.
+type Fleet struct {
+	Description *string `json:"description,omitempty"`
+	Name        string  `json:"name"`
+
+	// Rockets Channels of the rockets of the fleet, the ones outside the scope of the API key left out.
+	Rockets []openapi_types.UUID `json:"rockets"`
+
+	// Stats Aggregate of the states of the rockets of a fleet.
+	Stats FleetStats `json:"stats"`
+}
+
+// FleetStats Aggregate of the states of the rockets of a fleet.
+type FleetStats struct {
+	// AverageSpeed Average current speed of the tracked rockets in m/s.
+	AverageSpeed int64 `json:"averageSpeed"`
+	Exploded     int   `json:"exploded"`
+
+	// LastUpdateTime Latest update of a rocket of the fleet, absent when none is tracked.
+	LastUpdateTime *time.Time `json:"lastUpdateTime,omitempty"`
+	Launched       int        `json:"launched"`
+	MaxSpeed       int64      `json:"maxSpeed"`
+
+	// Missions Distinct missions of the tracked rockets, sorted.
+	Missions []string `json:"missions"`
+
+	// Other Tracked rockets with another status, e.g. PARTIAL.
+	Other int `json:"other"`
+
+	// Rockets Rockets of the fleet.
+	Rockets int `json:"rockets"`
+
+	// Tracked Rockets of the fleet that have sent a message.
+	Tracked int `json:"tracked"`
+}
+
+// IngestResult What processing a message did.
+type IngestResult struct {
+	// Disposition applied or backfilled (a late launch of a provisional state) when the message changed the state,
+	// duplicate for an old or duplicate message, buffered when it is ahead of a gap and held by the reorder
+	// buffer, ignored when the channel is quarantined.
+	Disposition IngestResultDisposition `json:"disposition"`
+
+	// Version Version of the rocket state after the message, the current one when the message was not applied.
+	Version int64 `json:"version"`
+
+	// Warnings Notable things that did not stop the message, e.g. a speed decrease below zero.
+	Warnings []string `json:"warnings"`
+}
+
+// IngestResultDisposition applied or backfilled (a late launch of a provisional state) when the message changed the state,
+// duplicate for an old or duplicate message, buffered when it is ahead of a gap and held by the reorder
+// buffer, ignored when the channel is quarantined.
+type IngestResultDisposition string
+
 // Message The specific message payload, determined by `metadata.messageType`.
 type Message struct {
 	// By Amount for speed change (for RocketSpeedIncreased/Decreased)
@@ -70,10 +389,10 @@ type Message struct {
 	// LaunchSpeed Launch speed (for RocketLaunched)
 	LaunchSpeed *int64 `json:"launchSpeed,omitempty"`
 
-	// Mission Mission name (for RocketLaunched)
+	// Mission Mission name (for RocketLaunched), normalized to upper case
 	Mission *string `json:"mission,omitempty"`
 
-	// NewMission New mission name (for RocketMissionChanged)
+	// NewMission New mission name (for RocketMissionChanged), normalized to upper case
 	NewMission *string `json:"newMission,omitempty"`
 
 	// Reason Reason for explosion (for RocketExploded)
@@ -88,24 +407,150 @@ type MessageMetadata struct {
 	// Channel Unique identifier for the rocket (also its ID).
 	Channel openapi_types.UUID `json:"channel"`
 
-	// MessageNumber Order of the message within its channel. Higher is newer.
-	MessageNumber int64 `json:"messageNumber"`
+	// MessageNumber Order of the message within its channel. Higher is newer. Omitted by the producers sending messages without numbers (ROCKETS_UNNUMBERED_CHANNELS, ROCKETS_UNNUMBERED_PRODUCERS), whose messages are ordered by messageTime and numbered on arrival.
+	MessageNumber int64 `json:"messageNumber,omitempty"`
 
 	// MessageTime Timestamp when the message was sent (ISO 8601 format).
 	MessageTime time.Time `json:"messageTime"`
 
-	// MessageType Type of event described by the message.
+	// MessageType Type of event described by the message. RocketHeartbeat carries no payload and only keeps the channel alive, advancing lastUpdateTime and lastProcessedMessageNumber.
 	MessageType MessageMetadataMessageType `json:"messageType"`
 }
 
-// MessageMetadataMessageType Type of event described by the message.
+// MessageMetadataMessageType Type of event described by the message. RocketHeartbeat carries no payload and only keeps the channel alive, advancing lastUpdateTime and lastProcessedMessageNumber.
 type MessageMetadataMessageType string
 
+// MessageRates Messages per minute the rocket sent over sliding windows, counted when they were applied.
+type MessageRates struct {
+	// FifteenMinutes Messages per minute over the last 15 minutes.
+	FifteenMinutes float64 `json:"fifteenMinutes"`
+
+	// FiveMinutes Messages per minute over the last 5 minutes.
+	FiveMinutes float64 `json:"fiveMinutes"`
+
+	// OneMinute Messages per minute over the last minute.
+	OneMinute float64 `json:"oneMinute"`
+}
+
+// ProcessingStats What processing did with the messages of a channel since the instance started.
+type ProcessingStats struct {
+	// Duplicates Messages not after the last processed one, not applied.
+	Duplicates int64 `json:"duplicates"`
+
+	// Ignored Messages received while the channel was quarantined.
+	Ignored int64 `json:"ignored"`
+
+	// LargestGap Most messages found missing before an arriving one.
+	LargestGap int64 `json:"largestGap"`
+
+	// LastRejection Invalid message of a channel.
+	LastRejection *Rejection `json:"lastRejection,omitempty"`
+
+	// OutOfOrder Messages that arrived ahead of a missing predecessor.
+	OutOfOrder int64 `json:"outOfOrder"`
+
+	// Received Messages of the channel, whatever processing did with them.
+	Received int64 `json:"received"`
+
+	// Rejected Invalid messages.
+	Rejected int64 `json:"rejected"`
+}
+
+// ProducerUsage Accounted traffic of a producer during a single UTC day.
+type ProducerUsage struct {
+	// ByteQuota Daily bytes quota, absent if unlimited.
+	ByteQuota *int64 `json:"byteQuota,omitempty"`
+
+	// Bytes Bytes received during the day.
+	Bytes int64 `json:"bytes"`
+
+	// Date The UTC day.
+	Date openapi_types.Date `json:"date"`
+
+	// MessageQuota Daily messages quota, absent if unlimited.
+	MessageQuota *int64 `json:"messageQuota,omitempty"`
+
+	// Messages Messages received during the day.
+	Messages int64 `json:"messages"`
+
+	// Producer Name of the producer the API key was issued to.
+	Producer string `json:"producer"`
+}
+
+// RateLimits Daily quota of every producer, a limit is absent when unlimited.
+type RateLimits struct {
+	// DailyBytes Bytes of message bodies a producer may ingest per UTC day.
+	DailyBytes *int64 `json:"dailyBytes,omitempty"`
+
+	// DailyMessages Messages a producer may ingest per UTC day.
+	DailyMessages *int64 `json:"dailyMessages,omitempty"`
+}
+
+// Registration Rocket registered ahead of its telemetry.
+type Registration struct {
+	// AwaitingTelemetry No message of the rocket was applied yet.
+	AwaitingTelemetry bool               `json:"awaitingTelemetry"`
+	Id                openapi_types.UUID `json:"id"`
+	Mission           string             `json:"mission"`
+
+	// RegisteredAt When the rocket was registered with its type and mission.
+	RegisteredAt time.Time `json:"registeredAt"`
+	Type         string    `json:"type"`
+}
+
+// Rejection Invalid message of a channel.
+type Rejection struct {
+	// MessageNumber Number of the rejected message.
+	MessageNumber int64 `json:"messageNumber"`
+
+	// Reason Why the message was rejected.
+	Reason string `json:"reason"`
+
+	// Time When the message was rejected.
+	Time time.Time `json:"time"`
+}
+
+// RocketBatchRequest Rockets to look up at once.
+type RocketBatchRequest struct {
+	// Ids Unique identifiers (channels) of the rockets, a repeated id is looked up once and answered for every occurrence.
+	Ids []openapi_types.UUID `json:"ids"`
+}
+
+// RocketLookup Outcome of looking up one rocket of a batch.
+type RocketLookup struct {
+	// Id The requested id.
+	Id openapi_types.UUID `json:"id"`
+
+	// State The current aggregated state of a rocket.
+	State *RocketState `json:"state,omitempty"`
+
+	// Status found with the state of the rocket, not_found when no message of the rocket was processed, forbidden
+	// when the rocket is outside the scope of the API key.
+	Status RocketLookupStatus `json:"status"`
+}
+
+// RocketLookupStatus found with the state of the rocket, not_found when no message of the rocket was processed, forbidden
+// when the rocket is outside the scope of the API key.
+type RocketLookupStatus string
+
+// RocketRegistration Type and mission the launch of a rocket must report.
+type RocketRegistration struct {
+	// Mission Mission name, normalized like mission names of messages.
+	Mission string `json:"mission"`
+	Type    string `json:"type"`
+}
+
 // RocketState The current aggregated state of a rocket.
 type RocketState struct {
+	// Anomaly The last speed decrease below zero, with the anomalous speed underflow policy.
+	Anomaly *string `json:"anomaly,omitempty"`
+
 	// CurrentSpeed Current speed of the rocket in meters per second (m/s).
 	CurrentSpeed int64 `json:"currentSpeed"`
 
+	// ExplosionReason Why the rocket exploded, the reported reason classified into a category and a severity by the words it is made of.
+	ExplosionReason *ExplosionReason `json:"explosionReason,omitempty"`
+
 	// Id Unique identifier (channel) of the rocket.
 	Id openapi_types.UUID `json:"id"`
 
@@ -115,22 +560,37 @@ type RocketState struct {
 	// LastUpdateTime Timestamp of the last processed message that updated this state.
 	LastUpdateTime time.Time `json:"lastUpdateTime"`
 
+	// MessageRates Messages per minute the rocket sent over sliding windows, counted when they were applied.
+	MessageRates *MessageRates `json:"messageRates,omitempty"`
+
 	// Mission The current mission assigned to the rocket.
 	Mission string `json:"mission"`
 
-	// Reason If exploded, the reason for the explosion.
+	// Name Human-friendly name assigned to the rocket by the operators, e.g. a tail number.
+	Name *string `json:"name,omitempty"`
+
+	// Reason If exploded, the reason for the explosion as reported. See explosionReason for its classification.
 	Reason *string `json:"reason"`
 
-	// Status The operational status of the rocket.
+	// Status The operational status of the rocket. PARTIAL when the launch message has not arrived yet and the state holds what later messages told (provisional state mode).
 	Status RocketStateStatus `json:"status"`
 
 	// Type The type of the rocket (e.g., Falcon-9, Soyuz).
 	Type string `json:"type"`
 }
 
-// RocketStateStatus The operational status of the rocket.
+// RocketStateStatus The operational status of the rocket. PARTIAL when the launch message has not arrived yet and the state holds what later messages told (provisional state mode).
 type RocketStateStatus string
 
+// SpeedSample Speed of a rocket at a point in time.
+type SpeedSample struct {
+	// Speed Speed in m/s.
+	Speed int64 `json:"speed"`
+
+	// Time Time of the message, or the start of the aggregation window.
+	Time time.Time `json:"time"`
+}
+
 // TelemetryMessage Base schema for any telemetry message received from a rocket.
 type TelemetryMessage struct {
 	// Message The specific message payload, determined by `metadata.messageType`.
@@ -138,6 +598,45 @@ type TelemetryMessage struct {
 	Metadata MessageMetadata `json:"metadata"`
 }
 
+// Watchlist A named list of rockets followed together.
+type Watchlist struct {
+	Description *string `json:"description,omitempty"`
+	Name        string  `json:"name"`
+
+	// Rockets Channels of the rockets, in the order they are shown.
+	Rockets []openapi_types.UUID `json:"rockets"`
+}
+
+// WatchlistStates A watchlist with the current states of its rockets.
+type WatchlistStates struct {
+	Description *string `json:"description,omitempty"`
+	Name        string  `json:"name"`
+
+	// Rockets Lookups of the rockets, in the order of the watchlist.
+	Rockets []RocketLookup `json:"rockets"`
+}
+
+// IngestMessageParams defines parameters for IngestMessage.
+type IngestMessageParams struct {
+	// DryRun Only validate the message and check it against the current state of the rocket, returning what
+	// processing it would change without persisting anything. Lets producers verify new payload formats
+	// safely against production.
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
+
+	// Prefer `handling=lenient` accepts old and duplicate messages with `202` and the `duplicate` disposition
+	// instead of answering `409`.
+	Prefer *string `json:"Prefer,omitempty"`
+}
+
+// GetMissionReportParams defines parameters for GetMissionReport.
+type GetMissionReportParams struct {
+	// Format Output format of the report.
+	Format *GetMissionReportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
+
+// GetMissionReportParamsFormat defines parameters for GetMissionReport.
+type GetMissionReportParamsFormat string
+
 // ListRocketsParams defines parameters for ListRockets.
 type ListRocketsParams struct {
 	// SortBy Field to sort by (e.g., id, type, speed, mission, lastUpdateTime)
@@ -145,6 +644,22 @@ type ListRocketsParams struct {
 
 	// SortOrder Sort order (asc or desc)
 	SortOrder *ListRocketsParamsSortOrder `form:"sortOrder,omitempty" json:"sortOrder,omitempty"`
+
+	// SortModifier How the string fields (type, mission) are compared. natural compares the numbers within them by value,
+	// so Falcon-9 sorts before Falcon-10.
+	SortModifier *ListRocketsParamsSortModifier `form:"sortModifier,omitempty" json:"sortModifier,omitempty"`
+
+	// Status Only rockets with this status.
+	Status *ListRocketsParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// Mission Only rockets currently flying this mission, normalized like mission names of messages.
+	Mission *string `form:"mission,omitempty" json:"mission,omitempty"`
+
+	// Type Only rockets of this type.
+	Type *string `form:"type,omitempty" json:"type,omitempty"`
+
+	// Noisy Only rockets sending more messages per minute over the last minute than the configured noisy rate.
+	Noisy *bool `form:"noisy,omitempty" json:"noisy,omitempty"`
 }
 
 // ListRocketsParamsSortBy defines parameters for ListRockets.
@@ -153,20 +668,92 @@ type ListRocketsParamsSortBy string
 // ListRocketsParamsSortOrder defines parameters for ListRockets.
 type ListRocketsParamsSortOrder string
 
+// ListRocketsParamsSortModifier defines parameters for ListRockets.
+type ListRocketsParamsSortModifier string
+
+// ListRocketsParamsStatus defines parameters for ListRockets.
+type ListRocketsParamsStatus string
+
+// GetRocketSpeedHistoryParams defines parameters for GetRocketSpeedHistory.
+type GetRocketSpeedHistoryParams struct {
+	// Window Length of the aggregation windows as a Go duration, e.g. 1m; the samples are returned as they are without it.
+	Window *string `form:"window,omitempty" json:"window,omitempty"`
+
+	// Agg How the samples of a window are combined, avg by default.
+	Agg *GetRocketSpeedHistoryParamsAgg `form:"agg,omitempty" json:"agg,omitempty"`
+}
+
+// GetRocketSpeedHistoryParamsAgg defines parameters for GetRocketSpeedHistory.
+type GetRocketSpeedHistoryParamsAgg string
+
 // IngestMessageJSONRequestBody defines body for IngestMessage for application/json ContentType.
 type IngestMessageJSONRequestBody = TelemetryMessage
 
+// BackfillHistoryJSONRequestBody defines body for BackfillHistory for application/json ContentType.
+type BackfillHistoryJSONRequestBody = BackfillRequest
+
+// BatchGetRocketsJSONRequestBody defines body for BatchGetRockets for application/json ContentType.
+type BatchGetRocketsJSONRequestBody = RocketBatchRequest
+
+// RegisterRocketJSONRequestBody defines body for RegisterRocket for application/json ContentType.
+type RegisterRocketJSONRequestBody = RocketRegistration
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// Ingest a new rocket telemetry message
 	// (POST /messages)
-	IngestMessage(ctx echo.Context) error
+	IngestMessage(ctx echo.Context, params IngestMessageParams) error
+	// Merge historical telemetry into the history of a rocket
+	// (POST /v1/backfill)
+	BackfillHistory(ctx echo.Context) error
+	// Get the capabilities of the deployment
+	// (GET /v1/capabilities)
+	GetCapabilities(ctx echo.Context) error
+	// Get the fleets and their aggregate stats
+	// (GET /v1/fleets)
+	ListFleets(ctx echo.Context) error
+	// Get a fleet and its aggregate stats
+	// (GET /v1/fleets/{name})
+	GetFleet(ctx echo.Context, name string) error
+	// Get a rendered summary of a mission
+	// (GET /v1/missions/{name}/report)
+	GetMissionReport(ctx echo.Context, name string, params GetMissionReportParams) error
+	// Get the registered rockets
+	// (GET /v1/registrations)
+	ListRegistrations(ctx echo.Context) error
 	// Get a list of all rockets and their current states
 	// (GET /v1/rockets)
 	ListRockets(ctx echo.Context, params ListRocketsParams) error
+	// Get the current states of several rockets
+	// (POST /v1/rockets/batch-get)
+	BatchGetRockets(ctx echo.Context) error
+	// Get the current state of a rocket by its name
+	// (GET /v1/rockets/by-name/{name})
+	GetRocketByName(ctx echo.Context, name string) error
 	// Get the current state of a specific rocket
 	// (GET /v1/rockets/{id})
 	GetRocketState(ctx echo.Context, id openapi_types.UUID) error
+	// Register a rocket before its telemetry starts
+	// (PUT /v1/rockets/{id})
+	RegisterRocket(ctx echo.Context, id openapi_types.UUID) error
+	// Get the processing statistics of a rocket
+	// (GET /v1/rockets/{id}/processing-stats)
+	GetRocketProcessingStats(ctx echo.Context, id openapi_types.UUID) error
+	// Get the speed history of a rocket
+	// (GET /v1/rockets/{id}/speed-history)
+	GetRocketSpeedHistory(ctx echo.Context, id openapi_types.UUID, params GetRocketSpeedHistoryParams) error
+	// Get per-producer usage
+	// (GET /v1/usage)
+	GetUsage(ctx echo.Context) error
+	// Get the build of the running instance
+	// (GET /v1/version)
+	GetVersion(ctx echo.Context) error
+	// Get the watchlists
+	// (GET /v1/watchlists)
+	ListWatchlists(ctx echo.Context) error
+	// Get the current states of the rockets of a watchlist
+	// (GET /v1/watchlists/{name})
+	GetWatchlist(ctx echo.Context, name string) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -178,8 +765,111 @@ type ServerInterfaceWrapper struct {
 func (w *ServerInterfaceWrapper) IngestMessage(ctx echo.Context) error {
 	var err error
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params IngestMessageParams
+	// ------------- Optional query parameter "dryRun" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", ctx.QueryParams(), &params.DryRun)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter dryRun: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "Prefer" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Prefer")]; found {
+		var Prefer string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for Prefer, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "Prefer", runtime.ParamLocationHeader, valueList[0], &Prefer)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter Prefer: %s", err))
+		}
+
+		params.Prefer = &Prefer
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.IngestMessage(ctx, params)
+	return err
+}
+
+// BackfillHistory converts echo context to params.
+func (w *ServerInterfaceWrapper) BackfillHistory(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.BackfillHistory(ctx)
+	return err
+}
+
+// GetCapabilities converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCapabilities(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetCapabilities(ctx)
+	return err
+}
+
+// ListFleets converts echo context to params.
+func (w *ServerInterfaceWrapper) ListFleets(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListFleets(ctx)
+	return err
+}
+
+// GetFleet converts echo context to params.
+func (w *ServerInterfaceWrapper) GetFleet(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetFleet(ctx, name)
+	return err
+}
+
+// GetMissionReport converts echo context to params.
+func (w *ServerInterfaceWrapper) GetMissionReport(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetMissionReportParams
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", ctx.QueryParams(), &params.Format)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter format: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetMissionReport(ctx, name, params)
+	return err
+}
+
+// ListRegistrations converts echo context to params.
+func (w *ServerInterfaceWrapper) ListRegistrations(ctx echo.Context) error {
+	var err error
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.IngestMessage(ctx)
+	err = w.Handler.ListRegistrations(ctx)
 	return err
 }
 
@@ -203,24 +893,191 @@ func (w *ServerInterfaceWrapper) ListRockets(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sortOrder: %s", err))
 	}
 
+	// ------------- Optional query parameter "sortModifier" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sortModifier", ctx.QueryParams(), &params.SortModifier)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sortModifier: %s", err))
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
+	// ------------- Optional query parameter "mission" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "mission", ctx.QueryParams(), &params.Mission)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter mission: %s", err))
+	}
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", ctx.QueryParams(), &params.Type)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter type: %s", err))
+	}
+
+	// ------------- Optional query parameter "noisy" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "noisy", ctx.QueryParams(), &params.Noisy)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter noisy: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
 	err = w.Handler.ListRockets(ctx, params)
 	return err
 }
 
-// GetRocketState converts echo context to params.
-func (w *ServerInterfaceWrapper) GetRocketState(ctx echo.Context) error {
+// BatchGetRockets converts echo context to params.
+func (w *ServerInterfaceWrapper) BatchGetRockets(ctx echo.Context) error {
 	var err error
-	// ------------- Path parameter "id" -------------
-	var id openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.BatchGetRockets(ctx)
+	return err
+}
+
+// GetRocketByName converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRocketByName(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
 	}
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.GetRocketState(ctx, id)
+	err = w.Handler.GetRocketByName(ctx, name)
+	return err
+}
+
+// GetRocketState converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRocketState(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetRocketState(ctx, id)
+	return err
+}
+
+// RegisterRocket converts echo context to params.
+func (w *ServerInterfaceWrapper) RegisterRocket(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RegisterRocket(ctx, id)
+	return err
+}
+
+// GetRocketProcessingStats converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRocketProcessingStats(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetRocketProcessingStats(ctx, id)
+	return err
+}
+
+// GetRocketSpeedHistory converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRocketSpeedHistory(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetRocketSpeedHistoryParams
+	// ------------- Optional query parameter "window" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "window", ctx.QueryParams(), &params.Window)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter window: %s", err))
+	}
+
+	// ------------- Optional query parameter "agg" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "agg", ctx.QueryParams(), &params.Agg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter agg: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetRocketSpeedHistory(ctx, id, params)
+	return err
+}
+
+// GetUsage converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUsage(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUsage(ctx)
+	return err
+}
+
+// GetVersion converts echo context to params.
+func (w *ServerInterfaceWrapper) GetVersion(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetVersion(ctx)
+	return err
+}
+
+// ListWatchlists converts echo context to params.
+func (w *ServerInterfaceWrapper) ListWatchlists(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListWatchlists(ctx)
+	return err
+}
+
+// GetWatchlist converts echo context to params.
+func (w *ServerInterfaceWrapper) GetWatchlist(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetWatchlist(ctx, name)
 	return err
 }
 
@@ -253,23 +1110,46 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	}
 
 	router.POST(baseURL+"/messages", wrapper.IngestMessage)
+	router.POST(baseURL+"/v1/backfill", wrapper.BackfillHistory)
+	router.GET(baseURL+"/v1/capabilities", wrapper.GetCapabilities)
+	router.GET(baseURL+"/v1/fleets", wrapper.ListFleets)
+	router.GET(baseURL+"/v1/fleets/:name", wrapper.GetFleet)
+	router.GET(baseURL+"/v1/missions/:name/report", wrapper.GetMissionReport)
+	router.GET(baseURL+"/v1/registrations", wrapper.ListRegistrations)
 	router.GET(baseURL+"/v1/rockets", wrapper.ListRockets)
+	router.POST(baseURL+"/v1/rockets/batch-get", wrapper.BatchGetRockets)
+	router.GET(baseURL+"/v1/rockets/by-name/:name", wrapper.GetRocketByName)
 	router.GET(baseURL+"/v1/rockets/:id", wrapper.GetRocketState)
+	router.PUT(baseURL+"/v1/rockets/:id", wrapper.RegisterRocket)
+	router.GET(baseURL+"/v1/rockets/:id/processing-stats", wrapper.GetRocketProcessingStats)
+	router.GET(baseURL+"/v1/rockets/:id/speed-history", wrapper.GetRocketSpeedHistory)
+	router.GET(baseURL+"/v1/usage", wrapper.GetUsage)
+	router.GET(baseURL+"/v1/version", wrapper.GetVersion)
+	router.GET(baseURL+"/v1/watchlists", wrapper.ListWatchlists)
+	router.GET(baseURL+"/v1/watchlists/:name", wrapper.GetWatchlist)
 
 }
 
 type IngestMessageRequestObject struct {
-	Body *IngestMessageJSONRequestBody
+	Params IngestMessageParams
+	Body   *IngestMessageJSONRequestBody
 }
 
 type IngestMessageResponseObject interface {
 	VisitIngestMessageResponse(w http.ResponseWriter) error
 }
 
-type IngestMessage202JSONResponse struct {
-	Status *string `json:"status,omitempty"`
+type IngestMessage200JSONResponse DryRunResult
+
+func (response IngestMessage200JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
+type IngestMessage202JSONResponse IngestResult
+
 func (response IngestMessage202JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(202)
@@ -286,183 +1166,1456 @@ func (response IngestMessage400JSONResponse) VisitIngestMessageResponse(w http.R
 	return json.NewEncoder(w).Encode(response)
 }
 
-type IngestMessage500JSONResponse ErrorResponse
+type IngestMessage401JSONResponse ErrorResponse
 
-func (response IngestMessage500JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
+func (response IngestMessage401JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(500)
+	w.WriteHeader(401)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type ListRocketsRequestObject struct {
-	Params ListRocketsParams
+type IngestMessage403JSONResponse ErrorResponse
+
+func (response IngestMessage403JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type ListRocketsResponseObject interface {
-	VisitListRocketsResponse(w http.ResponseWriter) error
+type IngestMessage409JSONResponse ErrorResponse
+
+func (response IngestMessage409JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type ListRockets200JSONResponse []RocketState
+type IngestMessage413JSONResponse ErrorResponse
 
-func (response ListRockets200JSONResponse) VisitListRocketsResponse(w http.ResponseWriter) error {
+func (response IngestMessage413JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(200)
+	w.WriteHeader(413)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type ListRockets400JSONResponse ErrorResponse
+type IngestMessage415JSONResponse ErrorResponse
 
-func (response ListRockets400JSONResponse) VisitListRocketsResponse(w http.ResponseWriter) error {
+func (response IngestMessage415JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(400)
+	w.WriteHeader(415)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type ListRockets500JSONResponse ErrorResponse
+type IngestMessage421JSONResponse ErrorResponse
 
-func (response ListRockets500JSONResponse) VisitListRocketsResponse(w http.ResponseWriter) error {
+func (response IngestMessage421JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(421)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type IngestMessage429JSONResponse ErrorResponse
+
+func (response IngestMessage429JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type IngestMessage500JSONResponse ErrorResponse
+
+func (response IngestMessage500JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(500)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type GetRocketStateRequestObject struct {
-	Id openapi_types.UUID `json:"id"`
+type IngestMessage502JSONResponse ErrorResponse
+
+func (response IngestMessage502JSONResponse) VisitIngestMessageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type GetRocketStateResponseObject interface {
-	VisitGetRocketStateResponse(w http.ResponseWriter) error
+type BackfillHistoryRequestObject struct {
+	Body *BackfillHistoryJSONRequestBody
 }
 
-type GetRocketState200JSONResponse RocketState
+type BackfillHistoryResponseObject interface {
+	VisitBackfillHistoryResponse(w http.ResponseWriter) error
+}
 
-func (response GetRocketState200JSONResponse) VisitGetRocketStateResponse(w http.ResponseWriter) error {
+type BackfillHistory200JSONResponse BackfillResult
+
+func (response BackfillHistory200JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type GetRocketState404JSONResponse ErrorResponse
+type BackfillHistory400JSONResponse ErrorResponse
 
-func (response GetRocketState404JSONResponse) VisitGetRocketStateResponse(w http.ResponseWriter) error {
+func (response BackfillHistory400JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(404)
+	w.WriteHeader(400)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type GetRocketState500JSONResponse ErrorResponse
+type BackfillHistory401JSONResponse ErrorResponse
 
-func (response GetRocketState500JSONResponse) VisitGetRocketStateResponse(w http.ResponseWriter) error {
+func (response BackfillHistory401JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(500)
+	w.WriteHeader(401)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-// StrictServerInterface represents all server handlers.
-type StrictServerInterface interface {
-	// Ingest a new rocket telemetry message
-	// (POST /messages)
-	IngestMessage(ctx context.Context, request IngestMessageRequestObject) (IngestMessageResponseObject, error)
-	// Get a list of all rockets and their current states
-	// (GET /v1/rockets)
-	ListRockets(ctx context.Context, request ListRocketsRequestObject) (ListRocketsResponseObject, error)
-	// Get the current state of a specific rocket
-	// (GET /v1/rockets/{id})
-	GetRocketState(ctx context.Context, request GetRocketStateRequestObject) (GetRocketStateResponseObject, error)
+type BackfillHistory403JSONResponse ErrorResponse
+
+func (response BackfillHistory403JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type StrictHandlerFunc = strictecho.StrictEchoHandlerFunc
-type StrictMiddlewareFunc = strictecho.StrictEchoMiddlewareFunc
+type BackfillHistory404JSONResponse ErrorResponse
 
-func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
-	return &strictHandler{ssi: ssi, middlewares: middlewares}
+func (response BackfillHistory404JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type strictHandler struct {
-	ssi         StrictServerInterface
-	middlewares []StrictMiddlewareFunc
+type BackfillHistory409JSONResponse ErrorResponse
+
+func (response BackfillHistory409JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-// IngestMessage operation middleware
-func (sh *strictHandler) IngestMessage(ctx echo.Context) error {
-	var request IngestMessageRequestObject
+type BackfillHistory413JSONResponse ErrorResponse
 
-	var body IngestMessageJSONRequestBody
-	if err := ctx.Bind(&body); err != nil {
-		return err
-	}
-	request.Body = &body
+func (response BackfillHistory413JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(413)
 
-	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
-		return sh.ssi.IngestMessage(ctx.Request().Context(), request.(IngestMessageRequestObject))
-	}
-	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "IngestMessage")
-	}
+	return json.NewEncoder(w).Encode(response)
+}
 
-	response, err := handler(ctx, request)
+type BackfillHistory421JSONResponse ErrorResponse
 
-	if err != nil {
-		return err
-	} else if validResponse, ok := response.(IngestMessageResponseObject); ok {
-		return validResponse.VisitIngestMessageResponse(ctx.Response())
-	} else if response != nil {
-		return fmt.Errorf("unexpected response type: %T", response)
-	}
-	return nil
+func (response BackfillHistory421JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(421)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-// ListRockets operation middleware
-func (sh *strictHandler) ListRockets(ctx echo.Context, params ListRocketsParams) error {
-	var request ListRocketsRequestObject
+type BackfillHistory429JSONResponse ErrorResponse
 
-	request.Params = params
+func (response BackfillHistory429JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(429)
 
-	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
-		return sh.ssi.ListRockets(ctx.Request().Context(), request.(ListRocketsRequestObject))
-	}
-	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "ListRockets")
-	}
+	return json.NewEncoder(w).Encode(response)
+}
 
-	response, err := handler(ctx, request)
+type BackfillHistory500JSONResponse ErrorResponse
 
-	if err != nil {
-		return err
-	} else if validResponse, ok := response.(ListRocketsResponseObject); ok {
-		return validResponse.VisitListRocketsResponse(ctx.Response())
-	} else if response != nil {
-		return fmt.Errorf("unexpected response type: %T", response)
-	}
-	return nil
+func (response BackfillHistory500JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-// GetRocketState operation middleware
-func (sh *strictHandler) GetRocketState(ctx echo.Context, id openapi_types.UUID) error {
-	var request GetRocketStateRequestObject
+type BackfillHistory502JSONResponse ErrorResponse
 
-	request.Id = id
+func (response BackfillHistory502JSONResponse) VisitBackfillHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(502)
 
-	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
-		return sh.ssi.GetRocketState(ctx.Request().Context(), request.(GetRocketStateRequestObject))
-	}
-	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetRocketState")
-	}
+	return json.NewEncoder(w).Encode(response)
+}
 
-	response, err := handler(ctx, request)
+type GetCapabilitiesRequestObject struct {
+}
 
-	if err != nil {
-		return err
-	} else if validResponse, ok := response.(GetRocketStateResponseObject); ok {
-		return validResponse.VisitGetRocketStateResponse(ctx.Response())
+type GetCapabilitiesResponseObject interface {
+	VisitGetCapabilitiesResponse(w http.ResponseWriter) error
+}
+
+type GetCapabilities200JSONResponse Capabilities
+
+func (response GetCapabilities200JSONResponse) VisitGetCapabilitiesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListFleetsRequestObject struct {
+}
+
+type ListFleetsResponseObject interface {
+	VisitListFleetsResponse(w http.ResponseWriter) error
+}
+
+type ListFleets200JSONResponse []Fleet
+
+func (response ListFleets200JSONResponse) VisitListFleetsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListFleets500JSONResponse ErrorResponse
+
+func (response ListFleets500JSONResponse) VisitListFleetsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListFleets503JSONResponse ErrorResponse
+
+func (response ListFleets503JSONResponse) VisitListFleetsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetFleetRequestObject struct {
+	Name string `json:"name"`
+}
+
+type GetFleetResponseObject interface {
+	VisitGetFleetResponse(w http.ResponseWriter) error
+}
+
+type GetFleet200JSONResponse Fleet
+
+func (response GetFleet200JSONResponse) VisitGetFleetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetFleet404JSONResponse ErrorResponse
+
+func (response GetFleet404JSONResponse) VisitGetFleetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetFleet500JSONResponse ErrorResponse
+
+func (response GetFleet500JSONResponse) VisitGetFleetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetFleet503JSONResponse ErrorResponse
+
+func (response GetFleet503JSONResponse) VisitGetFleetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMissionReportRequestObject struct {
+	Name   string `json:"name"`
+	Params GetMissionReportParams
+}
+
+type GetMissionReportResponseObject interface {
+	VisitGetMissionReportResponse(w http.ResponseWriter) error
+}
+
+type GetMissionReport200ApplicationpdfResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetMissionReport200ApplicationpdfResponse) VisitGetMissionReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/pdf")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetMissionReport200TexthtmlResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetMissionReport200TexthtmlResponse) VisitGetMissionReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/html")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetMissionReport400JSONResponse ErrorResponse
+
+func (response GetMissionReport400JSONResponse) VisitGetMissionReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMissionReport403JSONResponse ErrorResponse
+
+func (response GetMissionReport403JSONResponse) VisitGetMissionReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMissionReport404JSONResponse ErrorResponse
+
+func (response GetMissionReport404JSONResponse) VisitGetMissionReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetMissionReport500JSONResponse ErrorResponse
+
+func (response GetMissionReport500JSONResponse) VisitGetMissionReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListRegistrationsRequestObject struct {
+}
+
+type ListRegistrationsResponseObject interface {
+	VisitListRegistrationsResponse(w http.ResponseWriter) error
+}
+
+type ListRegistrations200JSONResponse []Registration
+
+func (response ListRegistrations200JSONResponse) VisitListRegistrationsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListRegistrations500JSONResponse ErrorResponse
+
+func (response ListRegistrations500JSONResponse) VisitListRegistrationsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListRocketsRequestObject struct {
+	Params ListRocketsParams
+}
+
+type ListRocketsResponseObject interface {
+	VisitListRocketsResponse(w http.ResponseWriter) error
+}
+
+type ListRockets200ResponseHeaders struct {
+	XDataAsOf string
+}
+
+type ListRockets200JSONResponse struct {
+	Body    []RocketState
+	Headers ListRockets200ResponseHeaders
+}
+
+func (response ListRockets200JSONResponse) VisitListRocketsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Data-As-Of", fmt.Sprint(response.Headers.XDataAsOf))
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ListRockets400JSONResponse ErrorResponse
+
+func (response ListRockets400JSONResponse) VisitListRocketsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListRockets500JSONResponse ErrorResponse
+
+func (response ListRockets500JSONResponse) VisitListRocketsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListRockets503JSONResponse ErrorResponse
+
+func (response ListRockets503JSONResponse) VisitListRocketsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type BatchGetRocketsRequestObject struct {
+	Body *BatchGetRocketsJSONRequestBody
+}
+
+type BatchGetRocketsResponseObject interface {
+	VisitBatchGetRocketsResponse(w http.ResponseWriter) error
+}
+
+type BatchGetRockets200JSONResponse []RocketLookup
+
+func (response BatchGetRockets200JSONResponse) VisitBatchGetRocketsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type BatchGetRockets400JSONResponse ErrorResponse
+
+func (response BatchGetRockets400JSONResponse) VisitBatchGetRocketsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type BatchGetRockets500JSONResponse ErrorResponse
+
+func (response BatchGetRockets500JSONResponse) VisitBatchGetRocketsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type BatchGetRockets503JSONResponse ErrorResponse
+
+func (response BatchGetRockets503JSONResponse) VisitBatchGetRocketsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketByNameRequestObject struct {
+	Name string `json:"name"`
+}
+
+type GetRocketByNameResponseObject interface {
+	VisitGetRocketByNameResponse(w http.ResponseWriter) error
+}
+
+type GetRocketByName200JSONResponse RocketState
+
+func (response GetRocketByName200JSONResponse) VisitGetRocketByNameResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketByName403JSONResponse ErrorResponse
+
+func (response GetRocketByName403JSONResponse) VisitGetRocketByNameResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketByName404JSONResponse ErrorResponse
+
+func (response GetRocketByName404JSONResponse) VisitGetRocketByNameResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketByName500JSONResponse ErrorResponse
+
+func (response GetRocketByName500JSONResponse) VisitGetRocketByNameResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketByName503JSONResponse ErrorResponse
+
+func (response GetRocketByName503JSONResponse) VisitGetRocketByNameResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketStateRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type GetRocketStateResponseObject interface {
+	VisitGetRocketStateResponse(w http.ResponseWriter) error
+}
+
+type GetRocketState200JSONResponse RocketState
+
+func (response GetRocketState200JSONResponse) VisitGetRocketStateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketState403JSONResponse ErrorResponse
+
+func (response GetRocketState403JSONResponse) VisitGetRocketStateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketState404JSONResponse ErrorResponse
+
+func (response GetRocketState404JSONResponse) VisitGetRocketStateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketState500JSONResponse ErrorResponse
+
+func (response GetRocketState500JSONResponse) VisitGetRocketStateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketState503JSONResponse ErrorResponse
+
+func (response GetRocketState503JSONResponse) VisitGetRocketStateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocketRequestObject struct {
+	Id   openapi_types.UUID `json:"id"`
+	Body *RegisterRocketJSONRequestBody
+}
+
+type RegisterRocketResponseObject interface {
+	VisitRegisterRocketResponse(w http.ResponseWriter) error
+}
+
+type RegisterRocket200JSONResponse Registration
+
+func (response RegisterRocket200JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocket201JSONResponse Registration
+
+func (response RegisterRocket201JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocket400JSONResponse ErrorResponse
+
+func (response RegisterRocket400JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocket401JSONResponse ErrorResponse
+
+func (response RegisterRocket401JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocket403JSONResponse ErrorResponse
+
+func (response RegisterRocket403JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocket409JSONResponse ErrorResponse
+
+func (response RegisterRocket409JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocket421JSONResponse ErrorResponse
+
+func (response RegisterRocket421JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(421)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocket500JSONResponse ErrorResponse
+
+func (response RegisterRocket500JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RegisterRocket502JSONResponse ErrorResponse
+
+func (response RegisterRocket502JSONResponse) VisitRegisterRocketResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(502)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketProcessingStatsRequestObject struct {
+	Id openapi_types.UUID `json:"id"`
+}
+
+type GetRocketProcessingStatsResponseObject interface {
+	VisitGetRocketProcessingStatsResponse(w http.ResponseWriter) error
+}
+
+type GetRocketProcessingStats200JSONResponse ProcessingStats
+
+func (response GetRocketProcessingStats200JSONResponse) VisitGetRocketProcessingStatsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketProcessingStats403JSONResponse ErrorResponse
+
+func (response GetRocketProcessingStats403JSONResponse) VisitGetRocketProcessingStatsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketProcessingStats404JSONResponse ErrorResponse
+
+func (response GetRocketProcessingStats404JSONResponse) VisitGetRocketProcessingStatsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketProcessingStats500JSONResponse ErrorResponse
+
+func (response GetRocketProcessingStats500JSONResponse) VisitGetRocketProcessingStatsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketProcessingStats503JSONResponse ErrorResponse
+
+func (response GetRocketProcessingStats503JSONResponse) VisitGetRocketProcessingStatsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketSpeedHistoryRequestObject struct {
+	Id     openapi_types.UUID `json:"id"`
+	Params GetRocketSpeedHistoryParams
+}
+
+type GetRocketSpeedHistoryResponseObject interface {
+	VisitGetRocketSpeedHistoryResponse(w http.ResponseWriter) error
+}
+
+type GetRocketSpeedHistory200JSONResponse []SpeedSample
+
+func (response GetRocketSpeedHistory200JSONResponse) VisitGetRocketSpeedHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketSpeedHistory400JSONResponse ErrorResponse
+
+func (response GetRocketSpeedHistory400JSONResponse) VisitGetRocketSpeedHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketSpeedHistory403JSONResponse ErrorResponse
+
+func (response GetRocketSpeedHistory403JSONResponse) VisitGetRocketSpeedHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketSpeedHistory404JSONResponse ErrorResponse
+
+func (response GetRocketSpeedHistory404JSONResponse) VisitGetRocketSpeedHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketSpeedHistory500JSONResponse ErrorResponse
+
+func (response GetRocketSpeedHistory500JSONResponse) VisitGetRocketSpeedHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRocketSpeedHistory503JSONResponse ErrorResponse
+
+func (response GetRocketSpeedHistory503JSONResponse) VisitGetRocketSpeedHistoryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetUsageRequestObject struct {
+}
+
+type GetUsageResponseObject interface {
+	VisitGetUsageResponse(w http.ResponseWriter) error
+}
+
+type GetUsage200JSONResponse []ProducerUsage
+
+func (response GetUsage200JSONResponse) VisitGetUsageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetUsage403JSONResponse ErrorResponse
+
+func (response GetUsage403JSONResponse) VisitGetUsageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetUsage500JSONResponse ErrorResponse
+
+func (response GetUsage500JSONResponse) VisitGetUsageResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetVersionRequestObject struct {
+}
+
+type GetVersionResponseObject interface {
+	VisitGetVersionResponse(w http.ResponseWriter) error
+}
+
+type GetVersion200JSONResponse BuildInfo
+
+func (response GetVersion200JSONResponse) VisitGetVersionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListWatchlistsRequestObject struct {
+}
+
+type ListWatchlistsResponseObject interface {
+	VisitListWatchlistsResponse(w http.ResponseWriter) error
+}
+
+type ListWatchlists200JSONResponse []Watchlist
+
+func (response ListWatchlists200JSONResponse) VisitListWatchlistsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListWatchlists500JSONResponse ErrorResponse
+
+func (response ListWatchlists500JSONResponse) VisitListWatchlistsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListWatchlists503JSONResponse ErrorResponse
+
+func (response ListWatchlists503JSONResponse) VisitListWatchlistsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetWatchlistRequestObject struct {
+	Name string `json:"name"`
+}
+
+type GetWatchlistResponseObject interface {
+	VisitGetWatchlistResponse(w http.ResponseWriter) error
+}
+
+type GetWatchlist200JSONResponse WatchlistStates
+
+func (response GetWatchlist200JSONResponse) VisitGetWatchlistResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetWatchlist404JSONResponse ErrorResponse
+
+func (response GetWatchlist404JSONResponse) VisitGetWatchlistResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetWatchlist500JSONResponse ErrorResponse
+
+func (response GetWatchlist500JSONResponse) VisitGetWatchlistResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetWatchlist503JSONResponse ErrorResponse
+
+func (response GetWatchlist503JSONResponse) VisitGetWatchlistResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// Ingest a new rocket telemetry message
+	// (POST /messages)
+	IngestMessage(ctx context.Context, request IngestMessageRequestObject) (IngestMessageResponseObject, error)
+	// Merge historical telemetry into the history of a rocket
+	// (POST /v1/backfill)
+	BackfillHistory(ctx context.Context, request BackfillHistoryRequestObject) (BackfillHistoryResponseObject, error)
+	// Get the capabilities of the deployment
+	// (GET /v1/capabilities)
+	GetCapabilities(ctx context.Context, request GetCapabilitiesRequestObject) (GetCapabilitiesResponseObject, error)
+	// Get the fleets and their aggregate stats
+	// (GET /v1/fleets)
+	ListFleets(ctx context.Context, request ListFleetsRequestObject) (ListFleetsResponseObject, error)
+	// Get a fleet and its aggregate stats
+	// (GET /v1/fleets/{name})
+	GetFleet(ctx context.Context, request GetFleetRequestObject) (GetFleetResponseObject, error)
+	// Get a rendered summary of a mission
+	// (GET /v1/missions/{name}/report)
+	GetMissionReport(ctx context.Context, request GetMissionReportRequestObject) (GetMissionReportResponseObject, error)
+	// Get the registered rockets
+	// (GET /v1/registrations)
+	ListRegistrations(ctx context.Context, request ListRegistrationsRequestObject) (ListRegistrationsResponseObject, error)
+	// Get a list of all rockets and their current states
+	// (GET /v1/rockets)
+	ListRockets(ctx context.Context, request ListRocketsRequestObject) (ListRocketsResponseObject, error)
+	// Get the current states of several rockets
+	// (POST /v1/rockets/batch-get)
+	BatchGetRockets(ctx context.Context, request BatchGetRocketsRequestObject) (BatchGetRocketsResponseObject, error)
+	// Get the current state of a rocket by its name
+	// (GET /v1/rockets/by-name/{name})
+	GetRocketByName(ctx context.Context, request GetRocketByNameRequestObject) (GetRocketByNameResponseObject, error)
+	// Get the current state of a specific rocket
+	// (GET /v1/rockets/{id})
+	GetRocketState(ctx context.Context, request GetRocketStateRequestObject) (GetRocketStateResponseObject, error)
+	// Register a rocket before its telemetry starts
+	// (PUT /v1/rockets/{id})
+	RegisterRocket(ctx context.Context, request RegisterRocketRequestObject) (RegisterRocketResponseObject, error)
+	// Get the processing statistics of a rocket
+	// (GET /v1/rockets/{id}/processing-stats)
+	GetRocketProcessingStats(ctx context.Context, request GetRocketProcessingStatsRequestObject) (GetRocketProcessingStatsResponseObject, error)
+	// Get the speed history of a rocket
+	// (GET /v1/rockets/{id}/speed-history)
+	GetRocketSpeedHistory(ctx context.Context, request GetRocketSpeedHistoryRequestObject) (GetRocketSpeedHistoryResponseObject, error)
+	// Get per-producer usage
+	// (GET /v1/usage)
+	GetUsage(ctx context.Context, request GetUsageRequestObject) (GetUsageResponseObject, error)
+	// Get the build of the running instance
+	// (GET /v1/version)
+	GetVersion(ctx context.Context, request GetVersionRequestObject) (GetVersionResponseObject, error)
+	// Get the watchlists
+	// (GET /v1/watchlists)
+	ListWatchlists(ctx context.Context, request ListWatchlistsRequestObject) (ListWatchlistsResponseObject, error)
+	// Get the current states of the rockets of a watchlist
+	// (GET /v1/watchlists/{name})
+	GetWatchlist(ctx context.Context, request GetWatchlistRequestObject) (GetWatchlistResponseObject, error)
+}
+
+type StrictHandlerFunc = strictecho.StrictEchoHandlerFunc
+type StrictMiddlewareFunc = strictecho.StrictEchoMiddlewareFunc
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+}
+
+// IngestMessage operation middleware
+func (sh *strictHandler) IngestMessage(ctx echo.Context, params IngestMessageParams) error {
+	var request IngestMessageRequestObject
+
+	request.Params = params
+
+	var body IngestMessageJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return err
+	}
+	request.Body = &body
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.IngestMessage(ctx.Request().Context(), request.(IngestMessageRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "IngestMessage")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(IngestMessageResponseObject); ok {
+		return validResponse.VisitIngestMessageResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// BackfillHistory operation middleware
+func (sh *strictHandler) BackfillHistory(ctx echo.Context) error {
+	var request BackfillHistoryRequestObject
+
+	var body BackfillHistoryJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return err
+	}
+	request.Body = &body
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.BackfillHistory(ctx.Request().Context(), request.(BackfillHistoryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "BackfillHistory")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(BackfillHistoryResponseObject); ok {
+		return validResponse.VisitBackfillHistoryResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetCapabilities operation middleware
+func (sh *strictHandler) GetCapabilities(ctx echo.Context) error {
+	var request GetCapabilitiesRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetCapabilities(ctx.Request().Context(), request.(GetCapabilitiesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetCapabilities")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetCapabilitiesResponseObject); ok {
+		return validResponse.VisitGetCapabilitiesResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListFleets operation middleware
+func (sh *strictHandler) ListFleets(ctx echo.Context) error {
+	var request ListFleetsRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListFleets(ctx.Request().Context(), request.(ListFleetsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListFleets")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListFleetsResponseObject); ok {
+		return validResponse.VisitListFleetsResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetFleet operation middleware
+func (sh *strictHandler) GetFleet(ctx echo.Context, name string) error {
+	var request GetFleetRequestObject
+
+	request.Name = name
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetFleet(ctx.Request().Context(), request.(GetFleetRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetFleet")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetFleetResponseObject); ok {
+		return validResponse.VisitGetFleetResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetMissionReport operation middleware
+func (sh *strictHandler) GetMissionReport(ctx echo.Context, name string, params GetMissionReportParams) error {
+	var request GetMissionReportRequestObject
+
+	request.Name = name
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetMissionReport(ctx.Request().Context(), request.(GetMissionReportRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetMissionReport")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetMissionReportResponseObject); ok {
+		return validResponse.VisitGetMissionReportResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListRegistrations operation middleware
+func (sh *strictHandler) ListRegistrations(ctx echo.Context) error {
+	var request ListRegistrationsRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListRegistrations(ctx.Request().Context(), request.(ListRegistrationsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListRegistrations")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListRegistrationsResponseObject); ok {
+		return validResponse.VisitListRegistrationsResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListRockets operation middleware
+func (sh *strictHandler) ListRockets(ctx echo.Context, params ListRocketsParams) error {
+	var request ListRocketsRequestObject
+
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListRockets(ctx.Request().Context(), request.(ListRocketsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListRockets")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListRocketsResponseObject); ok {
+		return validResponse.VisitListRocketsResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// BatchGetRockets operation middleware
+func (sh *strictHandler) BatchGetRockets(ctx echo.Context) error {
+	var request BatchGetRocketsRequestObject
+
+	var body BatchGetRocketsJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return err
+	}
+	request.Body = &body
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.BatchGetRockets(ctx.Request().Context(), request.(BatchGetRocketsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "BatchGetRockets")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(BatchGetRocketsResponseObject); ok {
+		return validResponse.VisitBatchGetRocketsResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetRocketByName operation middleware
+func (sh *strictHandler) GetRocketByName(ctx echo.Context, name string) error {
+	var request GetRocketByNameRequestObject
+
+	request.Name = name
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetRocketByName(ctx.Request().Context(), request.(GetRocketByNameRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetRocketByName")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetRocketByNameResponseObject); ok {
+		return validResponse.VisitGetRocketByNameResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetRocketState operation middleware
+func (sh *strictHandler) GetRocketState(ctx echo.Context, id openapi_types.UUID) error {
+	var request GetRocketStateRequestObject
+
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetRocketState(ctx.Request().Context(), request.(GetRocketStateRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetRocketState")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetRocketStateResponseObject); ok {
+		return validResponse.VisitGetRocketStateResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// RegisterRocket operation middleware
+func (sh *strictHandler) RegisterRocket(ctx echo.Context, id openapi_types.UUID) error {
+	var request RegisterRocketRequestObject
+
+	request.Id = id
+
+	var body RegisterRocketJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return err
+	}
+	request.Body = &body
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.RegisterRocket(ctx.Request().Context(), request.(RegisterRocketRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RegisterRocket")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(RegisterRocketResponseObject); ok {
+		return validResponse.VisitRegisterRocketResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetRocketProcessingStats operation middleware
+func (sh *strictHandler) GetRocketProcessingStats(ctx echo.Context, id openapi_types.UUID) error {
+	var request GetRocketProcessingStatsRequestObject
+
+	request.Id = id
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetRocketProcessingStats(ctx.Request().Context(), request.(GetRocketProcessingStatsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetRocketProcessingStats")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetRocketProcessingStatsResponseObject); ok {
+		return validResponse.VisitGetRocketProcessingStatsResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetRocketSpeedHistory operation middleware
+func (sh *strictHandler) GetRocketSpeedHistory(ctx echo.Context, id openapi_types.UUID, params GetRocketSpeedHistoryParams) error {
+	var request GetRocketSpeedHistoryRequestObject
+
+	request.Id = id
+	request.Params = params
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetRocketSpeedHistory(ctx.Request().Context(), request.(GetRocketSpeedHistoryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetRocketSpeedHistory")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetRocketSpeedHistoryResponseObject); ok {
+		return validResponse.VisitGetRocketSpeedHistoryResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetUsage operation middleware
+func (sh *strictHandler) GetUsage(ctx echo.Context) error {
+	var request GetUsageRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetUsage(ctx.Request().Context(), request.(GetUsageRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetUsage")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetUsageResponseObject); ok {
+		return validResponse.VisitGetUsageResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetVersion operation middleware
+func (sh *strictHandler) GetVersion(ctx echo.Context) error {
+	var request GetVersionRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetVersion(ctx.Request().Context(), request.(GetVersionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetVersion")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetVersionResponseObject); ok {
+		return validResponse.VisitGetVersionResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListWatchlists operation middleware
+func (sh *strictHandler) ListWatchlists(ctx echo.Context) error {
+	var request ListWatchlistsRequestObject
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListWatchlists(ctx.Request().Context(), request.(ListWatchlistsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListWatchlists")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(ListWatchlistsResponseObject); ok {
+		return validResponse.VisitListWatchlistsResponse(ctx.Response())
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetWatchlist operation middleware
+func (sh *strictHandler) GetWatchlist(ctx echo.Context, name string) error {
+	var request GetWatchlistRequestObject
+
+	request.Name = name
+
+	handler := func(ctx echo.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetWatchlist(ctx.Request().Context(), request.(GetWatchlistRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetWatchlist")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return err
+	} else if validResponse, ok := response.(GetWatchlistResponseObject); ok {
+		return validResponse.VisitGetWatchlistResponse(ctx.Response())
 	} else if response != nil {
 		return fmt.Errorf("unexpected response type: %T", response)
 	}
@@ -472,37 +2625,172 @@ func (sh *strictHandler) GetRocketState(ctx echo.Context, id openapi_types.UUID)
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xYbW/bOBL+KwTvgKQ4W5bjtHX8LW3cjXF5g+0cDtgG2Yk0trknkSpJOesr8t8PQ71Y",
-	"kuXExe0u7sMBBRpTFGc48zzzzOg7D1ScKInSGj76zk2wwhjcn2OtlZ6iSZQ0SAuJVglqK9A9DlToVkM0",
-	"gRaJFUryET9nqRTfUmRIbzPa5PEOx98gTiLkIz69/fz38fzx5nb++OX2/uaCd7jdJPTEWC3kkr90eIzG",
-	"wLL19FUag+xqhBCeosJKvr9hSAX/QsuehV2xyQU7gqeg2z8ZHDGpLFuoVIberu2XDtf4LRUaQz76Obvj",
-	"1p+Hcr96+hUDS75e7/N1vkJmEgzEQgSFhyyBTaQg7LAQLepYSAzZ04b9EqOFECx4+cb5JsFfvK+SdxpR",
-	"f9q0RCVWqaQ7aTKIIQtWIJfIjmklC8OM1icy0AgGw94F5n+9q4Zs4Pt+hy+UjsHyERfSfjjdhkhIi0vU",
-	"dOcIUhms3Jm73ly5h7knFRey9YbJ9wdajIUx7vimtevsAZMQ45vW+Pl0Pr6ezNowJ/H5ep+RG3xm8R5D",
-	"+UufXcgb5maX9/P51fjxejJtM0kpaDM3desun/hbEilnt2JyTIth09jddDyb3U/Hj/8Yz2bjq8cv55Or",
-	"++m4zXC2sGM2Iww9fDuSXyAKlOyetVJoH0muc5S3FJMVSInRrlP3WTURIUorFgK1C4tdIdOZu8cQGcWE",
-	"NWxy8a5eAfpng5OPPpx1g7Ng0T31T6E7XAwH3eFgiB/74Rngh4+8Ar80FeEr5egmjZ9Q77p4q0PUTC2c",
-	"VwXRqewI6fzK7+axS7FcoWbCMInPqGvO9g+jQV4dRNxWcESMxkKcsOcVyro3YJhBadnxZHbLhh/8Psus",
-	"NSJ24p+cdH36N++fjQZnI/+9N/wwGHz8m98f+X41WCFY7FpyZH/E5q04o1UKF67Jo+zZU1YHKz47x2Qa",
-	"UxmuA5F3eFtRqy+XFa5cLlhTLtSZS8W9KR4Vi2/oRA7fJlbqGavHpU1M8gtYsHsEJUi1pqjBcqlxCRZD",
-	"Zmg3xRNySng7opG/tadgf87PzCp2juOcXUKymJTKsAQ1MxgoGbLjuGfqwBkeqhwiPITix3k839W9+WPY",
-	"HYGxd1oFaAyG169TnVKwIhYbW3JLur0sKY7IC5QwbV73Twan7w/UWGPvE2LZW2zPY0QvVLwo3LMrsCx1",
-	"B4WZWw4wfxDv9yloFbuFkoIxYkkNkFV7k/yKYO9Tz8kiU80Qw0527lZO6Wcpqd6B8inTKKJmk4+sTrHF",
-	"EwpoatovTTQE+g0Ry/a1YDovc1fn9zefL8fUEo//eXd1ezG+qFelyoYDNZ1csHm9rYomekuvwwoR77CZ",
-	"2qT/bojBqxJfrX5bXnXqpWYLiDJIO8B+lYBtNXKOEcZo9WZv5/0JDLJskHFZB7lhtnirJIbGAMWa+KpV",
-	"/ErxrAwjf9W44CP+l952ZurlA1Ov8Map37bNOeCVsitqxrU857UJhF4ScqFaxoK7ibt+rKSwijLnIFDq",
-	"RXZhw55IJpmSTMhAxW5bM1jG+yovQYYRGqZS21WLrnJdD8iQge1GCMZ2lQy2LUeIkVij3hC7YxDSgpAM",
-	"JIMgSDVY/CqLepA55LpdCFZFHtzsY4WtznJOGNkM9VoEyM7vJrzD16izksP7nu/5FH+VoIRE8BEfeL43",
-	"oIyCXblk9ooLuRZUGUv/lxydhFQ/5BKNLdKZZQSN/aTCTTb2SovSvQdJEonAvdn7Na9FWW7fyvwOhl0a",
-	"29vxZi48RqSGwKYQFfMkM1angU01kmIf5TuP2EJgFLIQE5ShoRQftQ2aRx6vAo+qnENiNvm7UJ34Jz90",
-	"+TqFtgVyW13ymxMeMLF7eqwWrDfGv8YpDka5CAq59AgOp77/uyWu/k2kxaGJXEMktvKb6SZzn0KcfefS",
-	"+z/XJYva6Q/qNer8m0mYuopQfpioxow0LY1j0JuSDwxoaCn0YweUlD1YGqpa1wXDHuic3rrfywsNXWSJ",
-	"LYy7EsZO8z3EVQ1Zz8lHPzdr2hcHaKuYUdrSxJArmSCt3yTYybrYTtFkdFhdbmiIFXTQtxT1hsQdYoc5",
-	"pe0n+r2NeaHKVXUzO7LWULOHFhQ37zAj17PyeQwmIGzQhtdccyNmzbsQF5BG1JCBCSo9RPaLzmvz5WGH",
-	"1j+GQ2ExNm8BsjrEbDkMWsOmDZ7nLBLGVgTp/6TdR9omNX9C4mURPoiiUtNJl+0KhWY1lTUVnhaMa9K0",
-	"912EL3u5+hPaan7foCspVfoj450jAKn1Fv+Of3V1qtH0dx4I/2uOHEyN3aRXp6SyTasGyBHj9M9DYd6D",
-	"VL6Y/8/ywLbFDrZf4LMQtuOfDnPHZxhOdcRHfGVtMur1IhVAtFLGjob+0O9BInrrPieU5AftfA4s+GKY",
-	"xigbu1UhnJlnMUhYYkzRK2FeuLOrF5UDqbsRTo9Ju/eJsdmeWorxy8PLfwIAAP//ggxbnOsZAAA=",
+	"H4sIAAAAAAAC/+y9bXPctrIg/FdQfG5VpOdy5JEsJ7ZO7QfFlmPtlWyvJJ+cPUdZDUT2zOCaAzAAKHly",
+	"yv99C40XgiRmNIpt2ZtyVapikRyg0ehu9Dv+nRViUQsOXKvs4N+ZKuawoPjPn2nxfsqq6gx+b0Bp86gE",
+	"VUhWayZ4dpC9YkoLyQpakQUoRWegiJgSSqQo3oPeyfKslqIGqRngiMWccg7VcKSLObgfET2HdrRrqASf",
+	"ES1yAjcgl/4NWTRKE1qWEpQiDKeCD3RRV5AdZLvPHu/9NKbPRsWzYjraH+/T0dPp08ejp4+fwk+75TMK",
+	"P/6U5dlUyAXV2UHWNKzM8kwva/NrpSXjs+xjnnkwDLxMwwL/8R8SptlB9v89avH2yCHt0QVUsAAtl6f2",
+	"lzgI/XBsf7s7Ho/HebZg3D8Ic1Ip6TL7+DHPJPzeMAlldvCvgK4Ikt/CT8T1f0OhzQztPqmmSmzTr3Oq",
+	"yQLkjBlUzoHME/tWsnK4X2VTV6ygGtRw1FP/S/We1TWURDFegBmfScKbxTVIRagEQisJtFwSxqPJl0RI",
+	"IqEGqqH0r66pLuadrRyH5TKuYQbSboucQbkGIlqWUBIt4vk6wz5ODSuhrugyNfDRjdllIsFseWMAnkqx",
+	"wNGByoqBsviFMtCn4LmjZHwsOCjCeFE1JZQdUJ6kQFGaariL2M6QX87x0z7l2AECqvJ4J6OVJqmpYVV5",
+	"zKdiiAd8ZTjcrEw2nBuCYlxpygsYUs+1+fyCLSBFkuC2nHEql+SWKmI+1zmh1wq4JmxKGv6ei1ve5e29",
+	"8d7eaGz+u9h9dvD42cH4yT9jXi6phpE2kyYYuhCLBUtwyN+fnxMJN0wxkQYLN/xO2J6U4z348Xq3GE/3",
+	"6VN4Vv50vVc8pvvTJ/Bj+VPx9PoZHcPudC8F2kz8HaRCcPrQ/SKIFqIq5pStgO6W6S7fZDOxu7O3vzNO",
+	"TXWzaqIzqIAqIO6DnJRwQ6ZCmv9DJeqFWTzuquoJ3J3kVD2i9PPGi01R4HNa02tWsSCFOkC+BKobCYoA",
+	"p9cV8hYixdMhobzEBxVbMG0OBwJ8KmQBakiitNFz4Bo5ozwDWqoUVmhpCN2eT4q4JSlCOTl8e0zeQ1e4",
+	"TGmlICzrWogKKDfrQvF2zGfJs/RUSCM8KSeChwOQFJSTa7M28yMoSQ0S5welN5uzEFwD10e8EGYRieU9",
+	"t18Q8J9Y7raTkGtRMlBkQZcGDiOFJCgF5ZDk/pXN/mB1lmclTCuqwWxtODQHNNg99/Js6nZ1CN8b/Aet",
+	"yPSOjT8gnC5A5WRaAWiVeySeUW2eqhqgfOUOH8rLS75gytDgGdRCarVzybvLwdGMaMHh7rccu2EvUSit",
+	"wbn5mfICNWyyP5N76KW1FeFM8Ef/rQS/H0QL+uFnQ3/n7A9IUZ/SYV5CiwJqT24WrCTV7SZPZ/rhZ1Eu",
+	"f14mtYYTKuPhDH0t2/mE4d8SWjLrTjfef/rkpx8jYc+4/nE/S0FRN9cVKxKYp1UFUiH5ikZHPEyMlkIk",
+	"lLQwoHhuN9KESgMVZ1CSBtnSPHTUQ6Qjn43YUVINJyiX7jzd2y+tbiLkanpyL/owefEhgZcgUc/KCSxq",
+	"vSS3c2g/w/UxhVzVI7q5XlRZntXl9H7kprQEujAvB8CixkKMbjuDAKL93mptRcUMKnIi9BzkLVOoVS5J",
+	"LarKCie7N5ugfKAXebC64rjHHz0q7vNzQqz2tyiSaHnqlAkU2qGJ1Gn4Qi7PGr5Wt6+lKEApc0jRcHTc",
+	"iqYqSSnSVtgsebQyqEoVW2BuFPuLnSyigHW0i+M8x9+kaKNopASu76Xf5hmHD/f9iWh0IVLqJ4pSKImQ",
+	"5NoZT1CSLUrMwUUq2vBibi3ZWgqrFtKKoFK9bVlnFY7wBX6YX/KgdKMSZY72Cudsn7shcnLdTKfIojg6",
+	"04QpQudGIiEYM1oHvUaCkCVI9xPzoeD5JWczLsIA5jtnPZoPfm+opFwzDqU75nizCGcKGggtHmJrwbxw",
+	"kJnNt1Nkv0V8Fw0xEAqNETvTStymDf4epUIhUftEDNYAJVr/t+QPkGKnHX4Va/vNzgN9xwCkOOtISiGf",
+	"izJBIIdkQYs54zAyp4IRjATM16QQJeyQ51ZCEXXLtKEUh3BRQt6hDKbIAijXuP/zZkG5PVCMOtXylN+M",
+	"ohIcrtiiFkqx6wrijbhyQ3p95Ao+MGXljJDXrCzBbKuzda+8MI8eadlwlD8ozTRITqsrXBM+uKEVK6/o",
+	"bCZhhipG9BQFZfy3KJfRnwh2/Lfny+gZcDQg4s+mrNIgOw8+xH+ZVUZ/szL+gxes7M5QGRMl+ntBzSq5",
+	"UQyvbhkvxW38EuQMOn975IYnjF91nxh1MPqzlmDYghfxQwkzprTsI1DrGLJbg86KqRj6ACFC1qUBM/GV",
+	"pu9xh7nQV1PR8NL9uwJaIhbFLQd51XB6Q1lF7S9ruqwELa+0EFcVtUv+vRGaXsGHAqBEYohhvioEn1as",
+	"MKDZQ7alMymqygiJLnDK6HEGxzNaZ3lmDG/RmJ+bOReUG8KjhWNGcw4Kyf7AeZ0F3f7ryql17QO3LVfI",
+	"ue1jJaS+ul72nyxEyaYMZP85Skt8qJranNFg6NEe3Fmehe1ol3orBZ9d1VRqpp2R2sq7eAe6Ei/PPowM",
+	"L49uqLTGw8G/WiHz3LDJcYy78OqF5/LTQIfh3UvDB0cesvZxxPXhobNuXrTc3391EUmB8O7YiYMjJw2i",
+	"F0idhx2p0H/7s5MOg+dWSvQfP3fSYvA8khr9d0et9Oi/eumlyPDFh9RTJ1X6z4/L1MNWyvRfnThp039+",
+	"2kqdXz1LD75xwmf4fLD5/g3jh8kXr+ki9fhtLJv6L8+6Mqr/+kKn1vVrJLMG7wYLxRWmSd1AfOFkWftQ",
+	"6JeOo+JnJ162hYdvjJB715Fx4d1bK+wuhDihPQT/LyPzjlqRF17EuHjeir72PcrAIfedOVmYXuO5k4m/",
+	"0Dp+fBFkY/tIiFPKlxdeRIYX77qyMnruhWb/0UsvPPsvHFldLGtIvD0XUv+8XPHitJWoqddvZNl/FyTs",
+	"UStgw+tAREOE/mok7ttW4Hrt7AxULbhCDa1nxDi9bZ05EIY32l4JmrIVoSch2YzxNhySE8bJEZ9VTM1z",
+	"q1FHKp2WlKvKBS5clKGifNaY986Zc4gujdGJfzxHWu56TV3Aa5NoFeHCKJGpUwe9TVZQHSdiF8fmBe5i",
+	"62eyX2M8Q5ZQeuuk4fChBvR+KJA3IK3OmxMtjIluNAdvX1hjF93/vdjb/nS32KPPYLR3/VM52i+ePBkZ",
+	"r/dofP1jsTv9qdyD3d01sbaUKo6Kc18Rd993J7f8is4dcvyC/ECvi9Hu3uMfWvTt3OmlRsJq4UkaDh/q",
+	"SljXIVUpR/qv82XkqCBgvi+htMaBxR2UROKvSVFRpcwOOXKixJzQM+emJJQoE/pkekmu7ai3QpbK2YgL",
+	"WhqaSxj5boyUC6xRgU7BL+WAvD178/bdyfnxm9dkC/iMcVA5wUGrinKttnNyfnH27vnFu7PDE7KlKX+P",
+	"H4BSjQRyA0pBpXJCmZxKuoDtnPzy7vjF4evnR2Rr1rDSnIo54fSGWWUiJ4XgWooqJ0pM9S2V5jdHJ0fP",
+	"L86Onx+e5OTi1dHZqfnH2eHrX46uzg9fHl38b7I1rdhsrokGuWCcWseh8U0vKC+3c/Lr0aH5IRGSvMF/",
+	"IMly9KsbEQRlbHW1y87yrF1glmceeiOoAlBZnjmosjyLwcryzE2c5RnO29UbO0MPyN/vcWK7zo4vzMSt",
+	"YX8Dc1ZUgPGfSiht+BPZ16GzMixNWdVIyMnp4f98cxa8CuYnJSgtxRJKguZxuwmwM9vxNNZDMROcqKXS",
+	"sIhR50HL8gyn6S44ejtYrnbOnEQWgGUKqlo+cSDVUpRN0Reib8+Ozs/fnR1d/f3o/Pzo5Orl4fHJu7Oj",
+	"u9nc80eEewdXiuVjl1babxbiopbnVeTlxCXQWGR1eXVqfm/+0S7L+cjOa0i7Vkw8MhHHpFUD5BqmNpgU",
+	"+ZfiyKWCrsx+Mh6bLRGrxqNTDXLz4Z6Ox31k2wUm8YoKeULoc/QGz6RoaoNZ74431uR7KA19GH5uOEuk",
+	"mXTGirH6klaF4OQZmTKpcI9moMjPu+MPH1JINjB0B5jiAKNrIZQGqVI/cpAmGNn641SXUMKf6PCwJwRm",
+	"C4hGK1babVSFqFvVwsUrKphq81XHLXtnTsvQUU/vDkbgLp3jl/2tdW4Rv2o/4Mq9PtfJCIa3McMqzTCQ",
+	"whW1mBruOr0BSWdgWWY4gX1LHGM5D6Mb3lOVn4ZxsnjUjS/sYe7OBpEnf9h3CCcZKauo0u/qkmpIJ0mc",
+	"GAxo0uAncWJVj2QcM7anHAt8srNxaoR1em8C9oJ+CDhuM2o2RY8LUSVI4AVTmvFC+yiWWrE7OVF4LnTo",
+	"/k46x1hS4sjp7TyqjpTj10iDjXIH49vDs4vjw5O705NWCoCzBMPfnZbkVr/ZcESbeNCc3gBBmqBJPXlv",
+	"OE+Pq1t29tNHFBLRuMdr3uW+iEiiHU/JBBt/u390K52uxlQtFNPJ5JbPG/PxB/uXDffMoQrqjwv7XHL7",
+	"25x8gwGflZlFLt0nqSMl1As9bwW14JAIuVGFNp2Dpc9FG8ihWyp5OiHmtdBoZ+q5y4ah2pAbzqe0qLug",
+	"omyg7jgJMaxu5GpTKdVjwpic8yh7KoCeYqjTVXb0hQ2rFWzKioBHFyHISQlW1be66mQBmpZU051F6z2a",
+	"WErq5fglLJbDhWhczMuixYVGt8wTF6I1z4+5xVb56IXDW7md5XeeKQv6gS2ahcundRm19sk4fcwa1l6h",
+	"FpzgSwdnBOCJE3bbPUX5M8Dj5GEiCce+QNU3CUtOuJm8Ml5BogVp6hokKaiCGMrs8Ozi6PT43IJ2Anym",
+	"59nBj/t5VlOtQZqp/s+/Dkf/pKM/xqNnZOdq9Nt//kdS/4Xb01XAvoZbslgBsPuRNZc2Bvv81buLi5Oj",
+	"q9Pjs08HXa7wzFiPDdJmcH3EoB+5k237zxmZ/kH6rMZ8rzupzFkpo2efioWPq8XDqePvhF91VYr+O85+",
+	"b4Cw1p9o1hFJ8y1aKUGYVuT4xfYXTch/jdnliVRBzItwZ0w4KpgR5AiXW9sOecVmc5s6weEW5A55s2A6",
+	"4WpQRAHH5M+QG+cTx3yG+9bZm+f/dXRxfvXu9et3pz8fnR29uHr+6vD166OT85wkXr49e/Pi3fOjs/Nt",
+	"41kWKqp2oBIIHvIWEi972cJmnNkprbeLSsluaNVPx7vr4DMhypkYmacjk7c/Ei67clQL843MDrRsoEV1",
+	"2jIxT5Wmizp9MqPquXV8/oY8/XG8SyxQ23fmce88/fHx45/+c7x7MB5vbLdEB1QCzqU1m8Ek8BP77rrd",
+	"Zq8bO258BVTqa6CaFAa9oAgX/oTEDRC8WpL3ALXq6Fu0YjeQE1reUF4YYukadvhT8+itVWShPI3JOHao",
+	"daVClrsH3dOy+/gF9B8ftcp5ShyHx2G5XfVuAMMdfrR+jcqF3ah4X9boKWd3FJeYk2LBeKOhoziiYmgi",
+	"FKpiyJ82gULlpDC6R6QVL8ktSIgVxb7vbaoB+CnOsSEkOLON+ihNdp+4512HwbOdpzENi8ZGCR0iLC+j",
+	"J4/dwCfMnp58d7yzv9HsgrvJ/8zc9mF34r0Npu2RUAtDFxt5f3NSdPQ2WIcrPEt9G9Ko8mjldwrN0Ozy",
+	"DB0qmdqiAqWp1Cn62ahECk2VqY5xV3thQASH/BONGW+nrYZAQgHsBvmCVdARX7e0ay/2HBwbTF/ZpG4T",
+	"774jsxxjcFZv5DPvqKbuODOPBB+4KjYCwPgQDEk4XXVtkmj40KaIvpnaQPZq5KEJiCBCGZvpfh21hBLM",
+	"bopucOKnjYD3W7MGADGNd8zoDVSboMUqul70eHJD35xEzKQgcake6dKEDfSOgX/Jr7lXmRZtR4esItg6",
+	"XomUOEDN7d2KYHLhzwct6dRYwt7jg78iZSOtn8mgtALy7uI5KekyUdu21DatJOHHpKwysVoNCmPmtBsu",
+	"wbKkfhGgtRw326XrdGUFpqq3fO5WYsjGLaCVKftPNp2rpHqFKyHCTEqp6+tva1S3tWgMkmNDTO5ujse4",
+	"uvcuqbkGmxtzlyeyhC1t7GfH4/6rTqjHiGimVIP2c6/QTxqROlIag7Sj3TsVtgBG7jcmIMLTVoqxzjqV",
+	"K6mtwh1y2rZchoXkhNpSPPRxRqGKzgb2DlUz4M/r6FxMg8XhStQiLjbp1a5sqQaZJFVXS7QxG7DK13Sv",
+	"o5f7wrAhraYM+U4a3yp/g83tBRkfW0wron2d+hD19JYyk9gTStlTDtKA+64/15Cpd7Mve7ENY1amKqJY",
+	"L/D92VwFreMq5RnTQ4eRR9WhXlOvHK20/YU9dxGxy9rae276z1i77B1LST/RepZvMdTipbfiPLHxSTEQ",
+	"a1lrdYSOSj2ksztcOfZ5oC93+CejWft7Gyo363O1YgeGn6+7fay7wAMSeZaNcPMoJ0MHX3JD11fGr4Jn",
+	"E2rp7X8X1wEVDoTkNiP0mNq9svuHjz9qQSoh3pOmJlRjCedwt1mpNvAoKrLl6EVt9yL/5hRp20Ugus2k",
+	"UJpphS/7plzdgt8BewyJwgaSCuhVNm4kaH67T2pFp9PHffp8GOys3oUTId43CcvqjS14MogyqDD6CeIC",
+	"oiwB2vbU6G/IqvQr3G1E8pfx4d6/t4X9TZOgIGtOBmseh+4STk5CvYZPkFhzeAWjPCehrOqS3/aEP7s7",
+	"OacbdI0Ldvy/w/hd79s9Kkti6jhHBPkk+uGbKMM+9bMASvLkcNhfTaHrdZGL3pnofCBtvN2hFVv62KS/",
+	"xHGxSdCsE2iq2HvoRKhipbFrwK6OmN3vFF7/4x5qe+fxauye65VGmI+R+7o9KFseWN2FiXKxoNUyPSQ6",
+	"p1ZGs/OW2ewoolHu61BrSWpRsaJnGdpv9sbjMCp64R+Px4QG/xB5kmzaEqdCDrPqUvlcnlE5WYAGaV2Y",
+	"CgrBS7K1eKS2+wmL90jt6uZ9ry0/6H0etN27Amv+FOwdgl9GHq+OTKQJZG5iZ61Pz0WlIn+mDQsylYJ6",
+	"d+/x/pONXXrrkuPaEJTDUc+r6sFD351NoSstWMghXzQYFYIa66ijEwBZlxYQM7r7iFCl2Iy3Ta5SBLLG",
+	"2PGJrb12blhnMZUMeFktbVQ/PZEPoBmpQrWQKuTBmBobRxJdaF6bQoz9+4Tqj6eDwokQu++ULsTJ4jvk",
+	"HKJXUbgfA8Cu1MJ2Udk0kZw3la026xqxXZWmUemdsyhqk8maXk5rSCxsQ6nubPQUPPe5Ts4BvQQdegFY",
+	"aT8XVanQKYzJbLL1mmlRlWRrkNBGFqKE7TjyeHL47vXzV0cvsjw7+sfbkzcv8J8OtK6KEn26YfaDwYNe",
+	"1n2Na8vQTE78AZqTc7Fs/ujFif+UkdvLnm9tXrdTA/GyVgymjmYc+twBOehw4s+ioNtQTSjBELs5lIz4",
+	"GB7LKn3A2cESGckbH1t6pQDt5UrkxLEWRrv8y6gpgAuz/kkz1H1k15lC6qB94tD5RxUQK0FdkuWydWYF",
+	"jgluW2zTt1oRimrONpDUVsC3eTMb/CSk2QwtcvdifaFZW3a7skLCvI0LJKaiqsQtCuwZ6DnI4bJXlkeE",
+	"jGIrh2paKqJFSZebFUdYuTVa8f196yJy3xXSdjvBUD6VQNTc9d37szUP6wsY1m4DquKp6gUSugi0CnKo",
+	"NAiVDOYIinoXfdO7Yg3EOzbFvQyL37hDUMez8ck79BFrYFMdK405buTEQnCmRYjhBCPJc801tZF4wngh",
+	"FvhZX6qYBnGvKC8rW5szEtORRQI6nvSoAqr0CF1RIUkdTGaQXBItCPYjoYxjtNv4pKiGS96hEYQUaDH3",
+	"AuvS+uh0RAYESZCcg7xhBbobotRg04hxvDM2CBU1cFqz7CB7vDPeeZxh7uAc9+VRHPiqhRUuQU0xNcQu",
+	"K79tSFBTSa0llR38a+CHMllR6BmlulN1hpgp5lC8J8zYqJRxpYes0XfYSNANJjijRnPJoxg3065ZkMso",
+	"9sl4tUGAwnpkypeYuL1DTszGtpl8psZvujQJfyGnywoOdckVnUK1DCDaH6GKeGn7uGQH2e8NYLWg5bCs",
+	"xLZcWe4aJlvSm1IsY1jZi6yPusncEBTjs/9RAWfA9cT1wlNYO2DwNygecDUqk73x3iTogZPw2YREaeOX",
+	"3KzH5yugc9TgaLI/fjaJljb3jRbc2mz/iM7a+if7b5ZFQWns84EF+dgSzfxz0Kkw9JW+fwPljx/zHtIc",
+	"IwwYdIcYPZMWuqFV2GKlZVPoRoIRXD+4L38gWJBISqiBlyhZf0hlvP+wc8nNmNikMN0Ck0xcL8eR73hw",
+	"QEwfzAkRkkxcK8xJW1vb0iOWLHOlJcWs+4rx964HZSv7XC6mdF0QkGP3xuPPhu5Ob7kEql/IJZENt6ps",
+	"J/iBftU5MilVvhpmx0ievfHeZ4OvUx2UgO/UCxrXQHKHhO3SUFXOJkolyTCNwO5/RmR2G1YkoO0HyKz4",
+	"IcKWYAP3IO0+HEinLoNJSN/ROOQcbGG2Kxql7pFNUDYtotiskVBuO3gfPxy8UZ94Z6rETmWPWIU1nBt4",
+	"6S38zx4W/qiTiCsQo62UP0Al0fnUWDJz0P++73HzmLnkW5NB37jJ9g553eYY/o1MrJA/IKuPIKbjo8ad",
+	"JDvkV6fgXhrhxQpNSiib2nl9c4JVAG2hXsg7cIXNJPQY4fBBk61J3EFsst3JhCQN16yyc/lcu06yvDJM",
+	"c4me1f3dByZDI2XyYcfanNjeai5FPDALcWVCRLE/gGxNBr3ZJo6ddp88/DoMpfUPNuwoEfr5DM+5Cdma",
+	"pFqq+XXs7T7sOnw6q70yAsPSlIQebkTcuto2X9wrAcFxNRjhKgnLcu2+5Y78omi8v9BgKuQtleiedAwY",
+	"p9QavkIv0BRBGczq03DdyJe8E4AkW5NeF7oBByvgZTyCd9GalUrPFXsPLNxC/hN8mNPGBpK1ImWbIWbP",
+	"6Mk/Rpj3N/r/J643kgr1GbgK/PaSGwV3cmbUvNGhEYOTcLDbulkJCmzv7o959uRhT3PbNa/TK8knCvZk",
+	"tLFHLIB7D3xWOnITt9zbvp5GC8p/0LY7NC3m1sPvKLrTAV4LsjUZdJdELjeu72axoHIZbEZC0cKSK3T0",
+	"LM80nWH4OqTTYdD50c3uI1+RHNulPV9oc73o5K9Zu4Zg58a2R78N+tjrEVydNVQKbucgoVXFKbG5k8Tl",
+	"Tvq9o/ySi0ajRfGOYxh5EmzmSd6tG3BWQSjNinqvDNP8D1ovlrsXhHEtLrn52pYo+ctRbA1uqKeZ5D2P",
+	"P7vvbSQ7l74RnHISb4MrWswrd7uL06yNo8dM7s19bMSCMrSyZdv8kru5GSfFvOHv0YX0ZDyOLvVZogc8",
+	"JxLvm/CE6UNLoG/BRkIWxBUUGjdKtOlWSF9yLI9v6h1y5q9kGGDyb4HmZ+CFO3beVvM2qGVcV8BD/tEM",
+	"iLEESUFrjT2UTGequWgUbOeXliAo8e1QrcnWdZ/4C3lc083sy9jK/euZPn78+JC2Y+/WofXKrrJVWI40",
+	"urTcEvJXN8lyq43HGYzu3PZSc2vS6xlsVdZ2nehs9Fd5YIbvJW9/hAw02fan83dTb1NTj7ah7z9r5u1/",
+	"FdidzPE9bLYmIQlssv117M/uScNUuPqBbE36DcQNdXdYopZQYjgDNc2qBKXdgO+h1u4AueR+9F7AdzLo",
+	"Rj7Z/qvZb38tu8drgV/M7lGXPD4aUmbPt2vKfBtGx1/etMB+0vGthZEqyLvX/MVZF2uNjaJ3ydgMVjSk",
+	"TF87xdQmF47lRIXrbPCz8xf/Za+8oSWttdM/L7lnO2uHOKFZQl2JJd65FkVx5lSWI+tpMU7shPb5C+jO",
+	"DWpfUAfszLNKPkXftC2I/Z2B3Y3+xd3/mfpNi45oX10ctN1Wd1nXqg3FFoRWuYkaTKooFBwy2VyPRzKl",
+	"C8PweApiwAaqytmLAaKcLCinth+YFM3MZciWC4Ya1c5gj06Y0haWT92ezS7kMVMl4uzJDbModE32zMlh",
+	"MPVtiboHVhWMZIHQA8zGUX0eV96RaM4dIEFLrORPk7fDsJMbTLb528R2z2wJ3BFJj74f/dvsyceIzAci",
+	"wN90sDZ0bxbHo+JT11ASsxSW2KFpx8eITfpAGyH2KRkdazOOF9+jZaqNJX8hCeUofw2lP7x18FrYmTGz",
+	"U7st+M5gn4nBXH9Y5C5zHG/OW75RpeOuRzaneOVZcoa36qnINHWAkK0oW8r22g/M7owls7CKcdi2WYxa",
+	"421TM6IFqYXSI5cCbO6khVu14pQ/je+v3ITV42qYT2fslfnlwzyXN42uGx0C3tOoD/7OivyacBlQIr/G",
+	"30rok5e7lxR+mngxg3SIO2QX2lt3U8vFtuGPEIrOT/sfrtCj3eWM3csbv54vzM7vNuvr+H08KjZw7rQ2",
+	"KN7s6bLl/VsjROxFi5jyjnd5SDRr25svg0u4CG65/Qd2ywke31DxrRwECdEaiNW9iPrgCB6J1tPQ89cL",
+	"1/iasdX6+Vm4Y3nYuMCqSsH0y2MXVDy6be6OpldJ1fxaUFlao8ukDju3hpvHF723w6ZkrdHWzzrwP4TS",
+	"Hs+4qe7ewfI3TEl6DvEeR+2mHQE5Qojop01UTqq8uEdhmLVHob2uQQs0cYyB4wpRWJljmYq7Kzr3dJ33",
+	"GvptrziylL9LqcWmP6Hi4hQ1qErpDp/9tsGJau5fcknYW1QV2GYaVLEONN9WKXWgUlXETaLxLzPeRrC8",
+	"slxF7Bc2t1KRLYtKt8ptF1BY1FSaaCI3ThVa+SdOD3YhSdeyEyOA15hb3Jj+2kqEWiHcOuWjre7p7nh1",
+	"uq7qXmc13CEH0EYLxoznTsf4UFjYqFUKTSg9Gs59Z/HV/SBy2dXVkkyrpfXNMdUS871qpFMraQm3Xcpd",
+	"pc9rAUbhzmz3lFWTeub5PDOGtq5CRjHKOxod2pheLyzABVNLIqleCTl+kUqmbhPDf3uQ86TbU+Gu4+Sw",
+	"X1pk1udSdcxU/xi9oJqODtXozTRxkL98Th4/fvwMLZ0QZEenpWrL1s3W75Bj5w1UeGyYb9y3yhdw1SCZ",
+	"KI3rt1oa5W0qAeP3itNazYVuu/CeHJ9fXJ0e/uPq/OLw5Oj10fn5trcnQ2GOjirf/AjxZeNMX3KmBp/6",
+	"G/AveZY2iFJVxLt7j/+ZMBk+fpu5x9/9D5/D/+D5hlZVEDitr69bFraByvMIkwZGTutJJ0aZSipFmrpz",
+	"WQ7jrhuMSXLSktXOt21cDpFmjKFp2/Vwyj6Y9M9QzkWOsHwpbgpzyWe4Huw509TJejD3fY65eraQB0sR",
+	"++1W0N7J2+5ZiBI06Ji65D5LQchOQCl0b7YG3w457GR9dT8tBSjzt7kOzcJp4FDppB1dzH+BSIn8Ekk7",
+	"icZKXyBv53PU4CUZpepVBQbCCGfEQwu214Kwcph2gw8xsNzCOEzD+S7zPl9QY1juivfq0WqddWcaG7nX",
+	"eMGnI/++AFyOjC41jHr0HbJKVDdghNP8jlYWtO2lO2xckQ+7agx8r46Pl+4G5s2iLKu7dnyaJ3ZVb40v",
+	"GlnpqJMrQr+ryzy/aj7ZN5kz9lp48OKwUHBvcgxXR18Yru3e8WV7bn4XaV9GpHX6eVwvbZ2W5dE7Nbh/",
+	"s3JtsDbmpQ2ESXOfjlEJ0YLeqE0Ey+fpMfVdDP0/JIYsPr/FcMRfU6CEK9kGOWutNMmzukm1gOFcNOg3",
+	"CXKp9XH0my5iGYVrLBU1WiQnnWZT7iKMgWveR8xsfYxN6cQphAwzUNk26m3rVVpaxyoLWzAyiLq4Sk9m",
+	"mjHYu3mjKll/LwY5c2CFNh5GqRqsFLs4uJIP5cvUUxafH+7MY/6vKXi/lBHbjU89bPHJcO6VUrbXqtuG",
+	"DPIoAmVHMYTm2wRiiUS3p8Hu1wCdxZB/vYSAHqN/1S4Fvgd393z8JjoQfLMNBzrVkpFM9vn87m6j7t3H",
+	"yU1/6NqGUBL4mUsb7M7dUd2wphbhe84/71Vtfu6Uf382RyaXjfV2y3+RdNWmJtijthx7FO69T7qSNr40",
+	"LC57WH1t2AExmScLk1TrfJK2QWF8abQhywbjBs6XLhPalLsSCS+Itk3NE3dohWJGJdp+Q5e8oJyUjM64",
+	"UOBiEcqmMNriXrwZyaZE+VquBSywDtgWSUtt43GmEbRrSkKJBHyzIkXRbkf/jravr2l9TYu1j43V5Ufu",
+	"M1ugrjQr+iT33Xz9br4+oPm6kiTpOus1JYgxB2rkarbWll+l+rpb2WPv94hqtbH4io+c0OqUuebxvbqd",
+	"nimmFe8lx55G1LW39bZlXbk2Q1F7fddpyu5tjpfBGnQUcyMdXU2oImphYr8mzmoUFzKt2Gyu1VoZiW1+",
+	"24YB37qAHKTX2Fyc1T2Dle0U8Ysgpatzc6GX3cXfBgi3LSCtsyC0zPDNHtnKnHE704rsjN1Fdp9cNgcN",
+	"ErcjC5fBds04Hsk3M0NMLoduFUh0NkumfNGbmb0wIsOLYj49b/1PBoHjPtYbxoAdakJkInCHIlR320db",
+	"FnXoM13KUXTY3389e9bBI2RMpt+P0u9H6QMepfZYW1+0PDhCG9+tPHlatrcQGrMAb0mk4brTGmSwBfAD",
+	"dyFhL9Hx8a55qJIhb3ul6kMIpe4lrhuIJfxwuEa8cPGh2fqdd1u3dSW5y1VS4QyjbfMVc6qUwJltmRQV",
+	"q3zD6fs1yFHAdNNr7GV3LRBtaE69Rslz3+TmeF0wW7t33bCq7CRghqrxkJZmlxKu69ftUKETyeQfI8dH",
+	"o7/bV77hHKGK3EJVrVDL3Ndfsnz+Z7PEYz4VK3uUIA42LJrvfCwbji4a/6N1NfMh32+NPyR80tbOtzq1",
+	"fbHuEoK7CuQv+aHjEeeFtGdg8PMSBaAItimKMxz7/ecx5xEzCc2vV5XytIt5EGkWpttUwYqW870I/4ud",
+	"wLcxFXjeiEgjwR53JaAls3D7NOpJFJknJ66dXMjxTdwTMRBNLUXds74/APGZa/zX3THxRX1p/Zs57uKo",
+	"1okbZbJ20P0V0r5a6L43BHiw5NQei9J2F1aKAzMyYsayWiMrLEXX9cGjR5UoaDUXSh88HT99mn38LQwy",
+	"qEPy3KyIhMpeSxdS/9ztXHhWulY3jhu9MfAxXzMg3nOGfVjjZqiDm0zaUb3NkBjWlfCOKriBytX7Mmdd",
+	"uPLpaBz7cWqc1+k+O6EjHlVYpdBwFq3WtWZYNdrdCkc7VLSHw+HeDhRZbzPZa1fdGN4QGV55bf1kmHZy",
+	"bRT7FbqXG8erXh9/+/h/BwAMcuKWeL0AAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rockets/internal/auth"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"testing"
+	"time"
+)
+
+// update rewrites the golden files with the current responses: go test ./internal/http -run Golden -update
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenServer creates a server holding rockets in representative states
+func goldenServer(t *testing.T) *echo.Echo {
+	t.Helper()
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: goldenService(t),
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+	return e
+}
+
+// goldenService creates a service holding rockets in representative states
+func goldenService(t *testing.T) *rocket.ServiceImpl {
+	t.Helper()
+	logger := zap.NewNop()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	svc.UseHistory(rocket.NewInMemoryHistoryStore())
+
+	launched := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	exploded := uuid.MustParse("7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30")
+	lost := uuid.MustParse("c1e5f7a2-3b4d-4e6f-8a9b-0c1d2e3f4a5b")
+	at := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+	messages := []rocket.TelemetryMessage{
+		{
+			Metadata: rocket.MessageMetadata{Channel: launched, MessageNumber: 1, MessageTime: at, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: launched, MessageNumber: 2, MessageTime: at.Add(time.Minute), MessageType: rocket.MessageTypeSpeedIncreased},
+			Message:  rocket.Message{By: ptr(rocket.Speed(3000))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: exploded, MessageNumber: 1, MessageTime: at, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Soyuz")), LaunchSpeed: ptr(rocket.Speed(800)), Mission: ptr(rocket.Mission("GEMINI"))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: exploded, MessageNumber: 2, MessageTime: at.Add(2 * time.Minute), MessageType: rocket.MessageTypeExploded},
+			Message:  rocket.Message{Reason: ptr("PRESSURE_VESSEL_FAILURE")},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: lost, MessageNumber: 1, MessageTime: at, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Starship")), LaunchSpeed: ptr(rocket.Speed(0)), Mission: ptr(rocket.Mission("APOLLO"))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: lost, MessageNumber: 2, MessageTime: at.Add(3 * time.Minute), MessageType: rocket.MessageTypeExploded},
+			Message:  rocket.Message{},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := svc.ProcessMessage(context.Background(), msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	return svc
+}
+
+func TestAPI_GoldenResponses(t *testing.T) {
+	e := goldenServer(t)
+	cases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		code   int
+	}{
+		{"rocket_launched", http.MethodGet, "/v1/rockets/193270a9-c9cf-404a-8f83-838e71d9ae67", "", http.StatusOK},
+		{"rocket_exploded_with_reason", http.MethodGet, "/v1/rockets/7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30", "", http.StatusOK},
+		{"rocket_exploded_without_reason", http.MethodGet, "/v1/rockets/c1e5f7a2-3b4d-4e6f-8a9b-0c1d2e3f4a5b", "", http.StatusOK},
+		{"rockets_sorted_by_mission", http.MethodGet, "/v1/rockets?sortBy=mission&sortOrder=asc", "", http.StatusOK},
+		{"rockets_sorted_by_last_update_time", http.MethodGet, "/v1/rockets?sortBy=lastUpdateTime&sortOrder=desc", "", http.StatusOK},
+		{"rockets_unknown_sort_by", http.MethodGet, "/v1/rockets?sortBy=altitude", "", http.StatusBadRequest},
+		{"rockets_filtered_by_status", http.MethodGet, "/v1/rockets?status=EXPLODED&type=Soyuz", "", http.StatusOK},
+		{"rocket_not_found", http.MethodGet, "/v1/rockets/00000000-0000-0000-0000-000000000001", "", http.StatusNotFound},
+		{"message_invalid", http.MethodPost, "/messages", `{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":3,"messageTime":"2022-02-02T19:41:05Z","messageType":"RocketSpeedIncreased"},"message":{"by":-1}}`, http.StatusBadRequest},
+		{"message_applied_with_warning", http.MethodPost, "/messages", `{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":3,"messageTime":"2022-02-02T19:41:05Z","messageType":"RocketSpeedDecreased"},"message":{"by":4000}}`, http.StatusAccepted},
+		{"message_duplicate", http.MethodPost, "/messages", `{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":2,"messageTime":"2022-02-02T19:40:05Z","messageType":"RocketSpeedIncreased"},"message":{"by":3000}}`, http.StatusConflict},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.target, bytes.NewBufferString(c.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != c.code {
+				t.Fatalf("Expected %d, got %d: %s", c.code, rec.Code, rec.Body.String())
+			}
+
+			var got bytes.Buffer
+			if err := json.Indent(&got, rec.Body.Bytes(), "", "  "); err != nil {
+				t.Fatalf("Response is not JSON: %v", err)
+			}
+			got.WriteByte('\n')
+
+			path := filepath.Join("testdata", "golden", c.name+".json")
+			if *update {
+				if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+					t.Fatalf("Can't update golden file: %v", err)
+				}
+			}
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Can't read golden file, run with -update to create it: %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), expected) {
+				t.Errorf("Response differs from %s; if the wire format change is intended, run with -update.\nExpected:\n%s\nGot:\n%s", path, expected, got.String())
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/incident"
+	"rockets/internal/usage"
+	"testing"
+)
+
+func TestAPI_Incidents(t *testing.T) {
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:      e,
+		Logger:    zap.NewNop(),
+		Rocket:    goldenService(t),
+		Keys:      auth.NewKeys(nil),
+		Usage:     usage.NewMeter(usage.Quota{}),
+		Incidents: incident.NewRegistry(zap.NewNop()),
+	})
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/admin/incidents", `{"rocket":"193270a9-c9cf-404a-8f83-838e71d9ae67","title":"Telemetry lost"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the incident created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created incident.Incident
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec := do(http.MethodPost, "/admin/incidents", `{"title":"no rocket"}`); rec.Code != http.StatusBadRequest || !bytes.Contains(rec.Body.Bytes(), []byte("invalid_incident")) {
+		t.Errorf("Expected an incident without a rocket rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	path := "/admin/incidents/" + created.ID.String()
+	if rec := do(http.MethodPatch, path, `{"status":"acknowledged","assignee":"flight-director"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the incident acknowledged, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list []incident.Incident
+	_ = json.Unmarshal(do(http.MethodGet, "/admin/incidents?status=acknowledged", "").Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Assignee != "flight-director" {
+		t.Errorf("Expected the acknowledged incident listed, got %+v", list)
+	}
+	if rec := do(http.MethodGet, "/admin/incidents?status=resolved", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown status filter rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/admin/incidents/nope", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid incident id rejected, got %d", rec.Code)
+	}
+
+	if rec := do(http.MethodDelete, path, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the incident deleted, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected a deleted incident not found, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"bytes"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"testing"
+)
+
+func FuzzIngestMessage(f *testing.F) {
+	for _, seed := range []string{
+		`{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":1,"messageTime":"2022-02-02T19:39:05.86337+01:00","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`,
+		`{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":2,"messageTime":"2022-02-02T19:40:05Z","messageType":"RocketSpeedIncreased"},"message":{"by":3000}}`,
+		`{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":3,"messageTime":"2022-02-02T19:41:05Z","messageType":"RocketSpeedDecreased"},"message":{}}`,
+		`{"metadata":{"channel":"193270a9-c9cf-404a-8f83-838e71d9ae67","messageNumber":9223372036854775807,"messageTime":"2022-02-02T19:41:05Z","messageType":"RocketExploded"},"message":{"reason":null}}`,
+		`{"metadata":{"channel":"not-a-uuid","messageNumber":-1,"messageType":"RocketMissionChanged"},"message":{"newMission":""}}`,
+		`{"metadata":null,"message":null}`,
+		`[]`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	e := echo.New()
+	logger := zap.NewNop()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	svc.UseHistory(rocket.NewInMemoryHistoryStore())
+	svc.AutoQuarantine(5)
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: logger,
+		Rocket: svc,
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req := httptest.NewRequest(http.MethodPost, "/messages", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		switch rec.Code {
+		case http.StatusAccepted, http.StatusBadRequest:
+		default:
+			t.Errorf("Expected 202 or 400, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+)
+
+// Listeners - addresses the HTTP server accepts connections on
+type Listeners struct {
+	// Addrs - TCP addresses, e.g. ":8088" or "127.0.0.1:9000"
+	Addrs []string
+	// UnixSocket - path of a Unix domain socket, e.g. for a sidecar ingesting on the same host, empty for none
+	UnixSocket string
+	// UnixSocketMode - permissions of the socket file, which control who may connect
+	UnixSocketMode fs.FileMode
+}
+
+// unixSocketKey - context key marking requests received over the Unix domain socket
+type unixSocketKey struct{}
+
+// Listen opens all the listeners, so a busy address fails the startup instead of a running server.
+// A stale socket file left by a previous run is removed first, the socket file is removed again when closed.
+func Listen(cfg Listeners) ([]net.Listener, error) {
+	var listeners []net.Listener
+	fail := func(err error) ([]net.Listener, error) {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+		return nil, err
+	}
+	for _, addr := range cfg.Addrs {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fail(fmt.Errorf("can't listen http server on %s: %w", addr, err))
+		}
+		listeners = append(listeners, l)
+	}
+	if cfg.UnixSocket != "" {
+		if info, err := os.Lstat(cfg.UnixSocket); err == nil && info.Mode().Type() == fs.ModeSocket {
+			if err := os.Remove(cfg.UnixSocket); err != nil {
+				return fail(fmt.Errorf("can't remove stale socket %s: %w", cfg.UnixSocket, err))
+			}
+		}
+		l, err := net.Listen("unix", cfg.UnixSocket)
+		if err != nil {
+			return fail(fmt.Errorf("can't listen http server on %s: %w", cfg.UnixSocket, err))
+		}
+		listeners = append(listeners, l)
+		if err := os.Chmod(cfg.UnixSocket, cfg.UnixSocketMode); err != nil {
+			return fail(fmt.Errorf("can't set permissions of socket %s: %w", cfg.UnixSocket, err))
+		}
+	}
+	return listeners, nil
+}
+
+// ServeEchoServer serves the Echo server on the listener until the server is shut down.
+// All listeners share the server, so ShutDownEchoServer stops them together.
+func ServeEchoServer(e *echo.Echo, l net.Listener, logger *zap.Logger) func() error {
+	return func() error {
+		addr := l.Addr().String()
+		logger.Info("Listening http server", zap.String("network", l.Addr().Network()), zap.String("addr", addr))
+		err := e.Server.Serve(l)
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("can't serve http server on %s: %w", addr, err)
+		}
+		logger.Info("Http server stopped listening", zap.String("addr", addr))
+
+		return nil
+	}
+}
+
+// markUnixSocket marks the context of connections accepted on a Unix domain socket
+func markUnixSocket(ctx context.Context, c net.Conn) context.Context {
+	if c.LocalAddr().Network() == "unix" {
+		return context.WithValue(ctx, unixSocketKey{}, true)
+	}
+	return ctx
+}
+
+// fromUnixSocket reports whether the request was received over the Unix domain socket
+func fromUnixSocket(r *http.Request) bool {
+	local, _ := r.Context().Value(unixSocketKey{}).(bool)
+	return local
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"testing"
+	"time"
+)
+
+func TestAPI_ListRocketsByStatus(t *testing.T) {
+	svc := goldenService(t)
+	svc.UseProvisionalState()
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: svc,
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+
+	// a speed change before the launch leaves a provisional state
+	partial := uuid.MustParse("5d2f8c1e-7a3b-4c9d-8e1f-2a3b4c5d6e7f")
+	_, err := svc.ProcessMessage(context.Background(), rocket.TelemetryMessage{
+		Metadata: rocket.MessageMetadata{Channel: partial, MessageNumber: 2, MessageTime: time.Date(2022, 2, 2, 19, 40, 5, 0, time.UTC), MessageType: rocket.MessageTypeSpeedIncreased},
+		Message:  rocket.Message{By: ptr(rocket.Speed(1000))},
+	})
+	if err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		status string
+		count  int
+	}{
+		{"LAUNCHED", 1},
+		{"EXPLODED", 2},
+		{"PARTIAL", 1},
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/rockets?status="+tt.status, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200 for status %s, got %d: %s", tt.status, rec.Code, rec.Body.String())
+		}
+		var states []gen.RocketState
+		_ = json.Unmarshal(rec.Body.Bytes(), &states)
+		if len(states) != tt.count {
+			t.Errorf("Expected %d rockets with status %s, got %+v", tt.count, tt.status, states)
+		}
+		for _, s := range states {
+			if string(s.Status) != tt.status {
+				t.Errorf("Expected rockets with status %s, got %+v", tt.status, s)
+			}
+		}
+	}
+}
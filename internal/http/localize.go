@@ -0,0 +1,72 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"rockets/internal/http/gen"
+	"rockets/internal/i18n"
+	"strings"
+)
+
+// Headers of the language negotiation
+const (
+	acceptLanguageHeader  = "Accept-Language"
+	contentLanguageHeader = "Content-Language"
+)
+
+// LocalizeErrors translates the message of the error responses into the language of the Accept-Language header,
+// by their code, keeping the original message under detail. Requests without the header, or preferring the
+// default language, get the original messages, which tell more than the catalog texts.
+func LocalizeErrors() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Add(echo.HeaderVary, acceptLanguageHeader)
+			l := i18n.Negotiate(c.Request().Header.Get(acceptLanguageHeader))
+			if l.Default() {
+				return next(c)
+			}
+
+			res := c.Response()
+			buffer := &bufferWriter{ResponseWriter: res.Writer, status: http.StatusOK}
+			res.Writer = buffer
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			res.Writer = buffer.ResponseWriter
+
+			body := buffer.body.Bytes()
+			if buffer.status >= 400 && strings.HasPrefix(res.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				body = localizeError(body, l, res.Header())
+			}
+			res.Writer.WriteHeader(buffer.status)
+			_, err = res.Writer.Write(body)
+			return err
+		}
+	}
+}
+
+// localizeError translates the message of the ErrorResponse body, returning other bodies and codes missing in
+// the catalog unchanged
+func localizeError(body []byte, l i18n.Localizer, header http.Header) []byte {
+	var resp gen.ErrorResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Code == "" || !i18n.Has(errorKey(resp.Code)) {
+		return body
+	}
+	detail := resp.Message
+	resp.Message = l.Text(errorKey(resp.Code))
+	resp.Detail = &detail
+	out, err := json.Marshal(resp)
+	if err != nil {
+		return body
+	}
+	header.Del(echo.HeaderContentLength)
+	header.Set(contentLanguageHeader, l.Language())
+	return append(out, '\n')
+}
+
+// errorKey returns the catalog key of the message of the error code
+func errorKey(code gen.ErrorCode) string {
+	return "error." + string(code)
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/i18n"
+	"rockets/internal/report"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+)
+
+func TestAPI_LocalizeErrors(t *testing.T) {
+	e := echo.New()
+	svc := goldenService(t)
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: svc,
+		Feed:   report.NewFeed(10),
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+	get := func(target, language string) (*httptest.ResponseRecorder, gen.ErrorResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if language != "" {
+			req.Header.Set(acceptLanguageHeader, language)
+		}
+		e.ServeHTTP(rec, req)
+		var resp gen.ErrorResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	target := "/v1/rockets/1a2b3c4d-0000-4000-8000-000000000000"
+	rec, resp := get(target, "fr-CH, fr;q=0.9, en;q=0.8")
+	if rec.Code != http.StatusNotFound || resp.Code != gen.ErrorCodeNotFound || resp.Message != "La fusée est introuvable." || resp.Detail == nil {
+		t.Errorf("Expected the French message with the original detail, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get(contentLanguageHeader) != "fr" {
+		t.Errorf("Expected Content-Language fr, got %q", rec.Header().Get(contentLanguageHeader))
+	}
+
+	_, english := get(target, "en-US")
+	_, plain := get(target, "")
+	if english.Detail != nil || plain.Detail != nil || english.Message != plain.Message || resp.Detail == nil || *resp.Detail != plain.Message {
+		t.Errorf("Expected the original message in English, got %+v and %+v", english, plain)
+	}
+
+	rec, _ = get("/status", "fr")
+	if !strings.Contains(rec.Body.String(), "<h1>État des fusées</h1>") || rec.Header().Get(contentLanguageHeader) != "fr" {
+		t.Errorf("Expected the status page in French, got %s", rec.Body.String())
+	}
+}
+
+func TestErrorCodes_Localized(t *testing.T) {
+	spec, err := gen.GetSwagger()
+	if err != nil {
+		t.Fatalf("Can't load the spec: %v", err)
+	}
+	codes := spec.Components.Schemas["ErrorCode"].Value.Enum
+	if len(codes) == 0 {
+		t.Fatalf("Expected the error codes in the spec")
+	}
+	for _, code := range codes {
+		if !i18n.Has(errorKey(gen.ErrorCode(code.(string)))) {
+			t.Errorf("Message catalog has no text for the error code %s", code)
+		}
+	}
+}
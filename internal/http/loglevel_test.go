@@ -0,0 +1,58 @@
+package http
+
+import (
+	"bytes"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/logging"
+	"rockets/internal/usage"
+	"testing"
+)
+
+func TestAPI_SetLogLevel(t *testing.T) {
+	levels, err := logging.NewLevels("warn")
+	if err != nil {
+		t.Fatalf("NewLevels failed: %v", err)
+	}
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: goldenService(t),
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+		Levels: levels,
+	})
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/admin/log-level", bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for body, code := range map[string]string{
+		`{"component":"stroe","level":"debug"}`: "invalid_component",
+		`{"level":""}`:                          "invalid_level",
+		`{}`:                                    "invalid_level",
+		`{"component":"store","level":"loud"}`:  "invalid_level",
+	} {
+		if rec := put(body); rec.Code != http.StatusBadRequest || !bytes.Contains(rec.Body.Bytes(), []byte(code)) {
+			t.Errorf("Expected 400 %s for %s, got %d: %s", code, body, rec.Code, rec.Body)
+		}
+	}
+	if global, components := levels.Level(); global != zapcore.WarnLevel || len(components) != 0 {
+		t.Errorf("Expected the levels unchanged, got %s and %v", global, components)
+	}
+
+	if rec := put(`{"component":"incident","level":"debug"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if _, components := levels.Level(); components[logging.ComponentIncident] != zapcore.DebugLevel {
+		t.Errorf("Expected the incident component at debug, got %v", components)
+	}
+}
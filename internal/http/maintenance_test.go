@@ -0,0 +1,67 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/maintenance"
+	"rockets/internal/usage"
+	"testing"
+	"time"
+)
+
+func TestAPI_Maintenance(t *testing.T) {
+	e := echo.New()
+	windows := maintenance.NewRegistry()
+	NewServer(&ServerOpts{
+		Echo:        e,
+		Logger:      zap.NewNop(),
+		Rocket:      goldenService(t),
+		Keys:        auth.NewKeys(nil),
+		Usage:       usage.NewMeter(usage.Quota{}),
+		Maintenance: windows,
+		Suppressor:  maintenance.NewSuppressor(windows, nil, zap.NewNop()),
+	})
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	start, end := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339), time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	rec := do(http.MethodPost, "/admin/maintenance", `{"name":"static fire","missions":["artemis"],"start":"`+start+`","end":"`+end+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the window created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created maintenance.Window
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec := do(http.MethodPost, "/admin/maintenance", `{"name":"backwards","missions":["ARTEMIS"],"start":"`+end+`","end":"`+start+`"}`); rec.Code != http.StatusBadRequest || !bytes.Contains(rec.Body.Bytes(), []byte("invalid_maintenance_window")) {
+		t.Errorf("Expected a window ending before its start rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var active []maintenance.Window
+	_ = json.Unmarshal(do(http.MethodGet, "/admin/maintenance?active=true", "").Body.Bytes(), &active)
+	if len(active) != 1 || active[0].ID != created.ID || active[0].Missions[0] != "ARTEMIS" {
+		t.Errorf("Expected the window active, got %+v", active)
+	}
+	if rec := do(http.MethodGet, "/admin/maintenance/suppressed", ""); rec.Code != http.StatusOK || rec.Body.String() != "[]\n" {
+		t.Errorf("Expected no suppressed alerts, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	path := "/admin/maintenance/" + created.ID.String()
+	if rec := do(http.MethodPut, path, `{"name":"static fire","channels":["193270a9-c9cf-404a-8f83-838e71d9ae67"],"start":"`+start+`","end":"`+end+`"}`); rec.Code != http.StatusOK {
+		t.Errorf("Expected the window updated, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodDelete, path, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the window deleted, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected a deleted window not found, got %d", rec.Code)
+	}
+}
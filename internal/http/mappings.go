@@ -1,8 +1,12 @@
 package http
 
 import (
+	"fmt"
+	"rockets/internal/buildinfo"
+	"rockets/internal/fleet"
 	"rockets/internal/http/gen"
 	"rockets/internal/rocket"
+	"rockets/internal/watchlist"
 )
 
 // stateToServer converts a rocket.State to a gen.RocketState.
@@ -10,19 +14,290 @@ func stateToServer(state rocket.State) gen.RocketState {
 	var status gen.RocketStateStatus
 	switch state.Status {
 	case rocket.StatusLaunched:
-		status = gen.LAUNCHED
+		status = gen.RocketStateStatusLAUNCHED
 	case rocket.StatusExploded:
-		status = gen.EXPLODED
+		status = gen.RocketStateStatusEXPLODED
+	case rocket.StatusPartial:
+		status = gen.RocketStateStatusPARTIAL
 	}
 
-	return gen.RocketState{
-		CurrentSpeed:               state.CurrentSpeed,
+	out := gen.RocketState{
+		Anomaly:                    state.Anomaly,
+		CurrentSpeed:               int64(state.CurrentSpeed),
 		Id:                         state.ID,
 		LastProcessedMessageNumber: state.LastProcessedMessageNumber,
 		LastUpdateTime:             state.LastUpdateTime,
-		Mission:                    state.Mission,
-		Reason:                     state.Reason,
+		Mission:                    string(state.Mission),
 		Status:                     status,
-		Type:                       state.Type,
+		Type:                       string(state.Type),
 	}
+	if state.Reason != nil {
+		out.Reason = &state.Reason.Text
+		out.ExplosionReason = &gen.ExplosionReason{
+			Category: gen.ExplosionReasonCategory(state.Reason.Category),
+			Severity: gen.ExplosionReasonSeverity(state.Reason.Severity),
+			Text:     state.Reason.Text,
+		}
+	}
+	return out
+}
+
+// sortToDomain converts the sort parameters of the rockets listing, by ID in ascending order by default
+func sortToDomain(params gen.ListRocketsParams) (rocket.SortKey, error) {
+	sortBy, sortOrder := rocket.SortByID, rocket.SortAsc
+	if params.SortBy != nil {
+		switch *params.SortBy {
+		case gen.Id:
+			sortBy = rocket.SortByID
+		case gen.Type:
+			sortBy = rocket.SortByType
+		case gen.Speed:
+			sortBy = rocket.SortBySpeed
+		case gen.Mission:
+			sortBy = rocket.SortByMission
+		case gen.LastUpdateTime:
+			sortBy = rocket.SortByLastUpdateTime
+		default:
+			return rocket.SortKey{}, fmt.Errorf("%w: %s", rocket.ErrUnknownSortField, *params.SortBy)
+		}
+	}
+	if params.SortOrder != nil {
+		switch *params.SortOrder {
+		case gen.Asc:
+			sortOrder = rocket.SortAsc
+		case gen.Desc:
+			sortOrder = rocket.SortDesc
+		default:
+			return rocket.SortKey{}, fmt.Errorf("%w: %s", rocket.ErrUnknownSortOrder, *params.SortOrder)
+		}
+	}
+	key := rocket.SortKey{Field: sortBy, Order: sortOrder}
+	if params.SortModifier != nil {
+		switch *params.SortModifier {
+		case gen.Natural:
+			key.Natural = true
+		default:
+			return rocket.SortKey{}, fmt.Errorf("%w: %s", rocket.ErrUnknownSortModifier, *params.SortModifier)
+		}
+	}
+	return key, nil
+}
+
+// filterToDomain converts the filter parameters of the rockets listing to a rocket.Filter
+func filterToDomain(params gen.ListRocketsParams) (rocket.Filter, error) {
+	var filter rocket.Filter
+	if params.Status != nil {
+		switch *params.Status {
+		case gen.ListRocketsParamsStatusLAUNCHED:
+			filter.Status = rocket.StatusLaunched
+		case gen.ListRocketsParamsStatusEXPLODED:
+			filter.Status = rocket.StatusExploded
+		case gen.ListRocketsParamsStatusPARTIAL:
+			filter.Status = rocket.StatusPartial
+		default:
+			return rocket.Filter{}, fmt.Errorf("unknown status: %s", *params.Status)
+		}
+	}
+	if params.Mission != nil {
+		mission, err := rocket.NewMission(*params.Mission)
+		if err != nil {
+			return rocket.Filter{}, err
+		}
+		filter.Mission = mission
+	}
+	if params.Type != nil {
+		rocketType, err := rocket.NewRocketType(*params.Type)
+		if err != nil {
+			return rocket.Filter{}, err
+		}
+		filter.Type = rocketType
+	}
+	return filter, nil
+}
+
+// messageTypeToDomain converts the message type of the API, false when it is unknown
+func messageTypeToDomain(t gen.MessageMetadataMessageType) (rocket.MessageType, bool) {
+	switch t {
+	case gen.RocketExploded:
+		return rocket.MessageTypeExploded, true
+	case gen.RocketHeartbeat:
+		return rocket.MessageTypeHeartbeat, true
+	case gen.RocketLaunched:
+		return rocket.MessageTypeLaunched, true
+	case gen.RocketSpeedIncreased:
+		return rocket.MessageTypeSpeedIncreased, true
+	case gen.RocketSpeedDecreased:
+		return rocket.MessageTypeSpeedDecreased, true
+	case gen.RocketMissionChanged:
+		return rocket.MessageTypeMissionChanged, true
+	default:
+		return "", false
+	}
+}
+
+// messageToDomain converts a gen.Message to a rocket.Message, validating the values of the present fields.
+func messageToDomain(m gen.Message) (rocket.Message, error) {
+	by, err := optional(m.By, rocket.NewSpeed)
+	if err != nil {
+		return rocket.Message{}, err
+	}
+	launchSpeed, err := optional(m.LaunchSpeed, rocket.NewSpeed)
+	if err != nil {
+		return rocket.Message{}, err
+	}
+	mission, err := optional(m.Mission, rocket.NewMission)
+	if err != nil {
+		return rocket.Message{}, err
+	}
+	newMission, err := optional(m.NewMission, rocket.NewMission)
+	if err != nil {
+		return rocket.Message{}, err
+	}
+	rocketType, err := optional(m.Type, rocket.NewRocketType)
+	if err != nil {
+		return rocket.Message{}, err
+	}
+
+	return rocket.Message{
+		By:          by,
+		LaunchSpeed: launchSpeed,
+		Mission:     mission,
+		NewMission:  newMission,
+		Reason:      m.Reason,
+		Type:        rocketType,
+	}, nil
+}
+
+// optional constructs a value object from an optional field
+func optional[T, V any](v *T, construct func(T) (V, error)) (*V, error) {
+	if v == nil {
+		return nil, nil
+	}
+	out, err := construct(*v)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// buildInfoToServer converts a buildinfo.Info to a gen.BuildInfo, leaving out the unknown fields.
+func buildInfoToServer(info buildinfo.Info) gen.BuildInfo {
+	resp := gen.BuildInfo{
+		Version:   info.Version,
+		GoVersion: info.GoVersion,
+	}
+	if info.Commit != "" {
+		resp.Commit = &info.Commit
+	}
+	if !info.BuildTime.IsZero() {
+		resp.BuildTime = &info.BuildTime
+	}
+	return resp
+}
+
+// registrationToServer converts a rocket.Registration to a gen.Registration.
+func registrationToServer(reg rocket.Registration, awaiting bool) gen.Registration {
+	return gen.Registration{
+		Id:                reg.ID,
+		Type:              string(reg.Type),
+		Mission:           string(reg.Mission),
+		RegisteredAt:      reg.RegisteredAt,
+		AwaitingTelemetry: awaiting,
+	}
+}
+
+// resultToServer converts a rocket.Result to a gen.IngestResult.
+func resultToServer(result rocket.Result) gen.IngestResult {
+	warnings := result.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	return gen.IngestResult{
+		Disposition: gen.IngestResultDisposition(result.Outcome),
+		Version:     result.Version,
+		Warnings:    warnings,
+	}
+}
+
+// dryRunToServer converts a rocket.DryRun to a gen.DryRunResult.
+func dryRunToServer(result rocket.DryRun) gen.DryRunResult {
+	resp := gen.DryRunResult{
+		Outcome:   gen.DryRunResultOutcome(result.Outcome),
+		Changes:   make([]gen.FieldChange, 0, len(result.Changes)),
+		Underflow: result.Underflow,
+	}
+	if result.Current != nil {
+		current := stateToServer(*result.Current)
+		resp.Current = &current
+	}
+	if result.Next != nil {
+		next := stateToServer(*result.Next)
+		resp.Next = &next
+	}
+	for _, change := range result.Changes {
+		c := gen.FieldChange{Field: string(change.Field)}
+		if change.From != nil {
+			c.From = &change.From
+		}
+		if change.To != nil {
+			c.To = &change.To
+		}
+		resp.Changes = append(resp.Changes, c)
+	}
+	return resp
+}
+
+// watchlistToServer converts the watchlist to its API representation
+func watchlistToServer(w watchlist.Watchlist) gen.Watchlist {
+	out := gen.Watchlist{Name: w.Name, Rockets: w.Rockets}
+	if w.Description != "" {
+		out.Description = &w.Description
+	}
+	return out
+}
+
+// fleetToServer converts the fleet to its API representation, with the stats of the states of its rockets
+func fleetToServer(f fleet.Fleet, states []rocket.State) gen.Fleet {
+	stats := fleet.Summarize(f, states)
+	out := gen.Fleet{
+		Name:    f.Name,
+		Rockets: f.Rockets,
+		Stats: gen.FleetStats{
+			AverageSpeed: int64(stats.AverageSpeed),
+			Exploded:     stats.Exploded,
+			Launched:     stats.Launched,
+			MaxSpeed:     int64(stats.MaxSpeed),
+			Missions:     stats.Missions,
+			Other:        stats.Other,
+			Rockets:      stats.Rockets,
+			Tracked:      stats.Tracked,
+		},
+	}
+	if f.Description != "" {
+		out.Description = &f.Description
+	}
+	if !stats.LastUpdateTime.IsZero() {
+		out.Stats.LastUpdateTime = &stats.LastUpdateTime
+	}
+	return out
+}
+
+// processingStatsToServer converts the processing statistics of a channel to their API representation
+func processingStatsToServer(stats rocket.ProcessingStats) gen.ProcessingStats {
+	out := gen.ProcessingStats{
+		Received:   stats.Received,
+		Duplicates: stats.Duplicates,
+		OutOfOrder: stats.OutOfOrder,
+		LargestGap: stats.LargestGap,
+		Rejected:   stats.Rejected,
+		Ignored:    stats.Ignored,
+	}
+	if stats.LastRejection != nil {
+		out.LastRejection = &gen.Rejection{
+			MessageNumber: stats.LastRejection.MessageNumber,
+			Reason:        stats.LastRejection.Reason,
+			Time:          stats.LastRejection.Time,
+		}
+	}
+	return out
 }
@@ -0,0 +1,784 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/netip"
+	"net/url"
+	"rockets/internal/auth"
+	"rockets/internal/capture"
+	"rockets/internal/http/gen"
+	"rockets/internal/leader"
+	"rockets/internal/logging"
+	"rockets/internal/metrics"
+	"rockets/internal/netacl"
+	"rockets/internal/notify"
+	"rockets/internal/partition"
+	"rockets/internal/rocket"
+	"rockets/internal/tracing"
+	"rockets/internal/usage"
+	"rockets/pkg/telemetry"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiKeyHeader - header carrying the producer API key, "Authorization: Bearer <key>" is accepted as well
+const apiKeyHeader = "X-API-Key"
+
+// readAfterWrite - preference of GET /v1/rockets/:id to wait until the message with the given number is applied
+const readAfterWrite = "read-after-write"
+
+// Bounds of the wait preference of read-after-write requests
+const (
+	defaultReadAfterWriteWait = time.Second
+	maxReadAfterWriteWait     = 10 * time.Second
+	readAfterWritePoll        = 10 * time.Millisecond
+)
+
+// traceparentHeader - W3C trace context header of the producer's span
+const traceparentHeader = "traceparent"
+
+// versionHeader - header carrying the version of the instance that served the response
+const versionHeader = "X-Rockets-Version"
+
+// incidentHeader - header carrying the id of the incident recorded for a recovered panic
+const incidentHeader = "X-Incident-ID"
+
+// forwardedHeader - set on the requests forwarded to the replica owning the channel, so they aren't forwarded again
+const forwardedHeader = "X-Rockets-Forwarded"
+
+// RequestLogger attaches a child logger tagged with the request id to the request context
+// and writes an access log line when the request is done. Must run after middleware.RequestID.
+func RequestLogger(logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			reqLogger := logger.With(
+				zap.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)),
+				zap.String("method", req.Method),
+				zap.String("uri", req.RequestURI),
+			)
+			c.SetRequest(req.WithContext(logging.WithLogger(req.Context(), reqLogger)))
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			reqLogger.Info("Request served",
+				logging.Event(logging.EventRequestServed),
+				zap.Int("status", c.Response().Status),
+				zap.Duration("latency", time.Since(start)),
+				zap.Int64("bytes_out", c.Response().Size),
+				zap.String("remote_ip", c.RealIP()),
+			)
+			return nil
+		}
+	}
+}
+
+// Recover converts panics of the handlers into 500 responses carrying an incident id.
+// The panic is logged with its stack trace, counted per route and, when a notifier is given, alerted.
+func Recover(logger *zap.Logger, panics *metrics.Counter, notifier notify.Notifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				incident := uuid.NewString()
+				logging.FromContext(c.Request().Context(), logger).Error("Recovered from panic",
+					logging.Event(logging.EventPanic),
+					zap.String("incident_id", incident),
+					zap.String("route", c.Path()),
+					zap.Any("panic", r),
+					zap.ByteString("stack", debug.Stack()),
+				)
+				panics.Inc(c.Path())
+				if notifier != nil {
+					go alertPanic(logger, notifier, incident, c.Path(), r)
+				}
+
+				c.Response().Header().Set(incidentHeader, incident)
+				if c.Response().Committed {
+					err = nil
+					return
+				}
+				err = c.JSON(http.StatusInternalServerError, gen.ErrorResponse{
+					Code:       gen.ErrorCodeInternalError,
+					Message:    "internal server error",
+					IncidentId: &incident,
+				})
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// alertPanic notifies the on-call engineer about the panic, failures are only logged.
+// The timeout leaves room for the retries of the notifier.
+func alertPanic(logger *zap.Logger, notifier notify.Notifier, incident, route string, r any) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	err := notifier.Notify(ctx, notify.Alert{
+		Key:      "panic/" + incident,
+		Severity: notify.SeverityCritical,
+		Title:    fmt.Sprintf("Panic in %s", route),
+		Details: map[string]string{
+			"incident_id": incident,
+			"route":       route,
+			"panic":       fmt.Sprint(r),
+		},
+		Time: time.Now().UTC(),
+	})
+	if err != nil {
+		logger.Error("Can't send panic alert", logging.Event(logging.EventDeliveryError), zap.String("incident_id", incident), zap.Error(err))
+	}
+}
+
+// VersionHeader tells the version of the instance in every response, so support can see what served a request
+func VersionHeader(version string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set(versionHeader, version)
+			return next(c)
+		}
+	}
+}
+
+// AccessControl rejects requests whose remote address is denied or not allowed for the route group.
+// The address of the TCP peer is used, forwarding headers are not trusted. Requests over the Unix domain socket
+// have no address and are let through, the permissions of the socket file control who may connect.
+func AccessControl(acl *netacl.ACL, group netacl.Group) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if acl == nil || fromUnixSocket(c.Request()) {
+				return next(c)
+			}
+			host, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+			if err != nil {
+				host = c.Request().RemoteAddr
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !acl.Allowed(group, addr) {
+				return c.JSON(http.StatusForbidden, gen.ErrorResponse{
+					Code:    gen.ErrorCodeForbidden,
+					Message: fmt.Sprintf("access from %s is not allowed", host),
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// Decompress decodes request bodies compressed with Content-Encoding gzip or deflate and rejects bodies larger
+// than limit bytes once decoded with 413, so a small compressed payload can't expand into an unbounded one.
+// Other encodings are rejected with 415. A limit of 0 leaves the size unchecked.
+func Decompress(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			var body io.Reader = req.Body
+			switch encoding := strings.ToLower(strings.TrimSpace(req.Header.Get(echo.HeaderContentEncoding))); encoding {
+			case "", "identity":
+				if limit <= 0 {
+					return next(c)
+				}
+			case "gzip", "x-gzip", "deflate":
+				var zr io.ReadCloser
+				var err error
+				if encoding == "deflate" {
+					zr, err = zlib.NewReader(req.Body)
+				} else {
+					zr, err = gzip.NewReader(req.Body)
+				}
+				if err != nil {
+					return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+						Code:    gen.ErrorCodeInvalidBody,
+						Message: fmt.Sprintf("can't decompress request body: %s", err),
+					})
+				}
+				defer zr.Close()
+				body = zr
+			default:
+				return c.JSON(http.StatusUnsupportedMediaType, gen.ErrorResponse{
+					Code:    gen.ErrorCodeUnsupportedEncoding,
+					Message: fmt.Sprintf("unsupported content encoding %q, use gzip or deflate", encoding),
+				})
+			}
+
+			if limit > 0 {
+				body = io.LimitReader(body, limit+1)
+			}
+			decoded, err := io.ReadAll(body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+					Code:    gen.ErrorCodeInvalidBody,
+					Message: fmt.Sprintf("can't read request body: %s", err),
+				})
+			}
+			if limit > 0 && int64(len(decoded)) > limit {
+				return c.JSON(http.StatusRequestEntityTooLarge, gen.ErrorResponse{
+					Code:    gen.ErrorCodePayloadTooLarge,
+					Message: fmt.Sprintf("request body exceeds %d bytes once decompressed", limit),
+				})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(decoded))
+			req.ContentLength = int64(len(decoded))
+			req.Header.Del(echo.HeaderContentEncoding)
+			return next(c)
+		}
+	}
+}
+
+// Capture records full bodies of sampled requests and their responses into the recorder.
+// Requests are sampled by the rocket channel taken from the id path parameter or the message metadata.
+// Mounted on the routes after the access control and the authentication, so rejected callers are never captured;
+// the response is recorded as the handler wrote it, before the envelope and the localization.
+func Capture(recorder *capture.Recorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !recorder.Enabled() || c.Path() == capturesPath {
+				return next(c)
+			}
+
+			req := c.Request()
+			var body []byte
+			if req.Body != nil {
+				var err error
+				body, err = io.ReadAll(req.Body)
+				if err != nil {
+					return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+						Code:    gen.ErrorCodeInvalidBody,
+						Message: fmt.Sprintf("can't read request body: %s", err),
+					})
+				}
+				req.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			channel := requestChannel(c, body)
+			if !recorder.Sample(channel) {
+				return next(c)
+			}
+
+			res := c.Response()
+			tee := &teeWriter{ResponseWriter: res.Writer}
+			res.Writer = tee
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			res.Writer = tee.ResponseWriter
+
+			recorder.Record(capture.Exchange{
+				Time:            start.UTC(),
+				Method:          req.Method,
+				Path:            req.URL.RequestURI(),
+				RemoteAddr:      req.RemoteAddr,
+				Channel:         channel,
+				Status:          res.Status,
+				Duration:        time.Since(start).String(),
+				RequestHeaders:  req.Header,
+				RequestBody:     string(body),
+				ResponseHeaders: res.Header(),
+				ResponseBody:    tee.body.String(),
+			})
+			return nil
+		}
+	}
+}
+
+// requestChannel finds the rocket channel the request is about, nil when there is none
+func requestChannel(c echo.Context, body []byte) *uuid.UUID {
+	if id, err := uuid.Parse(c.Param("id")); err == nil {
+		return &id
+	}
+	// a telemetry message names it in its metadata, a backfill at the top level
+	var msg struct {
+		Channel  uuid.UUID `json:"channel"`
+		Metadata struct {
+			Channel uuid.UUID `json:"channel"`
+		} `json:"metadata"`
+	}
+	if json.Unmarshal(body, &msg) != nil {
+		return nil
+	}
+	if msg.Metadata.Channel != uuid.Nil {
+		return &msg.Metadata.Channel
+	}
+	if msg.Channel != uuid.Nil {
+		return &msg.Channel
+	}
+	return nil
+}
+
+// teeWriter copies the response body while writing it to the client
+type teeWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Redact removes the fields from the JSON responses of callers without an API key, for a public read-only view.
+// Fields are removed from every object of the response at any depth, e.g. from the states of the lookups of a
+// batch-get or a watchlist; other responses are served as they are.
+func Redact(fields []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if len(fields) == 0 || !auth.FromContext(c.Request().Context()).IsAnonymous() {
+				return next(c)
+			}
+
+			res := c.Response()
+			buffer := &bufferWriter{ResponseWriter: res.Writer, status: http.StatusOK}
+			res.Writer = buffer
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			res.Writer = buffer.ResponseWriter
+
+			body := buffer.body.Bytes()
+			if strings.HasPrefix(res.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				body = redactJSON(body, fields)
+				res.Header().Del(echo.HeaderContentLength)
+			}
+			res.Writer.WriteHeader(buffer.status)
+			_, err = res.Writer.Write(body)
+			return err
+		}
+	}
+}
+
+// redactJSON removes the fields from every object of the JSON body, returning other bodies unchanged
+func redactJSON(body []byte, fields []string) []byte {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	// numbers are kept as they are written, large ones would lose precision as floats
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+	redactValue(v, fields)
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return append(out, '\n')
+}
+
+// redactValue removes the fields from the objects of the decoded JSON value and of the values nested in it
+func redactValue(v any, fields []string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, f := range fields {
+			delete(v, f)
+		}
+		for _, nested := range v {
+			redactValue(nested, fields)
+		}
+	case []any:
+		for _, nested := range v {
+			redactValue(nested, fields)
+		}
+	}
+}
+
+// bufferWriter holds the response until the handler is done, so it can be changed before it is sent
+type bufferWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// Authenticate resolves the API key of the request into a principal stored in the request context.
+// When no API keys are configured every caller is anonymous.
+func Authenticate(keys *auth.Keys) echo.MiddlewareFunc {
+	return authenticate(keys, true)
+}
+
+// Identify resolves the API key of the request like Authenticate, if the request carries one, so the scope of
+// the key applies to it; requests without a key are anonymous.
+func Identify(keys *auth.Keys) echo.MiddlewareFunc {
+	return authenticate(keys, false)
+}
+
+func authenticate(keys *auth.Keys, required bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := auth.Anonymous
+			key := c.Request().Header.Get(apiKeyHeader)
+			if key == "" {
+				key = strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			}
+			if keys.Enabled() && (required || key != "") {
+				var ok bool
+				principal, ok = keys.Authenticate(key)
+				if !ok {
+					return c.JSON(http.StatusUnauthorized, gen.ErrorResponse{
+						Code:    gen.ErrorCodeUnauthorized,
+						Message: "missing or unknown API key",
+					})
+				}
+			}
+
+			c.SetRequest(c.Request().WithContext(auth.WithPrincipal(c.Request().Context(), principal)))
+			return next(c)
+		}
+	}
+}
+
+// backfillPath - route of the batches of historical messages, every message of a batch counts towards the quota
+const backfillPath = "/v1/backfill"
+
+// Quota accounts messages and bytes of the authenticated producer and rejects requests
+// exceeding the daily quota with 429. Must run after Authenticate.
+func Quota(meter *usage.Meter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+					Code:    gen.ErrorCodeInvalidBody,
+					Message: fmt.Sprintf("can't read request body: %s", err),
+				})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			messages := int64(1)
+			if strings.HasSuffix(c.Path(), backfillPath) {
+				messages = batchLength(body)
+			}
+			producer := auth.FromContext(req.Context()).Producer
+			u, err := meter.Record(producer, messages, int64(len(body)))
+			setQuotaHeaders(c.Response().Header(), u, meter.Quota(), meter.ResetTime())
+			if errors.Is(err, usage.ErrQuotaExceeded) {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(meter.RetryAfter().Seconds())+1))
+				return c.JSON(http.StatusTooManyRequests, gen.ErrorResponse{
+					Code:    gen.ErrorCodeQuotaExceeded,
+					Message: fmt.Sprintf("producer %s exhausted its daily quota", producer),
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// batchLength returns the number of messages of a back-fill batch, at least 1, so a malformed batch counts like
+// a single message before the handler rejects it
+func batchLength(body []byte) int64 {
+	var batch struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+	_ = json.Unmarshal(body, &batch)
+	return max(int64(len(batch.Messages)), 1)
+}
+
+type signatureKey struct{}
+
+// VerifySignature verifies the signature of the request body by the authenticated producer when the producer has
+// a signing key, recording the outcome in the request context for the message. Unsigned and tampered payloads are
+// not rejected, only recorded and logged, so they can be told apart after the fact. Must run after Authenticate.
+func VerifySignature(keys *auth.SigningKeys, logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			producer := auth.FromContext(req.Context()).Producer
+			if !keys.Has(producer) {
+				return next(c)
+			}
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+					Code:    gen.ErrorCodeInvalidBody,
+					Message: fmt.Sprintf("can't read request body: %s", err),
+				})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			status := rocket.SignatureVerified
+			if signature := req.Header.Get(telemetry.SignatureHeader); signature == "" {
+				status = rocket.SignatureMissing
+			} else if !keys.Verify(producer, body, signature) {
+				status = rocket.SignatureInvalid
+			}
+			if status != rocket.SignatureVerified {
+				logging.FromContext(req.Context(), logger).Warn("Signature of the payload not verified",
+					logging.Event(logging.EventSignatureRejected),
+					zap.String("producer", producer),
+					zap.String("signature", string(status)),
+				)
+			}
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), signatureKey{}, status)))
+			return next(c)
+		}
+	}
+}
+
+// signatureFromContext returns the verification of the signature of the request, empty when it was not verified
+func signatureFromContext(ctx context.Context) rocket.SignatureStatus {
+	status, _ := ctx.Value(signatureKey{}).(rocket.SignatureStatus)
+	return status
+}
+
+func setQuotaHeaders(h http.Header, u usage.Usage, q usage.Quota, reset time.Time) {
+	messages, bytes := u.Remaining(q)
+	if q.Messages > 0 {
+		h.Set("X-Quota-Messages-Limit", strconv.FormatInt(q.Messages, 10))
+		h.Set("X-Quota-Messages-Remaining", strconv.FormatInt(messages, 10))
+	}
+	if q.Bytes > 0 {
+		h.Set("X-Quota-Bytes-Limit", strconv.FormatInt(q.Bytes, 10))
+		h.Set("X-Quota-Bytes-Remaining", strconv.FormatInt(bytes, 10))
+	}
+	if q.Messages > 0 || q.Bytes > 0 {
+		h.Set("X-Quota-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}
+}
+
+// LeaderOnly rejects requests with 503 while the instance is a standby. A nil elector lets every request through.
+func LeaderOnly(elector *leader.Elector) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if elector == nil || elector.IsLeader() {
+				return next(c)
+			}
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(elector.Retry().Seconds())+1))
+			return c.JSON(http.StatusServiceUnavailable, gen.ErrorResponse{
+				Code:    gen.ErrorCodeNotLeader,
+				Message: "this instance is a standby, changes are made by the leader",
+			})
+		}
+	}
+}
+
+// Partition forwards the requests about channels of partitions the replica doesn't own to the owner, so producers
+// don't need to know the partition map, and answers with the owner's response. Requests for channels without a
+// known owner, and requests already forwarded by a replica, are rejected with 421 instead of being passed around.
+// Requests without a channel and every request when owner is nil are let through.
+func Partition(owner partition.Owner, logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if owner == nil {
+				return next(c)
+			}
+			req := c.Request()
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+					Code:    gen.ErrorCodeInvalidBody,
+					Message: fmt.Sprintf("can't read request body: %s", err),
+				})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			channel := requestChannel(c, body)
+			if channel == nil || owner.Owns(*channel) {
+				return next(c)
+			}
+			peer := owner.Peer(*channel)
+			if peer == "" || req.Header.Get(forwardedHeader) != "" {
+				return c.JSON(http.StatusMisdirectedRequest, gen.ErrorResponse{
+					Code:    gen.ErrorCodeWrongPartition,
+					Message: fmt.Sprintf("channel %s belongs to a partition of another replica", channel),
+				})
+			}
+			forward(c, peer, body, logging.FromContext(req.Context(), logger))
+			return nil
+		}
+	}
+}
+
+// forward proxies the request with the body to the replica at peer and copies its response, answering with 502
+// when the replica can't be reached
+func forward(c echo.Context, peer string, body []byte, logger *zap.Logger) {
+	target, err := url.Parse(peer)
+	if err != nil {
+		_ = c.JSON(http.StatusBadGateway, gen.ErrorResponse{
+			Code:    gen.ErrorCodeOwnerUnavailable,
+			Message: fmt.Sprintf("invalid address of the owner replica %q", peer),
+		})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request().Context(), forwardTimeout)
+	defer cancel()
+	req := c.Request().WithContext(ctx)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+			r.Out.Header.Set(forwardedHeader, "1")
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Warn("Can't forward the request to the owner replica", zap.String("peer", peer), zap.Error(err))
+			_ = c.JSON(http.StatusBadGateway, gen.ErrorResponse{
+				Code:    gen.ErrorCodeOwnerUnavailable,
+				Message: "the replica owning the channel can't be reached, retry later",
+			})
+		},
+	}
+	proxy.ServeHTTP(c.Response(), req)
+}
+
+// forwardTimeout - how long a forwarded request may take on the owner replica
+const forwardTimeout = 10 * time.Second
+
+// Trace continues the trace of the producer carried by the W3C traceparent header, or starts a new one, with a
+// server span covering the request. The request logger is tagged with the trace and span ids, and the span
+// context is passed down to the service. A nil tracer leaves the request untraced.
+func Trace(tracer *tracing.Tracer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if tracer == nil {
+				return next(c)
+			}
+			req := c.Request()
+			ctx := req.Context()
+			logger := logging.FromContext(ctx, zap.NewNop())
+			if header := req.Header.Get(traceparentHeader); header != "" {
+				parent, err := tracing.ParseTraceparent(header)
+				if err != nil {
+					// an invalid traceparent is ignored, as the trace context specification requires
+					logger.Debug("Ignoring invalid traceparent", zap.Error(err))
+				} else {
+					ctx = tracing.WithSpanContext(ctx, parent)
+				}
+			}
+			ctx, span := tracer.Start(ctx, req.Method+" "+c.Path(), tracing.KindServer)
+			if span == nil {
+				// the producer doesn't sample the trace, the service must not start a new one either
+				c.SetRequest(req.WithContext(ctx))
+				return next(c)
+			}
+			span.SetAttribute("http.request.method", req.Method)
+			span.SetAttribute("http.route", c.Path())
+			logger = logger.With(zap.String("trace_id", span.Context.TraceID.String()), zap.String("span_id", span.Context.SpanID.String()))
+			c.SetRequest(req.WithContext(logging.WithLogger(ctx, logger)))
+
+			// the error is handled here so the span sees the status it was converted to
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			status := c.Response().Status
+			span.SetAttribute("http.response.status_code", status)
+			if status >= http.StatusInternalServerError {
+				span.Error = http.StatusText(status)
+			}
+			span.Finish()
+			return nil
+		}
+	}
+}
+
+// ReadAfterWrite honours "Prefer: read-after-write=<message number>" on routes with the rocket id parameter:
+// the request waits until the rocket processed at least that message, up to the "wait=<seconds>" preference
+// (1s by default, 10s at most). When satisfied, the response carries "Preference-Applied: read-after-write";
+// otherwise the current state is served as is. The number is the one of a message of the rocket's own channel,
+// so the consistency is per rocket: nothing orders the writes of different rockets.
+func ReadAfterWrite(rockets rocket.Service) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Add("Vary", "Prefer")
+			prefs := parsePrefer(c.Request().Header.Values("Prefer"))
+			v, ok := prefs[readAfterWrite]
+			if !ok {
+				return next(c)
+			}
+			id, err := uuid.Parse(c.Param("id"))
+			if err != nil {
+				return next(c)
+			}
+			number, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || number <= 0 {
+				return c.JSON(http.StatusBadRequest, gen.ErrorResponse{
+					Code:    gen.ErrorCodeInvalidPreference,
+					Message: fmt.Sprintf("%s must carry a positive message number, got %q", readAfterWrite, v),
+				})
+			}
+
+			wait := defaultReadAfterWriteWait
+			if seconds, err := strconv.Atoi(prefs["wait"]); err == nil && seconds >= 0 {
+				wait = min(time.Duration(seconds)*time.Second, maxReadAfterWriteWait)
+			}
+			if waitForMessage(c.Request().Context(), rockets, id, number, wait) {
+				c.Response().Header().Set("Preference-Applied", readAfterWrite)
+			} else {
+				warn(c.Request().Context(), fmt.Sprintf("message %d was not processed within %s, the current state is served", number, wait))
+			}
+			return next(c)
+		}
+	}
+}
+
+// waitForMessage polls the rocket until it processed the message, reporting false on timeout
+func waitForMessage(ctx context.Context, rockets rocket.Service, id uuid.UUID, number int64, wait time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	ticker := time.NewTicker(readAfterWritePoll)
+	defer ticker.Stop()
+	for {
+		if state, err := rockets.GetRocketState(ctx, id); err == nil && state.LastProcessedMessageNumber >= number {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// parsePrefer returns the preferences of Prefer header values (RFC 7240) by lower-cased name,
+// their parameters are ignored
+func parsePrefer(values []string) map[string]string {
+	prefs := make(map[string]string)
+	for _, value := range values {
+		for _, pref := range strings.Split(value, ",") {
+			pref, _, _ = strings.Cut(pref, ";")
+			name, v, _ := strings.Cut(pref, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			prefs[name] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	return prefs
+}
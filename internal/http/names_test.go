@@ -0,0 +1,65 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/names"
+	"rockets/internal/usage"
+	"testing"
+)
+
+func TestAPI_RocketNames(t *testing.T) {
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: goldenService(t),
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+		Names:  names.NewRegistry(),
+	})
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	launched := "193270a9-c9cf-404a-8f83-838e71d9ae67"
+	if rec := do(http.MethodPut, "/admin/rockets/"+launched+"/name", `{"name":"Artemis-1"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the name assigned, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPut, "/admin/rockets/7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30/name", `{"name":"artemis-1"}`); rec.Code != http.StatusConflict {
+		t.Errorf("Expected a name taken by another rocket rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/admin/rockets/"+launched+"/name", `{"name":"a b"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid name rejected, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodGet, "/v1/rockets/by-name/ARTEMIS-1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the rocket resolved by its name, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var state map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &state)
+	if state["id"] != launched || state["name"] != "Artemis-1" {
+		t.Errorf("Expected the named rocket, got %v", state)
+	}
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/rockets/"+launched, "").Body.Bytes(), &state)
+	if state["name"] != "Artemis-1" {
+		t.Errorf("Expected the name in the state, got %v", state)
+	}
+
+	if rec := do(http.MethodDelete, "/admin/rockets/"+launched+"/name", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the name removed, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/rockets/by-name/artemis-1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown name not found, got %d", rec.Code)
+	}
+}
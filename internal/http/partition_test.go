@@ -0,0 +1,186 @@
+package http
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/partition"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+)
+
+// partitionReplica - replica owning one of two partitions
+type partitionReplica struct {
+	owner *partition.Static
+	svc   rocket.Service
+	meter *usage.Meter
+	e     *echo.Echo
+}
+
+func newPartitionReplica(t *testing.T, owned int) *partitionReplica {
+	owner, err := partition.NewStatic(2, []int{owned})
+	if err != nil {
+		t.Fatalf("NewStatic failed: %v", err)
+	}
+	logger := zap.NewNop()
+	r := &partitionReplica{
+		owner: owner,
+		svc:   rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger),
+		meter: usage.NewMeter(usage.Quota{}),
+		e:     echo.New(),
+	}
+	NewServer(&ServerOpts{
+		Echo:       r.e,
+		Logger:     logger,
+		Rocket:     r.svc,
+		Keys:       auth.NewKeys(map[string]string{"acme-key": "acme"}),
+		Usage:      r.meter,
+		Partitions: owner,
+	})
+	return r
+}
+
+func (r *partitionReplica) do(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(apiKeyHeader, "acme-key")
+	rec := httptest.NewRecorder()
+	r.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPI_Partitions(t *testing.T) {
+	a, b := newPartitionReplica(t, 0), newPartitionReplica(t, 1)
+	var owned, foreign uuid.UUID
+	for owned == uuid.Nil || foreign == uuid.Nil {
+		id := uuid.New()
+		if a.owner.Owns(id) {
+			owned = id
+		} else {
+			foreign = id
+		}
+	}
+	launch := func(id uuid.UUID) string {
+		return fmt.Sprintf(`{"metadata":{"channel":"%s","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`, id)
+	}
+
+	if rec := a.do(t, http.MethodPost, "/messages", launch(owned)); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202 for an owned channel, got %d: %s", rec.Code, rec.Body)
+	}
+	// without the address of the owner the message is rejected
+	rec := a.do(t, http.MethodPost, "/messages", launch(foreign))
+	if rec.Code != http.StatusMisdirectedRequest || !strings.Contains(rec.Body.String(), "wrong_partition") {
+		t.Fatalf("Expected 421 wrong_partition for a foreign channel, got %d: %s", rec.Code, rec.Body)
+	}
+	if usages := a.meter.List(); len(usages) != 1 || usages[0].Messages != 1 {
+		t.Errorf("Expected only the owned message counted towards the quota, got %+v", usages)
+	}
+	if rec := a.do(t, http.MethodGet, "/v1/rockets/"+foreign.String(), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected reads not to be partitioned, got %d", rec.Code)
+	}
+
+	// with it the message is applied by the owner and its response returned
+	server := httptest.NewServer(b.e)
+	defer server.Close()
+	if err := a.owner.UsePeers(map[string]string{"1": server.URL}); err != nil {
+		t.Fatalf("UsePeers failed: %v", err)
+	}
+	rec = a.do(t, http.MethodGet, "/admin/partitions?channel="+foreign.String(), "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"owned":[0],"peers":{"1":"`+server.URL+`"}`) ||
+		!strings.Contains(rec.Body.String(), `"owned":false,"peer":"`+server.URL+`"`) {
+		t.Errorf("Expected the partitions and the owner of the channel, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := a.do(t, http.MethodPost, "/messages", launch(foreign)); rec.Code != http.StatusAccepted || !strings.Contains(rec.Body.String(), `"applied"`) {
+		t.Fatalf("Expected the message forwarded and applied, got %d: %s", rec.Code, rec.Body)
+	}
+	if _, err := b.svc.GetRocketState(t.Context(), foreign); err != nil {
+		t.Errorf("Expected the owner to apply the forwarded message: %v", err)
+	}
+	if _, err := a.svc.GetRocketState(t.Context(), foreign); err == nil {
+		t.Errorf("Expected the forwarding replica not to apply the message")
+	}
+	if rec := a.do(t, http.MethodPost, "/messages", launch(foreign)); rec.Code != http.StatusConflict {
+		t.Errorf("Expected the owner's 409 for a duplicate, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := a.do(t, http.MethodPut, "/v1/rockets/"+foreign.String(), `{"type":"Falcon-9","mission":"ARTEMIS"}`); rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+		t.Errorf("Expected the registration forwarded, got %d: %s", rec.Code, rec.Body)
+	}
+
+	// a forwarded request is not forwarded again, e.g. when the replicas disagree on the owner
+	if err := b.owner.UsePeers(map[string]string{"0": server.URL}); err != nil {
+		t.Fatalf("UsePeers failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(launch(owned)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(apiKeyHeader, "acme-key")
+	req.Header.Set(forwardedHeader, "1")
+	fwd := httptest.NewRecorder()
+	b.e.ServeHTTP(fwd, req)
+	if fwd.Code != http.StatusMisdirectedRequest {
+		t.Errorf("Expected 421 for a forwarded request of a foreign channel, got %d: %s", fwd.Code, fwd.Body)
+	}
+
+	server.Close()
+	if rec := a.do(t, http.MethodPost, "/messages", launch(foreign)); rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "owner_unavailable") {
+		t.Errorf("Expected 502 owner_unavailable when the owner is down, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestAPI_Handoff(t *testing.T) {
+	owner, err := partition.NewStatic(2, []int{0})
+	if err != nil {
+		t.Fatalf("NewStatic failed: %v", err)
+	}
+	logger := zap.NewNop()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	e := echo.New()
+	secret := []byte("ring secret")
+	NewServer(&ServerOpts{
+		Echo:          e,
+		Logger:        logger,
+		Rocket:        svc,
+		Keys:          auth.NewKeys(nil),
+		Usage:         usage.NewMeter(usage.Quota{}),
+		Partitions:    owner,
+		HandoffSecret: secret,
+	})
+	var owned, foreign uuid.UUID
+	for owned == uuid.Nil || foreign == uuid.Nil {
+		id := uuid.New()
+		if owner.Owns(id) {
+			owned = id
+		} else {
+			foreign = id
+		}
+	}
+	state := func(id uuid.UUID) string {
+		return fmt.Sprintf(`{"channel":"%s","state":{"id":"%s","type":"Falcon-9","currentSpeed":500,"mission":"ARTEMIS","status":"LAUNCHED","lastUpdateTime":"2022-02-02T19:39:05Z","lastProcessedMessageNumber":1,"version":1}}`, id, id)
+	}
+	body := "[" + state(owned) + "," + state(foreign) + "]"
+	post := func(signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/handoff", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(partition.HandoffSignatureHeader, signature)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post(partition.SignHandoff([]byte("other secret"), []byte(body))); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 for a handoff signed with another secret, got %d: %s", rec.Code, rec.Body)
+	}
+	rec := post(partition.SignHandoff(secret, []byte(body)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"states":1`) || !strings.Contains(rec.Body.String(), `"foreign":1`) {
+		t.Fatalf("Expected the owned state taken and the foreign one left out, got %d: %s", rec.Code, rec.Body)
+	}
+	if _, err := svc.GetRocketState(t.Context(), foreign); err == nil {
+		t.Errorf("Expected no state taken for a channel the replica doesn't own")
+	}
+}
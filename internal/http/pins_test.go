@@ -0,0 +1,61 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"testing"
+	"time"
+)
+
+func TestAPI_Pins(t *testing.T) {
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: goldenService(t),
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+		Pins:   rocket.NewPins(nil, []rocket.Mission{"VOYAGER"}, time.Now()),
+	})
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	channel := "193270a9-c9cf-404a-8f83-838e71d9ae67"
+	if rec := do(http.MethodPut, "/admin/pins/channels/"+channel, `{"reason":"deep space"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the channel pinned, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPut, "/admin/pins/channels/nope", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid channel rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/admin/pins/missions/PIONEER", `{"reason":"silent for weeks"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the mission pinned, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var pins []rocket.Pin
+	_ = json.Unmarshal(do(http.MethodGet, "/admin/pins", "").Body.Bytes(), &pins)
+	if len(pins) != 3 || pins[0].Mission != "VOYAGER" || !pins[0].Configured {
+		t.Fatalf("Expected the configured pin first and the two added ones, got %+v", pins)
+	}
+
+	if rec := do(http.MethodDelete, "/admin/pins/channels/"+channel, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the channel unpinned, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/admin/pins/channels/"+channel, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected an unpinned channel not found, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/admin/pins/missions/VOYAGER", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the configured mission unpinned, got %d", rec.Code)
+	}
+}
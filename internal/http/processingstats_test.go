@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"testing"
+	"time"
+)
+
+func TestAPI_ProcessingStats(t *testing.T) {
+	logger := zap.NewNop()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: logger,
+		Rocket: svc,
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+
+	id := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	at := time.Date(2022, 2, 2, 19, 39, 0, 0, time.UTC)
+	launch := rocket.TelemetryMessage{
+		Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: at, MessageType: rocket.MessageTypeLaunched},
+		Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+	}
+	for range 2 {
+		_, _ = svc.ProcessMessage(context.Background(), launch)
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/rockets/"+id.String()+"/processing-stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var stats gen.ProcessingStats
+	_ = json.Unmarshal(rec.Body.Bytes(), &stats)
+	if stats.Received != 2 || stats.Duplicates != 1 || stats.LastRejection != nil {
+		t.Errorf("Expected 2 received and 1 duplicate, got %+v", stats)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/rockets/"+uuid.NewString()+"/processing-stats", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown rocket, got %d", rec.Code)
+	}
+}
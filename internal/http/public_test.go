@@ -0,0 +1,85 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/usage"
+	"rockets/internal/watchlist"
+	"strings"
+	"testing"
+)
+
+func TestAPI_PublicMode(t *testing.T) {
+	e := echo.New()
+	watchlists := watchlist.NewRegistry()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: goldenService(t),
+		Keys:   auth.NewKeys(map[string]string{"ops-key": "ops"}),
+		Usage:  usage.NewMeter(usage.Quota{}),
+		Public: true,
+		Redact: []string{"reason", "lastProcessedMessageNumber"},
+
+		Watchlists: watchlists,
+	})
+	get := func(target, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	exploded := "/v1/rockets/7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30"
+	var state map[string]any
+	_ = json.Unmarshal(get(exploded, "").Body.Bytes(), &state)
+	if _, ok := state["reason"]; ok || state["lastProcessedMessageNumber"] != nil || state["status"] != "EXPLODED" {
+		t.Errorf("Expected the sensitive fields redacted for anonymous callers, got %v", state)
+	}
+	_ = json.Unmarshal(get(exploded, "ops-key").Body.Bytes(), &state)
+	if state["reason"] != "PRESSURE_VESSEL_FAILURE" || state["lastProcessedMessageNumber"] != float64(2) {
+		t.Errorf("Expected the full state for callers with a key, got %v", state)
+	}
+	var list []map[string]any
+	_ = json.Unmarshal(get("/v1/rockets", "").Body.Bytes(), &list)
+	for _, s := range list {
+		if _, ok := s["lastProcessedMessageNumber"]; ok {
+			t.Errorf("Expected the listing redacted for anonymous callers, got %v", s)
+		}
+	}
+	if len(list) != 3 {
+		t.Errorf("Expected 3 rockets listed, got %d", len(list))
+	}
+
+	id := uuid.MustParse("7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30")
+	if _, err := watchlists.Create(watchlist.Watchlist{Name: "exploded", Rockets: []uuid.UUID{id}}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/rockets/batch-get", strings.NewReader(`{"ids":["`+id.String()+`"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	batch := httptest.NewRecorder()
+	e.ServeHTTP(batch, req)
+	for target, body := range map[string]string{"/v1/rockets/batch-get": batch.Body.String(), "/v1/watchlists/exploded": get("/v1/watchlists/exploded", "").Body.String()} {
+		if !strings.Contains(body, `"EXPLODED"`) || strings.Contains(body, `"reason"`) || strings.Contains(body, "lastProcessedMessageNumber") {
+			t.Errorf("Expected the nested states of %s redacted for anonymous callers, got %s", target, body)
+		}
+	}
+
+	if rec := get("/v1/usage", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected usage denied to anonymous callers, got %d", rec.Code)
+	}
+	if rec := get("/v1/missions/GEMINI/report", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected mission reports denied to anonymous callers, got %d", rec.Code)
+	}
+	if rec := get("/v1/usage", "ops-key"); rec.Code != http.StatusOK {
+		t.Errorf("Expected usage served to callers with a key, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"testing"
+)
+
+func TestAPI_MessageRates(t *testing.T) {
+	rates := rocket.NewMessageRates()
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:      e,
+		Logger:    zap.NewNop(),
+		Rocket:    goldenService(t),
+		Keys:      auth.NewKeys(nil),
+		Usage:     usage.NewMeter(usage.Quota{}),
+		Rates:     rates,
+		NoisyRate: 60,
+	})
+	chatty := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	for range 100 {
+		rates.Count(chatty)
+	}
+	list := func(target string) []gen.RocketState {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var states []gen.RocketState
+		_ = json.Unmarshal(rec.Body.Bytes(), &states)
+		return states
+	}
+
+	all := list("/v1/rockets")
+	if len(all) != 3 {
+		t.Fatalf("Expected all rockets, got %d", len(all))
+	}
+	for _, s := range all {
+		if s.MessageRates == nil {
+			t.Fatalf("Expected the message rates of %s", s.Id)
+		}
+		if s.Id == chatty && s.MessageRates.OneMinute != 100 {
+			t.Errorf("Expected 100 messages per minute, got %+v", s.MessageRates)
+		}
+	}
+	if noisy := list("/v1/rockets?noisy=true"); len(noisy) != 1 || noisy[0].Id != chatty {
+		t.Errorf("Expected only the chatty rocket listed as noisy, got %+v", noisy)
+	}
+	if quiet := list("/v1/rockets?noisy=false"); len(quiet) != 3 {
+		t.Errorf("Expected noisy=false to list all rockets, got %d", len(quiet))
+	}
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+)
+
+func TestAPI_RegisterRocket(t *testing.T) {
+	e := echo.New()
+	registrations := rocket.NewRegistrations()
+	svc := goldenService(t)
+	svc.UseRegistrations(registrations)
+	keys := auth.NewKeys(map[string]string{"ops-key": "ops", "gemini-key": "gemini"})
+	keys.Restrict("gemini", auth.Scope{Missions: []string{"GEMINI"}})
+	NewServer(&ServerOpts{
+		Echo:          e,
+		Logger:        zap.NewNop(),
+		Rocket:        svc,
+		Keys:          keys,
+		Usage:         usage.NewMeter(usage.Quota{}),
+		Registrations: registrations,
+	})
+	registerAs := func(key, id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/v1/rockets/"+id, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	register := func(id, body string) *httptest.ResponseRecorder {
+		return registerAs("ops-key", id, body)
+	}
+
+	awaiting := "0b6b1a4e-5f0c-4d7e-9a3b-2c1d0e9f8a7b"
+	if rec := register(awaiting, `{"type": "Falcon-9", "mission": " artemis "}`); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := register(awaiting, `{"type": "Falcon-9", "mission": "ARTEMIS"}`); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 registering the same again, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := register(awaiting, `{"type": "Falcon-9", "mission": "ARTEMIS/2"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid mission, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// the launched rocket of the golden service flies a Falcon-9 on ARTEMIS
+	launched := "193270a9-c9cf-404a-8f83-838e71d9ae67"
+	if rec := register(launched, `{"type": "Soyuz", "mission": "ARTEMIS"}`); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for another type than the telemetry reports, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := register(launched, `{"type": "Falcon-9", "mission": "ARTEMIS"}`); rec.Code != http.StatusCreated {
+		t.Errorf("Expected 201 registering the launched rocket as it flies, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// a scoped key can't take over rockets outside its scope by naming its own mission
+	if rec := registerAs("gemini-key", launched, `{"type": "Falcon-9", "mission": "GEMINI"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 re-registering a rocket of another mission, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := registerAs("gemini-key", awaiting, `{"type": "Falcon-9", "mission": "GEMINI"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 re-registering a rocket registered on another mission, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if reg, _ := registrations.Get(uuid.MustParse(awaiting)); reg.Mission != "ARTEMIS" {
+		t.Errorf("Expected the registration kept, got %+v", reg)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/registrations", nil)
+	req.Header.Set(apiKeyHeader, "ops-key")
+	e.ServeHTTP(rec, req)
+	var listed []gen.Registration
+	_ = json.Unmarshal(rec.Body.Bytes(), &listed)
+	if len(listed) != 2 {
+		t.Fatalf("Expected 2 registrations, got %s", rec.Body.String())
+	}
+	first, second := listed[0], listed[1]
+	if first.Id.String() != awaiting || first.Mission != "ARTEMIS" || !first.AwaitingTelemetry {
+		t.Errorf("Expected the first rocket awaiting telemetry, got %+v", first)
+	}
+	if second.Id.String() != launched || second.AwaitingTelemetry {
+		t.Errorf("Expected the launched rocket not awaiting telemetry, got %+v", second)
+	}
+}
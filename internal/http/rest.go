@@ -2,51 +2,247 @@ package http
 
 import (
 	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"io/fs"
+	"rockets/internal/auth"
+	"rockets/internal/capture"
+	"rockets/internal/export"
+	"rockets/internal/fleet"
 	"rockets/internal/http/gen"
+	"rockets/internal/incident"
+	"rockets/internal/latency"
+	"rockets/internal/leader"
+	"rockets/internal/logging"
+	"rockets/internal/maintenance"
+	"rockets/internal/metrics"
+	"rockets/internal/names"
+	"rockets/internal/netacl"
+	"rockets/internal/partition"
+	"rockets/internal/report"
 	"rockets/internal/rocket"
+	"rockets/internal/tracing"
+	"rockets/internal/usage"
+	"rockets/internal/warehouse"
+	"rockets/internal/watchlist"
 )
 
 type ServerOpts struct {
-	Echo   *echo.Echo
-	Rocket rocket.Service
+	Echo     *echo.Echo
+	Logger   *zap.Logger
+	Rocket   rocket.Service
+	Missions *report.MissionReporter
+	// History - event history the speed history of the rockets is read from, nil leaves it empty
+	History rocket.HistoryStore
+	Feed    *report.Feed
+	Keys    *auth.Keys
+	// SigningKeys - public keys the payloads of the producers are verified with, nil verifies none
+	SigningKeys *auth.SigningKeys
+	Usage       *usage.Meter
+	ACL         *netacl.ACL
+	Capture     *capture.Recorder
+	Metrics     *metrics.Registry
+	// Leader - hot/standby election, nil when the instance is the only leader
+	Leader *leader.Elector
+	// Partitions - channel partitions the replica ingests, the others are forwarded to their owners; nil ingests
+	// every channel
+	Partitions partition.Owner
+	// HandoffSecret - secret the replicas of the ring sign their handoffs with, nil disables POST /admin/handoff
+	HandoffSecret []byte
+	// Tracer - records the spans of ingested messages, nil disables tracing
+	Tracer *tracing.Tracer
+	// Dashboard - assets of the dashboard served under /ui, nil disables it
+	Dashboard fs.FS
+	// Names - names of the rockets, resolvable through /v1/rockets/by-name and assigned through the admin API,
+	// nil disables naming
+	Names *names.Registry
+	// Collation - comparison of the types and missions the rockets are listed by, byte-wise when nil
+	Collation *rocket.Collation
+	// Rates - message rates of the rockets shown in their states, nil leaves them out
+	Rates *rocket.MessageRates
+	// NoisyRate - messages per minute over the last minute above which ?noisy=true lists a rocket
+	NoisyRate float64
+	// Latency - ingestion latency reported through /admin/latency, nil disables the endpoint
+	Latency *latency.Tracker
+	// Pins - channels and missions exempt from the eviction of quiet rockets, managed through the admin API; nil
+	// disables the endpoints
+	Pins *rocket.Pins
+	// Registrations - rockets registered through PUT /v1/rockets/{id} and listed by GET /v1/registrations, the
+	// registry the service registers them in (see rocket.ServiceImpl.UseRegistrations); nil lists none
+	Registrations *rocket.Registrations
+	// Cache - micro-cache of the rocket listing and the fleet stats, nil neither caches them nor sets Cache-Control
+	Cache *MicroCache
+	// Snapshot - periodically refreshed copy of the rockets /v1/rockets is served from, nil lists the live store
+	Snapshot *rocket.Snapshot
+	// Fleets - groups of rockets listed with their stats under /v1/fleets and managed through the admin API,
+	// nil disables fleets
+	Fleets *fleet.Registry
+	// Watchlists - lists of rockets whose states are read at once under /v1/watchlists, managed through the admin
+	// API; nil disables watchlists
+	Watchlists *watchlist.Registry
+	// Incidents - incident records of the exploded rockets managed through the admin API, nil disables the
+	// endpoints
+	Incidents *incident.Registry
+	// Maintenance - maintenance windows managed through the admin API, nil disables the endpoints
+	Maintenance *maintenance.Registry
+	// Suppressor - notifier recording the alerts suppressed by the maintenance windows, listed through the admin
+	// API; nil lists none
+	Suppressor *maintenance.Suppressor
+	// Export - Parquet export of the event history triggered through /admin/export, nil disables the endpoint
+	Export *export.Exporter
+	// Sink - warehouse sink whose progress and load errors are reported through /admin/warehouse, nil disables
+	// the endpoint
+	Sink *warehouse.Sink
+	// Levels - log levels adjustable through /admin/log-level, nil disables the endpoint
+	Levels *logging.Levels
+	// AuthReads - the read API routes require an API key like message ingestion, otherwise a key is optional
+	// and only narrows the reads to its scope
+	AuthReads bool
+	// Public - public read-only mode: callers without an API key read rockets with the Redact fields removed
+	// and are denied usage and mission reports
+	Public bool
+	Redact []string
+	// MaxBodyBytes - largest request body accepted once decompressed, 0 leaves it unchecked
+	MaxBodyBytes int64
+	// BasePath - prefix of the API routes and the dashboard, e.g. /rockets behind a shared ingress, empty
+	// serves them at the root
+	BasePath string
+}
+
+// RouteMiddlewares - middlewares applied per group of API routes
+type RouteMiddlewares struct {
+	// Read - middlewares of the read API routes (GET, HEAD, OPTIONS and the POST routes that only read)
+	Read []echo.MiddlewareFunc
+	// Ingest - middlewares of the other API routes, i.e. message ingestion
+	Ingest []echo.MiddlewareFunc
 }
 
 // NewServer creates a new HTTP server with the provided options and attaches the API routes.
 func NewServer(opts *ServerOpts) (*StrictServer, *echo.Echo) {
 	api := NewStrictServer(opts)
+	opts.Echo.Use(Decompress(opts.MaxBodyBytes))
+	// requests are captured only once the ACL and the authentication let them through
+	record := Capture(opts.Capture)
 
+	read := AccessControl(opts.ACL, netacl.GroupAPI)
+	identify := Identify(opts.Keys)
+	if opts.AuthReads {
+		identify = Authenticate(opts.Keys)
+	}
+	var redact []string
+	if opts.Public {
+		redact = opts.Redact
+	}
 	AttachHttpAPIRoutes(
 		opts.Echo,
 		gen.NewStrictHandler(api, nil),
+		opts.BasePath,
+		RouteMiddlewares{
+			Read:   []echo.MiddlewareFunc{EnvelopeResponses(), LocalizeErrors(), read, identify, record, Redact(redact), ReadAfterWrite(opts.Rocket), Cache(opts.Cache, opts.BasePath)},
+			Ingest: []echo.MiddlewareFunc{EnvelopeResponses(), LocalizeErrors(), Trace(opts.Tracer), AccessControl(opts.ACL, netacl.GroupIngest), LeaderOnly(opts.Leader), Authenticate(opts.Keys), record, Partition(opts.Partitions, opts.Logger), Quota(opts.Usage), VerifySignature(opts.SigningKeys, opts.Logger)},
+		},
 	)
+	AttachAdminRoutes(
+		adminRoutes{
+			router: opts.Echo.Group("/admin", LocalizeErrors(), AccessControl(opts.ACL, netacl.GroupAdmin), record),
+			leader: LeaderOnly(opts.Leader),
+		},
+		NewAdminServer(opts),
+	)
+	AttachSchemas(opts.Echo, opts.BasePath, LocalizeErrors(), read)
+	if opts.Feed != nil {
+		opts.Echo.GET("/status", StatusPage(opts.Rocket, opts.Feed), read)
+	}
+	if opts.Dashboard != nil {
+		AttachDashboard(opts.Echo, opts.BasePath, opts.Dashboard, read)
+	}
+	if opts.Metrics != nil {
+		opts.Echo.GET("/metrics", echo.WrapHandler(opts.Metrics.Handler()), AccessControl(opts.ACL, netacl.GroupAdmin))
+	}
 
 	return api, opts.Echo
 }
 
 func NewStrictServer(opts *ServerOpts) *StrictServer {
+	registrations := opts.Registrations
+	if registrations == nil {
+		registrations = rocket.NewRegistrations()
+	}
 	return &StrictServer{
-		rocket: opts.Rocket,
+		rocket:    opts.Rocket,
+		missions:  opts.Missions,
+		history:   opts.History,
+		usage:     opts.Usage,
+		logger:    opts.Logger,
+		public:    opts.Public,
+		names:     opts.Names,
+		fleets:    opts.Fleets,
+		collation: opts.Collation,
+		rates:     opts.Rates,
+		noisyRate: opts.NoisyRate,
+		snapshot:  opts.Snapshot,
+
+		watchlists:    opts.Watchlists,
+		registrations: registrations,
+		capabilities:  capabilities(opts),
+	}
+}
+
+// capabilities describes the features the options enable and the limits they set. Messages are ingested one per
+// request and state changes are not streamed, clients poll the rockets.
+func capabilities(opts *ServerOpts) gen.Capabilities {
+	c := gen.Capabilities{
+		MaxBatchSize:       1,
+		MaxBodyBytes:       opts.MaxBodyBytes,
+		IngestFormats:      []string{echo.MIMEApplicationJSON},
+		ContentEncodings:   []string{"gzip", "deflate"},
+		ReportFormats:      []string{},
+		Features:           []string{},
+		AuthenticatedReads: opts.AuthReads,
+		Public:             opts.Public,
 	}
+	if opts.Names != nil {
+		c.Features = append(c.Features, "names")
+	}
+	if opts.Fleets != nil {
+		c.Features = append(c.Features, "fleets")
+	}
+	if opts.Watchlists != nil {
+		c.Features = append(c.Features, "watchlists")
+	}
+	if opts.Rates != nil {
+		c.Features = append(c.Features, "messageRates")
+	}
+	if opts.History != nil {
+		c.Features = append(c.Features, "speedHistory")
+	}
+	if opts.SigningKeys != nil {
+		c.Features = append(c.Features, "signatures")
+	}
+	if opts.Partitions != nil {
+		c.Features = append(c.Features, "partitions")
+	}
+	if opts.Missions != nil {
+		c.Features = append(c.Features, "missionReports")
+		c.ReportFormats = []string{string(gen.Html), string(gen.Pdf)}
+	}
+	if opts.Usage != nil {
+		quota := opts.Usage.Quota()
+		if quota.Messages > 0 {
+			c.RateLimits.DailyMessages = &quota.Messages
+		}
+		if quota.Bytes > 0 {
+			c.RateLimits.DailyBytes = &quota.Bytes
+		}
+	}
+	return c
 }
 
 type ServerInterfaceWrapper struct {
 	Handler gen.ServerInterface
 }
 
-// AttachHttpAPIRoutes attaches the HTTP API routes to the provided Echo router.
-func AttachHttpAPIRoutes(router gen.EchoRouter, si gen.ServerInterface) {
-	hnd := gen.ServerInterfaceWrapper{Handler: si}
-	router.GET(
-		"/v1/rockets",
-		hnd.ListRockets,
-	)
-	router.GET(
-		"/v1/rockets/:id",
-		hnd.GetRocketState,
-	)
-
-	router.POST(
-		"/messages",
-		hnd.IngestMessage,
-	)
+// AttachHttpAPIRoutes attaches the HTTP API routes of the OpenAPI spec under the base path to the provided
+// Echo router, with the read middlewares on the GET routes and the read POST ones, the ingest ones on the others.
+func AttachHttpAPIRoutes(router gen.EchoRouter, si gen.ServerInterface, basePath string, mw RouteMiddlewares) {
+	gen.RegisterHandlersWithBaseURL(routeGroups{router: router, mw: mw, basePath: basePath, reads: readPosts()}, si, basePath)
 }
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+	"rockets/internal/http/gen"
+	"slices"
+	"strings"
+)
+
+var _ gen.EchoRouter = routeGroups{}
+
+// readExtension - extension of the spec marking the POST operations that only read, taking their input in the
+// body since it doesn't fit a query string
+const readExtension = "x-rockets-read"
+
+// readPosts returns the paths of the POST operations of the spec marked with readExtension. A spec that can't be
+// loaded marks none, so the POST routes fall back to the ingest middlewares rather than skipping them.
+func readPosts() map[string]bool {
+	reads := map[string]bool{}
+	spec, err := gen.GetSwagger()
+	if err != nil {
+		return reads
+	}
+	for path, item := range spec.Paths.Map() {
+		if item.Post != nil && item.Post.Extensions[readExtension] == true {
+			reads[echoPath(path)] = true
+		}
+	}
+	return reads
+}
+
+// routeGroups - router applying the middlewares of the route group to every route registered through it:
+// the read middlewares to safe methods and the read POST routes, the ingest ones to all others. It lets the
+// generated RegisterHandlers register the API routes, so they can't drift from the OpenAPI spec.
+type routeGroups struct {
+	router   gen.EchoRouter
+	mw       RouteMiddlewares
+	basePath string
+	// reads - paths of the read POST routes, without the base path
+	reads map[string]bool
+}
+
+func (r routeGroups) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.CONNECT(path, h, slices.Concat(r.mw.Ingest, m)...)
+}
+
+func (r routeGroups) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.DELETE(path, h, slices.Concat(r.mw.Ingest, m)...)
+}
+
+func (r routeGroups) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.GET(path, h, slices.Concat(r.mw.Read, m)...)
+}
+
+func (r routeGroups) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.HEAD(path, h, slices.Concat(r.mw.Read, m)...)
+}
+
+func (r routeGroups) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.OPTIONS(path, h, slices.Concat(r.mw.Read, m)...)
+}
+
+func (r routeGroups) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.PATCH(path, h, slices.Concat(r.mw.Ingest, m)...)
+}
+
+func (r routeGroups) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	if r.reads[strings.TrimPrefix(path, r.basePath)] {
+		return r.router.POST(path, h, slices.Concat(r.mw.Read, m)...)
+	}
+	return r.router.POST(path, h, slices.Concat(r.mw.Ingest, m)...)
+}
+
+func (r routeGroups) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.PUT(path, h, slices.Concat(r.mw.Ingest, m)...)
+}
+
+func (r routeGroups) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.TRACE(path, h, slices.Concat(r.mw.Ingest, m)...)
+}
+
+// RouteInfo - route served by the server
+type RouteInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Operation - operation of the route in the OpenAPI spec, empty for routes outside the public API
+	Operation string `json:"operation,omitempty"`
+}
+
+// specOperations returns the operations of the spec by route ("GET /v1/rockets/:id"), with the paths
+// prefixed by the base path
+func specOperations(basePath string) (map[string]string, error) {
+	spec, err := gen.GetSwagger()
+	if err != nil {
+		return nil, err
+	}
+	ops := map[string]string{}
+	for path, item := range spec.Paths.Map() {
+		for method, op := range item.Operations() {
+			ops[method+" "+basePath+echoPath(path)] = op.OperationID
+		}
+	}
+	return ops, nil
+}
+
+// echoPath converts the path parameters of an OpenAPI path ({id}) to the echo syntax (:id)
+func echoPath(path string) string {
+	return strings.NewReplacer("{", ":", "}", "").Replace(path)
+}
+
+// routeInventory lists the routes of the server sorted by path and method, with the operations of the API routes
+func routeInventory(routes []*echo.Route, ops map[string]string) []RouteInfo {
+	out := make([]RouteInfo, 0, len(routes))
+	for _, r := range routes {
+		// routes answering unmatched paths of groups, not served as such
+		if r.Method == echo.RouteNotFound {
+			continue
+		}
+		out = append(out, RouteInfo{Method: r.Method, Path: r.Path, Operation: ops[r.Method+" "+r.Path]})
+	}
+	slices.SortFunc(out, func(a, b RouteInfo) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+	return out
+}
+
+var _ gen.EchoRouter = adminRoutes{}
+
+// instanceAdminRoutes - admin routes changing only the instance they are sent to, which a standby serves as well
+var instanceAdminRoutes = []string{"/debug-traces/:id", "/captures", "/log-level"}
+
+// adminRoutes - router putting the admin routes changing the shared state behind the leader middleware: all but
+// the safe methods and the instance routes, so a standby doesn't write the rockets, the history or the registry
+// files behind the leader's back
+type adminRoutes struct {
+	router gen.EchoRouter
+	leader echo.MiddlewareFunc
+}
+
+// mutating returns the middlewares of a route changing the shared state unless it is an instance route
+func (r adminRoutes) mutating(path string, m []echo.MiddlewareFunc) []echo.MiddlewareFunc {
+	if slices.Contains(instanceAdminRoutes, path) {
+		return m
+	}
+	return slices.Concat([]echo.MiddlewareFunc{r.leader}, m)
+}
+
+func (r adminRoutes) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.CONNECT(path, h, r.mutating(path, m)...)
+}
+
+func (r adminRoutes) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.DELETE(path, h, r.mutating(path, m)...)
+}
+
+func (r adminRoutes) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.GET(path, h, m...)
+}
+
+func (r adminRoutes) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.HEAD(path, h, m...)
+}
+
+func (r adminRoutes) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.OPTIONS(path, h, m...)
+}
+
+func (r adminRoutes) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.PATCH(path, h, r.mutating(path, m)...)
+}
+
+func (r adminRoutes) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.POST(path, h, r.mutating(path, m)...)
+}
+
+func (r adminRoutes) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.PUT(path, h, r.mutating(path, m)...)
+}
+
+func (r adminRoutes) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.router.TRACE(path, h, r.mutating(path, m)...)
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"rockets/internal/auth"
+	"rockets/internal/leader"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPI_RoutesFollowSpec(t *testing.T) {
+	logger := zap.NewNop()
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:     e,
+		Logger:   logger,
+		Rocket:   rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger),
+		Keys:     auth.NewKeys(map[string]string{"secret": "tenant"}),
+		Usage:    usage.NewMeter(usage.Quota{}),
+		BasePath: "/rockets",
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/routes", nil))
+	var routes []RouteInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &routes); err != nil {
+		t.Fatalf("Can't decode routes: %v", err)
+	}
+	served := map[string]bool{}
+	for _, r := range routes {
+		if r.Operation != "" {
+			served[r.Operation] = true
+			if !strings.HasPrefix(r.Path, "/rockets/") {
+				t.Errorf("Expected the API route %s %s under the base path", r.Method, r.Path)
+			}
+		}
+	}
+	ops, err := specOperations("/rockets")
+	if err != nil {
+		t.Fatalf("Can't load the spec: %v", err)
+	}
+	for route, op := range ops {
+		if !served[op] {
+			t.Errorf("Expected the operation %s (%s) of the spec to be served", op, route)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rockets/messages", strings.NewReader("{}")))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the ingest middlewares on the POST route, got %d", rec.Code)
+	}
+	// the POST marked as a read in the spec gets the read middlewares, which don't require a key
+	if !readPosts()["/v1/rockets/batch-get"] {
+		t.Errorf("Expected batch-get marked as a read POST, got %v", readPosts())
+	}
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/rockets/v1/rockets/batch-get", strings.NewReader(`{"ids": []}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	e.ServeHTTP(rec, req)
+	if rec.Code == http.StatusUnauthorized {
+		t.Errorf("Expected the read middlewares on the read POST route, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/rockets", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected no API route outside the base path, got %d", rec.Code)
+	}
+}
+
+func TestAPI_AdminRoutesOnStandby(t *testing.T) {
+	logger := zap.NewNop()
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: logger,
+		Rocket: rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger),
+		Keys:   auth.NewKeys(nil),
+		Usage:  usage.NewMeter(usage.Quota{}),
+		// never run, the instance stays a standby
+		Leader: leader.New(filepath.Join(t.TempDir(), "leader.lock"), time.Second, logger),
+	})
+
+	for _, tt := range []struct {
+		method, target string
+		code           int
+	}{
+		{http.MethodPost, "/admin/rockets/193270a9-c9cf-404a-8f83-838e71d9ae67/rollback", http.StatusServiceUnavailable},
+		{http.MethodPost, "/admin/rockets/193270a9-c9cf-404a-8f83-838e71d9ae67/merge", http.StatusServiceUnavailable},
+		{http.MethodPut, "/admin/quarantine/193270a9-c9cf-404a-8f83-838e71d9ae67", http.StatusServiceUnavailable},
+		{http.MethodPost, "/admin/consistency-check", http.StatusServiceUnavailable},
+		{http.MethodGet, "/admin/quarantine", http.StatusOK},
+		{http.MethodDelete, "/admin/captures", http.StatusNoContent},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader("{}"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		e.ServeHTTP(rec, req)
+		if rec.Code != tt.code {
+			t.Errorf("Expected %d for %s %s on a standby, got %d: %s", tt.code, tt.method, tt.target, rec.Code, rec.Body.String())
+		}
+	}
+}
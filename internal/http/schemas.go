@@ -0,0 +1,49 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"rockets/internal/apischema"
+	"rockets/internal/http/gen"
+	"strings"
+)
+
+// schemaContentType - media type of the served JSON Schemas
+const schemaContentType = "application/schema+json"
+
+// AttachSchemas serves the JSON Schemas of the wire types under /schemas of the base path: the list of the schema
+// files at /schemas, and each at /schemas/<Type>.json, named as the types of the OpenAPI spec.
+func AttachSchemas(e *echo.Echo, basePath string, m ...echo.MiddlewareFunc) {
+	e.GET(basePath+"/schemas", func(c echo.Context) error {
+		schemas, err := apischema.Schemas()
+		if err != nil {
+			return err
+		}
+		names := apischema.Names(schemas)
+		files := make([]string, len(names))
+		for i, name := range names {
+			files[i] = name + ".json"
+		}
+		return c.JSON(http.StatusOK, files)
+	}, m...)
+	e.GET(basePath+"/schemas/:file", func(c echo.Context) error {
+		schemas, err := apischema.Schemas()
+		if err != nil {
+			return err
+		}
+		file := c.Param("file")
+		schema, ok := schemas[strings.TrimSuffix(file, ".json")]
+		if !ok || !strings.HasSuffix(file, ".json") {
+			return c.JSON(http.StatusNotFound, gen.ErrorResponse{
+				Code:    gen.ErrorCodeNotFound,
+				Message: "schema " + file + " not found",
+			})
+		}
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, schemaContentType, data)
+	}, m...)
+}
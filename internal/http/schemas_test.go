@@ -0,0 +1,49 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/usage"
+	"slices"
+	"testing"
+)
+
+func TestAPI_Schemas(t *testing.T) {
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: zap.NewNop(),
+		Rocket: goldenService(t),
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	var files []string
+	_ = json.Unmarshal(get("/schemas").Body.Bytes(), &files)
+	if !slices.Contains(files, "RocketState.json") || !slices.IsSorted(files) {
+		t.Errorf("Expected the sorted schema files, got %v", files)
+	}
+
+	rec := get("/schemas/RocketState.json")
+	if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentType) != schemaContentType {
+		t.Fatalf("Expected the schema, got %d %s", rec.Code, rec.Header().Get(echo.HeaderContentType))
+	}
+	var schema map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &schema)
+	if schema["title"] != "RocketState" || schema["type"] != "object" {
+		t.Errorf("Expected the RocketState schema, got %v", schema)
+	}
+
+	for _, target := range []string{"/schemas/Unknown.json", "/schemas/RocketState"} {
+		if rec := get(target); rec.Code != http.StatusNotFound {
+			t.Errorf("Expected %s not found, got %d", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+)
+
+func TestAPI_Scopes(t *testing.T) {
+	logger := zap.NewNop()
+	keys := auth.NewKeys(map[string]string{"acme-key": "acme", "ops-key": "ops"})
+	keys.Restrict("acme", auth.Scope{Missions: []string{"ARTEMIS"}})
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:   e,
+		Logger: logger,
+		Rocket: rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger),
+		Keys:   keys,
+		Usage:  usage.NewMeter(usage.Quota{}),
+	})
+	do := func(method, target, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	launch := func(channel, mission string) string {
+		return `{"metadata":{"channel":"` + channel + `","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},` +
+			`"message":{"type":"Falcon-9","launchSpeed":500,"mission":"` + mission + `"}}`
+	}
+	own, other := "193270a9-c9cf-404a-8f83-838e71d9ae67", "7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30"
+
+	if rec := do(http.MethodPost, "/messages", "acme-key", launch(own, "ARTEMIS")); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected a launch on the own mission to be accepted, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPost, "/messages", "acme-key", launch(other, "GEMINI")); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a launch on another mission, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodPost, "/messages", "ops-key", launch(other, "GEMINI")); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected an unscoped key to ingest any rocket, got %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec := do(http.MethodGet, "/v1/rockets/"+other, "acme-key", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 reading a rocket outside the scope, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/missions/GEMINI/report", "acme-key", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for the report of a mission outside the scope, got %d", rec.Code)
+	}
+	var listed []map[string]any
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/rockets", "acme-key", "").Body.Bytes(), &listed)
+	if len(listed) != 1 || listed[0]["id"] != own {
+		t.Errorf("Expected only the own rocket listed, got %v", listed)
+	}
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/rockets", "", "").Body.Bytes(), &listed)
+	if len(listed) != 2 {
+		t.Errorf("Expected anonymous reads unscoped without ROCKETS_AUTH_READS, got %v", listed)
+	}
+	if rec := do(http.MethodGet, "/v1/rockets", "unknown-key", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown key on reads, got %d", rec.Code)
+	}
+}
@@ -1,40 +1,41 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/rs/zerolog/log"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"go.uber.org/zap"
 	"net/http"
+	"rockets/internal/auth"
+	"rockets/internal/buildinfo"
+	"rockets/internal/fleet"
 	"rockets/internal/http/gen"
+	"rockets/internal/logging"
+	"rockets/internal/metrics"
+	"rockets/internal/names"
+	"rockets/internal/notify"
+	"rockets/internal/report"
 	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"rockets/internal/watchlist"
+	"slices"
+	"strings"
 	"time"
 )
 
-// ListenEchoServer starts the Echo server and listens on the specified address.
-func ListenEchoServer(_ context.Context, e *echo.Echo, addr string) func() error {
-	return func() error {
-		log.Info().Msgf("Listening http server on %s", addr)
-		err := e.Start(addr)
-		if !errors.Is(err, http.ErrServerClosed) {
-			return fmt.Errorf("can't listen http server on %s: %w", addr, err)
-		}
-		log.Info().Msgf("Http server stopped listening")
-
-		return nil
-	}
-}
-
 // ShutDownEchoServer gracefully shuts down the Echo server when the context is done.
-func ShutDownEchoServer(ctx context.Context, echo *echo.Echo) func() error {
+func ShutDownEchoServer(ctx context.Context, echo *echo.Echo, logger *zap.Logger) func() error {
 	return func() error {
 		<-ctx.Done()
-		log.Info().Msgf("Shutting down http server on %s...", echo.Server.Addr)
+		logger.Info("Shutting down http server")
 
-		// stop the http
-		httpCtx, httpCancel := context.WithTimeout(ctx, time.Second*10)
+		// stop the http, letting the requests in flight finish although the context is done
+		httpCtx, httpCancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second*10)
 		defer httpCancel()
 		if err := echo.Shutdown(httpCtx); err != nil {
 			return fmt.Errorf("can't shutdown http server: %w", err)
@@ -45,11 +46,17 @@ func ShutDownEchoServer(ctx context.Context, echo *echo.Echo) func() error {
 }
 
 // NewEcho creates a new Echo instance with the necessary middleware and routes.
-func NewEcho() *echo.Echo {
+// Panics are counted in the registry and alerted through the notifier, which may be nil.
+func NewEcho(logger *zap.Logger, registry *metrics.Registry, notifier notify.Notifier) *echo.Echo {
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Server.ConnContext = markUnixSocket
 	e.Use(middleware.CORS())
-	e.Use(middleware.Recover())
-	e.Use(middleware.Logger())
+	e.Use(middleware.RequestID())
+	e.Use(VersionHeader(buildinfo.Get().Version))
+	e.Use(RequestLogger(logger))
+	e.Use(Recover(logger, registry.Counter("rockets_http_panics_total", "Panics recovered by the HTTP server.", "route"), notifier))
 	e.GET("/ready", func(c echo.Context) error {
 		return c.NoContent(http.StatusOK)
 	})
@@ -68,118 +75,889 @@ func NewEcho() *echo.Echo {
 
 // StrictServer implements the gen.StrictServerInterface for handling API requests.
 type StrictServer struct {
-	echo   *echo.Echo
-	rocket rocket.Service
+	echo     *echo.Echo
+	rocket   rocket.Service
+	missions *report.MissionReporter
+	history  rocket.HistoryStore
+	usage    *usage.Meter
+	logger   *zap.Logger
+	// public - callers without an API key only read rocket states
+	public bool
+	names  *names.Registry
+	fleets *fleet.Registry
+	// collation - comparison of the types and missions the rockets are listed by
+	collation     *rocket.Collation
+	rates         *rocket.MessageRates
+	noisyRate     float64
+	registrations *rocket.Registrations
+	// snapshot - periodically refreshed copy of the rockets listings are served from, nil lists the live store
+	snapshot *rocket.Snapshot
+	// watchlists - lists of rockets whose states are read at once, nil has none
+	watchlists *watchlist.Registry
+	// capabilities - what the instance was configured with, described by /v1/capabilities
+	capabilities gen.Capabilities
 }
 
 var _ gen.StrictServerInterface = (*StrictServer)(nil)
 
 func (s *StrictServer) IngestMessage(ctx context.Context, request gen.IngestMessageRequestObject) (gen.IngestMessageResponseObject, error) {
-	var msgType rocket.MessageType
-
-	switch request.Body.Metadata.MessageType {
-	case gen.RocketExploded:
-		msgType = rocket.MessageTypeExploded
-	case gen.RocketLaunched:
-		msgType = rocket.MessageTypeLaunched
-	case gen.RocketSpeedIncreased:
-		msgType = rocket.MessageTypeSpeedIncreased
-	case gen.RocketSpeedDecreased:
-		msgType = rocket.MessageTypeSpeedDecreased
-	case gen.RocketMissionChanged:
-		msgType = rocket.MessageTypeMissionChanged
-	default:
+	msgType, ok := messageTypeToDomain(request.Body.Metadata.MessageType)
+	if !ok {
 		return gen.IngestMessage400JSONResponse{
-			Code:    "unknown_message_type",
+			Code:    gen.ErrorCodeUnknownMessageType,
 			Message: fmt.Sprintf("unknown message type: %s", request.Body.Metadata.MessageType),
 		}, nil
 	}
 
+	payload, err := messageToDomain(request.Body.Message)
+	if err != nil {
+		return gen.IngestMessage400JSONResponse{
+			Code:    gen.ErrorCodeInvalidMessage,
+			Message: err.Error(),
+		}, nil
+	}
 	msg := rocket.TelemetryMessage{
 		Metadata: rocket.MessageMetadata{
 			Channel:       request.Body.Metadata.Channel,
 			MessageNumber: request.Body.Metadata.MessageNumber,
 			MessageTime:   request.Body.Metadata.MessageTime,
 			MessageType:   msgType,
+			Signature:     signatureFromContext(ctx),
+			Producer:      auth.FromContext(ctx).Producer,
 		},
-		Message: rocket.Message{
-			By:          request.Body.Message.By,
-			LaunchSpeed: request.Body.Message.LaunchSpeed,
-			Mission:     request.Body.Message.Mission,
-			NewMission:  request.Body.Message.NewMission,
-			Reason:      request.Body.Message.Reason,
-			Type:        request.Body.Message.Type,
-		},
+		Message: payload,
 	}
 
-	err := s.rocket.ProcessMessage(ctx, msg)
+	allowed, err := s.ingestAllowed(ctx, msg)
 	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Can't check the scope of the message", zap.Error(err))
 		return gen.IngestMessage500JSONResponse{
-			Code:    "unknown",
+			Code:    gen.ErrorCodeUnknown,
 			Message: err.Error(),
 		}, nil
 	}
+	if !allowed {
+		return gen.IngestMessage403JSONResponse{
+			Code:    gen.ErrorCodeForbidden,
+			Message: fmt.Sprintf("rocket %s is outside the scope of producer %s", msg.Metadata.Channel, auth.FromContext(ctx).Producer),
+		}, nil
+	}
+
+	if request.Params.DryRun != nil && *request.Params.DryRun {
+		result, err := s.rocket.DryRunMessage(ctx, msg)
+		if errors.Is(err, rocket.ErrInvalidMessage) {
+			return gen.IngestMessage400JSONResponse{
+				Code:    gen.ErrorCodeInvalidMessage,
+				Message: err.Error(),
+			}, nil
+		}
+		if errors.Is(err, rocket.ErrSequenceGap) {
+			return gen.IngestMessage409JSONResponse{
+				Code:    gen.ErrorCodeSequenceGap,
+				Message: err.Error(),
+			}, nil
+		}
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Error("Can't dry run message", zap.Error(err))
+			return gen.IngestMessage500JSONResponse{
+				Code:    gen.ErrorCodeUnknown,
+				Message: err.Error(),
+			}, nil
+		}
+		return gen.IngestMessage200JSONResponse(dryRunToServer(result)), nil
+	}
 
-	return gen.IngestMessage202JSONResponse{}, nil
+	result, err := s.rocket.ProcessMessage(ctx, msg)
+	if errors.Is(err, rocket.ErrInvalidMessage) {
+		return gen.IngestMessage400JSONResponse{
+			Code:    gen.ErrorCodeInvalidMessage,
+			Message: err.Error(),
+		}, nil
+	}
+	if errors.Is(err, rocket.ErrChannelMoved) {
+		return gen.IngestMessage421JSONResponse{
+			Code:    gen.ErrorCodeWrongPartition,
+			Message: err.Error(),
+		}, nil
+	}
+	if errors.Is(err, rocket.ErrSequenceGap) {
+		return gen.IngestMessage409JSONResponse{
+			Code:    gen.ErrorCodeSequenceGap,
+			Message: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Can't process message", zap.Error(err))
+		return gen.IngestMessage500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+
+	if result.Outcome == rocket.OutcomeDuplicate && !lenient(request.Params.Prefer) {
+		return gen.IngestMessage409JSONResponse{
+			Code:    gen.ErrorCodeDuplicateMessage,
+			Message: strings.Join(result.Warnings, "; "),
+		}, nil
+	}
+
+	for _, w := range result.Warnings {
+		warn(ctx, w)
+	}
+	return gen.IngestMessage202JSONResponse(resultToServer(result)), nil
 }
 
-func (s *StrictServer) ListRockets(ctx context.Context, request gen.ListRocketsRequestObject) (gen.ListRocketsResponseObject, error) {
-	var rockets []gen.RocketState
+// maxBackfill - most historical messages merged by one back-fill request
+const maxBackfill = 10000
 
-	var sortBy string
-	if request.Params.SortBy != nil {
-		switch *request.Params.SortBy {
-		case gen.Id:
-			sortBy = "id"
-		case gen.Mission:
-			sortBy = "mission"
-		case gen.Speed:
-			sortBy = "speed"
-		case gen.Type:
-			sortBy = "type"
-		default:
-			return gen.ListRockets400JSONResponse{
-				Code:    "unknown_sort_by",
-				Message: fmt.Sprintf("unknown sort by: %s", *request.Params.SortBy),
+func (s *StrictServer) BackfillHistory(ctx context.Context, request gen.BackfillHistoryRequestObject) (gen.BackfillHistoryResponseObject, error) {
+	if n := len(request.Body.Messages); n == 0 || n > maxBackfill {
+		return gen.BackfillHistory400JSONResponse{
+			Code:    gen.ErrorCodeInvalidBatch,
+			Message: fmt.Sprintf("the batch must hold between 1 and %d messages, got %d", maxBackfill, n),
+		}, nil
+	}
+	messages := make([]rocket.TelemetryMessage, 0, len(request.Body.Messages))
+	for _, m := range request.Body.Messages {
+		msgType, ok := messageTypeToDomain(m.Metadata.MessageType)
+		if !ok {
+			return gen.BackfillHistory400JSONResponse{
+				Code:    gen.ErrorCodeUnknownMessageType,
+				Message: fmt.Sprintf("message %d: unknown message type: %s", m.Metadata.MessageNumber, m.Metadata.MessageType),
 			}, nil
 		}
-	} else {
-		sortBy = "id"
-	}
+		payload, err := messageToDomain(m.Message)
+		if err != nil {
+			return gen.BackfillHistory400JSONResponse{
+				Code:    gen.ErrorCodeInvalidMessage,
+				Message: fmt.Sprintf("message %d: %s", m.Metadata.MessageNumber, err),
+			}, nil
+		}
+		msg := rocket.TelemetryMessage{
+			Metadata: rocket.MessageMetadata{
+				Channel:       m.Metadata.Channel,
+				MessageNumber: m.Metadata.MessageNumber,
+				MessageTime:   m.Metadata.MessageTime,
+				MessageType:   msgType,
+				Signature:     signatureFromContext(ctx),
+				Producer:      auth.FromContext(ctx).Producer,
+			},
+			Message: payload,
+		}
 
-	var sortOrder string
-	if request.Params.SortOrder != nil {
-		switch *request.Params.SortOrder {
-		case gen.Asc:
-			sortOrder = "ASC"
-		case gen.Desc:
-			sortOrder = "DESC"
-		default:
-			return gen.ListRockets400JSONResponse{
-				Code:    "unknown_sort_order",
-				Message: fmt.Sprintf("unknown sort order: %s", *request.Params.SortOrder),
+		allowed, err := s.ingestAllowed(ctx, msg)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Error("Can't check the scope of the message", zap.Error(err))
+			return gen.BackfillHistory500JSONResponse{
+				Code:    gen.ErrorCodeUnknown,
+				Message: err.Error(),
+			}, nil
+		}
+		if !allowed {
+			return gen.BackfillHistory403JSONResponse{
+				Code:    gen.ErrorCodeForbidden,
+				Message: fmt.Sprintf("rocket %s is outside the scope of producer %s", msg.Metadata.Channel, auth.FromContext(ctx).Producer),
 			}, nil
 		}
-	} else {
-		sortOrder = "asc"
+		messages = append(messages, msg)
+	}
+
+	report, err := s.rocket.BackfillHistory(ctx, request.Body.Channel, messages)
+	switch {
+	case errors.Is(err, rocket.ErrInvalidMessage):
+		return gen.BackfillHistory400JSONResponse{
+			Code:    gen.ErrorCodeInvalidMessage,
+			Message: err.Error(),
+		}, nil
+	case errors.Is(err, rocket.ErrRocketNotFound):
+		return gen.BackfillHistory404JSONResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: err.Error(),
+		}, nil
+	case errors.Is(err, rocket.ErrHistoryDisabled):
+		return gen.BackfillHistory409JSONResponse{
+			Code:    gen.ErrorCodeHistoryDisabled,
+			Message: err.Error(),
+		}, nil
+	case errors.Is(err, rocket.ErrHistoryTruncated):
+		return gen.BackfillHistory409JSONResponse{
+			Code:    gen.ErrorCodeHistoryTruncated,
+			Message: err.Error(),
+		}, nil
+	case errors.Is(err, rocket.ErrChannelMoved):
+		return gen.BackfillHistory421JSONResponse{
+			Code:    gen.ErrorCodeWrongPartition,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		logging.FromContext(ctx, s.logger).Error("Can't back-fill history", zap.Error(err))
+		return gen.BackfillHistory500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return gen.BackfillHistory200JSONResponse{
+		State:      s.stateToServer(report.State),
+		Merged:     report.Merged,
+		Duplicates: report.Duplicates,
+		Replayed:   report.Replayed,
+	}, nil
+}
+
+func (s *StrictServer) ListRockets(ctx context.Context, request gen.ListRocketsRequestObject) (gen.ListRocketsResponseObject, error) {
+	var rockets []gen.RocketState
+
+	sortKey, err := sortToDomain(request.Params)
+	if errors.Is(err, rocket.ErrUnknownSortField) {
+		return gen.ListRockets400JSONResponse{
+			Code:    gen.ErrorCodeUnknownSortBy,
+			Message: err.Error(),
+		}, nil
+	}
+	if errors.Is(err, rocket.ErrUnknownSortOrder) {
+		return gen.ListRockets400JSONResponse{
+			Code:    gen.ErrorCodeUnknownSortOrder,
+			Message: err.Error(),
+		}, nil
+	}
+	if errors.Is(err, rocket.ErrUnknownSortModifier) {
+		return gen.ListRockets400JSONResponse{
+			Code:    gen.ErrorCodeUnknownSortModifier,
+			Message: err.Error(),
+		}, nil
 	}
 
-	resp := s.rocket.ListAllRockets(ctx, sortBy, sortOrder)
+	filter, err := filterToDomain(request.Params)
+	if err != nil {
+		return gen.ListRockets400JSONResponse{
+			Code:    gen.ErrorCodeInvalidFilter,
+			Message: err.Error(),
+		}, nil
+	}
+
+	resp, asOf, err := s.listRockets(ctx, rocket.ListQuery{Filter: filter, Sort: []rocket.SortKey{sortKey}, Collation: s.collation})
+	switch {
+	case readTimedOut(err):
+		return gen.ListRockets503JSONResponse{
+			Code:    gen.ErrorCodeTimeout,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		logging.FromContext(ctx, s.logger).Error("Can't list rockets", zap.Error(err))
+		return gen.ListRockets500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+	noisy := request.Params.Noisy != nil && *request.Params.Noisy
+	scope := auth.FromContext(ctx).Scope
 	for _, state := range resp {
-		rockets = append(rockets, stateToServer(state))
+		if !scope.Allows(state.ID, string(state.Mission)) {
+			continue
+		}
+		if noisy && (s.rates == nil || s.rates.Rates(state.ID).OneMinute <= s.noisyRate) {
+			continue
+		}
+		rockets = append(rockets, s.stateToServer(state))
 	}
 
-	return gen.ListRockets200JSONResponse(rockets), nil
+	return gen.ListRockets200JSONResponse{
+		Body:    rockets,
+		Headers: gen.ListRockets200ResponseHeaders{XDataAsOf: asOf.UTC().Format(time.RFC3339Nano)},
+	}, nil
 }
 
-func (s *StrictServer) GetRocketState(ctx context.Context, request gen.GetRocketStateRequestObject) (gen.GetRocketStateResponseObject, error) {
-	state, ok := s.rocket.GetRocketState(ctx, request.Id)
+// listRockets lists the rockets answering the query from the snapshot when there is one, otherwise from the live
+// service, together with the time the listing is current as of
+func (s *StrictServer) listRockets(ctx context.Context, query rocket.ListQuery) ([]rocket.State, time.Time, error) {
+	if s.snapshot != nil {
+		return s.snapshot.ListAllRockets(ctx, query)
+	}
+	asOf := time.Now()
+	states, err := s.rocket.ListAllRockets(ctx, query)
+	return states, asOf, err
+}
+
+func (s *StrictServer) ListFleets(ctx context.Context, _ gen.ListFleetsRequestObject) (gen.ListFleetsResponseObject, error) {
+	states, err := s.visibleStates(ctx)
+	switch {
+	case readTimedOut(err):
+		return gen.ListFleets503JSONResponse{
+			Code:    gen.ErrorCodeTimeout,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		logging.FromContext(ctx, s.logger).Error("Can't list rockets of the fleets", zap.Error(err))
+		return gen.ListFleets500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+
+	fleets := gen.ListFleets200JSONResponse{}
+	for _, f := range s.fleets.List() {
+		fleets = append(fleets, fleetToServer(scopeFleet(ctx, f, states), states))
+	}
+	return fleets, nil
+}
+
+func (s *StrictServer) GetFleet(ctx context.Context, request gen.GetFleetRequestObject) (gen.GetFleetResponseObject, error) {
+	f, ok := s.fleets.Get(request.Name)
+	if !ok {
+		return gen.GetFleet404JSONResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("fleet %s not found", request.Name),
+		}, nil
+	}
+	states, err := s.visibleStates(ctx)
+	switch {
+	case readTimedOut(err):
+		return gen.GetFleet503JSONResponse{
+			Code:    gen.ErrorCodeTimeout,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		logging.FromContext(ctx, s.logger).Error("Can't list rockets of the fleet", zap.String("fleet", f.Name), zap.Error(err))
+		return gen.GetFleet500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+	return gen.GetFleet200JSONResponse(fleetToServer(scopeFleet(ctx, f, states), states)), nil
+}
+
+func (s *StrictServer) ListWatchlists(ctx context.Context, _ gen.ListWatchlistsRequestObject) (gen.ListWatchlistsResponseObject, error) {
+	states, err := s.visibleStates(ctx)
+	switch {
+	case readTimedOut(err):
+		return gen.ListWatchlists503JSONResponse{
+			Code:    gen.ErrorCodeTimeout,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		logging.FromContext(ctx, s.logger).Error("Can't list rockets of the watchlists", zap.Error(err))
+		return gen.ListWatchlists500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+
+	watchlists := gen.ListWatchlists200JSONResponse{}
+	for _, w := range s.watchlists.List() {
+		w.Rockets = scopeRockets(ctx, w.Rockets, states)
+		watchlists = append(watchlists, watchlistToServer(w))
+	}
+	return watchlists, nil
+}
+
+func (s *StrictServer) GetWatchlist(ctx context.Context, request gen.GetWatchlistRequestObject) (gen.GetWatchlistResponseObject, error) {
+	w, ok := s.watchlists.Get(request.Name)
+	if !ok {
+		return gen.GetWatchlist404JSONResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("watchlist %s not found", request.Name),
+		}, nil
+	}
+	lookups, err := s.lookupRockets(ctx, w.Rockets)
+	switch {
+	case readTimedOut(err):
+		return gen.GetWatchlist503JSONResponse{
+			Code:    gen.ErrorCodeTimeout,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		return gen.GetWatchlist500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+	out := gen.GetWatchlist200JSONResponse{Name: w.Name, Rockets: lookups}
+	if w.Description != "" {
+		out.Description = &w.Description
+	}
+	return out, nil
+}
+
+// visibleStates lists the states of the rockets in the scope of the caller
+func (s *StrictServer) visibleStates(ctx context.Context) ([]rocket.State, error) {
+	states, err := s.rocket.ListAllRockets(ctx, rocket.ListQuery{})
+	if err != nil {
+		return nil, err
+	}
+	scope := auth.FromContext(ctx).Scope
+	return slices.DeleteFunc(states, func(state rocket.State) bool {
+		return !scope.Allows(state.ID, string(state.Mission))
+	}), nil
+}
+
+// scopeFleet leaves out the rockets of the fleet outside the scope of the caller
+func scopeFleet(ctx context.Context, f fleet.Fleet, visible []rocket.State) fleet.Fleet {
+	f.Rockets = scopeRockets(ctx, f.Rockets, visible)
+	return f
+}
+
+// scopeRockets leaves out the rockets outside the scope of the caller: the ones without a visible state, unless
+// their channel is in the scope. The order of the others is kept.
+func scopeRockets(ctx context.Context, ids []uuid.UUID, visible []rocket.State) []uuid.UUID {
+	scope := auth.FromContext(ctx).Scope
+	if !scope.Restricted() {
+		return ids
+	}
+	return slices.DeleteFunc(slices.Clone(ids), func(id uuid.UUID) bool {
+		return !scope.Allows(id, "") && !slices.ContainsFunc(visible, func(state rocket.State) bool {
+			return state.ID == id
+		})
+	})
+}
+
+func (s *StrictServer) GetRocketByName(ctx context.Context, request gen.GetRocketByNameRequestObject) (gen.GetRocketByNameResponseObject, error) {
+	id, ok := s.names.Resolve(request.Name)
 	if !ok {
+		return gen.GetRocketByName404JSONResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("no rocket is named %s", request.Name),
+		}, nil
+	}
+	resp, err := s.GetRocketState(ctx, gen.GetRocketStateRequestObject{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	switch resp := resp.(type) {
+	case gen.GetRocketState200JSONResponse:
+		return gen.GetRocketByName200JSONResponse(resp), nil
+	case gen.GetRocketState403JSONResponse:
+		return gen.GetRocketByName403JSONResponse(resp), nil
+	case gen.GetRocketState404JSONResponse:
+		return gen.GetRocketByName404JSONResponse(resp), nil
+	case gen.GetRocketState503JSONResponse:
+		return gen.GetRocketByName503JSONResponse(resp), nil
+	case gen.GetRocketState500JSONResponse:
+		return gen.GetRocketByName500JSONResponse(resp), nil
+	}
+	return nil, fmt.Errorf("unexpected response type: %T", resp)
+}
+
+func (s *StrictServer) GetRocketState(ctx context.Context, request gen.GetRocketStateRequestObject) (gen.GetRocketStateResponseObject, error) {
+	state, err := s.rocket.GetRocketState(ctx, request.Id)
+	switch {
+	case errors.Is(err, rocket.ErrRocketNotFound):
 		return gen.GetRocketState404JSONResponse{
-			Code:    "not_found",
+			Code:    gen.ErrorCodeNotFound,
 			Message: fmt.Sprintf("rocket with id %s not found", request.Id),
 		}, nil
+	case readTimedOut(err):
+		return gen.GetRocketState503JSONResponse{
+			Code:    gen.ErrorCodeTimeout,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		logging.FromContext(ctx, s.logger).Error("Can't read rocket state", zap.String("rocket_id", request.Id.String()), zap.Error(err))
+		return gen.GetRocketState500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+
+	if principal := auth.FromContext(ctx); !principal.Scope.Allows(state.ID, string(state.Mission)) {
+		return gen.GetRocketState403JSONResponse{
+			Code:    gen.ErrorCodeForbidden,
+			Message: fmt.Sprintf("rocket %s is outside the scope of producer %s", request.Id, principal.Producer),
+		}, nil
+	}
+
+	return gen.GetRocketState200JSONResponse(s.stateToServer(state)), nil
+}
+
+// maxBatchGet - most rockets looked up by one batch-get request
+const maxBatchGet = 100
+
+func (s *StrictServer) BatchGetRockets(ctx context.Context, request gen.BatchGetRocketsRequestObject) (gen.BatchGetRocketsResponseObject, error) {
+	ids := request.Body.Ids
+	if len(ids) == 0 || len(ids) > maxBatchGet {
+		return gen.BatchGetRockets400JSONResponse{
+			Code:    gen.ErrorCodeInvalidBatch,
+			Message: fmt.Sprintf("the batch must name between 1 and %d rockets, got %d", maxBatchGet, len(ids)),
+		}, nil
+	}
+
+	lookups, err := s.lookupRockets(ctx, ids)
+	switch {
+	case readTimedOut(err):
+		return gen.BatchGetRockets503JSONResponse{
+			Code:    gen.ErrorCodeTimeout,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		return gen.BatchGetRockets500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+	return gen.BatchGetRockets200JSONResponse(lookups), nil
+}
+
+// lookupRockets looks up the rockets in the order of the ids, a repeated id once. Rockets that are unknown,
+// outside the scope of the caller or denied by the store are looked up as such; other read errors fail the
+// lookup.
+func (s *StrictServer) lookupRockets(ctx context.Context, ids []uuid.UUID) ([]gen.RocketLookup, error) {
+	scope := auth.FromContext(ctx).Scope
+	lookups := make(map[uuid.UUID]gen.RocketLookup, len(ids))
+	out := make([]gen.RocketLookup, 0, len(ids))
+	for _, id := range ids {
+		if lookup, ok := lookups[id]; ok {
+			out = append(out, lookup)
+			continue
+		}
+		lookup := gen.RocketLookup{Id: id, Status: gen.RocketLookupStatusFound}
+		state, err := s.rocket.GetRocketState(ctx, id)
+		switch {
+		case errors.Is(err, rocket.ErrRocketNotFound):
+			lookup.Status = gen.RocketLookupStatusNotFound
+		case err != nil:
+			if !readTimedOut(err) {
+				logging.FromContext(ctx, s.logger).Error("Can't read rocket state", zap.String("rocket_id", id.String()), zap.Error(err))
+			}
+			return nil, err
+		case !scope.Allows(state.ID, string(state.Mission)):
+			lookup.Status = gen.RocketLookupStatusForbidden
+		default:
+			rocketState := s.stateToServer(state)
+			lookup.State = &rocketState
+		}
+		lookups[id] = lookup
+		out = append(out, lookup)
+	}
+	return out, nil
+}
+
+func (s *StrictServer) RegisterRocket(ctx context.Context, request gen.RegisterRocketRequestObject) (gen.RegisterRocketResponseObject, error) {
+	typ, err := rocket.NewRocketType(request.Body.Type)
+	if err != nil {
+		return gen.RegisterRocket400JSONResponse{
+			Code:    gen.ErrorCodeInvalidRegistration,
+			Message: err.Error(),
+		}, nil
+	}
+	mission, err := rocket.NewMission(request.Body.Mission)
+	if err != nil {
+		return gen.RegisterRocket400JSONResponse{
+			Code:    gen.ErrorCodeInvalidRegistration,
+			Message: err.Error(),
+		}, nil
+	}
+	principal := auth.FromContext(ctx)
+	result, err := s.rocket.RegisterRocket(ctx, request.Id, typ, mission, func(m rocket.Mission) bool {
+		return principal.Scope.Allows(request.Id, string(m))
+	})
+	switch {
+	case errors.Is(err, rocket.ErrForbidden):
+		return gen.RegisterRocket403JSONResponse{
+			Code:    gen.ErrorCodeForbidden,
+			Message: fmt.Sprintf("rocket %s is outside the scope of producer %s", request.Id, principal.Producer),
+		}, nil
+	case errors.Is(err, rocket.ErrRegistrationConflict):
+		return gen.RegisterRocket409JSONResponse{
+			Code:    gen.ErrorCodeRegistrationConflict,
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		logging.FromContext(ctx, s.logger).Error("Can't register rocket", zap.String("rocket_id", request.Id.String()), zap.Error(err))
+		return gen.RegisterRocket500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+	if result.Created {
+		return gen.RegisterRocket201JSONResponse(registrationToServer(result.Registration, result.Awaiting)), nil
+	}
+	return gen.RegisterRocket200JSONResponse(registrationToServer(result.Registration, result.Awaiting)), nil
+}
+
+func (s *StrictServer) ListRegistrations(ctx context.Context, _ gen.ListRegistrationsRequestObject) (gen.ListRegistrationsResponseObject, error) {
+	scope := auth.FromContext(ctx).Scope
+	resp := gen.ListRegistrations200JSONResponse{}
+	for _, reg := range s.registrations.List() {
+		if !scope.Allows(reg.ID, string(reg.Mission)) {
+			continue
+		}
+		_, err := s.rocket.GetRocketState(ctx, reg.ID)
+		awaiting := errors.Is(err, rocket.ErrRocketNotFound)
+		if err != nil && !awaiting {
+			logging.FromContext(ctx, s.logger).Error("Can't read rocket state", zap.String("rocket_id", reg.ID.String()), zap.Error(err))
+			return gen.ListRegistrations500JSONResponse{
+				Code:    gen.ErrorCodeUnknown,
+				Message: err.Error(),
+			}, nil
+		}
+		resp = append(resp, registrationToServer(reg, awaiting))
+	}
+	return resp, nil
+}
+
+func (s *StrictServer) GetRocketSpeedHistory(ctx context.Context, request gen.GetRocketSpeedHistoryRequestObject) (gen.GetRocketSpeedHistoryResponseObject, error) {
+	var window time.Duration
+	if request.Params.Window != nil {
+		var err error
+		if window, err = time.ParseDuration(*request.Params.Window); err != nil || window <= 0 {
+			return gen.GetRocketSpeedHistory400JSONResponse{
+				Code:    gen.ErrorCodeInvalidWindow,
+				Message: fmt.Sprintf("window must be a positive duration, e.g. 1m, got %q", *request.Params.Window),
+			}, nil
+		}
+	}
+	agg := rocket.AggregationAvg
+	if request.Params.Agg != nil {
+		agg = rocket.Aggregation(*request.Params.Agg)
+	}
+	if err := agg.Validate(); err != nil {
+		return gen.GetRocketSpeedHistory400JSONResponse{
+			Code:    gen.ErrorCodeInvalidAggregation,
+			Message: err.Error(),
+		}, nil
+	}
+
+	resp, err := s.GetRocketState(ctx, gen.GetRocketStateRequestObject{Id: request.Id})
+	if err != nil {
+		return nil, err
+	}
+	switch resp := resp.(type) {
+	case gen.GetRocketState200JSONResponse:
+	case gen.GetRocketState403JSONResponse:
+		return gen.GetRocketSpeedHistory403JSONResponse(resp), nil
+	case gen.GetRocketState404JSONResponse:
+		return gen.GetRocketSpeedHistory404JSONResponse(resp), nil
+	case gen.GetRocketState503JSONResponse:
+		return gen.GetRocketSpeedHistory503JSONResponse(resp), nil
+	case gen.GetRocketState500JSONResponse:
+		return gen.GetRocketSpeedHistory500JSONResponse(resp), nil
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	var samples []rocket.SpeedSample
+	if s.history != nil {
+		samples = rocket.SpeedHistory(s.history.ListEvents(request.Id))
+	}
+	if window > 0 {
+		if samples, err = rocket.Downsample(samples, window, agg); err != nil {
+			return gen.GetRocketSpeedHistory400JSONResponse{
+				Code:    gen.ErrorCodeInvalidAggregation,
+				Message: err.Error(),
+			}, nil
+		}
+	}
+	out := make(gen.GetRocketSpeedHistory200JSONResponse, 0, len(samples))
+	for _, sample := range samples {
+		out = append(out, gen.SpeedSample{Speed: int64(sample.Speed), Time: sample.Time})
+	}
+	return out, nil
+}
+
+func (s *StrictServer) GetRocketProcessingStats(ctx context.Context, request gen.GetRocketProcessingStatsRequestObject) (gen.GetRocketProcessingStatsResponseObject, error) {
+	resp, err := s.GetRocketState(ctx, gen.GetRocketStateRequestObject{Id: request.Id})
+	if err != nil {
+		return nil, err
+	}
+	switch resp := resp.(type) {
+	case gen.GetRocketState200JSONResponse:
+	case gen.GetRocketState403JSONResponse:
+		return gen.GetRocketProcessingStats403JSONResponse(resp), nil
+	case gen.GetRocketState404JSONResponse:
+		return gen.GetRocketProcessingStats404JSONResponse(resp), nil
+	case gen.GetRocketState503JSONResponse:
+		return gen.GetRocketProcessingStats503JSONResponse(resp), nil
+	case gen.GetRocketState500JSONResponse:
+		return gen.GetRocketProcessingStats500JSONResponse(resp), nil
+	default:
+		return nil, fmt.Errorf("unexpected response type: %T", resp)
+	}
+
+	stats, err := s.rocket.GetProcessingStats(ctx, request.Id)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Can't get processing stats", zap.Error(err))
+		return gen.GetRocketProcessingStats500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+	return gen.GetRocketProcessingStats200JSONResponse(processingStatsToServer(stats)), nil
+}
+
+func (s *StrictServer) GetMissionReport(ctx context.Context, request gen.GetMissionReportRequestObject) (gen.GetMissionReportResponseObject, error) {
+	format := gen.Html
+	if request.Params.Format != nil {
+		format = *request.Params.Format
+	}
+	if format != gen.Html && format != gen.Pdf {
+		return gen.GetMissionReport400JSONResponse{
+			Code:    gen.ErrorCodeUnknownFormat,
+			Message: fmt.Sprintf("unknown report format: %s", format),
+		}, nil
+	}
+
+	mission, err := rocket.NewMission(request.Name)
+	if err != nil {
+		return gen.GetMissionReport404JSONResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("mission %s not found", request.Name),
+		}, nil
+	}
+	if s.private(ctx) {
+		return gen.GetMissionReport403JSONResponse{
+			Code:    gen.ErrorCodeForbidden,
+			Message: "mission reports are not public, an API key is required",
+		}, nil
+	}
+	if principal := auth.FromContext(ctx); !principal.Scope.AllowsMission(string(mission)) {
+		return gen.GetMissionReport403JSONResponse{
+			Code:    gen.ErrorCodeForbidden,
+			Message: fmt.Sprintf("mission %s is outside the scope of producer %s", mission, principal.Producer),
+		}, nil
+	}
+	missionReport, err := s.missions.MissionReport(ctx, mission)
+	if errors.Is(err, report.ErrMissionNotFound) {
+		return gen.GetMissionReport404JSONResponse{
+			Code:    gen.ErrorCodeNotFound,
+			Message: fmt.Sprintf("mission %s not found", request.Name),
+		}, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Can't build mission report", zap.Error(err))
+		return gen.GetMissionReport500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+
+	if format == gen.Pdf {
+		body, err := report.RenderMissionPDF(missionReport)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Error("Can't render mission report", zap.Error(err))
+			return gen.GetMissionReport500JSONResponse{
+				Code:    gen.ErrorCodeUnknown,
+				Message: err.Error(),
+			}, nil
+		}
+		return gen.GetMissionReport200ApplicationpdfResponse{
+			Body:          bytes.NewReader(body),
+			ContentLength: int64(len(body)),
+		}, nil
 	}
 
-	return gen.GetRocketState200JSONResponse(stateToServer(state)), nil
+	body, err := report.RenderMissionHTML(missionReport)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Error("Can't render mission report", zap.Error(err))
+		return gen.GetMissionReport500JSONResponse{
+			Code:    gen.ErrorCodeUnknown,
+			Message: err.Error(),
+		}, nil
+	}
+	return gen.GetMissionReport200TexthtmlResponse{
+		Body:          bytes.NewReader(body),
+		ContentLength: int64(len(body)),
+	}, nil
+}
+
+func (s *StrictServer) GetUsage(ctx context.Context, _ gen.GetUsageRequestObject) (gen.GetUsageResponseObject, error) {
+	if s.private(ctx) {
+		return gen.GetUsage403JSONResponse{
+			Code:    gen.ErrorCodeForbidden,
+			Message: "usage is not public, an API key is required",
+		}, nil
+	}
+	quota := s.usage.Quota()
+	resp := make(gen.GetUsage200JSONResponse, 0)
+	principal := auth.FromContext(ctx)
+	for _, u := range s.usage.List() {
+		// scoped producers share the instance with others, they only see their own usage
+		if principal.Scope.Restricted() && u.Producer != principal.Producer {
+			continue
+		}
+		item := gen.ProducerUsage{
+			Producer: u.Producer,
+			Date:     openapi_types.Date{Time: u.Date},
+			Messages: u.Messages,
+			Bytes:    u.Bytes,
+		}
+		if quota.Messages > 0 {
+			item.MessageQuota = &quota.Messages
+		}
+		if quota.Bytes > 0 {
+			item.ByteQuota = &quota.Bytes
+		}
+		resp = append(resp, item)
+	}
+
+	return resp, nil
+}
+
+// stateToServer converts the state to its API representation with the name and the message rates of the rocket
+func (s *StrictServer) stateToServer(state rocket.State) gen.RocketState {
+	out := stateToServer(state)
+	if name, ok := s.names.Name(state.ID); ok {
+		out.Name = &name
+	}
+	if s.rates != nil {
+		rates := s.rates.Rates(state.ID)
+		out.MessageRates = &gen.MessageRates{
+			OneMinute:      rates.OneMinute,
+			FiveMinutes:    rates.FiveMinutes,
+			FifteenMinutes: rates.FifteenMinutes,
+		}
+	}
+	return out
+}
+
+// private reports whether the caller is denied what is not public in public mode, i.e. has no API key
+func (s *StrictServer) private(ctx context.Context) bool {
+	return s.public && auth.FromContext(ctx).IsAnonymous()
+}
+
+// ingestAllowed reports whether the caller may ingest the message: its channel is in the scope, or the mission of
+// the rocket is and so is the mission the message sets, if any. A rocket whose mission is not known yet is only in
+// the scope by its channel.
+func (s *StrictServer) ingestAllowed(ctx context.Context, msg rocket.TelemetryMessage) (bool, error) {
+	scope := auth.FromContext(ctx).Scope
+	if !scope.Restricted() || slices.Contains(scope.Channels, msg.Metadata.Channel) {
+		return true, nil
+	}
+	state, err := s.rocket.GetRocketState(ctx, msg.Metadata.Channel)
+	if err != nil && !errors.Is(err, rocket.ErrRocketNotFound) {
+		return false, err
+	}
+	mission := state.Mission
+	if mission != "" && !scope.AllowsMission(string(mission)) {
+		return false, nil
+	}
+	for _, next := range []*rocket.Mission{msg.Message.Mission, msg.Message.NewMission} {
+		if next != nil {
+			if !scope.AllowsMission(string(*next)) {
+				return false, nil
+			}
+			mission = *next
+		}
+	}
+	return mission != "", nil
+}
+
+func (s *StrictServer) GetCapabilities(_ context.Context, _ gen.GetCapabilitiesRequestObject) (gen.GetCapabilitiesResponseObject, error) {
+	return gen.GetCapabilities200JSONResponse(s.capabilities), nil
+}
+
+func (s *StrictServer) GetVersion(_ context.Context, _ gen.GetVersionRequestObject) (gen.GetVersionResponseObject, error) {
+	return gen.GetVersion200JSONResponse(buildInfoToServer(buildinfo.Get())), nil
+}
+
+// readTimedOut reports whether a read of the service failed because the request was cancelled or ran out of time
+func readTimedOut(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// lenient reports whether the Prefer header asks for lenient handling (RFC 7240), accepting old and duplicate messages
+func lenient(prefer *string) bool {
+	if prefer == nil {
+		return false
+	}
+	return strings.EqualFold(parsePrefer([]string{*prefer})["handling"], "lenient")
 }
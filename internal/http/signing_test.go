@@ -0,0 +1,89 @@
+package http
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"rockets/pkg/telemetry"
+	"strings"
+	"testing"
+)
+
+func TestAPI_Signatures(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	signingKeys, err := auth.ParseSigningKeys(map[string]string{"acme": base64.StdEncoding.EncodeToString(public)})
+	if err != nil {
+		t.Fatalf("ParseSigningKeys failed: %v", err)
+	}
+	if _, err := auth.ParseSigningKeys(map[string]string{"ops": "c2hvcnQ="}); err == nil {
+		t.Errorf("Expected a key of the wrong size to be rejected")
+	}
+
+	logger := zap.NewNop()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	history := rocket.NewInMemoryHistoryStore()
+	svc.UseHistory(history)
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:        e,
+		Logger:      logger,
+		Rocket:      svc,
+		Keys:        auth.NewKeys(map[string]string{"acme-key": "acme", "ops-key": "ops"}),
+		SigningKeys: signingKeys,
+		Usage:       usage.NewMeter(usage.Quota{}),
+	})
+
+	id := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	send := func(key string, number int, sign func(body string) string) {
+		t.Helper()
+		body := fmt.Sprintf(`{"metadata":{"channel":"%s","messageNumber":%d,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketSpeedIncreased"},"message":{"by":100}}`, id, number)
+		if number == 1 {
+			body = fmt.Sprintf(`{"metadata":{"channel":"%s","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`, id)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(apiKeyHeader, key)
+		if sign != nil {
+			req.Header.Set(telemetry.SignatureHeader, sign(body))
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("Expected message %d accepted whatever its signature, got %d: %s", number, rec.Code, rec.Body)
+		}
+	}
+	valid := func(body string) string {
+		return telemetry.Sign([]byte(body), private)
+	}
+	tampered := func(body string) string {
+		return valid(strings.Replace(body, `"by":100`, `"by":900`, 1))
+	}
+
+	send("acme-key", 1, valid)
+	send("acme-key", 2, tampered)
+	send("acme-key", 3, nil)
+	send("acme-key", 4, func(string) string { return "not a signature" })
+	send("ops-key", 5, nil)
+
+	expected := []rocket.SignatureStatus{rocket.SignatureVerified, rocket.SignatureInvalid, rocket.SignatureMissing, rocket.SignatureInvalid, ""}
+	events := history.ListEvents(id)
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for i, e := range events {
+		if e.Message.Metadata.Signature != expected[i] {
+			t.Errorf("Expected the signature of message %d %q, got %q", i+1, expected[i], e.Message.Metadata.Signature)
+		}
+	}
+}
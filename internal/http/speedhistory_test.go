@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPI_SpeedHistory(t *testing.T) {
+	logger := zap.NewNop()
+	history := rocket.NewInMemoryHistoryStore()
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	svc.UseHistory(history)
+	e := echo.New()
+	NewServer(&ServerOpts{
+		Echo:    e,
+		Logger:  logger,
+		Rocket:  svc,
+		History: history,
+		Keys:    auth.NewKeys(nil),
+		Usage:   usage.NewMeter(usage.Quota{}),
+	})
+
+	id := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	at := time.Date(2022, 2, 2, 19, 39, 0, 0, time.UTC)
+	messages := []rocket.TelemetryMessage{
+		{
+			Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: at, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 2, MessageTime: at.Add(20 * time.Second), MessageType: rocket.MessageTypeSpeedIncreased},
+			Message:  rocket.Message{By: ptr(rocket.Speed(1000))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 3, MessageTime: at.Add(90 * time.Second), MessageType: rocket.MessageTypeSpeedIncreased},
+			Message:  rocket.Message{By: ptr(rocket.Speed(500))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := svc.ProcessMessage(context.Background(), msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []int64
+	}{
+		{"samples", "", []int64{500, 1500, 2000}},
+		{"avg", "?window=1m", []int64{1000, 2000}},
+		{"max", "?window=1m&agg=max", []int64{1500, 2000}},
+		{"min", "?window=1h&agg=min", []int64{500}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := get("/v1/rockets/" + id.String() + "/speed-history" + tt.query)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var samples []gen.SpeedSample
+			_ = json.Unmarshal(rec.Body.Bytes(), &samples)
+			if len(samples) != len(tt.expected) {
+				t.Fatalf("Expected %d samples, got %+v", len(tt.expected), samples)
+			}
+			for i, s := range samples {
+				if s.Speed != tt.expected[i] {
+					t.Errorf("Expected speed %d of sample %d, got %d", tt.expected[i], i, s.Speed)
+				}
+			}
+		})
+	}
+
+	if rec := get("/v1/rockets/" + id.String() + "/speed-history?window=0s"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an empty window rejected, got %d", rec.Code)
+	}
+	if rec := get("/v1/rockets/" + id.String() + "/speed-history?window=1m&agg=median"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown aggregation rejected, got %d", rec.Code)
+	}
+	// also without a window, where nothing is aggregated
+	rec := get("/v1/rockets/" + id.String() + "/speed-history?agg=bogus")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), string(gen.ErrorCodeInvalidAggregation)) {
+		t.Errorf("Expected an unknown aggregation rejected without a window, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := get("/v1/rockets/" + uuid.NewString() + "/speed-history"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown rocket not found, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"rockets/internal/i18n"
+	"rockets/internal/report"
+	"rockets/internal/rocket"
+	"time"
+)
+
+const (
+	statusRefresh     = 10 * time.Second
+	statusAlertWindow = time.Hour
+)
+
+// StatusPage serves the server-rendered at-a-glance status page, in the language of the Accept-Language header.
+func StatusPage(rockets rocket.Service, feed *report.Feed) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		states, err := rockets.ListAllRockets(c.Request().Context(), rocket.SortedBy(rocket.SortByID, rocket.SortAsc))
+		if err != nil {
+			return err
+		}
+		l := i18n.Negotiate(c.Request().Header.Get(acceptLanguageHeader))
+		page, err := report.RenderStatusHTML(report.BuildStatus(states, feed, statusAlertWindow), statusRefresh, l)
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Add(echo.HeaderVary, acceptLanguageHeader)
+		c.Response().Header().Set(contentLanguageHeader, l.Language())
+		return c.HTMLBlob(http.StatusOK, page)
+	}
+}
@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/labstack/echo/v4"
+	"io/fs"
+	"net/http"
+)
+
+// AttachDashboard serves the embedded single-page dashboard under /ui of the base path, next to the API it calls.
+func AttachDashboard(e *echo.Echo, basePath string, assets fs.FS, m ...echo.MiddlewareFunc) {
+	e.GET(basePath+"/ui", func(c echo.Context) error {
+		return c.Redirect(http.StatusMovedPermanently, basePath+"/ui/")
+	}, m...)
+	e.Group(basePath+"/ui", m...).StaticFS("/", assets)
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/auth"
+	"rockets/internal/http/gen"
+	"rockets/internal/usage"
+	"rockets/internal/watchlist"
+	"testing"
+)
+
+func TestAPI_Watchlists(t *testing.T) {
+	e := echo.New()
+	keys := auth.NewKeys(map[string]string{"ops-key": "ops", "apollo-key": "apollo"})
+	keys.Restrict("apollo", auth.Scope{Missions: []string{"APOLLO"}})
+	NewServer(&ServerOpts{
+		Echo:       e,
+		Logger:     zap.NewNop(),
+		Rocket:     goldenService(t),
+		Keys:       keys,
+		Usage:      usage.NewMeter(usage.Quota{}),
+		Watchlists: watchlist.NewRegistry(),
+	})
+	do := func(method, target, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	launched, exploded, unknown := "193270a9-c9cf-404a-8f83-838e71d9ae67", "7a9d2e61-0f3c-4c8e-9a51-2d4b6c8e1f30", "00000000-0000-0000-0000-000000000001"
+	body := `{"name":"launch-day","description":"pad 39A","rockets":["` + exploded + `","` + unknown + `","` + launched + `"]}`
+	if rec := do(http.MethodPost, "/admin/watchlists", "", body); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the watchlist created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/admin/watchlists", "", body); rec.Code != http.StatusConflict {
+		t.Errorf("Expected a second watchlist with the name rejected, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/admin/watchlists", "", `{"name":"no spaces"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an invalid watchlist rejected, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodGet, "/v1/watchlists/LAUNCH-DAY", "ops-key", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected the watchlist, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var w gen.WatchlistStates
+	_ = json.Unmarshal(rec.Body.Bytes(), &w)
+	statuses := make([]gen.RocketLookupStatus, 0, len(w.Rockets))
+	for _, lookup := range w.Rockets {
+		statuses = append(statuses, lookup.Status)
+	}
+	if w.Name != "launch-day" || len(w.Rockets) != 3 || w.Rockets[0].Id.String() != exploded || w.Rockets[0].State == nil ||
+		statuses[1] != gen.RocketLookupStatusNotFound || statuses[2] != gen.RocketLookupStatusFound {
+		t.Errorf("Expected the states of the rockets in the order of the watchlist, got %+v", w)
+	}
+
+	var scoped gen.WatchlistStates
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/watchlists/launch-day", "apollo-key", "").Body.Bytes(), &scoped)
+	for _, lookup := range scoped.Rockets {
+		if lookup.State != nil && lookup.State.Mission != "APOLLO" {
+			t.Errorf("Expected only the rockets in the scope of the API key with a state, got %+v", lookup)
+		}
+	}
+	var scopedList []gen.Watchlist
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/watchlists", "apollo-key", "").Body.Bytes(), &scopedList)
+	if len(scopedList) != 1 || len(scopedList[0].Rockets) != 0 {
+		t.Errorf("Expected the watchlist listed without the rockets outside the scope of the API key, got %+v", scopedList)
+	}
+
+	if rec := do(http.MethodPut, "/admin/watchlists/launch-day", "", `{"rockets":["`+launched+`"]}`); rec.Code != http.StatusOK {
+		t.Errorf("Expected the watchlist updated, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var listed []gen.Watchlist
+	_ = json.Unmarshal(do(http.MethodGet, "/v1/watchlists", "", "").Body.Bytes(), &listed)
+	if len(listed) != 1 || len(listed[0].Rockets) != 1 || listed[0].Description != nil {
+		t.Errorf("Expected the updated watchlist listed, got %+v", listed)
+	}
+
+	if rec := do(http.MethodDelete, "/admin/watchlists/launch-day", "", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the watchlist deleted, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/v1/watchlists/launch-day", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected a deleted watchlist not found, got %d", rec.Code)
+	}
+}
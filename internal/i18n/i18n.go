@@ -0,0 +1,145 @@
+// Package i18n localizes the texts operators read, error messages and status labels, into the language asked
+// for by the Accept-Language header, from a message catalog embedded in the binary.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage - language of the texts when no other one is acceptable, the catalog has every key in it
+const DefaultLanguage = "en"
+
+//go:embed catalog/*.json
+var catalogFiles embed.FS
+
+// catalog - texts by language and key
+var catalog = mustLoad()
+
+// mustLoad reads the catalog, one JSON file of texts by key per language. The catalog is part of the binary, so
+// a broken one is a programming error.
+func mustLoad() map[string]map[string]string {
+	files, err := catalogFiles.ReadDir("catalog")
+	if err != nil {
+		panic(err)
+	}
+	out := make(map[string]map[string]string, len(files))
+	for _, f := range files {
+		data, err := catalogFiles.ReadFile(path.Join("catalog", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var texts map[string]string
+		if err := json.Unmarshal(data, &texts); err != nil {
+			panic(fmt.Sprintf("invalid message catalog %s: %s", f.Name(), err))
+		}
+		out[strings.TrimSuffix(f.Name(), ".json")] = texts
+	}
+	for lang, texts := range out {
+		for key := range texts {
+			if _, ok := out[DefaultLanguage][key]; !ok {
+				panic(fmt.Sprintf("message catalog %s has key %s missing in %s", lang, key, DefaultLanguage))
+			}
+		}
+	}
+	return out
+}
+
+// Languages returns the languages of the catalog, sorted
+func Languages() []string {
+	langs := make([]string, 0, len(catalog))
+	for lang := range catalog {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Localizer - texts of a language, falling back to the default language for the keys it lacks
+type Localizer struct {
+	lang string
+}
+
+// For returns the localizer of the language, of the default language when the catalog doesn't have it.
+func For(lang string) Localizer {
+	if _, ok := catalog[lang]; !ok {
+		return Localizer{lang: DefaultLanguage}
+	}
+	return Localizer{lang: lang}
+}
+
+// Negotiate returns the localizer of the most preferred language of the Accept-Language header (RFC 9110) the
+// catalog has, matching a regional tag like fr-CH by its primary language too, or of the default language.
+func Negotiate(acceptLanguage string) Localizer {
+	type accepted struct {
+		tag string
+		q   float64
+	}
+	var tags []accepted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if tag != "" && q > 0 {
+			tags = append(tags, accepted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	for _, t := range tags {
+		if t.tag == "*" {
+			break
+		}
+		primary, _, _ := strings.Cut(t.tag, "-")
+		for _, lang := range []string{t.tag, primary} {
+			if _, ok := catalog[lang]; ok {
+				return Localizer{lang: lang}
+			}
+		}
+	}
+	return Localizer{lang: DefaultLanguage}
+}
+
+// Language returns the language of the texts
+func (l Localizer) Language() string {
+	if l.lang == "" {
+		return DefaultLanguage
+	}
+	return l.lang
+}
+
+// Default reports whether the texts are in the default language
+func (l Localizer) Default() bool {
+	return l.Language() == DefaultLanguage
+}
+
+// Text returns the text of the key formatted with the args like fmt.Sprintf, the key itself when the catalog
+// doesn't have it
+func (l Localizer) Text(key string, args ...any) string {
+	text, ok := catalog[l.Language()][key]
+	if !ok {
+		if text, ok = catalog[DefaultLanguage][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Has reports whether the catalog has the key
+func Has(key string) bool {
+	_, ok := catalog[DefaultLanguage][key]
+	return ok
+}
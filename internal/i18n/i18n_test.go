@@ -0,0 +1,49 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "en"},
+		{"fr", "fr"},
+		{"fr-CH, fr;q=0.9, en;q=0.8", "fr"},
+		{"de-DE, en;q=0.5, fr;q=0.7", "fr"},
+		{"en-GB, fr;q=0.9", "en"},
+		{"fr;q=0, en", "en"},
+		{"de, *;q=0.1", "en"},
+		{"FR-ca", "fr"},
+		{"fr;q=abc", "en"},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header).Language(); got != tt.expected {
+			t.Errorf("Negotiate(%q): expected %s, got %s", tt.header, tt.expected, got)
+		}
+	}
+}
+
+func TestLocalizer_Text(t *testing.T) {
+	if got := For("fr").Text("status.updated", "2022-02-02T19:39:05Z", 10); got != "Mis à jour le 2022-02-02T19:39:05Z, actualisé toutes les 10 s" {
+		t.Errorf("Expected the French text, got %q", got)
+	}
+	if got := For("de").Text("status.total"); got != "Total" {
+		t.Errorf("Expected the English text for an unknown language, got %q", got)
+	}
+	if got := For("fr").Text("status.missing"); got != "status.missing" {
+		t.Errorf("Expected the key for a missing text, got %q", got)
+	}
+}
+
+func TestCatalog_Complete(t *testing.T) {
+	for _, lang := range Languages() {
+		for key := range catalog[DefaultLanguage] {
+			if _, ok := catalog[lang][key]; !ok {
+				t.Errorf("Message catalog %s has no text for %s", lang, key)
+			}
+		}
+	}
+}
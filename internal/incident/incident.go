@@ -0,0 +1,507 @@
+// Package incident keeps the incident records of exploded rockets, opened automatically when an explosion is
+// applied and managed through their lifecycle by the operators.
+package incident
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"rockets/internal/jsonfile"
+	"rockets/internal/logging"
+	"rockets/internal/notify"
+	"rockets/internal/rocket"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidIncident - the incident has no rocket, an empty or too long title or notes, or an unknown status
+	ErrInvalidIncident = errors.New("invalid incident")
+	// ErrIncidentNotFound - no incident has the id
+	ErrIncidentNotFound = errors.New("incident not found")
+)
+
+// Status - stage of the lifecycle of an incident
+type Status string
+
+const (
+	// StatusOpen - the incident waits for an operator
+	StatusOpen Status = "open"
+	// StatusAcknowledged - an operator is handling the incident
+	StatusAcknowledged Status = "acknowledged"
+	// StatusClosed - the incident is resolved, it may be reopened
+	StatusClosed Status = "closed"
+)
+
+// Valid reports whether the status is one of the known ones
+func (s Status) Valid() bool {
+	return s == StatusOpen || s == StatusAcknowledged || s == StatusClosed
+}
+
+// EventRef - event of the rocket history linked to an incident
+type EventRef struct {
+	MessageNumber int64 `json:"messageNumber"`
+	// Version - version of the state the message led to
+	Version int64 `json:"version"`
+}
+
+// Incident - record of a failure handled by the operators
+type Incident struct {
+	ID     uuid.UUID `json:"id"`
+	Status Status    `json:"status"`
+	Title  string    `json:"title"`
+	// Rocket, Mission - the rocket the incident is about and its mission when the incident was opened
+	Rocket  uuid.UUID      `json:"rocket"`
+	Mission rocket.Mission `json:"mission,omitempty"`
+	// Reason - the classified reason of the explosion, nil for incidents opened by hand
+	Reason *rocket.ExplosionReason `json:"reason,omitempty"`
+	// Events - the events of the rocket history the incident refers to
+	Events   []EventRef `json:"events"`
+	Assignee string     `json:"assignee,omitempty"`
+	Notes    string     `json:"notes,omitempty"`
+	// Automatic - the incident was opened by an applied explosion rather than by an operator
+	Automatic      bool       `json:"automatic"`
+	OpenedAt       time.Time  `json:"openedAt"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+	ClosedAt       *time.Time `json:"closedAt,omitempty"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// Draft - incident opened by an operator
+type Draft struct {
+	Rocket   uuid.UUID  `json:"rocket"`
+	Title    string     `json:"title"`
+	Events   []EventRef `json:"events"`
+	Assignee string     `json:"assignee"`
+	Notes    string     `json:"notes"`
+}
+
+// Patch - changes of an incident, nil fields are left as they are
+type Patch struct {
+	Status   *Status `json:"status"`
+	Title    *string `json:"title"`
+	Assignee *string `json:"assignee"`
+	Notes    *string `json:"notes"`
+}
+
+// Filter - incidents to list, zero fields match all
+type Filter struct {
+	Status Status
+	Rocket uuid.UUID
+}
+
+// Limits of the texts of an incident
+const (
+	maxTitle = 256
+	maxNotes = 4096
+)
+
+var _ rocket.Listener = (*Registry)(nil)
+
+// Registry - incidents by id, kept by the instance applying the explosions. It opens an incident for every rocket
+// whose state turns EXPLODED, or links the explosion to the incident of the rocket still open, and alerts through
+// the notifier whenever an incident changes status. A nil registry keeps no incidents.
+type Registry struct {
+	mu        sync.RWMutex
+	incidents map[uuid.UUID]Incident
+	// file - JSON file the incidents are saved to on every change, empty keeps them in memory only
+	file string
+	// dirty - signals Run to save the incidents changed by an applied message, pending - they are not saved yet
+	dirty   chan struct{}
+	pending bool
+
+	notifier notify.Notifier
+	clock    clock.Clock
+	logger   *zap.Logger
+}
+
+// NewRegistry creates a registry keeping the incidents in memory only.
+func NewRegistry(logger *zap.Logger) *Registry {
+	return &Registry{
+		incidents: make(map[uuid.UUID]Incident),
+		dirty:     make(chan struct{}, 1),
+		clock:     clock.Real{},
+		logger:    logger,
+	}
+}
+
+// Open creates a registry saving the incidents to the file, loading the ones saved before if it exists.
+func Open(file string, logger *zap.Logger) (*Registry, error) {
+	r := NewRegistry(logger)
+	r.file = file
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Refresh loads the incidents from the file again, picking up the changes saved by the leader while in standby.
+// A registry keeping them in memory only is left as it is.
+func (r *Registry) Refresh() error {
+	if r == nil || r.file == "" {
+		return nil
+	}
+	var incidents []Incident
+	if _, err := jsonfile.Load(r.file, "incidents", &incidents); err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]Incident, len(incidents))
+	for _, incident := range incidents {
+		byID[incident.ID] = incident
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.incidents = byID
+	return nil
+}
+
+// UseClock replaces the system clock the incidents are timed by. Must be called before the registry is used.
+func (r *Registry) UseClock(c clock.Clock) {
+	r.clock = c
+}
+
+// UseNotifier alerts through the notifier when an incident is opened, acknowledged, closed or reopened. Must be
+// called before the registry is used.
+func (r *Registry) UseNotifier(n notify.Notifier) {
+	r.notifier = n
+}
+
+// StateChanged opens an incident when the message made the rocket explode. The change is saved by Run, off the
+// path applying the message.
+func (r *Registry) StateChanged(_ context.Context, msg rocket.TelemetryMessage, prev, next rocket.State) {
+	if next.Status != rocket.StatusExploded || prev.Status == rocket.StatusExploded {
+		return
+	}
+	ref := EventRef{MessageNumber: msg.Metadata.MessageNumber, Version: next.Version}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a rocket exploding again after a rollback belongs to the incident still being handled
+	for id, incident := range r.incidents {
+		if incident.Rocket == next.ID && incident.Status != StatusClosed {
+			incident.Events = append(incident.Events, ref)
+			incident.UpdatedAt = r.clock.Now().UTC()
+			r.incidents[id] = incident
+			r.changedLocked()
+			return
+		}
+	}
+
+	title := fmt.Sprintf("Rocket %s exploded", next.ID)
+	if next.Reason != nil {
+		title += ": " + next.Reason.Text
+	}
+	now := r.clock.Now().UTC()
+	incident := Incident{
+		ID:        uuid.New(),
+		Status:    StatusOpen,
+		Title:     truncate(title, maxTitle),
+		Rocket:    next.ID,
+		Mission:   next.Mission,
+		Reason:    next.Reason,
+		Events:    []EventRef{ref},
+		Automatic: true,
+		OpenedAt:  now,
+		UpdatedAt: now,
+	}
+	r.incidents[incident.ID] = incident
+	r.changedLocked()
+	r.logger.Warn("Incident opened",
+		logging.Event(logging.EventIncidentOpened),
+		zap.String("incident_id", incident.ID.String()),
+		zap.String("rocket_id", incident.Rocket.String()),
+		zap.Int64("msg_num", ref.MessageNumber),
+	)
+	r.alert(incident)
+}
+
+// Create opens an incident for the rocket. It fails with ErrInvalidIncident.
+func (r *Registry) Create(d Draft) (Incident, error) {
+	now := r.clock.Now().UTC()
+	incident := Incident{
+		ID:        uuid.New(),
+		Status:    StatusOpen,
+		Title:     strings.TrimSpace(d.Title),
+		Rocket:    d.Rocket,
+		Events:    d.Events,
+		Assignee:  strings.TrimSpace(d.Assignee),
+		Notes:     d.Notes,
+		OpenedAt:  now,
+		UpdatedAt: now,
+	}
+	if incident.Events == nil {
+		incident.Events = []EventRef{}
+	}
+	if err := validate(incident); err != nil {
+		return Incident{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.incidents[incident.ID] = incident
+	if err := r.saveLocked(); err != nil {
+		delete(r.incidents, incident.ID)
+		return Incident{}, err
+	}
+	r.logger.Info("Incident opened",
+		logging.Event(logging.EventIncidentOpened),
+		zap.String("incident_id", incident.ID.String()),
+		zap.String("rocket_id", incident.Rocket.String()),
+	)
+	r.alert(incident)
+	return incident, nil
+}
+
+// Update applies the patch to the incident with the id. Acknowledging or closing it records when that happened,
+// reopening a closed incident clears its closing time. It fails with ErrInvalidIncident or ErrIncidentNotFound.
+func (r *Registry) Update(id uuid.UUID, p Patch) (Incident, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.incidents[id]
+	if !ok {
+		return Incident{}, fmt.Errorf("%w: %s", ErrIncidentNotFound, id)
+	}
+
+	now := r.clock.Now().UTC()
+	incident := prev
+	if p.Title != nil {
+		incident.Title = strings.TrimSpace(*p.Title)
+	}
+	if p.Assignee != nil {
+		incident.Assignee = strings.TrimSpace(*p.Assignee)
+	}
+	if p.Notes != nil {
+		incident.Notes = *p.Notes
+	}
+	if p.Status != nil && *p.Status != prev.Status {
+		incident.Status = *p.Status
+		switch incident.Status {
+		case StatusOpen:
+			incident.ClosedAt = nil
+		case StatusAcknowledged:
+			incident.AcknowledgedAt = &now
+			incident.ClosedAt = nil
+		case StatusClosed:
+			incident.ClosedAt = &now
+		}
+	}
+	if err := validate(incident); err != nil {
+		return Incident{}, err
+	}
+	incident.UpdatedAt = now
+
+	r.incidents[id] = incident
+	if err := r.saveLocked(); err != nil {
+		r.incidents[id] = prev
+		return Incident{}, err
+	}
+	if incident.Status != prev.Status {
+		r.logger.Info("Incident status changed",
+			logging.Event(logging.EventIncidentStatusChanged),
+			zap.String("incident_id", id.String()),
+			zap.String("rocket_id", incident.Rocket.String()),
+			zap.String("prev_status", string(prev.Status)),
+			zap.String("status", string(incident.Status)),
+		)
+		r.alert(incident)
+	}
+	return incident, nil
+}
+
+// Delete drops the incident with the id, returning false if there is none
+func (r *Registry) Delete(id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.incidents[id]
+	if !ok {
+		return false, nil
+	}
+	delete(r.incidents, id)
+	if err := r.saveLocked(); err != nil {
+		r.incidents[id] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+// Get returns the incident with the id
+func (r *Registry) Get(id uuid.UUID) (Incident, bool) {
+	if r == nil {
+		return Incident{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	incident, ok := r.incidents[id]
+	return incident, ok
+}
+
+// List returns the incidents matching the filter, the most recently opened first
+func (r *Registry) List(f Filter) []Incident {
+	if r == nil {
+		return []Incident{}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	incidents := make([]Incident, 0, len(r.incidents))
+	for _, incident := range r.listLocked() {
+		if (f.Status == "" || incident.Status == f.Status) && (f.Rocket == uuid.Nil || incident.Rocket == f.Rocket) {
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents
+}
+
+func (r *Registry) listLocked() []Incident {
+	incidents := make([]Incident, 0, len(r.incidents))
+	for _, incident := range r.incidents {
+		incidents = append(incidents, incident)
+	}
+	sort.Slice(incidents, func(i, j int) bool {
+		if !incidents[i].OpenedAt.Equal(incidents[j].OpenedAt) {
+			return incidents[i].OpenedAt.After(incidents[j].OpenedAt)
+		}
+		return incidents[i].ID.String() < incidents[j].ID.String()
+	})
+	return incidents
+}
+
+// validate checks the fields an operator may set
+func validate(incident Incident) error {
+	switch {
+	case incident.Rocket == uuid.Nil:
+		return fmt.Errorf("%w: rocket is required", ErrInvalidIncident)
+	case incident.Title == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidIncident)
+	case len(incident.Title) > maxTitle:
+		return fmt.Errorf("%w: title longer than %d characters", ErrInvalidIncident, maxTitle)
+	case len(incident.Notes) > maxNotes:
+		return fmt.Errorf("%w: notes longer than %d characters", ErrInvalidIncident, maxNotes)
+	case !incident.Status.Valid():
+		return fmt.Errorf("%w: status must be open, acknowledged or closed, got %q", ErrInvalidIncident, incident.Status)
+	}
+	return nil
+}
+
+// truncate cuts the text to at most n bytes without splitting a character
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	return strings.ToValidUTF8(text[:n], "")
+}
+
+// alertSeverities, alertActions - severity and action of the alerts by the status the incident changed to
+var (
+	alertSeverities = map[Status]notify.Severity{
+		StatusOpen:         notify.SeverityCritical,
+		StatusAcknowledged: notify.SeverityWarning,
+		StatusClosed:       notify.SeverityInfo,
+	}
+	alertActions = map[Status]notify.Action{
+		StatusOpen:         notify.ActionTrigger,
+		StatusAcknowledged: notify.ActionAcknowledge,
+		StatusClosed:       notify.ActionResolve,
+	}
+)
+
+// alertKey returns the key of the alerts of the incident: the alerts of an explosion are keyed by the rocket, so
+// pager services deduplicate them per rocket, the ones of an incident opened by hand by the incident
+func alertKey(incident Incident) string {
+	if incident.Automatic {
+		return "rocket/" + incident.Rocket.String() + "/explosion"
+	}
+	return "incident/" + incident.ID.String()
+}
+
+// alert notifies about the status of the incident in the background, all alerts of an incident share its key.
+// Failures are only logged.
+func (r *Registry) alert(incident Incident) {
+	if r.notifier == nil {
+		return
+	}
+	alert := notify.Alert{
+		Key:      alertKey(incident),
+		Action:   alertActions[incident.Status],
+		Severity: alertSeverities[incident.Status],
+		Title:    incident.Title,
+		Details: map[string]string{
+			"incident_id": incident.ID.String(),
+			"rocket_id":   incident.Rocket.String(),
+			"status":      string(incident.Status),
+		},
+		Time: incident.UpdatedAt,
+	}
+	if incident.Mission != "" {
+		alert.Details["mission"] = string(incident.Mission)
+	}
+	if incident.Reason != nil {
+		alert.Details["reason"] = incident.Reason.Text
+		alert.Details["reason_category"] = string(incident.Reason.Category)
+	}
+	if incident.Assignee != "" {
+		alert.Details["assignee"] = incident.Assignee
+	}
+	// the timeout leaves room for the retries of the notifier
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		if err := r.notifier.Notify(ctx, alert); err != nil {
+			r.logger.Error("Can't send incident alert", logging.Event(logging.EventDeliveryError), zap.String("incident_id", incident.ID.String()), zap.Error(err))
+		}
+	}()
+}
+
+// Run saves the incidents changed by applied messages until the context is done, saving the last changes then.
+// Failures are logged, the changes are saved again with the next ones.
+func (r *Registry) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			r.flush()
+			return nil
+		case <-r.dirty:
+			r.flush()
+		}
+	}
+}
+
+// changedLocked signals Run to save the incidents, without waiting for it
+func (r *Registry) changedLocked() {
+	if r.file == "" {
+		return
+	}
+	r.pending = true
+	select {
+	case r.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// flush saves the pending changes, a standby which applied none leaves the file of the leader as it is
+func (r *Registry) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.pending {
+		return
+	}
+	if err := r.saveLocked(); err != nil {
+		r.logger.Error("Can't save incidents", zap.Error(err))
+	}
+}
+
+// saveLocked writes the incidents to the file
+func (r *Registry) saveLocked() error {
+	if r.file == "" {
+		return nil
+	}
+	if err := jsonfile.Save(r.file, "incidents", r.listLocked()); err != nil {
+		return err
+	}
+	r.pending = false
+	return nil
+}
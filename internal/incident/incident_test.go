@@ -0,0 +1,163 @@
+package incident
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"path/filepath"
+	"reflect"
+	"rockets/internal/clock"
+	"rockets/internal/notify"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+// recorder - notifier passing the alerts on, they are sent in the background
+type recorder chan notify.Alert
+
+func (r recorder) Notify(_ context.Context, alert notify.Alert) error {
+	r <- alert
+	return nil
+}
+
+func (r recorder) next(t *testing.T) notify.Alert {
+	t.Helper()
+	select {
+	case alert := <-r:
+		return alert
+	case <-time.After(time.Second):
+		t.Fatal("Expected an alert, got none")
+		return notify.Alert{}
+	}
+}
+
+func TestRegistry_OpensOnExplosion(t *testing.T) {
+	fake := clock.NewFake(time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC))
+	file := filepath.Join(t.TempDir(), "incidents.json")
+	r, err := Open(file, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	r.UseClock(fake)
+	alerts := make(recorder, 4)
+	r.UseNotifier(alerts)
+	ctx := context.Background()
+
+	id := uuid.New()
+	launched := rocket.State{ID: id, Status: rocket.StatusLaunched, Mission: "ARTEMIS", Version: 1}
+	msg := func(n int64) rocket.TelemetryMessage {
+		return rocket.TelemetryMessage{Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: n}}
+	}
+	r.StateChanged(ctx, msg(1), rocket.State{}, launched)
+	if list := r.List(Filter{}); len(list) != 0 {
+		t.Fatalf("Expected no incident before the explosion, got %+v", list)
+	}
+
+	text := "PRESSURE_VESSEL_FAILURE"
+	exploded := launched
+	exploded.Status, exploded.Reason, exploded.Version = rocket.StatusExploded, rocket.ClassifyReason(&text), 2
+	r.StateChanged(ctx, msg(2), launched, exploded)
+	// an explosion reported again doesn't open another incident
+	r.StateChanged(ctx, msg(3), exploded, exploded)
+
+	list := r.List(Filter{Rocket: id})
+	if len(list) != 1 {
+		t.Fatalf("Expected one incident, got %+v", list)
+	}
+	opened := list[0]
+	expected := []EventRef{{MessageNumber: 2, Version: 2}}
+	if opened.Status != StatusOpen || !opened.Automatic || opened.Mission != "ARTEMIS" || opened.Reason.Category != rocket.ReasonStructural || !reflect.DeepEqual(opened.Events, expected) {
+		t.Errorf("Expected an open incident linked to the explosion, got %+v", opened)
+	}
+	if alert := alerts.next(t); alert.Key != "rocket/"+id.String()+"/explosion" || alert.Severity != notify.SeverityCritical || alert.Details["reason_category"] != "STRUCTURAL" {
+		t.Errorf("Expected a critical alert of the incident, got %+v", alert)
+	}
+
+	// a rollback and a new explosion are linked to the incident still open
+	exploded.Version = 4
+	r.StateChanged(ctx, msg(4), launched, exploded)
+	if got, _ := r.Get(opened.ID); len(got.Events) != 2 || len(r.List(Filter{})) != 1 {
+		t.Errorf("Expected the explosion linked to the open incident, got %+v", r.List(Filter{}))
+	}
+
+	// the changes of the applied messages are saved by Run, the last ones when it stops
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- r.Run(runCtx) }()
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	reopened, err := Open(file, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got, ok := reopened.Get(opened.ID); !ok || got.Title != "Rocket "+id.String()+" exploded: PRESSURE_VESSEL_FAILURE" {
+		t.Errorf("Expected the incident to survive a restart, got %+v", got)
+	}
+}
+
+func TestRegistry_Lifecycle(t *testing.T) {
+	fake := clock.NewFake(time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC))
+	r := NewRegistry(zap.NewNop())
+	r.UseClock(fake)
+	alerts := make(recorder, 4)
+	r.UseNotifier(alerts)
+
+	if _, err := r.Create(Draft{Title: "no rocket"}); !errors.Is(err, ErrInvalidIncident) {
+		t.Errorf("Expected ErrInvalidIncident without a rocket, got %v", err)
+	}
+	created, err := r.Create(Draft{Rocket: uuid.New(), Title: " Telemetry lost over the Atlantic "})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.Title != "Telemetry lost over the Atlantic" || created.Automatic || created.Events == nil {
+		t.Errorf("Expected a trimmed manual incident, got %+v", created)
+	}
+	alerts.next(t)
+
+	status := func(s Status) *Status { return &s }
+	assignee := "flight-director"
+	fake.Advance(time.Minute)
+	acked, err := r.Update(created.ID, Patch{Status: status(StatusAcknowledged), Assignee: &assignee})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if acked.AcknowledgedAt == nil || !acked.AcknowledgedAt.Equal(fake.Now()) || acked.Assignee != assignee {
+		t.Errorf("Expected the incident acknowledged and assigned, got %+v", acked)
+	}
+	if alert := alerts.next(t); alert.Key != "incident/"+created.ID.String() || alert.Action != notify.ActionAcknowledge || alert.Severity != notify.SeverityWarning {
+		t.Errorf("Expected a warning of the acknowledgement, got %+v", alert)
+	}
+
+	closed, err := r.Update(created.ID, Patch{Status: status(StatusClosed)})
+	if err != nil || closed.ClosedAt == nil {
+		t.Fatalf("Expected the incident closed, got %+v, %v", closed, err)
+	}
+	if alert := alerts.next(t); alert.Action != notify.ActionResolve || alert.Severity != notify.SeverityInfo {
+		t.Errorf("Expected the closing sent as info, got %+v", alert)
+	}
+	if list := r.List(Filter{Status: StatusOpen}); len(list) != 0 {
+		t.Errorf("Expected no open incidents, got %+v", list)
+	}
+
+	if _, err := r.Update(created.ID, Patch{Status: status("resolved")}); !errors.Is(err, ErrInvalidIncident) {
+		t.Errorf("Expected ErrInvalidIncident for an unknown status, got %v", err)
+	}
+	if _, err := r.Update(uuid.New(), Patch{}); !errors.Is(err, ErrIncidentNotFound) {
+		t.Errorf("Expected ErrIncidentNotFound, got %v", err)
+	}
+	if got, _ := r.Get(created.ID); got.Status != StatusClosed {
+		t.Errorf("Expected a rejected update to change nothing, got %+v", got)
+	}
+
+	if deleted, err := r.Delete(created.ID); !deleted || err != nil {
+		t.Errorf("Expected the incident deleted, got %v, %v", deleted, err)
+	}
+	if deleted, _ := r.Delete(created.ID); deleted {
+		t.Error("Expected a deleted incident not found")
+	}
+}
@@ -0,0 +1,53 @@
+// Package jsonfile keeps the registries of the operators (names, fleets, watchlists, registrations, maintenance
+// windows, incidents and pins) in JSON files, each saved whole on every change.
+package jsonfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"rockets/internal/atomicfile"
+)
+
+// ValidName - names operators type, e.g. tail numbers like N-1234: letters, digits, '.', '_' and '-', up to 64
+// characters starting with a letter or a digit
+var ValidName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Load decodes the file into v, returning false when the file doesn't exist. what names the contents in errors,
+// e.g. "fleets".
+func Load(file, what string, v any) (bool, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("can't read %s file: %w", what, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("can't parse %s file: %w", what, err)
+	}
+	return true, nil
+}
+
+// Save writes v to the file through a synced temporary one, so a crash leaves either version whole. what names
+// the contents in errors, e.g. "fleets".
+func Save(file, what string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't encode %s: %w", what, err)
+	}
+	tmp, err := os.OpenFile(file+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("can't write %s file: %w", what, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("can't write %s file: %w", what, err)
+	}
+	if err := atomicfile.Replace(tmp, file); err != nil {
+		return fmt.Errorf("can't replace %s file: %w", what, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fleets.json")
+	var loaded []string
+	if ok, err := Load(file, "fleets", &loaded); ok || err != nil {
+		t.Fatalf("Expected a missing file to load nothing, got %t, %v", ok, err)
+	}
+
+	if err := Save(file, "fleets", []string{"falcon", "starlink"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if ok, err := Load(file, "fleets", &loaded); !ok || err != nil {
+		t.Fatalf("Load failed: %t, %v", ok, err)
+	}
+	if len(loaded) != 2 || loaded[0] != "falcon" || loaded[1] != "starlink" {
+		t.Errorf("Expected the saved fleets, got %v", loaded)
+	}
+	if _, err := os.Stat(file + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected no temporary file left, got %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(file, "fleets", &loaded); err == nil || !strings.Contains(err.Error(), "can't parse fleets file") {
+		t.Errorf("Expected a parse error naming the fleets, got %v", err)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"N-1234":                true,
+		"falcon.9_block-5":      true,
+		"":                      false,
+		"-leading":              false,
+		"no spaces":             false,
+		strings.Repeat("a", 64): true,
+		strings.Repeat("a", 65): false,
+	} {
+		if ValidName.MatchString(name) != valid {
+			t.Errorf("Expected %q valid %t", name, valid)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package kube calls the Kubernetes API server over plain HTTP with the service account of the pod, so the
+// coordination objects the replicas share don't need client-go.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// serviceAccountDir - directory of the service account mounted into every pod; a variable, so the integration
+// tests can link the binary against a fake cluster with -ldflags -X
+var serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount/"
+
+// MicroTime - layout of the MicroTime fields of Kubernetes objects
+const MicroTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// ErrConflict - the object was changed by another replica since it was read, or already exists
+var ErrConflict = errors.New("object changed concurrently")
+
+// ErrNotFound - the object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Client - API server reached with a bearer token
+type Client struct {
+	// URL - base URL of the API server
+	URL string
+	// TokenFile - bearer token of the service account, re-read on every request since projected tokens rotate
+	TokenFile string
+	HTTP      *http.Client
+}
+
+// Pod - the pod the replica runs in
+type Pod struct {
+	Name      string
+	Namespace string
+}
+
+// InCluster returns the client of the API server of the cluster the pod runs in, authenticated with its service
+// account, and the pod.
+func InCluster() (*Client, Pod, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, Pod{}, errors.New("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST is not set")
+	}
+	ns, err := os.ReadFile(serviceAccountDir + "namespace")
+	if err != nil {
+		return nil, Pod{}, fmt.Errorf("can't read the namespace of the pod: %w", err)
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "ca.crt")
+	if err != nil {
+		return nil, Pod{}, fmt.Errorf("can't read the CA of the cluster: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, Pod{}, errors.New("can't parse the CA of the cluster")
+	}
+	name, err := os.Hostname()
+	if err != nil {
+		return nil, Pod{}, fmt.Errorf("can't get the pod name: %w", err)
+	}
+	return &Client{
+		URL:       "https://" + net.JoinHostPort(host, port),
+		TokenFile: serviceAccountDir + "token",
+		HTTP: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+	}, Pod{Name: name, Namespace: strings.TrimSpace(string(ns))}, nil
+}
+
+// Lease - coordination.k8s.io/v1 Lease object
+type Lease struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       LeaseSpec  `json:"spec"`
+}
+
+// ObjectMeta - metadata of an object
+type ObjectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+}
+
+// LeaseSpec - holder and timing of a lease
+type LeaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int    `json:"leaseTransitions"`
+}
+
+// NewLease creates a lease object to be written
+func NewLease(namespace, name string, spec LeaseSpec) Lease {
+	return Lease{
+		APIVersion: "coordination.k8s.io/v1",
+		Kind:       "Lease",
+		Metadata:   ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       spec,
+	}
+}
+
+// LeasesPath returns the path of the leases of the namespace
+func LeasesPath(namespace string) string {
+	return "/apis/coordination.k8s.io/v1/namespaces/" + namespace + "/leases"
+}
+
+// Do sends the request with the JSON encoded body, when not nil, and decodes the response into out, when not
+// nil. A 404 fails with ErrNotFound and a 409 with ErrConflict.
+func (c *Client) Do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("can't encode object: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, reader)
+	if err != nil {
+		return fmt.Errorf("can't create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.TokenFile != "" {
+		token, err := os.ReadFile(c.TokenFile)
+		if err != nil {
+			return fmt.Errorf("can't read token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't send request: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("can't decode object: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+// Package latency measures how long the messages take from their message time to the applied state, per channel
+// and overall, and alerts when the pipeline lags behind a budget.
+package latency
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"math"
+	"rockets/internal/clock"
+	"rockets/internal/metrics"
+	"rockets/internal/notify"
+	"rockets/internal/rocket"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The window is made of windowSlots slots, every slot a histogram of the latencies of the messages applied
+// during it, binned by a factor of binGrowth from binBase, so a percentile is exact up to binGrowth
+const (
+	windowSlots = 12
+	binBase     = time.Millisecond
+	binGrowth   = 1.25
+)
+
+// alertKey - key of the budget alert, its recovery is sent with the same key
+const alertKey = "ingest-latency-budget"
+
+// Stats - latency percentiles of the messages applied within the window, in seconds
+type Stats struct {
+	Samples int     `json:"samples"`
+	P50     float64 `json:"p50"`
+	P90     float64 `json:"p90"`
+	P99     float64 `json:"p99"`
+	Max     float64 `json:"max"`
+}
+
+// ChannelStats - latency percentiles of a channel
+type ChannelStats struct {
+	Channel uuid.UUID `json:"channel"`
+	Stats
+}
+
+// Report - latency of the pipeline against the budget
+type Report struct {
+	// Budget, Window - p99 latency above which the pipeline lags, 0 without a budget, and the span of the samples,
+	// in seconds
+	Budget  float64 `json:"budget"`
+	Window  float64 `json:"window"`
+	Lagging bool    `json:"lagging"`
+	Overall Stats   `json:"overall"`
+	// Channels - the slowest channels, by p99
+	Channels []ChannelStats `json:"channels"`
+}
+
+// bin - latencies of a histogram bin, the largest one standing for the bin in the percentiles
+type bin struct {
+	count int64
+	max   time.Duration
+}
+
+// binOf returns the bin of the latency
+func binOf(latency time.Duration) int {
+	if latency < binBase {
+		return 0
+	}
+	return 1 + int(math.Log(float64(latency)/float64(binBase))/math.Log(binGrowth))
+}
+
+// slot - histogram of the latencies applied during a slot of the window
+type slot struct {
+	// start - number of the slot since the epoch
+	start int64
+	bins  map[int]bin
+}
+
+// window - histograms of the latencies of a channel over the slots of the window, locked per channel so the
+// channels don't contend for one lock
+type window struct {
+	mu    sync.Mutex
+	slots [windowSlots]slot
+	// dropped - the window was removed from the tracker without samples, a new one takes the next sample
+	dropped bool
+}
+
+// add records the latency in the slot, returning false when the window was dropped
+func (w *window) add(n int64, latency time.Duration) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.dropped {
+		return false
+	}
+	s := &w.slots[n%windowSlots]
+	if s.start != n || s.bins == nil {
+		s.start, s.bins = n, make(map[int]bin)
+	}
+	b := s.bins[binOf(latency)]
+	b.count++
+	b.max = max(b.max, latency)
+	s.bins[binOf(latency)] = b
+	return true
+}
+
+// collect merges the bins of the slots within the window ending with the slot n into bins, returning their count
+func (w *window) collect(n int64, bins map[int]bin) int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var count int64
+	for _, s := range w.slots {
+		if s.start <= n-windowSlots || s.start > n {
+			continue
+		}
+		count += merge(bins, s.bins)
+	}
+	return count
+}
+
+// merge adds the bins of src to dst, returning the samples added
+func merge(dst, src map[int]bin) int64 {
+	var count int64
+	for i, b := range src {
+		merged := dst[i]
+		merged.count += b.count
+		merged.max = max(merged.max, b.max)
+		dst[i] = merged
+		count += b.count
+	}
+	return count
+}
+
+// drop removes the window when it has no samples within the window ending with the slot n
+func (w *window) drop(n int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, s := range w.slots {
+		if s.start > n-windowSlots && s.start <= n && len(s.bins) > 0 {
+			return false
+		}
+	}
+	w.dropped = true
+	return true
+}
+
+// stats returns the percentiles of the merged bins
+func stats(bins map[int]bin) Stats {
+	keys := make([]int, 0, len(bins))
+	var count int64
+	for i, b := range bins {
+		keys = append(keys, i)
+		count += b.count
+	}
+	slices.Sort(keys)
+	percentile := func(q float64) time.Duration {
+		if count == 0 {
+			return 0
+		}
+		rank := max(0, min(int64(q*float64(count))-1, count-1))
+		var seen int64
+		for _, i := range keys {
+			seen += bins[i].count
+			if seen > rank {
+				return bins[i].max
+			}
+		}
+		return 0
+	}
+	return Stats{
+		Samples: int(count),
+		P50:     percentile(0.50).Seconds(),
+		P90:     percentile(0.90).Seconds(),
+		P99:     percentile(0.99).Seconds(),
+		Max:     percentile(1).Seconds(),
+	}
+}
+
+var _ rocket.Listener = (*Tracker)(nil)
+
+// Tracker records the latency of every message ingested live, from its message time to when it was applied, so
+// it includes the time spent by the producer, the network, retries and the reorder buffer, and the clock skew of
+// the producer. Redriven, handed off and replayed messages are left out. Percentiles are computed over the
+// histograms of the window, the overall ones by merging the channels.
+type Tracker struct {
+	// channels - window of every channel with samples, by channel
+	channels sync.Map
+	window   time.Duration
+
+	budget   time.Duration
+	notifier notify.Notifier
+	gauge    *metrics.Gauge
+	clock    clock.Clock
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	lagging bool
+}
+
+// NewTracker creates a tracker computing the percentiles over the window.
+func NewTracker(window time.Duration, logger *zap.Logger) *Tracker {
+	return &Tracker{
+		window: window,
+		clock:  clock.Real{},
+		logger: logger,
+	}
+}
+
+// UseClock replaces the system clock the messages are timed by. Must be called before the tracker is used.
+func (t *Tracker) UseClock(c clock.Clock) {
+	t.clock = c
+}
+
+// UseBudget alerts through the notifier, which may be nil to only log, when the overall p99 latency exceeds the
+// budget, and again when it recovers. Must be called before the tracker is used.
+func (t *Tracker) UseBudget(budget time.Duration, notifier notify.Notifier) {
+	t.budget = budget
+	t.notifier = notifier
+}
+
+// UseMetrics exports the overall percentiles as the rockets_ingest_latency_seconds gauge, updated on every check.
+// Must be called before the tracker is used.
+func (t *Tracker) UseMetrics(registry *metrics.Registry) {
+	t.gauge = registry.Gauge("rockets_ingest_latency_seconds", "Latency from the message time to the applied state over the window.", "quantile")
+}
+
+// slotOf returns the number of the slot of the window the time falls in
+func (t *Tracker) slotOf(at time.Time) int64 {
+	return at.UnixNano() / int64(max(t.window/windowSlots, 1))
+}
+
+// StateChanged records the latency of the message applied live
+func (t *Tracker) StateChanged(ctx context.Context, msg rocket.TelemetryMessage, _, _ rocket.State) {
+	if !rocket.Live(ctx) {
+		return
+	}
+	now := t.clock.Now()
+	n, latency := t.slotOf(now), max(0, now.Sub(msg.Metadata.MessageTime))
+	for {
+		w, _ := t.channels.LoadOrStore(msg.Metadata.Channel, &window{})
+		if w.(*window).add(n, latency) {
+			return
+		}
+		// dropped by a check meanwhile
+		t.channels.CompareAndDelete(msg.Metadata.Channel, w)
+	}
+}
+
+// Overall returns the percentiles of all channels
+func (t *Tracker) Overall() Stats {
+	n := t.slotOf(t.clock.Now())
+	bins := make(map[int]bin)
+	t.channels.Range(func(_, w any) bool {
+		w.(*window).collect(n, bins)
+		return true
+	})
+	return stats(bins)
+}
+
+// Channel returns the percentiles of the channel, false when it has no samples in the window
+func (t *Tracker) Channel(id uuid.UUID) (Stats, bool) {
+	w, ok := t.channels.Load(id)
+	if !ok {
+		return Stats{}, false
+	}
+	bins := make(map[int]bin)
+	if w.(*window).collect(t.slotOf(t.clock.Now()), bins) == 0 {
+		return Stats{}, false
+	}
+	return stats(bins), true
+}
+
+// Report returns the overall percentiles and the limit slowest channels
+func (t *Tracker) Report(limit int) Report {
+	n := t.slotOf(t.clock.Now())
+	t.mu.Lock()
+	lagging := t.lagging
+	t.mu.Unlock()
+	report := Report{
+		Budget:   t.budget.Seconds(),
+		Window:   t.window.Seconds(),
+		Lagging:  lagging,
+		Channels: []ChannelStats{},
+	}
+	overall := make(map[int]bin)
+	t.channels.Range(func(id, w any) bool {
+		bins := make(map[int]bin)
+		if w.(*window).collect(n, bins) > 0 {
+			report.Channels = append(report.Channels, ChannelStats{Channel: id.(uuid.UUID), Stats: stats(bins)})
+			merge(overall, bins)
+		}
+		return true
+	})
+	report.Overall = stats(overall)
+	sort.Slice(report.Channels, func(i, j int) bool {
+		if report.Channels[i].P99 != report.Channels[j].P99 {
+			return report.Channels[i].P99 > report.Channels[j].P99
+		}
+		return report.Channels[i].Channel.String() < report.Channels[j].Channel.String()
+	})
+	if len(report.Channels) > limit {
+		report.Channels = report.Channels[:limit]
+	}
+	return report
+}
+
+// Run checks the latency every interval until the context is done
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			t.Check(ctx)
+		}
+	}
+}
+
+// Check updates the gauge, drops the channels without samples in the window and alerts when the overall p99
+// latency crosses the budget in either direction
+func (t *Tracker) Check(ctx context.Context) {
+	now := t.clock.Now()
+	n := t.slotOf(now)
+	overall := t.Overall()
+	t.channels.Range(func(id, w any) bool {
+		if w.(*window).drop(n) {
+			t.channels.CompareAndDelete(id, w)
+		}
+		return true
+	})
+	t.mu.Lock()
+	lagging := t.budget > 0 && overall.P99 > t.budget.Seconds()
+	changed := lagging != t.lagging
+	t.lagging = lagging
+	t.mu.Unlock()
+
+	if t.gauge != nil {
+		t.gauge.Set(overall.P50, "0.5")
+		t.gauge.Set(overall.P90, "0.9")
+		t.gauge.Set(overall.P99, "0.99")
+		t.gauge.Set(overall.Max, "1")
+	}
+	if !changed {
+		return
+	}
+
+	p99 := time.Duration(overall.P99 * float64(time.Second))
+	alert := notify.Alert{
+		Key:      alertKey,
+		Severity: notify.SeverityWarning,
+		Title:    "Ingestion lags behind the latency budget",
+		Details: map[string]string{
+			"p99":     p99.String(),
+			"budget":  t.budget.String(),
+			"samples": strconv.Itoa(overall.Samples),
+		},
+		Time: now.UTC(),
+	}
+	if lagging {
+		t.logger.Warn("Ingestion latency exceeds the budget", zap.Duration("p99", p99), zap.Duration("budget", t.budget))
+	} else {
+		alert.Action = notify.ActionResolve
+		alert.Severity = notify.SeverityInfo
+		alert.Title = "Ingestion latency is back within the budget"
+		t.logger.Info("Ingestion latency is back within the budget", zap.Duration("p99", p99), zap.Duration("budget", t.budget))
+	}
+	if t.notifier == nil {
+		return
+	}
+	if err := t.notifier.Notify(ctx, alert); err != nil {
+		t.logger.Error("Can't send the latency alert", zap.Error(err))
+	}
+}
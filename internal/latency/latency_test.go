@@ -0,0 +1,84 @@
+package latency
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"rockets/internal/metrics"
+	"rockets/internal/notify"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+// recorder - notifier remembering the alerts
+type recorder struct {
+	alerts []notify.Alert
+}
+
+func (r *recorder) Notify(_ context.Context, alert notify.Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestTracker(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tracker := NewTracker(time.Minute, zap.NewNop())
+	tracker.UseClock(fake)
+	alerts := &recorder{}
+	tracker.UseBudget(2*time.Second, alerts)
+	registry := metrics.NewRegistry()
+	tracker.UseMetrics(registry)
+	ctx := context.Background()
+
+	fast, slow := uuid.New(), uuid.New()
+	apply := func(channel uuid.UUID, latency time.Duration) {
+		msg := rocket.TelemetryMessage{Metadata: rocket.MessageMetadata{Channel: channel, MessageTime: fake.Now().Add(-latency)}}
+		tracker.StateChanged(ctx, msg, rocket.State{}, rocket.State{ID: channel})
+	}
+	for range 99 {
+		apply(fast, 100*time.Millisecond)
+	}
+	apply(slow, 10*time.Second)
+
+	overall := tracker.Overall()
+	if overall.Samples != 100 || overall.P50 != 0.1 || overall.P99 != 0.1 || overall.Max != 10 {
+		t.Errorf("Expected p50 and p99 of 100ms and max of 10s, got %+v", overall)
+	}
+	report := tracker.Report(1)
+	if len(report.Channels) != 1 || report.Channels[0].Channel != slow {
+		t.Errorf("Expected the slow channel first, got %+v", report.Channels)
+	}
+	tracker.Check(ctx)
+	if len(alerts.alerts) != 0 {
+		t.Errorf("Expected no alert within the budget, got %+v", alerts.alerts)
+	}
+	if v := registry.Gauge("rockets_ingest_latency_seconds", "", "quantile").Value("1"); v != 10 {
+		t.Errorf("Expected the max latency exported, got %g", v)
+	}
+
+	// the pipeline falls behind
+	for range 10 {
+		apply(slow, 5*time.Second)
+	}
+	tracker.Check(ctx)
+	tracker.Check(ctx)
+	if len(alerts.alerts) != 1 || alerts.alerts[0].Severity != notify.SeverityWarning {
+		t.Fatalf("Expected one warning over the budget, got %+v", alerts.alerts)
+	}
+	if !tracker.Report(10).Lagging {
+		t.Error("Expected the report to show the lag")
+	}
+
+	// the slow samples leave the window
+	fake.Advance(time.Minute)
+	apply(fast, 100*time.Millisecond)
+	tracker.Check(ctx)
+	if len(alerts.alerts) != 2 || alerts.alerts[1].Severity != notify.SeverityInfo || alerts.alerts[1].Key != alerts.alerts[0].Key {
+		t.Errorf("Expected the recovery alerted with the same key, got %+v", alerts.alerts)
+	}
+	if _, ok := tracker.Channel(slow); ok {
+		t.Error("Expected the slow channel without samples in the window")
+	}
+}
@@ -0,0 +1,76 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Elector - elects one leader among the instances sharing a lock file. The leader holds an exclusive lock on
+// the file until it stops; the lock is released by the OS when the process dies, so a standby takes over
+// on its next attempt.
+type Elector struct {
+	path   string
+	retry  time.Duration
+	logger *zap.Logger
+	leader atomic.Bool
+}
+
+// New creates an elector competing for the lock file, retrying every retry interval while in standby.
+func New(path string, retry time.Duration, logger *zap.Logger) *Elector {
+	return &Elector{path: path, retry: retry, logger: logger}
+}
+
+// IsLeader reports whether this instance holds the lock
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Retry returns how often a standby tries to take over
+func (e *Elector) Retry() time.Duration {
+	return e.retry
+}
+
+// Run competes for leadership until the context is done. While in standby, standby is called after every
+// failed attempt. Once the lock is taken, elected is called before the instance reports itself as the leader;
+// if it fails the lock is released and the error returned.
+func (e *Elector) Run(ctx context.Context, elected func() error, standby func()) error {
+	f, err := os.OpenFile(e.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("can't open leader lock file: %w", err)
+	}
+	defer f.Close()
+
+	ticker := time.NewTicker(e.retry)
+	defer ticker.Stop()
+	e.logger.Info("Waiting for leadership", zap.String("lock_file", e.path))
+	for {
+		ok, err := tryLock(f)
+		if err != nil {
+			return fmt.Errorf("can't lock leader lock file: %w", err)
+		}
+		if ok {
+			break
+		}
+		standby()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+	defer unlock(f)
+
+	if err := elected(); err != nil {
+		return fmt.Errorf("can't take over leadership: %w", err)
+	}
+	e.leader.Store(true)
+	e.logger.Info("Elected as leader", zap.String("lock_file", e.path))
+
+	<-ctx.Done()
+	e.leader.Store(false)
+	return nil
+}
@@ -0,0 +1,61 @@
+package leader
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestElector_Failover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	first := New(path, 5*time.Millisecond, zap.NewNop())
+	second := New(path, 5*time.Millisecond, zap.NewNop())
+
+	ctx1, stop1 := context.WithCancel(context.Background())
+	done1 := make(chan error, 1)
+	elected1 := make(chan struct{})
+	go func() {
+		done1 <- first.Run(ctx1, func() error { close(elected1); return nil }, func() {})
+	}()
+	<-elected1
+
+	ctx2, stop2 := context.WithCancel(context.Background())
+	defer stop2()
+	standby := make(chan struct{}, 1)
+	elected2 := make(chan struct{})
+	go func() {
+		_ = second.Run(ctx2, func() error { close(elected2); return nil }, func() {
+			select {
+			case standby <- struct{}{}:
+			default:
+			}
+		})
+	}()
+	<-standby
+	if !eventually(first.IsLeader) || second.IsLeader() {
+		t.Fatalf("Expected only the first instance to lead")
+	}
+
+	stop1()
+	if err := <-done1; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	select {
+	case <-elected2:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the standby to take over")
+	}
+	if first.IsLeader() || !eventually(second.IsLeader) {
+		t.Errorf("Expected the second instance to lead after failover")
+	}
+}
+
+// eventually polls the condition for a while, the leader flag is set right after the elected callback
+func eventually(cond func() bool) bool {
+	for i := 0; i < 100 && !cond(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	return cond()
+}
@@ -0,0 +1,267 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"net/http"
+	"rockets/internal/clock"
+	"rockets/internal/kube"
+	"sync"
+	"time"
+)
+
+// LeaseConfig - Lease object of the Kubernetes API the replicas compete for
+type LeaseConfig struct {
+	// URL - base URL of the API server
+	URL string
+	// TokenFile - bearer token of the service account, re-read on every request since projected tokens rotate
+	TokenFile string
+	Namespace string
+	Name      string
+	// Identity - name of the replica recorded as the holder, e.g. the pod name
+	Identity string
+	// Duration - how long the lease is held without being renewed, a standby takes over after that
+	Duration time.Duration
+	Client   *http.Client
+}
+
+type (
+	leaseObject = kube.Lease
+	leaseSpec   = kube.LeaseSpec
+)
+
+// Lease elects one leader among the replicas of a Kubernetes deployment through a coordination.k8s.io Lease
+// object, so singleton background jobs run on one replica only. The lease is renewed every third of its
+// duration and released when the replica stops, so a scaled down leader hands over right away. The API is
+// called over plain HTTP, no client-go is linked in.
+type Lease struct {
+	cfg    LeaseConfig
+	api    *kube.Client
+	clock  clock.Clock
+	logger *zap.Logger
+
+	// observed - the last lease record seen held by another replica and when it was seen changing; the
+	// holder is considered gone once the record stays the same for a lease duration, which is immune to
+	// clock skew between the replicas
+	observed   leaseSpec
+	observedAt time.Time
+	renewedAt  time.Time
+
+	mu      sync.Mutex
+	leader  bool
+	changed chan struct{}
+}
+
+// NewLease creates an elector competing for the lease.
+func NewLease(cfg LeaseConfig, logger *zap.Logger) *Lease {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Lease{
+		cfg:     cfg,
+		api:     &kube.Client{URL: cfg.URL, TokenFile: cfg.TokenFile, HTTP: cfg.Client},
+		clock:   clock.Real{},
+		logger:  logger,
+		changed: make(chan struct{}),
+	}
+}
+
+// InClusterLease creates an elector competing for the lease through the API server of the cluster the pod runs
+// in, with its service account. The namespace defaults to the one of the pod, the identity is the pod name.
+func InClusterLease(name, namespace string, duration time.Duration, logger *zap.Logger) (*Lease, error) {
+	api, pod, err := kube.InCluster()
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		namespace = pod.Namespace
+	}
+	return NewLease(LeaseConfig{
+		URL:       api.URL,
+		TokenFile: api.TokenFile,
+		Namespace: namespace,
+		Name:      name,
+		Identity:  pod.Name,
+		Duration:  duration,
+		Client:    api.HTTP,
+	}, logger), nil
+}
+
+// UseClock replaces the system clock the lease is timed by. Must be called before the elector is used.
+func (l *Lease) UseClock(c clock.Clock) {
+	l.clock = c
+}
+
+// IsLeader reports whether this replica holds the lease
+func (l *Lease) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leader
+}
+
+// state returns the leadership with a channel closed when it changes
+func (l *Lease) state() (bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leader, l.changed
+}
+
+func (l *Lease) setLeader(leader bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leader == leader {
+		return
+	}
+	l.leader = leader
+	close(l.changed)
+	l.changed = make(chan struct{})
+	if leader {
+		l.logger.Info("Acquired the lease", zap.String("lease", l.cfg.Name), zap.String("identity", l.cfg.Identity))
+	} else {
+		l.logger.Info("Lost the lease", zap.String("lease", l.cfg.Name), zap.String("identity", l.cfg.Identity))
+	}
+}
+
+// Run competes for the lease and renews it while holding it, until the context is done; then a held lease is
+// released. Failed attempts are logged, the leadership is given up when the lease could not be renewed for
+// two thirds of its duration.
+func (l *Lease) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.cfg.Duration / 3)
+	defer ticker.Stop()
+	for {
+		held, err := l.tryAcquireOrRenew(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			l.logger.Warn("Can't acquire or renew the lease", zap.String("lease", l.cfg.Name), zap.Error(err))
+			if l.IsLeader() && l.clock.Now().Sub(l.renewedAt) > l.cfg.Duration*2/3 {
+				l.setLeader(false)
+			}
+		case err == nil:
+			l.setLeader(held)
+		}
+		select {
+		case <-ctx.Done():
+			l.release()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// tryAcquireOrRenew renews the lease held by this replica, or takes it over when it is free or expired
+func (l *Lease) tryAcquireOrRenew(ctx context.Context) (bool, error) {
+	now := l.clock.Now()
+	lease, found, err := l.get(ctx)
+	if err != nil {
+		return false, err
+	}
+	spec := leaseSpec{
+		HolderIdentity:       l.cfg.Identity,
+		LeaseDurationSeconds: int(l.cfg.Duration.Seconds()),
+		AcquireTime:          now.UTC().Format(kube.MicroTime),
+		RenewTime:            now.UTC().Format(kube.MicroTime),
+	}
+	if !found {
+		err = l.api.Do(ctx, http.MethodPost, l.collectionPath(), kube.NewLease(l.cfg.Namespace, l.cfg.Name, spec), nil)
+	} else {
+		if lease.Spec.HolderIdentity == l.cfg.Identity {
+			spec.AcquireTime = lease.Spec.AcquireTime
+			spec.LeaseTransitions = lease.Spec.LeaseTransitions
+		} else {
+			if lease.Spec != l.observed {
+				l.observed, l.observedAt = lease.Spec, now
+			}
+			duration := time.Duration(lease.Spec.LeaseDurationSeconds) * time.Second
+			if lease.Spec.HolderIdentity != "" && now.Sub(l.observedAt) < duration {
+				return false, nil
+			}
+			spec.LeaseTransitions = lease.Spec.LeaseTransitions + 1
+		}
+		lease.Spec = spec
+		err = l.api.Do(ctx, http.MethodPut, l.objectPath(), lease, nil)
+	}
+	if errors.Is(err, kube.ErrConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	l.renewedAt = now
+	return true, nil
+}
+
+// release hands the held lease over right away, e.g. when the replica is scaled down
+func (l *Lease) release() {
+	if !l.IsLeader() {
+		return
+	}
+	l.setLeader(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lease, found, err := l.get(ctx)
+	if err == nil && found && lease.Spec.HolderIdentity == l.cfg.Identity {
+		lease.Spec.HolderIdentity = ""
+		lease.Spec.LeaseDurationSeconds = 1
+		lease.Spec.RenewTime = l.clock.Now().UTC().Format(kube.MicroTime)
+		err = l.api.Do(ctx, http.MethodPut, l.objectPath(), lease, nil)
+	}
+	if err != nil {
+		l.logger.Warn("Can't release the lease", zap.String("lease", l.cfg.Name), zap.Error(err))
+	}
+}
+
+// RunSingleton runs the job while this replica holds the lease, until the context is done. The context of the
+// job is canceled when the lease is lost, and the job is started again when it is acquired again. An error of
+// the job is returned.
+func (l *Lease) RunSingleton(ctx context.Context, job func(context.Context) error) error {
+	for {
+		leader, changed := l.state()
+		if !leader {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-changed:
+				continue
+			}
+		}
+
+		jobCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- job(jobCtx)
+		}()
+		select {
+		case err := <-done:
+			cancel()
+			return err
+		case <-changed:
+		case <-ctx.Done():
+		}
+		cancel()
+		if err := <-done; err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+func (l *Lease) collectionPath() string {
+	return kube.LeasesPath(l.cfg.Namespace)
+}
+
+func (l *Lease) objectPath() string {
+	return l.collectionPath() + "/" + l.cfg.Name
+}
+
+// get reads the lease, found is false when it does not exist yet
+func (l *Lease) get(ctx context.Context) (leaseObject, bool, error) {
+	var lease leaseObject
+	err := l.api.Do(ctx, http.MethodGet, l.objectPath(), nil, &lease)
+	if errors.Is(err, kube.ErrNotFound) {
+		return leaseObject{}, false, nil
+	}
+	if err != nil {
+		return leaseObject{}, false, err
+	}
+	return lease, true, nil
+}
@@ -0,0 +1,139 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/clock"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLeases - API server keeping one lease, rejecting writes of stale versions like Kubernetes
+type fakeLeases struct {
+	mu      sync.Mutex
+	lease   *leaseObject
+	version int
+}
+
+func (f *fakeLeases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	const collection = "/apis/coordination.k8s.io/v1/namespaces/rockets/leases"
+	var body leaseObject
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == collection+"/jobs":
+		if f.lease == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.lease)
+	case r.Method == http.MethodPost && r.URL.Path == collection:
+		if f.lease != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.store(body)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && r.URL.Path == collection+"/jobs":
+		if f.lease == nil || body.Metadata.ResourceVersion != f.lease.Metadata.ResourceVersion {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.store(body)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeLeases) store(lease leaseObject) {
+	f.version++
+	lease.Metadata.ResourceVersion = strconv.Itoa(f.version)
+	f.lease = &lease
+}
+
+func (f *fakeLeases) holder() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lease.Spec.HolderIdentity
+}
+
+func TestLease(t *testing.T) {
+	api := &fakeLeases{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	now := clock.NewFake(time.Date(2022, 2, 2, 20, 0, 0, 0, time.UTC))
+	replica := func(identity string) *Lease {
+		l := NewLease(LeaseConfig{URL: server.URL, Namespace: "rockets", Name: "jobs", Identity: identity, Duration: 15 * time.Second}, zap.NewNop())
+		l.UseClock(now)
+		return l
+	}
+	a, b := replica("rockets-0"), replica("rockets-1")
+	ctx := context.Background()
+
+	if held, err := a.tryAcquireOrRenew(ctx); err != nil || !held {
+		t.Fatalf("Expected the first replica to create the lease, got %v, %v", held, err)
+	}
+	if held, _ := b.tryAcquireOrRenew(ctx); held {
+		t.Fatalf("Expected the second replica to wait while the lease is held")
+	}
+	now.Advance(10 * time.Second)
+	if held, _ := a.tryAcquireOrRenew(ctx); !held {
+		t.Fatalf("Expected the holder to renew the lease")
+	}
+	now.Advance(10 * time.Second)
+	if held, _ := b.tryAcquireOrRenew(ctx); held {
+		t.Fatalf("Expected a renewed lease not to be taken over")
+	}
+
+	// the holder stops renewing, e.g. its node is gone
+	now.Advance(16 * time.Second)
+	if held, err := b.tryAcquireOrRenew(ctx); err != nil || !held {
+		t.Fatalf("Expected the expired lease taken over, got %v, %v", held, err)
+	}
+	if held, _ := a.tryAcquireOrRenew(ctx); held {
+		t.Errorf("Expected the previous holder to lose the lease")
+	}
+	if api.lease.Spec.LeaseTransitions != 1 {
+		t.Errorf("Expected one transition, got %d", api.lease.Spec.LeaseTransitions)
+	}
+
+	// the singleton job runs on the holder only and the lease is released when the holder stops
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- b.Run(runCtx) }()
+	started := make(chan struct{})
+	jobDone := make(chan error, 1)
+	go func() {
+		jobDone <- b.RunSingleton(runCtx, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+	}()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the job to start on the holder")
+	}
+	stop()
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if err := <-jobDone; err != nil {
+		t.Fatalf("RunSingleton failed: %v", err)
+	}
+	if holder := api.holder(); holder != "" {
+		t.Fatalf("Expected the lease released on shutdown, held by %q", holder)
+	}
+	if held, _ := a.tryAcquireOrRenew(ctx); !held {
+		t.Errorf("Expected a released lease taken over right away")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package leader
+
+import (
+	"errors"
+	"os"
+)
+
+func tryLock(*os.File) (bool, error) {
+	return false, errors.New("file locks are not supported on this platform")
+}
+
+func unlock(*os.File) {}
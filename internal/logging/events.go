@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"go.uber.org/zap"
+)
+
+// EventKey - field carrying the machine-parseable name of the event a log entry records.
+// Alerting should match on it rather than on the human-readable message, which may change.
+const EventKey = "event"
+
+// EventName - "<subject>.<happening>" name of an event. Entries of an event always carry the fields listed
+// at its name; rocket_id, msg_num and msg_type are named the same across all events.
+type EventName string
+
+const (
+	// EventMessageAccepted - a valid message was accepted for processing: rocket_id, msg_num, msg_type
+	EventMessageAccepted EventName = "message.accepted"
+	// EventMessageInvalid - a message failed validation and was rejected: rocket_id, msg_num, error
+	EventMessageInvalid EventName = "message.invalid"
+	// EventMessageDuplicate - an old or duplicate message was ignored: rocket_id, msg_num, current_num, class
+	EventMessageDuplicate EventName = "message.duplicate"
+	// EventMessageIgnored - a message of a quarantined channel was accepted but not applied: rocket_id, msg_num
+	EventMessageIgnored EventName = "message.ignored"
+	// EventMessageHeld - a message ahead of a gap was held by the reorder buffer: rocket_id, msg_num, current_num
+	EventMessageHeld EventName = "message.held"
+	// EventMessageGapRejected - the strict dedup policy rejected a message ahead of a gap: rocket_id, msg_num, current_num
+	EventMessageGapRejected EventName = "message.gap_rejected"
+	// EventMessageGapSkipped - held messages were applied skipping a gap: rocket_id, msg_num, current_num
+	EventMessageGapSkipped EventName = "message.gap_skipped"
+	// EventMessageGapsOpen - a channel has messages that never arrived, reported periodically: rocket_id, missing, gaps, oldest_age
+	EventMessageGapsOpen EventName = "message.gaps_open"
+	// EventRetransmitRequested - the producer of a channel was asked to send missing messages again: rocket_id, ranges, missing
+	EventRetransmitRequested EventName = "message.retransmit_requested"
+	// EventSignatureRejected - the signature of a payload was missing or did not verify, the message is recorded as such: producer, signature
+	EventSignatureRejected EventName = "message.signature_rejected"
+
+	// EventStateCreated - the first message of a rocket created its state: rocket_id
+	EventStateCreated EventName = "state.created"
+	// EventStateTransition - a message was applied to the state: rocket_id, msg_num, version, prev_status, status, speed
+	EventStateTransition EventName = "state.transition"
+	// EventSpeedUnderflow - a speed decrease would take the speed below zero: rocket_id, msg_num, speed, by, policy
+	EventSpeedUnderflow EventName = "state.speed_underflow"
+	// EventStateBackfill - a provisional state was rebuilt from its late launch: rocket_id, msg_num, version, replayed, status, speed
+	EventStateBackfill EventName = "state.backfill"
+	// EventHistoryBackfill - historical messages were merged into the history and the state recomputed: rocket_id, merged, duplicates, replayed, version
+	EventHistoryBackfill EventName = "state.history_backfill"
+	// EventChannelsMerged - the history of a channel was merged into another rocket and its own state removed:
+	// rocket_id, merged_channel, merged, epochs, version
+	EventChannelsMerged EventName = "state.channels_merged"
+	// EventRocketCloned - the history of a rocket up to a message was copied into a new rocket:
+	// rocket_id, clone_id, msg_num, events
+	EventRocketCloned EventName = "state.rocket_cloned"
+	// EventStateRollback - the state was rolled back: rocket_id, msg_num, restored_msg_num
+	EventStateRollback EventName = "state.rollback"
+	// EventStateInconsistent - a stored state violates an invariant: rocket_id, check, repaired
+	EventStateInconsistent EventName = "state.inconsistent"
+	// EventStateDrift - a stored state differs from its folded history: rocket_id, fields, fixed
+	EventStateDrift EventName = "state.drift"
+
+	// EventShadowDivergence - the shadow processor led to another state than the service: rocket_id, msg_num, msg_type, fields or error
+	EventShadowDivergence EventName = "shadow.divergence"
+
+	// EventChannelQuarantined - a channel was quarantined: rocket_id, reason or error
+	EventChannelQuarantined EventName = "channel.quarantined"
+	// EventChannelReleased - a channel was released from quarantine: rocket_id
+	EventChannelReleased EventName = "channel.released"
+
+	// EventRocketLost - a rocket that has not exploded sent no telemetry for the lost timeout: rocket_id, msg_num of its last message, silence
+	EventRocketLost EventName = "rocket.lost"
+	// EventRocketFound - a lost rocket sent telemetry again: rocket_id, msg_num
+	EventRocketFound EventName = "rocket.found"
+
+	// EventIncidentOpened - an incident was opened, by an explosion or an operator: incident_id, rocket_id, msg_num of the explosion
+	EventIncidentOpened EventName = "incident.opened"
+	// EventIncidentStatusChanged - an operator acknowledged, closed or reopened an incident: incident_id, rocket_id, prev_status, status
+	EventIncidentStatusChanged EventName = "incident.status_changed"
+
+	// EventStoreSave - a state was written to the store (only with write logging): rocket_id, version, msg_num
+	EventStoreSave EventName = "store.save"
+	// EventStoreSaveError - a state could not be persisted: error, rocket_id when a single state failed
+	EventStoreSaveError EventName = "store.save.error"
+	// EventStoreRecordSkipped - an unreadable or orphaned record was skipped while loading the store: path, line
+	EventStoreRecordSkipped EventName = "store.load.skipped"
+
+	// EventRequestServed - an HTTP request was served: request_id, method, uri, status, latency
+	EventRequestServed EventName = "http.request"
+	// EventPanic - a handler panicked: incident_id, route, panic
+	EventPanic EventName = "http.panic"
+	// EventLogLevelChanged - a log level was changed at runtime: component, level
+	EventLogLevelChanged EventName = "log.level_changed"
+	// EventAlertSuppressed - an alert about a rocket under maintenance was recorded instead of delivered: key, window, window_name
+	EventAlertSuppressed EventName = "alert.suppressed"
+	// EventDeliveryError - an outbound delivery (alert, digest, export) failed after its retries: error
+	EventDeliveryError EventName = "delivery.error"
+)
+
+// Event returns the field naming the event a log entry records.
+func Event(name EventName) zap.Field {
+	return zap.String(EventKey, string(name))
+}
@@ -0,0 +1,171 @@
+package logging
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Components of the binary with their own loggers, named with zap.Logger.Named, whose levels can be adjusted
+// separately from the global one
+const (
+	ComponentHTTP      = "http"
+	ComponentRocket    = "rocket"
+	ComponentStore     = "store"
+	ComponentLeader    = "leader"
+	ComponentReports   = "reports"
+	ComponentTSDB      = "tsdb"
+	ComponentOTLP      = "otlp"
+	ComponentExport    = "export"
+	ComponentWarehouse = "warehouse"
+	ComponentCDC       = "cdc"
+	ComponentIncident  = "incident"
+	ComponentPartition = "partition"
+	ComponentConsumer  = "consumer"
+	ComponentHealth    = "health"
+)
+
+// Components - the components whose levels can be adjusted
+var Components = []string{
+	ComponentHTTP, ComponentRocket, ComponentStore, ComponentLeader, ComponentReports, ComponentTSDB,
+	ComponentOTLP, ComponentExport, ComponentWarehouse, ComponentCDC, ComponentIncident, ComponentPartition,
+	ComponentConsumer, ComponentHealth,
+}
+
+// ValidComponent reports whether the component is one of Components
+func ValidComponent(component string) bool {
+	return slices.Contains(Components, component)
+}
+
+// Levels - log levels adjustable at runtime: the global level and overrides per component. The component of an
+// entry is the name of its logger up to the first dot, entries of loggers without a name use the global level.
+type Levels struct {
+	global zap.AtomicLevel
+
+	mu         sync.RWMutex
+	components map[string]zapcore.Level
+	// min - the lowest of the global and the component levels, entries below it are dropped right away
+	min zap.AtomicLevel
+}
+
+// NewLevels creates the levels with the global level (debug, info, warn, error) and no overrides.
+func NewLevels(level string) (*Levels, error) {
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse log level: %w", err)
+	}
+	return &Levels{
+		global:     zap.NewAtomicLevelAt(lvl),
+		components: make(map[string]zapcore.Level),
+		min:        zap.NewAtomicLevelAt(lvl),
+	}, nil
+}
+
+// Level returns the global level and the overrides by component
+func (l *Levels) Level() (zapcore.Level, map[string]zapcore.Level) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.global.Level(), maps.Clone(l.components)
+}
+
+// SetLevel sets the level of the component, or the global level when the component is empty
+func (l *Levels) SetLevel(component string, level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if component == "" {
+		l.global.SetLevel(level)
+	} else {
+		l.components[component] = level
+	}
+	l.updateMin()
+}
+
+// ResetLevel drops the override of the component, so it follows the global level again
+func (l *Levels) ResetLevel(component string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.components, component)
+	l.updateMin()
+}
+
+// updateMin recomputes the lowest enabled level. Must be called with the lock held.
+func (l *Levels) updateMin() {
+	lowest := l.global.Level()
+	for _, lvl := range l.components {
+		lowest = min(lowest, lvl)
+	}
+	l.min.SetLevel(lowest)
+}
+
+// enabled reports whether entries of the level are logged by the named logger
+func (l *Levels) enabled(name string, level zapcore.Level) bool {
+	component, _, _ := strings.Cut(name, ".")
+	l.mu.RLock()
+	lvl, ok := l.components[component]
+	l.mu.RUnlock()
+	if !ok {
+		return l.global.Enabled(level)
+	}
+	return lvl.Enabled(level)
+}
+
+// Filter wraps the core to log only the entries enabled by the levels of their components.
+// The core itself should enable all levels.
+func (l *Levels) Filter(core zapcore.Core) zapcore.Core {
+	return &levelCore{Core: core, levels: l}
+}
+
+// Tee returns the core also writing its entries to the other one. When the core is filtered by Levels, the entries
+// of the other core are filtered the same way, so it should enable all levels.
+func Tee(core zapcore.Core, other zapcore.Core) zapcore.Core {
+	if c, ok := core.(*levelCore); ok {
+		return &levelCore{Core: zapcore.NewTee(c.Core, other), levels: c.levels, verbose: c.verbose}
+	}
+	return zapcore.NewTee(core, other)
+}
+
+// Verbose returns the logger logging entries of all levels regardless of the levels of its component, to trace
+// a single flow in detail without turning on debug logging for everything. Loggers not filtered by Levels
+// are returned unchanged.
+func Verbose(logger *zap.Logger) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if c, ok := core.(*levelCore); ok && !c.verbose {
+			return &levelCore{Core: c.Core, levels: c.levels, verbose: true}
+		}
+		return core
+	}))
+}
+
+// levelCore - core dropping the entries below the level of their component
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+	// verbose - entries of all levels are logged, see Verbose
+	verbose bool
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return (c.verbose || c.levels.min.Enabled(level)) && c.Core.Enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels, verbose: c.verbose}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.verbose && !c.levels.enabled(entry.LoggerName, entry.Level) {
+		return ce
+	}
+	return c.Core.Check(entry, ce)
+}
+
+func (c *levelCore) Level() zapcore.Level {
+	if c.verbose {
+		return zapcore.DebugLevel
+	}
+	return c.levels.min.Level()
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Format - output format of the logs
+type Format string
+
+const (
+	// FormatJSON - structured JSON lines, for log shippers
+	FormatJSON Format = "json"
+	// FormatConsole - human readable lines, for local development
+	FormatConsole Format = "console"
+)
+
+// Sampling - per second, the first Initial entries with the same level and message are logged,
+// then every Thereafter-th one (none when it is zero). Zero Initial disables sampling.
+type Sampling struct {
+	Initial    int
+	Thereafter int
+}
+
+type ctxKey struct{}
+
+// New creates the logger of the binary with the given levels, format and sampling.
+// The levels can be adjusted while the logger is in use.
+func New(levels *Levels, format Format, sampling Sampling) (*zap.Logger, error) {
+	var cfg zap.Config
+	switch format {
+	case FormatJSON:
+		cfg = zap.NewProductionConfig()
+	case FormatConsole:
+		cfg = zap.NewDevelopmentConfig()
+	default:
+		return nil, fmt.Errorf("unknown log format: %s", format)
+	}
+	// the levels filter the entries, the core itself logs all of them
+	cfg.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	cfg.Sampling = nil
+	if sampling.Initial > 0 {
+		cfg.Sampling = &zap.SamplingConfig{Initial: sampling.Initial, Thereafter: sampling.Thereafter}
+	}
+
+	logger, err := cfg.Build(zap.WrapCore(levels.Filter))
+	if err != nil {
+		return nil, fmt.Errorf("can't build logger: %w", err)
+	}
+	return logger, nil
+}
+
+// WithLogger returns a copy of the context carrying the logger.
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context, or the fallback when there is none.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return fallback
+}
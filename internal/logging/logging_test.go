@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"reflect"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	levels, err := NewLevels("warn")
+	if err != nil {
+		t.Fatalf("NewLevels failed: %v", err)
+	}
+	logger, err := New(levels, FormatJSON, Sampling{Initial: 100, Thereafter: 100})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if logger.Core().Enabled(zap.InfoLevel) || !logger.Core().Enabled(zap.WarnLevel) {
+		t.Errorf("Expected only warn and above to be enabled")
+	}
+
+	if _, err := NewLevels("loud"); err == nil {
+		t.Errorf("Expected an error for an unknown level")
+	}
+	if _, err := New(levels, "xml", Sampling{}); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
+
+func TestLevels(t *testing.T) {
+	levels, _ := NewLevels("info")
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(levels.Filter(core))
+	store := logger.Named(ComponentStore)
+	rocket := logger.Named(ComponentRocket).Named("reorder")
+
+	store.Debug("hidden")
+	levels.SetLevel(ComponentStore, zap.DebugLevel)
+	store.Debug("store debug")
+	rocket.Debug("hidden")
+	logger.Debug("hidden")
+	levels.SetLevel("", zap.DebugLevel)
+	rocket.Debug("rocket debug")
+	levels.SetLevel(ComponentRocket, zap.ErrorLevel)
+	rocket.Warn("hidden")
+	levels.ResetLevel(ComponentRocket)
+	rocket.Warn("rocket warn")
+
+	var got []string
+	for _, entry := range logs.All() {
+		got = append(got, entry.Message)
+	}
+	if expected := []string{"store debug", "rocket debug", "rocket warn"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	global, components := levels.Level()
+	if global != zap.DebugLevel || !reflect.DeepEqual(components, map[string]zapcore.Level{ComponentStore: zap.DebugLevel}) {
+		t.Errorf("Expected debug and a store override, got %s, %v", global, components)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	fallback := zap.NewNop()
+	if FromContext(context.Background(), fallback) != fallback {
+		t.Errorf("Expected the fallback logger without a logger in the context")
+	}
+
+	logger := zap.NewExample()
+	if FromContext(WithLogger(context.Background(), logger), fallback) != logger {
+		t.Errorf("Expected the logger carried by the context")
+	}
+}
@@ -0,0 +1,243 @@
+// Package maintenance keeps the maintenance windows of missions and channels, during which the alerts about their
+// rockets are suppressed, e.g. for planned test campaigns.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"rockets/internal/jsonfile"
+	"rockets/internal/rocket"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidWindow - the window has no name, channels or missions, an invalid mission, or doesn't end after
+	// it starts
+	ErrInvalidWindow = errors.New("invalid maintenance window")
+	// ErrWindowNotFound - no window has the id
+	ErrWindowNotFound = errors.New("maintenance window not found")
+)
+
+// Window - span of time the alerts about the rockets of the channels and the missions are suppressed in
+type Window struct {
+	ID       uuid.UUID        `json:"id"`
+	Name     string           `json:"name"`
+	Channels []uuid.UUID      `json:"channels"`
+	Missions []rocket.Mission `json:"missions"`
+	// Start, End - the window covers [Start, End)
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Covers reports whether the window suppresses the alerts about the channel or the mission at the time
+func (w Window) Covers(channel uuid.UUID, mission rocket.Mission, at time.Time) bool {
+	if at.Before(w.Start) || !at.Before(w.End) {
+		return false
+	}
+	return (channel != uuid.Nil && slices.Contains(w.Channels, channel)) ||
+		(mission != "" && slices.Contains(w.Missions, mission))
+}
+
+// Registry - maintenance windows by id. A nil registry has no windows.
+type Registry struct {
+	mu      sync.RWMutex
+	windows map[uuid.UUID]Window
+	// file - JSON file the windows are saved to on every change, empty keeps them in memory only
+	file string
+}
+
+// NewRegistry creates a registry keeping the windows in memory only.
+func NewRegistry() *Registry {
+	return &Registry{windows: make(map[uuid.UUID]Window)}
+}
+
+// Open creates a registry saving the windows to the file, loading the ones saved before if it exists.
+func Open(file string) (*Registry, error) {
+	r := NewRegistry()
+	r.file = file
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Refresh loads the maintenance windows from the file again, picking up the changes saved by the leader while in standby.
+// A registry keeping them in memory only is left as it is.
+func (r *Registry) Refresh() error {
+	if r == nil || r.file == "" {
+		return nil
+	}
+	var windows []Window
+	if _, err := jsonfile.Load(r.file, "maintenance windows", &windows); err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]Window, len(windows))
+	for _, w := range windows {
+		byID[w.ID] = w
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.windows = byID
+	return nil
+}
+
+// Create adds the window under a new id. It fails with ErrInvalidWindow.
+func (r *Registry) Create(w Window, now time.Time) (Window, error) {
+	w, err := normalize(w)
+	if err != nil {
+		return Window{}, err
+	}
+	w.ID = uuid.New()
+	w.CreatedAt = now.UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.windows[w.ID] = w
+	if err := r.saveLocked(); err != nil {
+		delete(r.windows, w.ID)
+		return Window{}, err
+	}
+	return w, nil
+}
+
+// Update replaces the window with the id, keeping its id and creation time. It fails with ErrInvalidWindow or
+// ErrWindowNotFound.
+func (r *Registry) Update(id uuid.UUID, w Window) (Window, error) {
+	w, err := normalize(w)
+	if err != nil {
+		return Window{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.windows[id]
+	if !ok {
+		return Window{}, fmt.Errorf("%w: %s", ErrWindowNotFound, id)
+	}
+	w.ID, w.CreatedAt = prev.ID, prev.CreatedAt
+	r.windows[id] = w
+	if err := r.saveLocked(); err != nil {
+		r.windows[id] = prev
+		return Window{}, err
+	}
+	return w, nil
+}
+
+// Delete drops the window with the id, returning false if there is none
+func (r *Registry) Delete(id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.windows[id]
+	if !ok {
+		return false, nil
+	}
+	delete(r.windows, id)
+	if err := r.saveLocked(); err != nil {
+		r.windows[id] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+// Get returns the window with the id
+func (r *Registry) Get(id uuid.UUID) (Window, bool) {
+	if r == nil {
+		return Window{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.windows[id]
+	return w, ok
+}
+
+// List returns the windows by start time
+func (r *Registry) List() []Window {
+	if r == nil {
+		return []Window{}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.listLocked()
+}
+
+// Covering returns the first window, by start time, suppressing the alerts about the channel or the mission at
+// the time, false when none does
+func (r *Registry) Covering(channel uuid.UUID, mission rocket.Mission, at time.Time) (Window, bool) {
+	if r == nil {
+		return Window{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, w := range r.listLocked() {
+		if w.Covers(channel, mission, at) {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+func (r *Registry) listLocked() []Window {
+	windows := make([]Window, 0, len(r.windows))
+	for _, w := range r.windows {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].Start.Equal(windows[j].Start) {
+			return windows[i].Start.Before(windows[j].Start)
+		}
+		return windows[i].ID.String() < windows[j].ID.String()
+	})
+	return windows
+}
+
+// normalize validates the window, normalizes its missions like the ones of the messages and sorts its channels
+// and missions, dropping duplicates
+func normalize(w Window) (Window, error) {
+	w.Name = strings.TrimSpace(w.Name)
+	switch {
+	case w.Name == "":
+		return Window{}, fmt.Errorf("%w: name is required", ErrInvalidWindow)
+	case len(w.Name) > 256:
+		return Window{}, fmt.Errorf("%w: name longer than 256 characters", ErrInvalidWindow)
+	case len(w.Channels) == 0 && len(w.Missions) == 0:
+		return Window{}, fmt.Errorf("%w: channels or missions are required", ErrInvalidWindow)
+	case !w.End.After(w.Start):
+		return Window{}, fmt.Errorf("%w: end must be after start", ErrInvalidWindow)
+	}
+
+	missions := make([]rocket.Mission, 0, len(w.Missions))
+	for _, m := range w.Missions {
+		mission, err := rocket.NewMission(string(m))
+		if err != nil {
+			return Window{}, fmt.Errorf("%w: %w", ErrInvalidWindow, err)
+		}
+		missions = append(missions, mission)
+	}
+	slices.Sort(missions)
+	w.Missions = slices.Compact(missions)
+
+	channels := slices.Clone(w.Channels)
+	slices.SortFunc(channels, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	w.Channels = slices.Compact(channels)
+	if w.Channels == nil {
+		w.Channels = []uuid.UUID{}
+	}
+	w.Start, w.End = w.Start.UTC(), w.End.UTC()
+	return w, nil
+}
+
+// saveLocked writes the windows to the file through a temporary one, so a crash leaves either version whole
+func (r *Registry) saveLocked() error {
+	if r.file == "" {
+		return nil
+	}
+	return jsonfile.Save(r.file, "maintenance windows", r.listLocked())
+}
@@ -0,0 +1,112 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"path/filepath"
+	"reflect"
+	"rockets/internal/clock"
+	"rockets/internal/notify"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "maintenance.json")
+	r, err := Open(file)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	start := time.Date(2022, 2, 2, 19, 0, 0, 0, time.UTC)
+	channel := uuid.New()
+
+	if _, err := r.Create(Window{Name: "static fire", Missions: []rocket.Mission{"ARTEMIS"}, Start: start, End: start}, start); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("Expected ErrInvalidWindow for an empty window, got %v", err)
+	}
+	if _, err := r.Create(Window{Name: "static fire", Start: start, End: start.Add(time.Hour)}, start); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("Expected ErrInvalidWindow without channels and missions, got %v", err)
+	}
+	if _, err := r.Update(uuid.New(), Window{Name: "x", Channels: []uuid.UUID{channel}, Start: start, End: start.Add(time.Hour)}); !errors.Is(err, ErrWindowNotFound) {
+		t.Errorf("Expected ErrWindowNotFound, got %v", err)
+	}
+
+	w, err := r.Create(Window{Name: " static fire ", Missions: []rocket.Mission{"artemis", "ARTEMIS"}, Start: start, End: start.Add(time.Hour)}, start)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if w.Name != "static fire" || !reflect.DeepEqual(w.Missions, []rocket.Mission{"ARTEMIS"}) {
+		t.Errorf("Expected the window normalized, got %+v", w)
+	}
+	if _, ok := r.Covering(uuid.New(), "ARTEMIS", start.Add(time.Hour)); ok {
+		t.Error("Expected the end of the window not covered")
+	}
+
+	updated, err := r.Update(w.ID, Window{Name: "static fire", Channels: []uuid.UUID{channel}, Start: start, End: start.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if got, ok := r.Covering(channel, "", start.Add(90*time.Minute)); !ok || got.ID != w.ID {
+		t.Errorf("Expected the channel covered by the extended window, got %+v", got)
+	}
+	if _, ok := r.Covering(uuid.New(), "ARTEMIS", start); ok {
+		t.Error("Expected the mission no longer covered")
+	}
+
+	reopened, err := Open(file)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got, ok := reopened.Get(w.ID); !ok || !reflect.DeepEqual(got, updated) {
+		t.Errorf("Expected the window to survive a restart, got %+v", got)
+	}
+	if deleted, err := reopened.Delete(w.ID); !deleted || err != nil {
+		t.Errorf("Expected the window deleted, got %v, %v", deleted, err)
+	}
+	if list := reopened.List(); len(list) != 0 {
+		t.Errorf("Expected no windows left, got %+v", list)
+	}
+}
+
+// recorder - notifier remembering the alerts
+type recorder struct {
+	alerts []notify.Alert
+}
+
+func (r *recorder) Notify(_ context.Context, alert notify.Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestSuppressor(t *testing.T) {
+	now := time.Date(2022, 2, 2, 19, 30, 0, 0, time.UTC)
+	windows := NewRegistry()
+	w, err := windows.Create(Window{Name: "test campaign", Missions: []rocket.Mission{"ARTEMIS"}, Start: now.Add(-time.Hour), End: now.Add(time.Hour)}, now)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	delivered := &recorder{}
+	s := NewSuppressor(windows, delivered, zap.NewNop())
+	s.UseClock(clock.NewFake(now))
+	ctx := context.Background()
+
+	covered := notify.Alert{Key: "rocket/a/lost", Details: map[string]string{"rocket_id": uuid.NewString(), "mission": "ARTEMIS"}}
+	other := notify.Alert{Key: "rocket/b/lost", Details: map[string]string{"rocket_id": uuid.NewString(), "mission": "APOLLO"}}
+	unrelated := notify.Alert{Key: "panic/abc"}
+	resolved := notify.Alert{Key: "rocket/c/lost", Action: notify.ActionResolve, Details: map[string]string{"rocket_id": uuid.NewString(), "mission": "ARTEMIS"}}
+	for _, alert := range []notify.Alert{covered, other, unrelated, resolved} {
+		if err := s.Notify(ctx, alert); err != nil {
+			t.Fatalf("Notify failed: %v", err)
+		}
+	}
+
+	if len(delivered.alerts) != 3 || delivered.alerts[0].Key != other.Key || delivered.alerts[1].Key != unrelated.Key || delivered.alerts[2].Key != resolved.Key {
+		t.Errorf("Expected the alerts outside the window and the resolution delivered, got %+v", delivered.alerts)
+	}
+	suppressed := s.Suppressed()
+	if len(suppressed) != 1 || suppressed[0].Alert.Key != covered.Key || suppressed[0].Window != w.ID || !suppressed[0].At.Equal(now) {
+		t.Errorf("Expected the alert of the mission recorded as suppressed, got %+v", suppressed)
+	}
+}
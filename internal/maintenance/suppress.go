@@ -0,0 +1,102 @@
+package maintenance
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"rockets/internal/logging"
+	"rockets/internal/notify"
+	"rockets/internal/rocket"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxSuppressed - suppressed alerts kept, the oldest are dropped first
+const maxSuppressed = 1000
+
+// Suppressed - alert that was not delivered since its rocket was under maintenance
+type Suppressed struct {
+	Alert notify.Alert `json:"alert"`
+	// Window - the window the alert fell in
+	Window uuid.UUID `json:"window"`
+	At     time.Time `json:"at"`
+}
+
+var _ notify.Notifier = (*Suppressor)(nil)
+
+// Suppressor - notifier delivering alerts through another one, except the triggered alerts about a rocket under
+// maintenance: the ones whose rocket_id or mission detail is covered by a window when they are sent. Those are
+// logged and recorded instead. Alerts without these details, e.g. recovered panics, are always delivered.
+type Suppressor struct {
+	windows *Registry
+	next    notify.Notifier
+	clock   clock.Clock
+	logger  *zap.Logger
+
+	mu         sync.Mutex
+	suppressed []Suppressed
+}
+
+// NewSuppressor creates a notifier delivering the alerts outside the windows through next, which may be nil to
+// only record the suppressed alerts.
+func NewSuppressor(windows *Registry, next notify.Notifier, logger *zap.Logger) *Suppressor {
+	return &Suppressor{
+		windows: windows,
+		next:    next,
+		clock:   clock.Real{},
+		logger:  logger,
+	}
+}
+
+// UseClock replaces the system clock the windows are checked by. Must be called before the notifier is used.
+func (s *Suppressor) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// Notify records the triggered alert when its rocket is under maintenance, otherwise delivers it. Acknowledgements
+// and resolutions are always delivered, so an alert triggered before a window is not left open by it.
+func (s *Suppressor) Notify(ctx context.Context, alert notify.Alert) error {
+	channel, _ := uuid.Parse(alert.Details["rocket_id"])
+	now := s.clock.Now()
+	var (
+		w  Window
+		ok bool
+	)
+	if alert.Action == "" || alert.Action == notify.ActionTrigger {
+		w, ok = s.windows.Covering(channel, rocket.Mission(alert.Details["mission"]), now)
+	}
+	if !ok {
+		if s.next == nil {
+			return nil
+		}
+		return s.next.Notify(ctx, alert)
+	}
+
+	s.mu.Lock()
+	s.suppressed = append(s.suppressed, Suppressed{Alert: alert, Window: w.ID, At: now.UTC()})
+	if len(s.suppressed) > maxSuppressed {
+		s.suppressed = slices.Delete(s.suppressed, 0, len(s.suppressed)-maxSuppressed)
+	}
+	s.mu.Unlock()
+	s.logger.Info("Alert suppressed by a maintenance window",
+		logging.Event(logging.EventAlertSuppressed),
+		zap.String("key", alert.Key),
+		zap.String("window", w.ID.String()),
+		zap.String("window_name", w.Name),
+	)
+	return nil
+}
+
+// Suppressed returns the suppressed alerts, the newest first
+func (s *Suppressor) Suppressed() []Suppressed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	suppressed := slices.Clone(s.suppressed)
+	slices.Reverse(suppressed)
+	if suppressed == nil {
+		suppressed = []Suppressed{}
+	}
+	return suppressed
+}
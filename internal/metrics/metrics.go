@@ -0,0 +1,259 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// kind - type of the metric as exposed to Prometheus
+type kind string
+
+const (
+	kindCounter kind = "counter"
+	kindGauge   kind = "gauge"
+)
+
+// Registry keeps the metrics of the service and renders them in the Prometheus text format
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]*metric
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*metric)}
+}
+
+// metric - family of series sharing a name and label names
+type metric struct {
+	name   string
+	help   string
+	kind   kind
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	mu     sync.Mutex
+	value  float64
+}
+
+// Counter - monotonically increasing value, partitioned by label values
+type Counter struct {
+	m *metric
+}
+
+// Gauge - value that can go up and down, partitioned by label values
+type Gauge struct {
+	m *metric
+}
+
+// Counter registers a counter, or returns the already registered one with the same name.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{m: r.register(name, help, kindCounter, labels)}
+}
+
+// Gauge registers a gauge, or returns the already registered one with the same name.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{m: r.register(name, help, kindGauge, labels)}
+}
+
+func (r *Registry) register(name, help string, k kind, labels []string) *metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.metrics[name]; ok {
+		if m.kind != k || len(m.labels) != len(labels) {
+			panic(fmt.Sprintf("metric %s is already registered as a different %s", name, m.kind))
+		}
+		return m
+	}
+	m := &metric{name: name, help: help, kind: k, labels: labels, series: make(map[string]*series)}
+	r.metrics[name] = m
+	return m
+}
+
+// with returns the series of the label values, creating it on first use
+func (m *metric) with(values []string) *series {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", m.name, len(m.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		m.series[key] = s
+	}
+	return s
+}
+
+// Inc increments the counter of the label values by one.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add increases the counter of the label values, negative deltas are ignored.
+func (c *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+	s := c.m.with(values)
+	s.mu.Lock()
+	s.value += delta
+	s.mu.Unlock()
+}
+
+// Value returns the current value of the counter of the label values.
+func (c *Counter) Value(values ...string) float64 {
+	s := c.m.with(values)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value
+}
+
+// Set sets the gauge of the label values.
+func (g *Gauge) Set(v float64, values ...string) {
+	s := g.m.with(values)
+	s.mu.Lock()
+	s.value = v
+	s.mu.Unlock()
+}
+
+// Add adds the delta to the gauge of the label values.
+func (g *Gauge) Add(delta float64, values ...string) {
+	s := g.m.with(values)
+	s.mu.Lock()
+	s.value += delta
+	s.mu.Unlock()
+}
+
+// Value returns the current value of the gauge of the label values.
+func (g *Gauge) Value(values ...string) float64 {
+	s := g.m.with(values)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value
+}
+
+// Family - snapshot of a metric and its series
+type Family struct {
+	Name string
+	Help string
+	// Counter - the values only increase, otherwise the family is a gauge
+	Counter bool
+	Labels  []string
+	Series  []Series
+}
+
+// Series - snapshot of the value of a metric for one set of label values
+type Series struct {
+	Values []string
+	Value  float64
+}
+
+// Snapshot returns the current values of all metrics, sorted by name and label values.
+func (r *Registry) Snapshot() []Family {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	families := make([]Family, 0, len(names))
+	for _, name := range names {
+		r.mu.Lock()
+		m := r.metrics[name]
+		r.mu.Unlock()
+
+		m.mu.Lock()
+		keys := make([]string, 0, len(m.series))
+		for key := range m.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		all := make([]*series, 0, len(keys))
+		for _, key := range keys {
+			all = append(all, m.series[key])
+		}
+		m.mu.Unlock()
+
+		family := Family{Name: m.name, Help: m.help, Counter: m.kind == kindCounter, Labels: m.labels}
+		for _, s := range all {
+			s.mu.Lock()
+			family.Series = append(family.Series, Series{Values: s.values, Value: s.value})
+			s.mu.Unlock()
+		}
+		families = append(families, family)
+	}
+	return families
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format, sorted by name and labels.
+func (r *Registry) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, family := range r.Snapshot() {
+		k := kindGauge
+		if family.Counter {
+			k = kindCounter
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", family.Name, k)
+
+		if len(family.Series) == 0 && len(family.Labels) == 0 {
+			fmt.Fprintf(&b, "%s 0\n", family.Name)
+		}
+		for _, s := range family.Series {
+			fmt.Fprintf(&b, "%s%s %s\n", family.Name, formatLabels(family.Labels, s.Values), formatValue(s.Value))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the metrics of the registry.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%q", name, values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("rockets_panics_total", "Recovered panics.")
+	requests := r.Counter("rockets_requests_total", "Served requests.", "route", "status")
+	rockets := r.Gauge("rockets_tracked", "Tracked rockets.")
+
+	requests.Inc("/messages", "202")
+	requests.Add(2, "/messages", "202")
+	requests.Inc("/v1/rockets", "200")
+	requests.Add(-5, "/v1/rockets", "200")
+	rockets.Set(3)
+	rockets.Add(-1)
+
+	if requests.Value("/messages", "202") != 3 {
+		t.Errorf("Expected counter value 3, got %v", requests.Value("/messages", "202"))
+	}
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	expected := `# HELP rockets_panics_total Recovered panics.
+# TYPE rockets_panics_total counter
+rockets_panics_total 0
+# HELP rockets_requests_total Served requests.
+# TYPE rockets_requests_total counter
+rockets_requests_total{route="/messages",status="202"} 3
+rockets_requests_total{route="/v1/rockets",status="200"} 1
+# HELP rockets_tracked Tracked rockets.
+# TYPE rockets_tracked gauge
+rockets_tracked 2
+`
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
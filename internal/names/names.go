@@ -0,0 +1,167 @@
+package names
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"rockets/internal/jsonfile"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidName - the name is empty, too long or has characters other than letters, digits, '.', '_' and '-'
+	ErrInvalidName = errors.New("invalid rocket name")
+	// ErrNameTaken - the name is assigned to another rocket
+	ErrNameTaken = errors.New("name is assigned to another rocket")
+)
+
+// Entry - human-friendly name assigned to a rocket channel
+type Entry struct {
+	Channel uuid.UUID `json:"channel"`
+	Name    string    `json:"name"`
+}
+
+// Registry - names of rocket channels, resolvable in both directions. Names are unique regardless of case
+// and keep the case they were assigned with. A nil registry has no names.
+type Registry struct {
+	mu sync.RWMutex
+	// byName - channels by the lower-cased name
+	byName    map[string]uuid.UUID
+	byChannel map[uuid.UUID]string
+	// file - JSON file the names are saved to on every change, empty keeps them in memory only
+	file string
+}
+
+// NewRegistry creates a registry keeping the names in memory only.
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]uuid.UUID), byChannel: make(map[uuid.UUID]string)}
+}
+
+// Open creates a registry saving the names to the file, loading the ones saved before if it exists.
+func Open(file string) (*Registry, error) {
+	r := NewRegistry()
+	r.file = file
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Refresh loads the names from the file again, picking up the changes saved by the leader while in standby.
+// A registry keeping them in memory only is left as it is.
+func (r *Registry) Refresh() error {
+	if r == nil || r.file == "" {
+		return nil
+	}
+	var entries []Entry
+	if _, err := jsonfile.Load(r.file, "names", &entries); err != nil {
+		return err
+	}
+	byName := make(map[string]uuid.UUID, len(entries))
+	byChannel := make(map[uuid.UUID]string, len(entries))
+	for _, e := range entries {
+		byName[strings.ToLower(e.Name)] = e.Channel
+		byChannel[e.Channel] = e.Name
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byName, r.byChannel = byName, byChannel
+	return nil
+}
+
+// Assign names the channel, replacing its previous name. It fails with ErrInvalidName or ErrNameTaken.
+func (r *Registry) Assign(channel uuid.UUID, name string) (Entry, error) {
+	if !jsonfile.ValidName.MatchString(name) {
+		return Entry{}, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(name)
+	if owner, ok := r.byName[key]; ok && owner != channel {
+		return Entry{}, fmt.Errorf("%w: %s is the name of %s", ErrNameTaken, name, owner)
+	}
+	prev, named := r.byChannel[channel]
+	if named {
+		delete(r.byName, strings.ToLower(prev))
+	}
+	r.byName[key] = channel
+	r.byChannel[channel] = name
+	if err := r.saveLocked(); err != nil {
+		delete(r.byName, key)
+		delete(r.byChannel, channel)
+		if named {
+			r.byName[strings.ToLower(prev)] = channel
+			r.byChannel[channel] = prev
+		}
+		return Entry{}, err
+	}
+	return Entry{Channel: channel, Name: name}, nil
+}
+
+// Remove drops the name of the channel, returning false if it had none
+func (r *Registry) Remove(channel uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name, ok := r.byChannel[channel]
+	if !ok {
+		return false, nil
+	}
+	delete(r.byName, strings.ToLower(name))
+	delete(r.byChannel, channel)
+	if err := r.saveLocked(); err != nil {
+		r.byName[strings.ToLower(name)] = channel
+		r.byChannel[channel] = name
+		return false, err
+	}
+	return true, nil
+}
+
+// Resolve returns the channel with the name, in any case
+func (r *Registry) Resolve(name string) (uuid.UUID, bool) {
+	if r == nil {
+		return uuid.Nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	channel, ok := r.byName[strings.ToLower(name)]
+	return channel, ok
+}
+
+// Name returns the name of the channel
+func (r *Registry) Name(channel uuid.UUID) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.byChannel[channel]
+	return name, ok
+}
+
+// List returns the names sorted by name
+func (r *Registry) List() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.listLocked()
+}
+
+func (r *Registry) listLocked() []Entry {
+	entries := make([]Entry, 0, len(r.byChannel))
+	for channel, name := range r.byChannel {
+		entries = append(entries, Entry{Channel: channel, Name: name})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// saveLocked writes the names to the file
+func (r *Registry) saveLocked() error {
+	if r.file == "" {
+		return nil
+	}
+	return jsonfile.Save(r.file, "names", r.listLocked())
+}
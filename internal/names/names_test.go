@@ -0,0 +1,55 @@
+package names
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "names.json")
+	r, err := Open(file)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first, second := uuid.New(), uuid.New()
+
+	if _, err := r.Assign(first, "N-1234"); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if _, err := r.Assign(second, "n-1234"); !errors.Is(err, ErrNameTaken) {
+		t.Errorf("Expected ErrNameTaken for a name taken in another case, got %v", err)
+	}
+	if _, err := r.Assign(second, "no spaces"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName, got %v", err)
+	}
+	if id, ok := r.Resolve("n-1234"); !ok || id != first {
+		t.Errorf("Expected the name to resolve to %s in any case, got %s", first, id)
+	}
+
+	// renaming frees the previous name
+	if _, err := r.Assign(first, "N-5678"); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if _, ok := r.Resolve("N-1234"); ok {
+		t.Errorf("Expected the previous name to be freed")
+	}
+	if _, err := r.Assign(second, "N-1234"); err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+
+	reopened, err := Open(file)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if name, ok := reopened.Name(first); !ok || name != "N-5678" {
+		t.Errorf("Expected the names to survive a restart, got %q", name)
+	}
+	if removed, err := reopened.Remove(second); !removed || err != nil {
+		t.Errorf("Expected the name removed, got %v, %v", removed, err)
+	}
+	if list := reopened.List(); len(list) != 1 || list[0].Channel != first {
+		t.Errorf("Expected one name left, got %+v", list)
+	}
+}
@@ -0,0 +1,153 @@
+package netacl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Group - set of routes sharing an allowlist
+type Group string
+
+const (
+	// GroupIngest - telemetry ingestion
+	GroupIngest Group = "ingest"
+	// GroupAPI - read API, status page and dashboard
+	GroupAPI Group = "api"
+	// GroupAdmin - admin API
+	GroupAdmin Group = "admin"
+)
+
+// ACL - network-level access control: per-group CIDR allowlists and a global, hot-reloadable denylist
+type ACL struct {
+	// allow - allowed prefixes per group, a group without prefixes is open to everyone
+	allow map[Group][]netip.Prefix
+	deny  atomic.Pointer[[]netip.Prefix]
+}
+
+// New creates an ACL from allowlists given as CIDRs or single addresses.
+func New(allow map[Group][]string) (*ACL, error) {
+	acl := &ACL{allow: make(map[Group][]netip.Prefix)}
+	for group, cidrs := range allow {
+		prefixes, err := ParsePrefixes(cidrs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s allowlist: %w", group, err)
+		}
+		acl.allow[group] = prefixes
+	}
+	acl.SetDenylist(nil)
+	return acl, nil
+}
+
+// Allowed reports whether the address may access the group.
+// A nil ACL allows everything.
+func (a *ACL) Allowed(group Group, addr netip.Addr) bool {
+	if a == nil {
+		return true
+	}
+	addr = addr.Unmap()
+	if contains(*a.deny.Load(), addr) {
+		return false
+	}
+	allow := a.allow[group]
+	return len(allow) == 0 || contains(allow, addr)
+}
+
+// SetDenylist atomically replaces the denylist
+func (a *ACL) SetDenylist(prefixes []netip.Prefix) {
+	a.deny.Store(&prefixes)
+}
+
+// WatchDenylist loads the denylist file and reloads it whenever its modification time changes,
+// until the context is done. A file that fails to parse keeps the previous denylist in effect.
+func (a *ACL) WatchDenylist(ctx context.Context, path string, interval time.Duration, logger *zap.Logger) error {
+	var modTime time.Time
+	reload := func() {
+		info, err := os.Stat(path)
+		if err != nil {
+			logger.Error("Can't stat IP denylist", zap.String("path", path), zap.Error(err))
+			return
+		}
+		if info.ModTime().Equal(modTime) {
+			return
+		}
+		prefixes, err := LoadFile(path)
+		if err != nil {
+			logger.Error("Can't reload IP denylist, keeping the previous one", zap.String("path", path), zap.Error(err))
+			return
+		}
+		modTime = info.ModTime()
+		a.SetDenylist(prefixes)
+		logger.Info("IP denylist loaded", zap.String("path", path), zap.Int("prefixes", len(prefixes)))
+	}
+
+	reload()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			reload()
+		}
+	}
+}
+
+// LoadFile reads prefixes from a file with one CIDR or address per line; blank lines and # comments are skipped.
+func LoadFile(path string) ([]netip.Prefix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cidrs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			cidrs = append(cidrs, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ParsePrefixes(cidrs)
+}
+
+// ParsePrefixes parses CIDRs, treating single addresses as host prefixes
+func ParsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
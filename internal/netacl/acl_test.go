@@ -0,0 +1,82 @@
+package netacl
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestACL_Allowed(t *testing.T) {
+	acl, err := New(map[Group][]string{
+		GroupIngest: {"10.0.0.0/8", "192.168.1.7"},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	acl.SetDenylist([]netip.Prefix{netip.MustParsePrefix("10.6.0.0/16")})
+
+	cases := []struct {
+		group    Group
+		addr     string
+		expected bool
+	}{
+		{GroupIngest, "10.1.2.3", true},
+		{GroupIngest, "::ffff:10.1.2.3", true},
+		{GroupIngest, "192.168.1.7", true},
+		{GroupIngest, "192.168.1.8", false},
+		{GroupIngest, "10.6.1.1", false},
+		// groups without an allowlist are open, but the denylist applies everywhere
+		{GroupAPI, "8.8.8.8", true},
+		{GroupAPI, "10.6.1.1", false},
+	}
+	for _, c := range cases {
+		if got := acl.Allowed(c.group, netip.MustParseAddr(c.addr)); got != c.expected {
+			t.Errorf("Allowed(%s, %s): expected %v, got %v", c.group, c.addr, c.expected, got)
+		}
+	}
+
+	if _, err := New(map[Group][]string{GroupAdmin: {"not-an-ip"}}); err == nil {
+		t.Errorf("Expected an error for an invalid allowlist")
+	}
+}
+
+func TestACL_WatchDenylist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist")
+	if err := os.WriteFile(path, []byte("# abusive station\n203.0.113.0/24\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	acl, _ := New(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = acl.WatchDenylist(ctx, path, 10*time.Millisecond, zap.NewNop())
+	}()
+
+	waitFor := func(addr string, allowed bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for acl.Allowed(GroupAPI, netip.MustParseAddr(addr)) != allowed {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %s allowed=%v", addr, allowed)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor("203.0.113.5", false)
+
+	later := time.Now().Add(time.Minute)
+	if err := os.WriteFile(path, []byte("198.51.100.1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("203.0.113.5", true)
+	waitFor("198.51.100.1", false)
+}
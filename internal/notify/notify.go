@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"rockets/internal/retry"
+	"time"
+)
+
+// Severity - urgency of the alert
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Action - what an alert does to the problem identified by its key
+type Action string
+
+const (
+	// ActionTrigger - the problem started or goes on
+	ActionTrigger Action = "trigger"
+	// ActionAcknowledge - someone is handling the problem
+	ActionAcknowledge Action = "acknowledge"
+	// ActionResolve - the problem is over
+	ActionResolve Action = "resolve"
+)
+
+// Alert - notification for the on-call engineer
+type Alert struct {
+	// Key - identifies the alert, notifications with the same key refer to the same problem
+	Key string `json:"key"`
+	// Action - empty triggers the alert
+	Action   Action            `json:"action,omitempty"`
+	Severity Severity          `json:"severity"`
+	Title    string            `json:"title"`
+	Details  map[string]string `json:"details,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Notifier - delivers alerts to an external system
+type Notifier interface {
+	// Notify delivers the alert
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Nop - notifier dropping every alert, used when alerting is not configured
+type Nop struct{}
+
+// Notify drops the alert
+func (Nop) Notify(context.Context, Alert) error { return nil }
+
+// Multi - notifier delivering every alert to all of its notifiers
+type Multi []Notifier
+
+// Notify delivers the alert to every notifier, even when some of them fail, and returns their errors joined
+func (m Multi) Notify(ctx context.Context, alert Alert) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Webhook - notifier posting alerts as JSON to an HTTP endpoint
+type Webhook struct {
+	url     string
+	client  *http.Client
+	retrier *retry.Retrier
+}
+
+// NewWebhook creates a notifier posting alerts to the url.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// UseRetry retries failed deliveries with the retrier. Must be called before the notifier is used.
+func (w *Webhook) UseRetry(r *retry.Retrier) {
+	w.retrier = r
+}
+
+// Notify posts the alert, any non-2xx response is an error. Every attempt carries the same Idempotency-Key
+// header, so the receiver can drop duplicates of a delivery that timed out after all.
+func (w *Webhook) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("can't marshal alert: %w", err)
+	}
+	key := uuid.NewString()
+	if w.retrier == nil {
+		return w.post(ctx, body, key)
+	}
+	return w.retrier.Do(ctx, "webhook", func(ctx context.Context) error {
+		return w.post(ctx, body, key)
+	})
+}
+
+// post makes one delivery attempt, client errors other than 429 are permanent
+func (w *Webhook) post(ctx context.Context, body []byte, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("can't create alert request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	return send(w.client, req)
+}
+
+// send makes the request, any non-2xx response is an error and client errors other than 429 are permanent
+func send(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't post alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("can't post alert: unexpected status %s", resp.Status)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
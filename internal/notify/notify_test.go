@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/metrics"
+	"rockets/internal/retry"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	var got Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Can't decode alert: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	alert := Alert{
+		Key:      "panic/abc",
+		Severity: SeverityCritical,
+		Title:    "Panic",
+		Details:  map[string]string{"route": "/messages"},
+		Time:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := NewWebhook(srv.URL).Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if got.Key != alert.Key || got.Details["route"] != "/messages" || !got.Time.Equal(alert.Time) {
+		t.Errorf("Expected %+v, got %+v", alert, got)
+	}
+}
+
+func TestWebhook_NotifyFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	if err := NewWebhook(srv.URL).Notify(context.Background(), Alert{}); err == nil {
+		t.Errorf("Expected an error for a non-2xx response")
+	}
+}
+
+func TestWebhook_NotifyRetry(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	webhook := NewWebhook(srv.URL)
+	webhook.UseRetry(retry.New(retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, metrics.NewRegistry()))
+	if err := webhook.Notify(context.Background(), Alert{Key: "panic/abc"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Expected 2 attempts with the same idempotency key, got %q", keys)
+	}
+}
+
+func TestWebhook_NotifyRejected(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	webhook := NewWebhook(srv.URL)
+	webhook.UseRetry(retry.New(retry.Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, metrics.NewRegistry()))
+	if err := webhook.Notify(context.Background(), Alert{}); err == nil || calls != 1 {
+		t.Errorf("Expected a rejected alert not to be retried, got %v after %d calls", err, calls)
+	}
+}
+
+func TestPagerDuty_Notify(t *testing.T) {
+	var events []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("Can't decode event: %v", err)
+		}
+		events = append(events, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	pd := NewPagerDuty(srv.URL, "routing-key")
+	alert := Alert{
+		Key:      "rocket/193270a9-c9cf-404a-8f83-838e71d9ae67/lost",
+		Severity: SeverityCritical,
+		Title:    "Rocket lost",
+		Details:  map[string]string{"mission": "ARTEMIS"},
+		Time:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := pd.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	alert.Action = ActionResolve
+	if err := pd.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	trigger, resolve := events[0], events[1]
+	payload, _ := trigger["payload"].(map[string]any)
+	if trigger["event_action"] != "trigger" || trigger["routing_key"] != "routing-key" || trigger["dedup_key"] != alert.Key ||
+		payload["severity"] != "critical" || payload["summary"] != "Rocket lost" || payload["timestamp"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("Expected a triggering event, got %v", trigger)
+	}
+	if resolve["event_action"] != "resolve" || resolve["dedup_key"] != alert.Key || resolve["payload"] != nil {
+		t.Errorf("Expected a resolving event of the same key, got %v", resolve)
+	}
+}
+
+func TestOpsgenie_Notify(t *testing.T) {
+	var paths, auths []string
+	var created opsgenieAlert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		auths = append(auths, r.Header.Get("Authorization"))
+		if len(paths) == 1 {
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("Can't decode alert: %v", err)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	og := NewOpsgenie(srv.URL, "api-key")
+	alert := Alert{Key: "rocket/abc/explosion", Severity: SeverityCritical, Title: "Rocket exploded"}
+	for _, action := range []Action{ActionTrigger, ActionAcknowledge, ActionResolve} {
+		alert.Action = action
+		if err := og.Notify(context.Background(), alert); err != nil {
+			t.Fatalf("Notify failed: %v", err)
+		}
+	}
+
+	expected := []string{
+		"/v2/alerts",
+		"/v2/alerts/rocket%2Fabc%2Fexplosion/acknowledge?identifierType=alias",
+		"/v2/alerts/rocket%2Fabc%2Fexplosion/close?identifierType=alias",
+	}
+	if len(paths) != 3 || paths[0] != expected[0] || paths[1] != expected[1] || paths[2] != expected[2] {
+		t.Errorf("Expected %q, got %q", expected, paths)
+	}
+	if auths[0] != "GenieKey api-key" {
+		t.Errorf("Expected the API key in the Authorization header, got %q", auths[0])
+	}
+	if created.Alias != alert.Key || created.Message != "Rocket exploded" || created.Priority != "P1" {
+		t.Errorf("Expected the alert created under the key, got %+v", created)
+	}
+}
+
+// failing - notifier failing every alert
+type failing struct{}
+
+func (failing) Notify(context.Context, Alert) error { return errors.New("unreachable") }
+
+func TestMulti_Notify(t *testing.T) {
+	delivered := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered++
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	err := Multi{failing{}, NewWebhook(srv.URL)}.Notify(context.Background(), Alert{Key: "panic/abc"})
+	if err == nil || delivered != 1 {
+		t.Errorf("Expected the alert delivered past a failing notifier and the failure returned, got %v after %d deliveries", err, delivered)
+	}
+}
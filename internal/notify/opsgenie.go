@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"rockets/internal/retry"
+	"time"
+)
+
+// opsgeniePriorities - priority of the Opsgenie alerts by severity
+var opsgeniePriorities = map[Severity]string{
+	SeverityCritical: "P1",
+	SeverityWarning:  "P3",
+	SeverityInfo:     "P5",
+}
+
+// opsgenieAlert - alert created through the Opsgenie Alert API
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority,omitempty"`
+	Source      string            `json:"source"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// opsgenieAction - body of the acknowledge and close requests
+type opsgenieAction struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+// Opsgenie - notifier creating, acknowledging and closing Opsgenie alerts through the Alert API. The key of the
+// alert is the alias, so the alerts of a problem are deduplicated into one Opsgenie alert.
+type Opsgenie struct {
+	url     string
+	apiKey  string
+	client  *http.Client
+	retrier *retry.Retrier
+}
+
+// NewOpsgenie creates a notifier calling the Opsgenie API at the base url, https://api.opsgenie.com or
+// https://api.eu.opsgenie.com for accounts in the EU region, with the key of an API integration.
+func NewOpsgenie(url, apiKey string) *Opsgenie {
+	return &Opsgenie{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// UseRetry retries failed deliveries with the retrier. Must be called before the notifier is used.
+func (o *Opsgenie) UseRetry(r *retry.Retrier) {
+	o.retrier = r
+}
+
+// Notify creates the alert, or acknowledges or closes the alert with its key. Opsgenie counts repeated creations
+// of an open alert instead of creating another one, so retries are safe.
+func (o *Opsgenie) Notify(ctx context.Context, alert Alert) error {
+	path := "/v2/alerts"
+	var payload any
+	switch alert.Action {
+	case ActionAcknowledge:
+		path += "/" + url.PathEscape(alert.Key) + "/acknowledge?identifierType=alias"
+		payload = opsgenieAction{Source: "rockets", Note: alert.Title}
+	case ActionResolve:
+		path += "/" + url.PathEscape(alert.Key) + "/close?identifierType=alias"
+		payload = opsgenieAction{Source: "rockets", Note: alert.Title}
+	default:
+		payload = opsgenieAlert{
+			Message:     truncate(alert.Title, 130),
+			Alias:       truncate(alert.Key, 512),
+			Description: alert.Title,
+			Priority:    opsgeniePriorities[alert.Severity],
+			Source:      "rockets",
+			Details:     alert.Details,
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("can't marshal Opsgenie alert: %w", err)
+	}
+	if o.retrier == nil {
+		return o.post(ctx, path, body)
+	}
+	return o.retrier.Do(ctx, "opsgenie", func(ctx context.Context) error {
+		return o.post(ctx, path, body)
+	})
+}
+
+// post makes one delivery attempt
+func (o *Opsgenie) post(ctx context.Context, path string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url+path, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("can't create Opsgenie request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.apiKey)
+	return send(o.client, req)
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rockets/internal/retry"
+	"strings"
+	"time"
+)
+
+// pagerDutyEvent - event of the PagerDuty Events API v2
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload - details of a triggering event
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      Severity          `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// PagerDuty - notifier opening, acknowledging and resolving PagerDuty incidents through the Events API v2. The
+// key of the alert is the dedup key, so the alerts of a problem land on one incident.
+type PagerDuty struct {
+	url        string
+	routingKey string
+	client     *http.Client
+	retrier    *retry.Retrier
+}
+
+// NewPagerDuty creates a notifier sending the events to the url, https://events.pagerduty.com/v2/enqueue unless
+// testing, with the routing key of the integration of a service.
+func NewPagerDuty(url, routingKey string) *PagerDuty {
+	return &PagerDuty{
+		url:        url,
+		routingKey: routingKey,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// UseRetry retries failed deliveries with the retrier. Must be called before the notifier is used.
+func (p *PagerDuty) UseRetry(r *retry.Retrier) {
+	p.retrier = r
+}
+
+// Notify sends the alert as an event, triggering unless the alert acknowledges or resolves its key. PagerDuty
+// drops repeated triggers of an open incident, so retries are safe.
+func (p *PagerDuty) Notify(ctx context.Context, alert Alert) error {
+	event := pagerDutyEvent{RoutingKey: p.routingKey, EventAction: string(ActionTrigger), DedupKey: alert.Key}
+	switch alert.Action {
+	case ActionAcknowledge, ActionResolve:
+		event.EventAction = string(alert.Action)
+	default:
+		event.Payload = &pagerDutyPayload{
+			Summary:       truncate(alert.Title, 1024),
+			Source:        "rockets",
+			Severity:      alert.Severity,
+			Timestamp:     alert.Time.UTC().Format(time.RFC3339),
+			CustomDetails: alert.Details,
+		}
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("can't marshal PagerDuty event: %w", err)
+	}
+	if p.retrier == nil {
+		return p.post(ctx, body)
+	}
+	return p.retrier.Do(ctx, "pagerduty", func(ctx context.Context) error {
+		return p.post(ctx, body)
+	})
+}
+
+// post makes one delivery attempt
+func (p *PagerDuty) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("can't create PagerDuty request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return send(p.client, req)
+}
+
+// truncate cuts the text to at most n bytes without splitting a character
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	return strings.ToValidUTF8(text[:n], "")
+}
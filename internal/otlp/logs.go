@@ -0,0 +1,196 @@
+package otlp
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type logsRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource  resource    `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type scopeLogs struct {
+	Scope      scope       `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+type logRecord struct {
+	TimeUnixNano   string     `json:"timeUnixNano"`
+	SeverityNumber int        `json:"severityNumber"`
+	SeverityText   string     `json:"severityText"`
+	Body           anyValue   `json:"body"`
+	Attributes     []keyValue `json:"attributes,omitempty"`
+}
+
+func (e *Exporter) logsRequest(records []logRecord) logsRequest {
+	return logsRequest{ResourceLogs: []resourceLogs{{
+		Resource:  serviceResource(),
+		ScopeLogs: []scopeLogs{{Scope: scope{Name: serviceName}, LogRecords: records}},
+	}}}
+}
+
+// logBuffer - log records waiting for the next push
+type logBuffer struct {
+	mu      sync.Mutex
+	records []logRecord
+	dropped int
+}
+
+// add buffers the record, dropping the oldest one when the buffer is full
+func (b *logBuffer) add(record logRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.records) >= maxBufferedLogs {
+		b.records = b.records[1:]
+		b.dropped++
+	}
+	b.records = append(b.records, record)
+}
+
+// take returns the buffered records and the number of dropped ones, emptying the buffer
+func (b *logBuffer) take() ([]logRecord, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records, dropped := b.records, b.dropped
+	b.records, b.dropped = nil, 0
+	return records, dropped
+}
+
+// logCore - zap core converting entries into OTLP log records
+type logCore struct {
+	zapcore.LevelEnabler
+	buf    *logBuffer
+	fields []zapcore.Field
+}
+
+func (c *logCore) With(fields []zapcore.Field) zapcore.Core {
+	return &logCore{
+		LevelEnabler: c.LevelEnabler,
+		buf:          c.buf,
+		fields:       append(append([]zapcore.Field(nil), c.fields...), fields...),
+	}
+}
+
+func (c *logCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *logCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	if entry.LoggerName != "" {
+		enc.AddString("logger", entry.LoggerName)
+	}
+	if entry.Stack != "" {
+		enc.AddString("exception.stacktrace", entry.Stack)
+	}
+
+	severity, text := severityOf(entry.Level)
+	c.buf.add(logRecord{
+		TimeUnixNano:   unixNano(entry.Time),
+		SeverityNumber: severity,
+		SeverityText:   text,
+		Body:           stringValue(entry.Message),
+		Attributes:     attributes(enc.Fields),
+	})
+	return nil
+}
+
+func (c *logCore) Sync() error {
+	return nil
+}
+
+// severityOf maps the zap level to the OTLP severity number and text
+func severityOf(level zapcore.Level) (int, string) {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return 5, "DEBUG"
+	case level == zapcore.InfoLevel:
+		return 9, "INFO"
+	case level == zapcore.WarnLevel:
+		return 13, "WARN"
+	case level == zapcore.ErrorLevel:
+		return 17, "ERROR"
+	default:
+		return 21, "FATAL"
+	}
+}
+
+// attributes converts the encoded fields, sorted by key; values without an OTLP counterpart become JSON strings
+func attributes(fields map[string]any) []keyValue {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]keyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, keyValue{Key: k, Value: valueOf(fields[k])})
+	}
+	return attrs
+}
+
+func valueOf(v any) anyValue {
+	switch v := v.(type) {
+	case string:
+		return stringValue(v)
+	case bool:
+		return anyValue{BoolValue: &v}
+	case int:
+		return intValue(int64(v))
+	case int8:
+		return intValue(int64(v))
+	case int16:
+		return intValue(int64(v))
+	case int32:
+		return intValue(int64(v))
+	case int64:
+		return intValue(v)
+	case uint8:
+		return intValue(int64(v))
+	case uint16:
+		return intValue(int64(v))
+	case uint32:
+		return intValue(int64(v))
+	case float32:
+		f := float64(v)
+		return anyValue{DoubleValue: &f}
+	case float64:
+		return anyValue{DoubleValue: &v}
+	case time.Time:
+		return stringValue(v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return stringValue(v.String())
+	case fmt.Stringer:
+		return stringValue(v.String())
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return stringValue(fmt.Sprint(v))
+	}
+	return stringValue(string(b))
+}
+
+func intValue(i int64) anyValue {
+	s := strconv.FormatInt(i, 10)
+	return anyValue{IntValue: &s}
+}
@@ -0,0 +1,83 @@
+package otlp
+
+import (
+	"rockets/internal/metrics"
+)
+
+// aggregationTemporalityCumulative - counters report the total since the exporter started
+const aggregationTemporalityCumulative = 2
+
+type metricsRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type metric struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Sum         *sum   `json:"sum,omitempty"`
+	Gauge       *gauge `json:"gauge,omitempty"`
+}
+
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+// metricsRequest converts the snapshot of the registry: counters into cumulative monotonic sums, gauges into gauges
+func (e *Exporter) metricsRequest(families []metrics.Family) metricsRequest {
+	now := unixNano(e.clock.Now())
+	start := unixNano(e.start)
+
+	converted := make([]metric, 0, len(families))
+	for _, family := range families {
+		points := make([]numberDataPoint, 0, len(family.Series))
+		for _, s := range family.Series {
+			point := numberDataPoint{TimeUnixNano: now, AsDouble: s.Value}
+			for i, label := range family.Labels {
+				point.Attributes = append(point.Attributes, keyValue{Key: label, Value: stringValue(s.Values[i])})
+			}
+			if family.Counter {
+				point.StartTimeUnixNano = start
+			}
+			points = append(points, point)
+		}
+		if len(points) == 0 {
+			continue
+		}
+
+		m := metric{Name: family.Name, Description: family.Help}
+		if family.Counter {
+			m.Sum = &sum{DataPoints: points, AggregationTemporality: aggregationTemporalityCumulative, IsMonotonic: true}
+		} else {
+			m.Gauge = &gauge{DataPoints: points}
+		}
+		converted = append(converted, m)
+	}
+
+	return metricsRequest{ResourceMetrics: []resourceMetrics{{
+		Resource:     serviceResource(),
+		ScopeMetrics: []scopeMetrics{{Scope: scope{Name: serviceName}, Metrics: converted}},
+	}}}
+}
@@ -0,0 +1,206 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"rockets/internal/buildinfo"
+	"rockets/internal/clock"
+	"rockets/internal/config"
+	"rockets/internal/logging"
+	"rockets/internal/metrics"
+	"rockets/internal/retry"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serviceName - service.name resource attribute of the exported telemetry
+const serviceName = "rockets"
+
+// maxBufferedLogs - log records kept between pushes, older ones are dropped when the collector is unreachable
+const maxBufferedLogs = 10000
+
+// Exporter pushes the service's own metrics, logs and traces to an OpenTelemetry collector over OTLP/HTTP,
+// using the JSON encoding of the protocol, so sites without Prometheus scraping still get its telemetry
+type Exporter struct {
+	cfg     config.OTLP
+	client  *http.Client
+	retrier *retry.Retrier
+	clock   clock.Clock
+	// start - start time of the cumulative counters
+	start time.Time
+	logs  *logBuffer
+	spans *spanBuffer
+}
+
+// NewExporter creates an exporter pushing to the collector in the settings.
+func NewExporter(cfg config.OTLP) *Exporter {
+	return &Exporter{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		clock:  clock.Real{},
+		start:  time.Now(),
+		logs:   &logBuffer{},
+		spans:  &spanBuffer{},
+	}
+}
+
+// UseRetry retries failed pushes with the retrier. Must be called before the exporter runs.
+func (e *Exporter) UseRetry(r *retry.Retrier) {
+	e.retrier = r
+}
+
+// UseClock replaces the system clock timestamping the metrics, e.g. with a fake one in tests.
+// Must be called before the exporter runs.
+func (e *Exporter) UseClock(c clock.Clock) {
+	e.clock = c
+	e.start = c.Now()
+}
+
+// Core returns a logger core buffering the entries enabled by the level for the next push.
+func (e *Exporter) Core(level zapcore.LevelEnabler) zapcore.Core {
+	return &logCore{LevelEnabler: level, buf: e.logs}
+}
+
+// Run pushes the metrics of the registry, the buffered logs and spans every interval until the context is done,
+// then pushes once more. Failures are logged.
+func (e *Exporter) Run(ctx context.Context, registry *metrics.Registry, logger *zap.Logger) error {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// the service is going down, give the last push a moment of its own
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			e.push(ctx, registry, logger)
+			cancel()
+			return nil
+		case <-ticker.C:
+			e.push(ctx, registry, logger)
+		}
+	}
+}
+
+// push sends the enabled signals, failures are logged
+func (e *Exporter) push(ctx context.Context, registry *metrics.Registry, logger *zap.Logger) {
+	if e.cfg.Metrics {
+		if err := e.send(ctx, "/v1/metrics", e.metricsRequest(registry.Snapshot())); err != nil {
+			logger.Error("Can't export metrics", logging.Event(logging.EventDeliveryError), zap.String("endpoint", e.cfg.Endpoint), zap.Error(err))
+		}
+	}
+	if e.cfg.Logs {
+		e.pushLogs(ctx, logger)
+	}
+	if e.cfg.Traces {
+		e.pushSpans(ctx, logger)
+	}
+}
+
+func (e *Exporter) pushLogs(ctx context.Context, logger *zap.Logger) {
+	records, dropped := e.logs.take()
+	if dropped > 0 {
+		logger.Warn("Log records dropped before export", zap.Int("dropped", dropped))
+	}
+	if len(records) == 0 {
+		return
+	}
+	if err := e.send(ctx, "/v1/logs", e.logsRequest(records)); err != nil {
+		logger.Error("Can't export logs", logging.Event(logging.EventDeliveryError), zap.String("endpoint", e.cfg.Endpoint), zap.Int("records", len(records)), zap.Error(err))
+	}
+}
+
+func (e *Exporter) pushSpans(ctx context.Context, logger *zap.Logger) {
+	spans, dropped := e.spans.take()
+	if dropped > 0 {
+		logger.Warn("Spans dropped before export", zap.Int("dropped", dropped))
+	}
+	if len(spans) == 0 {
+		return
+	}
+	if err := e.send(ctx, "/v1/traces", e.tracesRequest(spans)); err != nil {
+		logger.Error("Can't export spans", logging.Event(logging.EventDeliveryError), zap.String("endpoint", e.cfg.Endpoint), zap.Int("spans", len(spans)), zap.Error(err))
+	}
+}
+
+// send posts the request to the signal path of the collector, with retries when configured
+func (e *Exporter) send(ctx context.Context, path string, request any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("can't marshal OTLP request: %w", err)
+	}
+	if e.retrier == nil {
+		return e.post(ctx, path, body)
+	}
+	return e.retrier.Do(ctx, "otlp", func(ctx context.Context) error {
+		return e.post(ctx, path, body)
+	})
+}
+
+// post makes one push attempt, client errors other than 429 are permanent
+func (e *Exporter) post(ctx context.Context, path string, body []byte) error {
+	url := strings.TrimSuffix(e.cfg.Endpoint, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("can't create OTLP request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't post to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("can't post to %s: unexpected status %s", url, resp.Status)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// keyValue - attribute of a resource, data point or log record
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// anyValue - attribute value, exactly one field is set; 64-bit integers are strings in the JSON encoding
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+func stringValue(s string) anyValue {
+	return anyValue{StringValue: &s}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func serviceResource() resource {
+	return resource{Attributes: []keyValue{
+		{Key: "service.name", Value: stringValue(serviceName)},
+		{Key: "service.version", Value: stringValue(buildinfo.Get().Version)},
+	}}
+}
@@ -0,0 +1,144 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/clock"
+	"rockets/internal/config"
+	"rockets/internal/metrics"
+	"rockets/internal/tracing"
+	"testing"
+	"time"
+)
+
+func TestExporter_Push(t *testing.T) {
+	received := make(map[string]map[string]any)
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Can't decode %s: %v", r.URL.Path, err)
+		}
+		received[r.URL.Path] = body
+		auth = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	e := NewExporter(config.OTLP{Endpoint: srv.URL + "/", Headers: map[string]string{"Authorization": "Bearer secret"}, Interval: time.Second, Metrics: true, Logs: true})
+	e.UseClock(clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	registry := metrics.NewRegistry()
+	registry.Counter("rockets_http_panics_total", "Panics recovered by the HTTP server.", "route").Inc("/messages")
+	registry.Gauge("rockets_idle", "Unused gauge.")
+
+	logger := zap.New(e.Core(zap.InfoLevel)).With(zap.String("rocket_id", "193270a9"))
+	logger.Debug("Not exported")
+	logger.Warn("Channel quarantined", zap.Int64("msg_num", 7), zap.Error(errors.New("invalid speed")))
+
+	e.push(context.Background(), registry, zap.NewNop())
+
+	if auth != "Bearer secret" {
+		t.Errorf("Expected the configured headers, got %q", auth)
+	}
+	metric := dig(t, received["/v1/metrics"], "resourceMetrics", 0, "scopeMetrics", 0, "metrics")
+	if n := len(metric.([]any)); n != 1 {
+		t.Fatalf("Expected only the metric with series to be exported, got %d", n)
+	}
+	point := dig(t, metric, 0, "sum", "dataPoints", 0).(map[string]any)
+	if point["asDouble"] != 1.0 || point["startTimeUnixNano"] != "1704067200000000000" || dig(t, point, "attributes", 0, "value", "stringValue") != "/messages" {
+		t.Errorf("Unexpected data point %v", point)
+	}
+
+	records := dig(t, received["/v1/logs"], "resourceLogs", 0, "scopeLogs", 0, "logRecords").([]any)
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 exported log record, got %d", len(records))
+	}
+	record := records[0].(map[string]any)
+	if record["severityText"] != "WARN" || dig(t, record, "body", "stringValue") != "Channel quarantined" {
+		t.Errorf("Unexpected log record %v", record)
+	}
+	want := map[string]string{"error": "invalid speed", "msg_num": "7", "rocket_id": "193270a9"}
+	for _, attr := range record["attributes"].([]any) {
+		kv := attr.(map[string]any)
+		value := kv["value"].(map[string]any)
+		got, _ := value["stringValue"].(string)
+		if got == "" {
+			got, _ = value["intValue"].(string)
+		}
+		if want[kv["key"].(string)] != got {
+			t.Errorf("Unexpected attribute %v", kv)
+		}
+		delete(want, kv["key"].(string))
+	}
+	if len(want) > 0 {
+		t.Errorf("Expected attributes %v to be exported", want)
+	}
+
+	received = make(map[string]map[string]any)
+	e.push(context.Background(), registry, zap.NewNop())
+	if _, ok := received["/v1/logs"]; ok {
+		t.Errorf("Expected exported log records not to be sent again")
+	}
+}
+
+// dig walks the decoded JSON through object keys and array indexes
+func dig(t *testing.T, v any, path ...any) any {
+	t.Helper()
+	for _, p := range path {
+		switch p := p.(type) {
+		case string:
+			m, ok := v.(map[string]any)
+			if !ok {
+				t.Fatalf("Expected an object at %q, got %v", p, v)
+			}
+			v = m[p]
+		case int:
+			a, ok := v.([]any)
+			if !ok || len(a) <= p {
+				t.Fatalf("Expected an array with item %d, got %v", p, v)
+			}
+			v = a[p]
+		}
+	}
+	return v
+}
+
+func TestExporter_PushSpans(t *testing.T) {
+	var received map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("Expected only traces to be pushed, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Can't decode %s: %v", r.URL.Path, err)
+		}
+	}))
+	defer srv.Close()
+
+	e := NewExporter(config.OTLP{Endpoint: srv.URL, Interval: time.Second, Traces: true})
+	tracer := tracing.NewTracer(e)
+	parent, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := tracer.Start(tracing.WithSpanContext(context.Background(), parent), "rocket.ProcessMessage", tracing.KindInternal)
+	span.SetAttribute("message.number", int64(7))
+	span.Fail(errors.New("invalid speed"))
+	span.Finish()
+
+	e.push(context.Background(), metrics.NewRegistry(), zap.NewNop())
+
+	got := dig(t, received, "resourceSpans", 0, "scopeSpans", 0, "spans", 0).(map[string]any)
+	if got["traceId"] != "4bf92f3577b34da6a3ce929d0e0e4736" || got["parentSpanId"] != "00f067aa0ba902b7" || got["spanId"] != span.Context.SpanID.String() {
+		t.Errorf("Expected the span to continue the producer's trace, got %v", got)
+	}
+	if got["name"] != "rocket.ProcessMessage" || got["kind"] != 1.0 {
+		t.Errorf("Unexpected span %v", got)
+	}
+	if dig(t, got, "attributes", 0, "value", "intValue") != "7" {
+		t.Errorf("Expected the message number attribute, got %v", got["attributes"])
+	}
+	if dig(t, got, "status", "code") != 2.0 || dig(t, got, "status", "message") != "invalid speed" {
+		t.Errorf("Expected an error status, got %v", got["status"])
+	}
+}
@@ -0,0 +1,106 @@
+package otlp
+
+import (
+	"rockets/internal/tracing"
+	"sort"
+	"sync"
+)
+
+// maxBufferedSpans - spans kept between pushes, older ones are dropped when the collector is unreachable
+const maxBufferedSpans = 10000
+
+type tracesRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type scopeSpans struct {
+	Scope scope  `json:"scope"`
+	Spans []span `json:"spans"`
+}
+
+// span - OTLP span, ids are hex strings in the JSON encoding
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            spanStatus `json:"status"`
+}
+
+// spanStatus - outcome of the span, code 1 is ok and 2 is error
+type spanStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func (e *Exporter) tracesRequest(spans []span) tracesRequest {
+	return tracesRequest{ResourceSpans: []resourceSpans{{
+		Resource:   serviceResource(),
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: serviceName}, Spans: spans}},
+	}}}
+}
+
+// RecordSpan buffers the finished span for the next push
+func (e *Exporter) RecordSpan(s *tracing.Span) {
+	out := span{
+		TraceID:           s.Context.TraceID.String(),
+		SpanID:            s.Context.SpanID.String(),
+		Name:              s.Name,
+		Kind:              int(s.Kind),
+		StartTimeUnixNano: unixNano(s.Start),
+		EndTimeUnixNano:   unixNano(s.End),
+		Status:            spanStatus{Code: 1},
+	}
+	if s.Parent != (tracing.SpanID{}) {
+		out.ParentSpanID = s.Parent.String()
+	}
+	attrs := s.Attributes()
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		out.Attributes = append(out.Attributes, keyValue{Key: k, Value: valueOf(attrs[k])})
+	}
+	if s.Error != "" {
+		out.Status = spanStatus{Code: 2, Message: s.Error}
+	}
+	e.spans.add(out)
+}
+
+// spanBuffer - spans waiting for the next push
+type spanBuffer struct {
+	mu      sync.Mutex
+	spans   []span
+	dropped int
+}
+
+// add buffers the span, dropping the oldest one when the buffer is full
+func (b *spanBuffer) add(s span) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.spans) >= maxBufferedSpans {
+		b.spans = b.spans[1:]
+		b.dropped++
+	}
+	b.spans = append(b.spans, s)
+}
+
+// take returns the buffered spans and the number of dropped ones, emptying the buffer
+func (b *spanBuffer) take() ([]span, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	spans, dropped := b.spans, b.dropped
+	b.spans, b.dropped = nil, 0
+	return spans, dropped
+}
@@ -0,0 +1,121 @@
+package partition
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"rockets/internal/rocket"
+	"strings"
+	"time"
+)
+
+// handoffBatch - handoffs sent per request
+const handoffBatch = 500
+
+// HandoffSignatureHeader - hex encoded HMAC-SHA256 of the handoff request body by the secret shared by the
+// replicas of the ring
+const HandoffSignatureHeader = "X-Rockets-Handoff-Signature"
+
+// SignHandoff returns the signature of the handoff request body
+func SignHandoff(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHandoff reports whether the signature of the handoff request body was made with the secret
+func VerifyHandoff(secret, body []byte, signature string) bool {
+	sum, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
+
+// HandoffSource - replica whose hot state of the moved channels is handed off
+type HandoffSource interface {
+	ExportHandoff(ctx context.Context, moved func(channel uuid.UUID) bool) []rocket.Handoff
+	AcceptHandoff(ctx context.Context, handoffs []rocket.Handoff) rocket.HandoffReport
+}
+
+// Handoff sends the hot state of the channels that moved to other replicas when the ring changes to their new
+// owners through POST /admin/handoff, so they continue the sequences without duplicates or gaps. Handoffs an
+// owner can't take are taken back, their held messages are released by the reorder janitor. The requests are
+// signed with the secret shared by the replicas, see SignHandoff.
+type Handoff struct {
+	source HandoffSource
+	secret []byte
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewHandoff creates the handoff of the replica's state, signed with the secret.
+func NewHandoff(source HandoffSource, secret []byte, logger *zap.Logger) *Handoff {
+	return &Handoff{
+		source: source,
+		secret: secret,
+		client: &http.Client{Timeout: 30 * time.Second},
+		logger: logger,
+	}
+}
+
+// send hands the channels self owns in prev but not in next over to their owners in next
+func (h *Handoff) send(ctx context.Context, self string, prev, next *Ring) {
+	moved := h.source.ExportHandoff(ctx, func(channel uuid.UUID) bool {
+		return prev.Owner(channel).ID == self && next.Owner(channel).ID != self
+	})
+	byPeer := make(map[string][]rocket.Handoff)
+	for _, handoff := range moved {
+		peer := next.Peer(handoff.Channel)
+		byPeer[peer] = append(byPeer[peer], handoff)
+	}
+	for peer, handoffs := range byPeer {
+		for len(handoffs) > 0 {
+			batch := handoffs[:min(handoffBatch, len(handoffs))]
+			handoffs = handoffs[len(batch):]
+			if err := h.post(ctx, peer, batch); err != nil {
+				h.logger.Warn("Can't hand the channels off to their new owner", zap.String("peer", peer), zap.Int("channels", len(batch)), zap.Error(err))
+				h.source.AcceptHandoff(ctx, batch)
+				continue
+			}
+			h.logger.Info("Handed the channels off to their new owner", zap.String("peer", peer), zap.Int("channels", len(batch)))
+		}
+	}
+}
+
+func (h *Handoff) post(ctx context.Context, peer string, handoffs []rocket.Handoff) error {
+	if peer == "" {
+		return errors.New("the owner has no URL")
+	}
+	body, err := json.Marshal(handoffs)
+	if err != nil {
+		return fmt.Errorf("can't encode handoffs: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, peer+"/admin/handoff", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("can't create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HandoffSignatureHeader, SignHandoff(h.secret, body))
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package partition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"rockets/internal/clock"
+	"rockets/internal/kube"
+	"slices"
+	"time"
+)
+
+// Labels and annotations of the member leases
+const (
+	ringLabel     = "rockets.io/ring"
+	urlAnnotation = "rockets.io/url"
+)
+
+// MembershipConfig - Lease objects the replicas of a ring keep alive
+type MembershipConfig struct {
+	API       *kube.Client
+	Namespace string
+	// Ring - name of the ring, the replicas with the same name share the channels
+	Ring string
+	// Duration - how long a member stays on the ring without renewing its lease
+	Duration time.Duration
+}
+
+// observation - lease of another member and when it was seen changing
+type observation struct {
+	spec kube.LeaseSpec
+	at   time.Time
+}
+
+// Membership keeps the members of the ring up to date through Kubernetes Lease objects: every replica holds a
+// lease labeled with the ring name and annotated with its URL, renews it every third of the duration and lists
+// the leases of the others. A member is dropped once its lease stays unchanged for the duration, measured by the
+// local clock so clock skew between nodes doesn't matter, and a stopping replica deletes its lease so it leaves
+// right away.
+type Membership struct {
+	cfg     MembershipConfig
+	ring    *Ring
+	handoff *Handoff
+	clock   clock.Clock
+	logger  *zap.Logger
+
+	observed map[string]observation
+}
+
+// NewMembership creates the membership of the replica in the ring.
+func NewMembership(cfg MembershipConfig, ring *Ring, logger *zap.Logger) *Membership {
+	return &Membership{
+		cfg:      cfg,
+		ring:     ring,
+		clock:    clock.Real{},
+		logger:   logger,
+		observed: make(map[string]observation),
+	}
+}
+
+// UseClock replaces the system clock the leases are timed by. Must be called before the membership is used.
+func (m *Membership) UseClock(c clock.Clock) {
+	m.clock = c
+}
+
+// UseHandoff hands the hot state of the channels that move to other replicas over to them when the members
+// change. Must be called before Run.
+func (m *Membership) UseHandoff(h *Handoff) {
+	m.handoff = h
+}
+
+// Run keeps the membership up to date until the context is done, then leaves the ring. Failures are logged, the
+// ring keeps its members until the next successful update.
+func (m *Membership) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.cfg.Duration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.leave()
+			return nil
+		case <-ticker.C:
+			if err := m.Sync(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Warn("Can't update the ring members", zap.String("ring", m.cfg.Ring), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Sync renews the lease of the replica and updates the ring with the live members
+func (m *Membership) Sync(ctx context.Context) error {
+	if err := m.renew(ctx); err != nil {
+		return fmt.Errorf("can't renew the member lease: %w", err)
+	}
+	var list struct {
+		Items []kube.Lease `json:"items"`
+	}
+	path := kube.LeasesPath(m.cfg.Namespace) + "?labelSelector=" + url.QueryEscape(ringLabel+"="+m.cfg.Ring)
+	if err := m.cfg.API.Do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return fmt.Errorf("can't list the member leases: %w", err)
+	}
+
+	now := m.clock.Now()
+	self := m.ring.self
+	members := []Member{self}
+	seen := make(map[string]bool, len(list.Items))
+	for _, lease := range list.Items {
+		id := lease.Spec.HolderIdentity
+		if id == "" || id == self.ID {
+			continue
+		}
+		seen[lease.Metadata.Name] = true
+		o, ok := m.observed[lease.Metadata.Name]
+		if !ok || o.spec != lease.Spec {
+			o = observation{spec: lease.Spec, at: now}
+			m.observed[lease.Metadata.Name] = o
+		}
+		if now.Sub(o.at) < time.Duration(lease.Spec.LeaseDurationSeconds)*time.Second {
+			members = append(members, Member{ID: id, URL: lease.Metadata.Annotations[urlAnnotation]})
+		}
+	}
+	for name := range m.observed {
+		if !seen[name] {
+			delete(m.observed, name)
+		}
+	}
+
+	prev := m.ring.Members()
+	if m.ring.SetMembers(members, now) {
+		ids := make([]string, 0, len(members))
+		for _, member := range m.ring.Members() {
+			ids = append(ids, member.ID)
+		}
+		m.logger.Info("Ring members changed", zap.String("ring", m.cfg.Ring), zap.Strings("members", ids))
+		if m.handoff != nil {
+			m.handoff.send(ctx, self.ID, ringOf(prev, now), m.ring)
+		}
+	}
+	return nil
+}
+
+// ringOf returns a ring of the members
+func ringOf(members []Member, now time.Time) *Ring {
+	r := NewRing(members[0])
+	r.SetMembers(members, now)
+	return r
+}
+
+func (m *Membership) leaseName() string {
+	return m.cfg.Ring + "-" + m.ring.self.ID
+}
+
+// renew creates the lease of the replica or bumps its renew time
+func (m *Membership) renew(ctx context.Context) error {
+	now := m.clock.Now().UTC().Format(kube.MicroTime)
+	path := kube.LeasesPath(m.cfg.Namespace) + "/" + m.leaseName()
+	var lease kube.Lease
+	err := m.cfg.API.Do(ctx, http.MethodGet, path, nil, &lease)
+	if errors.Is(err, kube.ErrNotFound) {
+		lease = kube.NewLease(m.cfg.Namespace, m.leaseName(), kube.LeaseSpec{AcquireTime: now})
+		lease.Metadata.Labels = map[string]string{ringLabel: m.cfg.Ring}
+		m.fill(&lease, now)
+		return m.cfg.API.Do(ctx, http.MethodPost, kube.LeasesPath(m.cfg.Namespace), lease, nil)
+	}
+	if err != nil {
+		return err
+	}
+	m.fill(&lease, now)
+	return m.cfg.API.Do(ctx, http.MethodPut, path, lease, nil)
+}
+
+// fill sets the holder, timing and URL of the replica on its lease
+func (m *Membership) fill(lease *kube.Lease, now string) {
+	lease.Spec.HolderIdentity = m.ring.self.ID
+	lease.Spec.LeaseDurationSeconds = int(m.cfg.Duration.Seconds())
+	lease.Spec.RenewTime = now
+	if lease.Metadata.Annotations == nil {
+		lease.Metadata.Annotations = make(map[string]string)
+	}
+	lease.Metadata.Annotations[urlAnnotation] = m.ring.self.URL
+}
+
+// leave deletes the lease of the replica, so the others take its channels over on their next update, and hands
+// its channels off to them
+func (m *Membership) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	members := m.ring.Members()
+	others := slices.DeleteFunc(slices.Clone(members), func(member Member) bool { return member.ID == m.ring.self.ID })
+	if m.handoff != nil && len(others) > 0 {
+		now := m.clock.Now()
+		m.handoff.send(ctx, m.ring.self.ID, ringOf(members, now), ringOf(others, now))
+	}
+	err := m.cfg.API.Do(ctx, http.MethodDelete, kube.LeasesPath(m.cfg.Namespace)+"/"+m.leaseName(), nil, nil)
+	if err != nil && !errors.Is(err, kube.ErrNotFound) {
+		m.logger.Warn("Can't leave the ring", zap.String("ring", m.cfg.Ring), zap.Error(err))
+	}
+}
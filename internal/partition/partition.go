@@ -0,0 +1,168 @@
+// Package partition splits the channels into hash partitions owned by the replicas, so every replica ingests
+// only its share of the rockets and ingestion scales without locks shared between the replicas.
+package partition
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Owner - decides which channels the replica ingests and where the others go
+type Owner interface {
+	// Owns reports whether the replica owns the channel
+	Owns(channel uuid.UUID) bool
+	// Peer returns the base URL of the replica owning the channel, empty when it is unknown
+	Peer(channel uuid.UUID) string
+	// Status describes the ownership for the admin API
+	Status() Status
+}
+
+// Status - partition ownership of the replica
+type Status struct {
+	// Mode - static for configured partitions, ring for consistent hashing over the live replicas
+	Mode string `json:"mode"`
+	// Count, Owned, Peers - partitions, the ones owned and the base URLs of the owners of the others by partition
+	Count int               `json:"count,omitempty"`
+	Owned []int             `json:"owned,omitempty"`
+	Peers map[string]string `json:"peers,omitempty"`
+	// Self, Members, ChangedAt - the replica, the live replicas of the ring and when they last changed
+	Self      string     `json:"self,omitempty"`
+	Members   []Member   `json:"members,omitempty"`
+	ChangedAt *time.Time `json:"changedAt,omitempty"`
+}
+
+// Of returns the partition of the channel among count partitions, the FNV-1a hash of its bytes modulo count
+func Of(channel uuid.UUID, count int) int {
+	return int(hash(channel[:]) % uint32(count))
+}
+
+// hash returns the FNV-1a hash of the bytes
+func hash(b []byte) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write(b)
+	return h.Sum32()
+}
+
+var _ Owner = (*Static)(nil)
+
+// Static - partitions owned by the replica by configuration
+type Static struct {
+	count int
+	owned []bool
+	// peers - base URLs of the owners by partition
+	peers []string
+}
+
+// NewStatic creates the owner of the partitions among count.
+func NewStatic(count int, owned []int) (*Static, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("partition count must be positive, got %d", count)
+	}
+	s := &Static{count: count, owned: make([]bool, count), peers: make([]string, count)}
+	for _, p := range owned {
+		if p < 0 || p >= count {
+			return nil, fmt.Errorf("partition %d is out of range 0..%d", p, count-1)
+		}
+		s.owned[p] = true
+	}
+	return s, nil
+}
+
+// ParseOwned parses a comma-separated list of partitions and ranges, e.g. "0-3,8". The word "ordinal" stands for
+// the partition numbered like the ordinal of the StatefulSet pod, the number ending the host name.
+func ParseOwned(v string) ([]int, error) {
+	var owned []int
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "ordinal" {
+			ordinal, err := hostOrdinal()
+			if err != nil {
+				return nil, err
+			}
+			owned = append(owned, ordinal)
+			continue
+		}
+		from, to, isRange := strings.Cut(item, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition %q", item)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil || last < first {
+				return nil, fmt.Errorf("invalid partition range %q", item)
+			}
+		}
+		for p := first; p <= last; p++ {
+			owned = append(owned, p)
+		}
+	}
+	return owned, nil
+}
+
+// UsePeers sets the base URLs of the replicas owning the partitions, keyed by partitions and ranges like the
+// owned ones, e.g. "4-7" to "http://rockets-1.rockets:8080". The placeholder {partition} in a URL is replaced with
+// the partition number, so "0-7" to "http://rockets-{partition}.rockets:8080" addresses the pods of a StatefulSet
+// owning their ordinals.
+func (s *Static) UsePeers(peers map[string]string) error {
+	for key, url := range peers {
+		partitions, err := ParseOwned(key)
+		if err != nil {
+			return err
+		}
+		for _, p := range partitions {
+			if p < 0 || p >= s.count {
+				return fmt.Errorf("partition %d is out of range 0..%d", p, s.count-1)
+			}
+			s.peers[p] = strings.TrimSuffix(strings.ReplaceAll(url, "{partition}", strconv.Itoa(p)), "/")
+		}
+	}
+	return nil
+}
+
+// hostOrdinal returns the ordinal of the StatefulSet pod, e.g. 2 of rockets-2
+func hostOrdinal() (int, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return 0, fmt.Errorf("can't get the host name: %w", err)
+	}
+	i := strings.LastIndex(host, "-")
+	ordinal, err := strconv.Atoi(host[i+1:])
+	if i < 0 || err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("host name %q does not end with a StatefulSet ordinal", host)
+	}
+	return ordinal, nil
+}
+
+// Owns reports whether the partition of the channel is owned
+func (s *Static) Owns(channel uuid.UUID) bool {
+	return s.owned[Of(channel, s.count)]
+}
+
+// Peer returns the base URL of the replica owning the partition of the channel, empty when none is set
+func (s *Static) Peer(channel uuid.UUID) string {
+	return s.peers[Of(channel, s.count)]
+}
+
+// Status describes the configured partitions
+func (s *Static) Status() Status {
+	status := Status{Mode: "static", Count: s.count, Owned: []int{}, Peers: make(map[string]string)}
+	for p := range s.count {
+		if s.owned[p] {
+			status.Owned = append(status.Owned, p)
+		} else if s.peers[p] != "" {
+			status.Peers[strconv.Itoa(p)] = s.peers[p]
+		}
+	}
+	return status
+}
+
+// Count returns the number of partitions
+func (s *Static) Count() int {
+	return s.count
+}
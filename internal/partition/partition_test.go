@@ -0,0 +1,80 @@
+package partition
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"reflect"
+	"testing"
+)
+
+func TestStatic(t *testing.T) {
+	owned, err := ParseOwned("0-2, 5")
+	if err != nil || !reflect.DeepEqual(owned, []int{0, 1, 2, 5}) {
+		t.Fatalf("Expected [0 1 2 5], got %v (%v)", owned, err)
+	}
+	for _, v := range []string{"", "a", "3-1", "1-x"} {
+		if _, err := ParseOwned(v); err == nil {
+			t.Errorf("Expected %q to be rejected", v)
+		}
+	}
+	if _, err := NewStatic(4, []int{4}); err == nil {
+		t.Errorf("Expected a partition out of range to be rejected")
+	}
+
+	// every channel is owned by exactly one of the replicas splitting the partitions
+	a, _ := NewStatic(8, []int{0, 1, 2, 3})
+	b, _ := NewStatic(8, []int{4, 5, 6, 7})
+	counts := make(map[bool]int)
+	for i := 0; i < 1000; i++ {
+		channel := uuid.New()
+		if a.Owns(channel) == b.Owns(channel) {
+			t.Fatalf("Expected channel %s owned by one replica", channel)
+		}
+		counts[a.Owns(channel)]++
+	}
+	if counts[true] < 400 || counts[false] < 400 {
+		t.Errorf("Expected the channels spread evenly, got %v", counts)
+	}
+}
+
+func TestOf(t *testing.T) {
+	// FNV-1a of the UUID bytes, the partition map of replicas running different versions must agree
+	tests := []struct {
+		channel string
+		count   int
+		want    int
+	}{
+		{"00000000-0000-0000-0000-000000000000", 8, 5},
+		{"ffffffff-ffff-ffff-ffff-ffffffffffff", 8, 5},
+		{"193270a9-c9cf-404a-8f83-838e71d9ae67", 8, 1},
+		{"7a9d2e61-4b5c-4d3e-9f1a-2b3c4d5e6f70", 8, 3},
+		{"193270a9-c9cf-404a-8f83-838e71d9ae67", 3, 1},
+		{"7a9d2e61-4b5c-4d3e-9f1a-2b3c4d5e6f70", 3, 0},
+		{"7a9d2e61-4b5c-4d3e-9f1a-2b3c4d5e6f70", 1, 0},
+	}
+	for _, tt := range tests {
+		if got := Of(uuid.MustParse(tt.channel), tt.count); got != tt.want {
+			t.Errorf("Expected partition %d of channel %s among %d, got %d", tt.want, tt.channel, tt.count, got)
+		}
+	}
+}
+
+func TestStatic_Peers(t *testing.T) {
+	s, _ := NewStatic(4, []int{0})
+	if err := s.UsePeers(map[string]string{"1-3": "http://rockets-{partition}.rockets:8080/"}); err != nil {
+		t.Fatalf("UsePeers failed: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		channel := uuid.New()
+		expected := ""
+		if p := Of(channel, 4); p != 0 {
+			expected = fmt.Sprintf("http://rockets-%d.rockets:8080", p)
+		}
+		if peer := s.Peer(channel); peer != expected {
+			t.Fatalf("Expected peer %q of channel %s, got %q", expected, channel, peer)
+		}
+	}
+	if err := s.UsePeers(map[string]string{"4": "http://rockets-4"}); err == nil {
+		t.Errorf("Expected a partition out of range to be rejected")
+	}
+}
@@ -0,0 +1,120 @@
+package partition
+
+import (
+	"cmp"
+	"github.com/google/uuid"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// vnodes - points of every replica on the ring, so the channels spread evenly and a leaving replica's channels
+// are shared by the others
+const vnodes = 128
+
+// Member - replica of the ring
+type Member struct {
+	// ID - name of the replica, e.g. the pod name
+	ID string `json:"id"`
+	// URL - base URL the other replicas forward messages to
+	URL string `json:"url"`
+}
+
+// point - position of a replica on the ring
+type point struct {
+	hash   uint32
+	member int
+}
+
+var _ Owner = (*Ring)(nil)
+
+// Ring spreads the channels over the live replicas by consistent hashing: a channel belongs to the replica of the
+// first point at or after its hash. Replicas joining or leaving only move the channels next to their points, so
+// most channels keep their owner. The ring holds the replica itself until the members are set.
+type Ring struct {
+	self Member
+
+	mu        sync.RWMutex
+	members   []Member
+	points    []point
+	changedAt time.Time
+}
+
+// NewRing creates a ring of the replica alone.
+func NewRing(self Member) *Ring {
+	r := &Ring{self: self}
+	r.SetMembers([]Member{self}, time.Now())
+	return r
+}
+
+// SetMembers replaces the live replicas, reporting whether they changed. The replica itself is always a member.
+func (r *Ring) SetMembers(members []Member, now time.Time) bool {
+	members = slices.Clone(members)
+	if !slices.ContainsFunc(members, func(m Member) bool { return m.ID == r.self.ID }) {
+		members = append(members, r.self)
+	}
+	slices.SortFunc(members, func(a, b Member) int { return cmp.Compare(a.ID, b.ID) })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.Equal(members, r.members) {
+		return false
+	}
+	points := make([]point, 0, len(members)*vnodes)
+	for i, m := range members {
+		for v := range vnodes {
+			points = append(points, point{hash: mix(hash([]byte(m.ID + "#" + strconv.Itoa(v)))), member: i})
+		}
+	}
+	slices.SortFunc(points, func(a, b point) int { return cmp.Compare(a.hash, b.hash) })
+	r.members, r.points, r.changedAt = members, points, now
+	return true
+}
+
+// mix spreads the bits of a hash of similar names, the finalizer of MurmurHash3
+func mix(h uint32) uint32 {
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// Owner returns the replica owning the channel
+func (r *Ring) Owner(channel uuid.UUID) Member {
+	h := mix(hash(channel[:]))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, _ := slices.BinarySearchFunc(r.points, h, func(p point, h uint32) int { return cmp.Compare(p.hash, h) })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.members[r.points[i].member]
+}
+
+// Owns reports whether the replica itself owns the channel
+func (r *Ring) Owns(channel uuid.UUID) bool {
+	return r.Owner(channel).ID == r.self.ID
+}
+
+// Peer returns the base URL of the replica owning the channel
+func (r *Ring) Peer(channel uuid.UUID) string {
+	return r.Owner(channel).URL
+}
+
+// Members returns the live replicas ordered by id
+func (r *Ring) Members() []Member {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.members)
+}
+
+// Status describes the live replicas
+func (r *Ring) Status() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	changedAt := r.changedAt
+	return Status{Mode: "ring", Self: r.self.ID, Members: slices.Clone(r.members), ChangedAt: &changedAt}
+}
@@ -0,0 +1,239 @@
+package partition
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/clock"
+	"rockets/internal/kube"
+	"rockets/internal/rocket"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRing(t *testing.T) {
+	now := time.Date(2022, 2, 2, 20, 0, 0, 0, time.UTC)
+	members := []Member{{ID: "rockets-0", URL: "http://a"}, {ID: "rockets-1", URL: "http://b"}, {ID: "rockets-2", URL: "http://c"}}
+	r := NewRing(members[0])
+	channels := make([]uuid.UUID, 3000)
+	for i := range channels {
+		channels[i] = uuid.New()
+		if !r.Owns(channels[i]) {
+			t.Fatalf("Expected a ring of the replica alone to own every channel")
+		}
+	}
+
+	if !r.SetMembers(members, now) {
+		t.Fatalf("Expected changed members reported")
+	}
+	if r.SetMembers([]Member{members[2], members[1]}, now) {
+		t.Fatalf("Expected the same members in another order, the replica itself included, not reported as changed")
+	}
+	owners := make(map[uuid.UUID]string)
+	counts := make(map[string]int)
+	for _, channel := range channels {
+		owner := r.Owner(channel)
+		owners[channel] = owner.ID
+		counts[owner.ID]++
+		if r.Owns(channel) != (owner.ID == "rockets-0") || r.Peer(channel) != owner.URL {
+			t.Fatalf("Expected Owns and Peer to agree with the owner %s of %s", owner.ID, channel)
+		}
+	}
+	for _, m := range members {
+		if counts[m.ID] < 700 {
+			t.Errorf("Expected the channels spread evenly, got %v", counts)
+		}
+	}
+
+	// a leaving replica only moves its own channels
+	r.SetMembers(members[:2], now)
+	for _, channel := range channels {
+		if owner := r.Owner(channel).ID; owners[channel] != "rockets-2" && owner != owners[channel] {
+			t.Fatalf("Expected channel %s to stay with %s, moved to %s", channel, owners[channel], owner)
+		}
+	}
+}
+
+// fakeMembers - API server keeping the leases of the ring members
+type fakeMembers struct {
+	mu     sync.Mutex
+	leases map[string]kube.Lease
+}
+
+func (f *fakeMembers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	const collection = "/apis/coordination.k8s.io/v1/namespaces/rockets/leases"
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, collection), "/")
+	var body kube.Lease
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		if r.URL.Query().Get("labelSelector") != "rockets.io/ring=ingest" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		items := []kube.Lease{}
+		for _, lease := range f.leases {
+			items = append(items, lease)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+	case r.Method == http.MethodGet:
+		lease, ok := f.leases[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(lease)
+	case r.Method == http.MethodPost:
+		f.leases[body.Metadata.Name] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		f.leases[name] = body
+	case r.Method == http.MethodDelete:
+		delete(f.leases, name)
+	}
+}
+
+func TestMembership(t *testing.T) {
+	server := httptest.NewServer(&fakeMembers{leases: make(map[string]kube.Lease)})
+	defer server.Close()
+	now := clock.NewFake(time.Date(2022, 2, 2, 20, 0, 0, 0, time.UTC))
+	replica := func(id string) (*Ring, *Membership) {
+		ring := NewRing(Member{ID: id, URL: "http://" + id})
+		m := NewMembership(MembershipConfig{
+			API:       &kube.Client{URL: server.URL, HTTP: server.Client()},
+			Namespace: "rockets",
+			Ring:      "ingest",
+			Duration:  15 * time.Second,
+		}, ring, zap.NewNop())
+		m.UseClock(now)
+		return ring, m
+	}
+	ids := func(r *Ring) string {
+		var ids []string
+		for _, m := range r.Members() {
+			ids = append(ids, m.ID+"="+m.URL)
+		}
+		return strings.Join(ids, ",")
+	}
+	ctx := context.Background()
+	ringA, a := replica("rockets-0")
+	ringB, b := replica("rockets-1")
+
+	for _, m := range []*Membership{a, b, a} {
+		if err := m.Sync(ctx); err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+	}
+	if got := ids(ringA); got != "rockets-0=http://rockets-0,rockets-1=http://rockets-1" {
+		t.Fatalf("Expected both replicas on the ring, got %s", got)
+	}
+
+	// a replica that stops renewing is dropped once its lease stays unchanged for the duration
+	now.Advance(10 * time.Second)
+	_ = a.Sync(ctx)
+	if got := ids(ringA); !strings.Contains(got, "rockets-1") {
+		t.Fatalf("Expected the member kept within the duration, got %s", got)
+	}
+	now.Advance(10 * time.Second)
+	_ = a.Sync(ctx)
+	if got := ids(ringA); got != "rockets-0=http://rockets-0" {
+		t.Fatalf("Expected the silent member dropped, got %s", got)
+	}
+
+	// it joins again once it renews, and a stopping replica leaves right away
+	_ = b.Sync(ctx)
+	_ = a.Sync(ctx)
+	if got := ids(ringA); got != ids(ringB) {
+		t.Fatalf("Expected the replicas to agree on the members, got %s and %s", got, ids(ringB))
+	}
+	b.leave()
+	_ = a.Sync(ctx)
+	if got := ids(ringA); got != "rockets-0=http://rockets-0" {
+		t.Errorf("Expected the stopped replica gone, got %s", got)
+	}
+	if status := ringA.Status(); status.Mode != "ring" || status.Self != "rockets-0" || len(status.Members) != 1 {
+		t.Errorf("Expected the ring status, got %+v", status)
+	}
+}
+
+func TestHandoff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 2, 2, 20, 0, 0, 0, time.UTC)
+	newService := func() *rocket.ServiceImpl {
+		svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+		svc.UseReorderBuffer(10, time.Minute)
+		return svc
+	}
+	prev, next := newService(), newService()
+	secret := []byte("ring secret")
+	var received int
+	owner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var handoffs []rocket.Handoff
+		body, _ := io.ReadAll(r.Body)
+		if !VerifyHandoff(secret, body, r.Header.Get(HandoffSignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/admin/handoff" || json.Unmarshal(body, &handoffs) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received += len(handoffs)
+		_ = json.NewEncoder(w).Encode(next.AcceptHandoff(r.Context(), handoffs))
+	}))
+	defer owner.Close()
+
+	a, b := Member{ID: "rockets-0", URL: "http://a"}, Member{ID: "rockets-1", URL: owner.URL}
+	before, after := ringOf([]Member{a}, now), ringOf([]Member{a, b}, now)
+	var moved []uuid.UUID
+	for i := 0; i < 50; i++ {
+		id := uuid.New()
+		launch := rocket.TelemetryMessage{Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: now, MessageType: rocket.MessageTypeLaunched}}
+		speed, mission, kind := rocket.Speed(500), rocket.Mission("ARTEMIS"), rocket.RocketType("Falcon-9")
+		launch.Message = rocket.Message{LaunchSpeed: &speed, Mission: &mission, Type: &kind}
+		if _, err := prev.ProcessMessage(ctx, launch); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+		if after.Owner(id).ID == b.ID {
+			moved = append(moved, id)
+		}
+	}
+
+	NewHandoff(prev, []byte("other secret"), zap.NewNop()).send(ctx, a.ID, before, after)
+	if received != 0 {
+		t.Fatalf("Expected the handoffs signed with another secret refused, got %d", received)
+	}
+	NewHandoff(prev, secret, zap.NewNop()).send(ctx, a.ID, before, after)
+	if received != len(moved) {
+		t.Fatalf("Expected the %d moved channels handed off, got %d", len(moved), received)
+	}
+	for _, id := range moved {
+		if _, err := next.GetRocketState(ctx, id); err != nil {
+			t.Fatalf("Expected the new owner to have the state of %s: %v", id, err)
+		}
+	}
+
+	// handoffs an owner can't take are taken back
+	id := moved[0]
+	held := rocket.TelemetryMessage{Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 3, MessageTime: now, MessageType: rocket.MessageTypeSpeedIncreased}}
+	by := rocket.Speed(100)
+	held.Message.By = &by
+	if res, _ := prev.ProcessMessage(ctx, held); res.Outcome != rocket.OutcomeBuffered {
+		t.Fatalf("Expected the message held, got %s", res.Outcome)
+	}
+	owner.Close()
+	NewHandoff(prev, secret, zap.NewNop()).send(ctx, a.ID, before, after)
+	if handoffs := prev.ExportHandoff(ctx, func(channel uuid.UUID) bool { return channel == id }); len(handoffs) != 1 || len(handoffs[0].Held) != 1 {
+		t.Errorf("Expected the held message kept by the previous owner, got %+v", handoffs)
+	}
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"rockets/internal/rocket"
+	"sort"
+	"sync"
+	"time"
+)
+
+// bucketSize - granularity of the activity aggregation
+const bucketSize = time.Hour
+
+// retention - how long aggregated activity is kept, enough for a weekly digest
+const retention = 8 * 24 * time.Hour
+
+var _ rocket.Listener = (*Collector)(nil)
+
+// Collector aggregates applied telemetry into hourly per-mission buckets
+type Collector struct {
+	mu      sync.Mutex
+	buckets map[time.Time]map[rocket.Mission]*bucket
+}
+
+type bucket struct {
+	launches   int
+	explosions int
+	peakSpeed  rocket.Speed
+	rockets    map[uuid.UUID]struct{}
+}
+
+// NewCollector creates an empty activity collector.
+func NewCollector() *Collector {
+	return &Collector{
+		buckets: make(map[time.Time]map[rocket.Mission]*bucket),
+	}
+}
+
+// StateChanged records the applied message in the bucket of its message time
+func (c *Collector) StateChanged(_ context.Context, msg rocket.TelemetryMessage, _, next rocket.State) {
+	start := msg.Metadata.MessageTime.UTC().Truncate(bucketSize)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	missions, ok := c.buckets[start]
+	if !ok {
+		c.prune(start)
+		missions = make(map[rocket.Mission]*bucket)
+		c.buckets[start] = missions
+	}
+	b, ok := missions[next.Mission]
+	if !ok {
+		b = &bucket{rockets: make(map[uuid.UUID]struct{})}
+		missions[next.Mission] = b
+	}
+
+	b.rockets[next.ID] = struct{}{}
+	switch msg.Metadata.MessageType {
+	case rocket.MessageTypeLaunched:
+		b.launches++
+	case rocket.MessageTypeExploded:
+		b.explosions++
+	}
+	if next.CurrentSpeed > b.peakSpeed {
+		b.peakSpeed = next.CurrentSpeed
+	}
+}
+
+// prune drops buckets that fell out of the retention window relative to the newest bucket
+func (c *Collector) prune(newest time.Time) {
+	for start := range c.buckets {
+		if newest.Sub(start) > retention {
+			delete(c.buckets, start)
+		}
+	}
+}
+
+// Report builds a summary of the activity in [from, to)
+func (c *Collector) Report(period Period, from, to time.Time) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rockets := make(map[rocket.Mission]map[uuid.UUID]struct{})
+	// all - rockets of every mission, a rocket changing its mission counts once in the totals
+	all := make(map[uuid.UUID]struct{})
+	stats := make(map[rocket.Mission]*MissionStats)
+	for start, missions := range c.buckets {
+		if start.Before(from.Truncate(bucketSize)) || !start.Before(to) {
+			continue
+		}
+		for mission, b := range missions {
+			st, ok := stats[mission]
+			if !ok {
+				st = &MissionStats{Mission: mission}
+				stats[mission] = st
+				rockets[mission] = make(map[uuid.UUID]struct{})
+			}
+			st.Launches += b.launches
+			st.Explosions += b.explosions
+			st.PeakSpeed = max(st.PeakSpeed, b.peakSpeed)
+			for id := range b.rockets {
+				rockets[mission][id] = struct{}{}
+				all[id] = struct{}{}
+			}
+		}
+	}
+
+	r := Report{Period: period, From: from, To: to}
+	r.Totals.Rockets = len(all)
+	for mission, st := range stats {
+		st.Rockets = len(rockets[mission])
+		r.Missions = append(r.Missions, *st)
+		r.Totals.Launches += st.Launches
+		r.Totals.Explosions += st.Explosions
+		r.Totals.PeakSpeed = max(r.Totals.PeakSpeed, st.PeakSpeed)
+	}
+	sort.Slice(r.Missions, func(i, j int) bool {
+		return r.Missions[i].Mission < r.Missions[j].Mission
+	})
+
+	return r
+}
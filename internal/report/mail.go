@@ -0,0 +1,119 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"rockets/internal/config"
+	"rockets/internal/retry"
+	"strconv"
+	"strings"
+)
+
+// Sender - delivers rendered digests
+type Sender interface {
+	// Send delivers a message with text and html alternatives
+	Send(ctx context.Context, subject, text, html string) error
+}
+
+var _ Sender = (*SMTPSender)(nil)
+
+// SMTPSender delivers digests through an SMTP server
+type SMTPSender struct {
+	cfg     config.SMTP
+	retrier *retry.Retrier
+}
+
+// NewSMTPSender creates a sender using the provided SMTP settings.
+func NewSMTPSender(cfg config.SMTP) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+// UseRetry retries failed deliveries with the retrier. Must be called before the sender is used.
+func (s *SMTPSender) UseRetry(r *retry.Retrier) {
+	s.retrier = r
+}
+
+// Send delivers a multipart/alternative message to all configured recipients. Retries resend the same
+// message with the same Message-ID, so mail clients collapse a message delivered twice.
+func (s *SMTPSender) Send(ctx context.Context, subject, text, html string) error {
+	body, err := buildMessage(s.cfg.From, s.cfg.To, messageID(s.cfg.From), subject, text, html)
+	if err != nil {
+		return err
+	}
+	if s.retrier == nil {
+		return s.send(body)
+	}
+	return s.retrier.Do(ctx, "smtp", func(context.Context) error {
+		return s.send(body)
+	})
+}
+
+// send makes one delivery attempt, permanent SMTP replies (5xx) are not worth retrying
+func (s *SMTPSender) send(body []byte) error {
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	if err := smtp.SendMail(addr, auth, s.cfg.From, s.cfg.To, body); err != nil {
+		err = fmt.Errorf("can't send mail via %s: %w", addr, err)
+		var reply *textproto.Error
+		if errors.As(err, &reply) && reply.Code >= 500 {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// messageID returns a unique Message-ID in the domain of the sender address
+func messageID(from string) string {
+	domain := "rockets"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimSuffix(from[i+1:], ">")
+	}
+	return fmt.Sprintf("<%s@%s>", uuid.NewString(), domain)
+}
+
+func buildMessage(from string, to []string, id, subject, text, html string) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", id)
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	}
+	for _, p := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {p.contentType}})
+		if err != nil {
+			return nil, fmt.Errorf("can't build mail part: %w", err)
+		}
+		if _, err := w.Write([]byte(p.content)); err != nil {
+			return nil, fmt.Errorf("can't build mail part: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("can't build mail: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
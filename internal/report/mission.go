@@ -0,0 +1,153 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"rockets/internal/rocket"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrMissionNotFound - no rocket ever flew the mission
+var ErrMissionNotFound = errors.New("mission not found")
+
+// MissionReport - summary of a mission for post-launch reviews
+type MissionReport struct {
+	Mission     rocket.Mission
+	GeneratedAt time.Time
+	// Rockets - rockets that took part in the mission, in their current state
+	Rockets []rocket.State
+	// Timeline - applied events while the rockets were on the mission, ordered by message time
+	Timeline []rocket.Event
+	// Incidents - explosions that happened during the mission
+	Incidents []rocket.Event
+}
+
+// MissionReporter builds mission reports from the current state and the event history
+type MissionReporter struct {
+	rockets rocket.Service
+	history rocket.HistoryStore
+}
+
+// NewMissionReporter creates a mission reporter.
+func NewMissionReporter(rockets rocket.Service, history rocket.HistoryStore) *MissionReporter {
+	return &MissionReporter{
+		rockets: rockets,
+		history: history,
+	}
+}
+
+// MissionReport builds the report of the named mission, returning ErrMissionNotFound if no rocket ever flew it
+func (r *MissionReporter) MissionReport(ctx context.Context, mission rocket.Mission) (MissionReport, error) {
+	report := MissionReport{
+		Mission:     mission,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	states, err := r.rockets.ListAllRockets(ctx, rocket.SortedBy(rocket.SortByID, rocket.SortAsc))
+	if err != nil {
+		return MissionReport{}, fmt.Errorf("can't list rockets: %w", err)
+	}
+	for _, state := range states {
+		participated := state.Mission == mission
+		for _, event := range r.history.ListEvents(state.ID) {
+			if event.Superseded || event.State.Mission != mission {
+				continue
+			}
+			participated = true
+			report.Timeline = append(report.Timeline, event)
+			if event.Message.Metadata.MessageType == rocket.MessageTypeExploded {
+				report.Incidents = append(report.Incidents, event)
+			}
+		}
+		if participated {
+			report.Rockets = append(report.Rockets, state)
+		}
+	}
+	if len(report.Rockets) == 0 {
+		return MissionReport{}, ErrMissionNotFound
+	}
+
+	byTime := func(events []rocket.Event) func(i, j int) bool {
+		return func(i, j int) bool {
+			return events[i].Message.Metadata.MessageTime.Before(events[j].Message.Metadata.MessageTime)
+		}
+	}
+	sort.SliceStable(report.Timeline, byTime(report.Timeline))
+	sort.SliceStable(report.Incidents, byTime(report.Incidents))
+
+	return report, nil
+}
+
+var missionFuncs = map[string]any{
+	"ts": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"reason": func(reason *rocket.ExplosionReason) string {
+		if reason == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%s (%s, %s)", reason.Text, reason.Category, reason.Severity)
+	},
+}
+
+var missionTextTemplate = template.Must(template.New("mission-text").Funcs(missionFuncs).Parse(
+	`Mission report: {{.Mission}}
+Generated at: {{ts .GeneratedAt}}
+
+Rockets ({{len .Rockets}}):
+{{range .Rockets}}  {{.ID}} type={{.Type}} status={{.Status}} speed={{.CurrentSpeed}} mission={{.Mission}}
+{{end}}
+Incidents ({{len .Incidents}}):
+{{range .Incidents}}  {{ts .Message.Metadata.MessageTime}} {{.State.ID}} reason={{reason .State.Reason}}
+{{else}}  none
+{{end}}
+Timeline ({{len .Timeline}}):
+{{range .Timeline}}  {{ts .Message.Metadata.MessageTime}} {{.State.ID}} #{{.Message.Metadata.MessageNumber}} {{.Message.Metadata.MessageType}} speed={{.State.CurrentSpeed}}
+{{end}}`))
+
+var missionHTMLTemplate = htmltemplate.Must(htmltemplate.New("mission-html").Funcs(missionFuncs).Parse(
+	`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Mission report: {{.Mission}}</title></head><body>
+<h1>Mission report: {{.Mission}}</h1>
+<p>Generated at {{ts .GeneratedAt}}</p>
+<h2>Rockets</h2>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>ID</th><th>Type</th><th>Status</th><th>Speed</th><th>Current mission</th><th>Last update</th></tr>
+{{range .Rockets}}<tr><td>{{.ID}}</td><td>{{.Type}}</td><td>{{.Status}}</td><td>{{.CurrentSpeed}}</td><td>{{.Mission}}</td><td>{{ts .LastUpdateTime}}</td></tr>
+{{end}}</table>
+<h2>Incidents</h2>
+{{if .Incidents}}<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Time</th><th>Rocket</th><th>Reason</th></tr>
+{{range .Incidents}}<tr><td>{{ts .Message.Metadata.MessageTime}}</td><td>{{.State.ID}}</td><td>{{reason .State.Reason}}</td></tr>
+{{end}}</table>{{else}}<p>No incidents.</p>{{end}}
+<h2>Timeline</h2>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Time</th><th>Rocket</th><th>#</th><th>Event</th><th>Speed</th></tr>
+{{range .Timeline}}<tr><td>{{ts .Message.Metadata.MessageTime}}</td><td>{{.State.ID}}</td><td>{{.Message.Metadata.MessageNumber}}</td><td>{{.Message.Metadata.MessageType}}</td><td>{{.State.CurrentSpeed}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// RenderMissionHTML renders the mission report as an HTML document
+func RenderMissionHTML(r MissionReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := missionHTMLTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("can't render html mission report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderMissionPDF renders the mission report as a plain text PDF document
+func RenderMissionPDF(r MissionReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := missionTextTemplate.Execute(&buf, r); err != nil {
+		return nil, fmt.Errorf("can't render pdf mission report: %w", err)
+	}
+	return renderPDF(strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")), nil
+}
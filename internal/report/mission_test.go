@@ -0,0 +1,90 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/rocket"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestMissionReporter_MissionReport(t *testing.T) {
+	logger := zap.NewNop()
+	history := rocket.NewInMemoryHistoryStore()
+	service := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	service.UseHistory(history)
+
+	launchTime := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	r1, r2, r3 := uuid.New(), uuid.New(), uuid.New()
+	messages := []rocket.TelemetryMessage{
+		{
+			Metadata: rocket.MessageMetadata{Channel: r1, MessageNumber: 1, MessageTime: launchTime, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: r2, MessageNumber: 1, MessageTime: launchTime.Add(time.Minute), MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Soyuz")), LaunchSpeed: ptr(rocket.Speed(300)), Mission: ptr(rocket.Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: r2, MessageNumber: 2, MessageTime: launchTime.Add(2 * time.Minute), MessageType: rocket.MessageTypeExploded},
+			Message:  rocket.Message{Reason: ptr("PRESSURE_VESSEL_FAILURE")},
+		},
+		{
+			Metadata: rocket.MessageMetadata{Channel: r3, MessageNumber: 1, MessageTime: launchTime, MessageType: rocket.MessageTypeLaunched},
+			Message:  rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(500)), Mission: ptr(rocket.Mission("APOLLO"))},
+		},
+		// r1 leaves the mission, its earlier events stay on the timeline
+		{
+			Metadata: rocket.MessageMetadata{Channel: r1, MessageNumber: 2, MessageTime: launchTime.Add(3 * time.Minute), MessageType: rocket.MessageTypeMissionChanged},
+			Message:  rocket.Message{NewMission: ptr(rocket.Mission("SHUTTLE_MIR"))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := service.ProcessMessage(context.Background(), msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	reporter := NewMissionReporter(service, history)
+	r, err := reporter.MissionReport(context.Background(), "ARTEMIS")
+	if err != nil {
+		t.Fatalf("Expected ARTEMIS mission report to be found, got %v", err)
+	}
+	if len(r.Rockets) != 2 {
+		t.Errorf("Expected 2 rockets on the mission, got %d", len(r.Rockets))
+	}
+	if len(r.Timeline) != 3 {
+		t.Errorf("Expected 3 timeline events, got %d", len(r.Timeline))
+	}
+	if len(r.Incidents) != 1 || r.Incidents[0].State.ID != r2 {
+		t.Errorf("Expected a single incident for rocket %s, got %+v", r2, r.Incidents)
+	}
+
+	html, err := RenderMissionHTML(r)
+	if err != nil {
+		t.Fatalf("RenderMissionHTML failed: %v", err)
+	}
+	if !strings.Contains(string(html), "PRESSURE_VESSEL_FAILURE") {
+		t.Errorf("HTML mission report does not mention the incident reason")
+	}
+
+	pdf, err := RenderMissionPDF(r)
+	if err != nil {
+		t.Fatalf("RenderMissionPDF failed: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.Contains(pdf, []byte("Mission report: ARTEMIS")) {
+		t.Errorf("PDF mission report is malformed")
+	}
+
+	if _, err := reporter.MissionReport(context.Background(), "UNKNOWN"); !errors.Is(err, ErrMissionNotFound) {
+		t.Errorf("Expected ErrMissionNotFound for UNKNOWN mission, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"rockets/internal/rocket"
+	"text/template"
+	"time"
+)
+
+// Period - reporting period of a digest
+type Period string
+
+const (
+	PeriodDaily  Period = "daily"
+	PeriodWeekly Period = "weekly"
+)
+
+// MissionStats - activity summary of a single mission over a reporting period
+type MissionStats struct {
+	Mission    rocket.Mission
+	Rockets    int
+	Launches   int
+	Explosions int
+	PeakSpeed  rocket.Speed
+}
+
+// Report - per-mission activity digest for a reporting period
+type Report struct {
+	Period   Period
+	From     time.Time
+	To       time.Time
+	Missions []MissionStats
+	Totals   MissionStats
+}
+
+// Subject returns the mail subject line for the report
+func (r Report) Subject() string {
+	return fmt.Sprintf("Rocket mission %s digest %s - %s", r.Period, r.From.Format(time.DateOnly), r.To.Format(time.DateOnly))
+}
+
+var funcs = map[string]any{
+	"mission": func(name rocket.Mission) rocket.Mission {
+		if name == "" {
+			return "(unknown)"
+		}
+		return name
+	},
+	"ts": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}
+
+var textTemplate = template.Must(template.New("text").Funcs(funcs).Parse(
+	`Mission {{.Period}} digest
+Period: {{ts .From}} - {{ts .To}}
+
+{{range .Missions}}{{mission .Mission}}: rockets={{.Rockets}} launches={{.Launches}} explosions={{.Explosions}} peak_speed={{.PeakSpeed}}
+{{else}}No mission activity.
+{{end}}
+Total: rockets={{.Totals.Rockets}} launches={{.Totals.Launches}} explosions={{.Totals.Explosions}} peak_speed={{.Totals.PeakSpeed}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(
+	`<html><body>
+<h2>Mission {{.Period}} digest</h2>
+<p>Period: {{ts .From}} &ndash; {{ts .To}}</p>
+<table border="1" cellpadding="4" cellspacing="0">
+<tr><th>Mission</th><th>Rockets</th><th>Launches</th><th>Explosions</th><th>Peak speed</th></tr>
+{{range .Missions}}<tr><td>{{mission .Mission}}</td><td>{{.Rockets}}</td><td>{{.Launches}}</td><td>{{.Explosions}}</td><td>{{.PeakSpeed}}</td></tr>
+{{else}}<tr><td colspan="5">No mission activity.</td></tr>
+{{end}}<tr><th>Total</th><th>{{.Totals.Rockets}}</th><th>{{.Totals.Launches}}</th><th>{{.Totals.Explosions}}</th><th>{{.Totals.PeakSpeed}}</th></tr>
+</table>
+</body></html>
+`))
+
+// RenderText renders the report as plain text
+func RenderText(r Report) (string, error) {
+	var buf bytes.Buffer
+	if err := textTemplate.Execute(&buf, r); err != nil {
+		return "", fmt.Errorf("can't render text report: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// RenderHTML renders the report as an HTML document
+func RenderHTML(r Report) (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return "", fmt.Errorf("can't render html report: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"rockets/internal/rocket"
+	"strings"
+	"testing"
+	"time"
+)
+
+func applied(id uuid.UUID, msgType rocket.MessageType, at time.Time, mission rocket.Mission, speed rocket.Speed) (rocket.TelemetryMessage, rocket.State) {
+	msg := rocket.TelemetryMessage{
+		Metadata: rocket.MessageMetadata{Channel: id, MessageTime: at, MessageType: msgType},
+	}
+	return msg, rocket.State{ID: id, Mission: mission, CurrentSpeed: speed}
+}
+
+func TestCollector_Report(t *testing.T) {
+	c := NewCollector()
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	r1, r2 := uuid.New(), uuid.New()
+
+	events := []struct {
+		id      uuid.UUID
+		typ     rocket.MessageType
+		at      time.Time
+		mission rocket.Mission
+		speed   rocket.Speed
+	}{
+		{r1, rocket.MessageTypeLaunched, day.Add(1 * time.Hour), "ARTEMIS", 500},
+		{r1, rocket.MessageTypeSpeedIncreased, day.Add(2 * time.Hour), "ARTEMIS", 3500},
+		{r1, rocket.MessageTypeSpeedDecreased, day.Add(3 * time.Hour), "ARTEMIS", 1000},
+		{r2, rocket.MessageTypeLaunched, day.Add(4 * time.Hour), "APOLLO", 200},
+		{r2, rocket.MessageTypeExploded, day.Add(5 * time.Hour), "APOLLO", 0},
+		{r1, rocket.MessageTypeMissionChanged, day.Add(6 * time.Hour), "GEMINI", 1000},
+		// outside of the reporting period
+		{r2, rocket.MessageTypeLaunched, day.Add(30 * time.Hour), "APOLLO", 9000},
+	}
+	for _, e := range events {
+		msg, state := applied(e.id, e.typ, e.at, e.mission, e.speed)
+		c.StateChanged(context.Background(), msg, rocket.State{}, state)
+	}
+
+	r := c.Report(PeriodDaily, day, day.Add(24*time.Hour))
+	expected := []MissionStats{
+		{Mission: "APOLLO", Rockets: 1, Launches: 1, Explosions: 1, PeakSpeed: 200},
+		{Mission: "ARTEMIS", Rockets: 1, Launches: 1, Explosions: 0, PeakSpeed: 3500},
+		{Mission: "GEMINI", Rockets: 1, Launches: 0, Explosions: 0, PeakSpeed: 1000},
+	}
+	if len(r.Missions) != len(expected) {
+		t.Fatalf("Expected %d missions, got %d: %+v", len(expected), len(r.Missions), r.Missions)
+	}
+	for i := range expected {
+		if r.Missions[i] != expected[i] {
+			t.Errorf("Mission stats mismatch.\nExpected: %+v\nGot: %+v", expected[i], r.Missions[i])
+		}
+	}
+	// the rocket changing from ARTEMIS to GEMINI counts once
+	if r.Totals.Rockets != 2 || r.Totals.Launches != 2 || r.Totals.Explosions != 1 || r.Totals.PeakSpeed != 3500 {
+		t.Errorf("Unexpected totals: %+v", r.Totals)
+	}
+
+	text, err := RenderText(r)
+	if err != nil {
+		t.Fatalf("RenderText failed: %v", err)
+	}
+	if !strings.Contains(text, "ARTEMIS: rockets=1 launches=1 explosions=0 peak_speed=3500") {
+		t.Errorf("Text report does not contain ARTEMIS stats:\n%s", text)
+	}
+
+	html, err := RenderHTML(r)
+	if err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if !strings.Contains(html, "<td>APOLLO</td>") {
+		t.Errorf("HTML report does not contain APOLLO row:\n%s", html)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	cases := []struct {
+		now      time.Time
+		hour     int
+		expected time.Time
+	}{
+		{time.Date(2024, 3, 4, 5, 0, 0, 0, time.UTC), 6, time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC), 6, time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 3, 4, 23, 30, 0, 0, time.UTC), 0, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		if got := nextRun(c.now, c.hour); !got.Equal(c.expected) {
+			t.Errorf("nextRun(%s, %d): expected %s, got %s", c.now, c.hour, c.expected, got)
+		}
+	}
+}
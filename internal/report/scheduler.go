@@ -0,0 +1,92 @@
+package report
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"rockets/internal/config"
+	"rockets/internal/logging"
+	"time"
+)
+
+// Scheduler periodically renders digests from the collector and delivers them
+type Scheduler struct {
+	collector *Collector
+	sender    Sender
+	cfg       config.Reports
+	clock     clock.Clock
+	logger    *zap.Logger
+}
+
+// NewScheduler creates a digest scheduler.
+func NewScheduler(collector *Collector, sender Sender, cfg config.Reports, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		collector: collector,
+		sender:    sender,
+		cfg:       cfg,
+		clock:     clock.Real{},
+		logger:    logger,
+	}
+}
+
+// UseClock replaces the system clock deciding when digests are due, e.g. with a fake one in tests.
+// Must be called before the scheduler runs.
+func (s *Scheduler) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// Run sends the configured digests every day at the configured hour until the context is done.
+// Weekly digests are sent on Mondays.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		now := s.clock.Now()
+		next := nextRun(now, s.cfg.Hour)
+		s.logger.Info("Next mission digest scheduled", zap.Time("at", next))
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		if s.cfg.Daily {
+			s.send(ctx, PeriodDaily, next.Add(-24*time.Hour), next)
+		}
+		if s.cfg.Weekly && next.Weekday() == time.Monday {
+			s.send(ctx, PeriodWeekly, next.Add(-7*24*time.Hour), next)
+		}
+	}
+}
+
+func (s *Scheduler) send(ctx context.Context, period Period, from, to time.Time) {
+	r := s.collector.Report(period, from, to)
+
+	text, err := RenderText(r)
+	if err != nil {
+		s.logger.Error("Failed to render mission digest", zap.Error(err))
+		return
+	}
+	html, err := RenderHTML(r)
+	if err != nil {
+		s.logger.Error("Failed to render mission digest", zap.Error(err))
+		return
+	}
+
+	if err := s.sender.Send(ctx, r.Subject(), text, html); err != nil {
+		s.logger.Error("Failed to send mission digest", logging.Event(logging.EventDeliveryError), zap.String("period", string(period)), zap.Error(err))
+		return
+	}
+	s.logger.Info("Mission digest sent", zap.String("period", string(period)), zap.Int("missions", len(r.Missions)))
+}
+
+// nextRun returns the first moment after now at the given UTC hour
+func nextRun(now time.Time, hour int) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"html/template"
+	"rockets/internal/i18n"
+	"rockets/internal/rocket"
+	"sync"
+	"time"
+)
+
+var _ rocket.Listener = (*Feed)(nil)
+
+// Feed keeps the most recent applied events across all rockets
+type Feed struct {
+	mu     sync.Mutex
+	events []rocket.Event
+	next   int
+	full   bool
+}
+
+// NewFeed creates a feed keeping up to size latest events.
+func NewFeed(size int) *Feed {
+	return &Feed{
+		events: make([]rocket.Event, size),
+	}
+}
+
+// StateChanged records the applied message in the feed, overwriting the oldest one when full. Heartbeats are
+// left out, they would crowd out the events of the other rockets.
+func (f *Feed) StateChanged(_ context.Context, msg rocket.TelemetryMessage, _, next rocket.State) {
+	if msg.Metadata.MessageType == rocket.MessageTypeHeartbeat {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[f.next] = rocket.Event{Message: msg, State: next}
+	f.next = (f.next + 1) % len(f.events)
+	if f.next == 0 {
+		f.full = true
+	}
+}
+
+// Recent returns the recorded events, newest first
+func (f *Feed) Recent() []rocket.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := f.next
+	if f.full {
+		n = len(f.events)
+	}
+	events := make([]rocket.Event, 0, n)
+	for i := 1; i <= n; i++ {
+		events = append(events, f.events[(f.next-i+len(f.events))%len(f.events)])
+	}
+	return events
+}
+
+// Alert - explosion requiring attention of the on-call engineer
+type Alert struct {
+	RocketID uuid.UUID
+	Since    time.Time
+	Type     rocket.RocketType
+	Mission  rocket.Mission
+	// Reason - the reason reported with the explosion, nil without one
+	Reason *string
+}
+
+// Status - at-a-glance view of the tracked rockets
+type Status struct {
+	GeneratedAt time.Time
+	Total       int
+	Launched    int
+	Exploded    int
+	Unknown     int
+	Recent      []rocket.Event
+	Alerts      []Alert
+}
+
+// BuildStatus summarizes the rockets; explosions reported within alertWindow are listed as active alerts.
+func BuildStatus(rockets []rocket.State, feed *Feed, alertWindow time.Duration) Status {
+	status := Status{
+		GeneratedAt: time.Now().UTC(),
+		Total:       len(rockets),
+		Recent:      feed.Recent(),
+	}
+	for _, state := range rockets {
+		switch state.Status {
+		case rocket.StatusLaunched:
+			status.Launched++
+		case rocket.StatusExploded:
+			status.Exploded++
+			if status.GeneratedAt.Sub(state.LastUpdateTime) <= alertWindow {
+				status.Alerts = append(status.Alerts, explosionAlert(state))
+			}
+		default:
+			status.Unknown++
+		}
+	}
+	return status
+}
+
+func explosionAlert(state rocket.State) Alert {
+	alert := Alert{RocketID: state.ID, Since: state.LastUpdateTime, Type: state.Type, Mission: state.Mission}
+	if state.Reason != nil {
+		alert.Reason = &state.Reason.Text
+	}
+	return alert
+}
+
+var statusTemplate = template.Must(template.New("status").Funcs(missionFuncs).Parse(
+	`<!DOCTYPE html>
+<html lang="{{.L.Language}}"><head><meta charset="utf-8"><meta http-equiv="refresh" content="{{.Refresh}}"><title>{{.L.Text "status.title"}}</title>
+<style>body{font-family:sans-serif}td,th{padding:2px 8px;text-align:left}.alert{color:#b00}</style>
+</head><body>
+<h1>{{.L.Text "status.title"}}</h1>
+<p>{{.L.Text "status.updated" (ts .GeneratedAt) .Refresh}}</p>
+<table><tr><th>{{.L.Text "status.total"}}</th><th>{{.L.Text "status.launched"}}</th><th>{{.L.Text "status.exploded"}}</th><th>{{.L.Text "status.unknown"}}</th></tr>
+<tr><td>{{.Total}}</td><td>{{.Launched}}</td><td>{{.Exploded}}</td><td>{{.Unknown}}</td></tr></table>
+<h2>{{.L.Text "status.active_alerts"}}</h2>
+{{range .Alerts}}<p class="alert">{{ts .Since}} {{$.L.Text "status.alert_exploded" .RocketID .Type .Mission}}{{with .Reason}}: {{.}}{{end}}</p>
+{{else}}<p>{{.L.Text "status.no_alerts"}}</p>
+{{end}}<h2>{{.L.Text "status.recent_events"}}</h2>
+<table><tr><th>{{.L.Text "status.time"}}</th><th>{{.L.Text "status.rocket"}}</th><th>#</th><th>{{.L.Text "status.event"}}</th><th>{{.L.Text "status.mission"}}</th><th>{{.L.Text "status.speed"}}</th></tr>
+{{range .Recent}}<tr><td>{{ts .Message.Metadata.MessageTime}}</td><td>{{.State.ID}}</td><td>{{.Message.Metadata.MessageNumber}}</td><td>{{.Message.Metadata.MessageType}}</td><td>{{.State.Mission}}</td><td>{{.State.CurrentSpeed}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// RenderStatusHTML renders the status page in the language of the localizer, asking the browser to reload it
+// every refresh interval
+func RenderStatusHTML(status Status, refresh time.Duration, l i18n.Localizer) ([]byte, error) {
+	var buf bytes.Buffer
+	err := statusTemplate.Execute(&buf, struct {
+		Status
+		Refresh int
+		L       i18n.Localizer
+	}{status, int(refresh.Seconds()), l})
+	if err != nil {
+		return nil, fmt.Errorf("can't render status page: %w", err)
+	}
+	return buf.Bytes(), nil
+}
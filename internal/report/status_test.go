@@ -0,0 +1,66 @@
+package report
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"rockets/internal/i18n"
+	"rockets/internal/rocket"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeed_Recent(t *testing.T) {
+	feed := NewFeed(3)
+	if events := feed.Recent(); len(events) != 0 {
+		t.Fatalf("Expected empty feed, got %d events", len(events))
+	}
+
+	id := uuid.New()
+	for i := int64(1); i <= 5; i++ {
+		msg := rocket.TelemetryMessage{Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: i}}
+		feed.StateChanged(context.Background(), msg, rocket.State{}, rocket.State{ID: id})
+	}
+	heartbeat := rocket.TelemetryMessage{Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 6, MessageType: rocket.MessageTypeHeartbeat}}
+	feed.StateChanged(context.Background(), heartbeat, rocket.State{}, rocket.State{ID: id})
+
+	events := feed.Recent()
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+	for i, expected := range []int64{5, 4, 3} {
+		if got := events[i].Message.Metadata.MessageNumber; got != expected {
+			t.Errorf("Expected event %d to be message #%d, got #%d", i, expected, got)
+		}
+	}
+}
+
+func TestBuildStatus(t *testing.T) {
+	now := time.Now().UTC()
+	reason := "PRESSURE_VESSEL_FAILURE"
+	rockets := []rocket.State{
+		{ID: uuid.New(), Status: rocket.StatusLaunched, LastUpdateTime: now},
+		{ID: uuid.New(), Status: rocket.StatusExploded, Reason: rocket.ClassifyReason(&reason), LastUpdateTime: now.Add(-time.Minute)},
+		{ID: uuid.New(), Status: rocket.StatusExploded, LastUpdateTime: now.Add(-2 * time.Hour)},
+		{ID: uuid.New(), Status: "UNKNOWN", LastUpdateTime: now},
+	}
+
+	status := BuildStatus(rockets, NewFeed(10), time.Hour)
+	if status.Total != 4 || status.Launched != 1 || status.Exploded != 2 || status.Unknown != 1 {
+		t.Errorf("Unexpected counts: %+v", status)
+	}
+	if len(status.Alerts) != 1 || status.Alerts[0].RocketID != rockets[1].ID {
+		t.Fatalf("Expected a single alert for the recent explosion, got %+v", status.Alerts)
+	}
+
+	page, err := RenderStatusHTML(status, 10*time.Second, i18n.For("fr"))
+	if err != nil {
+		t.Fatalf("RenderStatusHTML failed: %v", err)
+	}
+	if !strings.Contains(string(page), `content="10"`) || !strings.Contains(string(page), reason) {
+		t.Errorf("Status page is missing refresh interval or alert:\n%s", page)
+	}
+	if !strings.Contains(string(page), "<h1>État des fusées</h1>") || !strings.Contains(string(page), "a explosé: "+reason) {
+		t.Errorf("Status page is not in French:\n%s", page)
+	}
+}
@@ -0,0 +1,76 @@
+// Package retransmit asks the producers of the channels with lasting gaps to send the missing messages again.
+package retransmit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"rockets/internal/retry"
+	"rockets/internal/rocket"
+	"time"
+)
+
+var _ rocket.Retransmitter = (*Webhook)(nil)
+
+// Webhook - retransmitter posting the requests as JSON to a callback endpoint of the producers
+type Webhook struct {
+	url     string
+	client  *http.Client
+	retrier *retry.Retrier
+}
+
+// NewWebhook creates a retransmitter posting the requests to the url.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// UseRetry retries failed deliveries with the retrier. Must be called before the retransmitter is used.
+func (w *Webhook) UseRetry(r *retry.Retrier) {
+	w.retrier = r
+}
+
+// RequestRetransmission posts the request, any non-2xx response is an error. Every attempt carries the same
+// Idempotency-Key header, so the producer can drop duplicates of a delivery that timed out after all.
+func (w *Webhook) RequestRetransmission(ctx context.Context, req rocket.RetransmitRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("can't marshal retransmission request: %w", err)
+	}
+	key := uuid.NewString()
+	if w.retrier == nil {
+		return w.post(ctx, body, key)
+	}
+	return w.retrier.Do(ctx, "retransmit", func(ctx context.Context) error {
+		return w.post(ctx, body, key)
+	})
+}
+
+// post makes one delivery attempt, client errors other than 429 are permanent
+func (w *Webhook) post(ctx context.Context, body []byte, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("can't create retransmission request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't post retransmission request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("can't post retransmission request: unexpected status %s", resp.Status)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
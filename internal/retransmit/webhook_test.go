@@ -0,0 +1,50 @@
+package retransmit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+func TestWebhook_RequestRetransmission(t *testing.T) {
+	var got rocket.RetransmitRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Idempotency-Key") == "" {
+			t.Errorf("Expected an Idempotency-Key header")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Can't decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	req := rocket.RetransmitRequest{
+		Channel:     uuid.New(),
+		Ranges:      []rocket.MessageRange{{From: 2, To: 2}, {From: 4, To: 5}},
+		RequestedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := NewWebhook(srv.URL).RequestRetransmission(context.Background(), req); err != nil {
+		t.Fatalf("RequestRetransmission failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, req) {
+		t.Errorf("Expected %+v, got %+v", req, got)
+	}
+}
+
+func TestWebhook_RequestRetransmissionFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	if err := NewWebhook(srv.URL).RequestRetransmission(context.Background(), rocket.RetransmitRequest{}); err == nil {
+		t.Errorf("Expected an error for a non-2xx response")
+	}
+}
@@ -0,0 +1,100 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"rockets/internal/metrics"
+	"time"
+)
+
+// Policy - how failed calls to an outbound integration are retried
+type Policy struct {
+	// MaxAttempts - calls including the first one, values below 1 mean a single call
+	MaxAttempts int
+	// InitialBackoff - wait before the second attempt, doubled for every next one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter - share (0..1) of every wait that is randomized, spreading the retries of concurrent callers
+	Jitter float64
+}
+
+// Backoff returns the wait before the given retry, the first retry being 1
+func (p Policy) Backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+	return d
+}
+
+// permanentError - failure that retrying can't fix
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks the error as not worth retrying, e.g. a rejected request
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether the error was marked as not worth retrying
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// Retrier - runs the calls of outbound integrations with a shared retry policy, counting the attempts
+type Retrier struct {
+	policy   Policy
+	attempts *metrics.Counter
+}
+
+// New creates a retrier with the policy, registering its attempts counter in the registry.
+func New(policy Policy, registry *metrics.Registry) *Retrier {
+	return &Retrier{
+		policy:   policy,
+		attempts: registry.Counter("rockets_outbound_attempts_total", "Calls of outbound integrations by result: success, retry or failure.", "integration", "result"),
+	}
+}
+
+// Do calls fn until it succeeds, fails permanently, the attempts are exhausted or the context is done.
+// The integration names the caller in metrics and errors.
+func (r *Retrier) Do(ctx context.Context, integration string, fn func(ctx context.Context) error) error {
+	attempts := max(r.policy.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			r.attempts.Inc(integration, "success")
+			return nil
+		}
+		if IsPermanent(err) || attempt >= attempts {
+			r.attempts.Inc(integration, "failure")
+			return fmt.Errorf("%s failed after %d attempts: %w", integration, attempt, err)
+		}
+		r.attempts.Inc(integration, "retry")
+
+		timer := time.NewTimer(r.policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.attempts.Inc(integration, "failure")
+			return fmt.Errorf("%s failed after %d attempts: %w", integration, attempt, err)
+		case <-timer.C:
+		}
+	}
+}
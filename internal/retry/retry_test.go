@@ -0,0 +1,82 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"rockets/internal/metrics"
+	"testing"
+	"time"
+)
+
+func TestRetrier_Do(t *testing.T) {
+	registry := metrics.NewRegistry()
+	r := New(Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Jitter: 0.5}, registry)
+	failure := errors.New("connection refused")
+
+	calls := 0
+	err := r.Do(context.Background(), "webhook", func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return failure
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = r.Do(context.Background(), "webhook", func(context.Context) error {
+		calls++
+		return failure
+	})
+	if !errors.Is(err, failure) || calls != 3 {
+		t.Errorf("Expected to give up after 3 attempts, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = r.Do(context.Background(), "webhook", func(context.Context) error {
+		calls++
+		return Permanent(failure)
+	})
+	if !errors.Is(err, failure) || calls != 1 {
+		t.Errorf("Expected a permanent failure not to be retried, got %v after %d calls", err, calls)
+	}
+
+	attempts := registry.Counter("rockets_outbound_attempts_total", "", "integration", "result")
+	for result, want := range map[string]float64{"success": 1, "retry": 4, "failure": 2} {
+		if got := attempts.Value("webhook", result); got != want {
+			t.Errorf("Expected %v %s attempts, got %v", want, result, got)
+		}
+	}
+}
+
+func TestRetrier_DoCanceled(t *testing.T) {
+	r := New(Policy{MaxAttempts: 10, InitialBackoff: time.Hour}, metrics.NewRegistry())
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := r.Do(ctx, "smtp", func(context.Context) error {
+		calls++
+		cancel()
+		return errors.New("timeout")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Expected to stop waiting once the context is done, got %v after %d calls", err, calls)
+	}
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	p := Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		if got := p.Backoff(retry); got != want {
+			t.Errorf("Expected backoff %s before retry %d, got %s", want, retry, got)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.Backoff(1); got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("Expected a jittered backoff within 50ms..100ms, got %s", got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// actors - per-rocket goroutines that own all updates of their rocket, each draining its own mailbox in order.
+// An actor is spawned on the first update of its rocket and reaped after staying idle for the idle timeout.
+type actors struct {
+	mu      sync.Mutex
+	running map[uuid.UUID]*actor
+	mailbox int
+	idle    time.Duration
+}
+
+// actor - goroutine serializing the updates of one rocket
+type actor struct {
+	mailbox chan func()
+	// pending - updates sent or about to be sent to the mailbox, guarded by actors.mu; the actor is reaped only at 0
+	pending int
+	// busy - the update in progress
+	busy busy
+}
+
+func newActors(mailbox int, idle time.Duration) *actors {
+	return &actors{
+		running: make(map[uuid.UUID]*actor),
+		mailbox: mailbox,
+		idle:    idle,
+	}
+}
+
+// do runs fn on the actor of the rocket, after the updates queued before it, and waits for it to finish
+func (a *actors) do(id uuid.UUID, fn func()) {
+	a.mu.Lock()
+	act, ok := a.running[id]
+	if !ok {
+		act = &actor{mailbox: make(chan func(), a.mailbox)}
+		a.running[id] = act
+		go a.run(id, act)
+	}
+	act.pending++
+	a.mu.Unlock()
+
+	done := make(chan struct{})
+	act.mailbox <- func() {
+		defer close(done)
+		fn()
+	}
+	<-done
+}
+
+// run drains the mailbox until the actor stays idle for the idle timeout
+func (a *actors) run(id uuid.UUID, act *actor) {
+	timer := time.NewTimer(a.idle)
+	defer timer.Stop()
+	for {
+		select {
+		case fn := <-act.mailbox:
+			act.busy.begin()
+			fn()
+			act.busy.end()
+			a.mu.Lock()
+			act.pending--
+			a.mu.Unlock()
+			timer.Reset(a.idle)
+		case <-timer.C:
+			a.mu.Lock()
+			if act.pending == 0 {
+				delete(a.running, id)
+				a.mu.Unlock()
+				return
+			}
+			a.mu.Unlock()
+			timer.Reset(a.idle)
+		}
+	}
+}
+
+// stalled returns how long the longest update in progress of the running actors has been running
+func (a *actors) stalled() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var longest time.Duration
+	for _, act := range a.running {
+		longest = max(longest, act.busy.stalled())
+	}
+	return longest
+}
+
+// len returns the number of running actors
+func (a *actors) len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.running)
+}
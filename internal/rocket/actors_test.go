@@ -0,0 +1,57 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestActors_SerializeUpdates(t *testing.T) {
+	a := newActors(1, time.Minute)
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+
+	var wg sync.WaitGroup
+	counts := make([]int, len(ids))
+	for i := 0; i < 200; i++ {
+		n := i % len(ids)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.do(ids[n], func() {
+				// only the actor of the rocket touches its counter
+				counts[n]++
+			})
+		}()
+	}
+	wg.Wait()
+
+	a.do(ids[0], func() {
+		if counts[0] != 100 {
+			t.Errorf("Expected 100 updates, got %d", counts[0])
+		}
+	})
+	if n := a.len(); n != len(ids) {
+		t.Errorf("Expected %d running actors, got %d", len(ids), n)
+	}
+}
+
+func TestActors_ReapIdle(t *testing.T) {
+	a := newActors(1, 5*time.Millisecond)
+	id := uuid.New()
+
+	a.do(id, func() {})
+	deadline := time.Now().Add(time.Second)
+	for a.len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := a.len(); n != 0 {
+		t.Fatalf("Expected the idle actor to be reaped, got %d running", n)
+	}
+
+	ran := false
+	a.do(id, func() { ran = true })
+	if !ran {
+		t.Errorf("Expected the actor to be spawned again")
+	}
+}
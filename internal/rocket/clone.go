@@ -0,0 +1,112 @@
+package rocket
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/logging"
+	"slices"
+)
+
+// CloneRocket copies the history of a rocket up to and including the given message into a new rocket, e.g. when
+// two physical rockets reported on the same channel and the data of one must be separated. The clone gets the
+// state the rocket had after the message, with the events renumbered from the first version when the history is
+// complete; the rocket itself is left as it is, so it can be rolled back or merged into separately. The clone is
+// passed to the rewrite listeners as a new rocket, like a rollback.
+//
+// It fails with ErrMessageNotFound when the message is not in the effective history and ErrRocketExists when
+// the clone channel is in use: tracked, with a history left, e.g. by a rocket merged away, with messages held by
+// the reorder buffer, or quarantined.
+func (s *ServiceImpl) CloneRocket(ctx context.Context, id, clone uuid.UUID, messageNumber int64) (state State, err error) {
+	if s.history == nil {
+		return State{}, ErrHistoryDisabled
+	}
+	if id == clone {
+		return State{}, ErrSameChannel
+	}
+
+	var events []Event
+	s.exclusive(id, func() {
+		events, err = s.historyUntil(id, messageNumber)
+	})
+	if err != nil {
+		return State{}, err
+	}
+	s.exclusive(clone, func() {
+		state, err = s.cloneRocket(ctx, clone, events)
+	})
+	if err != nil {
+		return State{}, err
+	}
+
+	logging.FromContext(ctx, s.logger).Info("Rocket cloned",
+		logging.Event(logging.EventRocketCloned),
+		zap.String("rocket_id", id.String()),
+		zap.String("clone_id", clone.String()),
+		zap.Int64("msg_num", messageNumber),
+		zap.Int("events", len(events)),
+	)
+	return state, nil
+}
+
+// historyUntil returns the effective events of the rocket up to and including the message, with the versions
+// renumbered from the first one when the history is complete. Must be called from exclusive.
+func (s *ServiceImpl) historyUntil(id uuid.UUID, messageNumber int64) ([]Event, error) {
+	if _, ok := s.store.GetRocketByID(id); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRocketNotFound, id)
+	}
+	events, complete := s.effectiveHistory(id)
+	target := -1
+	for i, event := range events {
+		if event.Message.Metadata.MessageNumber == messageNumber {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		return nil, fmt.Errorf("%w: message %d of rocket %s", ErrMessageNotFound, messageNumber, id)
+	}
+
+	version := events[0].State.Version - 1
+	if complete {
+		version = 0
+	}
+	copied := make([]Event, 0, target+1)
+	for _, event := range events[:target+1] {
+		version++
+		event.State.Version = version
+		copied = append(copied, event)
+	}
+	return copied, nil
+}
+
+// cloneRocket creates the clone from the copied events. Must be called from exclusive.
+func (s *ServiceImpl) cloneRocket(ctx context.Context, clone uuid.UUID, events []Event) (State, error) {
+	if _, ok := s.store.GetRocketByID(clone); ok {
+		return State{}, fmt.Errorf("%w: %s", ErrRocketExists, clone)
+	}
+	if len(s.history.ListEvents(clone)) > 0 {
+		return State{}, fmt.Errorf("%w: channel %s has a history", ErrRocketExists, clone)
+	}
+	if s.reorder != nil && s.reorder.size(clone) > 0 {
+		return State{}, fmt.Errorf("%w: channel %s has held messages", ErrRocketExists, clone)
+	}
+	if s.quarantine.quarantined(clone) {
+		return State{}, fmt.Errorf("%w: channel %s is quarantined", ErrRocketExists, clone)
+	}
+	var state State
+	for _, event := range events {
+		event.Message.Metadata.Channel = clone
+		event.State.ID = clone
+		// the provisional messages are shared with the events of the rocket
+		event.State.Prelaunch = slices.Clone(event.State.Prelaunch)
+		for i := range event.State.Prelaunch {
+			event.State.Prelaunch[i].Metadata.Channel = clone
+		}
+		s.history.AppendEvent(event)
+		state = event.State
+	}
+	s.rewrite(ctx, RewriteClone, State{}, state)
+	return state, nil
+}
@@ -0,0 +1,80 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRocketService_CloneRocket(t *testing.T) {
+	ctx := context.Background()
+	launchTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	history := NewInMemoryHistoryStore()
+	service.UseHistory(history)
+
+	id, clone := uuid.New(), uuid.New()
+	message := func(number int64, after time.Duration, msgType MessageType, msg Message) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: launchTime.Add(after), MessageType: msgType},
+			Message:  msg,
+		}
+	}
+	for _, msg := range []TelemetryMessage{
+		message(1, 0, MessageTypeLaunched, Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))}),
+		message(2, 10*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))}),
+		message(3, 20*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(300))}),
+	} {
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	if _, err := service.CloneRocket(ctx, id, id, 2); !errors.Is(err, ErrSameChannel) {
+		t.Errorf("Expected ErrSameChannel, got %v", err)
+	}
+	if _, err := service.CloneRocket(ctx, id, clone, 7); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("Expected ErrMessageNotFound, got %v", err)
+	}
+
+	state, err := service.CloneRocket(ctx, id, clone, 2)
+	if err != nil {
+		t.Fatalf("CloneRocket failed: %v", err)
+	}
+	if state.ID != clone || state.CurrentSpeed != 600 || state.LastProcessedMessageNumber != 2 || state.Version != 2 {
+		t.Errorf("Expected the clone to have the state after message 2, got %+v", state)
+	}
+	events := history.ListEvents(clone)
+	if len(events) != 2 || events[1].Message.Metadata.Channel != clone {
+		t.Errorf("Expected 2 events copied to the clone, got %+v", events)
+	}
+	if original, _ := service.GetRocketState(ctx, id); original.CurrentSpeed != 900 || original.Version != 3 {
+		t.Errorf("Expected the rocket to be left as it is, got %+v", original)
+	}
+	if _, err := service.CloneRocket(ctx, id, clone, 2); !errors.Is(err, ErrRocketExists) {
+		t.Errorf("Expected ErrRocketExists for a second clone, got %v", err)
+	}
+
+	// the clone goes on on its own
+	next := message(3, 30*time.Minute, MessageTypeSpeedDecreased, Message{By: ptr(Speed(200))})
+	next.Metadata.Channel = clone
+	if _, err := service.ProcessMessage(ctx, next); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	if report := service.CheckConsistency(ctx, false); len(report.Drifts) != 0 {
+		t.Errorf("Expected the clone to match its history, got %+v", report.Drifts)
+	}
+
+	// a channel in use without a state is not a clone target either
+	orphan, quarantined := uuid.New(), uuid.New()
+	history.AppendEvent(Event{Message: next, State: State{ID: orphan, Version: 1}})
+	service.QuarantineChannel(ctx, quarantined, "test")
+	for _, target := range []uuid.UUID{orphan, quarantined} {
+		if _, err := service.CloneRocket(ctx, id, target, 2); !errors.Is(err, ErrRocketExists) {
+			t.Errorf("Expected ErrRocketExists for a channel in use, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package rocket
+
+import (
+	"fmt"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"strings"
+	"sync"
+)
+
+// Collation - locale-aware comparison of the string sort fields (type, mission), so e.g. "artemis" and "ARTEMIS"
+// sort together and accented letters sort next to the plain ones. A nil collation compares the bytes.
+type Collation struct {
+	locale language.Tag
+	// collators - collators are not safe for concurrent use, so every sort takes its own
+	collators sync.Pool
+}
+
+// NewCollation creates a collation of the BCP 47 locale, e.g. "en" or "sv", "und" for the root collation
+// of the Unicode Collation Algorithm. Case is ignored unless caseSensitive.
+func NewCollation(locale string, caseSensitive bool) (*Collation, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collation locale %q: %w", locale, err)
+	}
+	var opts []collate.Option
+	if !caseSensitive {
+		opts = append(opts, collate.IgnoreCase)
+	}
+	c := &Collation{locale: tag}
+	c.collators.New = func() any {
+		return collate.New(tag, opts...)
+	}
+	return c, nil
+}
+
+// Locale returns the locale of the collation
+func (c *Collation) Locale() string {
+	return c.locale.String()
+}
+
+// comparer returns the comparison of strings of a single sort, and a function releasing it
+func (c *Collation) comparer() (func(a, b string) int, func()) {
+	if c == nil {
+		return strings.Compare, func() {}
+	}
+	collator := c.collators.Get().(*collate.Collator)
+	return collator.CompareString, func() { c.collators.Put(collator) }
+}
@@ -0,0 +1,54 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"reflect"
+	"testing"
+)
+
+func TestListQuery_Collation(t *testing.T) {
+	// IDs in ascending order, so equal types keep the order of the tie-break
+	types := []RocketType{"falcon-9", "Electron", "Ångström", "Falcon-9", "soyuz", "Atlas"}
+	states := make([]State, len(types))
+	for i, typ := range types {
+		states[i] = State{ID: uuid.MustParse("00000000-0000-0000-0000-00000000000" + string(rune('1'+i))), Type: typ}
+	}
+	sortedTypes := func(collation *Collation) []RocketType {
+		q := ListQuery{Sort: []SortKey{{Field: SortByType}}, Collation: collation}
+		var got []RocketType
+		for _, s := range q.Apply(append([]State(nil), states...)) {
+			got = append(got, s.Type)
+		}
+		return got
+	}
+
+	ignoreCase, err := NewCollation("und", false)
+	if err != nil {
+		t.Fatalf("NewCollation failed: %v", err)
+	}
+	swedish, err := NewCollation("sv", true)
+	if err != nil {
+		t.Fatalf("NewCollation failed: %v", err)
+	}
+	tests := []struct {
+		name      string
+		collation *Collation
+		expected  []RocketType
+	}{
+		{"byte-wise", nil, []RocketType{"Atlas", "Electron", "Falcon-9", "falcon-9", "soyuz", "Ångström"}},
+		{"case-insensitive", ignoreCase, []RocketType{"Ångström", "Atlas", "Electron", "falcon-9", "Falcon-9", "soyuz"}},
+		// Å is a letter after Z in Swedish
+		{"swedish", swedish, []RocketType{"Atlas", "Electron", "falcon-9", "Falcon-9", "soyuz", "Ångström"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortedTypes(tt.collation); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := NewCollation("not a locale!", false); err == nil {
+		t.Errorf("Expected an invalid locale rejected")
+	}
+}
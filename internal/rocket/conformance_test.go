@@ -0,0 +1,151 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// testStoreConformance verifies the behaviour every Store implementation must provide
+func testStoreConformance(t *testing.T, newStore func(t *testing.T) Store) {
+	t.Run("SaveAndGet", func(t *testing.T) {
+		store := newStore(t)
+		state := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 500, Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+		store.SaveRocket(state)
+		if got, ok := store.GetRocketByID(state.ID); !ok || !reflect.DeepEqual(got, state) {
+			t.Errorf("Expected %+v, got %+v", state, got)
+		}
+		if _, ok := store.GetRocketByID(uuid.New()); ok {
+			t.Errorf("Expected an unknown rocket not to be found")
+		}
+		if got := store.ListAllRockets(); len(got) != 1 {
+			t.Errorf("Expected 1 rocket, got %d", len(got))
+		}
+	})
+
+	t.Run("ApplyDelta", func(t *testing.T) {
+		store := newStore(t)
+		if _, ok := store.ApplyDelta(uuid.New(), Delta{Version: 1}); ok {
+			t.Errorf("Expected ApplyDelta to fail for an unknown rocket")
+		}
+		prev := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 500, Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+		store.SaveRocket(prev)
+		next := prev
+		next.Mission = "APOLLO"
+		next.Version = 2
+		delta, _ := Diff(prev, next)
+		if got, ok := store.ApplyDelta(prev.ID, delta); !ok || !reflect.DeepEqual(got, next) {
+			t.Errorf("Expected %+v, got %+v", next, got)
+		}
+		if got, _ := store.GetRocketByID(prev.ID); !reflect.DeepEqual(got, next) {
+			t.Errorf("Expected %+v, got %+v", next, got)
+		}
+	})
+
+	t.Run("DeleteRocket", func(t *testing.T) {
+		store := newStore(t)
+		state := State{ID: uuid.New(), Type: "Falcon-9", Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+		store.SaveRocket(state)
+		if !store.DeleteRocket(state.ID) {
+			t.Errorf("Expected the rocket to be deleted")
+		}
+		if store.DeleteRocket(state.ID) {
+			t.Errorf("Expected a deleted rocket not to be deleted again")
+		}
+		if _, ok := store.GetRocketByID(state.ID); ok {
+			t.Errorf("Expected a deleted rocket not to be found")
+		}
+		if got := store.ListRocketsBy(IndexMission, "ARTEMIS"); len(got) != 0 {
+			t.Errorf("Expected a deleted rocket to be dropped from the indexes, got %+v", got)
+		}
+	})
+
+	t.Run("ListRocketsBy", func(t *testing.T) {
+		store := newStore(t)
+		a := State{ID: uuid.New(), Type: "Falcon-9", Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+		b := State{ID: uuid.New(), Type: "Falcon-9", Mission: "APOLLO", Status: StatusLaunched, Version: 1}
+		c := State{ID: uuid.New(), Type: "Soyuz", Mission: "ARTEMIS", Status: StatusExploded, Version: 1}
+		for _, state := range []State{a, b, c} {
+			store.SaveRocket(state)
+		}
+
+		// a explodes through a full save, b changes mission through a delta
+		a.Status = StatusExploded
+		a.Version = 2
+		store.SaveRocket(a)
+		moved := b
+		moved.Mission = "ARTEMIS"
+		moved.Version = 2
+		delta, _ := Diff(b, moved)
+		store.ApplyDelta(b.ID, delta)
+
+		cases := []struct {
+			index    Index
+			key      string
+			expected []uuid.UUID
+		}{
+			{IndexStatus, string(StatusLaunched), []uuid.UUID{b.ID}},
+			{IndexStatus, string(StatusExploded), []uuid.UUID{a.ID, c.ID}},
+			{IndexMission, "ARTEMIS", []uuid.UUID{a.ID, b.ID, c.ID}},
+			{IndexMission, "APOLLO", nil},
+			{IndexType, "Falcon-9", []uuid.UUID{a.ID, b.ID}},
+			{IndexType, "Starship", nil},
+		}
+		for _, tc := range cases {
+			if got := ids(store.ListRocketsBy(tc.index, tc.key)); !reflect.DeepEqual(got, sortedIDs(tc.expected)) {
+				t.Errorf("ListRocketsBy(%s, %s): expected %v, got %v", tc.index, tc.key, sortedIDs(tc.expected), got)
+			}
+		}
+	})
+
+	t.Run("QueryRockets", func(t *testing.T) {
+		store := newStore(t)
+		a := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 300, Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+		b := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 100, Mission: "APOLLO", Status: StatusLaunched, Version: 1}
+		c := State{ID: uuid.New(), Type: "Soyuz", CurrentSpeed: 200, Mission: "ARTEMIS", Status: StatusExploded, Version: 1}
+		for _, state := range []State{a, b, c} {
+			store.SaveRocket(state)
+		}
+
+		got := store.QueryRockets(ListQuery{Filter: Filter{Mission: "ARTEMIS"}, Sort: []SortKey{{Field: SortBySpeed}}, Limit: 1, Fields: []Field{FieldStatus}})
+		if expected := []State{{ID: c.ID, Status: StatusExploded}}; !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, got)
+		}
+		if got := store.QueryRockets(ListQuery{}); len(got) != 3 {
+			t.Errorf("Expected the zero query to list all 3 rockets, got %d", len(got))
+		}
+	})
+}
+
+func ids(states []State) []uuid.UUID {
+	var out []uuid.UUID
+	for _, state := range states {
+		out = append(out, state.ID)
+	}
+	return sortedIDs(out)
+}
+
+func sortedIDs(ids []uuid.UUID) []uuid.UUID {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+func TestInMemoryRocketStore_Conformance(t *testing.T) {
+	testStoreConformance(t, func(t *testing.T) Store {
+		return NewInMemoryRocketStore(zap.NewNop())
+	})
+}
+
+func TestFileRocketStore_Conformance(t *testing.T) {
+	testStoreConformance(t, func(t *testing.T) Store {
+		store, err := OpenFileRocketStore(filepath.Join(t.TempDir(), "rockets.jsonl"), nil, zap.NewNop())
+		if err != nil {
+			t.Fatalf("OpenFileRocketStore failed: %v", err)
+		}
+		t.Cleanup(func() { _ = store.Close() })
+		return store
+	})
+}
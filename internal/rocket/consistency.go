@@ -0,0 +1,257 @@
+package rocket
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"reflect"
+	"rockets/internal/logging"
+	"slices"
+	"time"
+)
+
+// Consistency checks of the stored rocket states
+const (
+	CheckNegativeSpeed       = "negative_speed"
+	CheckExplodedWithSpeed   = "exploded_with_speed"
+	CheckUnknownStatus       = "unknown_status"
+	CheckLaunchedWithoutInfo = "launched_without_info"
+	CheckNoMessage           = "no_processed_message"
+)
+
+// Violation - stored state breaking an invariant
+type Violation struct {
+	RocketID uuid.UUID `json:"rocketId"`
+	Check    string    `json:"check"`
+	Message  string    `json:"message"`
+	// Repaired - the state was fixed and saved
+	Repaired bool `json:"repaired"`
+}
+
+// Drift - stored state differing from the state folded from the rocket's event history
+type Drift struct {
+	RocketID uuid.UUID `json:"rocketId"`
+	// Fields - JSON names of the differing fields
+	Fields []string `json:"fields"`
+	Stored State    `json:"stored"`
+	Folded State    `json:"folded"`
+	// Fixed - the folded state was saved in place of the stored one
+	Fixed bool `json:"fixed"`
+}
+
+// ConsistencyReport - outcome of a consistency check
+type ConsistencyReport struct {
+	Checked    int         `json:"checked"`
+	Violations []Violation `json:"violations"`
+	// Drifts - found only when the event history is enabled
+	Drifts []Drift `json:"drifts,omitempty"`
+	// Unverified - rockets without history to fold, e.g. loaded from a persistent store
+	Unverified int `json:"unverified,omitempty"`
+}
+
+// CheckState verifies the invariants of the state. It returns the found violations and the state
+// with the repairable ones fixed; the violations are marked repaired accordingly.
+func CheckState(state State) ([]Violation, State) {
+	var violations []Violation
+	violate := func(check string, repaired bool, format string, args ...any) {
+		violations = append(violations, Violation{
+			RocketID: state.ID,
+			Check:    check,
+			Message:  fmt.Sprintf(format, args...),
+			Repaired: repaired,
+		})
+	}
+
+	fixed := state
+	switch state.Status {
+	case StatusLaunched, StatusExploded, StatusUnknown, StatusPartial:
+	default:
+		violate(CheckUnknownStatus, false, "unknown status %q", state.Status)
+	}
+	if state.CurrentSpeed < 0 {
+		violate(CheckNegativeSpeed, true, "speed %d is negative", state.CurrentSpeed)
+		fixed.CurrentSpeed = 0
+	}
+	if state.Status == StatusExploded && state.CurrentSpeed > 0 {
+		violate(CheckExplodedWithSpeed, true, "exploded rocket has speed %d", state.CurrentSpeed)
+		fixed.CurrentSpeed = 0
+	}
+	if state.Status == StatusLaunched && (state.Type == "" || state.Mission == "") {
+		violate(CheckLaunchedWithoutInfo, false, "launched rocket has no type or mission")
+	}
+	if state.LastProcessedMessageNumber <= 0 {
+		violate(CheckNoMessage, false, "last processed message number %d is not positive", state.LastProcessedMessageNumber)
+	}
+	return violations, fixed
+}
+
+// WarmUp verifies the invariants of every stored state before the service starts processing messages.
+// Violations are logged; with repair enabled the repairable ones are fixed and saved as a new version.
+func (s *ServiceImpl) WarmUp(ctx context.Context, repair bool) ConsistencyReport {
+	report := ConsistencyReport{Violations: []Violation{}}
+	for _, state := range s.store.ListAllRockets() {
+		report.Checked++
+		report.Violations = append(report.Violations, s.checkInvariants(ctx, state, repair)...)
+	}
+
+	s.logger.Info("Store warm-up finished",
+		zap.Int("checked", report.Checked),
+		zap.Int("violations", len(report.Violations)),
+	)
+	return report
+}
+
+// checkInvariants logs violations of the state and, with repair enabled, saves the repaired state as a new version
+func (s *ServiceImpl) checkInvariants(ctx context.Context, state State, repair bool) []Violation {
+	violations, fixed := CheckState(state)
+	repaired := false
+	for i := range violations {
+		violations[i].Repaired = violations[i].Repaired && repair
+		repaired = repaired || violations[i].Repaired
+		s.logger.Warn("Inconsistent rocket state",
+			logging.Event(logging.EventStateInconsistent),
+			zap.String("rocket_id", state.ID.String()),
+			zap.String("check", violations[i].Check),
+			zap.String("message", violations[i].Message),
+			zap.Bool("repaired", violations[i].Repaired),
+		)
+	}
+	if repaired {
+		fixed.Version = state.Version + 1
+		s.rewrite(ctx, RewriteFix, state, fixed)
+	}
+	return violations
+}
+
+// CheckConsistency folds the effective event history of every rocket and compares the result with the stored state,
+// to catch bugs in incremental processing. Invariants of the stored states are checked as well.
+// With fix enabled drifted states are replaced by the folded ones and violations repaired, both as new versions.
+func (s *ServiceImpl) CheckConsistency(ctx context.Context, fix bool) ConsistencyReport {
+	report := ConsistencyReport{Violations: []Violation{}}
+	if s.history != nil {
+		report.Drifts = []Drift{}
+	}
+
+	for _, listed := range s.store.ListAllRockets() {
+		s.exclusive(listed.ID, func() {
+			s.checkRocket(ctx, listed.ID, fix, &report)
+		})
+	}
+
+	s.logger.Info("Consistency check finished",
+		zap.Int("checked", report.Checked),
+		zap.Int("violations", len(report.Violations)),
+		zap.Int("drifts", len(report.Drifts)),
+		zap.Int("unverified", report.Unverified),
+	)
+	return report
+}
+
+// checkRocket adds the violations and drift of the stored state to the report. Must be called from exclusive.
+func (s *ServiceImpl) checkRocket(ctx context.Context, id uuid.UUID, fix bool, report *ConsistencyReport) {
+	stored, ok := s.store.GetRocketByID(id)
+	if !ok {
+		return
+	}
+	report.Checked++
+
+	if s.history != nil {
+		folded, ok := fold(s.history.ListEvents(stored.ID), s.rules)
+		if ok && !s.heartbeats && stored.Version == folded.Version && stored.LastProcessedMessageNumber > folded.LastProcessedMessageNumber {
+			// heartbeats left out of the history move the liveness of the stored state ahead of it without a new
+			// version, so the same version tells only heartbeats were applied after the last event
+			folded.LastProcessedMessageNumber, folded.LastUpdateTime = stored.LastProcessedMessageNumber, stored.LastUpdateTime
+		}
+		if !ok {
+			report.Unverified++
+		} else if fields := diffStates(stored, folded); len(fields) > 0 {
+			drift := Drift{RocketID: stored.ID, Fields: fields, Stored: stored, Folded: folded, Fixed: fix}
+			s.logger.Warn("Rocket state drifted from its history",
+				logging.Event(logging.EventStateDrift),
+				zap.String("rocket_id", stored.ID.String()),
+				zap.Strings("fields", fields),
+				zap.Bool("fixed", fix),
+			)
+			if fix {
+				folded.Version = stored.Version + 1
+				s.rewrite(ctx, RewriteFix, stored, folded)
+				s.dedup.forget(stored.ID)
+				stored = folded
+			}
+			report.Drifts = append(report.Drifts, drift)
+		}
+	}
+
+	report.Violations = append(report.Violations, s.checkInvariants(ctx, stored, fix)...)
+}
+
+// fold replays the effective events of a rocket. When the history does not start with the first version
+// (it was truncated or started after a restart) folding starts from the state of the oldest event.
+// The folded state has the version of the last event. It returns false when there is nothing to fold.
+func fold(events []Event, r rules) (State, bool) {
+	var state State
+	started := false
+	for _, event := range events {
+		if event.Superseded {
+			continue
+		}
+		if !started {
+			started = true
+			if event.State.Version != 1 {
+				state = event.State
+				continue
+			}
+			state = State{ID: event.State.ID, Status: StatusUnknown}
+		}
+		if lateLaunch(state, event.Message, r) {
+			state = backfill(state, event.Message, r)
+		} else {
+			state, _ = apply(state, event.Message, r)
+		}
+		state.Version = event.State.Version
+	}
+	return state, started
+}
+
+// diffStates returns the JSON names of the fields of the states that differ, ignoring the version
+func diffStates(a, b State) []string {
+	var fields []string
+	if a.Type != b.Type {
+		fields = append(fields, "type")
+	}
+	if a.CurrentSpeed != b.CurrentSpeed {
+		fields = append(fields, "currentSpeed")
+	}
+	if a.Mission != b.Mission {
+		fields = append(fields, "mission")
+	}
+	if a.Status != b.Status {
+		fields = append(fields, "status")
+	}
+	if !reflect.DeepEqual(a.Reason, b.Reason) {
+		fields = append(fields, "reason")
+	}
+	if !reflect.DeepEqual(a.Anomaly, b.Anomaly) {
+		fields = append(fields, "anomaly")
+	}
+	if !a.LastUpdateTime.Equal(b.LastUpdateTime) {
+		fields = append(fields, "lastUpdateTime")
+	}
+	if a.LastProcessedMessageNumber != b.LastProcessedMessageNumber {
+		fields = append(fields, "lastProcessedMessageNumber")
+	}
+	if !slices.EqualFunc(a.Prelaunch, b.Prelaunch, sameMessage) {
+		fields = append(fields, "prelaunch")
+	}
+	return fields
+}
+
+// sameMessage reports whether the messages are equal, comparing the times by the instant
+func sameMessage(a, b TelemetryMessage) bool {
+	if !a.Metadata.MessageTime.Equal(b.Metadata.MessageTime) {
+		return false
+	}
+	a.Metadata.MessageTime, b.Metadata.MessageTime = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,146 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestCheckState(t *testing.T) {
+	valid := State{
+		ID:                         uuid.New(),
+		Type:                       "Falcon-9",
+		Mission:                    "ARTEMIS",
+		CurrentSpeed:               100,
+		Status:                     StatusLaunched,
+		LastProcessedMessageNumber: 1,
+	}
+
+	cases := []struct {
+		name     string
+		mutate   func(s *State)
+		expected []string
+	}{
+		{"valid", func(s *State) {}, nil},
+		{"negative speed", func(s *State) { s.CurrentSpeed = -5 }, []string{CheckNegativeSpeed}},
+		{"exploded with speed", func(s *State) { s.Status = StatusExploded }, []string{CheckExplodedWithSpeed}},
+		{"unknown status", func(s *State) { s.Status = "FLYING" }, []string{CheckUnknownStatus}},
+		{"launched without mission", func(s *State) { s.Mission = "" }, []string{CheckLaunchedWithoutInfo}},
+		{"no message", func(s *State) { s.LastProcessedMessageNumber = 0 }, []string{CheckNoMessage}},
+	}
+	for _, c := range cases {
+		state := valid
+		c.mutate(&state)
+		violations, fixed := CheckState(state)
+		if len(violations) != len(c.expected) {
+			t.Errorf("%s: expected violations %v, got %+v", c.name, c.expected, violations)
+			continue
+		}
+		for i, v := range violations {
+			if v.Check != c.expected[i] {
+				t.Errorf("%s: expected check %s, got %s", c.name, c.expected[i], v.Check)
+			}
+			if v.Repaired && fixed.CurrentSpeed != 0 {
+				t.Errorf("%s: expected repaired speed 0, got %d", c.name, fixed.CurrentSpeed)
+			}
+		}
+	}
+}
+
+func TestRocketService_WarmUp(t *testing.T) {
+	store := NewInMemoryRocketStore(zap.NewNop())
+	broken := State{
+		ID:                         uuid.New(),
+		Status:                     StatusExploded,
+		CurrentSpeed:               700,
+		LastProcessedMessageNumber: 4,
+		Version:                    4,
+	}
+	store.SaveRocket(broken)
+	svc := NewRocketService(store, zap.NewNop())
+
+	report := svc.WarmUp(context.Background(), false)
+	if report.Checked != 1 || len(report.Violations) != 1 || report.Violations[0].Repaired {
+		t.Fatalf("Expected 1 unrepaired violation, got %+v", report)
+	}
+	if state, _ := store.GetRocketByID(broken.ID); state.CurrentSpeed != 700 {
+		t.Errorf("Expected state to stay untouched without repair, got speed %d", state.CurrentSpeed)
+	}
+
+	report = svc.WarmUp(context.Background(), true)
+	if len(report.Violations) != 1 || !report.Violations[0].Repaired {
+		t.Fatalf("Expected 1 repaired violation, got %+v", report)
+	}
+	state, _ := store.GetRocketByID(broken.ID)
+	if state.CurrentSpeed != 0 || state.Version != 5 {
+		t.Errorf("Expected repaired state with speed 0 and version 5, got %+v", state)
+	}
+}
+
+func TestRocketService_CheckConsistency(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	service := NewRocketService(store, logger)
+	service.UseHistory(NewInMemoryHistoryStore())
+	ctx := context.Background()
+
+	rocketID := uuid.New()
+	launchTime := time.Now().UTC().Truncate(time.Millisecond)
+	messages := []TelemetryMessage{
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 1, MessageTime: launchTime, MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 2, MessageTime: launchTime.Add(time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(3000))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 3, MessageTime: launchTime.Add(2 * time.Second), MessageType: MessageTypeMissionChanged},
+			Message:  Message{NewMission: ptr(Mission("GARBAGE"))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	if _, err := service.RollbackRocket(ctx, rocketID, 3); err != nil {
+		t.Fatalf("RollbackRocket failed: %v", err)
+	}
+
+	report := service.CheckConsistency(ctx, false)
+	if report.Checked != 1 || len(report.Drifts) != 0 || len(report.Violations) != 0 {
+		t.Fatalf("Expected a consistent rocket after rollback, got %+v", report)
+	}
+
+	// simulate a bug in incremental processing
+	state, _ := store.GetRocketByID(rocketID)
+	state.CurrentSpeed = 42
+	store.SaveRocket(state)
+
+	report = service.CheckConsistency(ctx, false)
+	if len(report.Drifts) != 1 || report.Drifts[0].Fixed {
+		t.Fatalf("Expected 1 unfixed drift, got %+v", report)
+	}
+	if fields := report.Drifts[0].Fields; len(fields) != 1 || fields[0] != "currentSpeed" {
+		t.Errorf("Expected drift of currentSpeed, got %v", fields)
+	}
+	if report.Drifts[0].Folded.CurrentSpeed != 3500 {
+		t.Errorf("Expected folded speed 3500, got %d", report.Drifts[0].Folded.CurrentSpeed)
+	}
+
+	report = service.CheckConsistency(ctx, true)
+	if len(report.Drifts) != 1 || !report.Drifts[0].Fixed {
+		t.Fatalf("Expected 1 fixed drift, got %+v", report)
+	}
+	fixed, _ := store.GetRocketByID(rocketID)
+	if fixed.CurrentSpeed != 3500 || fixed.Version != state.Version+1 {
+		t.Errorf("Expected fixed state with speed 3500 and version %d, got %+v", state.Version+1, fixed)
+	}
+	if report = service.CheckConsistency(ctx, false); len(report.Drifts) != 0 {
+		t.Errorf("Expected no drift after fixing, got %+v", report.Drifts)
+	}
+}
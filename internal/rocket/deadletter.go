@@ -0,0 +1,116 @@
+package rocket
+
+import (
+	"cmp"
+	"github.com/google/uuid"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DeadLetter - message that could not be applied, kept so it can be redriven once the cause is fixed:
+// a message rejected by validation or received while its channel was quarantined
+type DeadLetter struct {
+	Message TelemetryMessage `json:"message"`
+	// Reason - why the message failed the last time
+	Reason string `json:"reason"`
+	// Failures - times the message was received and failed, a producer retrying a poison message raises it
+	Failures  int       `json:"failures"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// RedriveReport - outcome of processing the dead letters again
+type RedriveReport struct {
+	// Redriven - dead letters processed again
+	Redriven int `json:"redriven"`
+	// Applied - of them, messages no longer failing, whatever processing did with them
+	Applied int `json:"applied"`
+	// Failed - of them, messages failing again, kept as dead letters
+	Failed int `json:"failed"`
+}
+
+// deadLetterKey - a message is identified by its channel and number, a resent message counts as a new failure.
+// Messages without a number, of unnumbered channels, are identified by the hash of their content instead.
+type deadLetterKey struct {
+	channel uuid.UUID
+	number  int64
+	hash    string
+}
+
+// deadLetters - bounded queue of failed messages kept in memory, the oldest ones are dropped when it is full
+type deadLetters struct {
+	mu      sync.Mutex
+	entries map[deadLetterKey]*DeadLetter
+	// order - keys in the order the messages first failed
+	order []deadLetterKey
+	// size - dead letters kept, 0 disables the queue
+	size int
+}
+
+func newDeadLetters(size int) *deadLetters {
+	return &deadLetters{entries: make(map[deadLetterKey]*DeadLetter), size: size}
+}
+
+// add records a failure of the message
+func (d *deadLetters) add(msg TelemetryMessage, reason string, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.size <= 0 {
+		return
+	}
+	key := deadLetterKey{channel: msg.Metadata.Channel, number: msg.Metadata.MessageNumber}
+	if key.number == 0 {
+		key.hash = contentHash(msg)
+	}
+	entry, ok := d.entries[key]
+	if !ok {
+		if len(d.order) >= d.size {
+			delete(d.entries, d.order[0])
+			d.order = d.order[1:]
+		}
+		entry = &DeadLetter{FirstSeen: now.UTC()}
+		d.entries[key] = entry
+		d.order = append(d.order, key)
+	}
+	entry.Message = msg
+	entry.Reason = reason
+	entry.Failures++
+	entry.LastSeen = now.UTC()
+}
+
+// take removes the dead letters of the channel, of all channels for uuid.Nil, and returns them sorted by channel
+// and message number, the order they are redriven in
+func (d *deadLetters) take(channel uuid.UUID) []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []DeadLetter
+	d.order = slices.DeleteFunc(d.order, func(key deadLetterKey) bool {
+		if channel != uuid.Nil && key.channel != channel {
+			return false
+		}
+		out = append(out, *d.entries[key])
+		delete(d.entries, key)
+		return true
+	})
+	slices.SortFunc(out, func(a, b DeadLetter) int {
+		if c := slices.Compare(a.Message.Metadata.Channel[:], b.Message.Metadata.Channel[:]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Message.Metadata.MessageNumber, b.Message.Metadata.MessageNumber)
+	})
+	return out
+}
+
+// list returns the dead letters of the channel, of all channels for uuid.Nil, oldest first
+func (d *deadLetters) list(channel uuid.UUID) []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]DeadLetter, 0, len(d.order))
+	for _, key := range d.order {
+		if channel == uuid.Nil || key.channel == channel {
+			out = append(out, *d.entries[key])
+		}
+	}
+	return out
+}
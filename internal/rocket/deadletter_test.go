@@ -0,0 +1,143 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRocketService_DeadLetters(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	service := NewRocketService(store, logger)
+	service.UseDeadLetters(2)
+	ctx := context.Background()
+
+	rocketID, other := uuid.New(), uuid.New()
+	message := func(id uuid.UUID, number int64, by Speed) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: time.Now(), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(by)},
+		}
+	}
+	launch := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 1, MessageTime: time.Now(), MessageType: MessageTypeLaunched},
+		Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+	}
+	if _, err := service.ProcessMessage(ctx, launch); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	// a poison message resent by its producer is counted, not queued twice
+	invalid := TelemetryMessage{Metadata: message(other, 1, 0).Metadata}
+	_, _ = service.ProcessMessage(ctx, invalid)
+	_, _ = service.ProcessMessage(ctx, invalid)
+	if letters := service.ListDeadLetters(ctx, other); len(letters) != 1 || letters[0].Failures != 2 {
+		t.Fatalf("Expected one dead letter failed twice, got %+v", letters)
+	}
+	service.QuarantineChannel(ctx, rocketID, "investigating")
+	_, _ = service.ProcessMessage(ctx, message(rocketID, 3, 200))
+	_, _ = service.ProcessMessage(ctx, message(rocketID, 2, 100))
+
+	letters := service.ListDeadLetters(ctx, uuid.Nil)
+	if len(letters) != 2 || letters[0].Message.Metadata.MessageNumber != 3 || letters[1].Message.Metadata.MessageNumber != 2 {
+		t.Fatalf("Expected the oldest dead letter dropped from the full queue, got %+v", letters)
+	}
+	if len(service.ListDeadLetters(ctx, other)) != 0 {
+		t.Errorf("Expected no dead letters of the other channel")
+	}
+
+	if report := service.RedriveDeadLetters(ctx, rocketID); report != (RedriveReport{Redriven: 2, Failed: 2}) {
+		t.Errorf("Expected the messages of the quarantined channel to fail again, got %+v", report)
+	}
+	service.ReleaseChannel(ctx, rocketID)
+	if report := service.RedriveDeadLetters(ctx, rocketID); report != (RedriveReport{Redriven: 2, Applied: 2}) {
+		t.Errorf("Expected the released channel to be redriven, got %+v", report)
+	}
+	if state, _ := store.GetRocketByID(rocketID); state.CurrentSpeed != 800 || state.LastProcessedMessageNumber != 3 {
+		t.Errorf("Expected the redriven messages applied in order, got speed %d and message %d", state.CurrentSpeed, state.LastProcessedMessageNumber)
+	}
+	if n := service.ClearDeadLetters(ctx, uuid.Nil); n != 0 {
+		t.Errorf("Expected an empty queue after the redrive, got %d", n)
+	}
+}
+
+func TestRocketService_RedriveNotQuarantining(t *testing.T) {
+	logger := zap.NewNop()
+	service := NewRocketService(NewInMemoryRocketStore(logger), logger)
+	service.UseDeadLetters(10)
+	service.AutoQuarantine(2)
+	ctx := context.Background()
+
+	rocketID := uuid.New()
+	invalid := func(number int64) TelemetryMessage {
+		return TelemetryMessage{Metadata: MessageMetadata{Channel: rocketID, MessageNumber: number, MessageTime: time.Now(), MessageType: MessageTypeSpeedIncreased}}
+	}
+	_, _ = service.ProcessMessage(ctx, invalid(1))
+	_, _ = service.ProcessMessage(ctx, invalid(2))
+	service.ReleaseChannel(ctx, rocketID)
+
+	if report := service.RedriveDeadLetters(ctx, rocketID); report != (RedriveReport{Redriven: 2, Failed: 2}) {
+		t.Errorf("Expected the invalid messages to fail again, got %+v", report)
+	}
+	if len(service.ListQuarantinedChannels(ctx)) != 0 {
+		t.Errorf("Expected the redrive not to quarantine the channel again")
+	}
+}
+
+func TestDeadLetters_Unnumbered(t *testing.T) {
+	letters := newDeadLetters(10)
+	rocketID := uuid.New()
+	now := time.Now()
+	message := func(by Speed) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: rocketID, MessageTime: now, MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(by)},
+		}
+	}
+	letters.add(message(100), "invalid", now)
+	letters.add(message(200), "invalid", now)
+	letters.add(message(100), "invalid", now)
+
+	list := letters.list(rocketID)
+	if len(list) != 2 || list[0].Failures != 2 || list[1].Failures != 1 {
+		t.Errorf("Expected unnumbered messages told apart by their content, got %+v", list)
+	}
+}
+
+// liveListener - listener remembering whether the applied messages were ingested live
+type liveListener struct {
+	live []bool
+}
+
+func (l *liveListener) StateChanged(ctx context.Context, _ TelemetryMessage, _, _ State) {
+	l.live = append(l.live, Live(ctx))
+}
+
+func TestRocketService_RedriveNotLive(t *testing.T) {
+	logger := zap.NewNop()
+	service := NewRocketService(NewInMemoryRocketStore(logger), logger)
+	service.UseDeadLetters(10)
+	listener := &liveListener{}
+	service.AddListener(listener)
+	ctx := context.Background()
+
+	rocketID := uuid.New()
+	_, _ = service.ProcessMessage(ctx, TelemetryMessage{
+		Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 1, MessageTime: time.Now(), MessageType: MessageTypeLaunched},
+		Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+	})
+	service.QuarantineChannel(ctx, rocketID, "noisy")
+	_, _ = service.ProcessMessage(ctx, TelemetryMessage{
+		Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 2, MessageTime: time.Now(), MessageType: MessageTypeSpeedIncreased},
+		Message:  Message{By: ptr(Speed(100))},
+	})
+	service.ReleaseChannel(ctx, rocketID)
+	service.RedriveDeadLetters(ctx, rocketID)
+
+	if len(listener.live) != 2 || !listener.live[0] || listener.live[1] {
+		t.Errorf("Expected the launch live and the redriven message not, got %v", listener.live)
+	}
+}
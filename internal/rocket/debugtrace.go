@@ -0,0 +1,109 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxDebugTraces - channels traced at the same time unless configured otherwise
+	defaultMaxDebugTraces = 16
+	// defaultDebugTraceTTL - how long a channel is traced unless configured otherwise
+	defaultDebugTraceTTL = 15 * time.Minute
+)
+
+// DebugTrace - channel whose processing is logged in detail: every decision, the reorder buffer state
+// and the duplicate checks, regardless of the configured log levels
+type DebugTrace struct {
+	Channel uuid.UUID `json:"channel"`
+	Since   time.Time `json:"since"`
+	// Expires - the channel stops being traced at this time
+	Expires time.Time `json:"expires"`
+}
+
+// debugTraces - registry of traced channels, bounded in number, entries expire after their TTL
+type debugTraces struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]DebugTrace
+	max     int
+	// ttl - default and longest time a channel is traced
+	ttl time.Duration
+}
+
+func newDebugTraces(max int, ttl time.Duration) *debugTraces {
+	return &debugTraces{
+		entries: make(map[uuid.UUID]DebugTrace),
+		max:     max,
+		ttl:     ttl,
+	}
+}
+
+// traced reports whether the channel is traced at the time, dropping its entry once expired
+func (d *debugTraces) traced(id uuid.UUID, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.entries) == 0 {
+		return false
+	}
+	entry, ok := d.entries[id]
+	if ok && !now.Before(entry.Expires) {
+		delete(d.entries, id)
+		return false
+	}
+	return ok
+}
+
+// add traces the channel for the TTL, the default one when zero, capped at the default. Tracing a traced channel
+// again extends its trace. It fails with ErrTooManyDebugTraces when the limit of traced channels is reached.
+func (d *debugTraces) add(id uuid.UUID, ttl time.Duration, now time.Time) (DebugTrace, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pruneLocked(now)
+	if ttl <= 0 || ttl > d.ttl {
+		ttl = d.ttl
+	}
+	entry, ok := d.entries[id]
+	if !ok {
+		if len(d.entries) >= d.max {
+			return DebugTrace{}, ErrTooManyDebugTraces
+		}
+		entry = DebugTrace{Channel: id, Since: now.UTC()}
+	}
+	entry.Expires = now.Add(ttl).UTC()
+	d.entries[id] = entry
+	return entry, nil
+}
+
+func (d *debugTraces) remove(id uuid.UUID) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.entries[id]
+	delete(d.entries, id)
+	return ok
+}
+
+// list returns the channels traced at the time, oldest first
+func (d *debugTraces) list(now time.Time) []DebugTrace {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pruneLocked(now)
+	entries := make([]DebugTrace, 0, len(d.entries))
+	for _, entry := range d.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Since.Before(entries[j].Since)
+	})
+	return entries
+}
+
+// pruneLocked drops the expired entries. Must be called with the lock held.
+func (d *debugTraces) pruneLocked(now time.Time) {
+	for id, entry := range d.entries {
+		if !now.Before(entry.Expires) {
+			delete(d.entries, id)
+		}
+	}
+}
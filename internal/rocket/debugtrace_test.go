@@ -0,0 +1,74 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"rockets/internal/clock"
+	"rockets/internal/logging"
+	"testing"
+	"time"
+)
+
+func TestRocketService_DebugTraces(t *testing.T) {
+	levels, _ := logging.NewLevels("info")
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(levels.Filter(core))
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), logger)
+	fake := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	service.UseClock(fake)
+	service.UseDebugTraces(1, time.Hour)
+	ctx := context.Background()
+
+	traced, other := uuid.New(), uuid.New()
+	launch := func(id uuid.UUID, number int64) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: fake.Now(), MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		}
+	}
+	debugEntries := func() int {
+		n := logs.FilterLevelExact(zap.DebugLevel).FilterField(zap.Bool("debug_trace", true)).Len()
+		logs.TakeAll()
+		return n
+	}
+
+	entry, err := service.TraceChannel(ctx, traced, 10*time.Minute)
+	if err != nil || entry.Expires != fake.Now().Add(10*time.Minute) {
+		t.Fatalf("Expected the channel traced for 10m, got %+v, %v", entry, err)
+	}
+	if _, err := service.TraceChannel(ctx, other, 0); !errors.Is(err, ErrTooManyDebugTraces) {
+		t.Errorf("Expected ErrTooManyDebugTraces over the limit, got %v", err)
+	}
+
+	_, _ = service.ProcessMessage(ctx, launch(other, 1))
+	if n := logs.FilterLevelExact(zap.DebugLevel).Len(); n != 0 {
+		t.Errorf("Expected no debug entries of an untraced channel, got %d", n)
+	}
+	_, _ = service.ProcessMessage(ctx, launch(traced, 1))
+	_, _ = service.ProcessMessage(ctx, launch(traced, 1))
+	if n := debugEntries(); n == 0 {
+		t.Errorf("Expected debug entries of the traced channel")
+	}
+
+	fake.Advance(10 * time.Minute)
+	if list := service.ListTracedChannels(ctx); len(list) != 0 {
+		t.Errorf("Expected the trace to expire, got %+v", list)
+	}
+	_, _ = service.ProcessMessage(ctx, launch(traced, 2))
+	if n := debugEntries(); n != 0 {
+		t.Errorf("Expected no debug entries after the trace expired, got %d", n)
+	}
+
+	if _, err := service.TraceChannel(ctx, other, 2*time.Hour); err != nil {
+		t.Fatalf("Expected the expired trace to free its slot, got %v", err)
+	}
+	if list := service.ListTracedChannels(ctx); len(list) != 1 || list[0].Expires != fake.Now().Add(time.Hour) {
+		t.Errorf("Expected the TTL capped at 1h, got %+v", list)
+	}
+	if !service.UntraceChannel(ctx, other) || service.UntraceChannel(ctx, other) {
+		t.Errorf("Expected the channel to be untraced exactly once")
+	}
+}
@@ -0,0 +1,302 @@
+package rocket
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/logging"
+	"slices"
+	"sync"
+)
+
+// DedupPolicy - how the number of an arriving message is checked against the messages already processed
+type DedupPolicy string
+
+const (
+	// DedupGapTolerant - a number not after the last processed one is a duplicate, numbers ahead of the next one
+	// are accepted and skip the gap, or are held by the reorder buffer
+	DedupGapTolerant DedupPolicy = "gap-tolerant"
+	// DedupStrict - only the number following the last processed one is accepted, a number ahead of it is
+	// rejected until the gap is filled
+	DedupStrict DedupPolicy = "strict"
+	// DedupWindowedExact - the applied numbers within a window below the highest one are tracked, so a late
+	// message not seen before is applied and only a number applied already is a duplicate
+	DedupWindowedExact DedupPolicy = "windowed-exact"
+)
+
+// DedupPolicies - all dedup policies
+var DedupPolicies = []DedupPolicy{DedupGapTolerant, DedupStrict, DedupWindowedExact}
+
+// Validate checks that the policy is known
+func (p DedupPolicy) Validate() error {
+	for _, known := range DedupPolicies {
+		if p == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown dedup policy %q", p)
+}
+
+// DedupClass - why the dedup policy did not apply a message
+type DedupClass string
+
+const (
+	// DedupStale - the number is not after the last processed one, or below the window of windowed-exact
+	DedupStale DedupClass = "stale"
+	// DedupDuplicate - the number was applied already, told apart from stale numbers by windowed-exact only
+	DedupDuplicate DedupClass = "duplicate"
+	// DedupGap - the number is ahead of the one following the last processed one, rejected by strict
+	DedupGap DedupClass = "gap"
+	// DedupConflict - a late message of windowed-exact whose effect depends on the order of the messages applied
+	// after it, with no history to recompute the state from
+	DedupConflict DedupClass = "conflict"
+)
+
+// dedup - the dedup policy of the service with the numbers seen by windowed-exact
+type dedup struct {
+	policy DedupPolicy
+	// window - numbers below the highest one tracked by windowed-exact
+	window int64
+	// report - called with the class of every message not applied, nil to not report
+	report func(DedupClass)
+
+	mu      sync.Mutex
+	rockets map[uuid.UUID]*seenNumbers
+}
+
+// seenNumbers - ring of the types of the messages numbered (highest-window, highest] applied to a rocket, by
+// their codes: typeNone for numbers not applied, typeUnknown for the ones applied before the tracking started
+type seenNumbers struct {
+	highest int64
+	types   []byte
+}
+
+const (
+	typeNone    byte = 0
+	typeUnknown byte = 0xff
+)
+
+// messageTypes - message types by their code in seenNumbers, less one
+var messageTypes = []MessageType{MessageTypeLaunched, MessageTypeSpeedIncreased, MessageTypeSpeedDecreased, MessageTypeExploded, MessageTypeMissionChanged, MessageTypeHeartbeat}
+
+func typeCode(t MessageType) byte {
+	for i, known := range messageTypes {
+		if known == t {
+			return byte(i + 1)
+		}
+	}
+	return typeUnknown
+}
+
+func newDedup(policy DedupPolicy, window int, report func(DedupClass)) *dedup {
+	return &dedup{policy: policy, window: int64(max(window, 1)), report: report, rockets: make(map[uuid.UUID]*seenNumbers)}
+}
+
+// check returns the class of the message not to be applied to the current state, empty when it is to be applied.
+// A late message of windowed-exact not seen before is applied with late.
+func (d *dedup) check(current State, exists bool, msg TelemetryMessage) (class DedupClass, late bool) {
+	n, last := msg.Metadata.MessageNumber, current.LastProcessedMessageNumber
+	switch {
+	case !exists:
+		return "", false
+	case n > last+1 && d.policy == DedupStrict:
+		return DedupGap, false
+	case n > last:
+		return "", false
+	case d.policy != DedupWindowedExact:
+		return DedupStale, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen, ok := d.rockets[current.ID]
+	switch {
+	// numbers processed before the tracking started, e.g. before a restart, count as seen
+	case !ok || n <= seen.highest-d.window:
+		return DedupStale, false
+	case seen.has(n, d.window):
+		return DedupDuplicate, false
+	default:
+		return "", true
+	}
+}
+
+// reject reports the class of a message not applied
+func (d *dedup) reject(class DedupClass) {
+	if d.report != nil {
+		d.report(class)
+	}
+}
+
+// applied marks the number of the applied message as seen. The numbers up to last, the last processed one before
+// the message, count as seen when the rocket is not tracked yet.
+func (d *dedup) applied(msg TelemetryMessage, last int64) {
+	if d.policy != DedupWindowedExact {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := msg.Metadata.Channel
+	seen, ok := d.rockets[id]
+	if !ok {
+		seen = &seenNumbers{highest: last, types: make([]byte, d.window)}
+		for i := range seen.types {
+			seen.types[i] = typeUnknown
+		}
+		d.rockets[id] = seen
+	}
+	seen.mark(msg.Metadata.MessageNumber, typeCode(msg.Metadata.MessageType), d.window)
+}
+
+// appliedAfter returns the types of the messages of the rocket applied with numbers after n, false when the type
+// of one of them is not known, e.g. it was applied before the tracking started
+func (d *dedup) appliedAfter(id uuid.UUID, n int64) ([]MessageType, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen, ok := d.rockets[id]
+	if !ok {
+		return nil, false
+	}
+	var types []MessageType
+	for k := n + 1; k <= seen.highest; k++ {
+		switch code := seen.types[k%d.window]; code {
+		case typeNone:
+		case typeUnknown:
+			return nil, false
+		default:
+			types = append(types, messageTypes[code-1])
+		}
+	}
+	return types, true
+}
+
+// forget stops tracking the numbers of the rocket, e.g. after a rollback made its superseded numbers free again
+func (d *dedup) forget(id uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.rockets, id)
+}
+
+func (s *seenNumbers) has(n, window int64) bool {
+	return s.types[n%window] != typeNone
+}
+
+// mark records the type of the message with the number, clearing the numbers skipped when it is the highest one
+func (s *seenNumbers) mark(n int64, code byte, window int64) {
+	if n <= s.highest-window {
+		return
+	}
+	if n > s.highest {
+		if n-s.highest >= window {
+			clear(s.types)
+		} else {
+			for k := s.highest + 1; k < n; k++ {
+				s.types[k%window] = typeNone
+			}
+		}
+		s.highest = n
+	}
+	s.types[n%window] = code
+}
+
+// commutes reports whether applying the late message after the messages of the later types leaves the state it
+// would have left applied in order: heartbeats change nothing but the liveness, speed changes of one direction
+// add up in any order, clamped decreases included, and speed changes don't touch the mission
+func commutes(late MessageType, later []MessageType, underflow UnderflowPolicy) bool {
+	speed := func(t MessageType) bool {
+		return t == MessageTypeSpeedIncreased || t == MessageTypeSpeedDecreased
+	}
+	for _, t := range later {
+		switch {
+		case late == MessageTypeHeartbeat || t == MessageTypeHeartbeat:
+		case late == t && late == MessageTypeSpeedIncreased:
+		case late == t && late == MessageTypeSpeedDecreased && underflow == UnderflowClamp:
+		case speed(late) && t == MessageTypeMissionChanged, late == MessageTypeMissionChanged && speed(t):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// decideLate decides what becomes of a late message of windowed-exact, numbered before messages applied already.
+// It is applied on top of the current state when its effect commutes with the messages applied after it;
+// otherwise the state is recomputed from the history with the message in its place, and without a history to do
+// so the message is not applied. A message applied on top of the current state is rejected by the reject
+// underflow policy like one in order.
+func (s *ServiceImpl) decideLate(current State, msg TelemetryMessage) decision {
+	id, n := current.ID, msg.Metadata.MessageNumber
+	onTop := decision{action: actionApply, late: true}
+	if s.rejectsUnderflow(current, msg) {
+		onTop = decision{action: actionUnderflow}
+	}
+	later, known := s.dedup.appliedAfter(id, n)
+	// the messages of a provisional state are remembered in the order they were applied
+	if known && current.Status != StatusPartial && commutes(msg.Metadata.MessageType, later, s.rules.underflow) {
+		return onTop
+	}
+
+	var events []Event
+	complete := false
+	if s.history != nil {
+		events, complete = s.effectiveHistory(id)
+	}
+	k := slices.IndexFunc(events, func(event Event) bool {
+		return event.Message.Metadata.MessageNumber > n
+	})
+	switch {
+	case s.history != nil && k < 0:
+		// only heartbeats left out of the history were applied after the message, it commutes with them
+		return onTop
+	case s.history == nil, k == 0 && !complete:
+		return decision{action: actionDuplicate, class: DedupConflict}
+	}
+
+	d := decision{action: actionRecompute, base: State{ID: id, Status: StatusUnknown}, replayed: []TelemetryMessage{msg}, supersede: events[k].State.Version}
+	if k > 0 {
+		d.base = events[k-1].State
+	}
+	for _, event := range events[k:] {
+		d.replayed = append(d.replayed, event.Message)
+	}
+	slices.SortStableFunc(d.replayed, func(a, b TelemetryMessage) int {
+		return cmp.Compare(a.Metadata.MessageNumber, b.Metadata.MessageNumber)
+	})
+	return d
+}
+
+// recomputed returns the current state recomputed by the decision of a late message, passing the events of the
+// replayed messages to record unless it is nil
+func (s *ServiceImpl) recomputed(current State, d decision, record func(Event)) State {
+	next := s.fold(d.base, d.replayed, current.Version, record)
+	// heartbeats left out of the history still count for the liveness
+	next.LastProcessedMessageNumber = max(next.LastProcessedMessageNumber, current.LastProcessedMessageNumber)
+	if current.LastUpdateTime.After(next.LastUpdateTime) {
+		next.LastUpdateTime = current.LastUpdateTime
+	}
+	return next
+}
+
+// recompute recomputes the state with the late message in its place, superseding the events replayed.
+// Must be called from exclusive.
+func (s *ServiceImpl) recompute(ctx context.Context, logger *zap.Logger, current State, msg TelemetryMessage, d decision) Result {
+	s.history.SupersedeEvents(current.ID, d.supersede)
+	next := s.recomputed(current, d, s.history.AppendEvent)
+	s.save(current, true, next)
+	s.dedup.applied(msg, current.LastProcessedMessageNumber)
+	s.notify(replaying(ctx), msg, current, next)
+	logger.Info("Late message applied in order, recomputing the later messages",
+		logging.Event(logging.EventStateTransition),
+		zap.String("rocket_id", current.ID.String()),
+		zap.Int64("msg_num", msg.Metadata.MessageNumber),
+		zap.Int("replayed", len(d.replayed)),
+		zap.Int64("version", next.Version),
+	)
+	return Result{
+		Outcome:  OutcomeApplied,
+		Version:  next.Version,
+		Warnings: []string{fmt.Sprintf("late message %d applied in order, recomputing the %d messages after it", msg.Metadata.MessageNumber, len(d.replayed)-1)},
+	}
+}
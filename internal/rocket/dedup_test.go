@@ -0,0 +1,170 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRocketService_DedupPolicies(t *testing.T) {
+	ctx := context.Background()
+	launchTime := time.Now().UTC().Truncate(time.Millisecond)
+	msg := func(id uuid.UUID, number int64) TelemetryMessage {
+		if number == 1 {
+			return TelemetryMessage{
+				Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: launchTime, MessageType: MessageTypeLaunched},
+				Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+			}
+		}
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: launchTime.Add(time.Duration(number) * time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(100))},
+		}
+	}
+
+	tests := []struct {
+		policy DedupPolicy
+		// numbers - the late message 2 is retried, for strict message 3 is resent after it
+		numbers  []int64
+		outcomes []Outcome
+		speed    Speed
+		classes  []DedupClass
+	}{
+		{
+			policy:   DedupGapTolerant,
+			numbers:  []int64{1, 3, 2, 2, 3},
+			outcomes: []Outcome{OutcomeApplied, OutcomeApplied, OutcomeDuplicate, OutcomeDuplicate, OutcomeDuplicate},
+			speed:    600,
+			classes:  []DedupClass{DedupStale, DedupStale, DedupStale},
+		},
+		{
+			policy:   DedupStrict,
+			numbers:  []int64{1, 3, 2, 2, 3},
+			outcomes: []Outcome{OutcomeApplied, OutcomeRejected, OutcomeApplied, OutcomeDuplicate, OutcomeApplied},
+			speed:    700,
+			classes:  []DedupClass{DedupGap, DedupStale},
+		},
+		{
+			policy: DedupWindowedExact,
+			// the late message 3 and message 5 are retried, message 1 is below the window of message 5
+			numbers:  []int64{1, 5, 3, 3, 5, 1},
+			outcomes: []Outcome{OutcomeApplied, OutcomeApplied, OutcomeApplied, OutcomeDuplicate, OutcomeDuplicate, OutcomeDuplicate},
+			speed:    700,
+			classes:  []DedupClass{DedupDuplicate, DedupDuplicate, DedupStale},
+		},
+	}
+	for _, tt := range tests {
+		logger := zap.NewNop()
+		store := NewInMemoryRocketStore(logger)
+		service := NewRocketService(store, logger)
+		var classes []DedupClass
+		service.UseDedupPolicy(tt.policy, 4, func(class DedupClass) {
+			classes = append(classes, class)
+		})
+
+		id := uuid.New()
+		for i, n := range tt.numbers {
+			result, err := service.ProcessMessage(ctx, msg(id, n))
+			if result.Outcome != tt.outcomes[i] {
+				t.Errorf("%s: expected message %d (#%d) %s, got %+v", tt.policy, n, i, tt.outcomes[i], result)
+			}
+			if (result.Outcome == OutcomeRejected) != errors.Is(err, ErrSequenceGap) {
+				t.Errorf("%s: expected ErrSequenceGap for a rejected message %d, got %v", tt.policy, n, err)
+			}
+		}
+		if state, _ := store.GetRocketByID(id); state.CurrentSpeed != tt.speed {
+			t.Errorf("%s: expected speed %d, got %d", tt.policy, tt.speed, state.CurrentSpeed)
+		}
+		if len(classes) != len(tt.classes) {
+			t.Fatalf("%s: expected rejections %v, got %v", tt.policy, tt.classes, classes)
+		}
+		for i := range classes {
+			if classes[i] != tt.classes[i] {
+				t.Errorf("%s: expected rejections %v, got %v", tt.policy, tt.classes, classes)
+				break
+			}
+		}
+	}
+}
+
+func TestRocketService_DedupLateConflict(t *testing.T) {
+	ctx := context.Background()
+	launchTime := time.Now().UTC().Truncate(time.Millisecond)
+	id := uuid.New()
+	messages := map[int64]TelemetryMessage{
+		1: {
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: launchTime, MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+		2: {
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 2, MessageTime: launchTime.Add(2 * time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(100))},
+		},
+		3: {
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 3, MessageTime: launchTime.Add(3 * time.Second), MessageType: MessageTypeMissionChanged},
+			Message:  Message{NewMission: ptr(Mission("GEMINI"))},
+		},
+		4: {
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 4, MessageTime: launchTime.Add(4 * time.Second), MessageType: MessageTypeExploded},
+			Message:  Message{Reason: ptr("PRESSURE_VESSEL_FAILURE")},
+		},
+	}
+
+	for _, history := range []bool{true, false} {
+		logger := zap.NewNop()
+		store := NewInMemoryRocketStore(logger)
+		service := NewRocketService(store, logger)
+		if history {
+			service.UseHistory(NewInMemoryHistoryStore())
+		}
+		var classes []DedupClass
+		service.UseDedupPolicy(DedupWindowedExact, 8, func(class DedupClass) {
+			classes = append(classes, class)
+		})
+
+		for _, n := range []int64{1, 3, 4} {
+			if _, err := service.ProcessMessage(ctx, messages[n]); err != nil {
+				t.Fatalf("Expected message %d applied, got %v", n, err)
+			}
+		}
+		// the late speed increase doesn't commute with the explosion
+		dry, err := service.DryRunMessage(ctx, messages[2])
+		if err != nil {
+			t.Fatalf("DryRunMessage failed: %v", err)
+		}
+		result, _ := service.ProcessMessage(ctx, messages[2])
+		state, _ := store.GetRocketByID(id)
+		if dry.Outcome != result.Outcome {
+			t.Errorf("Expected the dry run outcome %s (history %t), got %s", result.Outcome, history, dry.Outcome)
+		}
+		if history && (dry.Next == nil || dry.Next.Version != state.Version || dry.Next.Status != state.Status || dry.Next.CurrentSpeed != state.CurrentSpeed) {
+			t.Errorf("Expected the dry run to predict the state %+v, got %+v", state, dry.Next)
+		}
+		if state.Status != StatusExploded || state.CurrentSpeed != 0 || state.Mission != "GEMINI" {
+			t.Errorf("Expected an exploded rocket at speed 0 on GEMINI (history %t), got %+v", history, state)
+		}
+		if !state.LastUpdateTime.Equal(messages[4].Metadata.MessageTime) {
+			t.Errorf("Expected the last update time of message 4 (history %t), got %v", history, state.LastUpdateTime)
+		}
+
+		if history {
+			// messages 2 to 4 are replayed as versions 4 to 6
+			if result.Outcome != OutcomeApplied || result.Version != 6 {
+				t.Errorf("Expected the late message applied in order as version 6, got %+v", result)
+			}
+			if len(classes) != 0 {
+				t.Errorf("Expected no rejections, got %v", classes)
+			}
+			continue
+		}
+		if result.Outcome != OutcomeDuplicate || len(classes) != 1 || classes[0] != DedupConflict {
+			t.Errorf("Expected the late message rejected as a conflict without history, got %+v and %v", result, classes)
+		}
+		if state.Version != 3 {
+			t.Errorf("Expected version 3 without history, got %d", state.Version)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package rocket
+
+import (
+	"slices"
+	"time"
+)
+
+// Delta - fields of a rocket state changed by a single update. Nil fields are left unchanged; the position
+// in the message sequence and the version change with every update, so they are always set.
+type Delta struct {
+	Type         *RocketType      `json:"type,omitempty"`
+	CurrentSpeed *Speed           `json:"currentSpeed,omitempty"`
+	Mission      *Mission         `json:"mission,omitempty"`
+	Status       *Status          `json:"status,omitempty"`
+	Reason       *ExplosionReason `json:"reason,omitempty"`
+	Anomaly      *string          `json:"anomaly,omitempty"`
+	// Prelaunch - all remembered pre-launch messages, written by earlier versions
+	Prelaunch []TelemetryMessage `json:"prelaunch,omitempty"`
+	// PrelaunchAppended - pre-launch messages remembered by the update, replacing the ones with their numbers
+	PrelaunchAppended []TelemetryMessage `json:"prelaunchAppended,omitempty"`
+	// LastUnnumberedHash - empty when unchanged
+	LastUnnumberedHash         string    `json:"lastUnnumberedHash,omitempty"`
+	LastUpdateTime             time.Time `json:"lastUpdateTime"`
+	LastProcessedMessageNumber int64     `json:"lastProcessedMessageNumber"`
+	Version                    int64     `json:"version"`
+}
+
+// Diff returns the delta turning prev into next. It returns false when the change can't be expressed
+// as a delta, i.e. the reason, the anomaly or the pre-launch messages are cleared or dropped, and the whole state
+// has to be saved instead.
+func Diff(prev, next State) (Delta, bool) {
+	if (prev.Reason != nil && next.Reason == nil) || (prev.Anomaly != nil && next.Anomaly == nil) || (len(prev.Prelaunch) > 0 && len(next.Prelaunch) == 0) {
+		return Delta{}, false
+	}
+	d := Delta{
+		LastUpdateTime:             next.LastUpdateTime,
+		LastProcessedMessageNumber: next.LastProcessedMessageNumber,
+		Version:                    next.Version,
+	}
+	if prev.Type != next.Type {
+		d.Type = &next.Type
+	}
+	if prev.CurrentSpeed != next.CurrentSpeed {
+		d.CurrentSpeed = &next.CurrentSpeed
+	}
+	if prev.Mission != next.Mission {
+		d.Mission = &next.Mission
+	}
+	if prev.Status != next.Status {
+		d.Status = &next.Status
+	}
+	if next.Reason != nil && (prev.Reason == nil || *prev.Reason != *next.Reason) {
+		reason := *next.Reason
+		d.Reason = &reason
+	}
+	if next.Anomaly != nil && (prev.Anomaly == nil || *prev.Anomaly != *next.Anomaly) {
+		anomaly := *next.Anomaly
+		d.Anomaly = &anomaly
+	}
+	if !slices.EqualFunc(prev.Prelaunch, next.Prelaunch, sameMessage) {
+		for _, msg := range next.Prelaunch {
+			if !slices.ContainsFunc(prev.Prelaunch, func(m TelemetryMessage) bool { return sameMessage(m, msg) }) {
+				d.PrelaunchAppended = append(d.PrelaunchAppended, msg)
+			}
+		}
+		if !slices.EqualFunc(d.Apply(prev).Prelaunch, next.Prelaunch, sameMessage) {
+			return Delta{}, false
+		}
+	}
+	if prev.LastUnnumberedHash != next.LastUnnumberedHash {
+		d.LastUnnumberedHash = next.LastUnnumberedHash
+	}
+	return d, true
+}
+
+// Apply returns the state with the delta applied
+func (d Delta) Apply(state State) State {
+	if d.Type != nil {
+		state.Type = *d.Type
+	}
+	if d.CurrentSpeed != nil {
+		state.CurrentSpeed = *d.CurrentSpeed
+	}
+	if d.Mission != nil {
+		state.Mission = *d.Mission
+	}
+	if d.Status != nil {
+		state.Status = *d.Status
+	}
+	if d.Reason != nil {
+		reason := *d.Reason
+		state.Reason = &reason
+	}
+	if d.Anomaly != nil {
+		anomaly := *d.Anomaly
+		state.Anomaly = &anomaly
+	}
+	if d.Prelaunch != nil {
+		state.Prelaunch = slices.Clone(d.Prelaunch)
+	}
+	for _, msg := range d.PrelaunchAppended {
+		state.Prelaunch = remember(state.Prelaunch, msg)
+	}
+	if d.LastUnnumberedHash != "" {
+		state.LastUnnumberedHash = d.LastUnnumberedHash
+	}
+	state.LastUpdateTime = d.LastUpdateTime
+	state.LastProcessedMessageNumber = d.LastProcessedMessageNumber
+	state.Version = d.Version
+	return state
+}
@@ -0,0 +1,64 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	prev := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 500, Mission: "ARTEMIS", Status: StatusLaunched, LastProcessedMessageNumber: 1, Version: 1}
+	next := prev
+	next.CurrentSpeed = 800
+	next.LastUpdateTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	next.LastProcessedMessageNumber = 2
+	next.Version = 2
+
+	delta, ok := Diff(prev, next)
+	if !ok {
+		t.Fatalf("Expected the change to be expressible as a delta")
+	}
+	if delta.CurrentSpeed == nil || delta.Type != nil || delta.Mission != nil || delta.Status != nil || delta.Reason != nil {
+		t.Errorf("Expected only the speed to change, got %+v", delta)
+	}
+	if got := delta.Apply(prev); !reflect.DeepEqual(got, next) {
+		t.Errorf("Expected %+v, got %+v", next, got)
+	}
+
+	exploded := next
+	exploded.Reason = ClassifyReason(ptr("PRESSURE_VESSEL_FAILURE"))
+	cleared := exploded
+	cleared.Reason = nil
+	if _, ok := Diff(exploded, cleared); ok {
+		t.Errorf("Expected clearing the reason not to be expressible as a delta")
+	}
+
+	anomalous := next
+	anomalous.CurrentSpeed = 0
+	anomalous.Anomaly = ptr("speed 800 decreased by 900 at message 3")
+	delta, ok = Diff(next, anomalous)
+	if !ok || delta.Anomaly == nil {
+		t.Fatalf("Expected the anomaly to be part of the delta, got %+v", delta)
+	}
+	if got := delta.Apply(next); !reflect.DeepEqual(got, anomalous) {
+		t.Errorf("Expected %+v, got %+v", anomalous, got)
+	}
+
+	partial := State{ID: prev.ID, Status: StatusPartial, Mission: "ARTEMIS", LastProcessedMessageNumber: 2, Version: 1}
+	partial.Prelaunch = []TelemetryMessage{{Metadata: MessageMetadata{Channel: prev.ID, MessageNumber: 2, MessageType: MessageTypeMissionChanged}}}
+	more := partial
+	more.Prelaunch = append(partial.Prelaunch, TelemetryMessage{Metadata: MessageMetadata{Channel: prev.ID, MessageNumber: 3, MessageType: MessageTypeSpeedIncreased}})
+	delta, ok = Diff(partial, more)
+	if !ok || len(delta.PrelaunchAppended) != 1 || delta.PrelaunchAppended[0].Metadata.MessageNumber != 3 {
+		t.Fatalf("Expected the appended pre-launch message to be part of the delta, got %+v", delta)
+	}
+	if got := delta.Apply(partial); !reflect.DeepEqual(got, more) {
+		t.Errorf("Expected %+v, got %+v", more, got)
+	}
+	launched := more
+	launched.Prelaunch = nil
+	if _, ok := Diff(more, launched); ok {
+		t.Errorf("Expected clearing the pre-launch messages not to be expressible as a delta")
+	}
+}
@@ -0,0 +1,139 @@
+package rocket
+
+import (
+	"context"
+	"fmt"
+)
+
+// FieldChange - field of the rocket state changed by a message
+type FieldChange struct {
+	Field Field
+	From  any
+	To    any
+}
+
+// DryRun - what processing a message would do, computed without changing anything
+type DryRun struct {
+	Outcome Outcome
+	// Current - state of the rocket before the message, nil for an unknown rocket
+	Current *State
+	// Next - state the message would lead to, nil when it would not be applied
+	Next *State
+	// Changes - fields the message would change, in the order of the API
+	Changes []FieldChange
+	// Underflow - the message would decrease the speed below zero
+	Underflow bool
+}
+
+// changeFields - fields compared by a dry run
+var changeFields = []Field{
+	FieldType,
+	FieldCurrentSpeed,
+	FieldMission,
+	FieldStatus,
+	FieldReason,
+	FieldAnomaly,
+	FieldLastUpdateTime,
+	FieldLastProcessedMessageNumber,
+}
+
+// DryRunMessage validates the message and checks what processing it would do against the current state: the
+// outcome and the resulting state with the changed fields. Nothing is saved, held or recorded, and the
+// consecutive failures of the channel are not counted. Invalid messages fail with ErrInvalidMessage, messages
+// the strict dedup policy rejects with ErrSequenceGap.
+func (s *ServiceImpl) DryRunMessage(_ context.Context, msg TelemetryMessage) (DryRun, error) {
+	if err := s.validate(msg); err != nil {
+		return DryRun{}, err
+	}
+	rocketID := msg.Metadata.Channel
+	var result DryRun
+	currentState, exists := s.store.GetRocketByID(rocketID)
+	if exists {
+		result.Current = &currentState
+	}
+	if window, ok := s.unnumbered.Window(msg.Metadata); ok && !s.quarantine.quarantined(rocketID) {
+		if msg, _, ok = s.number(msg, currentState, exists, window, false); !ok {
+			result.Outcome = OutcomeDuplicate
+			return result, nil
+		}
+	}
+
+	if s.quarantine.quarantined(rocketID) {
+		result.Outcome = OutcomeIgnored
+		return result, nil
+	}
+
+	var next State
+	switch d := s.decide(currentState, exists, msg); d.action {
+	case actionBackfill:
+		result.Outcome = OutcomeBackfilled
+		next = backfill(currentState, msg, s.rules)
+		next.Version = currentState.Version + 1
+	case actionGap:
+		return DryRun{}, fmt.Errorf("%w: message %d is ahead of message %d expected next", ErrSequenceGap, msg.Metadata.MessageNumber, currentState.LastProcessedMessageNumber+1)
+	case actionDuplicate:
+		result.Outcome = OutcomeDuplicate
+		return result, nil
+	case actionHold:
+		result.Outcome = OutcomeBuffered
+		return result, nil
+	case actionUnderflow:
+		return DryRun{}, s.underflowError(currentState, msg)
+	case actionRecompute:
+		result.Outcome = OutcomeApplied
+		next = s.recomputed(currentState, d, nil)
+	default:
+		result.Outcome = OutcomeApplied
+		next = currentState
+		if !exists {
+			next = State{ID: rocketID, Status: StatusUnknown}
+		}
+		next, result.Underflow = apply(next, msg, s.rules)
+		next.Version = currentState.Version + 1
+		if exists && s.silent(msg) {
+			next.Version = currentState.Version
+		}
+	}
+	result.Next = &next
+	result.Changes = changes(currentState, next)
+	return result, nil
+}
+
+// changes lists the fields differing between the states
+func changes(prev, next State) []FieldChange {
+	var out []FieldChange
+	for _, f := range changeFields {
+		if from, to := fieldValue(f, prev), fieldValue(f, next); from != to {
+			out = append(out, FieldChange{Field: f, From: from, To: to})
+		}
+	}
+	return out
+}
+
+// fieldValue returns the comparable value of the field, nil for an unset reason or anomaly
+func fieldValue(f Field, state State) any {
+	switch f {
+	case FieldType:
+		return state.Type
+	case FieldCurrentSpeed:
+		return state.CurrentSpeed
+	case FieldMission:
+		return state.Mission
+	case FieldStatus:
+		return state.Status
+	case FieldReason:
+		if state.Reason != nil {
+			return state.Reason.Text
+		}
+	case FieldAnomaly:
+		if state.Anomaly != nil {
+			return *state.Anomaly
+		}
+	case FieldLastUpdateTime:
+		// without the location and the monotonic reading, so equal times compare equal
+		return state.LastUpdateTime.UTC()
+	case FieldLastProcessedMessageNumber:
+		return state.LastProcessedMessageNumber
+	}
+	return nil
+}
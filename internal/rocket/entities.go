@@ -11,6 +11,10 @@ type Status string
 const (
 	StatusLaunched Status = "LAUNCHED"
 	StatusExploded Status = "EXPLODED"
+	// StatusUnknown - the rocket was seen, but its launch message has not arrived yet
+	StatusUnknown Status = "UNKNOWN"
+	// StatusPartial - the launch message has not arrived yet, the state holds what the later messages told
+	StatusPartial Status = "PARTIAL"
 )
 
 // MessageType - telemetry message type
@@ -22,18 +26,43 @@ const (
 	MessageTypeMissionChanged MessageType = "RocketMissionChanged"
 	MessageTypeSpeedDecreased MessageType = "RocketSpeedDecreased"
 	MessageTypeSpeedIncreased MessageType = "RocketSpeedIncreased"
+	// MessageTypeHeartbeat - the rocket is alive, e.g. coasting: only the last update time and message number
+	// advance, the state is otherwise left as it is
+	MessageTypeHeartbeat MessageType = "RocketHeartbeat"
+)
+
+// SignatureStatus - outcome of verifying the producer's signature of a message on receipt
+type SignatureStatus string
+
+const (
+	// SignatureVerified - the signature matches the payload and the key of the producer
+	SignatureVerified SignatureStatus = "verified"
+	// SignatureInvalid - the message carried a signature not matching the payload or the key, it may be tampered
+	SignatureInvalid SignatureStatus = "invalid"
+	// SignatureMissing - the producer has a key, but the message carried no signature
+	SignatureMissing SignatureStatus = "missing"
 )
 
 // State - rocket state
 type State struct {
-	ID                         uuid.UUID `json:"id"`
-	Type                       string    `json:"type"`
-	CurrentSpeed               int64     `json:"currentSpeed"`
-	Mission                    string    `json:"mission"`
-	Status                     Status    `json:"status"`
-	Reason                     *string   `json:"reason,omitempty"`
+	ID           uuid.UUID  `json:"id"`
+	Type         RocketType `json:"type"`
+	CurrentSpeed Speed      `json:"currentSpeed"`
+	Mission      Mission    `json:"mission"`
+	Status       Status     `json:"status"`
+	// Reason - why the rocket exploded, nil when it didn't or no reason was reported
+	Reason *ExplosionReason `json:"reason,omitempty"`
+	// Anomaly - the last speed decrease below zero, set by the anomalous underflow policy
+	Anomaly                    *string   `json:"anomaly,omitempty"`
 	LastUpdateTime             time.Time `json:"lastUpdateTime"`
 	LastProcessedMessageNumber int64     `json:"lastProcessedMessageNumber"`
+	// Version - incremented on every change of the state
+	Version int64 `json:"version"`
+	// Prelaunch - messages applied to a PARTIAL state, replayed on top of the launch message when it arrives late
+	Prelaunch []TelemetryMessage `json:"prelaunch,omitempty"`
+	// LastUnnumberedHash - hash of the content of the last applied message sent without a number, rejecting a
+	// retried copy of it after the in-memory hashes are lost
+	LastUnnumberedHash string `json:"lastUnnumberedHash,omitempty"`
 }
 
 // MessageMetadata - metadata for telemetry messages
@@ -42,16 +71,22 @@ type MessageMetadata struct {
 	MessageNumber int64       `json:"messageNumber"`
 	MessageTime   time.Time   `json:"messageTime"`
 	MessageType   MessageType `json:"messageType"`
+	// Signature - verification of the producer's signature, empty when the producer has no key registered
+	Signature SignatureStatus `json:"signature,omitempty"`
+	// Producer - the producer of the API key the message was received with, empty when it is not known
+	Producer string `json:"producer,omitempty"`
+	// Unnumbered - the message was sent without a number and numbered by the service
+	Unnumbered bool `json:"unnumbered,omitempty"`
 }
 
 // Message - structure for telemetry messages
 type Message struct {
-	By          *int64  `json:"by,omitempty"`
-	LaunchSpeed *int64  `json:"launchSpeed,omitempty"`
-	Mission     *string `json:"mission,omitempty"`
-	NewMission  *string `json:"newMission,omitempty"`
-	Reason      *string `json:"reason,omitempty"`
-	Type        *string `json:"type,omitempty"`
+	By          *Speed      `json:"by,omitempty"`
+	LaunchSpeed *Speed      `json:"launchSpeed,omitempty"`
+	Mission     *Mission    `json:"mission,omitempty"`
+	NewMission  *Mission    `json:"newMission,omitempty"`
+	Reason      *string     `json:"reason,omitempty"`
+	Type        *RocketType `json:"type,omitempty"`
 }
 
 // TelemetryMessage - structure for telemetry messages with metadata
@@ -0,0 +1,40 @@
+package rocket
+
+import "errors"
+
+var (
+	// ErrInvalidMessage - the message is malformed or misses the payload required by its type
+	ErrInvalidMessage = errors.New("invalid message")
+	// ErrSequenceGap - the strict dedup policy rejected a message ahead of the one following the last processed one
+	ErrSequenceGap = errors.New("gap in the message sequence")
+	// ErrRocketNotFound - the rocket is not tracked by the service
+	ErrRocketNotFound = errors.New("rocket not found")
+	// ErrHistoryDisabled - the operation requires the event history, which is not enabled
+	ErrHistoryDisabled = errors.New("event history is disabled")
+	// ErrMessageNotFound - the message is not part of the rocket's effective history
+	ErrMessageNotFound = errors.New("message not found in rocket history")
+	// ErrHistoryTruncated - the operation reaches before the oldest event kept in the rocket's history
+	ErrHistoryTruncated = errors.New("event history does not reach back far enough")
+	// ErrSameChannel - a channel can't be merged into its own rocket
+	ErrSameChannel = errors.New("can't merge a channel into itself")
+	// ErrRocketExists - the rocket to create is already tracked
+	ErrRocketExists = errors.New("rocket already exists")
+	// ErrNoPriorState - there is no state to roll back to before the message
+	ErrNoPriorState = errors.New("no state prior to message")
+	// ErrUnknownSortField - the rockets can't be listed by the requested field
+	ErrUnknownSortField = errors.New("unknown sort field")
+	// ErrUnknownSortOrder - the requested sort order is neither ascending nor descending
+	ErrUnknownSortOrder = errors.New("unknown sort order")
+	// ErrUnknownSortModifier - the requested comparison of the string fields is not known
+	ErrUnknownSortModifier = errors.New("unknown sort modifier")
+	// ErrInvalidQuery - the listing query has invalid page bounds or projects unknown fields
+	ErrInvalidQuery = errors.New("invalid list query")
+	// ErrForbidden - the scope of the caller doesn't allow managing the rockets
+	ErrForbidden = errors.New("access to rockets denied")
+	// ErrRegistrationConflict - the rocket to register already reports another type or mission
+	ErrRegistrationConflict = errors.New("registration conflicts with the rocket telemetry")
+	// ErrChannelMoved - the channel was handed off to the replica owning it now
+	ErrChannelMoved = errors.New("channel moved to another replica")
+	// ErrTooManyDebugTraces - the limit of channels traced at the same time is reached
+	ErrTooManyDebugTraces = errors.New("too many traced channels")
+)
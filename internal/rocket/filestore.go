@@ -0,0 +1,399 @@
+package rocket
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"rockets/internal/atomicfile"
+	"rockets/internal/encrypt"
+	"rockets/internal/logging"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ Store = (*FileRocketStore)(nil)
+
+// record - line of the store file, either a whole state, a delta of the state with the same ID or the deletion
+// of the rocket
+type record struct {
+	State
+	Delta   *Delta `json:"delta,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// deltaRecord - line of the store file persisting only the changed fields of a rocket
+type deltaRecord struct {
+	ID    uuid.UUID `json:"id"`
+	Delta Delta     `json:"delta"`
+}
+
+// deletedRecord - line of the store file removing a rocket
+type deletedRecord struct {
+	ID      uuid.UUID `json:"id"`
+	Deleted bool      `json:"deleted"`
+}
+
+// FileRocketStore keeps rocket states in memory and persists every saved state to an append-only file.
+// The file is replayed and compacted when the store is opened, so states survive restarts.
+// A standby store only replays the file, leaving it to the instance writing it, until it is promoted.
+type FileRocketStore struct {
+	// mem - replayed states, replaced as a whole when a standby store is refreshed
+	mem  atomic.Pointer[InMemoryRocketStore]
+	path string
+	// cipher - encrypts the records written, nil writes them as plain JSON
+	cipher    *encrypt.Cipher
+	logger    *zap.Logger
+	logWrites bool
+
+	mu    sync.Mutex
+	file  *os.File
+	batch *groupCommit
+	// writing - the write or sync in progress, for Stalled
+	writing busy
+}
+
+// OpenFileRocketStore loads the states persisted in the file, compacts it and opens it for appending.
+// The file is created when it does not exist. With a cipher the records are encrypted; with a cipher accepting
+// plain records, the records of a file written without one are read and encrypted by the compaction.
+func OpenFileRocketStore(path string, cipher *encrypt.Cipher, logger *zap.Logger) (*FileRocketStore, error) {
+	s, err := OpenStandbyFileRocketStore(path, cipher, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// OpenStandbyFileRocketStore loads the states persisted in the file without compacting or writing it, so
+// another instance may keep appending to it. Saves are rejected until the store is promoted.
+func OpenStandbyFileRocketStore(path string, cipher *encrypt.Cipher, logger *zap.Logger) (*FileRocketStore, error) {
+	s := &FileRocketStore{
+		path:   path,
+		cipher: cipher,
+		logger: logger,
+	}
+	records, err := s.reload()
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Store loaded", zap.String("path", path), zap.Int("records", records), zap.Int("rockets", len(s.ListAllRockets())))
+	return s, nil
+}
+
+// Refresh replays the file again, picking up the states appended by the instance writing it.
+func (s *FileRocketStore) Refresh() error {
+	records, err := s.reload()
+	if err != nil {
+		return err
+	}
+	s.logger.Debug("Store refreshed", zap.String("path", s.path), zap.Int("records", records))
+	return nil
+}
+
+// Promote replays the file written by the previous leader, compacts it and opens it for appending.
+// The previous writer must have stopped.
+func (s *FileRocketStore) Promote() error {
+	records, err := s.reload()
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Store promoted", zap.String("path", s.path), zap.Int("records", records), zap.Int("rockets", len(s.ListAllRockets())))
+	return s.open()
+}
+
+// reload replays the file into new in-memory states replacing the current ones
+func (s *FileRocketStore) reload() (int, error) {
+	mem := NewInMemoryRocketStore(s.logger)
+	records, err := s.load(mem)
+	if err != nil {
+		return 0, err
+	}
+	mem.LogWrites(s.logWrites)
+	s.mem.Store(mem)
+	return records, nil
+}
+
+// open compacts the file and opens it for appending
+func (s *FileRocketStore) open() error {
+	if err := s.compact(); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("can't open store file: %w", err)
+	}
+	s.mu.Lock()
+	s.file = file
+	s.mu.Unlock()
+	return nil
+}
+
+// load replays the file into mem, applying deltas on top of the last state of their rocket,
+// and returns the number of records read. An encrypted record that can't be decrypted fails the load unless it
+// is the torn last line, so a missing or wrong key never compacts the states away. With a cipher a plain record
+// fails it the same way, so no unauthenticated record can be slipped into the file, unless the cipher accepts
+// plain records to encrypt a file written without one.
+func (s *FileRocketStore) load(mem *InMemoryRocketStore) (int, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("can't open store file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	line, undecryptable, plain := 0, 0, false
+	unreadable := func() error {
+		if plain {
+			return fmt.Errorf("plain store record at line %d in an encrypted store", undecryptable)
+		}
+		return fmt.Errorf("can't decrypt store record at line %d, check the encryption keys", undecryptable)
+	}
+	for scanner.Scan() {
+		line++
+		if undecryptable > 0 {
+			return 0, unreadable()
+		}
+		data := scanner.Bytes()
+		if len(data) > 0 && data[0] == '{' && s.cipher != nil && !s.cipher.AcceptsPlain() {
+			undecryptable, plain = line, true
+			continue
+		}
+		if len(data) > 0 && data[0] != '{' {
+			if s.cipher == nil {
+				undecryptable = line
+				continue
+			}
+			if data, err = s.cipher.Open(data); err != nil {
+				undecryptable = line
+				continue
+			}
+		}
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			// a torn last line is expected after a crash, anything else is corruption
+			s.logger.Warn("Skipping unreadable store record", logging.Event(logging.EventStoreRecordSkipped), zap.String("path", s.path), zap.Int("line", line), zap.Error(err))
+			continue
+		}
+		if rec.Deleted {
+			mem.DeleteRocket(rec.ID)
+			continue
+		}
+		if rec.Delta == nil {
+			mem.restore(rec.State)
+			continue
+		}
+		prev, ok := mem.GetRocketByID(rec.ID)
+		if !ok {
+			s.logger.Warn("Skipping store delta of unknown rocket", logging.Event(logging.EventStoreRecordSkipped), zap.String("path", s.path), zap.Int("line", line), zap.String("rocket_id", rec.ID.String()))
+			continue
+		}
+		mem.restore(rec.Delta.Apply(prev))
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("can't read store file: %w", err)
+	}
+	if undecryptable > 0 {
+		// a torn write lacks the newline ending every record
+		info, err := file.Stat()
+		if err != nil {
+			return 0, fmt.Errorf("can't stat store file: %w", err)
+		}
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil || last[0] == '\n' {
+			return 0, unreadable()
+		}
+		s.logger.Warn("Skipping undecryptable last store record", logging.Event(logging.EventStoreRecordSkipped), zap.String("path", s.path), zap.Int("line", undecryptable))
+	}
+	return line, nil
+}
+
+// compact rewrites the file with the latest state of every rocket
+func (s *FileRocketStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("can't create store file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, state := range s.mem.Load().ListAllRockets() {
+		b, err := s.encode(state)
+		if err == nil {
+			_, err = w.Write(b)
+		}
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("can't write store file: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("can't write store file: %w", err)
+	}
+	if err := atomicfile.Replace(tmp, s.path); err != nil {
+		return fmt.Errorf("can't replace store file: %w", err)
+	}
+	return nil
+}
+
+// LogWrites enables logging a summary of the fields changed by every write.
+// Must be called before the store is used.
+func (s *FileRocketStore) LogWrites(enabled bool) {
+	s.logWrites = enabled
+	s.mem.Load().LogWrites(enabled)
+}
+
+// SaveRocket saves the current state of a rocket and appends it to the file.
+// Write failures are logged, the in-memory state is updated regardless.
+func (s *FileRocketStore) SaveRocket(state State) {
+	s.mem.Load().SaveRocket(state)
+
+	s.append(state.ID, state)
+}
+
+// ApplyDelta applies the changed fields to the stored state and appends only the delta to the file.
+// Write failures are logged, the in-memory state is updated regardless.
+func (s *FileRocketStore) ApplyDelta(id uuid.UUID, delta Delta) (State, bool) {
+	state, ok := s.mem.Load().ApplyDelta(id, delta)
+	if ok {
+		s.append(id, deltaRecord{ID: id, Delta: delta})
+	}
+	return state, ok
+}
+
+// DeleteRocket removes the state of the rocket and appends its deletion to the file.
+// Write failures are logged, the in-memory state is removed regardless.
+func (s *FileRocketStore) DeleteRocket(id uuid.UUID) bool {
+	ok := s.mem.Load().DeleteRocket(id)
+	if ok {
+		s.append(id, deletedRecord{ID: id, Deleted: true})
+	}
+	return ok
+}
+
+// UseGroupCommit coalesces the records appended within the window into one write followed by a sync.
+// With wait, saves return once their record is synced; otherwise they return immediately and up to
+// a window of updates may be lost on a crash. Must be called before the store is used.
+func (s *FileRocketStore) UseGroupCommit(window time.Duration, wait bool) {
+	s.batch = newGroupCommit(window, wait, s.commit)
+}
+
+// encode marshals the record as a line of the file, encrypted with the cipher of the store
+func (s *FileRocketStore) encode(rec any) ([]byte, error) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal record: %w", err)
+	}
+	if s.cipher != nil {
+		if b, err = s.cipher.Seal(b); err != nil {
+			return nil, fmt.Errorf("can't encrypt record: %w", err)
+		}
+	}
+	return append(b, '\n'), nil
+}
+
+// append writes the record as a line of the file, or adds it to the current batch
+func (s *FileRocketStore) append(id uuid.UUID, rec any) {
+	b, err := s.encode(rec)
+	if err != nil {
+		s.logger.Error("Can't encode rocket state", logging.Event(logging.EventStoreSaveError), zap.String("rocket_id", id.String()), zap.Error(err))
+		return
+	}
+	if s.batch != nil {
+		if err := s.batch.append(b); err != nil {
+			s.logger.Error("Can't persist rocket state", logging.Event(logging.EventStoreSaveError), zap.String("rocket_id", id.String()), zap.Error(err))
+		}
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		s.logger.Error("Can't persist rocket state to a standby store", logging.Event(logging.EventStoreSaveError), zap.String("rocket_id", id.String()))
+		return
+	}
+	s.writing.begin()
+	defer s.writing.end()
+	if _, err := s.file.Write(b); err != nil {
+		s.logger.Error("Can't persist rocket state", logging.Event(logging.EventStoreSaveError), zap.String("rocket_id", id.String()), zap.Error(err))
+	}
+}
+
+// commit writes and syncs a batch of records, failures are logged
+func (s *FileRocketStore) commit(batch []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		s.logger.Error("Can't persist batch of rocket states to a standby store", logging.Event(logging.EventStoreSaveError), zap.Int("bytes", len(batch)))
+		return
+	}
+	s.writing.begin()
+	defer s.writing.end()
+	if _, err := s.file.Write(batch); err != nil {
+		s.logger.Error("Can't persist batch of rocket states", logging.Event(logging.EventStoreSaveError), zap.Int("bytes", len(batch)), zap.Error(err))
+		return
+	}
+	if err := s.file.Sync(); err != nil {
+		s.logger.Error("Can't sync store file", logging.Event(logging.EventStoreSaveError), zap.Error(err))
+	}
+}
+
+// Stalled returns an error when a write or sync of the file has been running for longer than the limit, e.g. on
+// a hung disk, so the states are no longer persisted
+func (s *FileRocketStore) Stalled(limit time.Duration) error {
+	if d := s.writing.stalled(); d > limit {
+		return fmt.Errorf("store write running for %s", d.Truncate(time.Millisecond))
+	}
+	return nil
+}
+
+// GetRocketByID retrieves the state of a rocket by its ID
+func (s *FileRocketStore) GetRocketByID(id uuid.UUID) (State, bool) {
+	return s.mem.Load().GetRocketByID(id)
+}
+
+// ListAllRockets lists all rockets in the store
+func (s *FileRocketStore) ListAllRockets() []State {
+	return s.mem.Load().ListAllRockets()
+}
+
+// ListRocketsBy lists the rockets whose field covered by the secondary index equals the key
+func (s *FileRocketStore) ListRocketsBy(index Index, key string) []State {
+	return s.mem.Load().ListRocketsBy(index, key)
+}
+
+// QueryRockets lists the rockets answering the validated query
+func (s *FileRocketStore) QueryRockets(query ListQuery) []State {
+	return s.mem.Load().QueryRockets(query)
+}
+
+// Close commits the pending batch, syncs and closes the file
+func (s *FileRocketStore) Close() error {
+	if s.batch != nil {
+		s.batch.close()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("can't sync store file: %w", err)
+	}
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("can't close store file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package rocket
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"reflect"
+	"rockets/internal/encrypt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileRocketStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rockets.jsonl")
+	logger := zap.NewNop()
+
+	store, err := OpenFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	id := uuid.New()
+	state := State{
+		ID:                         id,
+		Type:                       "Falcon-9",
+		CurrentSpeed:               500,
+		Mission:                    "ARTEMIS",
+		Status:                     StatusLaunched,
+		LastUpdateTime:             time.Now().UTC().Truncate(time.Millisecond),
+		LastProcessedMessageNumber: 1,
+		Version:                    1,
+	}
+	store.SaveRocket(state)
+	state.CurrentSpeed = 3500
+	state.LastProcessedMessageNumber = 2
+	state.Version = 2
+	store.SaveRocket(state)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// simulate a write torn by a crash
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"id":"` + id.String() + `","currentSp`)
+	_ = f.Close()
+
+	reopened, err := OpenFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	defer reopened.Close()
+
+	got, ok := reopened.GetRocketByID(id)
+	if !ok {
+		t.Fatalf("Expected rocket %s to survive reopening", id)
+	}
+	if !reflect.DeepEqual(got, state) {
+		t.Errorf("Expected %+v, got %+v", state, got)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(b), "\n"); lines != 1 {
+		t.Errorf("Expected the file to be compacted to 1 record, got %d", lines)
+	}
+}
+
+func TestFileRocketStore_DeleteRocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rockets.jsonl")
+	logger := zap.NewNop()
+
+	store, err := OpenFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	kept := State{ID: uuid.New(), Type: "Falcon-9", Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+	deleted := State{ID: uuid.New(), Type: "Soyuz", Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+	store.SaveRocket(kept)
+	store.SaveRocket(deleted)
+	store.DeleteRocket(deleted.ID)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := OpenFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	defer reopened.Close()
+	if _, ok := reopened.GetRocketByID(deleted.ID); ok {
+		t.Errorf("Expected rocket %s to stay deleted after reopening", deleted.ID)
+	}
+	if got, ok := reopened.GetRocketByID(kept.ID); !ok || !reflect.DeepEqual(got, kept) {
+		t.Errorf("Expected %+v, got %+v", kept, got)
+	}
+}
+
+func TestFileRocketStore_ApplyDelta(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rockets.jsonl")
+	logger := zap.NewNop()
+
+	store, err := OpenFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	if _, ok := store.ApplyDelta(uuid.New(), Delta{Version: 2}); ok {
+		t.Errorf("Expected ApplyDelta to fail for an unknown rocket")
+	}
+
+	prev := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 500, Mission: "ARTEMIS", Status: StatusLaunched, LastProcessedMessageNumber: 1, Version: 1}
+	store.SaveRocket(prev)
+	next := prev
+	next.CurrentSpeed = 0
+	next.Status = StatusExploded
+	next.Reason = ClassifyReason(ptr("PRESSURE_VESSEL_FAILURE"))
+	next.LastProcessedMessageNumber = 2
+	next.Version = 2
+	delta, ok := Diff(prev, next)
+	if !ok {
+		t.Fatalf("Expected the change to be expressible as a delta")
+	}
+	if got, ok := store.ApplyDelta(prev.ID, delta); !ok || !reflect.DeepEqual(got, next) {
+		t.Errorf("Expected %+v, got %+v", next, got)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"delta"`) || strings.Contains(lines[1], "Falcon-9") {
+		t.Errorf("Expected only the delta to be appended, got %s", b)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := OpenFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	defer reopened.Close()
+	if got, _ := reopened.GetRocketByID(prev.ID); !reflect.DeepEqual(got, next) {
+		t.Errorf("Expected the delta to be replayed.\nExpected: %+v\nGot: %+v", next, got)
+	}
+	if got := reopened.ListRocketsBy(IndexStatus, string(StatusExploded)); len(got) != 1 || got[0].ID != prev.ID {
+		t.Errorf("Expected the indexes to be rebuilt on load, got %+v", got)
+	}
+}
+
+func TestGroupCommit_AppendAfterClose(t *testing.T) {
+	var committed []byte
+	g := newGroupCommit(time.Hour, true, func(batch []byte) { committed = append(committed, batch...) })
+	done := make(chan error)
+	go func() { done <- g.append([]byte("a\n")) }()
+	// the pending record is committed by the close
+	for {
+		g.mu.Lock()
+		pending := len(g.pending)
+		g.mu.Unlock()
+		if pending > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	g.close()
+	if err := <-done; err != nil || string(committed) != "a\n" {
+		t.Fatalf("Expected the pending record committed by the close, got %q: %v", committed, err)
+	}
+
+	go func() { done <- g.append([]byte("b\n")) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, errCommitClosed) {
+			t.Errorf("Expected errCommitClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the append after close to return")
+	}
+}
+
+func TestFileRocketStore_GroupCommit(t *testing.T) {
+	for _, wait := range []bool{true, false} {
+		path := filepath.Join(t.TempDir(), "rockets.jsonl")
+		logger := zap.NewNop()
+
+		store, err := OpenFileRocketStore(path, nil, logger)
+		if err != nil {
+			t.Fatalf("OpenFileRocketStore failed: %v", err)
+		}
+		store.UseGroupCommit(5*time.Millisecond, wait)
+
+		var wg sync.WaitGroup
+		states := make([]State, 20)
+		for i := range states {
+			states[i] = State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: Speed(i), Status: StatusLaunched, LastProcessedMessageNumber: 1, Version: 1}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				store.SaveRocket(states[i])
+			}()
+		}
+		wg.Wait()
+
+		if wait {
+			// every save returned after its batch was synced
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lines := strings.Count(string(b), "\n"); lines != len(states) {
+				t.Errorf("Expected %d committed records, got %d", len(states), lines)
+			}
+		}
+		if err := store.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		reopened, err := OpenFileRocketStore(path, nil, logger)
+		if err != nil {
+			t.Fatalf("OpenFileRocketStore failed: %v", err)
+		}
+		for _, state := range states {
+			if got, _ := reopened.GetRocketByID(state.ID); !reflect.DeepEqual(got, state) {
+				t.Errorf("Expected %+v to be committed (wait=%v), got %+v", state, wait, got)
+			}
+		}
+		_ = reopened.Close()
+	}
+}
+
+func TestFileRocketStore_StandbyPromote(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rockets.jsonl")
+	logger := zap.NewNop()
+
+	leader, err := OpenFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	state := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 500, Status: StatusLaunched, LastProcessedMessageNumber: 1, Version: 1}
+	leader.SaveRocket(state)
+
+	standby, err := OpenStandbyFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenStandbyFileRocketStore failed: %v", err)
+	}
+	defer standby.Close()
+	if got, _ := standby.GetRocketByID(state.ID); !reflect.DeepEqual(got, state) {
+		t.Errorf("Expected the standby to load %+v, got %+v", state, got)
+	}
+
+	state.CurrentSpeed = 1500
+	state.LastProcessedMessageNumber = 2
+	state.Version = 2
+	leader.SaveRocket(state)
+	if err := standby.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if got, _ := standby.GetRocketByID(state.ID); !reflect.DeepEqual(got, state) {
+		t.Errorf("Expected the standby to pick up %+v, got %+v", state, got)
+	}
+	if err := leader.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if err := standby.Promote(); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+	state.CurrentSpeed = 2500
+	state.LastProcessedMessageNumber = 3
+	state.Version = 3
+	standby.SaveRocket(state)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(b), "\n"); lines != 2 {
+		t.Errorf("Expected the compacted state and the new save after promotion, got %d records", lines)
+	}
+}
+
+func TestFileRocketStore_Encryption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rockets.jsonl")
+	logger := zap.NewNop()
+	cipher := func(t *testing.T, keys ...string) *encrypt.Cipher {
+		t.Helper()
+		var raw [][]byte
+		for _, k := range keys {
+			raw = append(raw, []byte(k))
+		}
+		c, err := encrypt.New(raw)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		return c
+	}
+	oldKey, newKey := "0123456789abcdef0123456789abcdef", "fedcba9876543210fedcba9876543210"
+
+	// a plain file is rejected, unless the cipher accepts plain records to encrypt it by the compaction
+	store, err := OpenFileRocketStore(path, nil, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	id := uuid.New()
+	state := State{ID: id, Type: "Falcon-9", CurrentSpeed: 500, Mission: "ARTEMIS", Status: StatusLaunched, LastProcessedMessageNumber: 1, Version: 1}
+	store.SaveRocket(state)
+	store.SaveRocket(State{ID: uuid.New(), Type: "Falcon-9", Mission: "GEMINI", Status: StatusLaunched, LastProcessedMessageNumber: 1, Version: 1})
+	_ = store.Close()
+
+	if _, err := OpenStandbyFileRocketStore(path, cipher(t, oldKey), logger); err == nil {
+		t.Errorf("Expected the open of a plain file with a key to fail")
+	}
+	migrating := cipher(t, oldKey)
+	migrating.AcceptPlain()
+	store, err = OpenFileRocketStore(path, migrating, logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	state.CurrentSpeed, state.LastProcessedMessageNumber, state.Version = 3500, 2, 2
+	store.SaveRocket(state)
+	_ = store.Close()
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "ARTEMIS") || strings.Count(string(data), "\n") != 3 {
+		t.Fatalf("Expected three encrypted records, got %s", data)
+	}
+
+	// a plain record slipped into an encrypted file fails the open
+	injected := filepath.Join(t.TempDir(), "rockets.jsonl")
+	plain := `{"state":{"id":"` + id.String() + `","type":"Falcon-9","currentSpeed":0,"mission":"ARTEMIS","status":"EXPLODED"}}` + "\n"
+	_ = os.WriteFile(injected, append(append([]byte{}, data...), plain...), 0o600)
+	if _, err := OpenStandbyFileRocketStore(injected, cipher(t, oldKey), logger); err == nil {
+		t.Errorf("Expected the open with a plain record in an encrypted file to fail")
+	}
+
+	// a missing or wrong key fails the open instead of dropping the states
+	if _, err := OpenStandbyFileRocketStore(path, nil, logger); err == nil {
+		t.Errorf("Expected the open without a key to fail")
+	}
+	if _, err := OpenStandbyFileRocketStore(path, cipher(t, newKey), logger); err == nil {
+		t.Errorf("Expected the open with a wrong key to fail")
+	}
+
+	// a torn last record is skipped, the rotated key still decrypts the old records
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("c2VhbGVk")
+	_ = f.Close()
+	store, err = OpenFileRocketStore(path, cipher(t, newKey, oldKey), logger)
+	if err != nil {
+		t.Fatalf("OpenFileRocketStore failed: %v", err)
+	}
+	_ = store.Close()
+	if got, ok := store.GetRocketByID(id); !ok || got.CurrentSpeed != 3500 {
+		t.Errorf("Expected the latest state decrypted, got %+v", got)
+	}
+	if _, err := OpenStandbyFileRocketStore(path, cipher(t, newKey), logger); err != nil {
+		t.Errorf("Expected the compaction to re-encrypt with the new key, got %v", err)
+	}
+	if _, err := OpenStandbyFileRocketStore(path, cipher(t, oldKey), logger); err == nil {
+		t.Errorf("Expected the only, complete record not to be taken for a torn one")
+	}
+}
@@ -0,0 +1,92 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func FuzzProcessMessage(f *testing.F) {
+	f.Add("RocketLaunched", int64(2), int64(0), "Falcon-9", "ARTEMIS", uint8(0b0111))
+	f.Add("RocketSpeedIncreased", int64(2), int64(3000), "", "", uint8(0b1000))
+	f.Add("RocketSpeedDecreased", int64(3), int64(-9223372036854775808), "", "", uint8(0b1000))
+	f.Add("RocketMissionChanged", int64(4), int64(0), "", "MARS;", uint8(0b0100))
+	f.Add("RocketExploded", int64(0), int64(0), "", "", uint8(0))
+	f.Add("Unknown", int64(-1), int64(9223372036854775807), "x", "y", uint8(0xff))
+
+	logger := zap.NewNop()
+	channel := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+
+	f.Fuzz(func(t *testing.T, typ string, number, amount int64, rocketType, mission string, present uint8) {
+		// every input starts from a launched rocket
+		store := NewInMemoryRocketStore(logger)
+		service := NewRocketService(store, logger)
+		service.UseHistory(NewInMemoryHistoryStore())
+		launch := TelemetryMessage{
+			Metadata: MessageMetadata{Channel: channel, MessageNumber: 1, MessageTime: time.Unix(0, 0), MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		}
+		if _, err := service.ProcessMessage(context.Background(), launch); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+
+		msg := TelemetryMessage{
+			Metadata: MessageMetadata{
+				Channel:       channel,
+				MessageNumber: number,
+				MessageTime:   time.Unix(number%1e10, 0),
+				MessageType:   MessageType(typ),
+			},
+		}
+		// present selects which payload fields are set
+		if present&0b0001 != 0 {
+			msg.Message.Type = ptr(RocketType(rocketType))
+		}
+		if present&0b0010 != 0 {
+			msg.Message.LaunchSpeed = ptr(Speed(amount))
+		}
+		if present&0b0100 != 0 {
+			msg.Message.Mission = ptr(Mission(mission))
+			msg.Message.NewMission = ptr(Mission(mission))
+		}
+		if present&0b1000 != 0 {
+			msg.Message.By = ptr(Speed(amount))
+		}
+
+		before, _ := store.GetRocketByID(channel)
+		_, err := service.ProcessMessage(context.Background(), msg)
+		if err != nil && !errors.Is(err, ErrInvalidMessage) {
+			t.Fatalf("Expected nil or ErrInvalidMessage, got %v", err)
+		}
+
+		after, ok := store.GetRocketByID(channel)
+		if err != nil {
+			if !reflect.DeepEqual(after, before) {
+				t.Fatalf("Expected a rejected message to leave the state untouched")
+			}
+			return
+		}
+		if !ok || after.Version == before.Version {
+			return
+		}
+		// speed changes must never wrap around
+		switch msg.Metadata.MessageType {
+		case MessageTypeSpeedIncreased:
+			if after.CurrentSpeed < before.CurrentSpeed {
+				t.Fatalf("Speed decreased from %d to %d on increase by %d", before.CurrentSpeed, after.CurrentSpeed, *msg.Message.By)
+			}
+		case MessageTypeSpeedDecreased:
+			if after.CurrentSpeed > before.CurrentSpeed {
+				t.Fatalf("Speed increased from %d to %d on decrease by %d", before.CurrentSpeed, after.CurrentSpeed, *msg.Message.By)
+			}
+		case MessageTypeLaunched:
+			if after.CurrentSpeed < 0 || after.CurrentSpeed > MaxSpeed {
+				t.Fatalf("Launch speed %d is out of range", after.CurrentSpeed)
+			}
+		}
+	})
+}
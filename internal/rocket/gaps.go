@@ -0,0 +1,220 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/logging"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxGapsPerChannel - open gaps kept per channel, the oldest are dropped first
+const maxGapsPerChannel = 64
+
+// Gap - range of message numbers of a channel that never arrived
+type Gap struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// DetectedAt - when a message arrived after the range
+	DetectedAt time.Time `json:"detectedAt"`
+	// RequestedAt - when the retransmission of the range was requested, nil before
+	RequestedAt *time.Time `json:"requestedAt,omitempty"`
+	// Age - time since the gap was detected by the service clock, set by ListGaps
+	Age time.Duration `json:"-"`
+}
+
+// Size returns the number of missing messages
+func (g Gap) Size() int64 {
+	return g.To - g.From + 1
+}
+
+// ChannelGaps - the open gaps of a channel, the oldest first
+type ChannelGaps struct {
+	Channel uuid.UUID `json:"channel"`
+	// Missing - messages missing over all gaps
+	Missing int64 `json:"missing"`
+	Gaps    []Gap `json:"gaps"`
+}
+
+// gapTracker - message numbers missing per channel, found by comparing every arriving message with the highest
+// number the channel sent so far. A late message closes its part of a gap once it is applied or buffered.
+type gapTracker struct {
+	mu       sync.Mutex
+	channels map[uuid.UUID]*channelGaps
+}
+
+// channelGaps - highest number a channel sent and its open gaps
+type channelGaps struct {
+	highest int64
+	gaps    []Gap
+}
+
+func newGapTracker() *gapTracker {
+	return &gapTracker{channels: make(map[uuid.UUID]*channelGaps)}
+}
+
+// arrived opens a gap before a message ahead of the highest number. The first message seen of a tracked rocket is
+// compared with the last processed number, of a new rocket with nothing.
+func (t *gapTracker) arrived(id uuid.UUID, number int64, last int64, exists bool, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.channels[id]
+	if !ok {
+		c = &channelGaps{highest: number - 1}
+		if exists {
+			c.highest = last
+		}
+		t.channels[id] = c
+	}
+	if number > c.highest+1 {
+		c.gaps = append(c.gaps, Gap{From: c.highest + 1, To: number - 1, DetectedAt: now.UTC()})
+		if len(c.gaps) > maxGapsPerChannel {
+			c.gaps = c.gaps[len(c.gaps)-maxGapsPerChannel:]
+		}
+	}
+	c.highest = max(c.highest, number)
+}
+
+// filled closes the number of a message applied or buffered
+func (t *gapTracker) filled(id uuid.UUID, number int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c, ok := t.channels[id]; ok {
+		c.fill(number)
+	}
+}
+
+// backfilled closes the numbers of messages merged into the history of the channel outside of the sequence
+func (t *gapTracker) backfilled(id uuid.UUID, messages []TelemetryMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.channels[id]
+	if !ok {
+		return
+	}
+	for _, msg := range messages {
+		c.fill(msg.Metadata.MessageNumber)
+	}
+}
+
+// fill closes the number, splitting its gap
+func (c *channelGaps) fill(number int64) {
+	for i, g := range c.gaps {
+		if number < g.From || number > g.To {
+			continue
+		}
+		var parts []Gap
+		if number > g.From {
+			parts = append(parts, Gap{From: g.From, To: number - 1, DetectedAt: g.DetectedAt, RequestedAt: g.RequestedAt})
+		}
+		if number < g.To {
+			parts = append(parts, Gap{From: number + 1, To: g.To, DetectedAt: g.DetectedAt, RequestedAt: g.RequestedAt})
+		}
+		c.gaps = append(c.gaps[:i], append(parts, c.gaps[i+1:]...)...)
+		return
+	}
+}
+
+// list returns the channels with gaps detected before the cutoff, the channel with the oldest gap first
+func (t *gapTracker) list(cutoff time.Time) []ChannelGaps {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := []ChannelGaps{}
+	for id, c := range t.channels {
+		entry := ChannelGaps{Channel: id}
+		for _, g := range c.gaps {
+			if !g.DetectedAt.After(cutoff) {
+				entry.Gaps = append(entry.Gaps, g)
+				entry.Missing += g.Size()
+			}
+		}
+		if len(entry.Gaps) > 0 {
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Gaps[0].DetectedAt, out[j].Gaps[0].DetectedAt
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return out[i].Channel.String() < out[j].Channel.String()
+	})
+	return out
+}
+
+// expire drops the gaps detected before the cutoff and returns their number. Channels left without gaps are
+// forgotten, their highest number is taken from the state again with their next message.
+func (t *gapTracker) expire(cutoff time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var expired int
+	for id, c := range t.channels {
+		kept := c.gaps[:0]
+		for _, g := range c.gaps {
+			if g.DetectedAt.Before(cutoff) {
+				expired++
+				continue
+			}
+			kept = append(kept, g)
+		}
+		if len(kept) == 0 {
+			delete(t.channels, id)
+			continue
+		}
+		c.gaps = kept
+	}
+	return expired
+}
+
+// ListGaps returns the channels with message numbers that never arrived, only counting the gaps open for at least
+// minAge, the channel with the oldest gap first
+func (s *ServiceImpl) ListGaps(_ context.Context, minAge time.Duration) []ChannelGaps {
+	now := s.clock.Now()
+	channels := s.gaps.list(now.Add(-minAge))
+	for _, channel := range channels {
+		for i := range channel.Gaps {
+			channel.Gaps[i].Age = now.Sub(channel.Gaps[i].DetectedAt)
+		}
+	}
+	return channels
+}
+
+// RunGapReport reports the open gaps every interval until the context is done, dropping the ones older than the
+// retention. It returns once the retransmission requests in flight are done.
+func (s *ServiceImpl) RunGapReport(ctx context.Context, interval, retention time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.retransmits.Wait()
+			return nil
+		case <-ticker.C:
+			s.ReportGaps(ctx, retention)
+		}
+	}
+}
+
+// ReportGaps drops the gaps older than the retention, which are not expected to be filled anymore, logs every
+// channel with open gaps and, with a retransmitter, starts requesting the gaps open for longer than its threshold
+// in the background
+func (s *ServiceImpl) ReportGaps(ctx context.Context, retention time.Duration) {
+	now := s.clock.Now()
+	if expired := s.gaps.expire(now.Add(-retention)); expired > 0 {
+		s.logger.Info("Gaps older than the retention dropped", zap.Int("gaps", expired), zap.Duration("retention", retention))
+	}
+	for _, channel := range s.gaps.list(now) {
+		s.logger.Warn("Channel has missing messages",
+			logging.Event(logging.EventMessageGapsOpen),
+			zap.String("rocket_id", channel.Channel.String()),
+			zap.Int64("missing", channel.Missing),
+			zap.Int("gaps", len(channel.Gaps)),
+			zap.Duration("oldest_age", now.Sub(channel.Gaps[0].DetectedAt)),
+		)
+	}
+	if s.retransmitter != nil {
+		s.requestRetransmissions(ctx, now)
+	}
+}
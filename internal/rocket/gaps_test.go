@@ -0,0 +1,175 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"reflect"
+	"rockets/internal/clock"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRocketService_ListGaps(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	service.UseClock(fake)
+	// late messages are applied
+	service.UseDedupPolicy(DedupWindowedExact, 64, nil)
+
+	id, other := uuid.New(), uuid.New()
+	send := func(channel uuid.UUID, number int64) {
+		msg := TelemetryMessage{
+			Metadata: MessageMetadata{Channel: channel, MessageNumber: number, MessageTime: fake.Now(), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(10))},
+		}
+		if number == 1 {
+			msg.Metadata.MessageType = MessageTypeLaunched
+			msg.Message = Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))}
+		}
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	send(id, 1)
+	send(id, 6)
+	fake.Advance(10 * time.Minute)
+	send(id, 3)
+	send(other, 1)
+	send(other, 3)
+
+	expected := []ChannelGaps{
+		{Channel: id, Missing: 3, Gaps: []Gap{{From: 2, To: 2, DetectedAt: start, Age: 10 * time.Minute}, {From: 4, To: 5, DetectedAt: start, Age: 10 * time.Minute}}},
+		{Channel: other, Missing: 1, Gaps: []Gap{{From: 2, To: 2, DetectedAt: start.Add(10 * time.Minute)}}},
+	}
+	if got := service.ListGaps(ctx, 0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	if got := service.ListGaps(ctx, 5*time.Minute); !reflect.DeepEqual(got, expected[:1]) {
+		t.Errorf("Expected only the gaps open for 5 minutes, got %+v", got)
+	}
+
+	send(id, 2)
+	send(id, 4)
+	send(id, 5)
+	fake.Advance(time.Hour)
+	service.ReportGaps(ctx, 30*time.Minute)
+	if got := service.ListGaps(ctx, 0); len(got) != 0 {
+		t.Errorf("Expected the gaps to be filled or expired, got %+v", got)
+	}
+	if n := len(service.gaps.channels); n != 0 {
+		t.Errorf("Expected the channels without gaps forgotten, got %d", n)
+	}
+}
+
+func TestRocketService_ListGaps_DroppedMessage(t *testing.T) {
+	ctx := context.Background()
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	id := uuid.New()
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	launch := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: at, MessageType: MessageTypeLaunched},
+		Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+	}
+	increase := func(number int64) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: at.Add(time.Duration(number) * time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(10))},
+		}
+	}
+	for _, msg := range []TelemetryMessage{launch, increase(3), increase(2)} {
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	// gap-tolerant drops the late message, which leaves its number missing
+	if got := service.ListGaps(ctx, 0); len(got) != 1 || got[0].Missing != 1 || got[0].Gaps[0].From != 2 || got[0].Gaps[0].To != 2 {
+		t.Errorf("Expected the gap of message 2 left open, got %+v", got)
+	}
+}
+
+// retransmissions - retransmitter recording the requests, failing while err is set
+type retransmissions struct {
+	mu       sync.Mutex
+	requests []RetransmitRequest
+	err      error
+}
+
+func (r *retransmissions) RequestRetransmission(_ context.Context, req RetransmitRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.requests = append(r.requests, req)
+	return nil
+}
+
+func TestRocketService_ReportGaps_RequestsRetransmission(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	service.UseClock(fake)
+	service.UseDedupPolicy(DedupWindowedExact, 64, nil)
+	producer := &retransmissions{err: errors.New("producer unreachable")}
+	service.UseRetransmission(producer, 5*time.Minute)
+
+	id := uuid.New()
+	send := func(number int64) {
+		msg := TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: fake.Now(), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(10))},
+		}
+		if number == 1 {
+			msg.Metadata.MessageType = MessageTypeLaunched
+			msg.Message = Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))}
+		}
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	send(1)
+	send(5)
+	fake.Advance(time.Minute)
+	service.ReportGaps(ctx, time.Hour)
+	service.retransmits.Wait()
+	if len(producer.requests) != 0 {
+		t.Fatalf("Expected no request for a gap open for less than the threshold, got %+v", producer.requests)
+	}
+
+	// a failed request is made again on the next report
+	fake.Advance(5 * time.Minute)
+	service.ReportGaps(ctx, time.Hour)
+	service.retransmits.Wait()
+	producer.err = nil
+	send(3)
+	send(8)
+	service.ReportGaps(ctx, time.Hour)
+	service.retransmits.Wait()
+	requestedAt := fake.Now()
+	expected := []RetransmitRequest{{Channel: id, Ranges: []MessageRange{{From: 2, To: 2}, {From: 4, To: 4}}, RequestedAt: requestedAt}}
+	if !reflect.DeepEqual(producer.requests, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, producer.requests)
+	}
+
+	// requested gaps are not requested again, the later one once it stays open past the threshold
+	fake.Advance(5 * time.Minute)
+	service.ReportGaps(ctx, time.Hour)
+	service.retransmits.Wait()
+	expected = append(expected, RetransmitRequest{Channel: id, Ranges: []MessageRange{{From: 6, To: 7}}, RequestedAt: fake.Now()})
+	if !reflect.DeepEqual(producer.requests, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, producer.requests)
+	}
+	gaps := service.ListGaps(ctx, 0)
+	if len(gaps) != 1 || len(gaps[0].Gaps) != 3 || gaps[0].Gaps[0].RequestedAt == nil || !gaps[0].Gaps[0].RequestedAt.Equal(requestedAt) {
+		t.Errorf("Expected the gaps to be marked as requested, got %+v", gaps)
+	}
+}
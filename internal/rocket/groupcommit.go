@@ -0,0 +1,101 @@
+package rocket
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// errCommitClosed - the record was appended after the group commit was closed, it is not written
+var errCommitClosed = errors.New("group commit is closed")
+
+// groupCommit - coalesces records appended within a window into a single write and sync
+type groupCommit struct {
+	window time.Duration
+	// wait - appenders block until their record is committed, otherwise up to a window of records
+	// may be lost on a crash
+	wait   bool
+	commit func(batch []byte)
+
+	mu        sync.Mutex
+	pending   []byte
+	closed    bool
+	committed chan struct{}
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// newGroupCommit starts committing the appended records every window through the commit function
+func newGroupCommit(window time.Duration, wait bool, commit func(batch []byte)) *groupCommit {
+	g := &groupCommit{
+		window:    window,
+		wait:      wait,
+		commit:    commit,
+		committed: make(chan struct{}),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go g.run()
+	return g
+}
+
+// append adds the record to the current batch, waiting for its commit if configured so. It returns
+// errCommitClosed once the group commit is closed, as nothing would commit the record.
+func (g *groupCommit) append(record []byte) error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return errCommitClosed
+	}
+	g.pending = append(g.pending, record...)
+	committed := g.committed
+	g.mu.Unlock()
+
+	if g.wait {
+		<-committed
+	}
+	return nil
+}
+
+func (g *groupCommit) run() {
+	defer close(g.stopped)
+	ticker := time.NewTicker(g.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.stop:
+			g.flush()
+			return
+		case <-ticker.C:
+			g.flush()
+		}
+	}
+}
+
+// flush commits the pending records and wakes up the appenders waiting for them
+func (g *groupCommit) flush() {
+	g.mu.Lock()
+	batch, committed := g.pending, g.committed
+	if len(batch) == 0 {
+		g.mu.Unlock()
+		return
+	}
+	g.pending, g.committed = nil, make(chan struct{})
+	g.mu.Unlock()
+
+	g.commit(batch)
+	close(committed)
+}
+
+// close commits the remaining records and stops committing, the records appended afterwards are rejected
+func (g *groupCommit) close() {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return
+	}
+	g.closed = true
+	g.mu.Unlock()
+	close(g.stop)
+	<-g.stopped
+}
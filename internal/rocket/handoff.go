@@ -0,0 +1,152 @@
+package rocket
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"slices"
+	"time"
+)
+
+// Handoff - hot state of a channel moving to another replica: its rocket state and the messages the reorder
+// buffer holds ahead of a gap
+type Handoff struct {
+	Channel uuid.UUID          `json:"channel"`
+	State   *State             `json:"state,omitempty"`
+	Held    []TelemetryMessage `json:"held,omitempty"`
+	// HeldSince - when the gap before the held messages opened
+	HeldSince *time.Time `json:"heldSince,omitempty"`
+}
+
+// HandoffReport - outcome of a handoff accepted from another replica
+type HandoffReport struct {
+	// States - states taken over, Kept - states kept because the replica already had a newer one
+	States int `json:"states"`
+	Kept   int `json:"kept"`
+	// Held - held messages taken over
+	Held int `json:"held"`
+	// Invalid - states and held messages rejected as malformed
+	Invalid int `json:"invalid"`
+	// Foreign - handoffs of channels the replica doesn't own, left out
+	Foreign int `json:"foreign"`
+}
+
+// ExportHandoff returns the hot state of the channels the replica no longer owns, for their new owners. Every
+// channel is taken under its lock: the held messages are taken out of the reorder buffer along with the state,
+// which is left in place. The messages of the channel processed afterwards are rejected with ErrChannelMoved by
+// the owner check of UseOwner, so none is applied after its state was taken.
+func (s *ServiceImpl) ExportHandoff(_ context.Context, moved func(channel uuid.UUID) bool) []Handoff {
+	var ids []uuid.UUID
+	for _, state := range s.store.ListAllRockets() {
+		ids = append(ids, state.ID)
+	}
+	if s.reorder != nil {
+		ids = append(ids, s.reorder.channels()...)
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	ids = slices.Compact(ids)
+
+	var result []Handoff
+	for _, id := range ids {
+		if !moved(id) {
+			continue
+		}
+		s.exclusive(id, func() {
+			if h, ok := s.snapshotHandoff(id); ok {
+				result = append(result, h)
+			}
+		})
+	}
+	return result
+}
+
+// snapshotHandoff takes the hot state of the channel, false when it has none. Must be called from exclusive.
+func (s *ServiceImpl) snapshotHandoff(id uuid.UUID) (Handoff, bool) {
+	handoff := Handoff{Channel: id}
+	if state, ok := s.store.GetRocketByID(id); ok {
+		handoff.State = &state
+	}
+	if s.reorder != nil {
+		if h := s.reorder.take(id); h != nil {
+			for _, msg := range h.messages {
+				handoff.Held = append(handoff.Held, msg)
+			}
+			slices.SortFunc(handoff.Held, func(a, b TelemetryMessage) int {
+				return cmp.Compare(a.Metadata.MessageNumber, b.Metadata.MessageNumber)
+			})
+			since := h.since
+			handoff.HeldSince = &since
+		}
+	}
+	return handoff, handoff.State != nil || len(handoff.Held) > 0
+}
+
+// AcceptHandoff takes over the hot state of channels handed off by their previous owner. A state replaces the
+// replica's own unless that one has processed more messages, e.g. when messages reached the replica before the
+// handoff. The held messages go to the reorder buffer, or are processed right away without one, and the ones
+// following the state are applied. Malformed states and held messages, e.g. of another channel, are left out.
+func (s *ServiceImpl) AcceptHandoff(ctx context.Context, handoffs []Handoff) HandoffReport {
+	var report HandoffReport
+	for _, h := range handoffs {
+		logger := s.channelLogger(s.logger, h.Channel)
+		held := slices.DeleteFunc(slices.Clone(h.Held), func(msg TelemetryMessage) bool {
+			return msg.Metadata.Channel != h.Channel || s.validate(msg) != nil
+		})
+		report.Invalid += len(h.Held) - len(held)
+		h.Held = held
+		if h.State != nil && !validHandoffState(h.Channel, *h.State) {
+			h.State = nil
+			report.Invalid++
+		}
+		s.exclusive(h.Channel, func() {
+			if h.State != nil {
+				current, exists := s.store.GetRocketByID(h.Channel)
+				if exists && current.LastProcessedMessageNumber >= h.State.LastProcessedMessageNumber {
+					report.Kept++
+				} else {
+					state := *h.State
+					state.Version = max(state.Version, current.Version+1)
+					s.rewrite(ctx, RewriteHandoff, current, state)
+					s.dedup.forget(h.Channel)
+					report.States++
+				}
+			}
+			if s.reorder == nil || len(h.Held) == 0 {
+				return
+			}
+			since := s.clock.Now()
+			if h.HeldSince != nil {
+				since = *h.HeldSince
+			}
+			for _, msg := range h.Held {
+				if s.reorder.holdSince(msg, since) {
+					report.Held++
+				}
+			}
+			s.release(replaying(ctx), logger, h.Channel, false)
+		})
+		if s.reorder == nil {
+			for _, msg := range h.Held {
+				if _, err := s.ProcessMessage(replaying(ctx), msg); err != nil {
+					logger.Warn("Can't process a handed off message", zap.Int64("msg_num", msg.Metadata.MessageNumber), zap.Error(err))
+					continue
+				}
+				report.Held++
+			}
+		}
+	}
+	return report
+}
+
+// validHandoffState reports whether the handed off state is one the service could have written for the channel
+func validHandoffState(channel uuid.UUID, state State) bool {
+	switch state.Status {
+	case StatusLaunched, StatusExploded, StatusUnknown, StatusPartial:
+	default:
+		return false
+	}
+	return state.ID == channel && state.Version > 0 && state.LastProcessedMessageNumber >= 0 && state.CurrentSpeed >= 0 &&
+		len(state.Prelaunch) <= maxPrelaunch
+}
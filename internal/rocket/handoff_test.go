@@ -0,0 +1,93 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestService_Handoff(t *testing.T) {
+	ctx := context.Background()
+	newService := func() *ServiceImpl {
+		svc := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+		svc.UseReorderBuffer(10, time.Minute)
+		return svc
+	}
+	id, other := uuid.New(), uuid.New()
+	start := time.Date(2022, 2, 2, 20, 0, 0, 0, time.UTC)
+	message := func(id uuid.UUID, number int64) TelemetryMessage {
+		md := MessageMetadata{Channel: id, MessageNumber: number, MessageTime: start.Add(time.Duration(number) * time.Second), MessageType: MessageTypeSpeedIncreased}
+		m := Message{By: ptr(Speed(100))}
+		if number == 1 {
+			md.MessageType = MessageTypeLaunched
+			m = Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))}
+		}
+		return TelemetryMessage{Metadata: md, Message: m}
+	}
+
+	prev, next := newService(), newService()
+	for _, n := range []int64{1, 2, 4, 5} {
+		if _, err := prev.ProcessMessage(ctx, message(id, n)); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	if _, err := prev.ProcessMessage(ctx, message(other, 1)); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+
+	handoffs := prev.ExportHandoff(ctx, func(channel uuid.UUID) bool { return channel == id })
+	if len(handoffs) != 1 || handoffs[0].State == nil || len(handoffs[0].Held) != 2 || handoffs[0].Held[0].Metadata.MessageNumber != 4 {
+		t.Fatalf("Expected the state and the held messages 4 and 5 of the moved channel, got %+v", handoffs)
+	}
+	if prev.reorder.count(id) != 0 {
+		t.Errorf("Expected the held messages taken out of the reorder buffer")
+	}
+
+	report := next.AcceptHandoff(ctx, handoffs)
+	if report != (HandoffReport{States: 1, Held: 2}) {
+		t.Errorf("Expected the state and two held messages taken over, got %+v", report)
+	}
+	// the new owner continues the sequence: message 3 fills the gap and releases the handed off messages
+	if _, err := next.ProcessMessage(ctx, message(id, 3)); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	state, err := next.GetRocketState(ctx, id)
+	if err != nil || state.LastProcessedMessageNumber != 5 || state.CurrentSpeed != 900 {
+		t.Fatalf("Expected the held messages applied after the gap, got %+v (%v)", state, err)
+	}
+	if res, _ := next.ProcessMessage(ctx, message(id, 2)); res.Outcome != OutcomeDuplicate {
+		t.Errorf("Expected a message applied by the previous owner to be a duplicate, got %s", res.Outcome)
+	}
+
+	// a state the new owner advanced past is kept
+	stale := Handoff{Channel: id, State: handoffs[0].State}
+	if report := next.AcceptHandoff(ctx, []Handoff{stale}); report.Kept != 1 || report.States != 0 {
+		t.Errorf("Expected the newer state kept, got %+v", report)
+	}
+	if state, _ := next.GetRocketState(ctx, id); state.LastProcessedMessageNumber != 5 {
+		t.Errorf("Expected the newer state kept, got %d", state.LastProcessedMessageNumber)
+	}
+
+	// malformed states and held messages are left out
+	forged := *handoffs[0].State
+	forged.Status = "ORBITING"
+	invalid := Handoff{Channel: other, State: &forged, Held: []TelemetryMessage{message(id, 9), {Metadata: MessageMetadata{Channel: other, MessageNumber: 9}}}}
+	if report := next.AcceptHandoff(ctx, []Handoff{invalid}); report != (HandoffReport{Invalid: 3}) {
+		t.Errorf("Expected the malformed state and messages left out, got %+v", report)
+	}
+	if _, err := next.GetRocketState(ctx, other); err == nil {
+		t.Errorf("Expected no state taken from the malformed handoff")
+	}
+
+	// once the channel moved, its messages are not applied by the previous owner
+	prev.UseOwner(func(channel uuid.UUID) bool { return channel != id })
+	if _, err := prev.ProcessMessage(ctx, message(id, 3)); !errors.Is(err, ErrChannelMoved) {
+		t.Errorf("Expected ErrChannelMoved for a message of the moved channel, got %v", err)
+	}
+	if _, err := prev.ProcessMessage(ctx, message(other, 2)); err != nil {
+		t.Errorf("Expected the messages of the owned channels applied, got %v", err)
+	}
+}
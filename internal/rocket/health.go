@@ -0,0 +1,50 @@
+package rocket
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// started - reference of the monotonic times the running updates and writes are stamped with
+var started = time.Now()
+
+// busy - when the update or write in progress started, 0 when none is
+type busy struct {
+	since atomic.Int64
+}
+
+// begin stamps the start of an update or write
+func (b *busy) begin() {
+	b.since.Store(int64(time.Since(started)) + 1)
+}
+
+// end clears the stamp once the update or write is done
+func (b *busy) end() {
+	b.since.Store(0)
+}
+
+// stalled returns how long the update or write in progress has been running, 0 when none is
+func (b *busy) stalled() time.Duration {
+	since := b.since.Load()
+	if since == 0 {
+		return 0
+	}
+	return time.Since(started) - time.Duration(since-1)
+}
+
+// Stalled returns an error when an update of a rocket has been running for longer than the limit, e.g. stuck on
+// a deadlock or on the store, so the ingestion no longer makes progress for its rockets
+func (s *ServiceImpl) Stalled(limit time.Duration) error {
+	var longest time.Duration
+	if s.actors != nil {
+		longest = s.actors.stalled()
+	}
+	for i := range s.busy {
+		longest = max(longest, s.busy[i].stalled())
+	}
+	if longest > limit {
+		return fmt.Errorf("rocket update running for %s", longest.Truncate(time.Millisecond))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRocketService_Stalled(t *testing.T) {
+	for _, actors := range []bool{false, true} {
+		logger := zap.NewNop()
+		service := NewRocketService(NewInMemoryRocketStore(logger), logger)
+		if actors {
+			service.UseActors(8, time.Minute)
+		}
+
+		hung, release, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(done)
+			service.exclusive(uuid.New(), func() {
+				close(hung)
+				<-release
+			})
+		}()
+		<-hung
+		time.Sleep(20 * time.Millisecond)
+		if err := service.Stalled(10 * time.Millisecond); err == nil {
+			t.Errorf("Expected the hung update to stall the service (actors %v)", actors)
+		}
+		if err := service.Stalled(time.Minute); err != nil {
+			t.Errorf("Expected no stall within the limit (actors %v), got %v", actors, err)
+		}
+
+		close(release)
+		<-done
+		// the actor clears its update right after running it
+		time.Sleep(5 * time.Millisecond)
+		if err := service.Stalled(0); err != nil {
+			t.Errorf("Expected no stall once the update is done (actors %v), got %v", actors, err)
+		}
+	}
+}
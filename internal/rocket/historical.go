@@ -0,0 +1,204 @@
+package rocket
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/logging"
+	"slices"
+	"sort"
+	"time"
+)
+
+// BackfillReport - outcome of merging historical messages into the history of a rocket
+type BackfillReport struct {
+	// State - the state recomputed from the merged history
+	State State
+	// Merged - messages added to the history
+	Merged int
+	// Duplicates - messages skipped since their numbers are already in the history or repeated in the batch
+	Duplicates int
+	// Replayed - events recomputed from the earliest merged message on, the merged ones included
+	Replayed int
+}
+
+// backfillChunk - most historical messages merged while holding the rocket, so its live messages are not held up
+// by the replay of a large batch
+const backfillChunk = 500
+
+// BackfillHistory merges historical messages of an existing rocket into its event history by message time, e.g.
+// the data a ground station buffered during an outage, and recomputes the state from the earliest merged message
+// on. Unlike ProcessMessage the messages may be numbered before the last processed one, since they are ordered by
+// time. The events from the earliest merged message on are superseded by the recomputed ones, and the state gets a
+// new version passed to the rewrite listeners, like a rollback. The messages are merged in chunks by time, the
+// rocket is released between them for its live messages; the report sums up the chunks.
+//
+// All messages must be valid and address the rocket, otherwise none is merged and ErrInvalidMessage is returned.
+// It fails with ErrHistoryDisabled without history, ErrRocketNotFound for an untracked rocket and
+// ErrHistoryTruncated when a message predates the oldest kept event, checked with the first chunk, which holds the
+// earliest messages.
+func (s *ServiceImpl) BackfillHistory(ctx context.Context, id uuid.UUID, messages []TelemetryMessage) (BackfillReport, error) {
+	if s.history == nil {
+		return BackfillReport{}, ErrHistoryDisabled
+	}
+	for _, msg := range messages {
+		if msg.Metadata.Channel != id {
+			return BackfillReport{}, fmt.Errorf("%w: message %d addresses channel %s instead of %s", ErrInvalidMessage, msg.Metadata.MessageNumber, msg.Metadata.Channel, id)
+		}
+		if err := s.validate(msg); err != nil {
+			return BackfillReport{}, fmt.Errorf("message %d: %w", msg.Metadata.MessageNumber, err)
+		}
+	}
+	messages = slices.Clone(messages)
+	sortByTime(messages)
+
+	var report BackfillReport
+	for start := 0; start == 0 || start < len(messages); start += backfillChunk {
+		var (
+			chunk BackfillReport
+			err   error
+		)
+		s.exclusive(id, func() {
+			if s.moved(id) {
+				err = fmt.Errorf("%w: %s", ErrChannelMoved, id)
+				return
+			}
+			chunk, err = s.backfillHistory(ctx, id, messages[start:min(start+backfillChunk, len(messages))])
+		})
+		if err != nil {
+			return report, err
+		}
+		report.State = chunk.State
+		report.Merged += chunk.Merged
+		report.Duplicates += chunk.Duplicates
+		report.Replayed += chunk.Replayed
+	}
+	return report, nil
+}
+
+// sortByTime sorts the messages by message time, then number
+func sortByTime(messages []TelemetryMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		a, b := messages[i].Metadata, messages[j].Metadata
+		if !a.MessageTime.Equal(b.MessageTime) {
+			return a.MessageTime.Before(b.MessageTime)
+		}
+		return a.MessageNumber < b.MessageNumber
+	})
+}
+
+// backfillHistory merges the validated messages. Must be called from exclusive.
+func (s *ServiceImpl) backfillHistory(ctx context.Context, id uuid.UUID, messages []TelemetryMessage) (BackfillReport, error) {
+	current, ok := s.store.GetRocketByID(id)
+	if !ok {
+		return BackfillReport{}, ErrRocketNotFound
+	}
+
+	events, complete := s.effectiveHistory(id)
+	// the state the replay starts from and the events replayed on top: the whole history when it starts with
+	// the first version, otherwise the oldest kept event is the base and nothing can be merged before it
+	base := State{ID: id, Status: StatusUnknown}
+	replayable := events
+	var horizon time.Time
+	switch {
+	case complete:
+	case len(events) > 0:
+		base, replayable, horizon = events[0].State, events[1:], events[0].Message.Metadata.MessageTime
+	default:
+		base, replayable, horizon = current, nil, current.LastUpdateTime
+	}
+
+	known := make(map[int64]bool, len(events)+len(messages))
+	for _, event := range events {
+		known[event.Message.Metadata.MessageNumber] = true
+	}
+	report := BackfillReport{State: current}
+	merged := make([]TelemetryMessage, 0, len(replayable)+len(messages))
+	for _, event := range replayable {
+		merged = append(merged, event.Message)
+	}
+	for _, msg := range messages {
+		if known[msg.Metadata.MessageNumber] {
+			report.Duplicates++
+			continue
+		}
+		if msg.Metadata.MessageTime.Before(horizon) {
+			return BackfillReport{}, fmt.Errorf("%w: message %d was sent at %s, before %s", ErrHistoryTruncated, msg.Metadata.MessageNumber, msg.Metadata.MessageTime.Format(time.RFC3339), horizon.Format(time.RFC3339))
+		}
+		known[msg.Metadata.MessageNumber] = true
+		merged = append(merged, msg)
+		report.Merged++
+	}
+	if report.Merged == 0 {
+		return report, nil
+	}
+	sortByTime(merged)
+
+	// the events before the first difference stay as they are
+	k := 0
+	for k < len(replayable) && replayable[k].Message.Metadata.MessageNumber == merged[k].Metadata.MessageNumber {
+		k++
+	}
+	state := base
+	if k > 0 {
+		state = replayable[k-1].State
+	}
+	if k < len(replayable) {
+		s.history.SupersedeEvents(id, replayable[k].State.Version)
+	}
+
+	state = s.replay(state, merged[k:], current.Version)
+	report.Replayed = len(merged) - k
+	s.rewrite(ctx, RewriteBackfill, current, state)
+	s.gaps.backfilled(id, merged[k:])
+	s.dedup.forget(id)
+	report.State = state
+
+	logging.FromContext(ctx, s.logger).Info("History back-filled with historical messages",
+		logging.Event(logging.EventHistoryBackfill),
+		zap.String("rocket_id", id.String()),
+		zap.Int("merged", report.Merged),
+		zap.Int("duplicates", report.Duplicates),
+		zap.Int("replayed", report.Replayed),
+		zap.Int64("version", state.Version),
+	)
+	return report, nil
+}
+
+// effectiveHistory returns the events of the rocket that are not superseded, and whether the history reaches
+// back to the first version of the state, superseded events included
+func (s *ServiceImpl) effectiveHistory(id uuid.UUID) ([]Event, bool) {
+	all := s.history.ListEvents(id)
+	var events []Event
+	for _, event := range all {
+		if !event.Superseded {
+			events = append(events, event)
+		}
+	}
+	return events, len(all) > 0 && all[0].State.Version == 1
+}
+
+// replay applies the messages on top of the state, recording an event for each with the versions following the
+// given one, and returns the resulting state. Must be called from exclusive.
+func (s *ServiceImpl) replay(state State, messages []TelemetryMessage, version int64) State {
+	return s.fold(state, messages, version, s.history.AppendEvent)
+}
+
+// fold applies the messages on top of the state with the versions following the given one, passing the event of
+// each to record unless it is nil, and returns the resulting state
+func (s *ServiceImpl) fold(state State, messages []TelemetryMessage, version int64, record func(Event)) State {
+	for _, msg := range messages {
+		if lateLaunch(state, msg, s.rules) {
+			state = backfill(state, msg, s.rules)
+		} else {
+			state, _ = apply(state, msg, s.rules)
+		}
+		version++
+		state.Version = version
+		if record != nil {
+			record(Event{Message: msg, State: state})
+		}
+	}
+	return state
+}
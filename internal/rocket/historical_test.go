@@ -0,0 +1,89 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRocketService_BackfillHistory(t *testing.T) {
+	ctx := context.Background()
+	launchTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	history := NewInMemoryHistoryStore()
+	service.UseHistory(history)
+
+	id := uuid.New()
+	message := func(number int64, after time.Duration, msgType MessageType, msg Message) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: launchTime.Add(after), MessageType: msgType},
+			Message:  msg,
+		}
+	}
+	// messages 3 to 5 were lost during an outage of the ground station
+	for _, msg := range []TelemetryMessage{
+		message(1, 0, MessageTypeLaunched, Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))}),
+		message(2, 10*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))}),
+		message(6, 60*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(50))}),
+	} {
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	if _, err := service.BackfillHistory(ctx, uuid.New(), nil); !errors.Is(err, ErrRocketNotFound) {
+		t.Errorf("Expected ErrRocketNotFound for an untracked rocket, got %v", err)
+	}
+	other := message(3, 20*time.Minute, MessageTypeSpeedDecreased, Message{By: ptr(Speed(200))})
+	other.Metadata.Channel = uuid.New()
+	if _, err := service.BackfillHistory(ctx, id, []TelemetryMessage{other}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("Expected ErrInvalidMessage for a message of another channel, got %v", err)
+	}
+
+	report, err := service.BackfillHistory(ctx, id, []TelemetryMessage{
+		message(5, 40*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(10))}),
+		message(3, 20*time.Minute, MessageTypeSpeedDecreased, Message{By: ptr(Speed(200))}),
+		message(2, 10*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))}),
+		message(4, 30*time.Minute, MessageTypeMissionChanged, Message{NewMission: ptr(Mission("APOLLO"))}),
+	})
+	if err != nil {
+		t.Fatalf("BackfillHistory failed: %v", err)
+	}
+	if report.Merged != 3 || report.Duplicates != 1 || report.Replayed != 4 {
+		t.Errorf("Expected 3 merged, 1 duplicate and 4 replayed, got %+v", report)
+	}
+	state, _ := service.GetRocketState(ctx, id)
+	if state.CurrentSpeed != 460 || state.Mission != "APOLLO" || state.LastProcessedMessageNumber != 6 || state.Version != 7 {
+		t.Errorf("Expected the state recomputed from the merged history, got %+v", state)
+	}
+
+	var numbers []int64
+	for _, event := range history.ListEvents(id) {
+		if !event.Superseded {
+			numbers = append(numbers, event.Message.Metadata.MessageNumber)
+		}
+	}
+	if len(numbers) != 6 || numbers[2] != 3 || numbers[5] != 6 {
+		t.Errorf("Expected the effective history in time order, got %v", numbers)
+	}
+	if check := service.CheckConsistency(ctx, false); len(check.Drifts) != 0 {
+		t.Errorf("Expected the state to match its folded history, got %+v", check.Drifts)
+	}
+
+	// a large batch is merged in chunks, the later ones on top of the earlier
+	var batch []TelemetryMessage
+	for n := int64(7); n < 7+2*backfillChunk+1; n++ {
+		batch = append(batch, message(n, 61*time.Minute+time.Duration(n)*time.Second, MessageTypeSpeedIncreased, Message{By: ptr(Speed(1))}))
+	}
+	if report, err = service.BackfillHistory(ctx, id, batch); err != nil || report.Merged != len(batch) || report.State.CurrentSpeed != 460+Speed(len(batch)) {
+		t.Errorf("Expected the whole batch merged, got %+v, %v", report, err)
+	}
+
+	// live messages keep being deduplicated against the newest number
+	if result, _ := service.ProcessMessage(ctx, message(5, 70*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(1))})); result.Outcome != OutcomeDuplicate {
+		t.Errorf("Expected a live message numbered before the last one to be a duplicate, got %s", result.Outcome)
+	}
+}
@@ -0,0 +1,111 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxEventsPerRocket - upper bound of the in-memory history kept for a single rocket
+const maxEventsPerRocket = 10000
+
+// Event - applied telemetry message together with the rocket state version it produced
+type Event struct {
+	Message TelemetryMessage `json:"message"`
+	State   State            `json:"state"`
+	// Superseded - the event was rolled back and no longer contributes to the state
+	Superseded bool `json:"superseded"`
+}
+
+// HistoryStore - interface for the per-rocket event history
+type HistoryStore interface {
+	// AppendEvent appends an applied event to the history of its rocket
+	AppendEvent(event Event)
+	// ListEvents lists the history of a rocket in the order the events were applied
+	ListEvents(id uuid.UUID) []Event
+	// SupersedeEvents marks events of a rocket starting from the given state version as superseded
+	SupersedeEvents(id uuid.UUID, fromVersion int64) int
+}
+
+var _ HistoryStore = (*InMemoryHistoryStore)(nil)
+
+// InMemoryHistoryStore keeps the latest events of every rocket in memory
+type InMemoryHistoryStore struct {
+	mu     sync.RWMutex
+	events map[uuid.UUID][]Event
+}
+
+// NewInMemoryHistoryStore creates an empty in-memory history store.
+func NewInMemoryHistoryStore() *InMemoryHistoryStore {
+	return &InMemoryHistoryStore{
+		events: make(map[uuid.UUID][]Event),
+	}
+}
+
+// AppendEvent appends an applied event to the history of its rocket, dropping the oldest events above the limit
+func (s *InMemoryHistoryStore) AppendEvent(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := append(s.events[event.State.ID], event)
+	if len(events) > maxEventsPerRocket {
+		events = events[len(events)-maxEventsPerRocket:]
+	}
+	s.events[event.State.ID] = events
+}
+
+// ListEvents lists the history of a rocket in the order the events were applied
+func (s *InMemoryHistoryStore) ListEvents(id uuid.UUID) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	events := make([]Event, len(s.events[id]))
+	copy(events, s.events[id])
+	return events
+}
+
+// SupersedeEvents marks events of a rocket starting from the given state version as superseded
+// and returns the number of newly superseded events
+func (s *InMemoryHistoryStore) SupersedeEvents(id uuid.UUID, fromVersion int64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	events := s.events[id]
+	for i := range events {
+		if !events[i].Superseded && events[i].State.Version >= fromVersion {
+			events[i].Superseded = true
+			n++
+		}
+	}
+	return n
+}
+
+// takeOlder removes and returns the leading events of every rocket whose messages are older than the cutoff,
+// stopping at the first newer one so the events stay in the order they were applied. The events of rockets
+// pinned by the channel or the mission of their latest state are kept.
+func (s *InMemoryHistoryStore) takeOlder(cutoff time.Time, pins *Pins) map[uuid.UUID][]Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	taken := make(map[uuid.UUID][]Event)
+	for id, events := range s.events {
+		if len(events) > 0 && pins.Pinned(id, events[len(events)-1].State.Mission) {
+			continue
+		}
+		n := 0
+		for n < len(events) && events[n].Message.Metadata.MessageTime.Before(cutoff) {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		taken[id] = slices.Clone(events[:n])
+		s.events[id] = slices.Clone(events[n:])
+	}
+	return taken
+}
+
+// restore puts events taken from the history of a rocket back in front of it
+func (s *InMemoryHistoryStore) restore(id uuid.UUID, events []Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[id] = append(slices.Clone(events), s.events[id]...)
+}
@@ -0,0 +1,53 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestInMemoryHistoryStore_AppendAndList(t *testing.T) {
+	store := NewInMemoryHistoryStore()
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	service.UseHistory(store)
+
+	rocketID := uuid.New()
+	launchTime := time.Now().UTC().Truncate(time.Millisecond)
+	messages := []TelemetryMessage{
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 1, MessageTime: launchTime, MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 2, MessageTime: launchTime.Add(time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(3000))},
+		},
+		// duplicate is not recorded
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 2, MessageTime: launchTime.Add(time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(3000))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := service.ProcessMessage(context.Background(), msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	events := store.ListEvents(rocketID)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].State.CurrentSpeed != 500 || events[1].State.CurrentSpeed != 3500 {
+		t.Errorf("Unexpected speeds in history: %d, %d", events[0].State.CurrentSpeed, events[1].State.CurrentSpeed)
+	}
+	if events[1].Message.Metadata.MessageNumber != 2 {
+		t.Errorf("Expected second event to be message #2, got #%d", events[1].Message.Metadata.MessageNumber)
+	}
+
+	if events := store.ListEvents(uuid.New()); len(events) != 0 {
+		t.Errorf("Expected no events for unknown rocket, got %d", len(events))
+	}
+}
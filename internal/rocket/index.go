@@ -0,0 +1,105 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+)
+
+// Index - secondary index of a store over a field of the rocket state
+type Index string
+
+const (
+	IndexStatus  Index = "status"
+	IndexMission Index = "mission"
+	IndexType    Index = "type"
+)
+
+// Indexes - secondary indexes maintained by every store
+var Indexes = []Index{IndexStatus, IndexMission, IndexType}
+
+// key returns the value of the indexed field of the state
+func (i Index) key(state State) string {
+	switch i {
+	case IndexStatus:
+		return string(state.Status)
+	case IndexMission:
+		return string(state.Mission)
+	case IndexType:
+		return string(state.Type)
+	}
+	return ""
+}
+
+// secondaryIndexes - IDs of rockets by the value of the indexed field, per index. Not safe for concurrent use.
+type secondaryIndexes map[Index]map[string]map[uuid.UUID]struct{}
+
+func newSecondaryIndexes() secondaryIndexes {
+	x := make(secondaryIndexes, len(Indexes))
+	for _, index := range Indexes {
+		x[index] = make(map[string]map[uuid.UUID]struct{})
+	}
+	return x
+}
+
+// update moves the rocket between the entries of the indexes whose fields changed
+func (x secondaryIndexes) update(prev State, existed bool, next State) {
+	for index, entries := range x {
+		key := index.key(next)
+		if existed {
+			old := index.key(prev)
+			if old == key {
+				continue
+			}
+			delete(entries[old], prev.ID)
+			if len(entries[old]) == 0 {
+				delete(entries, old)
+			}
+		}
+		if entries[key] == nil {
+			entries[key] = make(map[uuid.UUID]struct{})
+		}
+		entries[key][next.ID] = struct{}{}
+	}
+}
+
+// remove drops the rocket from the entries of the indexes
+func (x secondaryIndexes) remove(state State) {
+	for index, entries := range x {
+		key := index.key(state)
+		delete(entries[key], state.ID)
+		if len(entries[key]) == 0 {
+			delete(entries, key)
+		}
+	}
+}
+
+// ids returns the IDs of rockets whose indexed field equals the key
+func (x secondaryIndexes) ids(index Index, key string) map[uuid.UUID]struct{} {
+	return x[index][key]
+}
+
+// Filter - conditions on the indexed fields of listed rockets, zero fields match every rocket
+type Filter struct {
+	Status  Status
+	Mission Mission
+	Type    RocketType
+}
+
+// Match reports whether the state satisfies every condition of the filter
+func (f Filter) Match(state State) bool {
+	return (f.Status == "" || state.Status == f.Status) &&
+		(f.Mission == "" || state.Mission == f.Mission) &&
+		(f.Type == "" || state.Type == f.Type)
+}
+
+// index returns an index narrowing the listing down to the filter and its key, false if the filter is empty
+func (f Filter) index() (Index, string, bool) {
+	switch {
+	case f.Mission != "":
+		return IndexMission, string(f.Mission), true
+	case f.Type != "":
+		return IndexType, string(f.Type), true
+	case f.Status != "":
+		return IndexStatus, string(f.Status), true
+	}
+	return "", "", false
+}
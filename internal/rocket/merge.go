@@ -0,0 +1,170 @@
+package rocket
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/logging"
+	"sort"
+)
+
+// MergeEpoch - run of consecutive messages of one channel in the merged history, whose numbers keep increasing
+type MergeEpoch struct {
+	Channel uuid.UUID `json:"channel"`
+	// First, Last - original numbers of the first and the last message of the epoch
+	First int64 `json:"first"`
+	Last  int64 `json:"last"`
+	// Offset - added to the original numbers so the merged ones keep increasing across the epochs, 0 when they
+	// already do
+	Offset   int64 `json:"offset"`
+	Messages int   `json:"messages"`
+}
+
+// MergeReport - outcome of merging the history of a channel into a rocket
+type MergeReport struct {
+	// State - the state recomputed from the merged history
+	State State
+	// Merged - events taken over from the merged channel
+	Merged int
+	// Epochs - the merged history split into the runs of each channel, in message time order
+	Epochs []MergeEpoch
+}
+
+// MergeChannels combines the history of another channel into the rocket, for a telemetry source that was
+// misconfigured mid-flight and sent part of its messages on the wrong channel. The messages of both channels are
+// ordered by message time and split into epochs, a new one starting whenever the channel changes or the numbers
+// restart; an epoch is renumbered by an offset when its numbers would not follow the previous one. The state is
+// recomputed from the combined messages with a new version passed to the rewrite listeners, like a rollback.
+//
+// The other channel is quarantined, so the messages still sent on it are not applied, and its state is removed
+// and its events superseded once merged. Both histories must reach back to the first version of the states,
+// otherwise ErrHistoryTruncated is returned and nothing changes.
+func (s *ServiceImpl) MergeChannels(ctx context.Context, id, other uuid.UUID) (report MergeReport, err error) {
+	if s.history == nil {
+		return MergeReport{}, ErrHistoryDisabled
+	}
+	if id == other {
+		return MergeReport{}, ErrSameChannel
+	}
+
+	// the channels are updated one after the other, never nested, so two opposite merges can't deadlock; the
+	// quarantine keeps the other channel as read meanwhile
+	quarantined := s.quarantine.quarantined(other)
+	if !quarantined {
+		s.quarantine.add(other, "merging into rocket "+id.String(), s.clock.Now())
+	}
+	var taken []Event
+	s.exclusive(other, func() {
+		taken, err = s.completeHistory(other)
+	})
+	if err == nil {
+		s.exclusive(id, func() {
+			report, err = s.mergeChannels(ctx, id, other, taken)
+		})
+	}
+	if err != nil {
+		if !quarantined {
+			s.quarantine.release(other)
+		}
+		return MergeReport{}, err
+	}
+
+	s.exclusive(other, func() {
+		if state, ok := s.store.GetRocketByID(other); ok {
+			s.remove(ctx, RewriteMerge, state)
+		}
+		s.history.SupersedeEvents(other, 0)
+		s.dedup.forget(other)
+	})
+	s.quarantine.add(other, "merged into rocket "+id.String(), s.clock.Now())
+
+	logging.FromContext(ctx, s.logger).Warn("Channel merged into another rocket",
+		logging.Event(logging.EventChannelsMerged),
+		zap.String("rocket_id", id.String()),
+		zap.String("merged_channel", other.String()),
+		zap.Int("merged", report.Merged),
+		zap.Int("epochs", len(report.Epochs)),
+		zap.Int64("version", report.State.Version),
+	)
+	return report, nil
+}
+
+// completeHistory returns the effective events of a tracked rocket whose history reaches back to its first
+// version. Must be called from exclusive.
+func (s *ServiceImpl) completeHistory(id uuid.UUID) ([]Event, error) {
+	if _, ok := s.store.GetRocketByID(id); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRocketNotFound, id)
+	}
+	events, complete := s.effectiveHistory(id)
+	if !complete {
+		return nil, fmt.Errorf("%w: the history of rocket %s does not start with its first state", ErrHistoryTruncated, id)
+	}
+	return events, nil
+}
+
+// mergeChannels replays the events of the rocket combined with the ones taken from the other channel.
+// Must be called from exclusive.
+func (s *ServiceImpl) mergeChannels(ctx context.Context, id, other uuid.UUID, taken []Event) (MergeReport, error) {
+	current, ok := s.store.GetRocketByID(id)
+	if !ok {
+		return MergeReport{}, fmt.Errorf("%w: %s", ErrRocketNotFound, id)
+	}
+	events, err := s.completeHistory(id)
+	if err != nil {
+		return MergeReport{}, err
+	}
+
+	type entry struct {
+		msg   TelemetryMessage
+		other bool
+	}
+	entries := make([]entry, 0, len(events)+len(taken))
+	for _, event := range events {
+		entries = append(entries, entry{msg: event.Message})
+	}
+	for _, event := range taken {
+		entries = append(entries, entry{msg: event.Message, other: true})
+	}
+	// messages sent at the same time keep the rocket's first
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.msg.Metadata.MessageTime.Equal(b.msg.Metadata.MessageTime) {
+			return a.msg.Metadata.MessageTime.Before(b.msg.Metadata.MessageTime)
+		}
+		if a.other != b.other {
+			return !a.other
+		}
+		return a.msg.Metadata.MessageNumber < b.msg.Metadata.MessageNumber
+	})
+
+	report := MergeReport{Merged: len(taken)}
+	messages := make([]TelemetryMessage, 0, len(entries))
+	var last int64
+	for _, e := range entries {
+		channel := id
+		if e.other {
+			channel = other
+		}
+		n := e.msg.Metadata.MessageNumber
+		if len(report.Epochs) == 0 || report.Epochs[len(report.Epochs)-1].Channel != channel || n <= report.Epochs[len(report.Epochs)-1].Last {
+			report.Epochs = append(report.Epochs, MergeEpoch{Channel: channel, First: n, Offset: max(0, last+1-n)})
+		}
+		epoch := &report.Epochs[len(report.Epochs)-1]
+		epoch.Last = n
+		epoch.Messages++
+
+		msg := e.msg
+		msg.Metadata.Channel = id
+		msg.Metadata.MessageNumber = n + epoch.Offset
+		last = msg.Metadata.MessageNumber
+		messages = append(messages, msg)
+	}
+
+	s.history.SupersedeEvents(id, 0)
+	state := s.replay(State{ID: id, Status: StatusUnknown}, messages, current.Version)
+	s.rewrite(ctx, RewriteMerge, current, state)
+	s.dedup.forget(id)
+	report.State = state
+	return report, nil
+}
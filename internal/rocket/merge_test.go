@@ -0,0 +1,85 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRocketService_MergeChannels(t *testing.T) {
+	ctx := context.Background()
+	launchTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := NewInMemoryRocketStore(zap.NewNop())
+	service := NewRocketService(store, zap.NewNop())
+	service.UseHistory(NewInMemoryHistoryStore())
+
+	id, other := uuid.New(), uuid.New()
+	message := func(channel uuid.UUID, number int64, after time.Duration, msgType MessageType, msg Message) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: channel, MessageNumber: number, MessageTime: launchTime.Add(after), MessageType: msgType},
+			Message:  msg,
+		}
+	}
+	// the source switched to the other channel after 20 minutes, restarting its numbers
+	for _, msg := range []TelemetryMessage{
+		message(id, 1, 0, MessageTypeLaunched, Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))}),
+		message(id, 2, 10*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))}),
+		message(other, 1, 20*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(300))}),
+		message(other, 2, 30*time.Minute, MessageTypeSpeedDecreased, Message{By: ptr(Speed(100))}),
+	} {
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	if _, err := service.MergeChannels(ctx, id, id); !errors.Is(err, ErrSameChannel) {
+		t.Errorf("Expected ErrSameChannel, got %v", err)
+	}
+	if _, err := service.MergeChannels(ctx, id, uuid.New()); !errors.Is(err, ErrRocketNotFound) {
+		t.Errorf("Expected ErrRocketNotFound for an untracked channel, got %v", err)
+	}
+	// a rocket restored without its history, e.g. after a restart
+	restored := uuid.New()
+	store.SaveRocket(State{ID: restored, Type: "Soyuz", Status: StatusLaunched, LastProcessedMessageNumber: 7, Version: 7})
+	if _, err := service.MergeChannels(ctx, id, restored); !errors.Is(err, ErrHistoryTruncated) {
+		t.Errorf("Expected ErrHistoryTruncated, got %v", err)
+	}
+	if entries := service.ListQuarantinedChannels(ctx); len(entries) != 0 {
+		t.Errorf("Expected a failed merge to release the quarantine, got %+v", entries)
+	}
+
+	report, err := service.MergeChannels(ctx, id, other)
+	if err != nil {
+		t.Fatalf("MergeChannels failed: %v", err)
+	}
+	expected := []MergeEpoch{
+		{Channel: id, First: 1, Last: 2, Messages: 2},
+		{Channel: other, First: 1, Last: 2, Offset: 2, Messages: 2},
+	}
+	if report.Merged != 2 || !reflect.DeepEqual(report.Epochs, expected) {
+		t.Errorf("Expected 2 merged events in epochs %+v, got %+v", expected, report)
+	}
+	state, _ := service.GetRocketState(ctx, id)
+	if state.CurrentSpeed != 800 || state.LastProcessedMessageNumber != 4 || state.Version != 6 {
+		t.Errorf("Expected the state recomputed from both channels, got %+v", state)
+	}
+	if report := service.CheckConsistency(ctx, false); len(report.Drifts) != 0 {
+		t.Errorf("Expected the merged state to match its history, got %+v", report.Drifts)
+	}
+
+	if _, err := service.GetRocketState(ctx, other); !errors.Is(err, ErrRocketNotFound) {
+		t.Errorf("Expected the merged channel to be removed, got %v", err)
+	}
+	result, _ := service.ProcessMessage(ctx, message(other, 3, 40*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(10))}))
+	if result.Outcome != OutcomeIgnored {
+		t.Errorf("Expected messages of the merged channel to be ignored, got %s", result.Outcome)
+	}
+	result, _ = service.ProcessMessage(ctx, message(id, 5, 40*time.Minute, MessageTypeSpeedIncreased, Message{By: ptr(Speed(10))}))
+	if result.Outcome != OutcomeApplied {
+		t.Errorf("Expected messages after the merged numbers to be applied, got %s", result.Outcome)
+	}
+}
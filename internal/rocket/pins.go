@@ -0,0 +1,231 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"rockets/internal/jsonfile"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Pin - channel or mission exempt from the eviction of idle rockets: its history stays in the hot tier and the
+// gaps in its messages wait for the missing ones without a time limit
+type Pin struct {
+	// Channel, Mission - the pinned channel, or the mission whose rockets are pinned
+	Channel *uuid.UUID `json:"channel,omitempty"`
+	Mission Mission    `json:"mission,omitempty"`
+	Reason  string     `json:"reason"`
+	// Configured - the pin comes from the configuration and is restored on restart
+	Configured bool      `json:"configured"`
+	Since      time.Time `json:"since"`
+}
+
+// Pins - registry of pinned channels and missions, e.g. of long-duration missions going silent for weeks.
+// A nil registry pins nothing.
+type Pins struct {
+	mu       sync.RWMutex
+	channels map[uuid.UUID]Pin
+	missions map[Mission]Pin
+	// file - JSON file the pins added at runtime are saved to on every change, empty keeps them in memory only
+	file string
+}
+
+// NewPins creates a registry with the configured channels and missions pinned.
+func NewPins(channels []uuid.UUID, missions []Mission, now time.Time) *Pins {
+	p := &Pins{channels: make(map[uuid.UUID]Pin), missions: make(map[Mission]Pin)}
+	for _, id := range channels {
+		p.channels[id] = Pin{Channel: &id, Reason: "configured", Configured: true, Since: now.UTC()}
+	}
+	for _, mission := range missions {
+		p.missions[mission] = Pin{Mission: mission, Reason: "configured", Configured: true, Since: now.UTC()}
+	}
+	return p
+}
+
+// OpenPins creates a registry with the configured channels and missions pinned, saving the pins added at runtime
+// to the file and loading the ones saved before if it exists.
+func OpenPins(file string, channels []uuid.UUID, missions []Mission, now time.Time) (*Pins, error) {
+	p := NewPins(channels, missions, now)
+	p.file = file
+	if err := p.Refresh(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Refresh loads the pins added at runtime from the file again, picking up the changes saved by the leader while
+// in standby. The configured pins are kept. A registry keeping them in memory only is left as it is.
+func (p *Pins) Refresh() error {
+	if p == nil || p.file == "" {
+		return nil
+	}
+	var saved []Pin
+	if _, err := jsonfile.Load(p.file, "pins", &saved); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	channels, missions := make(map[uuid.UUID]Pin), make(map[Mission]Pin)
+	for id, pin := range p.channels {
+		if pin.Configured {
+			channels[id] = pin
+		}
+	}
+	for mission, pin := range p.missions {
+		if pin.Configured {
+			missions[mission] = pin
+		}
+	}
+	for _, pin := range saved {
+		pin.Configured = false
+		switch {
+		case pin.Channel != nil:
+			if _, ok := channels[*pin.Channel]; !ok {
+				channels[*pin.Channel] = pin
+			}
+		case pin.Mission != "":
+			if _, ok := missions[pin.Mission]; !ok {
+				missions[pin.Mission] = pin
+			}
+		}
+	}
+	p.channels, p.missions = channels, missions
+	return nil
+}
+
+// PinChannel pins the channel, replacing the reason of an existing pin
+func (p *Pins) PinChannel(id uuid.UUID, reason string, now time.Time) (Pin, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prev, ok := p.channels[id]
+	pin := prev
+	if !ok {
+		pin = Pin{Channel: &id, Since: now.UTC()}
+	}
+	pin.Reason = reason
+	p.channels[id] = pin
+	if err := p.saveLocked(); err != nil {
+		if ok {
+			p.channels[id] = prev
+		} else {
+			delete(p.channels, id)
+		}
+		return Pin{}, err
+	}
+	return pin, nil
+}
+
+// PinMission pins the rockets of the mission, replacing the reason of an existing pin
+func (p *Pins) PinMission(mission Mission, reason string, now time.Time) (Pin, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prev, ok := p.missions[mission]
+	pin := prev
+	if !ok {
+		pin = Pin{Mission: mission, Since: now.UTC()}
+	}
+	pin.Reason = reason
+	p.missions[mission] = pin
+	if err := p.saveLocked(); err != nil {
+		if ok {
+			p.missions[mission] = prev
+		} else {
+			delete(p.missions, mission)
+		}
+		return Pin{}, err
+	}
+	return pin, nil
+}
+
+// UnpinChannel removes the pin of the channel and reports whether it was pinned
+func (p *Pins) UnpinChannel(id uuid.UUID) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prev, ok := p.channels[id]
+	if !ok {
+		return false, nil
+	}
+	delete(p.channels, id)
+	if err := p.saveLocked(); err != nil {
+		p.channels[id] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+// UnpinMission removes the pin of the mission and reports whether it was pinned
+func (p *Pins) UnpinMission(mission Mission) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prev, ok := p.missions[mission]
+	if !ok {
+		return false, nil
+	}
+	delete(p.missions, mission)
+	if err := p.saveLocked(); err != nil {
+		p.missions[mission] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+// Pinned reports whether the channel, or the mission of its rocket, is pinned
+func (p *Pins) Pinned(id uuid.UUID, mission Mission) bool {
+	if p == nil {
+		return false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if _, ok := p.channels[id]; ok {
+		return true
+	}
+	_, ok := p.missions[mission]
+	return ok && mission != ""
+}
+
+// List returns the pins, the oldest first
+func (p *Pins) List() []Pin {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.listLocked(func(Pin) bool { return true })
+}
+
+// saveLocked writes the pins added at runtime to the file, the configured ones come from the configuration
+func (p *Pins) saveLocked() error {
+	if p.file == "" {
+		return nil
+	}
+	return jsonfile.Save(p.file, "pins", p.listLocked(func(pin Pin) bool { return !pin.Configured }))
+}
+
+// listLocked returns the pins matching keep, the oldest first
+func (p *Pins) listLocked(keep func(Pin) bool) []Pin {
+	pins := make([]Pin, 0, len(p.channels)+len(p.missions))
+	for _, pin := range p.channels {
+		if keep(pin) {
+			pins = append(pins, pin)
+		}
+	}
+	for _, pin := range p.missions {
+		if keep(pin) {
+			pins = append(pins, pin)
+		}
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		if !pins[i].Since.Equal(pins[j].Since) {
+			return pins[i].Since.Before(pins[j].Since)
+		}
+		return pins[i].key() < pins[j].key()
+	})
+	return pins
+}
+
+func (p Pin) key() string {
+	if p.Channel != nil {
+		return "channel/" + p.Channel.String()
+	}
+	return "mission/" + string(p.Mission)
+}
@@ -0,0 +1,46 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPins_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pins.json")
+	configured, runtime := uuid.New(), uuid.New()
+	now := time.Now()
+	p, err := OpenPins(file, []uuid.UUID{configured}, nil, now)
+	if err != nil {
+		t.Fatalf("OpenPins failed: %v", err)
+	}
+	if _, err := p.PinChannel(runtime, "probe coasting", now); err != nil {
+		t.Fatalf("PinChannel failed: %v", err)
+	}
+	if _, err := p.PinMission("VOYAGER", "deep space", now); err != nil {
+		t.Fatalf("PinMission failed: %v", err)
+	}
+
+	reopened, err := OpenPins(file, nil, nil, now)
+	if err != nil {
+		t.Fatalf("OpenPins failed: %v", err)
+	}
+	if !reopened.Pinned(runtime, "") || !reopened.Pinned(uuid.New(), "VOYAGER") {
+		t.Errorf("Expected the runtime pins to survive a restart, got %+v", reopened.List())
+	}
+	if reopened.Pinned(configured, "") {
+		t.Errorf("Expected the configured pin not saved to the file")
+	}
+
+	if ok, err := reopened.UnpinMission("VOYAGER"); !ok || err != nil {
+		t.Fatalf("Expected the mission unpinned, got %v, %v", ok, err)
+	}
+	// a standby reading the file of the leader keeps its configured pins
+	if err := p.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if p.Pinned(uuid.New(), "VOYAGER") || !p.Pinned(runtime, "") || !p.Pinned(configured, "") {
+		t.Errorf("Expected the pins of the file and the configured ones after a refresh, got %+v", p.List())
+	}
+}
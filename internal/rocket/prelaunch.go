@@ -0,0 +1,77 @@
+package rocket
+
+import (
+	"slices"
+	"sort"
+)
+
+// rules - settings changing how messages are applied, shared by processing messages and folding the history
+// so both arrive at the same state
+type rules struct {
+	underflow UnderflowPolicy
+	// provisional - messages before the launch build a PARTIAL state, back-filled when the launch arrives late
+	provisional bool
+}
+
+// maxPrelaunch - most messages remembered for the back-fill of a provisional state, the state and its history
+// events carry them all
+const maxPrelaunch = 100
+
+// provisional flags the state of a rocket not launched yet as PARTIAL and remembers the message applied to it
+// under the provisional rules, up to maxPrelaunch messages. The remembered messages are forgotten once the rocket
+// is launched.
+func provisional(state State, msg TelemetryMessage, r rules) State {
+	if !r.provisional {
+		return state
+	}
+	if state.Type != "" {
+		state.Prelaunch = nil
+		return state
+	}
+	if state.Status == StatusUnknown {
+		state.Status = StatusPartial
+	}
+	prelaunch := remember(state.Prelaunch, msg)
+	if len(prelaunch) > maxPrelaunch {
+		return state
+	}
+	state.Prelaunch = prelaunch
+	return state
+}
+
+// remember returns the pre-launch messages with the message appended, replacing a message with its number, e.g.
+// superseded by a rollback. The slice is shared with the previous versions of the state, e.g. in the history, so
+// it is copied.
+func remember(prelaunch []TelemetryMessage, msg TelemetryMessage) []TelemetryMessage {
+	prelaunch = slices.DeleteFunc(slices.Clone(prelaunch), func(m TelemetryMessage) bool {
+		return m.Metadata.MessageNumber == msg.Metadata.MessageNumber
+	})
+	return append(prelaunch, msg)
+}
+
+// lateLaunch reports whether the message is a launch arriving after later messages of a rocket that
+// has not been launched, which the provisional rules back-fill instead of dropping it as old
+func lateLaunch(state State, msg TelemetryMessage, r rules) bool {
+	return r.provisional &&
+		msg.Metadata.MessageType == MessageTypeLaunched &&
+		state.Type == "" &&
+		msg.Metadata.MessageNumber < state.LastProcessedMessageNumber
+}
+
+// backfill rebuilds the state of a rocket from its late launch message: the launch is applied first and the
+// remembered messages numbered after it on top, in order, as if they had arrived in sequence. Messages
+// numbered up to the last processed one are replayed, the ones superseded by a rollback are not.
+func backfill(current State, launch TelemetryMessage, r rules) State {
+	messages := slices.Clone(current.Prelaunch)
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Metadata.MessageNumber < messages[j].Metadata.MessageNumber
+	})
+	state, _ := apply(State{ID: current.ID, Status: StatusUnknown}, launch, r)
+	for _, msg := range messages {
+		n := msg.Metadata.MessageNumber
+		if n > launch.Metadata.MessageNumber && n <= current.LastProcessedMessageNumber {
+			state, _ = apply(state, msg, r)
+		}
+	}
+	return state
+}
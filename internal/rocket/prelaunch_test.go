@@ -0,0 +1,171 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// prelaunchMessages returns the in-order messages of a rocket and the state they result in
+func prelaunchMessages() ([]TelemetryMessage, State) {
+	id := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	messages := []TelemetryMessage{
+		{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: start, MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 2, MessageTime: start.Add(time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(3000))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 3, MessageTime: start.Add(2 * time.Second), MessageType: MessageTypeSpeedDecreased},
+			Message:  Message{By: ptr(Speed(1000))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 4, MessageTime: start.Add(3 * time.Second), MessageType: MessageTypeMissionChanged},
+			Message:  Message{NewMission: ptr(Mission("GEMINI"))},
+		},
+	}
+	return messages, State{ID: id, Type: "Falcon-9", CurrentSpeed: 2500, Mission: "GEMINI", Status: StatusLaunched, LastUpdateTime: start.Add(3 * time.Second), LastProcessedMessageNumber: 4}
+}
+
+func TestRocketService_ProvisionalState(t *testing.T) {
+	messages, want := prelaunchMessages()
+	id := want.ID
+
+	tests := []struct {
+		name    string
+		reorder bool
+	}{
+		{name: "without reorder buffer"},
+		{name: "with the gap skipped by the reorder buffer", reorder: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+			service.UseHistory(NewInMemoryHistoryStore())
+			service.UseProvisionalState()
+			if tt.reorder {
+				service.UseReorderBuffer(10, 0)
+			}
+			ctx := context.Background()
+			for _, msg := range messages[1:] {
+				if _, err := service.ProcessMessage(ctx, msg); err != nil {
+					t.Fatalf("ProcessMessage failed: %v", err)
+				}
+			}
+			service.FlushHeldMessages(ctx)
+
+			partial, _ := service.GetRocketState(ctx, id)
+			// the decrease by 1000 underflows the 3000 known so far and is clamped
+			if partial.Status != StatusPartial || partial.Mission != "GEMINI" || partial.CurrentSpeed != 2000 || partial.Type != "" || len(partial.Prelaunch) != 3 {
+				t.Errorf("Expected a partial state, got %+v", partial)
+			}
+
+			if _, err := service.ProcessMessage(ctx, messages[0]); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			got, _ := service.GetRocketState(ctx, id)
+			want.Version = partial.Version + 1
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected the back-filled state %+v, got %+v", want, got)
+			}
+			if report := service.CheckConsistency(ctx, false); len(report.Violations) > 0 || len(report.Drifts) > 0 {
+				t.Errorf("Expected the history to fold to the back-filled state, got %+v", report)
+			}
+
+			// the launch is applied once
+			if _, err := service.ProcessMessage(ctx, messages[0]); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			if again, _ := service.GetRocketState(ctx, id); !reflect.DeepEqual(again, got) {
+				t.Errorf("Expected a resent launch to be ignored, got %+v", again)
+			}
+		})
+	}
+}
+
+func TestRocketService_ProvisionalStatePersisted(t *testing.T) {
+	messages, want := prelaunchMessages()
+	path := filepath.Join(t.TempDir(), "rockets.jsonl")
+	ctx := context.Background()
+
+	process := func(messages []TelemetryMessage) State {
+		store, err := OpenFileRocketStore(path, nil, zap.NewNop())
+		if err != nil {
+			t.Fatalf("OpenFileRocketStore failed: %v", err)
+		}
+		defer store.Close()
+		service := NewRocketService(store, zap.NewNop())
+		service.UseProvisionalState()
+		for _, msg := range messages {
+			if _, err := service.ProcessMessage(ctx, msg); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+		}
+		state, _ := service.GetRocketState(ctx, want.ID)
+		return state
+	}
+
+	partial := process(messages[1:])
+	// the launch arrives after a restart
+	got := process(messages[:1])
+	want.Version = partial.Version + 1
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the back-filled state %+v, got %+v", want, got)
+	}
+}
+
+func TestRocketService_WithoutProvisionalState(t *testing.T) {
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	ctx := context.Background()
+	id := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	messages := []TelemetryMessage{
+		{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 2, MessageTime: start.Add(time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(3000))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: start, MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	if state, _ := service.GetRocketState(ctx, id); state.Status != StatusUnknown || state.CurrentSpeed != 3000 {
+		t.Errorf("Expected the late launch to be dropped, got %+v", state)
+	}
+}
+
+func TestRocketService_ProvisionalStateCapped(t *testing.T) {
+	messages, _ := prelaunchMessages()
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	service.UseProvisionalState()
+	ctx := context.Background()
+
+	increase := messages[1]
+	for n := int64(2); n <= maxPrelaunch+2; n++ {
+		increase.Metadata.MessageNumber = n
+		result, err := service.ProcessMessage(ctx, increase)
+		if err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+		if capped := len(result.Warnings) == 2; capped != (n == maxPrelaunch+2) {
+			t.Errorf("Expected a warning only for the message beyond the cap, got %v for message %d", result.Warnings, n)
+		}
+	}
+	state, _ := service.GetRocketState(ctx, increase.Metadata.Channel)
+	if len(state.Prelaunch) != maxPrelaunch || state.CurrentSpeed != 3000*(maxPrelaunch+1) {
+		t.Errorf("Expected %d messages remembered and all of them applied, got %d and speed %d", maxPrelaunch, len(state.Prelaunch), state.CurrentSpeed)
+	}
+}
@@ -0,0 +1,131 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// ProcessingStats - what processing did with the messages of a channel since the service started, for producers
+// to diagnose their senders. The statistics of a channel without messages for processingStatsIdle are forgotten.
+type ProcessingStats struct {
+	// Received - messages of the channel, whatever processing did with them
+	Received   int64
+	Duplicates int64
+	// OutOfOrder - messages that arrived ahead of a missing predecessor
+	OutOfOrder int64
+	// LargestGap - most messages found missing before an arriving one
+	LargestGap int64
+	Rejected   int64
+	// Ignored - messages received while the channel was quarantined
+	Ignored       int64
+	LastRejection *Rejection
+}
+
+// Rejection - invalid message of a channel
+type Rejection struct {
+	MessageNumber int64
+	Reason        string
+	Time          time.Time
+}
+
+// Statistics of the channels without messages for processingStatsIdle are dropped, looked for every
+// processingStatsSweep
+const (
+	processingStatsIdle  = time.Hour
+	processingStatsSweep = time.Minute
+)
+
+// channelStats - processing statistics of a channel and the time of its latest message
+type channelStats struct {
+	stats ProcessingStats
+	seen  time.Time
+}
+
+// processingStats - processing statistics per channel
+type processingStats struct {
+	mu       sync.Mutex
+	channels map[uuid.UUID]*channelStats
+	// swept - time of the last eviction of the idle channels
+	swept time.Time
+}
+
+func newProcessingStats() *processingStats {
+	return &processingStats{channels: make(map[uuid.UUID]*channelStats)}
+}
+
+// update changes the statistics of the channel under the lock, dropping the idle channels on the way
+func (p *processingStats) update(id uuid.UUID, now time.Time, fn func(stats *ProcessingStats)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now.Sub(p.swept) >= processingStatsSweep {
+		for channel, c := range p.channels {
+			if now.Sub(c.seen) >= processingStatsIdle {
+				delete(p.channels, channel)
+			}
+		}
+		p.swept = now
+	}
+	c, ok := p.channels[id]
+	if !ok {
+		c = &channelStats{}
+		p.channels[id] = c
+	}
+	c.seen = now
+	fn(&c.stats)
+}
+
+// record counts the message by its outcome
+func (p *processingStats) record(msg TelemetryMessage, outcome Outcome, reason string, now time.Time) {
+	p.update(msg.Metadata.Channel, now, func(stats *ProcessingStats) {
+		stats.Received++
+		switch outcome {
+		case OutcomeDuplicate:
+			stats.Duplicates++
+		case OutcomeIgnored:
+			stats.Ignored++
+		case OutcomeRejected:
+			stats.Rejected++
+			stats.LastRejection = &Rejection{MessageNumber: msg.Metadata.MessageNumber, Reason: reason, Time: now.UTC()}
+		}
+	})
+}
+
+// arrived counts the message as out of order when it is ahead of the message following the last processed one
+func (p *processingStats) arrived(id uuid.UUID, number, last int64, now time.Time) {
+	if gap := number - last - 1; gap > 0 {
+		p.update(id, now, func(stats *ProcessingStats) {
+			stats.OutOfOrder++
+			stats.LargestGap = max(stats.LargestGap, gap)
+		})
+	}
+}
+
+func (p *processingStats) get(id uuid.UUID) (ProcessingStats, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.channels[id]
+	if !ok {
+		return ProcessingStats{}, false
+	}
+	out := c.stats
+	if c.stats.LastRejection != nil {
+		rejection := *c.stats.LastRejection
+		out.LastRejection = &rejection
+	}
+	return out, true
+}
+
+// GetProcessingStats returns the processing statistics of the channel since the service started, zero for a
+// tracked rocket without messages since then. It returns ErrRocketNotFound for a channel that is neither tracked
+// nor sent messages.
+func (s *ServiceImpl) GetProcessingStats(_ context.Context, id uuid.UUID) (ProcessingStats, error) {
+	stats, ok := s.stats.get(id)
+	if !ok {
+		if _, exists := s.store.GetRocketByID(id); !exists {
+			return ProcessingStats{}, ErrRocketNotFound
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,67 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRocketService_GetProcessingStats(t *testing.T) {
+	ctx := context.Background()
+	launchTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+
+	id := uuid.New()
+	message := func(number int64, msgType MessageType, msg Message) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: launchTime.Add(time.Duration(number) * time.Minute), MessageType: msgType},
+			Message:  msg,
+		}
+	}
+	launch := message(1, MessageTypeLaunched, Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))})
+	for _, msg := range []TelemetryMessage{
+		launch,
+		launch,
+		message(4, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))}),
+		message(5, MessageTypeSpeedIncreased, Message{}),
+		message(3, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))}),
+	} {
+		_, _ = service.ProcessMessage(ctx, msg)
+	}
+	service.QuarantineChannel(ctx, id, "noisy")
+	_, _ = service.ProcessMessage(ctx, message(6, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))}))
+
+	stats, err := service.GetProcessingStats(ctx, id)
+	if err != nil {
+		t.Fatalf("GetProcessingStats failed: %v", err)
+	}
+	if stats.Received != 6 || stats.Duplicates != 2 || stats.OutOfOrder != 1 || stats.LargestGap != 2 || stats.Rejected != 1 || stats.Ignored != 1 {
+		t.Errorf("Expected 6 received, 2 duplicates, 1 out of order with a gap of 2, 1 rejected and 1 ignored, got %+v", stats)
+	}
+	if stats.LastRejection == nil || stats.LastRejection.MessageNumber != 5 || stats.LastRejection.Reason == "" {
+		t.Errorf("Expected message 5 to be the last rejection, got %+v", stats.LastRejection)
+	}
+
+	if _, err := service.GetProcessingStats(ctx, uuid.New()); !errors.Is(err, ErrRocketNotFound) {
+		t.Errorf("Expected ErrRocketNotFound for an unknown channel, got %v", err)
+	}
+}
+
+func TestProcessingStats_DropsIdleChannels(t *testing.T) {
+	stats := newProcessingStats()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	idle, busy := uuid.New(), uuid.New()
+	stats.record(TelemetryMessage{Metadata: MessageMetadata{Channel: idle}}, OutcomeApplied, "", now)
+	stats.record(TelemetryMessage{Metadata: MessageMetadata{Channel: busy}}, OutcomeApplied, "", now.Add(30*time.Minute))
+
+	stats.record(TelemetryMessage{Metadata: MessageMetadata{Channel: busy}}, OutcomeApplied, "", now.Add(processingStatsIdle))
+	if _, ok := stats.get(idle); ok {
+		t.Error("Expected the statistics of the idle channel dropped")
+	}
+	if got, ok := stats.get(busy); !ok || got.Received != 2 {
+		t.Errorf("Expected 2 messages received by the busy channel, got %+v", got)
+	}
+}
@@ -0,0 +1,112 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QuarantineEntry - channel whose messages are accepted but not applied to the rocket state
+type QuarantineEntry struct {
+	Channel uuid.UUID `json:"channel"`
+	Reason  string    `json:"reason"`
+	// Automatic - the channel was quarantined after repeated validation failures
+	Automatic bool      `json:"automatic"`
+	Since     time.Time `json:"since"`
+	// IgnoredMessages - number of messages received while quarantined
+	IgnoredMessages int64 `json:"ignoredMessages"`
+}
+
+// quarantine - registry of quarantined channels and their consecutive validation failures
+type quarantine struct {
+	mu       sync.Mutex
+	entries  map[uuid.UUID]*QuarantineEntry
+	failures map[uuid.UUID]int
+	// threshold - consecutive validation failures before automatic quarantine, 0 disables it
+	threshold int
+}
+
+func newQuarantine() *quarantine {
+	return &quarantine{
+		entries:  make(map[uuid.UUID]*QuarantineEntry),
+		failures: make(map[uuid.UUID]int),
+	}
+}
+
+// ignore reports whether the channel is quarantined, counting the ignored message
+func (q *quarantine) ignore(id uuid.UUID) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.entries[id]
+	if ok {
+		entry.IgnoredMessages++
+	}
+	return ok
+}
+
+// quarantined reports whether the channel is quarantined, without counting a message
+func (q *quarantine) quarantined(id uuid.UUID) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.entries[id]
+	return ok
+}
+
+// fail records a validation failure and reports whether it put the channel into quarantine
+func (q *quarantine) fail(id uuid.UUID, reason string, now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.threshold <= 0 {
+		return false
+	}
+	q.failures[id]++
+	if q.failures[id] < q.threshold {
+		return false
+	}
+	delete(q.failures, id)
+	q.entries[id] = &QuarantineEntry{Channel: id, Reason: reason, Automatic: true, Since: now.UTC()}
+	return true
+}
+
+// succeed resets the consecutive validation failures of the channel
+func (q *quarantine) succeed(id uuid.UUID) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.failures, id)
+}
+
+func (q *quarantine) add(id uuid.UUID, reason string, now time.Time) QuarantineEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.entries[id]
+	if !ok {
+		entry = &QuarantineEntry{Channel: id, Since: now.UTC()}
+		q.entries[id] = entry
+	}
+	entry.Reason = reason
+	entry.Automatic = false
+	return *entry
+}
+
+func (q *quarantine) release(id uuid.UUID) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, ok := q.entries[id]
+	delete(q.entries, id)
+	delete(q.failures, id)
+	return ok
+}
+
+func (q *quarantine) list() []QuarantineEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entries := make([]QuarantineEntry, 0, len(q.entries))
+	for _, entry := range q.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Since.Before(entries[j].Since)
+	})
+	return entries
+}
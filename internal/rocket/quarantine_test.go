@@ -0,0 +1,74 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRocketService_Quarantine(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	service := NewRocketService(store, logger)
+	service.AutoQuarantine(2)
+	ctx := context.Background()
+
+	rocketID := uuid.New()
+	launch := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 1, MessageTime: time.Now(), MessageType: MessageTypeLaunched},
+		Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+	}
+	invalid := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 2, MessageTime: time.Now(), MessageType: MessageTypeSpeedIncreased},
+	}
+	speedUp := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 3, MessageTime: time.Now(), MessageType: MessageTypeSpeedIncreased},
+		Message:  Message{By: ptr(Speed(100))},
+	}
+
+	if _, err := service.ProcessMessage(ctx, launch); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := service.ProcessMessage(ctx, invalid); !errors.Is(err, ErrInvalidMessage) {
+			t.Fatalf("Expected ErrInvalidMessage, got %v", err)
+		}
+	}
+
+	entries := service.ListQuarantinedChannels(ctx)
+	if len(entries) != 1 || entries[0].Channel != rocketID || !entries[0].Automatic {
+		t.Fatalf("Expected channel %s to be quarantined automatically, got %+v", rocketID, entries)
+	}
+
+	// accepted, but not applied
+	if _, err := service.ProcessMessage(ctx, speedUp); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	if state, _ := store.GetRocketByID(rocketID); state.CurrentSpeed != 500 {
+		t.Errorf("Expected quarantined message not to be applied, speed is %d", state.CurrentSpeed)
+	}
+	if entries := service.ListQuarantinedChannels(ctx); entries[0].IgnoredMessages != 1 {
+		t.Errorf("Expected 1 ignored message, got %d", entries[0].IgnoredMessages)
+	}
+
+	if !service.ReleaseChannel(ctx, rocketID) {
+		t.Fatalf("Expected channel %s to be released", rocketID)
+	}
+	if service.ReleaseChannel(ctx, rocketID) {
+		t.Errorf("Expected second release to report the channel as not quarantined")
+	}
+	if _, err := service.ProcessMessage(ctx, speedUp); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	if state, _ := store.GetRocketByID(rocketID); state.CurrentSpeed != 600 {
+		t.Errorf("Expected message to be applied after release, speed is %d", state.CurrentSpeed)
+	}
+
+	manual := service.QuarantineChannel(ctx, rocketID, "producer firmware bug")
+	if manual.Automatic || manual.Reason != "producer firmware bug" {
+		t.Errorf("Unexpected manual quarantine entry: %+v", manual)
+	}
+}
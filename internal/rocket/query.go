@@ -0,0 +1,102 @@
+package rocket
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Field - field of the listed states that can be projected, named like in the API
+type Field string
+
+const (
+	FieldType                       Field = "type"
+	FieldCurrentSpeed               Field = "currentSpeed"
+	FieldMission                    Field = "mission"
+	FieldStatus                     Field = "status"
+	FieldReason                     Field = "reason"
+	FieldAnomaly                    Field = "anomaly"
+	FieldLastUpdateTime             Field = "lastUpdateTime"
+	FieldLastProcessedMessageNumber Field = "lastProcessedMessageNumber"
+)
+
+// projectFields - copying of every projectable field to the projected state
+var projectFields = map[Field]func(dst *State, src State){
+	FieldType:                       func(dst *State, src State) { dst.Type = src.Type },
+	FieldCurrentSpeed:               func(dst *State, src State) { dst.CurrentSpeed = src.CurrentSpeed },
+	FieldMission:                    func(dst *State, src State) { dst.Mission = src.Mission },
+	FieldStatus:                     func(dst *State, src State) { dst.Status = src.Status },
+	FieldReason:                     func(dst *State, src State) { dst.Reason = src.Reason },
+	FieldAnomaly:                    func(dst *State, src State) { dst.Anomaly = src.Anomaly },
+	FieldLastUpdateTime:             func(dst *State, src State) { dst.LastUpdateTime = src.LastUpdateTime },
+	FieldLastProcessedMessageNumber: func(dst *State, src State) { dst.LastProcessedMessageNumber = src.LastProcessedMessageNumber },
+}
+
+// ListQuery - which rockets to list and how: the rockets matching the filter, ordered by the sort keys,
+// a page of them, holding only the projected fields. The zero query lists all rockets in the order of the store.
+type ListQuery struct {
+	Filter Filter
+	// Sort - sort keys, most significant first. Ties left are broken by the rocket ID.
+	Sort []SortKey
+	// Collation - comparison of the string sort fields, byte-wise when nil
+	Collation *Collation
+	// Offset - number of matching rockets skipped before the page
+	Offset int
+	// Limit - maximum number of rockets in the page, unlimited when zero
+	Limit int
+	// Fields - fields kept in the listed states besides the ID, all fields when empty
+	Fields []Field
+}
+
+// SortedBy returns a query listing all rockets ordered by a single sort key
+func SortedBy(field SortField, order SortOrder) ListQuery {
+	return ListQuery{Sort: []SortKey{{Field: field, Order: order}}}
+}
+
+// Validate checks the sort keys, the page bounds and the projected fields.
+// It returns ErrUnknownSortField or ErrUnknownSortOrder for invalid sort keys and ErrInvalidQuery otherwise.
+func (q ListQuery) Validate() error {
+	for _, k := range q.Sort {
+		if err := k.Validate(); err != nil {
+			return err
+		}
+	}
+	if q.Offset < 0 {
+		return fmt.Errorf("%w: negative offset %d", ErrInvalidQuery, q.Offset)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("%w: negative limit %d", ErrInvalidQuery, q.Limit)
+	}
+	for _, f := range q.Fields {
+		if _, ok := projectFields[f]; !ok {
+			return fmt.Errorf("%w: unknown field %s", ErrInvalidQuery, f)
+		}
+	}
+	return nil
+}
+
+// Apply answers the validated query from candidate states, e.g. all rockets of a store or the ones narrowed down
+// by a secondary index: it filters, sorts, pages and projects them. The candidates may be reordered.
+func (q ListQuery) Apply(candidates []State) []State {
+	states := slices.DeleteFunc(candidates, func(state State) bool { return !q.Filter.Match(state) })
+	sortStates(states, q.Sort, q.Collation)
+
+	states = states[min(q.Offset, len(states)):]
+	if q.Limit > 0 && q.Limit < len(states) {
+		states = states[:q.Limit]
+	}
+	if len(q.Fields) > 0 {
+		for i, state := range states {
+			states[i] = q.project(state)
+		}
+	}
+	return states
+}
+
+// project returns the state holding only the ID and the projected fields
+func (q ListQuery) project(state State) State {
+	projected := State{ID: state.ID}
+	for _, f := range q.Fields {
+		projectFields[f](&projected, state)
+	}
+	return projected
+}
@@ -0,0 +1,99 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+)
+
+func TestRocketService_ListAllRockets_Query(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	service := NewRocketService(store, logger)
+
+	r1 := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 300, Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+	r2 := State{ID: uuid.New(), Type: "Soyuz", CurrentSpeed: 100, Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+	r3 := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 200, Mission: "APOLLO", Status: StatusLaunched, Version: 1}
+	r4 := State{ID: uuid.New(), Type: "Soyuz", CurrentSpeed: 400, Mission: "APOLLO", Status: StatusExploded, Version: 1}
+	for _, r := range []State{r1, r2, r3, r4} {
+		store.SaveRocket(r)
+	}
+	byMissionThenSpeed := []SortKey{{Field: SortByMission}, {Field: SortBySpeed, Order: SortDesc}}
+
+	tests := []struct {
+		name     string
+		query    ListQuery
+		expected []uuid.UUID
+	}{
+		{name: "sort keys", query: ListQuery{Sort: byMissionThenSpeed}, expected: []uuid.UUID{r4.ID, r3.ID, r1.ID, r2.ID}},
+		{name: "first page", query: ListQuery{Sort: byMissionThenSpeed, Limit: 3}, expected: []uuid.UUID{r4.ID, r3.ID, r1.ID}},
+		{name: "second page", query: ListQuery{Sort: byMissionThenSpeed, Offset: 3, Limit: 3}, expected: []uuid.UUID{r2.ID}},
+		{name: "past the end", query: ListQuery{Sort: byMissionThenSpeed, Offset: 10}, expected: nil},
+		{name: "filtered page", query: ListQuery{Filter: Filter{Status: StatusLaunched}, Sort: byMissionThenSpeed, Offset: 1, Limit: 1}, expected: []uuid.UUID{r1.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rockets, err := service.ListAllRockets(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("ListAllRockets failed: %v", err)
+			}
+			var got []uuid.UUID
+			for _, state := range rockets {
+				got = append(got, state.ID)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("projection", func(t *testing.T) {
+		rockets, err := service.ListAllRockets(context.Background(), ListQuery{Filter: Filter{Type: "Soyuz"}, Sort: []SortKey{{Field: SortBySpeed}}, Fields: []Field{FieldCurrentSpeed}})
+		if err != nil {
+			t.Fatalf("ListAllRockets failed: %v", err)
+		}
+		expected := []State{{ID: r2.ID, CurrentSpeed: 100}, {ID: r4.ID, CurrentSpeed: 400}}
+		if !reflect.DeepEqual(rockets, expected) {
+			t.Errorf("Expected only IDs and speeds %+v, got %+v", expected, rockets)
+		}
+		if stored, _ := store.GetRocketByID(r2.ID); !reflect.DeepEqual(stored, r2) {
+			t.Errorf("Expected the projection not to change the stored state, got %+v", stored)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, query := range []ListQuery{{Offset: -1}, {Limit: -1}, {Fields: []Field{"altitude"}}} {
+			if _, err := service.ListAllRockets(context.Background(), query); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("Expected ErrInvalidQuery for %+v, got %v", query, err)
+			}
+		}
+		if _, err := service.ListAllRockets(context.Background(), ListQuery{Sort: []SortKey{{Field: SortByID}, {Field: "altitude"}}}); !errors.Is(err, ErrUnknownSortField) {
+			t.Errorf("Expected ErrUnknownSortField for an unknown secondary sort key, got %v", err)
+		}
+	})
+}
+
+func TestListQuery_NaturalSort(t *testing.T) {
+	types := []RocketType{"Falcon-10", "falcon-9", "Falcon-9B", "Electron", "Falcon-009", "Falcon-1"}
+	var states []State
+	for _, typ := range types {
+		states = append(states, State{ID: uuid.New(), Type: typ})
+	}
+	collation, err := NewCollation("und", false)
+	if err != nil {
+		t.Fatalf("NewCollation failed: %v", err)
+	}
+
+	q := ListQuery{Sort: []SortKey{{Field: SortByType, Natural: true}}, Collation: collation}
+	var got []RocketType
+	for _, s := range q.Apply(states) {
+		got = append(got, s.Type)
+	}
+	expected := []RocketType{"Electron", "Falcon-1", "falcon-9", "Falcon-009", "Falcon-9B", "Falcon-10"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,117 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"rockets/internal/clock"
+	"sync"
+	"time"
+)
+
+// Sliding windows of the message rates are made of buckets of rateBucket, the longest window is rateBuckets long
+const (
+	rateBucket  = 10 * time.Second
+	rateBuckets = int(15 * time.Minute / rateBucket)
+)
+
+// Rates - messages per minute a rocket received over the last 1, 5 and 15 minutes
+type Rates struct {
+	OneMinute      float64
+	FiveMinutes    float64
+	FifteenMinutes float64
+}
+
+// rateCounter - ring of message counts per bucket, starts holds the bucket number since the epoch of each slot,
+// last the bucket of the latest message
+type rateCounter struct {
+	counts [rateBuckets]int64
+	starts [rateBuckets]int64
+	last   int64
+}
+
+// MessageRates tracks how often the rockets send messages, counting the messages by the time they arrived,
+// duplicates and rejected ones included, to spot chattering sensors. The counters of the rockets without a
+// message in the longest window are dropped.
+type MessageRates struct {
+	mu       sync.Mutex
+	counters map[uuid.UUID]*rateCounter
+	// swept - bucket of the last eviction of the idle counters
+	swept int64
+	clock clock.Clock
+}
+
+// NewMessageRates creates a tracker without messages.
+func NewMessageRates() *MessageRates {
+	return &MessageRates{
+		counters: make(map[uuid.UUID]*rateCounter),
+		clock:    clock.Real{},
+	}
+}
+
+// UseClock replaces the system clock the messages are counted by. Must be called before the tracker is used.
+func (r *MessageRates) UseClock(c clock.Clock) {
+	r.clock = c
+}
+
+// Count counts a message of the rocket arriving. A nil tracker counts nothing.
+func (r *MessageRates) Count(id uuid.UUID) {
+	if r == nil {
+		return
+	}
+	bucket := r.clock.Now().UnixNano() / int64(rateBucket)
+	slot := bucket % int64(rateBuckets)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if bucket != r.swept {
+		r.evictLocked(bucket)
+	}
+	c, ok := r.counters[id]
+	if !ok {
+		c = &rateCounter{}
+		r.counters[id] = c
+	}
+	if c.starts[slot] != bucket {
+		c.starts[slot] = bucket
+		c.counts[slot] = 0
+	}
+	c.counts[slot]++
+	c.last = bucket
+}
+
+// evictLocked drops the counters without a message in the longest window, once per bucket
+func (r *MessageRates) evictLocked(bucket int64) {
+	r.swept = bucket
+	for id, c := range r.counters {
+		if c.last <= bucket-int64(rateBuckets) {
+			delete(r.counters, id)
+		}
+	}
+}
+
+// Rates returns the message rates of the rocket, zero for a rocket without recent messages
+func (r *MessageRates) Rates(id uuid.UUID) Rates {
+	now := r.clock.Now().UnixNano() / int64(rateBucket)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.counters[id]
+	if !ok {
+		return Rates{}
+	}
+	// count over the windows, the current bucket included
+	count := func(window time.Duration) float64 {
+		var n int64
+		from := now - int64(window/rateBucket)
+		for i, start := range c.starts {
+			if start > from && start <= now {
+				n += c.counts[i]
+			}
+		}
+		return float64(n) / window.Minutes()
+	}
+	return Rates{
+		OneMinute:      count(time.Minute),
+		FiveMinutes:    count(5 * time.Minute),
+		FifteenMinutes: count(15 * time.Minute),
+	}
+}
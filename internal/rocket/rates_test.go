@@ -0,0 +1,72 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"testing"
+	"time"
+)
+
+func TestMessageRates(t *testing.T) {
+	fake := clock.NewFake(time.Date(2022, 2, 2, 19, 39, 0, 0, time.UTC))
+	rates := NewMessageRates()
+	rates.UseClock(fake)
+	chatty, quiet := uuid.New(), uuid.New()
+	apply := func(id uuid.UUID, n int) {
+		for range n {
+			rates.Count(id)
+		}
+	}
+
+	// 30 messages 10 minutes ago, 12 in the last minute
+	apply(chatty, 30)
+	apply(quiet, 1)
+	fake.Advance(10 * time.Minute)
+	apply(chatty, 12)
+
+	got := rates.Rates(chatty)
+	expected := Rates{OneMinute: 12, FiveMinutes: 12.0 / 5, FifteenMinutes: 42.0 / 15}
+	if got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	if got := rates.Rates(quiet); got != (Rates{FifteenMinutes: 1.0 / 15}) {
+		t.Errorf("Expected only the 15 minutes rate of the quiet rocket, got %+v", got)
+	}
+
+	fake.Advance(15 * time.Minute)
+	if got := rates.Rates(chatty); got != (Rates{}) {
+		t.Errorf("Expected the rates to decay to zero, got %+v", got)
+	}
+	if got := rates.Rates(uuid.New()); got != (Rates{}) {
+		t.Errorf("Expected zero rates of an unknown rocket, got %+v", got)
+	}
+
+	// the counters of the idle rockets are dropped with the next message
+	apply(quiet, 1)
+	if len(rates.counters) != 1 {
+		t.Errorf("Expected the idle rocket evicted, got %d counters", len(rates.counters))
+	}
+}
+
+func TestRocketService_MessageRates(t *testing.T) {
+	logger := zap.NewNop()
+	service := NewRocketService(NewInMemoryRocketStore(logger), logger)
+	rates := NewMessageRates()
+	service.UseMessageRates(rates)
+	ctx := context.Background()
+
+	id := uuid.New()
+	launch := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: time.Now(), MessageType: MessageTypeLaunched},
+		Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+	}
+	invalid := TelemetryMessage{Metadata: MessageMetadata{Channel: id, MessageNumber: 2, MessageTime: time.Now(), MessageType: MessageTypeSpeedIncreased}}
+	for _, msg := range []TelemetryMessage{launch, launch, invalid} {
+		_, _ = service.ProcessMessage(ctx, msg)
+	}
+	if got := rates.Rates(id).OneMinute; got != 3 {
+		t.Errorf("Expected the duplicate and the invalid message counted on arrival, got %v per minute", got)
+	}
+}
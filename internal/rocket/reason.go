@@ -0,0 +1,109 @@
+package rocket
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ReasonCategory - cause of an explosion, so failures can be counted across missions
+type ReasonCategory string
+
+const (
+	// ReasonPropulsion - engines, propellants and their feed
+	ReasonPropulsion ReasonCategory = "PROPULSION"
+	// ReasonStructural - tanks, pressure vessels and the airframe
+	ReasonStructural ReasonCategory = "STRUCTURAL"
+	// ReasonGuidance - guidance, navigation, control and flight software
+	ReasonGuidance ReasonCategory = "GUIDANCE"
+	// ReasonElectrical - power, batteries and wiring
+	ReasonElectrical ReasonCategory = "ELECTRICAL"
+	// ReasonThermal - overheating and fires
+	ReasonThermal ReasonCategory = "THERMAL"
+	// ReasonRangeSafety - the flight was terminated on command
+	ReasonRangeSafety ReasonCategory = "RANGE_SAFETY"
+	// ReasonWeather - lightning, wind and other conditions
+	ReasonWeather ReasonCategory = "WEATHER"
+	// ReasonOther - a reason none of the categories matched
+	ReasonOther ReasonCategory = "OTHER"
+)
+
+// ReasonSeverity - how bad an explosion was
+type ReasonSeverity string
+
+const (
+	// SeverityCritical - the vehicle was lost to an uncontrolled failure
+	SeverityCritical ReasonSeverity = "CRITICAL"
+	// SeverityMajor - the vehicle was destroyed under control, e.g. by the flight termination system
+	SeverityMajor ReasonSeverity = "MAJOR"
+)
+
+// reasonKeywords - words of the reasons as reported by the producers per category, the first matching category
+// wins, so the more specific ones go first
+var reasonKeywords = []struct {
+	category ReasonCategory
+	words    []string
+}{
+	{ReasonRangeSafety, []string{"RANGE_SAFETY", "FLIGHT_TERMINATION", "FTS", "TERMINATED", "SELF_DESTRUCT", "DESTRUCT_COMMAND"}},
+	{ReasonStructural, []string{"PRESSURE_VESSEL", "COPV", "STRUCTURAL", "TANK", "BULKHEAD", "STRUT", "FAIRING", "AIRFRAME", "FATIGUE", "RUPTURE"}},
+	{ReasonPropulsion, []string{"ENGINE", "THRUST", "TURBOPUMP", "COMBUSTION", "NOZZLE", "INJECTOR", "PROPELLANT", "FUEL", "OXIDIZER", "LOX", "MOTOR", "IGNITION"}},
+	{ReasonGuidance, []string{"GUIDANCE", "NAVIGATION", "GNC", "CONTROL", "ATTITUDE", "GYRO", "SOFTWARE", "TRAJECTORY"}},
+	{ReasonElectrical, []string{"ELECTRICAL", "POWER", "BATTERY", "WIRING", "SHORT_CIRCUIT", "AVIONICS"}},
+	{ReasonThermal, []string{"THERMAL", "OVERHEAT", "HEAT", "FIRE", "TEMPERATURE"}},
+	{ReasonWeather, []string{"WEATHER", "LIGHTNING", "WIND", "ICE", "STORM"}},
+}
+
+// ExplosionReason - why a rocket exploded: the reason as the producer reported it, and its category and
+// severity derived from it
+type ExplosionReason struct {
+	Category ReasonCategory `json:"category"`
+	Severity ReasonSeverity `json:"severity"`
+	// Text - the reason as reported
+	Text string `json:"text"`
+}
+
+// ClassifyReason maps the reason reported with an explosion to a category and a severity by the words it is
+// made of, e.g. PRESSURE_VESSEL_FAILURE to STRUCTURAL. It returns nil for no reason.
+func ClassifyReason(text *string) *ExplosionReason {
+	if text == nil || strings.TrimSpace(*text) == "" {
+		return nil
+	}
+	// words are compared upper-cased and joined by underscores, however the producer wrote them
+	normalized := "_" + strings.Join(strings.FieldsFunc(strings.ToUpper(*text), func(r rune) bool {
+		return (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	}), "_") + "_"
+	reason := &ExplosionReason{Category: ReasonOther, Severity: SeverityCritical, Text: *text}
+	for _, k := range reasonKeywords {
+		if containsWord(normalized, k.words) {
+			reason.Category = k.category
+			break
+		}
+	}
+	if reason.Category == ReasonRangeSafety {
+		reason.Severity = SeverityMajor
+	}
+	return reason
+}
+
+// containsWord reports whether the normalized text has one of the words, starting at a word boundary
+func containsWord(normalized string, words []string) bool {
+	for _, w := range words {
+		if strings.Contains(normalized, "_"+w) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON reads the reason, or a plain reason string as states were persisted before the reasons were
+// classified
+func (r *ExplosionReason) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		if classified := ClassifyReason(&text); classified != nil {
+			*r = *classified
+		}
+		return nil
+	}
+	type plain ExplosionReason
+	return json.Unmarshal(data, (*plain)(r))
+}
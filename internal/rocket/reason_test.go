@@ -0,0 +1,58 @@
+package rocket
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestClassifyReason(t *testing.T) {
+	tests := []struct {
+		text     string
+		category ReasonCategory
+		severity ReasonSeverity
+	}{
+		{"PRESSURE_VESSEL_FAILURE", ReasonStructural, SeverityCritical},
+		{"engine 3 turbopump failure", ReasonPropulsion, SeverityCritical},
+		{"Flight termination system activated", ReasonRangeSafety, SeverityMajor},
+		{"GNC software fault", ReasonGuidance, SeverityCritical},
+		{"battery short-circuit", ReasonElectrical, SeverityCritical},
+		{"LIGHTNING_STRIKE", ReasonWeather, SeverityCritical},
+		{"overheating in stage 2", ReasonThermal, SeverityCritical},
+		// words are matched from their start, FTS is not part of SHAFTS
+		{"BROKEN_SHAFTS", ReasonOther, SeverityCritical},
+		{"ALIENS", ReasonOther, SeverityCritical},
+	}
+	for _, tt := range tests {
+		text := tt.text
+		got := ClassifyReason(&text)
+		if got == nil || got.Category != tt.category || got.Severity != tt.severity || got.Text != tt.text {
+			t.Errorf("ClassifyReason(%q): expected %s/%s, got %+v", tt.text, tt.category, tt.severity, got)
+		}
+	}
+	if got := ClassifyReason(ptr("  ")); got != nil {
+		t.Errorf("Expected no reason for a blank one, got %+v", got)
+	}
+	if got := ClassifyReason(nil); got != nil {
+		t.Errorf("Expected no reason without one, got %+v", got)
+	}
+}
+
+func TestExplosionReason_UnmarshalLegacy(t *testing.T) {
+	var state State
+	if err := json.Unmarshal([]byte(`{"status":"EXPLODED","reason":"PRESSURE_VESSEL_FAILURE"}`), &state); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	expected := ExplosionReason{Category: ReasonStructural, Severity: SeverityCritical, Text: "PRESSURE_VESSEL_FAILURE"}
+	if state.Reason == nil || *state.Reason != expected {
+		t.Errorf("Expected %+v, got %+v", expected, state.Reason)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var again State
+	if err := json.Unmarshal(data, &again); err != nil || again.Reason == nil || *again.Reason != expected {
+		t.Errorf("Expected the classified reason to round-trip, got %+v (%v)", again.Reason, err)
+	}
+}
@@ -0,0 +1,201 @@
+package rocket
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"rockets/internal/jsonfile"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Registration - rocket announced before its telemetry starts, with the type and mission its launch must report
+type Registration struct {
+	ID           uuid.UUID  `json:"id"`
+	Type         RocketType `json:"type"`
+	Mission      Mission    `json:"mission"`
+	RegisteredAt time.Time  `json:"registeredAt"`
+}
+
+// Registrations - rockets registered ahead of their telemetry. Launch messages of a registered rocket must
+// match its registration. A nil registry has no registrations.
+type Registrations struct {
+	mu   sync.RWMutex
+	byID map[uuid.UUID]Registration
+	// file - JSON file the registrations are saved to on every change, empty keeps them in memory only
+	file string
+}
+
+// NewRegistrations creates a registry keeping the registrations in memory only.
+func NewRegistrations() *Registrations {
+	return &Registrations{byID: make(map[uuid.UUID]Registration)}
+}
+
+// OpenRegistrations creates a registry saving the registrations to the file, loading the ones saved before if
+// it exists.
+func OpenRegistrations(file string) (*Registrations, error) {
+	r := NewRegistrations()
+	r.file = file
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Refresh loads the registrations from the file again, picking up the changes saved by the leader while in standby.
+// A registry keeping them in memory only is left as it is.
+func (r *Registrations) Refresh() error {
+	if r == nil || r.file == "" {
+		return nil
+	}
+	var registrations []Registration
+	if _, err := jsonfile.Load(r.file, "registrations", &registrations); err != nil {
+		return err
+	}
+	byID := make(map[uuid.UUID]Registration, len(registrations))
+	for _, reg := range registrations {
+		byID[reg.ID] = reg
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID = byID
+	return nil
+}
+
+// Register registers the rocket with the type and mission, replacing its previous registration, and reports
+// whether it was not registered before. Registering the same type and mission again keeps the registration time.
+func (r *Registrations) Register(id uuid.UUID, typ RocketType, mission Mission, now time.Time) (Registration, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, registered := r.byID[id]
+	if registered && prev.Type == typ && prev.Mission == mission {
+		return prev, false, nil
+	}
+	reg := Registration{ID: id, Type: typ, Mission: mission, RegisteredAt: now}
+	r.byID[id] = reg
+	if err := r.saveLocked(); err != nil {
+		if registered {
+			r.byID[id] = prev
+		} else {
+			delete(r.byID, id)
+		}
+		return Registration{}, false, err
+	}
+	return reg, !registered, nil
+}
+
+// Get returns the registration of the rocket
+func (r *Registrations) Get(id uuid.UUID) (Registration, bool) {
+	if r == nil {
+		return Registration{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reg, ok := r.byID[id]
+	return reg, ok
+}
+
+// List returns the registrations, the oldest first
+func (r *Registrations) List() []Registration {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.listLocked()
+}
+
+func (r *Registrations) listLocked() []Registration {
+	registrations := make([]Registration, 0, len(r.byID))
+	for _, reg := range r.byID {
+		registrations = append(registrations, reg)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		if !registrations[i].RegisteredAt.Equal(registrations[j].RegisteredAt) {
+			return registrations[i].RegisteredAt.Before(registrations[j].RegisteredAt)
+		}
+		return registrations[i].ID.String() < registrations[j].ID.String()
+	})
+	return registrations
+}
+
+// Check rejects the launch message of a registered rocket reporting another type or mission than registered,
+// with ErrInvalidMessage. Other messages and unregistered rockets pass.
+func (r *Registrations) Check(msg TelemetryMessage) error {
+	if msg.Metadata.MessageType != MessageTypeLaunched {
+		return nil
+	}
+	reg, ok := r.Get(msg.Metadata.Channel)
+	if !ok {
+		return nil
+	}
+	if *msg.Message.Type != reg.Type {
+		return fmt.Errorf("%w: type %q does not match the registered %q", ErrInvalidMessage, *msg.Message.Type, reg.Type)
+	}
+	if *msg.Message.Mission != reg.Mission {
+		return fmt.Errorf("%w: mission %q does not match the registered %q", ErrInvalidMessage, *msg.Message.Mission, reg.Mission)
+	}
+	return nil
+}
+
+// saveLocked writes the registrations to the file
+func (r *Registrations) saveLocked() error {
+	if r.file == "" {
+		return nil
+	}
+	return jsonfile.Save(r.file, "registrations", r.listLocked())
+}
+
+// RegisterResult - registration made by RegisterRocket
+type RegisterResult struct {
+	Registration Registration
+	// Created - the rocket was not registered before
+	Created bool
+	// Awaiting - the telemetry of the rocket has not started yet
+	Awaiting bool
+}
+
+// RegisterRocket registers the rocket with the type and mission. allowed reports whether the caller may manage the
+// rockets of a mission: the mission of the registration, the one of the rocket's current registration and the one
+// its telemetry reports must all be allowed, so a caller can't take over a rocket outside its scope by naming a
+// mission in it. The check and the registration are made under the lock of the rocket, so a launch can't be
+// applied in between.
+func (s *ServiceImpl) RegisterRocket(ctx context.Context, id uuid.UUID, typ RocketType, mission Mission, allowed func(Mission) bool) (RegisterResult, error) {
+	if err := ctx.Err(); err != nil {
+		return RegisterResult{}, fmt.Errorf("can't register rocket: %w", err)
+	}
+	var result RegisterResult
+	var err error
+	s.exclusive(id, func() {
+		result, err = s.register(id, typ, mission, allowed)
+	})
+	return result, err
+}
+
+// register registers the rocket, must be called from exclusive
+func (s *ServiceImpl) register(id uuid.UUID, typ RocketType, mission Mission, allowed func(Mission) bool) (RegisterResult, error) {
+	state, exists := s.store.GetRocketByID(id)
+	missions := []Mission{mission}
+	if reg, ok := s.registrations.Get(id); ok {
+		missions = append(missions, reg.Mission)
+	}
+	// a provisional state has no mission until its launch arrives
+	if exists && state.Mission != "" {
+		missions = append(missions, state.Mission)
+	}
+	for _, m := range missions {
+		if !allowed(m) {
+			return RegisterResult{}, fmt.Errorf("%w: rocket %s of mission %s", ErrForbidden, id, m)
+		}
+	}
+	if exists && (state.Type != "" && state.Type != typ || state.Mission != "" && state.Mission != mission) {
+		return RegisterResult{}, fmt.Errorf("%w: rocket %s already reports type %q and mission %q", ErrRegistrationConflict, id, state.Type, state.Mission)
+	}
+
+	reg, created, err := s.registrations.Register(id, typ, mission, s.clock.Now())
+	if err != nil {
+		return RegisterResult{}, fmt.Errorf("can't register rocket: %w", err)
+	}
+	return RegisterResult{Registration: reg, Created: created, Awaiting: !exists}, nil
+}
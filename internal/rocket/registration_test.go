@@ -0,0 +1,69 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRegistrations_Register(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "registrations.json")
+	registrations, err := OpenRegistrations(file)
+	if err != nil {
+		t.Fatalf("OpenRegistrations failed: %v", err)
+	}
+	id := uuid.New()
+	at := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+
+	reg, created, err := registrations.Register(id, "Falcon-9", "ARTEMIS", at)
+	if err != nil || !created || !reg.RegisteredAt.Equal(at) {
+		t.Fatalf("Expected the rocket registered at %s, got %+v, %t, %v", at, reg, created, err)
+	}
+	// the same registration again keeps its time
+	reg, created, err = registrations.Register(id, "Falcon-9", "ARTEMIS", at.Add(time.Hour))
+	if err != nil || created || !reg.RegisteredAt.Equal(at) {
+		t.Errorf("Expected the registration unchanged, got %+v, %t, %v", reg, created, err)
+	}
+	if _, _, err := registrations.Register(id, "Falcon-9", "APOLLO", at.Add(time.Hour)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	reopened, err := OpenRegistrations(file)
+	if err != nil {
+		t.Fatalf("OpenRegistrations failed: %v", err)
+	}
+	if got, ok := reopened.Get(id); !ok || got.Mission != "APOLLO" || !got.RegisteredAt.Equal(at.Add(time.Hour)) {
+		t.Errorf("Expected the updated registration reloaded, got %+v", got)
+	}
+}
+
+func TestRocketService_Registrations(t *testing.T) {
+	registrations := NewRegistrations()
+	svc := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	svc.UseRegistrations(registrations)
+	id := uuid.New()
+	if _, _, err := registrations.Register(id, "Falcon-9", "ARTEMIS", time.Now()); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	launch := func(typ RocketType, mission Mission) error {
+		_, err := svc.ProcessMessage(context.Background(), TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: time.Now(), MessageType: MessageTypeLaunched},
+			Message:  Message{Type: &typ, LaunchSpeed: ptr(Speed(500)), Mission: &mission},
+		})
+		return err
+	}
+
+	if err := launch("Soyuz", "ARTEMIS"); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("Expected the launch of another type rejected, got %v", err)
+	}
+	if err := launch("Falcon-9", "APOLLO"); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("Expected the launch on another mission rejected, got %v", err)
+	}
+	if err := launch("Falcon-9", "ARTEMIS"); err != nil {
+		t.Errorf("Expected the registered launch applied, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package rocket
+
+import (
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+// reorder - messages received ahead of a gap in their channel's sequence, held until the gap is filled
+type reorder struct {
+	mu      sync.Mutex
+	pending map[uuid.UUID]*held
+	// window - held messages per channel above which the gap is skipped
+	window int
+	// maxWait - how long a gap may stay open before it is skipped, 0 waits forever
+	maxWait time.Duration
+}
+
+// held - messages of a channel waiting for a gap, by message number
+type held struct {
+	messages map[int64]TelemetryMessage
+	since    time.Time
+}
+
+func newReorder(window int, maxWait time.Duration) *reorder {
+	return &reorder{
+		pending: make(map[uuid.UUID]*held),
+		window:  window,
+		maxWait: maxWait,
+	}
+}
+
+// hold keeps the message until its predecessors arrive, returning false if it is already held
+func (r *reorder) hold(msg TelemetryMessage, now time.Time) bool {
+	return r.holdSince(msg, now)
+}
+
+// holdSince keeps the message like hold, with the gap of a channel without held messages opened at since
+func (r *reorder) holdSince(msg TelemetryMessage, since time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := msg.Metadata.Channel
+	h, ok := r.pending[id]
+	if !ok {
+		h = &held{messages: make(map[int64]TelemetryMessage), since: since}
+		r.pending[id] = h
+	}
+	if _, ok := h.messages[msg.Metadata.MessageNumber]; ok {
+		return false
+	}
+	h.messages[msg.Metadata.MessageNumber] = msg
+	return true
+}
+
+// size returns the number of held messages of the channel
+func (r *reorder) size(id uuid.UUID) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h, ok := r.pending[id]; ok {
+		return len(h.messages)
+	}
+	return 0
+}
+
+// overdue reports whether the gap of the channel should be skipped. The gap of a pinned channel waits without
+// a time limit, only the window applies.
+func (r *reorder) overdue(id uuid.UUID, now time.Time, pinned bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.pending[id]
+	return ok && (len(h.messages) > r.window || (!pinned && r.maxWait > 0 && now.Sub(h.since) >= r.maxWait))
+}
+
+// overdueChannels lists channels whose gaps should be skipped, pinned reports the pinned channels
+func (r *reorder) overdueChannels(now time.Time, pinned func(uuid.UUID) bool) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, id := range r.channels() {
+		if r.overdue(id, now, pinned(id)) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// channels lists channels with held messages
+func (r *reorder) channels() []uuid.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]uuid.UUID, 0, len(r.pending))
+	for id := range r.pending {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// take removes the held messages of the channel, returning nil when there are none
+func (r *reorder) take(id uuid.UUID) *held {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := r.pending[id]
+	delete(r.pending, id)
+	return h
+}
+
+// next pops the held message following the given number. With skipGap the lowest held message
+// above the number is popped instead, when the following one is missing. Held messages
+// not above the number are dropped as duplicates.
+func (r *reorder) next(id uuid.UUID, after int64, skipGap bool, now time.Time) (TelemetryMessage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.pending[id]
+	if !ok {
+		return TelemetryMessage{}, false
+	}
+
+	var found bool
+	var lowest int64
+	for n := range h.messages {
+		switch {
+		case n <= after:
+			delete(h.messages, n)
+		case !found || n < lowest:
+			found, lowest = true, n
+		}
+	}
+	if !found || (lowest != after+1 && !skipGap) {
+		if len(h.messages) == 0 {
+			delete(r.pending, id)
+		}
+		return TelemetryMessage{}, false
+	}
+
+	msg := h.messages[lowest]
+	delete(h.messages, lowest)
+	if len(h.messages) == 0 {
+		delete(r.pending, id)
+	} else if lowest != after+1 {
+		// the skipped gap restarts the wait for the remaining messages
+		h.since = now
+	}
+	return msg, true
+}
+
+// count returns the number of held messages of the channel
+func (r *reorder) count(id uuid.UUID) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h, ok := r.pending[id]; ok {
+		return len(h.messages)
+	}
+	return 0
+}
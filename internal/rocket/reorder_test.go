@@ -0,0 +1,216 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"math/rand"
+	"reflect"
+	"rockets/internal/clock"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// scenario - in-order message sequence of a rocket and the order in which it is delivered
+type scenario struct {
+	Messages []TelemetryMessage
+	// Delivery - indexes into Messages, a permutation possibly with duplicates
+	Delivery []int
+}
+
+// Generate implements quick.Generator
+func (scenario) Generate(r *rand.Rand, size int) reflect.Value {
+	id := uuid.New()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := 1 + r.Intn(size+1)
+
+	messages := make([]TelemetryMessage, 0, n)
+	for i := 1; i <= n; i++ {
+		md := MessageMetadata{Channel: id, MessageNumber: int64(i), MessageTime: start.Add(time.Duration(i) * time.Second)}
+		var m Message
+		switch {
+		case i == 1:
+			md.MessageType = MessageTypeLaunched
+			m = Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(r.Intn(1000))), Mission: ptr(Mission("ARTEMIS"))}
+		case r.Intn(10) == 0:
+			md.MessageType = MessageTypeMissionChanged
+			m = Message{NewMission: ptr(Mission([]string{"ARTEMIS", "APOLLO", "GEMINI"}[r.Intn(3)]))}
+		case r.Intn(30) == 0:
+			md.MessageType = MessageTypeExploded
+			m = Message{Reason: ptr("PRESSURE_VESSEL_FAILURE")}
+		case r.Intn(2) == 0:
+			md.MessageType = MessageTypeSpeedDecreased
+			m = Message{By: ptr(Speed(r.Intn(500)))}
+		default:
+			md.MessageType = MessageTypeSpeedIncreased
+			m = Message{By: ptr(Speed(r.Intn(500)))}
+		}
+		messages = append(messages, TelemetryMessage{Metadata: md, Message: m})
+	}
+
+	delivery := r.Perm(n)
+	for i := r.Intn(n + 1); i > 0; i-- {
+		delivery = append(delivery, r.Intn(n))
+	}
+	r.Shuffle(len(delivery), func(i, j int) { delivery[i], delivery[j] = delivery[j], delivery[i] })
+
+	return reflect.ValueOf(scenario{Messages: messages, Delivery: delivery})
+}
+
+// finalState delivers the messages to a new service and returns the resulting state of the rocket
+func finalState(t *testing.T, messages []TelemetryMessage, delivery []int, window int) State {
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	if window > 0 {
+		service.UseReorderBuffer(window, 0)
+	}
+	ctx := context.Background()
+	for _, i := range delivery {
+		if _, err := service.ProcessMessage(ctx, messages[i]); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	state, _ := service.GetRocketState(ctx, messages[0].Metadata.Channel)
+	return state
+}
+
+func inOrder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+func TestReorder_PermutationConvergesToInOrderState(t *testing.T) {
+	property := func(s scenario) bool {
+		expected := finalState(t, s.Messages, inOrder(len(s.Messages)), 0)
+		got := finalState(t, s.Messages, s.Delivery, len(s.Messages))
+		if !reflect.DeepEqual(expected, got) {
+			t.Logf("Delivery %v\nExpected: %+v\nGot: %+v", s.Delivery, expected, got)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReorder_InOrderDeliveryIsUnaffected(t *testing.T) {
+	property := func(s scenario) bool {
+		order := inOrder(len(s.Messages))
+		return reflect.DeepEqual(finalState(t, s.Messages, order, 0), finalState(t, s.Messages, order, 1))
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReorder_SmallWindowNeverLosesLatestMessage(t *testing.T) {
+	// with a window too small to fill every gap the state may differ, but the last message is never lost
+	property := func(s scenario, window uint8) bool {
+		service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+		service.UseReorderBuffer(int(window%4), 0)
+		ctx := context.Background()
+		for _, i := range s.Delivery {
+			if _, err := service.ProcessMessage(ctx, s.Messages[i]); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+		}
+		id := s.Messages[0].Metadata.Channel
+		// gaps that can't be filled anymore are flushed by the janitor
+		service.reorder.maxWait = time.Nanosecond
+		service.FlushOverdueMessages(ctx)
+
+		state, _ := service.GetRocketState(ctx, id)
+		return state.LastProcessedMessageNumber == int64(len(s.Messages)) && service.reorder.count(id) == 0
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReorder_GapSkippedAfterMaxWait(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	service.UseClock(fake)
+	service.UseReorderBuffer(10, time.Minute)
+	ctx := context.Background()
+
+	id := uuid.New()
+	// message #2 never arrives
+	messages := []TelemetryMessage{
+		{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: fake.Now(), MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: 3, MessageTime: fake.Now(), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(100))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	fake.Advance(59 * time.Second)
+	service.FlushOverdueMessages(ctx)
+	if state, _ := service.GetRocketState(ctx, id); state.LastProcessedMessageNumber != 1 {
+		t.Errorf("Expected the gap to stay open before maxWait, got message #%d processed", state.LastProcessedMessageNumber)
+	}
+
+	fake.Advance(time.Second)
+	service.FlushOverdueMessages(ctx)
+	if state, _ := service.GetRocketState(ctx, id); state.LastProcessedMessageNumber != 3 || state.CurrentSpeed != 600 {
+		t.Errorf("Expected the gap to be skipped after maxWait, got %+v", state)
+	}
+}
+
+func TestReorder_PinnedGapWaits(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	service.UseClock(fake)
+	service.UseReorderBuffer(2, time.Minute)
+	pins := NewPins(nil, []Mission{"VOYAGER"}, fake.Now())
+	service.UsePins(pins)
+	ctx := context.Background()
+
+	id := uuid.New()
+	send := func(number int64, msgType MessageType, msg Message) {
+		t.Helper()
+		_, err := service.ProcessMessage(ctx, TelemetryMessage{
+			Metadata: MessageMetadata{Channel: id, MessageNumber: number, MessageTime: fake.Now(), MessageType: msgType},
+			Message:  msg,
+		})
+		if err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	send(1, MessageTypeLaunched, Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("VOYAGER"))})
+	send(3, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))})
+
+	fake.Advance(30 * 24 * time.Hour)
+	service.FlushOverdueMessages(ctx)
+	if state, _ := service.GetRocketState(ctx, id); state.LastProcessedMessageNumber != 1 {
+		t.Errorf("Expected the gap of the pinned mission to stay open, got message #%d processed", state.LastProcessedMessageNumber)
+	}
+
+	// the window still applies
+	send(4, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))})
+	send(5, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))})
+	if state, _ := service.GetRocketState(ctx, id); state.LastProcessedMessageNumber != 5 {
+		t.Errorf("Expected the gap skipped above the window, got message #%d processed", state.LastProcessedMessageNumber)
+	}
+
+	// once unpinned, the gap times out again
+	pins.UnpinMission("VOYAGER")
+	send(7, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))})
+	fake.Advance(time.Minute)
+	service.FlushOverdueMessages(ctx)
+	if state, _ := service.GetRocketState(ctx, id); state.LastProcessedMessageNumber != 7 {
+		t.Errorf("Expected the gap skipped after maxWait once unpinned, got message #%d processed", state.LastProcessedMessageNumber)
+	}
+}
@@ -0,0 +1,29 @@
+package rocket
+
+// Outcome - what processing a message does with it
+type Outcome string
+
+const (
+	// OutcomeApplied - the message is applied to the rocket state
+	OutcomeApplied Outcome = "applied"
+	// OutcomeBackfilled - the late launch message back-fills the provisional state
+	OutcomeBackfilled Outcome = "backfilled"
+	// OutcomeDuplicate - the message is old or a duplicate and is ignored
+	OutcomeDuplicate Outcome = "duplicate"
+	// OutcomeBuffered - the message is ahead of a gap and held by the reorder buffer
+	OutcomeBuffered Outcome = "buffered"
+	// OutcomeIgnored - the channel is quarantined, the message is accepted but not applied
+	OutcomeIgnored Outcome = "ignored"
+	// OutcomeRejected - the message is invalid
+	OutcomeRejected Outcome = "rejected"
+)
+
+// Result - what processing a message did
+type Result struct {
+	Outcome Outcome
+	// Version - version of the rocket state after the message, the current one when the message was not applied
+	// and 0 for an unknown rocket
+	Version int64
+	// Warnings - notable things that did not stop the message, e.g. a speed underflow or a skipped gap
+	Warnings []string
+}
@@ -0,0 +1,137 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/logging"
+	"time"
+)
+
+// MessageRange - range of message numbers, both ends included
+type MessageRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// RetransmitRequest - asks the producer of a channel to send the messages of the ranges again
+type RetransmitRequest struct {
+	Channel uuid.UUID      `json:"channel"`
+	Ranges  []MessageRange `json:"ranges"`
+	// RequestedAt - when the request was made, the same for all requests of a gap report
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+// Retransmitter - asks the producers for missing messages, e.g. through a callback endpoint or a control message
+type Retransmitter interface {
+	// RequestRetransmission delivers the request, an error leaves the ranges to be requested again
+	RequestRetransmission(ctx context.Context, req RetransmitRequest) error
+}
+
+// maxRetransmitRequests - retransmission requests in flight at once, the channels beyond them are requested on the
+// next report
+const maxRetransmitRequests = 8
+
+// UseRetransmission asks the producers through the retransmitter for the messages of the gaps that stay open for
+// longer than after, once per gap, on every gap report; see ReportGaps.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseRetransmission(r Retransmitter, after time.Duration) {
+	s.retransmitter = r
+	s.retransmitAfter = after
+	s.retransmitSlots = make(chan struct{}, maxRetransmitRequests)
+}
+
+// requestRetransmissions starts requesting the gaps open for longer than the threshold that were not requested
+// yet, a request per channel, without waiting for the producers. The gaps are marked as requested up front, so
+// a slow producer is not asked again by the next report; a failed request is logged and unmarks them, to be made
+// again on the next report.
+func (s *ServiceImpl) requestRetransmissions(ctx context.Context, now time.Time) {
+	for _, req := range s.gaps.unrequested(now.Add(-s.retransmitAfter), now) {
+		select {
+		case s.retransmitSlots <- struct{}{}:
+		default:
+			return
+		}
+		s.gaps.requested(req)
+		s.retransmits.Add(1)
+		go func() {
+			defer s.retransmits.Done()
+			defer func() { <-s.retransmitSlots }()
+			s.requestRetransmission(ctx, req)
+		}()
+	}
+}
+
+// requestRetransmission makes the request of the channel
+func (s *ServiceImpl) requestRetransmission(ctx context.Context, req RetransmitRequest) {
+	var missing int64
+	for _, r := range req.Ranges {
+		missing += r.To - r.From + 1
+	}
+	if err := s.retransmitter.RequestRetransmission(ctx, req); err != nil {
+		s.gaps.unrequest(req)
+		s.logger.Warn("Can't request the retransmission of missing messages",
+			zap.String("rocket_id", req.Channel.String()),
+			zap.Int64("missing", missing),
+			zap.Error(err),
+		)
+		return
+	}
+	s.logger.Info("Retransmission of missing messages requested",
+		logging.Event(logging.EventRetransmitRequested),
+		zap.String("rocket_id", req.Channel.String()),
+		zap.Int("ranges", len(req.Ranges)),
+		zap.Int64("missing", missing),
+	)
+}
+
+// unrequested returns a request per channel for the gaps detected before the cutoff that were not requested yet
+func (t *gapTracker) unrequested(cutoff, now time.Time) []RetransmitRequest {
+	var out []RetransmitRequest
+	for _, channel := range t.list(cutoff) {
+		req := RetransmitRequest{Channel: channel.Channel, RequestedAt: now.UTC()}
+		for _, g := range channel.Gaps {
+			if g.RequestedAt == nil {
+				req.Ranges = append(req.Ranges, MessageRange{From: g.From, To: g.To})
+			}
+		}
+		if len(req.Ranges) > 0 {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// unrequest clears the marks of the request, after it failed. Gaps marked by another request are left marked.
+func (t *gapTracker) unrequest(req RetransmitRequest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.channels[req.Channel]
+	if !ok {
+		return
+	}
+	for i, g := range c.gaps {
+		if g.RequestedAt != nil && g.RequestedAt.Equal(req.RequestedAt) {
+			c.gaps[i].RequestedAt = nil
+		}
+	}
+}
+
+// requested marks the gaps within the ranges of the request as requested. Gaps that were partly filled meanwhile
+// are still within their ranges.
+func (t *gapTracker) requested(req RetransmitRequest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.channels[req.Channel]
+	if !ok {
+		return
+	}
+	at := req.RequestedAt
+	for i, g := range c.gaps {
+		for _, r := range req.Ranges {
+			if g.RequestedAt == nil && g.From >= r.From && g.To <= r.To {
+				c.gaps[i].RequestedAt = &at
+			}
+		}
+	}
+}
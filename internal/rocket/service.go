@@ -2,135 +2,1041 @@ package rocket
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
-	"sort"
-	"strings"
+	"rockets/internal/clock"
+	"rockets/internal/logging"
+	"rockets/internal/tracing"
+	"sync"
+	"time"
 )
 
+// lockStripes - number of mutexes guarding read-modify-write cycles of rocket states
+const lockStripes = 256
+
 // Service - interface for rocket service
 type Service interface {
-	// ProcessMessage processes a telemetry message and updates the rocket state accordingly
-	ProcessMessage(ctx context.Context, msg TelemetryMessage) error
-	// GetRocketState retrieves the current state of a rocket by its ID
-	GetRocketState(ctx context.Context, id uuid.UUID) (State, bool)
-	// ListAllRockets lists all rockets, optionally sorted by a specified field and order
-	ListAllRockets(ctx context.Context, sortBy, sortOrder string) []State
+	// ProcessMessage processes a telemetry message and updates the rocket state accordingly, returning what
+	// it did with the message. Invalid messages are rejected with ErrInvalidMessage.
+	ProcessMessage(ctx context.Context, msg TelemetryMessage) (Result, error)
+	// DryRunMessage validates the message and returns what processing it would change, without changing anything
+	DryRunMessage(ctx context.Context, msg TelemetryMessage) (DryRun, error)
+	// GetRocketState retrieves the current state of a rocket by its ID.
+	// It returns ErrRocketNotFound for untracked rockets and the context error when the read did not finish in time.
+	GetRocketState(ctx context.Context, id uuid.UUID) (State, error)
+	// ListAllRockets lists the rockets answering the query: filtered, sorted, paged and projected.
+	// It returns ErrUnknownSortField, ErrUnknownSortOrder or ErrInvalidQuery for invalid queries,
+	// and the read errors of GetRocketState otherwise.
+	ListAllRockets(ctx context.Context, query ListQuery) ([]State, error)
+	// RollbackRocket restores the state of a rocket prior to the given message, superseding it and all later messages
+	RollbackRocket(ctx context.Context, id uuid.UUID, messageNumber int64) (State, error)
+	// BackfillHistory merges historical messages of a rocket into its history by message time and recomputes the state
+	BackfillHistory(ctx context.Context, id uuid.UUID, messages []TelemetryMessage) (BackfillReport, error)
+	// MergeChannels combines the history of another channel into the rocket and removes the other channel's state
+	MergeChannels(ctx context.Context, id, other uuid.UUID) (MergeReport, error)
+	// CloneRocket copies the history of a rocket up to the given message into a new rocket
+	CloneRocket(ctx context.Context, id, clone uuid.UUID, messageNumber int64) (State, error)
+	// QuarantineChannel stops applying messages of the channel to the rocket state until it is released
+	QuarantineChannel(ctx context.Context, id uuid.UUID, reason string) QuarantineEntry
+	// ReleaseChannel releases the channel from quarantine, returning false if it was not quarantined
+	ReleaseChannel(ctx context.Context, id uuid.UUID) bool
+	// ListQuarantinedChannels lists quarantined channels
+	ListQuarantinedChannels(ctx context.Context) []QuarantineEntry
+	// TraceChannel logs the processing of the channel in detail for the TTL, failing with ErrTooManyDebugTraces
+	// when too many channels are traced
+	TraceChannel(ctx context.Context, id uuid.UUID, ttl time.Duration) (DebugTrace, error)
+	// UntraceChannel stops tracing the channel, returning false if it was not traced
+	UntraceChannel(ctx context.Context, id uuid.UUID) bool
+	// ListTracedChannels lists traced channels
+	ListTracedChannels(ctx context.Context) []DebugTrace
+	// ListDeadLetters lists the messages that could not be applied, of the channel or of all channels for uuid.Nil
+	ListDeadLetters(ctx context.Context, channel uuid.UUID) []DeadLetter
+	// RedriveDeadLetters processes the dead letters of the channel, or of all channels for uuid.Nil, again
+	RedriveDeadLetters(ctx context.Context, channel uuid.UUID) RedriveReport
+	// ClearDeadLetters drops the dead letters of the channel, or of all channels for uuid.Nil, returning their number
+	ClearDeadLetters(ctx context.Context, channel uuid.UUID) int
+	// CheckConsistency verifies the stored states against their invariants and event history, optionally fixing them
+	CheckConsistency(ctx context.Context, fix bool) ConsistencyReport
+	// GetProcessingStats returns what processing did with the messages of the channel since the service started
+	GetProcessingStats(ctx context.Context, id uuid.UUID) (ProcessingStats, error)
+	// ListGaps returns the channels with message numbers that never arrived, open for at least minAge
+	ListGaps(ctx context.Context, minAge time.Duration) []ChannelGaps
+	// AcceptHandoff takes over the hot state of channels handed off by the replica that owned them before
+	AcceptHandoff(ctx context.Context, handoffs []Handoff) HandoffReport
+	// RegisterRocket registers the rocket ahead of its telemetry if the caller may manage the rockets of the
+	// missions of the registration, the rocket's current registration and its state. It returns ErrForbidden,
+	// or ErrRegistrationConflict when the rocket already reports another type or mission.
+	RegisterRocket(ctx context.Context, id uuid.UUID, typ RocketType, mission Mission, allowed func(Mission) bool) (RegisterResult, error)
+}
+
+// Listener - receives notifications about messages applied to rocket state
+type Listener interface {
+	// StateChanged is called after a message has been applied and the new state saved
+	StateChanged(ctx context.Context, msg TelemetryMessage, prev, next State)
+}
+
+// HeartbeatListener - listener also notified about the heartbeats left out of the history, which only advance
+// the liveness of the state without a new version and are not passed to StateChanged
+type HeartbeatListener interface {
+	// Heartbeat is called after the heartbeat has been applied and the new state saved
+	Heartbeat(ctx context.Context, msg TelemetryMessage, next State)
+}
+
+// replayKey - context key of the messages passed to the listeners that were not ingested live
+type replayKey struct{}
+
+// replaying marks the context of the messages that are not ingested live: redriven dead letters, handed off
+// messages, the late launch back-filling a provisional state and a late message recomputing the later ones
+func replaying(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replayKey{}, true)
+}
+
+// Live reports whether the message passed to a listener was ingested live rather than redriven, handed off or
+// replayed, for the listeners measuring the ingestion
+func Live(ctx context.Context) bool {
+	return ctx.Value(replayKey{}) == nil
+}
+
+// Rewrite - why a state was written without applying a message
+type Rewrite string
+
+const (
+	RewriteRollback Rewrite = "rollback"
+	RewriteBackfill Rewrite = "backfill"
+	RewriteMerge    Rewrite = "merge"
+	RewriteClone    Rewrite = "clone"
+	RewriteHandoff  Rewrite = "handoff"
+	RewriteFix      Rewrite = "fix"
+)
+
+// RewriteListener - listener also notified about the states written without applying a message, e.g. by a
+// rollback, a merge or a consistency fix, so it can resync the rocket instead of diverging from the store
+type RewriteListener interface {
+	// StateRewritten is called after the state has been saved, prev is zero for a new rocket and next is zero
+	// for a removed one
+	StateRewritten(ctx context.Context, cause Rewrite, prev, next State)
 }
 
 var _ Service = (*ServiceImpl)(nil)
 
 // ServiceImpl - implementation of the rocket service
 type ServiceImpl struct {
-	store  Store
-	logger *zap.Logger
+	store      Store
+	history    HistoryStore
+	quarantine *quarantine
+	traces     *debugTraces
+	dead       *deadLetters
+	rates      *MessageRates
+	stats      *processingStats
+	gaps       *gapTracker
+	reorder    *reorder
+	clock      clock.Clock
+	logger     *zap.Logger
+	listeners  []Listener
+	locks      [lockStripes]sync.Mutex
+	actors     *actors
+	tracer     *tracing.Tracer
+	rules      rules
+	// busy - updates in progress of the lock stripes, for Stalled
+	busy [lockStripes]busy
+	// registrations - rockets registered ahead of their telemetry, their launches are checked against them
+	registrations *Registrations
+	// pins - channels and missions whose gaps wait without a time limit
+	pins *Pins
+	// retransmitter - asks the producers for the gaps open for longer than retransmitAfter, nil to not ask
+	retransmitter   Retransmitter
+	retransmitAfter time.Duration
+	// retransmitSlots - requests in flight, retransmits - to wait for them
+	retransmitSlots chan struct{}
+	retransmits     sync.WaitGroup
+	// heartbeats - heartbeats are recorded in the history like the other messages
+	heartbeats bool
+	// unnumbered - channels and producers whose messages without numbers are numbered on arrival
+	unnumbered *Unnumbered
+	dedup      *dedup
+	// owns - whether the replica owns the channel, nil when it owns every channel
+	owns func(channel uuid.UUID) bool
 }
 
 // NewRocketService creates a new instance of the rocket service with the provided store and logger.
 func NewRocketService(store Store, logger *zap.Logger) *ServiceImpl {
 	return &ServiceImpl{
-		store:  store,
-		logger: logger,
+		store:      store,
+		quarantine: newQuarantine(),
+		traces:     newDebugTraces(defaultMaxDebugTraces, defaultDebugTraceTTL),
+		dead:       newDeadLetters(0),
+		stats:      newProcessingStats(),
+		gaps:       newGapTracker(),
+		clock:      clock.Real{},
+		logger:     logger,
+		rules:      rules{underflow: UnderflowClamp},
+		dedup:      newDedup(DedupGapTolerant, 0, nil),
+
+		registrations: NewRegistrations(),
+	}
+}
+
+// AddListener registers a listener notified about every applied message.
+// Listeners must be registered before the service starts processing messages.
+func (s *ServiceImpl) AddListener(l Listener) {
+	s.listeners = append(s.listeners, l)
+}
+
+// UseHistory enables recording every applied message as a versioned event, which makes rollbacks possible.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseHistory(history HistoryStore) {
+	s.history = history
+}
+
+// AutoQuarantine enables quarantining a channel after the given number of consecutive validation failures.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) AutoQuarantine(failures int) {
+	s.quarantine.threshold = failures
+}
+
+// UseDebugTraces sets the number of channels that can be traced at the same time and the default and longest
+// time a channel is traced; see TraceChannel.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseDebugTraces(max int, ttl time.Duration) {
+	s.traces = newDebugTraces(max, ttl)
+}
+
+// UseDeadLetters keeps up to size messages that failed validation or arrived while their channel was quarantined,
+// dropping the oldest ones, so they can be redriven once the cause is fixed; see RedriveDeadLetters.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseDeadLetters(size int) {
+	s.dead = newDeadLetters(size)
+}
+
+// UseMessageRates counts every message arriving, whatever processing does with it, in the rates.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseMessageRates(rates *MessageRates) {
+	s.rates = rates
+}
+
+// UseReorderBuffer enables holding messages received ahead of a gap in their channel's sequence until the
+// missing ones arrive, so they are applied in order. A gap is skipped when more than window messages are held
+// for the channel or it stays open for maxWait (0 waits forever); see RunReorderJanitor.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseReorderBuffer(window int, maxWait time.Duration) {
+	s.reorder = newReorder(window, maxWait)
+}
+
+// UseClock replaces the system clock used for quarantine times and reorder deadlines, e.g. with a fake one in tests.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// UseActors serializes the updates of every rocket on its own goroutine draining a mailbox of the given size,
+// instead of locking, so the order of updates per channel is the order they were queued in. An actor exits after
+// staying idle for idleTimeout and is spawned again on the next update.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseActors(mailbox int, idleTimeout time.Duration) {
+	s.actors = newActors(mailbox, idleTimeout)
+}
+
+// UseUnderflowPolicy sets how a speed decrease below zero is handled, the speed is clamped to zero by default.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseUnderflowPolicy(policy UnderflowPolicy) {
+	s.rules.underflow = policy
+}
+
+// UseProvisionalState applies the messages of a rocket arriving before its launch message to a PARTIAL state
+// holding what they tell, and remembers them in the state, so they are persisted with it. When the launch
+// message arrives late, even numbered before messages already processed, the state is back-filled: the launch
+// is applied first and the remembered messages following it on top.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseProvisionalState() {
+	s.rules.provisional = true
+}
+
+// UseHeartbeatHistory records the heartbeats in the history, which leaves them out by default so a rocket
+// coasting for hours doesn't push its meaningful events out of it. Must be called before the service starts
+// processing messages.
+func (s *ServiceImpl) UseHeartbeatHistory() {
+	s.heartbeats = true
+}
+
+// UseDedupPolicy checks the numbers of the messages with the policy instead of the gap-tolerant one. The window
+// is the number of messages below the highest one windowed-exact tracks. The report is called with the class of
+// every message the policy doesn't apply, e.g. to count them. Must be called before the service starts processing
+// messages.
+func (s *ServiceImpl) UseDedupPolicy(policy DedupPolicy, window int, report func(DedupClass)) {
+	s.dedup = newDedup(policy, window, report)
+}
+
+// UseUnnumbered accepts messages without numbers from the channels and producers of the registry, ordered by
+// their message time and deduplicated by their content. Must be called before the service starts processing
+// messages.
+func (s *ServiceImpl) UseUnnumbered(unnumbered *Unnumbered) {
+	s.unnumbered = unnumbered
+}
+
+// UseRegistrations rejects the launch messages of registered rockets reporting another type or mission than
+// registered, and registers the rockets in the registry. Must be called before the service starts processing
+// messages.
+func (s *ServiceImpl) UseRegistrations(registrations *Registrations) {
+	s.registrations = registrations
+}
+
+// UsePins exempts the gaps of the pinned channels and missions from the maximum wait of the reorder buffer, so
+// a rocket going silent for longer doesn't have its gap skipped. Must be called before the service starts
+// processing messages.
+func (s *ServiceImpl) UsePins(pins *Pins) {
+	s.pins = pins
+}
+
+// pinned reports whether the channel or the mission of its rocket is pinned
+func (s *ServiceImpl) pinned(id uuid.UUID) bool {
+	if s.pins == nil {
+		return false
+	}
+	state, _ := s.store.GetRocketByID(id)
+	return s.pins.Pinned(id, state.Mission)
+}
+
+// UseOwner rejects the messages of the channels the replica doesn't own with ErrChannelMoved, checked under the
+// lock of the channel, so a message that passed the routing before its channel was handed off is not applied
+// after the handoff took the state. Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseOwner(owns func(channel uuid.UUID) bool) {
+	s.owns = owns
+}
+
+// moved reports whether the channel is owned by another replica. Must be called from exclusive.
+func (s *ServiceImpl) moved(id uuid.UUID) bool {
+	return s.owns != nil && !s.owns(id)
+}
+
+// UseTracer records a span for every processed message, a child of the span carried by the request context,
+// so the latency from the producer to the applied state is visible in the trace.
+// Must be called before the service starts processing messages.
+func (s *ServiceImpl) UseTracer(tracer *tracing.Tracer) {
+	s.tracer = tracer
+}
+
+// RunReorderJanitor periodically skips the gaps that stayed open for too long, until the context is done.
+func (s *ServiceImpl) RunReorderJanitor(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			s.FlushOverdueMessages(ctx)
+		}
+	}
+}
+
+// FlushOverdueMessages applies the held messages of channels whose gaps are overdue, skipping the gaps.
+func (s *ServiceImpl) FlushOverdueMessages(ctx context.Context) {
+	if s.reorder == nil {
+		return
+	}
+	for _, id := range s.reorder.overdueChannels(s.clock.Now(), s.pinned) {
+		s.exclusive(id, func() {
+			s.release(ctx, s.channelLogger(s.logger, id), id, true)
+		})
+	}
+}
+
+// FlushHeldMessages applies all held messages skipping the gaps, e.g. before shutting down.
+func (s *ServiceImpl) FlushHeldMessages(ctx context.Context) {
+	if s.reorder == nil {
+		return
+	}
+	for _, id := range s.reorder.channels() {
+		s.exclusive(id, func() {
+			s.release(ctx, s.channelLogger(s.logger, id), id, true)
+		})
+	}
+}
+
+// channelLogger returns the logger for processing the messages of the channel, logging at all levels
+// while the channel is traced
+func (s *ServiceImpl) channelLogger(logger *zap.Logger, id uuid.UUID) *zap.Logger {
+	if !s.traces.traced(id, s.clock.Now()) {
+		return logger
 	}
+	return logging.Verbose(logger).With(zap.Bool("debug_trace", true))
+}
+
+// exclusive runs fn serialized with the other state updates of the rocket, on its actor when actors are used
+func (s *ServiceImpl) exclusive(id uuid.UUID, fn func()) {
+	if s.actors != nil {
+		s.actors.do(id, fn)
+		return
+	}
+	m := &s.locks[id[0]]
+	m.Lock()
+	defer m.Unlock()
+	s.busy[id[0]].begin()
+	defer s.busy[id[0]].end()
+	fn()
 }
 
 // ProcessMessage processes a telemetry message and updates the rocket state accordingly
-func (s *ServiceImpl) ProcessMessage(_ context.Context, msg TelemetryMessage) error {
-	s.logger.Info(
-		"Processing message",
-		zap.String("channel", msg.Metadata.Channel.String()),
-		zap.String("type", string(msg.Metadata.MessageType)),
-		zap.Int64("number", msg.Metadata.MessageNumber),
+func (s *ServiceImpl) ProcessMessage(ctx context.Context, msg TelemetryMessage) (Result, error) {
+	return s.process(ctx, msg, false)
+}
+
+// process processes the message, a redriven dead letter when redrive is set: it is not counted towards the
+// quarantine of its channel, so redriving the failures of a channel doesn't quarantine it again nor resets the
+// failures of its producer
+func (s *ServiceImpl) process(ctx context.Context, msg TelemetryMessage, redrive bool) (Result, error) {
+	ctx, span := s.tracer.Start(ctx, "rocket.ProcessMessage", tracing.KindInternal)
+	defer span.Finish()
+	rocketID := msg.Metadata.Channel
+	logger := s.channelLogger(logging.FromContext(ctx, s.logger), rocketID)
+	span.SetAttribute("rocket.id", rocketID.String())
+	span.SetAttribute("message.number", msg.Metadata.MessageNumber)
+	span.SetAttribute("message.type", string(msg.Metadata.MessageType))
+	if !msg.Metadata.MessageTime.IsZero() {
+		// time from the producer stamping the message to it being processed
+		span.SetAttribute("message.age_ms", s.clock.Now().Sub(msg.Metadata.MessageTime).Milliseconds())
+	}
+	ignored := s.quarantine.quarantined(rocketID)
+	if !redrive {
+		s.rates.Count(rocketID)
+		ignored = s.quarantine.ignore(rocketID)
+	}
+	if ignored {
+		logger.Warn("Message from quarantined channel accepted but not applied",
+			logging.Event(logging.EventMessageIgnored),
+			zap.String("rocket_id", rocketID.String()),
+			zap.Int64("msg_num", msg.Metadata.MessageNumber),
+			zap.Any("message", msg),
+		)
+		s.dead.add(msg, "channel is quarantined", s.clock.Now())
+		s.stats.record(msg, OutcomeIgnored, "", s.clock.Now())
+		current, _ := s.store.GetRocketByID(rocketID)
+		return Result{Outcome: OutcomeIgnored, Version: current.Version, Warnings: []string{"channel is quarantined, the message is not applied"}}, nil
+	}
+
+	if err := s.validate(msg); err != nil {
+		logger.Warn("Invalid message rejected",
+			logging.Event(logging.EventMessageInvalid),
+			zap.String("rocket_id", rocketID.String()),
+			zap.Int64("msg_num", msg.Metadata.MessageNumber),
+			zap.Error(err),
+		)
+		span.Fail(err)
+		s.dead.add(msg, err.Error(), s.clock.Now())
+		s.stats.record(msg, OutcomeRejected, err.Error(), s.clock.Now())
+		if !redrive && s.quarantine.fail(rocketID, err.Error(), s.clock.Now()) {
+			logger.Warn("Channel quarantined after repeated validation failures",
+				logging.Event(logging.EventChannelQuarantined),
+				zap.String("rocket_id", rocketID.String()),
+				zap.Error(err),
+			)
+		}
+		return Result{Outcome: OutcomeRejected}, err
+	}
+	if !redrive {
+		s.quarantine.succeed(rocketID)
+	}
+	logger.Debug("Message valid", zap.String("rocket_id", rocketID.String()), zap.Int64("msg_num", msg.Metadata.MessageNumber), zap.Any("message", msg))
+	logger.Info("Processing message",
+		logging.Event(logging.EventMessageAccepted),
+		zap.String("rocket_id", rocketID.String()),
+		zap.Int64("msg_num", msg.Metadata.MessageNumber),
+		zap.String("msg_type", string(msg.Metadata.MessageType)),
 	)
 
+	var result Result
+	var err error
+	s.exclusive(rocketID, func() {
+		if s.moved(rocketID) {
+			result, err = Result{Outcome: OutcomeRejected}, fmt.Errorf("%w: %s", ErrChannelMoved, rocketID)
+			return
+		}
+		result, err = s.processMessage(ctx, logger, msg)
+	})
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+	s.stats.record(msg, result.Outcome, reason, s.clock.Now())
+	return result, err
+}
+
+// validate checks the message and the launch of a registered rocket against its registration. A message without
+// a number of an unnumbered channel is checked as numbered, it is numbered when applied.
+func (s *ServiceImpl) validate(msg TelemetryMessage) error {
+	if _, ok := s.unnumbered.Window(msg.Metadata); ok {
+		if msg.Metadata.MessageTime.IsZero() {
+			return fmt.Errorf("%w: message time is required to order messages without numbers", ErrInvalidMessage)
+		}
+		msg.Metadata.MessageNumber = 1
+	}
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	return s.registrations.Check(msg)
+}
+
+// action - what processing does with a message
+type action int
+
+const (
+	// actionApply - the message is applied on top of the current state
+	actionApply action = iota
+	// actionBackfill - the late launch of a provisional state back-fills it
+	actionBackfill
+	// actionGap - the message ahead of a gap is rejected by the strict dedup policy
+	actionGap
+	// actionDuplicate - the stale, duplicate or conflicting message is not applied
+	actionDuplicate
+	// actionHold - the message ahead of a gap is held in the reorder buffer
+	actionHold
+	// actionUnderflow - the message decreasing the speed below zero is rejected by the reject policy
+	actionUnderflow
+	// actionRecompute - the late message is applied in its place, recomputing the messages after it
+	actionRecompute
+)
+
+// decision - what processing does with a message, decided from the current state of its rocket
+type decision struct {
+	action action
+	// class - why the message is not applied, of actionGap and actionDuplicate
+	class DedupClass
+	// late - the message of actionApply is numbered before messages applied already
+	late bool
+	// base, replayed, supersede - of actionRecompute, the state the late message and the ones after it are
+	// replayed on, in order, and the version the events superseded by them start at
+	base      State
+	replayed  []TelemetryMessage
+	supersede int64
+}
+
+// decide decides what processing does with the validated and numbered message, without changing anything, so
+// processing and the dry run take the same decisions
+func (s *ServiceImpl) decide(current State, exists bool, msg TelemetryMessage) decision {
+	if exists && lateLaunch(current, msg, s.rules) {
+		return decision{action: actionBackfill}
+	}
+	class, late := s.dedup.check(current, exists, msg)
+	switch {
+	case class == DedupGap:
+		return decision{action: actionGap, class: class}
+	case class != "":
+		return decision{action: actionDuplicate, class: class}
+	case late:
+		return s.decideLate(current, msg)
+	case s.reorder != nil && msg.Metadata.MessageNumber > current.LastProcessedMessageNumber+1:
+		return decision{action: actionHold}
+	case s.rejectsUnderflow(current, msg):
+		return decision{action: actionUnderflow}
+	}
+	return decision{action: actionApply}
+}
+
+// processMessage applies the validated message or holds it until the gap before it is filled.
+// Must be called from exclusive.
+func (s *ServiceImpl) processMessage(ctx context.Context, logger *zap.Logger, msg TelemetryMessage) (result Result, err error) {
 	rocketID := msg.Metadata.Channel
 	currentState, exists := s.store.GetRocketByID(rocketID)
+	if window, ok := s.unnumbered.Window(msg.Metadata); ok {
+		var reason string
+		if msg, reason, ok = s.number(msg, currentState, exists, window, true); !ok {
+			logger.Warn("Ignoring old or duplicate unnumbered message",
+				logging.Event(logging.EventMessageDuplicate),
+				zap.String("rocket_id", rocketID.String()),
+				zap.Int64("current_num", currentState.LastProcessedMessageNumber),
+				zap.String("reason", reason),
+			)
+			return Result{Outcome: OutcomeDuplicate, Version: currentState.Version, Warnings: []string{reason}}, nil
+		}
+	}
+	logger.Debug("Current state looked up",
+		zap.String("rocket_id", rocketID.String()),
+		zap.Int64("msg_num", msg.Metadata.MessageNumber),
+		zap.Bool("exists", exists),
+		zap.Int64("version", currentState.Version),
+		zap.Int64("current_num", currentState.LastProcessedMessageNumber),
+		zap.String("status", string(currentState.Status)),
+	)
 
-	// Check if the message is old or a duplicate
-	if exists && msg.Metadata.MessageNumber <= currentState.LastProcessedMessageNumber {
-		s.logger.Warn("Ignoring old or duplicate message",
+	if exists {
+		s.stats.arrived(rocketID, msg.Metadata.MessageNumber, currentState.LastProcessedMessageNumber, s.clock.Now())
+	}
+	s.gaps.arrived(rocketID, msg.Metadata.MessageNumber, currentState.LastProcessedMessageNumber, exists, s.clock.Now())
+	// the number of a late message is filled once the message made it into the state or the reorder buffer, not
+	// when it is dropped, e.g. a retransmitted copy arriving after the gap was skipped
+	number := msg.Metadata.MessageNumber
+	defer func() {
+		switch result.Outcome {
+		case OutcomeApplied, OutcomeBuffered, OutcomeBackfilled:
+			s.gaps.filled(rocketID, number)
+		}
+	}()
+	d := s.decide(currentState, exists, msg)
+	switch d.action {
+	case actionBackfill:
+		logger.Debug("Late launch of a provisional state, back-filling", zap.String("rocket_id", rocketID.String()), zap.Int64("msg_num", msg.Metadata.MessageNumber))
+		next := s.backfill(ctx, logger, currentState, msg)
+		return Result{
+			Outcome:  OutcomeBackfilled,
+			Version:  next.Version,
+			Warnings: []string{fmt.Sprintf("late launch back-filled the provisional state, replaying %d messages", len(currentState.Prelaunch))},
+		}, nil
+	case actionGap:
+		s.dedup.reject(d.class)
+		logger.Warn("Message ahead of a gap rejected by the strict dedup policy",
+			logging.Event(logging.EventMessageGapRejected),
+			zap.String("rocket_id", rocketID.String()),
+			zap.Int64("current_num", currentState.LastProcessedMessageNumber),
+			zap.Int64("msg_num", msg.Metadata.MessageNumber),
+		)
+		return Result{Outcome: OutcomeRejected, Version: currentState.Version}, fmt.Errorf("%w: message %d is ahead of message %d expected next", ErrSequenceGap, msg.Metadata.MessageNumber, currentState.LastProcessedMessageNumber+1)
+	case actionDuplicate:
+		s.dedup.reject(d.class)
+		text := "Ignoring old or duplicate message"
+		warning := fmt.Sprintf("message %d is not after the last processed message %d", msg.Metadata.MessageNumber, currentState.LastProcessedMessageNumber)
+		switch d.class {
+		case DedupDuplicate:
+			warning = fmt.Sprintf("message %d was applied already", msg.Metadata.MessageNumber)
+		case DedupConflict:
+			text = "Late message conflicting with later ones not applied"
+			warning = fmt.Sprintf("late message %d depends on the order of the messages applied after it and there is no history to recompute the state from", msg.Metadata.MessageNumber)
+		}
+		logger.Warn(text,
+			logging.Event(logging.EventMessageDuplicate),
 			zap.String("rocket_id", rocketID.String()),
 			zap.Int64("current_num", currentState.LastProcessedMessageNumber),
 			zap.Int64("msg_num", msg.Metadata.MessageNumber),
+			zap.String("class", string(d.class)),
 		)
-		return nil
+		return Result{Outcome: OutcomeDuplicate, Version: currentState.Version, Warnings: []string{warning}}, nil
+	case actionHold:
+		// Hold the message until its predecessors have arrived
+		now := s.clock.Now()
+		if s.reorder.hold(msg, now) {
+			logger.Info("Message held until the gap in the sequence is filled",
+				logging.Event(logging.EventMessageHeld),
+				zap.String("rocket_id", rocketID.String()),
+				zap.Int64("current_num", currentState.LastProcessedMessageNumber),
+				zap.Int64("msg_num", msg.Metadata.MessageNumber),
+			)
+		} else {
+			logger.Debug("Message already held", zap.String("rocket_id", rocketID.String()), zap.Int64("msg_num", msg.Metadata.MessageNumber))
+		}
+		overdue := s.reorder.overdue(rocketID, now, s.pins.Pinned(rocketID, currentState.Mission))
+		logger.Debug("Reorder buffer state",
+			zap.String("rocket_id", rocketID.String()),
+			zap.Int("held", s.reorder.size(rocketID)),
+			zap.Int("window", s.reorder.window),
+			zap.Bool("overdue", overdue),
+		)
+		if overdue {
+			s.release(ctx, logger, rocketID, true)
+			// the skipped gap applied the message along with the other held ones
+			if released, _ := s.store.GetRocketByID(rocketID); released.LastProcessedMessageNumber >= msg.Metadata.MessageNumber {
+				return Result{Outcome: OutcomeApplied, Version: released.Version, Warnings: []string{"the gap before the message was skipped"}}, nil
+			}
+		}
+		return Result{
+			Outcome:  OutcomeBuffered,
+			Version:  currentState.Version,
+			Warnings: []string{fmt.Sprintf("waiting for message %d", currentState.LastProcessedMessageNumber+1)},
+		}, nil
+	case actionUnderflow:
+		return s.rejectUnderflow(logger, currentState, msg)
+	case actionRecompute:
+		return s.recompute(ctx, logger, currentState, msg, d), nil
 	}
 
+	logger.Debug("Message not a duplicate",
+		zap.String("rocket_id", rocketID.String()),
+		zap.Int64("current_num", currentState.LastProcessedMessageNumber),
+		zap.Int64("msg_num", msg.Metadata.MessageNumber),
+	)
+	next, warnings := s.applyMessage(ctx, logger, currentState, exists, msg)
+	if d.late {
+		warnings = append(warnings, fmt.Sprintf("late message %d applied after message %d", msg.Metadata.MessageNumber, currentState.LastProcessedMessageNumber))
+	} else if s.reorder != nil {
+		s.release(ctx, logger, rocketID, false)
+	}
+	return Result{Outcome: OutcomeApplied, Version: next.Version, Warnings: warnings}, nil
+}
+
+// release applies the held messages following the current state of the rocket. With skipGap the gaps
+// in the sequence are skipped, applying all held messages. Must be called from exclusive.
+func (s *ServiceImpl) release(ctx context.Context, logger *zap.Logger, id uuid.UUID, skipGap bool) {
+	for {
+		current, exists := s.store.GetRocketByID(id)
+		msg, ok := s.reorder.next(id, current.LastProcessedMessageNumber, skipGap, s.clock.Now())
+		if !ok {
+			logger.Debug("No held message to release",
+				zap.String("rocket_id", id.String()),
+				zap.Int64("current_num", current.LastProcessedMessageNumber),
+				zap.Int("held", s.reorder.size(id)),
+				zap.Bool("skip_gap", skipGap),
+			)
+			return
+		}
+		logger.Debug("Releasing held message",
+			zap.String("rocket_id", id.String()),
+			zap.Int64("msg_num", msg.Metadata.MessageNumber),
+			zap.Int("held", s.reorder.size(id)),
+		)
+		if msg.Metadata.MessageNumber != current.LastProcessedMessageNumber+1 {
+			logger.Warn("Skipping gap in the message sequence",
+				logging.Event(logging.EventMessageGapSkipped),
+				zap.String("rocket_id", id.String()),
+				zap.Int64("current_num", current.LastProcessedMessageNumber),
+				zap.Int64("msg_num", msg.Metadata.MessageNumber),
+			)
+		}
+		if s.rejectsUnderflow(current, msg) {
+			// its number stays open like a gap, the held messages after it wait for it to be skipped
+			_, _ = s.rejectUnderflow(logger, current, msg)
+			continue
+		}
+		s.applyMessage(ctx, logger, current, exists, msg)
+	}
+}
+
+// applyMessage applies the validated message to the current state, saves and records the new state
+// and notifies the listeners. It returns the new state and warnings about the message.
+// Must be called from exclusive.
+func (s *ServiceImpl) applyMessage(ctx context.Context, logger *zap.Logger, currentState State, exists bool, msg TelemetryMessage) (State, []string) {
+	rocketID := msg.Metadata.Channel
 	newState := currentState
 	if !exists {
-		s.logger.Info("New rocket detected", zap.String("id", rocketID.String()))
+		logger.Info("New rocket detected", logging.Event(logging.EventStateCreated), zap.String("rocket_id", rocketID.String()))
 		newState = State{
 			ID:     rocketID,
-			Status: "UNKNOWN",
+			Status: StatusUnknown,
+		}
+	}
+
+	newState, underflow := apply(newState, msg, s.rules)
+	if exists && s.silent(msg) {
+		// the version counts the events the state can be folded from, so it doesn't change with the liveness
+		newState.Version = currentState.Version
+	} else {
+		newState.Version = currentState.Version + 1
+	}
+	logger.Debug("Message applied", zap.String("rocket_id", rocketID.String()), zap.Any("prev", currentState), zap.Any("next", newState))
+	var warnings []string
+	if underflow {
+		warnings = append(warnings, fmt.Sprintf("speed %d decreased by %d below zero, handled by the %s policy", currentState.CurrentSpeed, *msg.Message.By, s.rules.underflow))
+		logger.Warn("Speed decrease below zero",
+			logging.Event(logging.EventSpeedUnderflow),
+			zap.String("rocket_id", rocketID.String()),
+			zap.Int64("msg_num", msg.Metadata.MessageNumber),
+			zap.Int64("speed", int64(currentState.CurrentSpeed)),
+			zap.Int64("by", int64(*msg.Message.By)),
+			zap.String("policy", string(s.rules.underflow)),
+		)
+	}
+
+	s.record(ctx, currentState, exists, newState, msg)
+	logger.Info(
+		"Rocket state updated successfully",
+		logging.Event(logging.EventStateTransition),
+		zap.String("rocket_id", rocketID.String()),
+		zap.Int64("msg_num", msg.Metadata.MessageNumber),
+		zap.Int64("version", newState.Version),
+		zap.String("prev_status", string(currentState.Status)),
+		zap.String("status", string(newState.Status)),
+		zap.Int64("speed", int64(newState.CurrentSpeed)),
+	)
+	switch newState.Status {
+	case StatusPartial:
+		warnings = append(warnings, "the launch of the rocket was not received yet, its state is provisional")
+		if len(currentState.Prelaunch) >= maxPrelaunch {
+			warnings = append(warnings, fmt.Sprintf("%d messages before the launch are remembered already, the back-fill won't replay this one", maxPrelaunch))
+		}
+	case StatusUnknown:
+		warnings = append(warnings, "the launch of the rocket was not received yet")
+	}
+	return newState, warnings
+}
+
+// backfill applies the late launch message of a rocket with a provisional state, replaying the messages
+// remembered since, and returns the new state. Must be called from exclusive.
+func (s *ServiceImpl) backfill(ctx context.Context, logger *zap.Logger, currentState State, msg TelemetryMessage) State {
+	newState := backfill(currentState, msg, s.rules)
+	newState.Version = currentState.Version + 1
+
+	s.record(replaying(ctx), currentState, true, newState, msg)
+	logger.Info("Provisional state back-filled with the late launch",
+		logging.Event(logging.EventStateBackfill),
+		zap.String("rocket_id", currentState.ID.String()),
+		zap.Int64("msg_num", msg.Metadata.MessageNumber),
+		zap.Int64("version", newState.Version),
+		zap.Int("replayed", len(currentState.Prelaunch)),
+		zap.String("status", string(newState.Status)),
+		zap.Int64("speed", int64(newState.CurrentSpeed)),
+	)
+	return newState
+}
+
+// record saves the new state, appends it to the history and notifies the listeners
+func (s *ServiceImpl) record(ctx context.Context, currentState State, exists bool, newState State, msg TelemetryMessage) {
+	s.save(currentState, exists, newState)
+	s.dedup.applied(msg, currentState.LastProcessedMessageNumber)
+	if s.silent(msg) {
+		s.notifyHeartbeat(ctx, msg, newState)
+		return
+	}
+	if s.history != nil {
+		s.history.AppendEvent(Event{Message: msg, State: newState})
+	}
+	s.notify(ctx, msg, currentState, newState)
+}
+
+// silent reports whether the message is a heartbeat left out of the history
+func (s *ServiceImpl) silent(msg TelemetryMessage) bool {
+	return msg.Metadata.MessageType == MessageTypeHeartbeat && !s.heartbeats
+}
+
+// notifyHeartbeat passes the heartbeat left out of the history to the listeners following the liveness
+func (s *ServiceImpl) notifyHeartbeat(ctx context.Context, msg TelemetryMessage, next State) {
+	for _, l := range s.listeners {
+		if hl, ok := l.(HeartbeatListener); ok {
+			hl.Heartbeat(ctx, msg, next)
+		}
+	}
+}
+
+// notify passes the message applied to the state to the listeners
+func (s *ServiceImpl) notify(ctx context.Context, msg TelemetryMessage, prev, next State) {
+	for _, l := range s.listeners {
+		l.StateChanged(ctx, msg, prev, next)
+	}
+}
+
+// rewrite saves the state written without applying a message and notifies the listeners resyncing on rewrites.
+// Every state write other than record goes through it or remove, so no listener misses a change of the store.
+func (s *ServiceImpl) rewrite(ctx context.Context, cause Rewrite, prev, next State) {
+	s.store.SaveRocket(next)
+	s.notifyRewrite(ctx, cause, prev, next)
+}
+
+// remove deletes the state of the rocket and notifies the listeners resyncing on rewrites
+func (s *ServiceImpl) remove(ctx context.Context, cause Rewrite, prev State) {
+	s.store.DeleteRocket(prev.ID)
+	s.notifyRewrite(ctx, cause, prev, State{})
+}
+
+func (s *ServiceImpl) notifyRewrite(ctx context.Context, cause Rewrite, prev, next State) {
+	for _, l := range s.listeners {
+		if rl, ok := l.(RewriteListener); ok {
+			rl.StateRewritten(ctx, cause, prev, next)
 		}
 	}
+}
 
-	newState.LastProcessedMessageNumber = msg.Metadata.MessageNumber
-	newState.LastUpdateTime = msg.Metadata.MessageTime
+// save persists the new state of the rocket, writing only the changed fields when the rocket already exists
+func (s *ServiceImpl) save(current State, exists bool, next State) {
+	if exists {
+		if delta, ok := Diff(current, next); ok {
+			if _, ok := s.store.ApplyDelta(next.ID, delta); ok {
+				return
+			}
+		}
+	}
+	s.store.SaveRocket(next)
+}
+
+// apply returns the state changed by the validated message according to the rules, and whether the speed
+// underflowed. The version is left to the caller. The last processed number and the last update time never go
+// back, so historical messages merged by time don't reopen the numbers already processed and late messages
+// don't make the state look older.
+func apply(state State, msg TelemetryMessage, r rules) (State, bool) {
+	state.LastProcessedMessageNumber = max(state.LastProcessedMessageNumber, msg.Metadata.MessageNumber)
+	if msg.Metadata.MessageTime.After(state.LastUpdateTime) {
+		state.LastUpdateTime = msg.Metadata.MessageTime
+	}
+	if msg.Metadata.Unnumbered {
+		state.LastUnnumberedHash = contentHash(msg)
+	}
 
 	switch msg.Metadata.MessageType {
+	case MessageTypeHeartbeat:
+		// nothing to remember for the back-fill of a provisional state
+		return state, false
 	case MessageTypeLaunched:
-		newState.Type = *msg.Message.Type
-		newState.CurrentSpeed = *msg.Message.LaunchSpeed
-		newState.Mission = *msg.Message.Mission
-		newState.Status = StatusLaunched
+		state.Type = *msg.Message.Type
+		state.CurrentSpeed = *msg.Message.LaunchSpeed
+		state.Mission = *msg.Message.Mission
+		state.Status = StatusLaunched
 	case MessageTypeSpeedIncreased:
-		newState.CurrentSpeed += *msg.Message.By
+		state.CurrentSpeed += *msg.Message.By
 	case MessageTypeSpeedDecreased:
-		newState.CurrentSpeed -= *msg.Message.By
+		var underflow bool
+		state, underflow = decreaseSpeed(state, msg, r.underflow)
+		return provisional(state, msg, r), underflow
 	case MessageTypeExploded:
-		newState.CurrentSpeed = 0
-		newState.Status = StatusExploded
-		newState.Reason = msg.Message.Reason
+		state.CurrentSpeed = 0
+		state.Status = StatusExploded
+		state.Reason = ClassifyReason(msg.Message.Reason)
 	case MessageTypeMissionChanged:
-		newState.Mission = *msg.Message.NewMission
+		state.Mission = *msg.Message.NewMission
 	}
-
-	s.store.SaveRocket(newState)
-	s.logger.Info(
-		"Rocket state updated successfully",
-		zap.String("rocket_id", rocketID.String()),
-		zap.Any("new_speed", newState.CurrentSpeed),
-		zap.String("new_status", string(newState.Status)),
-	)
-	return nil
+	return provisional(state, msg, r), false
 }
 
 // GetRocketState retrieves the current state of a rocket by its ID
-func (s *ServiceImpl) GetRocketState(_ context.Context, id uuid.UUID) (State, bool) {
-	return s.store.GetRocketByID(id)
+func (s *ServiceImpl) GetRocketState(ctx context.Context, id uuid.UUID) (State, error) {
+	if err := ctx.Err(); err != nil {
+		return State{}, fmt.Errorf("can't read rocket state: %w", err)
+	}
+	state, ok := s.store.GetRocketByID(id)
+	if !ok {
+		return State{}, ErrRocketNotFound
+	}
+	return state, nil
 }
 
-// ListAllRockets lists all rockets, optionally sorted by a specified field and order
-func (s *ServiceImpl) ListAllRockets(_ context.Context, sortBy, sortOrder string) []State {
-	rockets := s.store.ListAllRockets()
+// RollbackRocket restores the state of a rocket prior to the given message, superseding it and all later messages.
+// The restored state gets a new version, so the rollback itself is visible to readers as a regular update.
+func (s *ServiceImpl) RollbackRocket(ctx context.Context, id uuid.UUID, messageNumber int64) (restored State, err error) {
+	if s.history == nil {
+		return State{}, ErrHistoryDisabled
+	}
+
+	s.exclusive(id, func() {
+		restored, err = s.rollback(ctx, id, messageNumber)
+	})
+	return restored, err
+}
 
-	if sortBy == "" {
-		return rockets // No sorting
+// rollback restores the state prior to the message. Must be called from exclusive.
+func (s *ServiceImpl) rollback(ctx context.Context, id uuid.UUID, messageNumber int64) (State, error) {
+	current, ok := s.store.GetRocketByID(id)
+	if !ok {
+		return State{}, ErrRocketNotFound
 	}
 
-	sort.Slice(rockets, func(i, j int) bool {
-		var less bool
-		switch strings.ToLower(sortBy) {
-		case "id":
-			less = rockets[i].ID.String() < rockets[j].ID.String()
-		case "type":
-			less = rockets[i].Type < rockets[j].Type
-		case "speed":
-			less = rockets[i].CurrentSpeed < rockets[j].CurrentSpeed
-		case "mission":
-			less = rockets[i].Mission < rockets[j].Mission
-		case "lastupdatetime":
-			less = rockets[i].LastUpdateTime.Before(rockets[j].LastUpdateTime)
-		default:
-			return i < j // Stable sort if unknown sort by field
+	events := s.history.ListEvents(id)
+	target := -1
+	for i, event := range events {
+		if !event.Superseded && event.Message.Metadata.MessageNumber == messageNumber {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		return State{}, ErrMessageNotFound
+	}
+	prior := -1
+	for i := target - 1; i >= 0; i-- {
+		if !events[i].Superseded {
+			prior = i
+			break
 		}
+	}
+	if prior < 0 {
+		return State{}, ErrNoPriorState
+	}
+
+	restored := events[prior].State
+	restored.Version = current.Version + 1
+	s.rewrite(ctx, RewriteRollback, current, restored)
+	superseded := s.history.SupersedeEvents(id, events[target].State.Version)
+	s.dedup.forget(id)
 
-		if strings.ToLower(sortOrder) == "desc" {
-			return !less
+	s.logger.Warn("Rocket state rolled back",
+		logging.Event(logging.EventStateRollback),
+		zap.String("rocket_id", id.String()),
+		zap.Int64("msg_num", messageNumber),
+		zap.Int64("restored_msg_num", restored.LastProcessedMessageNumber),
+		zap.Int("superseded_events", superseded),
+	)
+	return restored, nil
+}
+
+// QuarantineChannel stops applying messages of the channel to the rocket state until it is released
+func (s *ServiceImpl) QuarantineChannel(_ context.Context, id uuid.UUID, reason string) QuarantineEntry {
+	entry := s.quarantine.add(id, reason, s.clock.Now())
+	s.logger.Warn("Channel quarantined", logging.Event(logging.EventChannelQuarantined), zap.String("rocket_id", id.String()), zap.String("reason", reason))
+	return entry
+}
+
+// ReleaseChannel releases the channel from quarantine, returning false if it was not quarantined
+func (s *ServiceImpl) ReleaseChannel(_ context.Context, id uuid.UUID) bool {
+	released := s.quarantine.release(id)
+	if released {
+		s.logger.Info("Channel released from quarantine", logging.Event(logging.EventChannelReleased), zap.String("rocket_id", id.String()))
+	}
+	return released
+}
+
+// ListQuarantinedChannels lists quarantined channels, oldest first
+func (s *ServiceImpl) ListQuarantinedChannels(_ context.Context) []QuarantineEntry {
+	return s.quarantine.list()
+}
+
+// ListAllRockets lists the rockets answering the query. The store answers it, so filtered listings are served
+// from its secondary indexes and don't scan all rockets.
+func (s *ServiceImpl) ListAllRockets(ctx context.Context, query ListQuery) ([]State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("can't list rockets: %w", err)
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	return s.store.QueryRockets(query), nil
+}
+
+// TraceChannel logs the processing of the channel in detail for the TTL, the default one when zero
+func (s *ServiceImpl) TraceChannel(_ context.Context, id uuid.UUID, ttl time.Duration) (DebugTrace, error) {
+	entry, err := s.traces.add(id, ttl, s.clock.Now())
+	if err != nil {
+		return DebugTrace{}, err
+	}
+	s.logger.Info("Channel traced", zap.String("rocket_id", id.String()), zap.Time("expires", entry.Expires))
+	return entry, nil
+}
+
+// UntraceChannel stops tracing the channel, returning false if it was not traced
+func (s *ServiceImpl) UntraceChannel(_ context.Context, id uuid.UUID) bool {
+	untraced := s.traces.remove(id)
+	if untraced {
+		s.logger.Info("Channel no longer traced", zap.String("rocket_id", id.String()))
+	}
+	return untraced
+}
+
+// ListTracedChannels lists traced channels, oldest first
+func (s *ServiceImpl) ListTracedChannels(_ context.Context) []DebugTrace {
+	return s.traces.list(s.clock.Now())
+}
+
+// ListDeadLetters lists the dead letters of the channel, of all channels for uuid.Nil, oldest first
+func (s *ServiceImpl) ListDeadLetters(_ context.Context, channel uuid.UUID) []DeadLetter {
+	return s.dead.list(channel)
+}
+
+// RedriveDeadLetters takes the dead letters of the channel, of all channels for uuid.Nil, and processes them again
+// in the order of their channels and numbers, e.g. after a fix of the validation or releasing a quarantined
+// channel. Messages failing again are queued again as new dead letters.
+func (s *ServiceImpl) RedriveDeadLetters(ctx context.Context, channel uuid.UUID) RedriveReport {
+	letters := s.dead.take(channel)
+	report := RedriveReport{Redriven: len(letters)}
+	for _, letter := range letters {
+		result, err := s.process(replaying(ctx), letter.Message, true)
+		if err != nil || result.Outcome == OutcomeIgnored {
+			report.Failed++
+			continue
 		}
-		return less
-	})
+		report.Applied++
+	}
+	s.logger.Info("Dead letters redriven",
+		zap.Int("redriven", report.Redriven),
+		zap.Int("applied", report.Applied),
+		zap.Int("failed", report.Failed),
+	)
+	return report
+}
 
-	return rockets
+// ClearDeadLetters drops the dead letters of the channel, of all channels for uuid.Nil, returning their number
+func (s *ServiceImpl) ClearDeadLetters(_ context.Context, channel uuid.UUID) int {
+	return len(s.dead.take(channel))
 }
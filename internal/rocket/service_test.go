@@ -2,9 +2,12 @@ package rocket
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"reflect"
+	"rockets/internal/logging"
 	"sort"
 	"testing"
 	"time"
@@ -30,13 +33,13 @@ func TestRocketService_ProcessMessage_RocketLaunched_Integration(t *testing.T) {
 			MessageType:   MessageTypeLaunched,
 		},
 		Message: Message{
-			Type:        ptr("IntegrationRocket"),
-			LaunchSpeed: ptr(int64(1000)),
-			Mission:     ptr("IntegrationLaunch"),
+			Type:        ptr(RocketType("IntegrationRocket")),
+			LaunchSpeed: ptr(Speed(1000)),
+			Mission:     ptr(Mission("IntegrationLaunch")),
 		},
 	}
 
-	err := service.ProcessMessage(context.Background(), msg)
+	_, err := service.ProcessMessage(context.Background(), msg)
 	if err != nil {
 		t.Fatalf("ProcessMessage failed: %v", err)
 	}
@@ -55,6 +58,7 @@ func TestRocketService_ProcessMessage_RocketLaunched_Integration(t *testing.T) {
 		Reason:                     nil,
 		LastUpdateTime:             launchTime,
 		LastProcessedMessageNumber: 1,
+		Version:                    1,
 	}
 
 	if !reflect.DeepEqual(state, expectedState) {
@@ -81,13 +85,16 @@ func TestRocketService_ListAllRockets_Sorting_Integration(t *testing.T) {
 	store.SaveRocket(r3)
 
 	// Test: No sorting
-	rockets := service.ListAllRockets(context.Background(), "", "")
+	rockets, err := service.ListAllRockets(context.Background(), ListQuery{})
+	if err != nil {
+		t.Fatalf("ListAllRockets failed: %v", err)
+	}
 	if len(rockets) != 3 {
 		t.Fatalf("Expected 3 rockets, got %d", len(rockets))
 	}
 
 	// Test: Sort by ID (asc)
-	rockets = service.ListAllRockets(context.Background(), "id", "asc")
+	rockets, _ = service.ListAllRockets(context.Background(), SortedBy(SortByID, SortAsc))
 	// The exact UUIDs are random, so we need to sort the expected slice as well for direct comparison
 	expectedIDsSorted := []string{r1.ID.String(), r2.ID.String(), r3.ID.String()}
 	sort.Strings(expectedIDsSorted) // Sort UUID strings
@@ -99,14 +106,301 @@ func TestRocketService_ListAllRockets_Sorting_Integration(t *testing.T) {
 	}
 
 	// Test: Sort by Speed (desc)
-	rockets = service.ListAllRockets(context.Background(), "speed", "desc")
+	rockets, _ = service.ListAllRockets(context.Background(), SortedBy(SortBySpeed, SortDesc))
 	if rockets[0].ID != r1.ID || rockets[1].ID != r3.ID || rockets[2].ID != r2.ID { // C (300), B (200), A (100)
 		t.Errorf("Sorting by Speed DESC failed. Expected C, B, A. Got %s, %s, %s", rockets[0].ID.String(), rockets[1].ID.String(), rockets[2].ID.String())
 	}
 
 	// Test: Sort by LastUpdateTime (asc)
-	rockets = service.ListAllRockets(context.Background(), "lastupdatetime", "asc")
+	rockets, _ = service.ListAllRockets(context.Background(), SortedBy(SortByLastUpdateTime, SortAsc))
 	if rockets[0].ID != r2.ID || rockets[1].ID != r3.ID || rockets[2].ID != r1.ID { // A (t1), B (t2), C (t3)
 		t.Errorf("Sorting by LastUpdateTime ASC failed. Expected A, B, C. Got %s, %s, %s", rockets[0].ID.String(), rockets[1].ID.String(), rockets[2].ID.String())
 	}
+
+	// Test: Unknown sort parameters are rejected instead of listing in arbitrary order
+	if _, err := service.ListAllRockets(context.Background(), SortedBy("altitude", SortAsc)); !errors.Is(err, ErrUnknownSortField) {
+		t.Errorf("Expected ErrUnknownSortField, got %v", err)
+	}
+	if _, err := service.ListAllRockets(context.Background(), SortedBy(SortBySpeed, "sideways")); !errors.Is(err, ErrUnknownSortOrder) {
+		t.Errorf("Expected ErrUnknownSortOrder, got %v", err)
+	}
+}
+
+func TestRocketService_RollbackRocket(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	history := NewInMemoryHistoryStore()
+	service := NewRocketService(store, logger)
+	service.UseHistory(history)
+	ctx := context.Background()
+
+	rocketID := uuid.New()
+	launchTime := time.Now().UTC().Truncate(time.Millisecond)
+	messages := []TelemetryMessage{
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 1, MessageTime: launchTime, MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 2, MessageTime: launchTime.Add(time.Second), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(3000))},
+		},
+		// bad message from a buggy producer
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 3, MessageTime: launchTime.Add(2 * time.Second), MessageType: MessageTypeMissionChanged},
+			Message:  Message{NewMission: ptr(Mission("GARBAGE"))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 4, MessageTime: launchTime.Add(3 * time.Second), MessageType: MessageTypeSpeedDecreased},
+			Message:  Message{By: ptr(Speed(1000))},
+		},
+	}
+	for _, msg := range messages {
+		if _, err := service.ProcessMessage(ctx, msg); err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+
+	if _, err := service.RollbackRocket(ctx, uuid.New(), 3); !errors.Is(err, ErrRocketNotFound) {
+		t.Errorf("Expected ErrRocketNotFound for unknown rocket, got %v", err)
+	}
+	if _, err := service.RollbackRocket(ctx, rocketID, 42); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("Expected ErrMessageNotFound for unknown message, got %v", err)
+	}
+	if _, err := service.RollbackRocket(ctx, rocketID, 1); !errors.Is(err, ErrNoPriorState) {
+		t.Errorf("Expected ErrNoPriorState for the first message, got %v", err)
+	}
+
+	restored, err := service.RollbackRocket(ctx, rocketID, 3)
+	if err != nil {
+		t.Fatalf("RollbackRocket failed: %v", err)
+	}
+	if restored.Mission != "ARTEMIS" || restored.CurrentSpeed != 3500 || restored.LastProcessedMessageNumber != 2 {
+		t.Errorf("Unexpected restored state: %+v", restored)
+	}
+	if restored.Version != 5 {
+		t.Errorf("Expected restored state to get version 5, got %d", restored.Version)
+	}
+	if state, _ := store.GetRocketByID(rocketID); !reflect.DeepEqual(state, restored) {
+		t.Errorf("Stored state does not match restored state.\nExpected: %+v\nGot: %+v", restored, state)
+	}
+
+	for _, event := range history.ListEvents(rocketID) {
+		expected := event.Message.Metadata.MessageNumber >= 3
+		if event.Superseded != expected {
+			t.Errorf("Event #%d: expected superseded=%v", event.Message.Metadata.MessageNumber, expected)
+		}
+	}
+
+	// the producer resends the corrected message
+	fixed := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 3, MessageTime: launchTime.Add(2 * time.Second), MessageType: MessageTypeSpeedDecreased},
+		Message:  Message{By: ptr(Speed(500))},
+	}
+	if _, err := service.ProcessMessage(ctx, fixed); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	if state, _ := store.GetRocketByID(rocketID); state.CurrentSpeed != 3000 || state.Version != 6 {
+		t.Errorf("Unexpected state after resend: %+v", state)
+	}
+}
+
+func TestRocketService_ListAllRockets_Filter(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	service := NewRocketService(store, logger)
+
+	r1 := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 300, Mission: "ARTEMIS", Status: StatusLaunched}
+	r2 := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 100, Mission: "ARTEMIS", Status: StatusExploded}
+	r3 := State{ID: uuid.New(), Type: "Soyuz", CurrentSpeed: 200, Mission: "ARTEMIS", Status: StatusLaunched}
+	r4 := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 400, Mission: "APOLLO", Status: StatusLaunched}
+	for _, r := range []State{r1, r2, r3, r4} {
+		store.SaveRocket(r)
+	}
+
+	rockets, _ := service.ListAllRockets(context.Background(), ListQuery{Filter: Filter{Mission: "ARTEMIS", Status: StatusLaunched}, Sort: []SortKey{{Field: SortBySpeed, Order: SortAsc}}})
+	if len(rockets) != 2 || rockets[0].ID != r3.ID || rockets[1].ID != r1.ID {
+		t.Errorf("Expected launched ARTEMIS rockets sorted by speed, got %+v", rockets)
+	}
+	if rockets, _ := service.ListAllRockets(context.Background(), ListQuery{Filter: Filter{Type: "Falcon-9"}}); len(rockets) != 3 {
+		t.Errorf("Expected 3 Falcon-9 rockets, got %d", len(rockets))
+	}
+	if rockets, _ := service.ListAllRockets(context.Background(), ListQuery{Filter: Filter{}}); len(rockets) != 4 {
+		t.Errorf("Expected an empty filter to match all rockets, got %d", len(rockets))
+	}
+}
+
+func TestRocketService_ReadErrors(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	service := NewRocketService(store, logger)
+	known := State{ID: uuid.New(), Type: "Falcon-9", Status: StatusLaunched}
+	store.SaveRocket(known)
+
+	if _, err := service.GetRocketState(context.Background(), uuid.New()); !errors.Is(err, ErrRocketNotFound) {
+		t.Errorf("Expected ErrRocketNotFound for an untracked rocket, got %v", err)
+	}
+	if state, err := service.GetRocketState(context.Background(), known.ID); err != nil || state.ID != known.ID {
+		t.Errorf("Expected the known rocket, got %+v, %v", state, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.GetRocketState(ctx, known.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled reading with a done context, got %v", err)
+	}
+	if rockets, err := service.ListAllRockets(ctx, ListQuery{}); !errors.Is(err, context.Canceled) || rockets != nil {
+		t.Errorf("Expected context.Canceled listing with a done context instead of an empty result, got %v, %v", rockets, err)
+	}
+}
+
+func TestRocketService_LogEvents(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+	service := NewRocketService(NewInMemoryRocketStore(logger), logger)
+	id := uuid.New()
+	launch := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: time.Now(), MessageType: MessageTypeLaunched},
+		Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+	}
+	invalid := TelemetryMessage{
+		Metadata: MessageMetadata{Channel: id, MessageNumber: 2, MessageTime: time.Now(), MessageType: MessageTypeSpeedIncreased},
+	}
+
+	_, _ = service.ProcessMessage(context.Background(), launch)
+	_, _ = service.ProcessMessage(context.Background(), launch)
+	_, _ = service.ProcessMessage(context.Background(), invalid)
+
+	var events []logging.EventName
+	for _, entry := range logs.All() {
+		fields := entry.ContextMap()
+		name, ok := fields[logging.EventKey].(string)
+		if !ok {
+			t.Errorf("Expected %q to name its event", entry.Message)
+			continue
+		}
+		if fields["rocket_id"] != id.String() {
+			t.Errorf("Expected %s to carry rocket_id, got %v", name, fields)
+		}
+		events = append(events, logging.EventName(name))
+	}
+	want := []logging.EventName{
+		logging.EventMessageAccepted, logging.EventStateCreated, logging.EventStateTransition,
+		logging.EventMessageAccepted, logging.EventMessageDuplicate,
+		logging.EventMessageInvalid,
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Expected events %v, got %v", want, events)
+	}
+}
+
+func TestRocketService_ProcessMessage_Result(t *testing.T) {
+	logger := zap.NewNop()
+	service := NewRocketService(NewInMemoryRocketStore(logger), logger)
+	service.UseReorderBuffer(10, 0)
+	ctx := context.Background()
+
+	rocketID := uuid.New()
+	msg := func(number int64, messageType MessageType, payload Message) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: number, MessageTime: time.Now(), MessageType: messageType},
+			Message:  payload,
+		}
+	}
+	launch := msg(1, MessageTypeLaunched, Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))})
+
+	tests := []struct {
+		name     string
+		msg      TelemetryMessage
+		outcome  Outcome
+		version  int64
+		warnings int
+	}{
+		{name: "ahead of the launch", msg: msg(2, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))}), outcome: OutcomeBuffered, warnings: 1},
+		{name: "launch", msg: launch, outcome: OutcomeApplied, version: 1},
+		{name: "duplicate", msg: launch, outcome: OutcomeDuplicate, version: 2, warnings: 1},
+		{name: "underflow", msg: msg(3, MessageTypeSpeedDecreased, Message{By: ptr(Speed(1000))}), outcome: OutcomeApplied, version: 3, warnings: 1},
+		{name: "invalid", msg: msg(4, MessageTypeSpeedDecreased, Message{}), outcome: OutcomeRejected},
+	}
+	for _, tt := range tests {
+		result, _ := service.ProcessMessage(ctx, tt.msg)
+		if result.Outcome != tt.outcome || result.Version != tt.version || len(result.Warnings) != tt.warnings {
+			t.Errorf("%s: expected %s at version %d with %d warnings, got %+v", tt.name, tt.outcome, tt.version, tt.warnings, result)
+		}
+	}
+}
+
+func TestRocketService_Heartbeat(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	history := NewInMemoryHistoryStore()
+	service := NewRocketService(store, logger)
+	service.UseHistory(history)
+	listener := &heartbeatListener{}
+	service.AddListener(listener)
+	ctx := context.Background()
+
+	rocketID := uuid.New()
+	launchTime := time.Now().UTC().Truncate(time.Millisecond)
+	messages := []TelemetryMessage{
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 1, MessageTime: launchTime, MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+		{Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 2, MessageTime: launchTime.Add(time.Minute), MessageType: MessageTypeHeartbeat}},
+		{Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 3, MessageTime: launchTime.Add(2 * time.Minute), MessageType: MessageTypeHeartbeat}},
+	}
+	for _, msg := range messages {
+		if result, err := service.ProcessMessage(ctx, msg); err != nil || result.Outcome != OutcomeApplied {
+			t.Fatalf("Expected message %d applied, got %+v, %v", msg.Metadata.MessageNumber, result, err)
+		}
+	}
+
+	state, _ := store.GetRocketByID(rocketID)
+	if state.CurrentSpeed != 500 || state.Mission != "ARTEMIS" || state.Status != StatusLaunched {
+		t.Errorf("Expected heartbeats to leave the state unchanged, got %+v", state)
+	}
+	if !state.LastUpdateTime.Equal(launchTime.Add(2*time.Minute)) || state.LastProcessedMessageNumber != 3 || state.Version != 1 {
+		t.Errorf("Expected heartbeats to advance the liveness, got %+v", state)
+	}
+	if events := history.ListEvents(rocketID); len(events) != 1 {
+		t.Errorf("Expected heartbeats left out of the history, got %d events", len(events))
+	}
+	if listener.changed != 1 || listener.heartbeats != 2 {
+		t.Errorf("Expected 1 state change and 2 heartbeats notified, got %d and %d", listener.changed, listener.heartbeats)
+	}
+	if report := service.CheckConsistency(ctx, false); len(report.Drifts) != 0 {
+		t.Errorf("Expected no drift from heartbeats left out of the history, got %+v", report.Drifts)
+	}
+
+	// a message missing from the history moved the version, the liveness ahead of the history is a drift then
+	ahead := state
+	ahead.Version, ahead.LastProcessedMessageNumber = 2, 4
+	store.SaveRocket(ahead)
+	if report := service.CheckConsistency(ctx, false); len(report.Drifts) != 1 {
+		t.Errorf("Expected a drift of a message missing from the history, got %+v", report.Drifts)
+	}
+	store.SaveRocket(state)
+
+	service.UseHeartbeatHistory()
+	heartbeat := TelemetryMessage{Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 4, MessageTime: launchTime.Add(3 * time.Minute), MessageType: MessageTypeHeartbeat}}
+	if _, err := service.ProcessMessage(ctx, heartbeat); err != nil {
+		t.Fatalf("ProcessMessage failed: %v", err)
+	}
+	if events := history.ListEvents(rocketID); len(events) != 2 || events[1].Message.Metadata.MessageNumber != 4 {
+		t.Errorf("Expected the heartbeat recorded with UseHeartbeatHistory, got %+v", events)
+	}
+}
+
+// heartbeatListener counts the state changes and the heartbeats it is notified about
+type heartbeatListener struct {
+	changed, heartbeats int
+}
+
+func (l *heartbeatListener) StateChanged(context.Context, TelemetryMessage, State, State) {
+	l.changed++
+}
+
+func (l *heartbeatListener) Heartbeat(context.Context, TelemetryMessage, State) {
+	l.heartbeats++
 }
@@ -0,0 +1,130 @@
+package rocket
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/logging"
+	"slices"
+)
+
+// Processor - state machine applying a validated message to the state of its rocket, deciding nothing about
+// duplicates or ordering
+type Processor interface {
+	// Apply returns the state after the message; exists is false for the first message of a rocket,
+	// whose state is then the zero one
+	Apply(state State, exists bool, msg TelemetryMessage) State
+}
+
+// rulesProcessor - the state machine of the service with the given rules
+type rulesProcessor struct {
+	rules rules
+}
+
+// NewProcessor returns the state machine of the service with the speed underflow policy and, optionally,
+// provisional states; see UseUnderflowPolicy and UseProvisionalState.
+func NewProcessor(policy UnderflowPolicy, provisional bool) Processor {
+	return rulesProcessor{rules: rules{underflow: policy, provisional: provisional}}
+}
+
+func (p rulesProcessor) Apply(state State, exists bool, msg TelemetryMessage) State {
+	var next State
+	switch {
+	case exists && lateLaunch(state, msg, p.rules):
+		next = backfill(state, msg, p.rules)
+	case exists:
+		next, _ = apply(state, msg, p.rules)
+	default:
+		next, _ = apply(State{ID: msg.Metadata.Channel, Status: StatusUnknown}, msg, p.rules)
+	}
+	next.Version = state.Version + 1
+	return next
+}
+
+// Divergence - state transition of the shadow processor differing from the one of the service
+type Divergence struct {
+	RocketID      uuid.UUID
+	MessageNumber int64
+	MessageType   MessageType
+	// Fields - fields whose values differ
+	Fields []Field
+	// Primary, Shadow - states after the message according to the service and the shadow processor
+	Primary State
+	Shadow  State
+	// Panic - the shadow processor panicked, the states are not compared
+	Panic string
+}
+
+var _ Listener = (*Shadow)(nil)
+
+// Shadow - runs a candidate processor alongside the service on every applied message, comparing the state it
+// leads to with the one the service saved, to roll out a rewrite of the state machine safely. The candidate
+// starts from the previous state of the service for every message, so a divergence is reported at the message
+// causing it and does not carry over. It sees only the messages the service applied: duplicate detection and
+// reordering are not compared.
+type Shadow struct {
+	candidate Processor
+	logger    *zap.Logger
+	report    func(Divergence)
+}
+
+// NewShadow creates the shadow of the candidate processor, reporting divergences to the function besides logging them.
+func NewShadow(candidate Processor, logger *zap.Logger, report func(Divergence)) *Shadow {
+	return &Shadow{candidate: candidate, logger: logger, report: report}
+}
+
+// StateChanged applies the message with the candidate processor and compares the result with the next state
+func (s *Shadow) StateChanged(_ context.Context, msg TelemetryMessage, prev, next State) {
+	shadow, err := s.apply(prev, msg)
+	d := Divergence{
+		RocketID:      msg.Metadata.Channel,
+		MessageNumber: msg.Metadata.MessageNumber,
+		MessageType:   msg.Metadata.MessageType,
+		Primary:       next,
+		Shadow:        shadow,
+	}
+	if err != nil {
+		d.Panic = err.Error()
+		s.logger.Error("Shadow processor panicked",
+			logging.Event(logging.EventShadowDivergence),
+			zap.String("rocket_id", d.RocketID.String()),
+			zap.Int64("msg_num", d.MessageNumber),
+			zap.String("msg_type", string(d.MessageType)),
+			zap.Error(err),
+		)
+		s.report(d)
+		return
+	}
+	for _, f := range changeFields {
+		if fieldValue(f, next) != fieldValue(f, shadow) {
+			d.Fields = append(d.Fields, f)
+		}
+	}
+	if len(d.Fields) == 0 {
+		return
+	}
+	s.logger.Warn("Shadow processor diverged",
+		logging.Event(logging.EventShadowDivergence),
+		zap.String("rocket_id", d.RocketID.String()),
+		zap.Int64("msg_num", d.MessageNumber),
+		zap.String("msg_type", string(d.MessageType)),
+		zap.Any("fields", d.Fields),
+		zap.Any("primary", next),
+		zap.Any("shadow", shadow),
+	)
+	s.report(d)
+}
+
+// apply runs the candidate processor, turning its panics into errors so a faulty candidate can't take
+// the service down
+func (s *Shadow) apply(prev State, msg TelemetryMessage) (next State, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	// the candidate must not append to the pre-launch messages shared with the state of the service
+	prev.Prelaunch = slices.Clone(prev.Prelaunch)
+	return s.candidate.Apply(prev, prev.ID != uuid.Nil, msg), nil
+}
@@ -0,0 +1,79 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// panickingProcessor - candidate processor failing on every message
+type panickingProcessor struct{}
+
+func (panickingProcessor) Apply(State, bool, TelemetryMessage) State {
+	panic("not implemented")
+}
+
+func TestShadow(t *testing.T) {
+	rocketID := uuid.New()
+	messages := []TelemetryMessage{
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 1, MessageTime: time.Now(), MessageType: MessageTypeLaunched},
+			Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 2, MessageTime: time.Now(), MessageType: MessageTypeSpeedDecreased},
+			Message:  Message{By: ptr(Speed(800))},
+		},
+		{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: 3, MessageTime: time.Now(), MessageType: MessageTypeSpeedIncreased},
+			Message:  Message{By: ptr(Speed(100))},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		candidate Processor
+		expected  []Divergence
+	}{
+		{name: "same rules", candidate: NewProcessor(UnderflowClamp, false)},
+		{
+			name:      "other underflow policy",
+			candidate: NewProcessor(UnderflowReject, false),
+			// the rejected decrease diverges, the next message starts again from the state of the service
+			expected: []Divergence{{RocketID: rocketID, MessageNumber: 2, MessageType: MessageTypeSpeedDecreased, Fields: []Field{FieldCurrentSpeed}}},
+		},
+		{
+			name:      "panic",
+			candidate: panickingProcessor{},
+			expected: []Divergence{
+				{RocketID: rocketID, MessageNumber: 1, MessageType: MessageTypeLaunched, Panic: "panic: not implemented"},
+				{RocketID: rocketID, MessageNumber: 2, MessageType: MessageTypeSpeedDecreased, Panic: "panic: not implemented"},
+				{RocketID: rocketID, MessageNumber: 3, MessageType: MessageTypeSpeedIncreased, Panic: "panic: not implemented"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+			var got []Divergence
+			service.AddListener(NewShadow(tt.candidate, zap.NewNop(), func(d Divergence) {
+				d.Primary, d.Shadow = State{}, State{}
+				got = append(got, d)
+			}))
+			for _, msg := range messages {
+				if _, err := service.ProcessMessage(context.Background(), msg); err != nil {
+					t.Fatalf("ProcessMessage failed: %v", err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected divergences %+v, got %+v", tt.expected, got)
+			}
+			if state, _ := service.GetRocketState(context.Background(), rocketID); state.CurrentSpeed != 100 {
+				t.Errorf("Expected the shadow not to affect the state, got speed %d", state.CurrentSpeed)
+			}
+		})
+	}
+}
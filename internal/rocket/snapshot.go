@@ -0,0 +1,89 @@
+package rocket
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// snapshotView - the states of all rockets as of a point in time
+type snapshotView struct {
+	states []State
+	asOf   time.Time
+}
+
+// Snapshot serves rocket listings from a copy of all states refreshed in the background, so very hot dashboards
+// don't contend with the writers for the locks of the live store. Listings are at most maxStaleness old; when
+// the copy is older, e.g. because refreshing fails, they fall back to the live service.
+type Snapshot struct {
+	rockets      Service
+	maxStaleness time.Duration
+	clock        clock.Clock
+	logger       *zap.Logger
+
+	view atomic.Pointer[snapshotView]
+}
+
+// NewSnapshot creates a snapshot of the rockets of the service, empty until it is refreshed.
+func NewSnapshot(rockets Service, maxStaleness time.Duration, logger *zap.Logger) *Snapshot {
+	return &Snapshot{
+		rockets:      rockets,
+		maxStaleness: maxStaleness,
+		clock:        clock.Real{},
+		logger:       logger,
+	}
+}
+
+// UseClock replaces the system clock the age of the snapshot is measured by. Must be called before the snapshot
+// is used.
+func (s *Snapshot) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// Run refreshes the snapshot twice per staleness bound until the context is done, so a listing served from it
+// is never older than the bound while refreshing works. Failures are logged.
+func (s *Snapshot) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.maxStaleness / 2)
+	defer ticker.Stop()
+	for {
+		if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Can't refresh the rocket snapshot", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh copies the current states of all rockets
+func (s *Snapshot) Refresh(ctx context.Context) error {
+	asOf := s.clock.Now()
+	states, err := s.rockets.ListAllRockets(ctx, ListQuery{})
+	if err != nil {
+		return fmt.Errorf("can't list rockets: %w", err)
+	}
+	s.view.Store(&snapshotView{states: states, asOf: asOf})
+	return nil
+}
+
+// ListAllRockets lists the rockets answering the query like Service.ListAllRockets, together with the time the
+// listing is current as of: the time of the snapshot, or now when it was too old and the live service answered.
+func (s *Snapshot) ListAllRockets(ctx context.Context, query ListQuery) ([]State, time.Time, error) {
+	now := s.clock.Now()
+	view := s.view.Load()
+	if view == nil || now.Sub(view.asOf) > s.maxStaleness {
+		states, err := s.rockets.ListAllRockets(ctx, query)
+		return states, now, err
+	}
+	if err := query.Validate(); err != nil {
+		return nil, time.Time{}, err
+	}
+	// the query filters and sorts in place, the snapshot is shared by the readers
+	return query.Apply(slices.Clone(view.states)), view.asOf, nil
+}
@@ -0,0 +1,49 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_ListAllRockets(t *testing.T) {
+	fake := clock.NewFake(time.Date(2022, 2, 2, 19, 39, 0, 0, time.UTC))
+	store := NewInMemoryRocketStore(zap.NewNop())
+	svc := NewRocketService(store, zap.NewNop())
+	snapshot := NewSnapshot(svc, 2*time.Second, zap.NewNop())
+	snapshot.UseClock(fake)
+	first, second := uuid.New(), uuid.New()
+	store.SaveRocket(State{ID: first, Status: StatusLaunched, Version: 1})
+
+	if err := snapshot.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	refreshed := fake.Now()
+	store.SaveRocket(State{ID: second, Status: StatusLaunched, Version: 1})
+	fake.Advance(time.Second)
+
+	states, asOf, err := snapshot.ListAllRockets(context.Background(), ListQuery{})
+	if err != nil {
+		t.Fatalf("ListAllRockets failed: %v", err)
+	}
+	if len(states) != 1 || states[0].ID != first || !asOf.Equal(refreshed) {
+		t.Errorf("Expected the snapshot of the first rocket as of %s, got %v as of %s", refreshed, ids(states), asOf)
+	}
+
+	if _, _, err := snapshot.ListAllRockets(context.Background(), ListQuery{Offset: -1}); err == nil {
+		t.Errorf("Expected an invalid query to fail")
+	}
+
+	// a snapshot older than the bound is not served
+	fake.Advance(2 * time.Second)
+	states, asOf, err = snapshot.ListAllRockets(context.Background(), ListQuery{})
+	if err != nil {
+		t.Fatalf("ListAllRockets failed: %v", err)
+	}
+	if len(states) != 2 || !asOf.Equal(fake.Now()) {
+		t.Errorf("Expected the live rockets as of now, got %v as of %s", ids(states), asOf)
+	}
+}
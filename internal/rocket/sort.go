@@ -0,0 +1,151 @@
+package rocket
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortField - field the rockets are listed by
+type SortField string
+
+const (
+	// SortNone - the order of the store, unspecified
+	SortNone             SortField = ""
+	SortByID             SortField = "id"
+	SortByType           SortField = "type"
+	SortBySpeed          SortField = "speed"
+	SortByMission        SortField = "mission"
+	SortByLastUpdateTime SortField = "lastUpdateTime"
+)
+
+// SortOrder - direction of the listing, ascending when empty
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// sortFields - comparison of the states by every sort field, the string fields compared by compareStrings
+var sortFields = map[SortField]func(a, b State, compareStrings func(a, b string) int) int{
+	SortByID: func(a, b State, _ func(a, b string) int) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	},
+	SortByType: func(a, b State, compareStrings func(a, b string) int) int {
+		return compareStrings(string(a.Type), string(b.Type))
+	},
+	SortBySpeed: func(a, b State, _ func(a, b string) int) int {
+		return cmp.Compare(a.CurrentSpeed, b.CurrentSpeed)
+	},
+	SortByMission: func(a, b State, compareStrings func(a, b string) int) int {
+		return compareStrings(string(a.Mission), string(b.Mission))
+	},
+	SortByLastUpdateTime: func(a, b State, _ func(a, b string) int) int {
+		return a.LastUpdateTime.Compare(b.LastUpdateTime)
+	},
+}
+
+// Validate checks that the field is known
+func (f SortField) Validate() error {
+	if _, ok := sortFields[f]; !ok && f != SortNone {
+		return fmt.Errorf("%w: %s", ErrUnknownSortField, f)
+	}
+	return nil
+}
+
+// Validate checks that the order is known
+func (o SortOrder) Validate() error {
+	switch o {
+	case "", SortAsc, SortDesc:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownSortOrder, o)
+}
+
+// SortKey - one level of the listing order, the field and its direction
+type SortKey struct {
+	Field SortField
+	Order SortOrder
+	// Natural - the numbers within the string fields are compared by value, so Falcon-9 sorts before Falcon-10
+	Natural bool
+}
+
+// Validate checks that the field and the order are known
+func (k SortKey) Validate() error {
+	if err := k.Field.Validate(); err != nil {
+		return err
+	}
+	return k.Order.Validate()
+}
+
+// sortStates sorts the states by the keys, most significant first, comparing the string fields by the collation.
+// Ties left are broken by the rocket ID, so listings are stable. Keys without a field are skipped.
+func sortStates(states []State, keys []SortKey, collation *Collation) {
+	keys = slices.DeleteFunc(slices.Clone(keys), func(k SortKey) bool { return k.Field == SortNone })
+	if len(keys) == 0 {
+		return
+	}
+	compareStrings, release := collation.comparer()
+	defer release()
+	compareNatural := func(a, b string) int {
+		return naturalCompare(a, b, compareStrings)
+	}
+	slices.SortFunc(states, func(a, b State) int {
+		for _, k := range keys {
+			compare := compareStrings
+			if k.Natural {
+				compare = compareNatural
+			}
+			c := sortFields[k.Field](a, b, compare)
+			if k.Order == SortDesc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return sortFields[SortByID](a, b, compareStrings)
+	})
+}
+
+// naturalCompare compares the strings chunk by chunk: runs of digits by their value, the text between them
+// by compareStrings. Strings equal but for leading zeros, e.g. Falcon-7 and Falcon-007, are ordered by the
+// first number padded differently.
+func naturalCompare(a, b string, compareStrings func(a, b string) int) int {
+	padding := 0
+	for a != "" && b != "" {
+		chunkA, restA := nextChunk(a)
+		chunkB, restB := nextChunk(b)
+		var c int
+		if isDigit(chunkA[0]) && isDigit(chunkB[0]) {
+			trimmedA, trimmedB := strings.TrimLeft(chunkA, "0"), strings.TrimLeft(chunkB, "0")
+			c = cmp.Or(cmp.Compare(len(trimmedA), len(trimmedB)), strings.Compare(trimmedA, trimmedB))
+			if padding == 0 {
+				padding = cmp.Compare(len(chunkA), len(chunkB))
+			}
+		} else {
+			c = compareStrings(chunkA, chunkB)
+		}
+		if c != 0 {
+			return c
+		}
+		a, b = restA, restB
+	}
+	return cmp.Or(cmp.Compare(len(a), len(b)), padding)
+}
+
+// nextChunk splits the leading run of digits or of other characters off the non-empty string
+func nextChunk(s string) (string, string) {
+	digits := isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,91 @@
+package rocket
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrInvalidAggregation - the aggregation of the speed samples is unknown or its window is not positive
+var ErrInvalidAggregation = errors.New("invalid aggregation")
+
+// SpeedSample - speed of a rocket after a message, at the time of the message
+type SpeedSample struct {
+	Time  time.Time
+	Speed Speed
+}
+
+// Aggregation - how the speed samples of a window are combined
+type Aggregation string
+
+const (
+	AggregationAvg Aggregation = "avg"
+	AggregationMax Aggregation = "max"
+	AggregationMin Aggregation = "min"
+)
+
+// Validate checks that the aggregation is one of the known ones
+func (a Aggregation) Validate() error {
+	switch a {
+	case AggregationAvg, AggregationMax, AggregationMin:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown aggregation %q", ErrInvalidAggregation, a)
+	}
+}
+
+// SpeedHistory returns the speeds the effective events of a rocket left it with, ordered by the message time
+func SpeedHistory(events []Event) []SpeedSample {
+	samples := make([]SpeedSample, 0, len(events))
+	for _, e := range events {
+		if !e.Superseded {
+			samples = append(samples, SpeedSample{Time: e.Message.Metadata.MessageTime, Speed: e.State.CurrentSpeed})
+		}
+	}
+	slices.SortStableFunc(samples, func(a, b SpeedSample) int {
+		return a.Time.Compare(b.Time)
+	})
+	return samples
+}
+
+// Downsample combines the time-ordered samples falling into the same window into one sample at the start of
+// the window. Windows are aligned like time.Truncate, so 1m windows start on full minutes; empty ones are left out.
+func Downsample(samples []SpeedSample, window time.Duration, agg Aggregation) ([]SpeedSample, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("%w: window %s is not positive", ErrInvalidAggregation, window)
+	}
+	if err := agg.Validate(); err != nil {
+		return nil, err
+	}
+	var combine func(acc, speed Speed) Speed
+	switch agg {
+	case AggregationAvg:
+		combine = func(acc, speed Speed) Speed { return acc + speed }
+	case AggregationMax:
+		combine = func(acc, speed Speed) Speed { return max(acc, speed) }
+	default:
+		combine = func(acc, speed Speed) Speed { return min(acc, speed) }
+	}
+
+	var out []SpeedSample
+	var n Speed
+	for _, s := range samples {
+		start := s.Time.Truncate(window)
+		if len(out) > 0 && out[len(out)-1].Time.Equal(start) {
+			last := &out[len(out)-1]
+			last.Speed = combine(last.Speed, s.Speed)
+			n++
+			continue
+		}
+		if agg == AggregationAvg && n > 0 {
+			out[len(out)-1].Speed /= n
+		}
+		out = append(out, SpeedSample{Time: start, Speed: s.Speed})
+		n = 1
+	}
+	if agg == AggregationAvg && n > 0 {
+		out[len(out)-1].Speed /= n
+	}
+	return out, nil
+}
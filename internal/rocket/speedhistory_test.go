@@ -0,0 +1,63 @@
+package rocket
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDownsample(t *testing.T) {
+	at := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+	event := func(offset time.Duration, speed Speed, superseded bool) Event {
+		return Event{
+			Message:    TelemetryMessage{Metadata: MessageMetadata{MessageTime: at.Add(offset)}},
+			State:      State{CurrentSpeed: speed},
+			Superseded: superseded,
+		}
+	}
+	// applied out of order, the superseded event is left out
+	samples := SpeedHistory([]Event{
+		event(30*time.Second, 300, false),
+		event(0, 100, false),
+		event(40*time.Second, 900, true),
+		event(2*time.Minute, 700, false),
+		event(70*time.Second, 500, false),
+	})
+	if len(samples) != 4 || samples[0].Speed != 100 || samples[3].Speed != 700 {
+		t.Fatalf("Expected 4 samples ordered by time, got %+v", samples)
+	}
+
+	minute := at.Truncate(time.Minute)
+	tests := []struct {
+		agg      Aggregation
+		expected []Speed
+	}{
+		{AggregationAvg, []Speed{200, 500, 700}},
+		{AggregationMax, []Speed{300, 500, 700}},
+		{AggregationMin, []Speed{100, 500, 700}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.agg), func(t *testing.T) {
+			got, err := Downsample(samples, time.Minute, tt.agg)
+			if err != nil {
+				t.Fatalf("Downsample failed: %v", err)
+			}
+			expected := []SpeedSample{
+				{Time: minute, Speed: tt.expected[0]},
+				{Time: minute.Add(time.Minute), Speed: tt.expected[1]},
+				{Time: minute.Add(2 * time.Minute), Speed: tt.expected[2]},
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, got)
+			}
+		})
+	}
+
+	if _, err := Downsample(samples, 0, AggregationAvg); !errors.Is(err, ErrInvalidAggregation) {
+		t.Errorf("Expected ErrInvalidAggregation for an empty window, got %v", err)
+	}
+	if _, err := Downsample(samples, time.Minute, "median"); !errors.Is(err, ErrInvalidAggregation) {
+		t.Errorf("Expected ErrInvalidAggregation for an unknown aggregation, got %v", err)
+	}
+}
@@ -3,6 +3,8 @@ package rocket
 import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"hash/fnv"
+	"rockets/internal/logging"
 	"sync"
 )
 
@@ -10,51 +12,209 @@ import (
 type Store interface {
 	// SaveRocket saves the current state of a rocket
 	SaveRocket(state State)
+	// ApplyDelta persists only the changed fields of an existing rocket, returning the new state
+	// or false if the rocket is not stored
+	ApplyDelta(id uuid.UUID, delta Delta) (State, bool)
+	// DeleteRocket removes the state of a rocket, false if the rocket is not stored
+	DeleteRocket(id uuid.UUID) bool
 	// GetRocketByID retrieves the state of a rocket by its ID
 	GetRocketByID(id uuid.UUID) (State, bool)
 	// ListAllRockets lists all rockets in the store
 	ListAllRockets() []State
+	// ListRocketsBy lists the rockets whose field covered by the secondary index equals the key
+	ListRocketsBy(index Index, key string) []State
+	// QueryRockets lists the rockets answering the validated query
+	QueryRockets(query ListQuery) []State
 }
 
 var _ Store = (*InMemoryRocketStore)(nil)
 
+// storeShards - number of independently locked shards of the in-memory store
+const storeShards = 64
+
+// InMemoryRocketStore keeps rocket states in shards keyed by the hash of the rocket ID, each guarded by its
+// own lock, so channels ingesting concurrently rarely contend. Listings merge the shards.
 type InMemoryRocketStore struct {
+	shards    [storeShards]shard
+	logger    *zap.Logger
+	logWrites bool
+}
+
+// shard - part of the in-memory store with its own lock and secondary indexes
+type shard struct {
 	mu      sync.RWMutex
 	rockets map[uuid.UUID]State
-	logger  *zap.Logger
+	indexes secondaryIndexes
 }
 
-// NewInMemoryRocketStore creates a new instance of InMemoryRocketStore with an initialized map for storing rocket states.
+// NewInMemoryRocketStore creates a new instance of InMemoryRocketStore with initialized shards for storing rocket states.
 func NewInMemoryRocketStore(logger *zap.Logger) *InMemoryRocketStore {
-	return &InMemoryRocketStore{
-		rockets: make(map[uuid.UUID]State),
-		logger:  logger,
+	s := &InMemoryRocketStore{logger: logger}
+	for i := range s.shards {
+		s.shards[i].rockets = make(map[uuid.UUID]State)
+		s.shards[i].indexes = newSecondaryIndexes()
 	}
+	return s
+}
+
+// shard returns the shard holding the rocket
+func (s *InMemoryRocketStore) shard(id uuid.UUID) *shard {
+	h := fnv.New32a()
+	_, _ = h.Write(id[:])
+	return &s.shards[h.Sum32()%storeShards]
+}
+
+// LogWrites enables logging a summary of the fields changed by every write.
+// Must be called before the store is used.
+func (s *InMemoryRocketStore) LogWrites(enabled bool) {
+	s.logWrites = enabled
 }
 
 // SaveRocket saves the current state of a rocket
 func (s *InMemoryRocketStore) SaveRocket(state State) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.rockets[state.ID] = state
-	s.logger.Info("Rocket state saved", zap.String("rocket_id", state.ID.String()), zap.Any("state", state))
+	sh := s.shard(state.ID)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	prev, existed := sh.rockets[state.ID]
+	s.logWrite(prev, existed, state)
+	sh.put(prev, existed, state)
+}
+
+// ApplyDelta applies the changed fields to the stored state of the rocket
+func (s *InMemoryRocketStore) ApplyDelta(id uuid.UUID, delta Delta) (State, bool) {
+	sh := s.shard(id)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	prev, ok := sh.rockets[id]
+	if !ok {
+		return State{}, false
+	}
+	state := delta.Apply(prev)
+	s.logWrite(prev, true, state)
+	sh.put(prev, true, state)
+	return state, true
+}
+
+// DeleteRocket removes the state of the rocket and its index entries
+func (s *InMemoryRocketStore) DeleteRocket(id uuid.UUID) bool {
+	sh := s.shard(id)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	prev, ok := sh.rockets[id]
+	if !ok {
+		return false
+	}
+	if s.logWrites {
+		s.logger.Info("Rocket state deleted", zap.String("rocket_id", id.String()), zap.Int64("version", prev.Version))
+	}
+	delete(sh.rockets, id)
+	sh.indexes.remove(prev)
+	return true
+}
+
+// restore puts a state loaded from persistent storage without logging it
+func (s *InMemoryRocketStore) restore(state State) {
+	sh := s.shard(state.ID)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	prev, existed := sh.rockets[state.ID]
+	sh.put(prev, existed, state)
+}
+
+func (s *InMemoryRocketStore) logWrite(prev State, existed bool, state State) {
+	if !s.logWrites {
+		return
+	}
+	if ce := s.logger.Check(zap.InfoLevel, "Rocket state saved"); ce != nil {
+		ce.Write(changeSummary(prev, existed, state)...)
+	}
+}
+
+// put stores the state and updates the indexes, must be called with the shard locked
+func (sh *shard) put(prev State, existed bool, state State) {
+	sh.rockets[state.ID] = state
+	sh.indexes.update(prev, existed, state)
 }
 
 // GetRocketByID retrieves the state of a rocket by its ID
 func (s *InMemoryRocketStore) GetRocketByID(id uuid.UUID) (State, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	rocket, ok := s.rockets[id]
+	sh := s.shard(id)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	rocket, ok := sh.rockets[id]
 	return rocket, ok
 }
 
-// ListAllRockets lists all rockets in the store
+// ListAllRockets lists all rockets in the store, merging the shards
 func (s *InMemoryRocketStore) ListAllRockets() []State {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	states := make([]State, 0, len(s.rockets))
-	for _, rocket := range s.rockets {
-		states = append(states, rocket)
+	var states []State
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		for _, rocket := range sh.rockets {
+			states = append(states, rocket)
+		}
+		sh.mu.RUnlock()
+	}
+	if states == nil {
+		states = []State{}
+	}
+	return states
+}
+
+// ListRocketsBy lists the rockets whose field covered by the secondary index equals the key, merging the shards
+func (s *InMemoryRocketStore) ListRocketsBy(index Index, key string) []State {
+	states := []State{}
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		for id := range sh.indexes.ids(index, key) {
+			states = append(states, sh.rockets[id])
+		}
+		sh.mu.RUnlock()
 	}
 	return states
 }
+
+// QueryRockets lists the rockets answering the validated query, narrowed down by a secondary index when filtered
+func (s *InMemoryRocketStore) QueryRockets(query ListQuery) []State {
+	if index, key, ok := query.Filter.index(); ok {
+		return query.Apply(s.ListRocketsBy(index, key))
+	}
+	return query.Apply(s.ListAllRockets())
+}
+
+// changeSummary returns log fields identifying the written state and holding only the fields that changed
+func changeSummary(prev State, existed bool, next State) []zap.Field {
+	fields := []zap.Field{
+		logging.Event(logging.EventStoreSave),
+		zap.String("rocket_id", next.ID.String()),
+		zap.Int64("version", next.Version),
+		zap.Int64("msg_num", next.LastProcessedMessageNumber),
+	}
+	if !existed {
+		fields = append(fields, zap.Bool("created", true))
+	}
+	if prev.Type != next.Type {
+		fields = append(fields, zap.String("type", string(next.Type)))
+	}
+	if prev.CurrentSpeed != next.CurrentSpeed {
+		fields = append(fields, zap.Int64("speed", int64(next.CurrentSpeed)))
+	}
+	if prev.Mission != next.Mission {
+		fields = append(fields, zap.String("mission", string(next.Mission)))
+	}
+	if prev.Status != next.Status {
+		fields = append(fields, zap.String("status", string(next.Status)))
+	}
+	if next.Reason != nil && (prev.Reason == nil || *prev.Reason != *next.Reason) {
+		fields = append(fields, zap.String("reason", next.Reason.Text), zap.String("reason_category", string(next.Reason.Category)))
+	}
+	if next.Anomaly != nil && (prev.Anomaly == nil || *prev.Anomaly != *next.Anomaly) {
+		fields = append(fields, zap.String("anomaly", *next.Anomaly))
+	}
+	if len(prev.Prelaunch) != len(next.Prelaunch) {
+		fields = append(fields, zap.Int("prelaunch", len(next.Prelaunch)))
+	}
+	return fields
+}
@@ -3,7 +3,9 @@ package rocket
 import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 )
@@ -40,7 +42,7 @@ func TestInMemoryRocketStore_SaveAndGet(t *testing.T) {
 		t.Errorf("Expected rocket with ID %s not to be found, but it was", nonExistentID)
 	}
 
-	updatedSpeed := int64(3500)
+	updatedSpeed := Speed(3500)
 	updatedMessageNumber := int64(2)
 	updatedTime := time.Now().UTC().Add(time.Minute).Truncate(time.Millisecond)
 	updatedState := State{
@@ -120,7 +122,7 @@ func TestInMemoryRocketStore_Concurrency(t *testing.T) {
 		for i := 0; i < 100; i++ {
 			state := State{
 				ID:                         rocketID,
-				CurrentSpeed:               int64(i),
+				CurrentSpeed:               Speed(i),
 				LastUpdateTime:             time.Now(),
 				LastProcessedMessageNumber: int64(i),
 			}
@@ -135,3 +137,64 @@ func TestInMemoryRocketStore_Concurrency(t *testing.T) {
 
 	time.Sleep(50 * time.Millisecond)
 }
+
+func TestInMemoryRocketStore_Shards(t *testing.T) {
+	store := NewInMemoryRocketStore(zap.NewNop())
+
+	var wg sync.WaitGroup
+	ids := make([]uuid.UUID, 500)
+	for i := range ids {
+		ids[i] = uuid.New()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.SaveRocket(State{ID: ids[i], Mission: "ARTEMIS", Status: StatusLaunched, Version: 1})
+			_ = store.ListAllRockets()
+		}()
+	}
+	wg.Wait()
+
+	used := make(map[*shard]bool)
+	for _, id := range ids {
+		used[store.shard(id)] = true
+	}
+	if len(used) < 2 {
+		t.Errorf("Expected the rockets to be spread over shards, got %d", len(used))
+	}
+	if got := store.ListAllRockets(); len(got) != len(ids) {
+		t.Errorf("Expected %d rockets merged from the shards, got %d", len(ids), len(got))
+	}
+	if got := store.ListRocketsBy(IndexMission, "ARTEMIS"); len(got) != len(ids) {
+		t.Errorf("Expected %d rockets merged from the shard indexes, got %d", len(ids), len(got))
+	}
+}
+
+func TestInMemoryRocketStore_LogWrites(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	store := NewInMemoryRocketStore(zap.New(core))
+
+	state := State{ID: uuid.New(), Type: "Falcon-9", CurrentSpeed: 500, Mission: "ARTEMIS", Status: StatusLaunched, Version: 1}
+	store.SaveRocket(state)
+	if logs.Len() != 0 {
+		t.Fatalf("Expected no logs with write logging disabled, got %d", logs.Len())
+	}
+
+	store.LogWrites(true)
+	state.CurrentSpeed = 600
+	state.Version = 2
+	store.SaveRocket(state)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log entry, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["speed"] != int64(600) || fields["version"] != int64(2) {
+		t.Errorf("Expected the changed speed and the version to be logged, got %v", fields)
+	}
+	for _, unchanged := range []string{"type", "mission", "status", "created"} {
+		if _, ok := fields[unchanged]; ok {
+			t.Errorf("Expected unchanged %s not to be logged, got %v", unchanged, fields)
+		}
+	}
+}
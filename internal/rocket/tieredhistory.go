@@ -0,0 +1,260 @@
+package rocket
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"rockets/internal/atomicfile"
+	"rockets/internal/clock"
+	"sync"
+	"time"
+)
+
+// ColdHistory - cheaper, slower storage of the events the hot history no longer keeps
+type ColdHistory interface {
+	// AppendEvents appends events of a rocket, applied after the ones appended before
+	AppendEvents(id uuid.UUID, events []Event) error
+	// ListEvents lists the cold events of a rocket in the order they were applied
+	ListEvents(id uuid.UUID) ([]Event, error)
+	// SupersedeEvents marks cold events of a rocket starting from the given state version as superseded
+	SupersedeEvents(id uuid.UUID, fromVersion int64) (int, error)
+}
+
+var _ ColdHistory = (*DirColdHistory)(nil)
+
+// DirColdHistory keeps the cold events of every rocket in a gzip-compressed JSON lines file of a directory,
+// e.g. one on a cheap volume or a mounted bucket. Appends add a gzip member to the file.
+type DirColdHistory struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// OpenDirColdHistory creates the directory if needed.
+func OpenDirColdHistory(dir string) (*DirColdHistory, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("can't create cold history directory: %w", err)
+	}
+	return &DirColdHistory{dir: dir}, nil
+}
+
+// AppendEvents appends the events to the file of the rocket, syncing the directory when it creates the file
+func (h *DirColdHistory) AppendEvents(id uuid.UUID, events []Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := os.Stat(h.file(id))
+	created := errors.Is(err, os.ErrNotExist)
+	f, err := os.OpenFile(h.file(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("can't open cold history: %w", err)
+	}
+	if err := writeEvents(f, events); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("can't sync cold history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("can't close cold history: %w", err)
+	}
+	if created {
+		if err := atomicfile.SyncDir(h.dir); err != nil {
+			return fmt.Errorf("can't sync cold history: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListEvents reads the file of the rocket
+func (h *DirColdHistory) ListEvents(id uuid.UUID) ([]Event, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.read(id)
+}
+
+// SupersedeEvents rewrites the file of the rocket with the events from the version marked as superseded
+func (h *DirColdHistory) SupersedeEvents(id uuid.UUID, fromVersion int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	events, err := h.read(id)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for i := range events {
+		if !events[i].Superseded && events[i].State.Version >= fromVersion {
+			events[i].Superseded = true
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	tmp := h.file(id) + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, fmt.Errorf("can't rewrite cold history: %w", err)
+	}
+	if err := writeEvents(f, events); err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if err := atomicfile.Replace(f, h.file(id)); err != nil {
+		return 0, fmt.Errorf("can't replace cold history: %w", err)
+	}
+	return n, nil
+}
+
+func (h *DirColdHistory) file(id uuid.UUID) string {
+	return filepath.Join(h.dir, id.String()+".jsonl.gz")
+}
+
+func (h *DirColdHistory) read(id uuid.UUID) ([]Event, error) {
+	f, err := os.Open(h.file(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't open cold history: %w", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("can't read cold history: %w", err)
+	}
+	var events []Event
+	dec := json.NewDecoder(zr)
+	for {
+		var e Event
+		if err := dec.Decode(&e); errors.Is(err, io.EOF) {
+			return events, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("can't decode cold history: %w", err)
+		}
+		events = append(events, e)
+	}
+}
+
+// writeEvents writes the events as one gzip member of JSON lines
+func writeEvents(w io.Writer, events []Event) error {
+	bw := bufio.NewWriter(w)
+	zw := gzip.NewWriter(bw)
+	enc := json.NewEncoder(zw)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("can't encode cold history: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("can't write cold history: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("can't write cold history: %w", err)
+	}
+	return nil
+}
+
+var _ HistoryStore = (*TieredHistoryStore)(nil)
+
+// TieredHistoryStore keeps the recent events in the hot in-memory history and moves the events older than
+// the hot age to the cold tier. Reads span both tiers transparently.
+type TieredHistoryStore struct {
+	// mu - held exclusively while events move between the tiers, so reads never miss them
+	mu   sync.RWMutex
+	hot  *InMemoryHistoryStore
+	cold ColdHistory
+	age  time.Duration
+	// pins - rockets whose events stay hot however old
+	pins   *Pins
+	clock  clock.Clock
+	logger *zap.Logger
+}
+
+// NewTieredHistoryStore creates a history moving events whose messages are older than age from hot to cold.
+func NewTieredHistoryStore(hot *InMemoryHistoryStore, cold ColdHistory, age time.Duration, logger *zap.Logger) *TieredHistoryStore {
+	return &TieredHistoryStore{hot: hot, cold: cold, age: age, clock: clock.Real{}, logger: logger}
+}
+
+// UseClock replaces the system clock the age of the events is measured by. Must be called before the store is used.
+func (s *TieredHistoryStore) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// UsePins keeps the events of the pinned channels and missions in the hot tier. Must be called before the store
+// is used.
+func (s *TieredHistoryStore) UsePins(pins *Pins) {
+	s.pins = pins
+}
+
+// AppendEvent appends the event to the hot history
+func (s *TieredHistoryStore) AppendEvent(event Event) {
+	s.hot.AppendEvent(event)
+}
+
+// ListEvents lists the cold events of the rocket followed by the hot ones. When the cold tier can't be read,
+// the failure is logged and only the hot events are listed.
+func (s *TieredHistoryStore) ListEvents(id uuid.UUID) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cold, err := s.cold.ListEvents(id)
+	if err != nil {
+		s.logger.Error("Can't read cold history", zap.String("rocket_id", id.String()), zap.Error(err))
+	}
+	return append(cold, s.hot.ListEvents(id)...)
+}
+
+// SupersedeEvents marks the events of both tiers from the version as superseded
+func (s *TieredHistoryStore) SupersedeEvents(id uuid.UUID, fromVersion int64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, err := s.cold.SupersedeEvents(id, fromVersion)
+	if err != nil {
+		s.logger.Error("Can't supersede cold history", zap.String("rocket_id", id.String()), zap.Error(err))
+	}
+	return n + s.hot.SupersedeEvents(id, fromVersion)
+}
+
+// Run moves the old events to the cold tier every interval until the context is done, failures are logged.
+func (s *TieredHistoryStore) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := s.Tier(); err != nil {
+				s.logger.Error("Can't move history to the cold tier", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Tier moves the events whose messages are older than the hot age to the cold tier and returns how many moved,
+// except the ones of pinned rockets.
+// Events of a rocket that can't be written stay hot and are retried on the next run.
+func (s *TieredHistoryStore) Tier() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var moved int
+	var errs []error
+	for id, events := range s.hot.takeOlder(s.clock.Now().Add(-s.age), s.pins) {
+		if err := s.cold.AppendEvents(id, events); err != nil {
+			s.hot.restore(id, events)
+			errs = append(errs, fmt.Errorf("rocket %s: %w", id, err))
+			continue
+		}
+		moved += len(events)
+	}
+	return moved, errors.Join(errs...)
+}
@@ -0,0 +1,108 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"testing"
+	"time"
+)
+
+func TestTieredHistoryStore(t *testing.T) {
+	launchTime := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+	dir := t.TempDir()
+	cold, err := OpenDirColdHistory(dir)
+	if err != nil {
+		t.Fatalf("OpenDirColdHistory failed: %v", err)
+	}
+	history := NewTieredHistoryStore(NewInMemoryHistoryStore(), cold, 24*time.Hour, zap.NewNop())
+	fake := clock.NewFake(launchTime.Add(time.Hour))
+	history.UseClock(fake)
+	service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	service.UseHistory(history)
+
+	rocketID := uuid.New()
+	send := func(number int64, at time.Time, msg Message, msgType MessageType) {
+		t.Helper()
+		_, err := service.ProcessMessage(context.Background(), TelemetryMessage{
+			Metadata: MessageMetadata{Channel: rocketID, MessageNumber: number, MessageTime: at, MessageType: msgType},
+			Message:  msg,
+		})
+		if err != nil {
+			t.Fatalf("ProcessMessage failed: %v", err)
+		}
+	}
+	send(1, launchTime, Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))}, MessageTypeLaunched)
+	send(2, launchTime.Add(time.Minute), Message{By: ptr(Speed(100))}, MessageTypeSpeedIncreased)
+
+	// nothing is old enough yet
+	if moved, err := history.Tier(); moved != 0 || err != nil {
+		t.Fatalf("Expected nothing moved, got %d, %v", moved, err)
+	}
+
+	fake.Advance(48 * time.Hour)
+	send(3, fake.Now(), Message{By: ptr(Speed(200))}, MessageTypeSpeedIncreased)
+	if moved, err := history.Tier(); moved != 2 || err != nil {
+		t.Fatalf("Expected the two old events moved, got %d, %v", moved, err)
+	}
+	if hot := len(history.hot.ListEvents(rocketID)); hot != 1 {
+		t.Errorf("Expected one hot event left, got %d", hot)
+	}
+
+	// reads span both tiers, also after a restart of the cold tier
+	reopened, err := OpenDirColdHistory(dir)
+	if err != nil {
+		t.Fatalf("OpenDirColdHistory failed: %v", err)
+	}
+	history.cold = reopened
+	events := history.ListEvents(rocketID)
+	if len(events) != 3 || events[0].Message.Metadata.MessageNumber != 1 || events[2].Message.Metadata.MessageNumber != 3 {
+		t.Fatalf("Expected the events of both tiers in order, got %+v", events)
+	}
+
+	// rolling back into the cold tier supersedes the events there
+	if _, err := service.RollbackRocket(context.Background(), rocketID, 2); err != nil {
+		t.Fatalf("RollbackRocket failed: %v", err)
+	}
+	events = history.ListEvents(rocketID)
+	if events[0].Superseded || !events[1].Superseded || !events[2].Superseded {
+		t.Errorf("Expected the events from message 2 superseded across tiers, got %+v", events)
+	}
+}
+
+func TestTieredHistoryStore_Pins(t *testing.T) {
+	launchTime := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+	cold, err := OpenDirColdHistory(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenDirColdHistory failed: %v", err)
+	}
+	history := NewTieredHistoryStore(NewInMemoryHistoryStore(), cold, 24*time.Hour, zap.NewNop())
+	fake := clock.NewFake(launchTime.Add(48 * time.Hour))
+	history.UseClock(fake)
+	pinned := uuid.New()
+	pins := NewPins([]uuid.UUID{pinned}, []Mission{"VOYAGER"}, launchTime)
+	history.UsePins(pins)
+
+	probe, other := uuid.New(), uuid.New()
+	for id, mission := range map[uuid.UUID]Mission{pinned: "ARTEMIS", probe: "VOYAGER", other: "ARTEMIS"} {
+		history.AppendEvent(Event{
+			Message: TelemetryMessage{Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: launchTime}},
+			State:   State{ID: id, Mission: mission, Version: 1},
+		})
+	}
+
+	if moved, err := history.Tier(); moved != 1 || err != nil {
+		t.Fatalf("Expected only the event of the unpinned rocket moved, got %d, %v", moved, err)
+	}
+	for _, id := range []uuid.UUID{pinned, probe} {
+		if hot := len(history.hot.ListEvents(id)); hot != 1 {
+			t.Errorf("Expected the event of pinned rocket %s to stay hot, got %d", id, hot)
+		}
+	}
+
+	pins.UnpinChannel(pinned)
+	if moved, err := history.Tier(); moved != 1 || err != nil {
+		t.Fatalf("Expected the event of the unpinned channel moved, got %d, %v", moved, err)
+	}
+}
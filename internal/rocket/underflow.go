@@ -0,0 +1,79 @@
+package rocket
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"rockets/internal/logging"
+)
+
+// UnderflowPolicy - handling of a speed decrease that would take the speed below zero
+type UnderflowPolicy string
+
+const (
+	// UnderflowClamp - the speed stops at zero
+	UnderflowClamp UnderflowPolicy = "clamp"
+	// UnderflowReject - the message is rejected as invalid, leaving the state and its number unprocessed. Replayed
+	// from the history, e.g. by a rollback, the decrease is skipped.
+	UnderflowReject UnderflowPolicy = "reject"
+	// UnderflowAnomalous - the speed stops at zero and the state is flagged with the anomaly
+	UnderflowAnomalous UnderflowPolicy = "anomalous"
+)
+
+// UnderflowPolicies - all underflow policies
+var UnderflowPolicies = []UnderflowPolicy{UnderflowClamp, UnderflowReject, UnderflowAnomalous}
+
+// Validate checks that the policy is known
+func (p UnderflowPolicy) Validate() error {
+	for _, known := range UnderflowPolicies {
+		if p == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown speed underflow policy %q", p)
+}
+
+// rejectsUnderflow reports whether the reject policy rejects the message decreasing the speed of the state below zero
+func (s *ServiceImpl) rejectsUnderflow(state State, msg TelemetryMessage) bool {
+	return s.rules.underflow == UnderflowReject && msg.Metadata.MessageType == MessageTypeSpeedDecreased && *msg.Message.By > state.CurrentSpeed
+}
+
+// rejectUnderflow rejects the message decreasing the speed of the state below zero, keeping it as a dead letter.
+// Must be called from exclusive.
+func (s *ServiceImpl) rejectUnderflow(logger *zap.Logger, state State, msg TelemetryMessage) (Result, error) {
+	logger.Warn("Speed decrease below zero rejected",
+		logging.Event(logging.EventSpeedUnderflow),
+		zap.String("rocket_id", msg.Metadata.Channel.String()),
+		zap.Int64("msg_num", msg.Metadata.MessageNumber),
+		zap.Int64("speed", int64(state.CurrentSpeed)),
+		zap.Int64("by", int64(*msg.Message.By)),
+		zap.String("policy", string(s.rules.underflow)),
+	)
+	err := s.underflowError(state, msg)
+	s.dead.add(msg, err.Error(), s.clock.Now())
+	return Result{Outcome: OutcomeRejected, Version: state.Version}, err
+}
+
+// underflowError describes the rejection of the message decreasing the speed of the state below zero
+func (s *ServiceImpl) underflowError(state State, msg TelemetryMessage) error {
+	return fmt.Errorf("%w: speed %d decreased by %d below zero, rejected by the %s policy", ErrInvalidMessage, state.CurrentSpeed, *msg.Message.By, s.rules.underflow)
+}
+
+// decreaseSpeed returns the state with the speed decreased by the message according to the policy
+// and whether the decrease underflowed
+func decreaseSpeed(state State, msg TelemetryMessage, policy UnderflowPolicy) (State, bool) {
+	by := *msg.Message.By
+	if by <= state.CurrentSpeed {
+		state.CurrentSpeed -= by
+		return state, false
+	}
+	switch policy {
+	case UnderflowReject:
+	case UnderflowAnomalous:
+		anomaly := fmt.Sprintf("speed %d decreased by %d at message %d", state.CurrentSpeed, by, msg.Metadata.MessageNumber)
+		state.Anomaly = &anomaly
+		state.CurrentSpeed = 0
+	default:
+		state.CurrentSpeed = 0
+	}
+	return state, true
+}
@@ -0,0 +1,99 @@
+package rocket
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"rockets/internal/logging"
+	"testing"
+	"time"
+)
+
+func TestRocketService_SpeedUnderflow(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    UnderflowPolicy
+		by        Speed
+		speed     Speed
+		anomaly   string
+		underflow bool
+		rejected  bool
+	}{
+		{name: "clamp above zero", policy: UnderflowClamp, by: 100, speed: 100},
+		{name: "clamp to exactly zero", policy: UnderflowClamp, by: 200, speed: 0},
+		{name: "clamp below zero", policy: UnderflowClamp, by: 300, speed: 0, underflow: true},
+		{name: "reject above zero", policy: UnderflowReject, by: 100, speed: 100},
+		{name: "reject to exactly zero", policy: UnderflowReject, by: 200, speed: 0},
+		{name: "reject below zero", policy: UnderflowReject, by: 300, speed: 200, underflow: true, rejected: true},
+		{name: "anomalous above zero", policy: UnderflowAnomalous, by: 100, speed: 100},
+		{name: "anomalous to exactly zero", policy: UnderflowAnomalous, by: 200, speed: 0},
+		{name: "anomalous below zero", policy: UnderflowAnomalous, by: 300, speed: 0, anomaly: "speed 200 decreased by 300 at message 2", underflow: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			service := NewRocketService(NewInMemoryRocketStore(zap.NewNop()), zap.New(core))
+			service.UseUnderflowPolicy(tt.policy)
+			service.UseHistory(NewInMemoryHistoryStore())
+
+			ctx := context.Background()
+			id := uuid.New()
+			start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			messages := []TelemetryMessage{
+				{
+					Metadata: MessageMetadata{Channel: id, MessageNumber: 1, MessageTime: start, MessageType: MessageTypeLaunched},
+					Message:  Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(200)), Mission: ptr(Mission("ARTEMIS"))},
+				},
+				{
+					Metadata: MessageMetadata{Channel: id, MessageNumber: 2, MessageTime: start.Add(time.Second), MessageType: MessageTypeSpeedDecreased},
+					Message:  Message{By: ptr(tt.by)},
+				},
+			}
+			if _, err := service.ProcessMessage(ctx, messages[0]); err != nil {
+				t.Fatalf("ProcessMessage failed: %v", err)
+			}
+			result, err := service.ProcessMessage(ctx, messages[1])
+			if tt.rejected != errors.Is(err, ErrInvalidMessage) || tt.rejected != (result.Outcome == OutcomeRejected) {
+				t.Fatalf("Expected the decrease rejected %v, got %+v, %v", tt.rejected, result, err)
+			}
+
+			state, _ := service.GetRocketState(ctx, id)
+			if state.CurrentSpeed != tt.speed {
+				t.Errorf("Expected speed %d, got %d", tt.speed, state.CurrentSpeed)
+			}
+			number, version := int64(2), int64(2)
+			if tt.rejected {
+				number, version = 1, 1
+			}
+			if state.LastProcessedMessageNumber != number || state.Version != version {
+				t.Errorf("Expected message %d processed at version %d, got message %d at version %d", number, version, state.LastProcessedMessageNumber, state.Version)
+			}
+			anomaly := ""
+			if state.Anomaly != nil {
+				anomaly = *state.Anomaly
+			}
+			if anomaly != tt.anomaly {
+				t.Errorf("Expected anomaly %q, got %q", tt.anomaly, anomaly)
+			}
+			if n := logs.FilterField(logging.Event(logging.EventSpeedUnderflow)).Len(); (n > 0) != tt.underflow {
+				t.Errorf("Expected underflow logged %v, got %d entries", tt.underflow, n)
+			}
+			if report := service.CheckConsistency(ctx, false); len(report.Violations) > 0 || len(report.Drifts) > 0 {
+				t.Errorf("Expected a consistent state, got %+v", report)
+			}
+		})
+	}
+}
+
+func TestUnderflowPolicy_Validate(t *testing.T) {
+	for _, policy := range UnderflowPolicies {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", policy, err)
+		}
+	}
+	if err := UnderflowPolicy("wrap").Validate(); err == nil {
+		t.Errorf("Expected an unknown policy to be invalid")
+	}
+}
@@ -0,0 +1,109 @@
+package rocket
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Unnumbered - channels and producers sending their messages without numbers, e.g. legacy producers, with the
+// window duplicates of their messages are detected within. A message without a number of such a channel or
+// producer is ordered by its message time and numbered after the last processed message when applied; a message
+// with the content of one applied within the window, or of the last applied one at the time of the last update
+// of the rocket, is a duplicate. A nil registry has none.
+type Unnumbered struct {
+	channels  map[uuid.UUID]time.Duration
+	producers map[string]time.Duration
+
+	mu sync.Mutex
+	// seen - hashes of the messages applied within the window, by channel, oldest first
+	seen map[uuid.UUID][]seenMessage
+}
+
+// seenMessage - hash of the content of an applied unnumbered message
+type seenMessage struct {
+	hash string
+	at   time.Time
+}
+
+// NewUnnumbered creates a registry of the channels and producers, by name, sending messages without numbers.
+func NewUnnumbered(channels map[uuid.UUID]time.Duration, producers map[string]time.Duration) *Unnumbered {
+	return &Unnumbered{
+		channels:  channels,
+		producers: producers,
+		seen:      make(map[uuid.UUID][]seenMessage),
+	}
+}
+
+// Window returns the dedup window of the channel of the message, or of its producer, false when the message
+// carries a number or neither sends messages without numbers
+func (u *Unnumbered) Window(md MessageMetadata) (time.Duration, bool) {
+	if u == nil || md.MessageNumber != 0 {
+		return 0, false
+	}
+	if window, ok := u.channels[md.Channel]; ok {
+		return window, true
+	}
+	window, ok := u.producers[md.Producer]
+	return window, ok && md.Producer != ""
+}
+
+// duplicate returns when a message with the content of the message was applied within the window before now
+func (u *Unnumbered) duplicate(msg TelemetryMessage, window time.Duration, now time.Time) (time.Time, bool) {
+	hash := contentHash(msg)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, seen := range u.seen[msg.Metadata.Channel] {
+		if seen.hash == hash && now.Sub(seen.at) < window {
+			return seen.at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// remember records the content of the applied message, forgetting the ones of the channel older than the window
+func (u *Unnumbered) remember(msg TelemetryMessage, window time.Duration, now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	id := msg.Metadata.Channel
+	seen := slices.DeleteFunc(u.seen[id], func(s seenMessage) bool {
+		return now.Sub(s.at) >= window
+	})
+	u.seen[id] = append(seen, seenMessage{hash: contentHash(msg), at: now})
+}
+
+// contentHash hashes what the producer sent: the type, the time and the payload of the message, hex encoded
+func contentHash(msg TelemetryMessage) string {
+	payload, _ := json.Marshal(msg.Message)
+	hash := sha256.Sum256(fmt.Appendf(nil, "%s\n%s\n%s", msg.Metadata.MessageType, msg.Metadata.MessageTime.UTC().Format(time.RFC3339Nano), payload))
+	return hex.EncodeToString(hash[:])
+}
+
+// number numbers the message of an unnumbered channel after the last processed one. It returns false with the
+// reason when the message is a duplicate or older than the last update of the rocket, which leaves it out of the
+// order by message time. A copy of the last applied message is a duplicate by the hash kept in the state, so it
+// is rejected after a restart or a fail-over too. With remember the content of the numbered message is recorded
+// for the dedup.
+func (s *ServiceImpl) number(msg TelemetryMessage, current State, exists bool, window time.Duration, remember bool) (TelemetryMessage, string, bool) {
+	now := s.clock.Now()
+	if at, ok := s.unnumbered.duplicate(msg, window, now); ok {
+		return msg, fmt.Sprintf("a message with the same content was applied at %s", at.UTC().Format(time.RFC3339)), false
+	}
+	if exists && msg.Metadata.MessageTime.Before(current.LastUpdateTime) {
+		return msg, fmt.Sprintf("message time %s is before the last update %s", msg.Metadata.MessageTime.UTC().Format(time.RFC3339Nano), current.LastUpdateTime.UTC().Format(time.RFC3339Nano)), false
+	}
+	if exists && msg.Metadata.MessageTime.Equal(current.LastUpdateTime) && contentHash(msg) == current.LastUnnumberedHash {
+		return msg, fmt.Sprintf("a message with the same content was the last applied, at %s", current.LastUpdateTime.UTC().Format(time.RFC3339Nano)), false
+	}
+	if remember {
+		s.unnumbered.remember(msg, window, now)
+	}
+	msg.Metadata.MessageNumber = current.LastProcessedMessageNumber + 1
+	msg.Metadata.Unnumbered = true
+	return msg, "", true
+}
@@ -0,0 +1,82 @@
+package rocket
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"testing"
+	"time"
+)
+
+func TestRocketService_Unnumbered(t *testing.T) {
+	logger := zap.NewNop()
+	store := NewInMemoryRocketStore(logger)
+	service := NewRocketService(store, logger)
+	fake := clock.NewFake(time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC))
+	service.UseClock(fake)
+	legacy, numbered := uuid.New(), uuid.New()
+	service.UseUnnumbered(NewUnnumbered(map[uuid.UUID]time.Duration{legacy: 10 * time.Minute}, map[string]time.Duration{"ground-station": time.Minute}))
+	ctx := context.Background()
+
+	at := fake.Now()
+	msg := func(channel uuid.UUID, offset time.Duration, messageType MessageType, payload Message) TelemetryMessage {
+		return TelemetryMessage{
+			Metadata: MessageMetadata{Channel: channel, MessageTime: at.Add(offset), MessageType: messageType},
+			Message:  payload,
+		}
+	}
+	launch := msg(legacy, 0, MessageTypeLaunched, Message{Type: ptr(RocketType("Falcon-9")), LaunchSpeed: ptr(Speed(500)), Mission: ptr(Mission("ARTEMIS"))})
+	increase := msg(legacy, 2*time.Second, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))})
+	untimed := msg(legacy, 0, MessageTypeSpeedIncreased, Message{By: ptr(Speed(100))})
+	untimed.Metadata.MessageTime = time.Time{}
+
+	tests := []struct {
+		name    string
+		msg     TelemetryMessage
+		outcome Outcome
+		speed   Speed
+		number  int64
+	}{
+		{name: "launch", msg: launch, outcome: OutcomeApplied, speed: 500, number: 1},
+		{name: "increase", msg: increase, outcome: OutcomeApplied, speed: 600, number: 2},
+		{name: "retried increase", msg: increase, outcome: OutcomeDuplicate, speed: 600, number: 2},
+		{name: "older than the last update", msg: msg(legacy, time.Second, MessageTypeSpeedIncreased, Message{By: ptr(Speed(50))}), outcome: OutcomeDuplicate, speed: 600, number: 2},
+		{name: "same time, other content", msg: msg(legacy, 2*time.Second, MessageTypeSpeedIncreased, Message{By: ptr(Speed(50))}), outcome: OutcomeApplied, speed: 650, number: 3},
+		{name: "without a time", msg: untimed, outcome: OutcomeRejected, speed: 650, number: 3},
+	}
+	for _, tt := range tests {
+		result, _ := service.ProcessMessage(ctx, tt.msg)
+		state, _ := store.GetRocketByID(legacy)
+		if result.Outcome != tt.outcome || state.CurrentSpeed != tt.speed || state.LastProcessedMessageNumber != tt.number {
+			t.Errorf("%s: expected %s with speed %d at message %d, got %s with %+v", tt.name, tt.outcome, tt.speed, tt.number, result.Outcome, state)
+		}
+	}
+
+	fake.Advance(10 * time.Minute)
+	resent := increase
+	resent.Metadata.MessageTime = at.Add(3 * time.Second)
+	if result, _ := service.ProcessMessage(ctx, resent); result.Outcome != OutcomeApplied {
+		t.Errorf("Expected a message outside the window applied, got %+v", result)
+	}
+
+	// a restart forgets the hashes of the window, the one of the last applied message is kept in the state
+	restarted := NewRocketService(store, logger)
+	restarted.UseClock(fake)
+	restarted.UseUnnumbered(NewUnnumbered(map[uuid.UUID]time.Duration{legacy: 10 * time.Minute}, nil))
+	if result, _ := restarted.ProcessMessage(ctx, resent); result.Outcome != OutcomeDuplicate {
+		t.Errorf("Expected the last applied message retried after a restart a duplicate, got %+v", result)
+	}
+	if state, _ := store.GetRocketByID(legacy); state.CurrentSpeed != 750 || state.LastProcessedMessageNumber != 4 {
+		t.Errorf("Expected the state left as it was, got %+v", state)
+	}
+
+	byProducer := msg(numbered, 0, MessageTypeLaunched, launch.Message)
+	if _, err := service.ProcessMessage(ctx, byProducer); err == nil {
+		t.Error("Expected a message without a number of another producer rejected")
+	}
+	byProducer.Metadata.Producer = "ground-station"
+	if result, err := service.ProcessMessage(ctx, byProducer); err != nil || result.Outcome != OutcomeApplied {
+		t.Errorf("Expected the message of the unnumbered producer applied, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,68 @@
+package rocket
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// Validate checks that the message is addressed to a channel and carries the payload required by its type
+// with values satisfying the rules of their value objects
+func (m TelemetryMessage) Validate() error {
+	if m.Metadata.Channel == uuid.Nil {
+		return fmt.Errorf("%w: channel is required", ErrInvalidMessage)
+	}
+	if m.Metadata.MessageNumber <= 0 {
+		return fmt.Errorf("%w: message number %d is not positive", ErrInvalidMessage, m.Metadata.MessageNumber)
+	}
+	if err := m.Message.validateValues(); err != nil {
+		return err
+	}
+
+	required := func(field string, present bool) error {
+		if !present {
+			return fmt.Errorf("%w: %s is required for %s", ErrInvalidMessage, field, m.Metadata.MessageType)
+		}
+		return nil
+	}
+
+	switch m.Metadata.MessageType {
+	case MessageTypeLaunched:
+		if err := required("type", m.Message.Type != nil); err != nil {
+			return err
+		}
+		if err := required("launchSpeed", m.Message.LaunchSpeed != nil); err != nil {
+			return err
+		}
+		return required("mission", m.Message.Mission != nil)
+	case MessageTypeSpeedIncreased, MessageTypeSpeedDecreased:
+		return required("by", m.Message.By != nil)
+	case MessageTypeMissionChanged:
+		return required("newMission", m.Message.NewMission != nil)
+	case MessageTypeExploded, MessageTypeHeartbeat:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown message type %q", ErrInvalidMessage, m.Metadata.MessageType)
+	}
+}
+
+// validateValues validates the present payload fields, messages may be assembled without the constructors
+func (m Message) validateValues() error {
+	for _, s := range []*Speed{m.By, m.LaunchSpeed} {
+		if s != nil {
+			if err := s.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	for _, mission := range []*Mission{m.Mission, m.NewMission} {
+		if mission != nil {
+			if err := mission.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	if m.Type != nil {
+		return m.Type.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package rocket
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxSpeed - upper bound of speeds and speed changes reported by rockets
+const MaxSpeed = 1_000_000
+
+// maxNameLength - upper bound of mission and rocket type names, in characters
+const maxNameLength = 64
+
+// Speed - rocket speed or speed change, non-negative and bounded by MaxSpeed when constructed
+type Speed int64
+
+// NewSpeed validates the speed.
+func NewSpeed(v int64) (Speed, error) {
+	s := Speed(v)
+	if err := s.Validate(); err != nil {
+		return 0, err
+	}
+	return s, nil
+}
+
+// Validate checks that the speed is in range 0..MaxSpeed
+func (s Speed) Validate() error {
+	if s < 0 || s > MaxSpeed {
+		return fmt.Errorf("%w: speed %d is out of range 0..%d", ErrInvalidMessage, s, MaxSpeed)
+	}
+	return nil
+}
+
+// Mission - mission name, upper-cased with collapsed whitespace
+type Mission string
+
+// NewMission normalizes and validates the mission name. Letters, digits, spaces, '-', '_' and '.' are allowed.
+func NewMission(s string) (Mission, error) {
+	name := strings.ToUpper(strings.Join(strings.Fields(s), " "))
+	if err := checkName("mission", name); err != nil {
+		return "", err
+	}
+	return Mission(name), nil
+}
+
+// Validate checks length and charset of the mission name, normalization is left to NewMission
+func (m Mission) Validate() error {
+	return checkName("mission", string(m))
+}
+
+// RocketType - rocket model, e.g. Falcon-9
+type RocketType string
+
+// NewRocketType trims and validates the rocket type. Letters, digits, spaces, '-', '_' and '.' are allowed.
+func NewRocketType(s string) (RocketType, error) {
+	name := strings.TrimSpace(s)
+	if err := checkName("rocket type", name); err != nil {
+		return "", err
+	}
+	return RocketType(name), nil
+}
+
+// Validate checks length and charset of the rocket type
+func (t RocketType) Validate() error {
+	return checkName("rocket type", string(t))
+}
+
+// checkName validates length and charset of a name
+func checkName(what, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: %s is empty", ErrInvalidMessage, what)
+	}
+	if n := utf8.RuneCountInString(name); n > maxNameLength {
+		return fmt.Errorf("%w: %s is %d characters long, at most %d allowed", ErrInvalidMessage, what, n, maxNameLength)
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == ' ', r == '-', r == '_', r == '.':
+		default:
+			return fmt.Errorf("%w: %s %q contains invalid character %q", ErrInvalidMessage, what, name, r)
+		}
+	}
+	return nil
+}
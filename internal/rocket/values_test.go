@@ -0,0 +1,47 @@
+package rocket
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewSpeed(t *testing.T) {
+	for _, v := range []int64{0, 500, MaxSpeed} {
+		if s, err := NewSpeed(v); err != nil || int64(s) != v {
+			t.Errorf("Expected speed %d to be valid, got %v (%v)", v, s, err)
+		}
+	}
+	for _, v := range []int64{-1, MaxSpeed + 1} {
+		if _, err := NewSpeed(v); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("Expected ErrInvalidMessage for speed %d, got %v", v, err)
+		}
+	}
+}
+
+func TestNewMission(t *testing.T) {
+	cases := map[string]Mission{
+		"ARTEMIS":         "ARTEMIS",
+		"  apollo   11 ":  "APOLLO 11",
+		"shuttle_mir-2.0": "SHUTTLE_MIR-2.0",
+	}
+	for in, expected := range cases {
+		if got, err := NewMission(in); err != nil || got != expected {
+			t.Errorf("NewMission(%q): expected %q, got %q (%v)", in, expected, got, err)
+		}
+	}
+	for _, in := range []string{"", "   ", "MARS;DROP TABLE", strings.Repeat("A", maxNameLength+1)} {
+		if _, err := NewMission(in); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("Expected ErrInvalidMessage for mission %q, got %v", in, err)
+		}
+	}
+}
+
+func TestNewRocketType(t *testing.T) {
+	if got, err := NewRocketType(" Falcon-9 "); err != nil || got != "Falcon-9" {
+		t.Errorf("Expected Falcon-9, got %q (%v)", got, err)
+	}
+	if _, err := NewRocketType("Falcon<9>"); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("Expected ErrInvalidMessage for an invalid character, got %v", err)
+	}
+}
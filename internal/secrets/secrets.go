@@ -0,0 +1,293 @@
+// Package secrets resolves configuration values referencing secrets kept in HashiCorp Vault or AWS Secrets
+// Manager, so passwords, API keys and tokens don't have to live in env files.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefixes of the values referencing a secret, e.g. "vault:secret/data/rockets#smtp_password" or
+// "aws-sm:prod/rockets#smtpPassword"
+const (
+	vaultPrefix = "vault:"
+	awsPrefix   = "aws-sm:"
+)
+
+// IsReference reports whether the configuration value references a secret instead of holding it
+func IsReference(v string) bool {
+	return strings.HasPrefix(v, vaultPrefix) || strings.HasPrefix(v, awsPrefix)
+}
+
+// Vault - address and token of the Vault server, read from the standard VAULT_* variables
+type Vault struct {
+	Addr      string
+	Token     string
+	Namespace string
+}
+
+// AWS - region and credentials of Secrets Manager, read from the standard AWS_* variables
+type AWS struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint - Secrets Manager endpoint, empty uses the one of the region
+	Endpoint string
+}
+
+// lease - renewable lease of a dynamic Vault secret
+type lease struct {
+	id       string
+	duration time.Duration
+}
+
+// Resolver fetches the referenced secrets and keeps the Vault token and the leases of dynamic secrets alive.
+// Secrets are read once; a rotated secret is picked up by the next start.
+type Resolver struct {
+	vault  Vault
+	aws    AWS
+	client *http.Client
+	logger *zap.Logger
+
+	mu sync.Mutex
+	// cache - secret documents by reference without the key, so several keys of one secret are fetched once
+	cache  map[string]map[string]any
+	leases []lease
+}
+
+// NewResolver creates a resolver of the Vault and AWS secrets.
+func NewResolver(vault Vault, aws AWS) *Resolver {
+	return &Resolver{
+		vault:  vault,
+		aws:    aws,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: zap.NewNop(),
+		cache:  make(map[string]map[string]any),
+	}
+}
+
+// FromEnv creates a resolver configured by the standard variables of the Vault CLI and the AWS SDKs:
+// VAULT_ADDR, VAULT_TOKEN, VAULT_NAMESPACE, AWS_REGION (or AWS_DEFAULT_REGION), AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_ENDPOINT_URL_SECRETS_MANAGER.
+func FromEnv() *Resolver {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return NewResolver(Vault{
+		Addr:      strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+	}, AWS{
+		Region:          region,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Endpoint:        strings.TrimSuffix(os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER"), "/"),
+	})
+}
+
+// UseLogger logs the renewals with the logger. Must be called before the resolver is used.
+func (r *Resolver) UseLogger(logger *zap.Logger) {
+	r.logger = logger
+}
+
+// Resolve fetches the secret of the reference. The part after "#" selects a key of the secret; without it the
+// secret must hold a single value, e.g. a plain text AWS secret.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	name, key, _ := strings.Cut(ref, "#")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	doc, ok := r.cache[name]
+	if !ok {
+		var err error
+		switch {
+		case strings.HasPrefix(name, vaultPrefix):
+			doc, err = r.readVault(ctx, strings.TrimPrefix(name, vaultPrefix))
+		case strings.HasPrefix(name, awsPrefix):
+			doc, err = r.readAWS(ctx, strings.TrimPrefix(name, awsPrefix))
+		default:
+			err = fmt.Errorf("expected a %s or %s reference", vaultPrefix, awsPrefix)
+		}
+		if err != nil {
+			return "", err
+		}
+		r.cache[name] = doc
+	}
+
+	if key == "" {
+		if len(doc) != 1 {
+			return "", fmt.Errorf("secret %s has %d keys, select one with #key", name, len(doc))
+		}
+		for k := range doc {
+			key = k
+		}
+	}
+	v, ok := doc[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", name, key)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("can't encode key %q of secret %s: %w", key, name, err)
+	}
+	return string(b), nil
+}
+
+type vaultSecret struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+}
+
+// readVault reads the secret at the path, unwrapping the data of KV version 2 secrets, and remembers its
+// lease when it is renewable
+func (r *Resolver) readVault(ctx context.Context, path string) (map[string]any, error) {
+	if r.vault.Addr == "" || r.vault.Token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN are required for vault references")
+	}
+	var secret vaultSecret
+	if err := r.vaultRequest(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), nil, &secret); err != nil {
+		return nil, fmt.Errorf("can't read vault secret %s: %w", path, err)
+	}
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]any); ok && data["metadata"] != nil {
+		data = inner
+	}
+	if secret.Renewable && secret.LeaseID != "" {
+		r.leases = append(r.leases, lease{id: secret.LeaseID, duration: time.Duration(secret.LeaseDuration) * time.Second})
+	}
+	return data, nil
+}
+
+func (r *Resolver) vaultRequest(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("can't encode request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.vault.Addr+path, reader)
+	if err != nil {
+		return fmt.Errorf("can't create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", r.vault.Token)
+	if r.vault.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.vault.Namespace)
+	}
+	return r.do(req, out)
+}
+
+// readAWS reads the current version of the secret, a JSON object or a plain string kept under the empty key
+func (r *Resolver) readAWS(ctx context.Context, id string) (map[string]any, error) {
+	if r.aws.Region == "" || r.aws.AccessKeyID == "" || r.aws.SecretAccessKey == "" {
+		return nil, errors.New("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for aws-sm references")
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return nil, fmt.Errorf("can't encode request: %w", err)
+	}
+	endpoint := r.aws.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + r.aws.Region + ".amazonaws.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("can't create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, body, r.aws, "secretsmanager", time.Now().UTC())
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := r.do(req, &secret); err != nil {
+		return nil, fmt.Errorf("can't read aws secret %s: %w", id, err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(secret.SecretString), &doc); err != nil {
+		// not a JSON object, the secret is the value itself
+		return map[string]any{"": secret.SecretString}, nil
+	}
+	return doc, nil
+}
+
+func (r *Resolver) do(req *http.Request, out any) error {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("can't decode response: %w", err)
+	}
+	return nil
+}
+
+// Renew renews the Vault token and the leases of the dynamic secrets read. Nothing is renewed when no Vault
+// secret was read.
+func (r *Resolver) Renew(ctx context.Context) error {
+	r.mu.Lock()
+	leases := append([]lease(nil), r.leases...)
+	used := len(leases) > 0
+	for name := range r.cache {
+		used = used || strings.HasPrefix(name, vaultPrefix)
+	}
+	r.mu.Unlock()
+	if !used {
+		return nil
+	}
+
+	var errs []error
+	if err := r.vaultRequest(ctx, http.MethodPost, "/v1/auth/token/renew-self", map[string]any{}, nil); err != nil {
+		errs = append(errs, fmt.Errorf("can't renew vault token: %w", err))
+	}
+	for _, l := range leases {
+		body := map[string]any{"lease_id": l.id, "increment": int(l.duration.Seconds())}
+		if err := r.vaultRequest(ctx, http.MethodPut, "/v1/sys/leases/renew", body, nil); err != nil {
+			errs = append(errs, fmt.Errorf("can't renew lease %s: %w", l.id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run renews the token and the leases every interval until the context is done. Failures are logged.
+func (r *Resolver) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Renew(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Error("Can't renew secrets", zap.Error(err))
+			}
+		}
+	}
+}
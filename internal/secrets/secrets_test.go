@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	var reads, renewals []string
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/rockets":
+			reads = append(reads, r.URL.Path)
+			_, _ = w.Write([]byte(`{"data":{"data":{"smtp_password":"s3cret","api_keys":"k1=acme"},"metadata":{"version":3}}}`))
+		case "/v1/database/creds/rockets":
+			_, _ = w.Write([]byte(`{"lease_id":"database/creds/rockets/abc","lease_duration":3600,"renewable":true,"data":{"password":"dyn"}}`))
+		case "/v1/auth/token/renew-self", "/v1/sys/leases/renew":
+			var body struct {
+				LeaseID string `json:"lease_id"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			renewals = append(renewals, strings.TrimSpace(r.URL.Path+" "+body.LeaseID))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+	aws := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" || !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") || !strings.Contains(auth, "x-amz-date") {
+			t.Errorf("Expected a signed GetSecretValue request, got %q", auth)
+		}
+		var req struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.SecretId {
+		case "prod/webhook":
+			_, _ = w.Write([]byte(`{"SecretString":"https://hooks.example.com/T0"}`))
+		case "prod/rockets":
+			_, _ = w.Write([]byte(`{"SecretString":"{\"token\":\"t0k\",\"retries\":3}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer aws.Close()
+
+	r := NewResolver(Vault{Addr: vault.URL, Token: "root"}, AWS{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: aws.URL})
+	ctx := context.Background()
+	expected := map[string]string{
+		"vault:secret/data/rockets#smtp_password": "s3cret",
+		"vault:secret/data/rockets#api_keys":      "k1=acme",
+		"vault:database/creds/rockets":            "dyn",
+		"aws-sm:prod/webhook":                     "https://hooks.example.com/T0",
+		"aws-sm:prod/rockets#token":               "t0k",
+		"aws-sm:prod/rockets#retries":             "3",
+	}
+	for ref, want := range expected {
+		got, err := r.Resolve(ctx, ref)
+		if err != nil || got != want {
+			t.Errorf("Expected %s to resolve to %q, got %q (%v)", ref, want, got, err)
+		}
+	}
+	if len(reads) != 1 {
+		t.Errorf("Expected the keys of a secret read once, got %d reads", len(reads))
+	}
+	for _, ref := range []string{"vault:secret/data/rockets", "vault:secret/data/rockets#missing", "vault:secret/data/other#key", "aws-sm:unknown"} {
+		if _, err := r.Resolve(ctx, ref); err == nil {
+			t.Errorf("Expected %s to fail", ref)
+		}
+	}
+
+	if err := r.Renew(ctx); err != nil {
+		t.Fatalf("Renew failed: %v", err)
+	}
+	if len(renewals) != 2 || renewals[0] != "/v1/auth/token/renew-self" || renewals[1] != "/v1/sys/leases/renew database/creds/rockets/abc" {
+		t.Errorf("Expected the token and the lease renewed, got %v", renewals)
+	}
+}
@@ -0,0 +1,316 @@
+package sim
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"math/rand"
+	"reflect"
+	"rockets/internal/clock"
+	"rockets/internal/rocket"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Faults - probabilities of delivery faults applied to every message
+type Faults struct {
+	// Duplicate - the message is delivered twice
+	Duplicate float64
+	// Reorder - the message is delayed behind up to MaxDelay later messages
+	Reorder float64
+	// Loss - the message is never delivered
+	Loss float64
+	// MaxDelay - how many later messages a reordered message may be delayed behind
+	MaxDelay int
+}
+
+// Config - shape of the generated scenarios
+type Config struct {
+	Rockets  int
+	Messages int
+	Faults   Faults
+	// ReorderWindow - reorder buffer of the service, 0 disables it
+	ReorderWindow int
+	// Actors - the service applies the updates of every rocket on its actor instead of locking
+	Actors bool
+}
+
+// maxWait - how long the reorder buffer of the simulated service keeps a gap open
+const maxWait = time.Minute
+
+// start - simulated time the scenarios begin at
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Scenario - generated message streams of the rockets and their delivery schedules
+type Scenario struct {
+	Seed   int64
+	Config Config
+	// Streams - messages of every rocket in the order they were sent
+	Streams map[uuid.UUID][]rocket.TelemetryMessage
+	// Deliveries - messages of every rocket in the order they are delivered
+	Deliveries map[uuid.UUID][]rocket.TelemetryMessage
+}
+
+// Generate builds a scenario from the seed, the same seed always gives the same scenario.
+func Generate(seed int64, cfg Config) Scenario {
+	r := rand.New(rand.NewSource(seed))
+	sc := Scenario{
+		Seed:       seed,
+		Config:     cfg,
+		Streams:    make(map[uuid.UUID][]rocket.TelemetryMessage, cfg.Rockets),
+		Deliveries: make(map[uuid.UUID][]rocket.TelemetryMessage, cfg.Rockets),
+	}
+	for i := 0; i < cfg.Rockets; i++ {
+		var id uuid.UUID
+		r.Read(id[:])
+		stream := stream(r, id, start, 1+r.Intn(cfg.Messages))
+		sc.Streams[id] = stream
+		sc.Deliveries[id] = schedule(r, stream, cfg.Faults)
+	}
+	return sc
+}
+
+// stream generates n messages of a rocket, starting with its launch
+func stream(r *rand.Rand, id uuid.UUID, start time.Time, n int) []rocket.TelemetryMessage {
+	messages := make([]rocket.TelemetryMessage, 0, n)
+	for i := 1; i <= n; i++ {
+		md := rocket.MessageMetadata{Channel: id, MessageNumber: int64(i), MessageTime: start.Add(time.Duration(i) * time.Second)}
+		var m rocket.Message
+		switch p := r.Intn(100); {
+		case i == 1:
+			md.MessageType = rocket.MessageTypeLaunched
+			m = rocket.Message{Type: ptr(rocket.RocketType("Falcon-9")), LaunchSpeed: ptr(rocket.Speed(r.Intn(5000))), Mission: ptr(rocket.Mission("ARTEMIS"))}
+		case p < 10:
+			md.MessageType = rocket.MessageTypeMissionChanged
+			m = rocket.Message{NewMission: ptr(rocket.Mission([]string{"ARTEMIS", "APOLLO", "GEMINI"}[r.Intn(3)]))}
+		case p < 12:
+			md.MessageType = rocket.MessageTypeExploded
+			m = rocket.Message{Reason: ptr("PRESSURE_VESSEL_FAILURE")}
+		case p < 55:
+			md.MessageType = rocket.MessageTypeSpeedDecreased
+			m = rocket.Message{By: ptr(rocket.Speed(r.Intn(1000)))}
+		default:
+			md.MessageType = rocket.MessageTypeSpeedIncreased
+			m = rocket.Message{By: ptr(rocket.Speed(r.Intn(1000)))}
+		}
+		messages = append(messages, rocket.TelemetryMessage{Metadata: md, Message: m})
+	}
+	return messages
+}
+
+// schedule applies the faults to the stream
+func schedule(r *rand.Rand, stream []rocket.TelemetryMessage, f Faults) []rocket.TelemetryMessage {
+	type slot struct {
+		at  float64
+		msg rocket.TelemetryMessage
+	}
+	var slots []slot
+	for i, msg := range stream {
+		if r.Float64() < f.Loss {
+			continue
+		}
+		at := float64(i)
+		if f.MaxDelay > 0 && r.Float64() < f.Reorder {
+			at += float64(1+r.Intn(f.MaxDelay)) + 0.5
+		}
+		slots = append(slots, slot{at: at, msg: msg})
+		if r.Float64() < f.Duplicate {
+			slots = append(slots, slot{at: at + r.Float64()*float64(f.MaxDelay+1), msg: msg})
+		}
+	}
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].at < slots[j].at })
+
+	delivery := make([]rocket.TelemetryMessage, len(slots))
+	for i, s := range slots {
+		delivery[i] = s.msg
+	}
+	return delivery
+}
+
+// Result - outcome of a simulated scenario
+type Result struct {
+	States map[uuid.UUID]rocket.State
+	// Violations - broken invariants, empty when the service behaved correctly
+	Violations []string
+}
+
+// Run delivers the scenario to a new service, one goroutine per rocket, and checks the invariants.
+// The service runs on a fake clock, which is advanced past the reorder deadline once everything is delivered.
+func Run(ctx context.Context, sc Scenario) Result {
+	logger := zap.NewNop()
+	fake := clock.NewFake(start)
+	store := rocket.NewInMemoryRocketStore(logger)
+	service := rocket.NewRocketService(store, logger)
+	service.UseClock(fake)
+	service.UseHistory(rocket.NewInMemoryHistoryStore())
+	if sc.Config.ReorderWindow > 0 {
+		service.UseReorderBuffer(sc.Config.ReorderWindow, maxWait)
+	}
+	if sc.Config.Actors {
+		// short enough for actors to be reaped and respawned during a run
+		service.UseActors(4, time.Millisecond)
+	}
+	rec := newRecorder()
+	service.AddListener(rec)
+
+	res := Result{States: make(map[uuid.UUID]rocket.State)}
+	var mu sync.Mutex
+	violate := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		res.Violations = append(res.Violations, fmt.Sprintf(format, args...))
+	}
+
+	var wg sync.WaitGroup
+	for id, delivery := range sc.Deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, msg := range delivery {
+				if _, err := service.ProcessMessage(ctx, msg); err != nil {
+					violate("rocket %s: message #%d rejected: %v", id, msg.Metadata.MessageNumber, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	fake.Advance(maxWait)
+	service.FlushOverdueMessages(ctx)
+
+	for id, delivery := range sc.Deliveries {
+		state, err := service.GetRocketState(ctx, id)
+		if len(delivery) == 0 {
+			if err == nil {
+				violate("rocket %s: state exists without delivered messages", id)
+			}
+			continue
+		}
+		if err != nil {
+			violate("rocket %s: no state after %d delivered messages: %v", id, len(delivery), err)
+			continue
+		}
+		res.States[id] = state
+		checkRocket(id, state, delivery, rec.applied(id), sc.Config, violate)
+	}
+
+	report := service.CheckConsistency(ctx, false)
+	for _, d := range report.Drifts {
+		violate("rocket %s: state drifted from history in %v", d.RocketID, d.Fields)
+	}
+	return res
+}
+
+// checkRocket verifies the final state and the applied messages of a rocket against the model
+func checkRocket(id uuid.UUID, state rocket.State, delivery, applied []rocket.TelemetryMessage, cfg Config, violate func(string, ...any)) {
+	var highest int64
+	for _, msg := range delivery {
+		highest = max(highest, msg.Metadata.MessageNumber)
+	}
+	if state.LastProcessedMessageNumber != highest {
+		violate("rocket %s: last processed message #%d, expected #%d", id, state.LastProcessedMessageNumber, highest)
+	}
+	if state.Version != int64(len(applied)) {
+		violate("rocket %s: version %d after %d applied messages", id, state.Version, len(applied))
+	}
+	for i := 1; i < len(applied); i++ {
+		if applied[i].Metadata.MessageNumber <= applied[i-1].Metadata.MessageNumber {
+			violate("rocket %s: message #%d applied after #%d", id, applied[i].Metadata.MessageNumber, applied[i-1].Metadata.MessageNumber)
+		}
+	}
+
+	// the exact applied sequence is known when no gap had to be skipped for a full buffer
+	expected, exact := model(delivery, cfg)
+	if !exact {
+		return
+	}
+	if !sameNumbers(applied, expected) {
+		violate("rocket %s: applied %v, expected %v", id, numbers(applied), numbers(expected))
+		return
+	}
+	if want := replay(expected); !reflect.DeepEqual(state, want) {
+		violate("rocket %s: state %+v, expected %+v", id, state, want)
+	}
+}
+
+// model returns the messages the service is expected to apply, in order
+func model(delivery []rocket.TelemetryMessage, cfg Config) ([]rocket.TelemetryMessage, bool) {
+	if cfg.ReorderWindow == 0 {
+		// without reordering only messages newer than every earlier one are applied
+		var applied []rocket.TelemetryMessage
+		var last int64
+		for _, msg := range delivery {
+			if msg.Metadata.MessageNumber > last {
+				applied = append(applied, msg)
+				last = msg.Metadata.MessageNumber
+			}
+		}
+		return applied, true
+	}
+
+	distinct := make(map[int64]rocket.TelemetryMessage)
+	for _, msg := range delivery {
+		distinct[msg.Metadata.MessageNumber] = msg
+	}
+	if len(distinct) > cfg.ReorderWindow {
+		return nil, false
+	}
+	applied := make([]rocket.TelemetryMessage, 0, len(distinct))
+	for _, msg := range distinct {
+		applied = append(applied, msg)
+	}
+	sort.Slice(applied, func(i, j int) bool { return applied[i].Metadata.MessageNumber < applied[j].Metadata.MessageNumber })
+	return applied, true
+}
+
+// replay applies the messages in order to a new service, the reference for the expected state
+func replay(messages []rocket.TelemetryMessage) rocket.State {
+	service := rocket.NewRocketService(rocket.NewInMemoryRocketStore(zap.NewNop()), zap.NewNop())
+	for _, msg := range messages {
+		_, _ = service.ProcessMessage(context.Background(), msg)
+	}
+	state, _ := service.GetRocketState(context.Background(), messages[0].Metadata.Channel)
+	return state
+}
+
+func sameNumbers(a, b []rocket.TelemetryMessage) bool {
+	return reflect.DeepEqual(numbers(a), numbers(b))
+}
+
+func numbers(messages []rocket.TelemetryMessage) []int64 {
+	n := make([]int64, len(messages))
+	for i, msg := range messages {
+		n[i] = msg.Metadata.MessageNumber
+	}
+	return n
+}
+
+var _ rocket.Listener = (*recorder)(nil)
+
+// recorder keeps the messages applied by the service per rocket
+type recorder struct {
+	mu       sync.Mutex
+	messages map[uuid.UUID][]rocket.TelemetryMessage
+}
+
+func newRecorder() *recorder {
+	return &recorder{messages: make(map[uuid.UUID][]rocket.TelemetryMessage)}
+}
+
+// StateChanged records the applied message
+func (r *recorder) StateChanged(_ context.Context, msg rocket.TelemetryMessage, _, _ rocket.State) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[msg.Metadata.Channel] = append(r.messages[msg.Metadata.Channel], msg)
+}
+
+func (r *recorder) applied(id uuid.UUID) []rocket.TelemetryMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.messages[id]
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,61 @@
+package sim
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSimulation(t *testing.T) {
+	configs := map[string]Config{
+		"in order": {
+			Rockets: 4, Messages: 30,
+		},
+		"faults without reordering": {
+			Rockets: 4, Messages: 30,
+			Faults: Faults{Duplicate: 0.2, Reorder: 0.3, Loss: 0.1, MaxDelay: 5},
+		},
+		"faults with reordering": {
+			Rockets: 4, Messages: 30, ReorderWindow: 64,
+			Faults: Faults{Duplicate: 0.2, Reorder: 0.3, Loss: 0.1, MaxDelay: 5},
+		},
+		"faults with reordering on actors": {
+			Rockets: 4, Messages: 30, ReorderWindow: 64, Actors: true,
+			Faults: Faults{Duplicate: 0.2, Reorder: 0.3, Loss: 0.1, MaxDelay: 5},
+		},
+		"faults with a small reorder window": {
+			Rockets: 4, Messages: 30, ReorderWindow: 2,
+			Faults: Faults{Duplicate: 0.2, Reorder: 0.5, Loss: 0.2, MaxDelay: 8},
+		},
+	}
+
+	seeds := 1000
+	if testing.Short() {
+		seeds = 100
+	}
+	for name, cfg := range configs {
+		t.Run(name, func(t *testing.T) {
+			for seed := int64(1); seed <= int64(seeds); seed++ {
+				res := Run(context.Background(), Generate(seed, cfg))
+				if len(res.Violations) > 0 {
+					t.Fatalf("Seed %d broke invariants:\n%s", seed, strings.Join(res.Violations, "\n"))
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	cfg := Config{Rockets: 3, Messages: 20, Faults: Faults{Duplicate: 0.3, Reorder: 0.3, Loss: 0.1, MaxDelay: 3}}
+	a, b := Generate(42, cfg), Generate(42, cfg)
+	for id, delivery := range a.Deliveries {
+		if len(b.Deliveries[id]) != len(delivery) {
+			t.Fatalf("Expected the same scenario for the same seed")
+		}
+		for i := range delivery {
+			if delivery[i].Metadata != b.Deliveries[id][i].Metadata {
+				t.Fatalf("Expected the same delivery schedule for the same seed")
+			}
+		}
+	}
+}
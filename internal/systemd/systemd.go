@@ -0,0 +1,117 @@
+// Package systemd integrates the service with systemd on hosts without an orchestrator: socket activation
+// (sd_listen_fds) and readiness and watchdog notifications (sd_notify). Both are driven by the environment
+// systemd sets for the process and do nothing when it is not set.
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listenFDsStart - first file descriptor passed by socket activation
+const listenFDsStart = 3
+
+// Notification states of sd_notify
+const (
+	StateReady    = "READY=1"
+	StateStopping = "STOPPING=1"
+	StateWatchdog = "WATCHDOG=1"
+)
+
+// Listeners returns the listeners passed by socket activation, none if the process was not socket activated.
+// The activation variables are unset, so child processes don't inherit them.
+func Listeners() ([]net.Listener, error) {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	var listeners []net.Listener
+	for i := range n {
+		name := "LISTEN_FD_" + strconv.Itoa(listenFDsStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(listenFDsStart+i), name)
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("can't use activated socket %s: %w", name, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// Notify sends the state to the service manager, returning false if the process is not supervised by it
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// abstract socket addresses are passed with a leading @
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("can't connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("can't notify %s: %w", state, err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout of the service, false if the watchdog is not enabled for the process
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// RunWatchdog pings the watchdog at half its timeout until the context is done, as the manual recommends, as
+// long as healthy returns nil. Failed and skipped pings are logged, the manager restarts the service once they
+// stay missing for the timeout.
+func RunWatchdog(ctx context.Context, timeout time.Duration, healthy func() error, logger *zap.Logger) error {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := healthy(); err != nil {
+				logger.Error("Skipping systemd watchdog ping, the service is stalled", zap.Error(err))
+				continue
+			}
+			if _, err := Notify(StateWatchdog); err != nil {
+				logger.Warn("Can't ping systemd watchdog", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if ok, err := Notify(StateReady); ok || err != nil {
+		t.Errorf("Expected no notification without NOTIFY_SOCKET, got %v, %v", ok, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("ListenUnixgram failed: %v", err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	if ok, err := Notify(StateReady); !ok || err != nil {
+		t.Fatalf("Expected the notification to be sent, got %v, %v", ok, err)
+	}
+	buf := make([]byte, 64)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != StateReady {
+		t.Errorf("Expected %q, got %q, %v", StateReady, buf[:n], err)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	if timeout, ok := WatchdogInterval(); !ok || timeout != 30*time.Second {
+		t.Errorf("Expected a 30s watchdog, got %s, %v", timeout, ok)
+	}
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	if _, ok := WatchdogInterval(); ok {
+		t.Errorf("Expected the watchdog of another process to be ignored")
+	}
+	t.Setenv("WATCHDOG_USEC", "")
+	if _, ok := WatchdogInterval(); ok {
+		t.Errorf("Expected no watchdog without WATCHDOG_USEC")
+	}
+}
+
+func TestListeners_NotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	listeners, err := Listeners()
+	if err != nil || len(listeners) != 0 {
+		t.Errorf("Expected no listeners passed to another process, got %v, %v", listeners, err)
+	}
+	if _, ok := os.LookupEnv("LISTEN_FDS"); ok {
+		t.Errorf("Expected the activation variables to be unset")
+	}
+}
+
+// TestListeners_Activated passes a listening socket to a child process like systemd does: as fd 3 with LISTEN_PID
+// set to the child's pid, which the child sets itself as it is not known before the start
+func TestListeners_Activated(t *testing.T) {
+	if os.Getenv("SYSTEMD_TEST_CHILD") == "1" {
+		_ = os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		listeners, err := Listeners()
+		if err != nil || len(listeners) != 1 {
+			fmt.Printf("listeners: %v, %v\n", listeners, err)
+			os.Exit(1)
+		}
+		fmt.Printf("addr=%s\n", listeners[0].Addr())
+		os.Exit(0)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer l.Close()
+	f, err := l.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("Can't get the socket file: %v", err)
+	}
+	defer f.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestListeners_Activated$")
+	cmd.Env = append(os.Environ(), "SYSTEMD_TEST_CHILD=1", "LISTEN_FDS=1", "LISTEN_FDNAMES=api")
+	cmd.ExtraFiles = []*os.File{f}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Child failed: %v: %s", err, out)
+	}
+	if !strings.Contains(string(out), "addr="+l.Addr().String()) {
+		t.Errorf("Expected the child to listen on %s, got %s", l.Addr(), out)
+	}
+}
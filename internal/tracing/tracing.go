@@ -0,0 +1,191 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID, SpanID - W3C trace context identifiers, all zeros is invalid
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext - identity of a span propagated between processes
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Sampled - the caller records the trace
+	Sampled bool
+}
+
+// Valid reports whether both ids are set
+func (sc SpanContext) Valid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// ParseTraceparent parses a W3C traceparent header value ("00-<trace id>-<parent id>-<flags>").
+// Versions above 00 are parsed by their first four fields, as the specification requires.
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, fmt.Errorf("malformed traceparent %q", value)
+	}
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("unsupported traceparent version in %q", value)
+	}
+
+	var sc SpanContext
+	var flags [1]byte
+	for _, f := range []struct {
+		dst []byte
+		src string
+	}{{sc.TraceID[:], parts[1]}, {sc.SpanID[:], parts[2]}, {flags[:], parts[3]}} {
+		if strings.ToLower(f.src) != f.src {
+			return SpanContext{}, fmt.Errorf("malformed traceparent %q", value)
+		}
+		if _, err := hex.Decode(f.dst, []byte(f.src)); err != nil {
+			return SpanContext{}, fmt.Errorf("malformed traceparent %q: %w", value, err)
+		}
+	}
+	if !sc.Valid() {
+		return SpanContext{}, fmt.Errorf("traceparent %q has zero ids", value)
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+// Traceparent formats the span context as a W3C traceparent header value
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// Kind - role of the span in the trace, as numbered by OTLP
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindConsumer Kind = 5
+)
+
+// Span - timed operation of a trace. Methods of a nil span do nothing, so callers don't check whether tracing is on.
+type Span struct {
+	Name    string
+	Kind    Kind
+	Context SpanContext
+	// Parent - span of the caller, zero for a root span
+	Parent SpanID
+	Start  time.Time
+	End    time.Time
+	// Error - description of the failure, empty when the operation succeeded
+	Error string
+
+	mu         sync.Mutex
+	attributes map[string]any
+	tracer     *Tracer
+}
+
+// SetAttribute records a string, bool, integer or float attribute of the span
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attributes == nil {
+		s.attributes = make(map[string]any)
+	}
+	s.attributes[key] = value
+}
+
+// Attributes returns a copy of the recorded attributes
+func (s *Span) Attributes() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attrs := make(map[string]any, len(s.attributes))
+	for k, v := range s.attributes {
+		attrs[k] = v
+	}
+	return attrs
+}
+
+// Fail marks the span as failed with the error
+func (s *Span) Fail(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.Error = err.Error()
+}
+
+// Finish ends the span and hands it to the recorder
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.End = s.tracer.now()
+	s.tracer.recorder.RecordSpan(s)
+}
+
+// Recorder - receives finished spans, e.g. to export them
+type Recorder interface {
+	RecordSpan(span *Span)
+}
+
+// Tracer starts spans and hands the finished ones to the recorder. A nil tracer starts no spans.
+type Tracer struct {
+	recorder Recorder
+	now      func() time.Time
+}
+
+// NewTracer creates a tracer recording finished spans with the recorder.
+func NewTracer(recorder Recorder) *Tracer {
+	return &Tracer{recorder: recorder, now: time.Now}
+}
+
+type ctxKey struct{}
+
+// Start starts a span, a child of the span carried by the context if any, and returns the context carrying it.
+// Only sampled traces are recorded; a remote caller decides with its traceparent, new traces are always sampled.
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	parent, ok := FromContext(ctx)
+	if ok && !parent.Sampled {
+		return ctx, nil
+	}
+	span := &Span{Name: name, Kind: kind, Start: t.now(), tracer: t}
+	span.Context.Sampled = true
+	span.Context.TraceID = parent.TraceID
+	span.Parent = parent.SpanID
+	if !ok {
+		_, _ = rand.Read(span.Context.TraceID[:])
+	}
+	_, _ = rand.Read(span.Context.SpanID[:])
+	return WithSpanContext(ctx, span.Context), span
+}
+
+// WithSpanContext returns a copy of the context carrying the span context, e.g. one received from a producer.
+func WithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, ctxKey{}, sc)
+}
+
+// FromContext returns the span context carried by the context.
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(ctxKey{}).(SpanContext)
+	return sc, ok
+}
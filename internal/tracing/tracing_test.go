@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		sampled bool
+		wantErr bool
+	}{
+		{name: "sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sampled: true},
+		{name: "not sampled", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{name: "future version with extra fields", value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", want: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sampled: true},
+		{name: "version 00 with extra fields", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", wantErr: true},
+		{name: "invalid version", value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "zero trace id", value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", wantErr: true},
+		{name: "zero span id", value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", wantErr: true},
+		{name: "uppercase", value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", wantErr: true},
+		{name: "short trace id", value: "00-4bf92f3577b34da6-00f067aa0ba902b7-01", wantErr: true},
+		{name: "not hex", value: "00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", sc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if sc.Traceparent() != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, sc.Traceparent())
+			}
+			if sc.Sampled != tt.sampled {
+				t.Errorf("Expected sampled %v, got %v", tt.sampled, sc.Sampled)
+			}
+		})
+	}
+}
+
+type recorder []*Span
+
+func (r *recorder) RecordSpan(span *Span) {
+	*r = append(*r, span)
+}
+
+func TestTracer_Start(t *testing.T) {
+	var spans recorder
+	tracer := NewTracer(&spans)
+
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, server := tracer.Start(WithSpanContext(context.Background(), parent), "POST /messages", KindServer)
+	_, child := tracer.Start(ctx, "rocket.ProcessMessage", KindInternal)
+	child.SetAttribute("message.number", int64(3))
+	child.Finish()
+	server.Finish()
+
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 recorded spans, got %d", len(spans))
+	}
+	if server.Context.TraceID != parent.TraceID || server.Parent != parent.SpanID {
+		t.Errorf("Expected the server span to continue the producer's trace, got %s parent %s", server.Context.Traceparent(), server.Parent)
+	}
+	if child.Context.TraceID != parent.TraceID || child.Parent != server.Context.SpanID {
+		t.Errorf("Expected the child span to be a child of the server span, got %s parent %s", child.Context.Traceparent(), child.Parent)
+	}
+	if child.Attributes()["message.number"] != int64(3) {
+		t.Errorf("Expected the attribute to be recorded, got %v", child.Attributes())
+	}
+
+	_, root := tracer.Start(context.Background(), "root", KindServer)
+	if !root.Context.Valid() || root.Parent != (SpanID{}) {
+		t.Errorf("Expected a new root span, got %s parent %s", root.Context.Traceparent(), root.Parent)
+	}
+
+	parent.Sampled = false
+	if _, span := tracer.Start(WithSpanContext(context.Background(), parent), "unsampled", KindServer); span != nil {
+		t.Errorf("Expected no span for a trace the producer doesn't sample")
+	}
+
+	var none *Tracer
+	_, span := none.Start(context.Background(), "disabled", KindServer)
+	span.SetAttribute("ignored", true)
+	span.Finish()
+}
@@ -0,0 +1,145 @@
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"rockets/internal/clock"
+	"rockets/internal/config"
+	"rockets/internal/logging"
+	"rockets/internal/retry"
+	"rockets/internal/rocket"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Exporter periodically pushes a speed and status sample of every rocket to InfluxDB in line protocol,
+// so long-term trends live outside the service
+type Exporter struct {
+	rockets rocket.Service
+	cfg     config.TSDB
+	client  *http.Client
+	retrier *retry.Retrier
+	clock   clock.Clock
+	logger  *zap.Logger
+}
+
+// NewExporter creates an exporter pushing to the write endpoint in the settings.
+func NewExporter(rockets rocket.Service, cfg config.TSDB, logger *zap.Logger) *Exporter {
+	return &Exporter{
+		rockets: rockets,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		clock:   clock.Real{},
+		logger:  logger,
+	}
+}
+
+// UseRetry retries failed pushes with the retrier. Must be called before the exporter runs.
+func (e *Exporter) UseRetry(r *retry.Retrier) {
+	e.retrier = r
+}
+
+// UseClock replaces the system clock timestamping the samples, e.g. with a fake one in tests.
+// Must be called before the exporter runs.
+func (e *Exporter) UseClock(c clock.Clock) {
+	e.clock = c
+}
+
+// Run pushes samples every interval until the context is done, failures are logged.
+func (e *Exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := e.Push(ctx); err != nil {
+				e.logger.Error("Can't push rocket samples", logging.Event(logging.EventDeliveryError), zap.String("url", e.cfg.URL), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Push writes one sample of every rocket, all with the current time
+func (e *Exporter) Push(ctx context.Context) error {
+	states, err := e.rockets.ListAllRockets(ctx, rocket.ListQuery{})
+	if err != nil {
+		return fmt.Errorf("can't list rockets: %w", err)
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	body := Encode(states, e.clock.Now())
+	if e.retrier == nil {
+		return e.write(ctx, body)
+	}
+	return e.retrier.Do(ctx, "tsdb", func(ctx context.Context) error {
+		return e.write(ctx, body)
+	})
+}
+
+// write makes one push attempt, client errors other than 429 are permanent
+func (e *Exporter) write(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("can't create write request: %w", err))
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if e.cfg.Token != "" {
+		req.Header.Set("Authorization", "Token "+e.cfg.Token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("can't write samples: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("can't write samples: unexpected status %s", resp.Status)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// Encode renders the states as "rocket" points of the line protocol with nanosecond timestamps:
+// the id, type, mission and status are tags, the speed and last processed message number fields.
+func Encode(states []rocket.State, ts time.Time) []byte {
+	var b bytes.Buffer
+	for _, state := range states {
+		b.WriteString("rocket,id=")
+		b.WriteString(state.ID.String())
+		writeTag(&b, "type", string(state.Type))
+		writeTag(&b, "mission", string(state.Mission))
+		writeTag(&b, "status", string(state.Status))
+		b.WriteString(" speed=")
+		b.WriteString(strconv.FormatInt(int64(state.CurrentSpeed), 10))
+		b.WriteString("i,message_number=")
+		b.WriteString(strconv.FormatInt(state.LastProcessedMessageNumber, 10))
+		b.WriteString("i ")
+		b.WriteString(strconv.FormatInt(ts.UnixNano(), 10))
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// tagEscaper escapes the characters special in tag values of the line protocol
+var tagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// writeTag appends the tag, empty values are not allowed by the line protocol and skipped
+func writeTag(b *bytes.Buffer, key, value string) {
+	if value == "" {
+		return
+	}
+	b.WriteByte(',')
+	b.WriteString(key)
+	b.WriteByte('=')
+	b.WriteString(tagEscaper.Replace(value))
+}
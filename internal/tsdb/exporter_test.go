@@ -0,0 +1,59 @@
+package tsdb
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rockets/internal/clock"
+	"rockets/internal/config"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	id := uuid.MustParse("193270a9-c9cf-404a-8f83-838e71d9ae67")
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	got := string(Encode([]rocket.State{
+		{ID: id, Type: "Falcon-9", Mission: "APOLLO 11", Status: rocket.StatusLaunched, CurrentSpeed: 3500, LastProcessedMessageNumber: 42},
+		{ID: id, Status: rocket.StatusExploded, LastProcessedMessageNumber: 1},
+	}, ts))
+
+	want := "rocket,id=193270a9-c9cf-404a-8f83-838e71d9ae67,type=Falcon-9,mission=APOLLO\\ 11,status=LAUNCHED speed=3500i,message_number=42i 1704067200000000000\n" +
+		"rocket,id=193270a9-c9cf-404a-8f83-838e71d9ae67,status=EXPLODED speed=0i,message_number=1i 1704067200000000000\n"
+	if got != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestExporter_Push(t *testing.T) {
+	var body, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	logger := zap.NewNop()
+	store := rocket.NewInMemoryRocketStore(logger)
+	state := rocket.State{ID: uuid.New(), Type: "Falcon-9", Mission: "ARTEMIS", Status: rocket.StatusLaunched, CurrentSpeed: 500, LastProcessedMessageNumber: 1, Version: 1}
+	store.SaveRocket(state)
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	exporter := NewExporter(rocket.NewRocketService(store, logger), config.TSDB{URL: srv.URL, Token: "secret", Interval: time.Second}, logger)
+	exporter.UseClock(clock.NewFake(ts))
+	if err := exporter.Push(context.Background()); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if want := string(Encode([]rocket.State{state}, ts)); body != want {
+		t.Errorf("Expected %q, got %q", want, body)
+	}
+	if auth != "Token secret" {
+		t.Errorf("Expected the token to be sent, got %q", auth)
+	}
+}
@@ -0,0 +1,74 @@
+"use strict";
+
+// The dashboard polls the public rockets API, so it works behind any proxy serving both under one origin.
+const api = "../v1/rockets";
+const refreshMs = 5000;
+
+let sortBy = "id";
+let sortOrder = "asc";
+let selected = null;
+
+function cell(text, className) {
+  const td = document.createElement("td");
+  td.textContent = text;
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+async function loadRockets() {
+  const resp = await fetch(`${api}?sortBy=${sortBy}&sortOrder=${sortOrder}`);
+  if (!resp.ok) {
+    throw new Error(`list rockets: ${resp.status}`);
+  }
+  const rockets = (await resp.json()) || [];
+
+  const body = document.getElementById("rockets");
+  body.replaceChildren(...rockets.map((r) => {
+    const tr = document.createElement("tr");
+    tr.append(
+      cell(r.id),
+      cell(r.type),
+      cell(r.mission),
+      cell(r.status, r.status),
+      cell(r.currentSpeed),
+      cell(new Date(r.lastUpdateTime).toLocaleString()),
+    );
+    tr.addEventListener("click", () => showRocket(r.id));
+    return tr;
+  }));
+  document.getElementById("updated").textContent = `updated ${new Date().toLocaleTimeString()}`;
+}
+
+async function showRocket(id) {
+  selected = id;
+  const resp = await fetch(`${api}/${id}`);
+  const details = document.getElementById("details");
+  document.getElementById("details-title").textContent = id;
+  document.getElementById("details-body").textContent = JSON.stringify(await resp.json(), null, 2);
+  details.hidden = false;
+}
+
+async function refresh() {
+  try {
+    await loadRockets();
+    if (selected) {
+      await showRocket(selected);
+    }
+  } catch (err) {
+    document.getElementById("updated").textContent = err.message;
+  }
+}
+
+document.querySelectorAll("th[data-sort]").forEach((th) => {
+  th.addEventListener("click", () => {
+    const field = th.dataset.sort;
+    sortOrder = sortBy === field && sortOrder === "asc" ? "desc" : "asc";
+    sortBy = field;
+    refresh();
+  });
+});
+
+refresh();
+setInterval(refresh, refreshMs);
@@ -0,0 +1,18 @@
+package ui
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed dist
+var dist embed.FS
+
+// Assets returns the built dashboard assets, rooted at the directory containing index.html.
+func Assets() fs.FS {
+	assets, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err) // the embedded directory always exists
+	}
+	return assets
+}
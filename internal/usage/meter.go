@@ -0,0 +1,136 @@
+package usage
+
+import (
+	"errors"
+	"rockets/internal/clock"
+	"sort"
+	"sync"
+	"time"
+)
+
+// retentionDays - number of days of usage kept for billing
+const retentionDays = 31
+
+// ErrQuotaExceeded - the producer exhausted its daily quota
+var ErrQuotaExceeded = errors.New("daily quota exceeded")
+
+// Quota - daily limits per producer, zero means unlimited
+type Quota struct {
+	Messages int64
+	Bytes    int64
+}
+
+// Usage - accounted traffic of a producer during a single UTC day
+type Usage struct {
+	Producer string
+	Date     time.Time
+	Messages int64
+	Bytes    int64
+}
+
+// Remaining returns how many messages and bytes are left within the quota; negative values mean unlimited
+func (u Usage) Remaining(q Quota) (messages, bytes int64) {
+	messages, bytes = -1, -1
+	if q.Messages > 0 {
+		messages = max(q.Messages-u.Messages, 0)
+	}
+	if q.Bytes > 0 {
+		bytes = max(q.Bytes-u.Bytes, 0)
+	}
+	return messages, bytes
+}
+
+type dayKey struct {
+	producer string
+	date     time.Time
+}
+
+// Meter accounts messages and bytes per producer per day and enforces the daily quota
+type Meter struct {
+	mu    sync.Mutex
+	quota Quota
+	clock clock.Clock
+	days  map[dayKey]*Usage
+}
+
+// NewMeter creates a meter enforcing the given daily quota.
+func NewMeter(quota Quota) *Meter {
+	return &Meter{
+		quota: quota,
+		clock: clock.Real{},
+		days:  make(map[dayKey]*Usage),
+	}
+}
+
+// UseClock replaces the system clock deciding the current day, e.g. with a fake one in tests.
+// Must be called before the meter is used.
+func (m *Meter) UseClock(c clock.Clock) {
+	m.clock = c
+}
+
+// Quota returns the daily quota enforced per producer
+func (m *Meter) Quota() Quota {
+	return m.quota
+}
+
+// Record accounts the messages of a request of the given size, returning ErrQuotaExceeded without accounting
+// them if they do not fit into the remaining daily quota.
+func (m *Meter) Record(producer string, messages, bytes int64) (Usage, error) {
+	date := m.clock.Now().UTC().Truncate(24 * time.Hour)
+	key := dayKey{producer: producer, date: date}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.days[key]
+	if !ok {
+		m.prune(date)
+		u = &Usage{Producer: producer, Date: date}
+		m.days[key] = u
+	}
+
+	if (m.quota.Messages > 0 && u.Messages+messages > m.quota.Messages) || (m.quota.Bytes > 0 && u.Bytes+bytes > m.quota.Bytes) {
+		return *u, ErrQuotaExceeded
+	}
+	u.Messages += messages
+	u.Bytes += bytes
+	return *u, nil
+}
+
+// prune drops days that fell out of the retention window
+func (m *Meter) prune(today time.Time) {
+	oldest := today.Add(-retentionDays * 24 * time.Hour)
+	for key := range m.days {
+		if key.date.Before(oldest) {
+			delete(m.days, key)
+		}
+	}
+}
+
+// List returns the retained usage ordered by date and producer
+func (m *Meter) List() []Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usages := make([]Usage, 0, len(m.days))
+	for _, u := range m.days {
+		usages = append(usages, *u)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if !usages[i].Date.Equal(usages[j].Date) {
+			return usages[i].Date.Before(usages[j].Date)
+		}
+		return usages[i].Producer < usages[j].Producer
+	})
+	return usages
+}
+
+// ResetTime returns when the current daily quota window ends
+func (m *Meter) ResetTime() time.Time {
+	return m.clock.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// RetryAfter returns how long until the current daily quota window ends
+func (m *Meter) RetryAfter() time.Duration {
+	now := m.clock.Now()
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+}
@@ -0,0 +1,89 @@
+package usage
+
+import (
+	"errors"
+	"rockets/internal/clock"
+	"testing"
+	"time"
+)
+
+func TestMeter_Record(t *testing.T) {
+	m := NewMeter(Quota{Messages: 3, Bytes: 250})
+
+	for i := 0; i < 2; i++ {
+		if _, err := m.Record("alpha", 1, 100); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	// does not fit into the bytes quota
+	u, err := m.Record("alpha", 1, 100)
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if u.Messages != 2 || u.Bytes != 200 {
+		t.Errorf("Rejected message must not be accounted, got %+v", u)
+	}
+
+	u, err = m.Record("alpha", 1, 50)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if messages, bytes := u.Remaining(m.Quota()); messages != 0 || bytes != 0 {
+		t.Errorf("Expected no remaining quota, got %d messages and %d bytes", messages, bytes)
+	}
+	if _, err := m.Record("alpha", 1, 1); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded after message quota is exhausted, got %v", err)
+	}
+
+	// quotas are per producer
+	if _, err := m.Record("beta", 1, 10); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	usages := m.List()
+	if len(usages) != 2 || usages[0].Producer != "alpha" || usages[1].Producer != "beta" {
+		t.Fatalf("Unexpected usage list: %+v", usages)
+	}
+	if usages[0].Messages != 3 || usages[0].Bytes != 250 {
+		t.Errorf("Unexpected alpha usage: %+v", usages[0])
+	}
+}
+
+func TestUsage_RemainingUnlimited(t *testing.T) {
+	messages, bytes := Usage{Messages: 10, Bytes: 1000}.Remaining(Quota{})
+	if messages != -1 || bytes != -1 {
+		t.Errorf("Expected unlimited quota to report -1, got %d and %d", messages, bytes)
+	}
+}
+
+func TestMeter_DailyWindow(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC))
+	m := NewMeter(Quota{Messages: 1})
+	m.UseClock(fake)
+
+	if _, err := m.Record("alpha", 1, 1); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, err := m.Record("alpha", 1, 1); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if got := m.RetryAfter(); got != time.Hour {
+		t.Errorf("Expected quota reset in 1h, got %s", got)
+	}
+
+	// the quota resets the next day
+	fake.Advance(time.Hour)
+	if _, err := m.Record("alpha", 1, 1); err != nil {
+		t.Errorf("Expected quota to reset the next day, got %v", err)
+	}
+
+	// days out of the retention window are dropped
+	fake.Advance(retentionDays * 24 * time.Hour)
+	if _, err := m.Record("alpha", 1, 1); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if usages := m.List(); len(usages) != 2 || !usages[0].Date.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the first day to be pruned, got %+v", usages)
+	}
+}
@@ -0,0 +1,221 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"rockets/internal/config"
+	"rockets/internal/retry"
+	"strings"
+	"time"
+)
+
+var _ Loader = (*BigQuery)(nil)
+
+// BigQuery loads the rows into a BigQuery table through the REST API, streaming every batch with insertAll,
+// so no Google client library is needed
+type BigQuery struct {
+	cfg     config.Warehouse
+	client  *http.Client
+	retrier *retry.Retrier
+}
+
+// NewBigQuery creates a loader into the table in the settings.
+func NewBigQuery(cfg config.Warehouse) *BigQuery {
+	return &BigQuery{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// UseRetry retries failed requests with the retrier. Must be called before the loader is used.
+func (b *BigQuery) UseRetry(r *retry.Retrier) {
+	b.retrier = r
+}
+
+type bqField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Mode string `json:"mode,omitempty"`
+}
+
+type bqSchema struct {
+	Fields []bqField `json:"fields"`
+}
+
+type bqTable struct {
+	TableReference *bqTableReference `json:"tableReference,omitempty"`
+	Schema         bqSchema          `json:"schema"`
+	// TimePartitioning - partitioning of new tables, by the day of the messages
+	TimePartitioning *bqTimePartitioning `json:"timePartitioning,omitempty"`
+}
+
+type bqTableReference struct {
+	ProjectID string `json:"projectId"`
+	DatasetID string `json:"datasetId"`
+	TableID   string `json:"tableId"`
+}
+
+type bqTimePartitioning struct {
+	Type  string `json:"type"`
+	Field string `json:"field"`
+}
+
+type bqInsertRequest struct {
+	SkipInvalidRows bool          `json:"skipInvalidRows"`
+	Rows            []bqInsertRow `json:"rows"`
+}
+
+type bqInsertRow struct {
+	InsertID string `json:"insertId"`
+	JSON     Row    `json:"json"`
+}
+
+type bqInsertResponse struct {
+	InsertErrors []bqInsertError `json:"insertErrors"`
+}
+
+type bqInsertError struct {
+	Index  int       `json:"index"`
+	Errors []bqError `json:"errors"`
+}
+
+type bqError struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// EnsureSchema creates the table partitioned by the day of the messages, or adds the columns it lacks.
+// Columns are nullable, the only kind BigQuery lets be added to an existing table.
+func (b *BigQuery) EnsureSchema(ctx context.Context, columns []Column) error {
+	var table bqTable
+	status, err := b.do(ctx, http.MethodGet, b.tableURL(), nil, &table)
+	switch {
+	case status == http.StatusNotFound:
+		table = bqTable{
+			TableReference:   &bqTableReference{ProjectID: b.cfg.Project, DatasetID: b.cfg.Dataset, TableID: b.cfg.Table},
+			TimePartitioning: &bqTimePartitioning{Type: "DAY", Field: "message_time"},
+		}
+		for _, c := range columns {
+			table.Schema.Fields = append(table.Schema.Fields, bqField{Name: c.Name, Type: c.Type, Mode: "NULLABLE"})
+		}
+		if _, err := b.do(ctx, http.MethodPost, b.datasetURL()+"/tables", table, nil); err != nil {
+			return fmt.Errorf("can't create table: %w", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("can't get table: %w", err)
+	}
+
+	existing := make(map[string]bool, len(table.Schema.Fields))
+	for _, f := range table.Schema.Fields {
+		existing[strings.ToLower(f.Name)] = true
+	}
+	fields := table.Schema.Fields
+	for _, c := range columns {
+		if !existing[c.Name] {
+			fields = append(fields, bqField{Name: c.Name, Type: c.Type, Mode: "NULLABLE"})
+		}
+	}
+	if len(fields) == len(table.Schema.Fields) {
+		return nil
+	}
+	if _, err := b.do(ctx, http.MethodPatch, b.tableURL(), bqTable{Schema: bqSchema{Fields: fields}}, nil); err != nil {
+		return fmt.Errorf("can't add columns: %w", err)
+	}
+	return nil
+}
+
+// Load streams the rows with insertAll, skipping the invalid ones, which are returned as rejected
+func (b *BigQuery) Load(ctx context.Context, rows []Row) ([]Rejection, error) {
+	req := bqInsertRequest{SkipInvalidRows: true, Rows: make([]bqInsertRow, len(rows))}
+	for i, r := range rows {
+		req.Rows[i] = bqInsertRow{InsertID: r.InsertID(), JSON: r}
+	}
+	var resp bqInsertResponse
+	if _, err := b.do(ctx, http.MethodPost, b.tableURL()+"/insertAll", req, &resp); err != nil {
+		return nil, err
+	}
+	var rejections []Rejection
+	for _, e := range resp.InsertErrors {
+		reasons := make([]string, len(e.Errors))
+		for i, r := range e.Errors {
+			reasons[i] = fmt.Sprintf("%s: %s", r.Reason, r.Message)
+		}
+		rejections = append(rejections, Rejection{Index: e.Index, Reason: strings.Join(reasons, "; ")})
+	}
+	return rejections, nil
+}
+
+func (b *BigQuery) datasetURL() string {
+	return fmt.Sprintf("%s/bigquery/v2/projects/%s/datasets/%s",
+		strings.TrimSuffix(b.cfg.Endpoint, "/"), url.PathEscape(b.cfg.Project), url.PathEscape(b.cfg.Dataset))
+}
+
+func (b *BigQuery) tableURL() string {
+	return b.datasetURL() + "/tables/" + url.PathEscape(b.cfg.Table)
+}
+
+// do sends the request with retries when configured, decoding the response into out when given. The status of
+// the last response is returned, also with the error it caused.
+func (b *BigQuery) do(ctx context.Context, method, target string, in, out any) (int, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return 0, fmt.Errorf("can't marshal request: %w", err)
+		}
+	}
+	var status int
+	send := func(ctx context.Context) error {
+		var err error
+		status, err = b.send(ctx, method, target, body, out)
+		return err
+	}
+	if b.retrier == nil {
+		return status, send(ctx)
+	}
+	return status, b.retrier.Do(ctx, "warehouse", send)
+}
+
+// send makes one attempt, client errors other than 429 are permanent
+func (b *BigQuery) send(ctx context.Context, method, target string, body []byte, out any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, retry.Permanent(fmt.Errorf("can't create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.cfg.TokenFile != "" {
+		// re-read on every request, so the token can be refreshed by an external process
+		token, err := os.ReadFile(b.cfg.TokenFile)
+		if err != nil {
+			return 0, fmt.Errorf("can't read token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("can't send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return resp.StatusCode, retry.Permanent(err)
+		}
+		return resp.StatusCode, err
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("can't decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,269 @@
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"rockets/internal/batch"
+	"rockets/internal/clock"
+	"rockets/internal/logging"
+	"rockets/internal/rocket"
+	"sync"
+	"time"
+)
+
+// maxPendingRows - rows kept between loads, the oldest are dropped while the warehouse is unreachable
+const maxPendingRows = 100000
+
+// maxLoadErrors - rows rejected by the warehouse kept for the admin API, newest first
+const maxLoadErrors = 100
+
+// Column - column of the warehouse table, typed with the BigQuery standard SQL types
+type Column struct {
+	Name string
+	Type string
+}
+
+// Schema - columns of the events table. Columns are only ever added, so tables created by older versions
+// are migrated by adding the missing ones.
+var Schema = []Column{
+	{Name: "rocket_id", Type: "STRING"},
+	{Name: "message_number", Type: "INTEGER"},
+	{Name: "message_time", Type: "TIMESTAMP"},
+	{Name: "message_type", Type: "STRING"},
+	{Name: "rocket_type", Type: "STRING"},
+	{Name: "mission", Type: "STRING"},
+	{Name: "speed", Type: "INTEGER"},
+	{Name: "status", Type: "STRING"},
+	{Name: "reason", Type: "STRING"},
+	{Name: "version", Type: "INTEGER"},
+	{Name: "signature", Type: "STRING"},
+	{Name: "reason_category", Type: "STRING"},
+	{Name: "reason_severity", Type: "STRING"},
+	{Name: "rewrite", Type: "STRING"},
+	{Name: "removed", Type: "BOOLEAN"},
+}
+
+// Row - applied event as loaded into the table, the JSON names are the columns of Schema
+type Row struct {
+	RocketID      string    `json:"rocket_id"`
+	MessageNumber int64     `json:"message_number"`
+	MessageTime   time.Time `json:"message_time"`
+	MessageType   string    `json:"message_type"`
+	RocketType    string    `json:"rocket_type"`
+	Mission       string    `json:"mission"`
+	Speed         int64     `json:"speed"`
+	Status        string    `json:"status"`
+	Reason        *string   `json:"reason,omitempty"`
+	Version       int64     `json:"version"`
+	// Signature - verification of the producer's signature of the message, absent when it has no key
+	Signature string `json:"signature,omitempty"`
+	// ReasonCategory, ReasonSeverity - classification of the reason, absent without one
+	ReasonCategory string `json:"reason_category,omitempty"`
+	ReasonSeverity string `json:"reason_severity,omitempty"`
+	// Rewrite - why the state was written without applying a message, e.g. rollback, absent for applied
+	// messages. The row carries the written state with its last processed message, without a message type.
+	Rewrite string `json:"rewrite,omitempty"`
+	// Removed - the rocket was removed by the rewrite, e.g. merged into another one, the row has its last state
+	Removed bool `json:"removed,omitempty"`
+}
+
+// InsertID identifies the row, so the warehouse can drop a row loaded twice by a retried request
+func (r Row) InsertID() string {
+	if r.Rewrite != "" {
+		return fmt.Sprintf("%s:%d:%d:%s:%t", r.RocketID, r.MessageNumber, r.Version, r.Rewrite, r.Removed)
+	}
+	return fmt.Sprintf("%s:%d:%d", r.RocketID, r.MessageNumber, r.Version)
+}
+
+// Rejection - row of a load the warehouse refused, by its index in the loaded rows
+type Rejection struct {
+	Index  int
+	Reason string
+}
+
+// Loader - warehouse the rows are loaded into
+type Loader interface {
+	// EnsureSchema creates the table with the columns, or adds the columns an existing table lacks
+	EnsureSchema(ctx context.Context, columns []Column) error
+	// Load inserts the rows and returns the ones the warehouse rejected, the others are loaded
+	Load(ctx context.Context, rows []Row) ([]Rejection, error)
+}
+
+// LoadError - row the warehouse rejected, it is not loaded again
+type LoadError struct {
+	Time          time.Time `json:"time"`
+	RocketID      string    `json:"rocketId"`
+	MessageNumber int64     `json:"messageNumber"`
+	Reason        string    `json:"reason"`
+}
+
+// Status - progress of the sink, reported through the admin API
+type Status struct {
+	// Pending - rows waiting for the next load
+	Pending  int   `json:"pending"`
+	Loaded   int64 `json:"loaded"`
+	Rejected int64 `json:"rejected"`
+	// Dropped - rows dropped above the pending limit while the warehouse was unreachable
+	Dropped  int64      `json:"dropped"`
+	LastLoad *time.Time `json:"lastLoad,omitempty"`
+	// LastError - why the last load failed, empty once a load succeeds
+	LastError string `json:"lastError,omitempty"`
+	// Errors - the latest rows the warehouse rejected, newest first
+	Errors []LoadError `json:"errors"`
+}
+
+var _ rocket.RewriteListener = (*Sink)(nil)
+
+// Sink batches the applied events and loads them into the warehouse on a schedule, replacing external ETL
+type Sink struct {
+	loader Loader
+	clock  clock.Clock
+	logger *zap.Logger
+	rows   *batch.Queue[Row]
+	// schemaReady - the table has the schema, only accessed while the rows are flushed
+	schemaReady bool
+
+	mu     sync.Mutex
+	status Status
+}
+
+// NewSink creates a sink loading up to batchSize rows per request.
+func NewSink(loader Loader, batchSize int, logger *zap.Logger) *Sink {
+	return &Sink{
+		loader: loader,
+		clock:  clock.Real{},
+		logger: logger,
+		rows:   batch.NewQueue[Row](batchSize, maxPendingRows),
+	}
+}
+
+// UseClock replaces the system clock timestamping the loads, e.g. with a fake one in tests.
+// Must be called before the sink is used.
+func (s *Sink) UseClock(c clock.Clock) {
+	s.clock = c
+}
+
+// StateChanged queues the applied message for the next load
+func (s *Sink) StateChanged(_ context.Context, msg rocket.TelemetryMessage, _, next rocket.State) {
+	row := Row{
+		RocketID:      next.ID.String(),
+		MessageNumber: msg.Metadata.MessageNumber,
+		MessageTime:   msg.Metadata.MessageTime,
+		MessageType:   string(msg.Metadata.MessageType),
+		RocketType:    string(next.Type),
+		Mission:       string(next.Mission),
+		Speed:         int64(next.CurrentSpeed),
+		Status:        string(next.Status),
+		Version:       next.Version,
+		Signature:     string(msg.Metadata.Signature),
+	}
+	setReason(&row, next)
+	s.rows.Add(row)
+}
+
+// StateRewritten queues the state written without applying a message, e.g. by a rollback, so the table follows
+// the rewrites of the store
+func (s *Sink) StateRewritten(_ context.Context, cause rocket.Rewrite, prev, next rocket.State) {
+	written, removed := next, next.Version == 0
+	if removed {
+		written = prev
+	}
+	row := Row{
+		RocketID:      written.ID.String(),
+		MessageNumber: written.LastProcessedMessageNumber,
+		MessageTime:   written.LastUpdateTime,
+		RocketType:    string(written.Type),
+		Mission:       string(written.Mission),
+		Speed:         int64(written.CurrentSpeed),
+		Status:        string(written.Status),
+		Version:       written.Version,
+		Rewrite:       string(cause),
+		Removed:       removed,
+	}
+	setReason(&row, written)
+	s.rows.Add(row)
+}
+
+func setReason(row *Row, state rocket.State) {
+	if state.Reason != nil {
+		row.Reason = &state.Reason.Text
+		row.ReasonCategory = string(state.Reason.Category)
+		row.ReasonSeverity = string(state.Reason.Severity)
+	}
+}
+
+// Run loads the pending rows every interval until the context is done, then loads once more. Failures are logged.
+func (s *Sink) Run(ctx context.Context, interval time.Duration) error {
+	batch.Run(ctx, interval, s.load)
+	return nil
+}
+
+func (s *Sink) load(ctx context.Context) {
+	if err := s.Flush(ctx); err != nil {
+		s.logger.Error("Can't load events into the warehouse", logging.Event(logging.EventDeliveryError), zap.Error(err))
+	}
+}
+
+// Flush loads the pending rows in batches, making sure the table has the schema before the first one. Rows of
+// a failed load stay pending for the next one; rows the warehouse rejects are reported in the status and not
+// loaded again.
+func (s *Sink) Flush(ctx context.Context) error {
+	return s.rows.Flush(ctx, func(ctx context.Context, rows []Row) error {
+		if !s.schemaReady {
+			if err := s.loader.EnsureSchema(ctx, Schema); err != nil {
+				s.failed(err)
+				return fmt.Errorf("can't prepare the table: %w", err)
+			}
+			s.schemaReady = true
+		}
+		rejections, err := s.loader.Load(ctx, rows)
+		if err != nil {
+			// the table may have been dropped or altered, check it again on the next load
+			s.schemaReady = false
+			s.failed(err)
+			return fmt.Errorf("can't load %d rows: %w", len(rows), err)
+		}
+		s.loaded(rows, rejections)
+		return nil
+	})
+}
+
+func (s *Sink) failed(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastError = err.Error()
+}
+
+func (s *Sink) loaded(batch []Row, rejections []Rejection) {
+	now := s.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Loaded += int64(len(batch) - len(rejections))
+	s.status.Rejected += int64(len(rejections))
+	s.status.LastLoad = &now
+	s.status.LastError = ""
+	for _, r := range rejections {
+		if r.Index < 0 || r.Index >= len(batch) {
+			continue
+		}
+		row := batch[r.Index]
+		s.logger.Warn("Warehouse rejected an event",
+			zap.String("rocket_id", row.RocketID), zap.Int64("message_number", row.MessageNumber), zap.String("reason", r.Reason))
+		s.status.Errors = append([]LoadError{{Time: now, RocketID: row.RocketID, MessageNumber: row.MessageNumber, Reason: r.Reason}}, s.status.Errors...)
+	}
+	if len(s.status.Errors) > maxLoadErrors {
+		s.status.Errors = s.status.Errors[:maxLoadErrors]
+	}
+}
+
+// Status returns the progress of the sink
+func (s *Sink) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := s.status
+	status.Pending = s.rows.Len()
+	status.Dropped = s.rows.Dropped()
+	status.Errors = append([]LoadError{}, s.status.Errors...)
+	return status
+}
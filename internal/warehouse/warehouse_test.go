@@ -0,0 +1,142 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"rockets/internal/config"
+	"rockets/internal/rocket"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBigQuery - the part of the BigQuery API the loader uses, for a single table
+type fakeBigQuery struct {
+	mu    sync.Mutex
+	table *bqTable
+	rows  []bqInsertRow
+	fail  bool
+}
+
+func (f *fakeBigQuery) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	const table = "/bigquery/v2/projects/p/datasets/d/tables/events"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == table:
+		if f.table == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.table)
+	case r.Method == http.MethodPost && r.URL.Path == "/bigquery/v2/projects/p/datasets/d/tables",
+		r.Method == http.MethodPatch && r.URL.Path == table:
+		f.table = &bqTable{}
+		_ = json.NewDecoder(r.Body).Decode(f.table)
+		_ = json.NewEncoder(w).Encode(f.table)
+	case r.Method == http.MethodPost && r.URL.Path == table+"/insertAll":
+		if f.fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req bqInsertRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		// rows without a mission are invalid
+		var resp bqInsertResponse
+		for i, row := range req.Rows {
+			if row.JSON.Mission == "" {
+				resp.InsertErrors = append(resp.InsertErrors, bqInsertError{
+					Index:  i,
+					Errors: []bqError{{Reason: "invalid", Message: "mission is empty"}},
+				})
+				continue
+			}
+			f.rows = append(f.rows, row)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSink(t *testing.T) {
+	fake := &fakeBigQuery{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	token := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(token, []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("Can't write token: %v", err)
+	}
+	loader := NewBigQuery(config.Warehouse{Project: "p", Dataset: "d", Table: "events", TokenFile: token, Endpoint: server.URL})
+	sink := NewSink(loader, 2, zap.NewNop())
+
+	at := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+	apply := func(number int64, mission rocket.Mission) {
+		sink.StateChanged(context.Background(), rocket.TelemetryMessage{
+			Metadata: rocket.MessageMetadata{Channel: uuid.Nil, MessageNumber: number, MessageTime: at, MessageType: rocket.MessageTypeLaunched},
+		}, rocket.State{}, rocket.State{ID: uuid.Nil, Type: "Falcon-9", Mission: mission, Status: rocket.StatusLaunched, Version: number})
+	}
+	apply(1, "ARTEMIS")
+	apply(2, "")
+	apply(3, "ARTEMIS")
+
+	if err := sink.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if fake.table == nil || len(fake.table.Schema.Fields) != len(Schema) || fake.table.TimePartitioning == nil {
+		t.Fatalf("Expected the table created partitioned with the schema, got %+v", fake.table)
+	}
+	if len(fake.rows) != 2 || fake.rows[1].InsertID != "00000000-0000-0000-0000-000000000000:3:3" {
+		t.Fatalf("Expected the valid rows loaded, got %+v", fake.rows)
+	}
+	status := sink.Status()
+	if status.Pending != 0 || status.Loaded != 2 || status.Rejected != 1 || status.LastLoad == nil {
+		t.Errorf("Expected 2 rows loaded and 1 rejected, got %+v", status)
+	}
+	if len(status.Errors) != 1 || status.Errors[0].MessageNumber != 2 || status.Errors[0].Reason != "invalid: mission is empty" {
+		t.Errorf("Expected the rejected row reported, got %+v", status.Errors)
+	}
+
+	// failed loads keep the rows and check the table again
+	fake.fail = true
+	apply(4, "ARTEMIS")
+	if err := sink.Flush(context.Background()); err == nil {
+		t.Fatalf("Expected the load to fail")
+	}
+	if status := sink.Status(); status.Pending != 1 || status.LastError == "" {
+		t.Errorf("Expected the row pending with the error, got %+v", status)
+	}
+
+	// a column missing from the table is added
+	fake.table.Schema.Fields = fake.table.Schema.Fields[:len(Schema)-1]
+	fake.fail = false
+	if err := sink.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(fake.table.Schema.Fields) != len(Schema) || fake.table.Schema.Fields[len(Schema)-1].Name != "removed" {
+		t.Errorf("Expected the missing column added, got %+v", fake.table.Schema.Fields)
+	}
+	if status := sink.Status(); status.Pending != 0 || status.Loaded != 3 || status.LastError != "" {
+		t.Errorf("Expected the pending row loaded, got %+v", status)
+	}
+
+	// a rocket removed by a merge is loaded as a rewrite of its last state
+	removed := rocket.State{ID: uuid.Nil, Type: "Falcon-9", Mission: "ARTEMIS", Status: rocket.StatusLaunched, Version: 4, LastProcessedMessageNumber: 4, LastUpdateTime: at}
+	sink.StateRewritten(context.Background(), rocket.RewriteMerge, removed, rocket.State{})
+	if err := sink.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if last := fake.rows[len(fake.rows)-1]; last.JSON.Rewrite != "merge" || !last.JSON.Removed || last.JSON.MessageNumber != 4 || last.InsertID != "00000000-0000-0000-0000-000000000000:4:4:merge:true" {
+		t.Errorf("Expected the removal loaded as a rewrite, got %+v", last)
+	}
+}
@@ -0,0 +1,237 @@
+// Package watchdog alerts when rockets in flight stop sending telemetry, and again when they are heard from.
+package watchdog
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"rockets/internal/logging"
+	"rockets/internal/notify"
+	"rockets/internal/rocket"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rocketSeen - the last message applied to a rocket in flight
+type rocketSeen struct {
+	mission rocket.Mission
+	// at - when the message was applied, by the local clock
+	at            time.Time
+	messageNumber int64
+	lost          bool
+}
+
+var (
+	_ rocket.Listener          = (*Watchdog)(nil)
+	_ rocket.RewriteListener   = (*Watchdog)(nil)
+	_ rocket.HeartbeatListener = (*Watchdog)(nil)
+)
+
+// Watchdog tracks when the rockets that have not exploded were last heard from, by the local clock so the clocks
+// of the producers don't matter, and alerts a rocket as lost once it has sent nothing for the timeout. A message
+// of a lost rocket resolves the alert; an explosion stops the tracking, it is alerted by the incidents. Pinned
+// rockets, e.g. of long-duration missions going silent for weeks, are never lost. Rockets are tracked from their
+// first message after the start of the instance, or from their state written by a clone or a handoff, until the
+// channel is merged away or, with partitioning, owned by another replica.
+type Watchdog struct {
+	mu      sync.Mutex
+	rockets map[uuid.UUID]*rocketSeen
+	timeout time.Duration
+
+	pins     *rocket.Pins
+	notifier notify.Notifier
+	clock    clock.Clock
+	logger   *zap.Logger
+	// owns - whether the replica owns the channel, nil when it owns every channel
+	owns func(channel uuid.UUID) bool
+}
+
+// NewWatchdog creates a watchdog alerting through the notifier about the rockets silent for the timeout, except
+// the pinned ones; pins may be nil.
+func NewWatchdog(timeout time.Duration, pins *rocket.Pins, notifier notify.Notifier, logger *zap.Logger) *Watchdog {
+	return &Watchdog{
+		rockets:  make(map[uuid.UUID]*rocketSeen),
+		timeout:  timeout,
+		pins:     pins,
+		notifier: notifier,
+		clock:    clock.Real{},
+		logger:   logger,
+	}
+}
+
+// UseClock replaces the system clock the rockets are timed by. Must be called before the watchdog is used.
+func (w *Watchdog) UseClock(c clock.Clock) {
+	w.clock = c
+}
+
+// UseOwner stops tracking the channels the replica doesn't own, e.g. handed off to another replica after the ring
+// changed. Must be called before the watchdog is used.
+func (w *Watchdog) UseOwner(owns func(channel uuid.UUID) bool) {
+	w.owns = owns
+}
+
+// StateChanged records that the rocket was heard from, resolving its alert when it was lost
+func (w *Watchdog) StateChanged(_ context.Context, msg rocket.TelemetryMessage, _, next rocket.State) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	seen, ok := w.rockets[next.ID]
+	if next.Status == rocket.StatusExploded {
+		delete(w.rockets, next.ID)
+	} else {
+		if !ok {
+			seen = &rocketSeen{}
+			w.rockets[next.ID] = seen
+		}
+		seen.mission, seen.at, seen.messageNumber = next.Mission, w.clock.Now(), msg.Metadata.MessageNumber
+	}
+	if !ok || !seen.lost {
+		return
+	}
+	seen.lost = false
+
+	w.logger.Info("Lost rocket heard from again",
+		logging.Event(logging.EventRocketFound),
+		zap.String("rocket_id", next.ID.String()),
+		zap.Int64("msg_num", msg.Metadata.MessageNumber),
+	)
+	w.resolve(next.ID, seen, fmt.Sprintf("Rocket %s is sending telemetry again", next.ID))
+}
+
+// Heartbeat records that the rocket was heard from like any other message
+func (w *Watchdog) Heartbeat(ctx context.Context, msg rocket.TelemetryMessage, next rocket.State) {
+	w.StateChanged(ctx, msg, next, next)
+}
+
+// StateRewritten stops tracking a rocket removed by a merge, and starts tracking one written by a clone or a
+// handoff as if it was heard from. A rollback or another rewrite of a tracked rocket only updates its mission.
+func (w *Watchdog) StateRewritten(_ context.Context, cause rocket.Rewrite, prev, next rocket.State) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if next.ID == uuid.Nil || next.Status == rocket.StatusExploded {
+		id := cmp.Or(next.ID, prev.ID)
+		if seen, ok := w.rockets[id]; ok {
+			w.forgetLocked(id, seen, fmt.Sprintf("Rocket %s is no longer tracked after a %s", id, cause))
+		}
+		return
+	}
+	seen, ok := w.rockets[next.ID]
+	if !ok {
+		seen = &rocketSeen{at: w.clock.Now(), messageNumber: next.LastProcessedMessageNumber}
+		w.rockets[next.ID] = seen
+	}
+	seen.mission = next.Mission
+}
+
+// forgetLocked stops tracking the rocket, resolving its alert when it was lost
+func (w *Watchdog) forgetLocked(id uuid.UUID, seen *rocketSeen, title string) {
+	delete(w.rockets, id)
+	if !seen.lost {
+		return
+	}
+	w.logger.Info("Lost rocket no longer tracked",
+		logging.Event(logging.EventRocketFound),
+		zap.String("rocket_id", id.String()),
+		zap.Int64("msg_num", seen.messageNumber),
+	)
+	w.resolve(id, seen, title)
+}
+
+// resolve resolves the alert of the lost rocket. The state of the rocket is being written, the alert goes out in
+// the background; the timeout leaves room for the retries of the notifier.
+func (w *Watchdog) resolve(id uuid.UUID, seen *rocketSeen, title string) {
+	alert := notify.Alert{
+		Key:      alertKey(id),
+		Action:   notify.ActionResolve,
+		Severity: notify.SeverityInfo,
+		Title:    title,
+		Details:  w.details(id, seen),
+		Time:     w.clock.Now().UTC(),
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		w.notify(ctx, alert)
+	}()
+}
+
+// Run checks the rockets every interval, at least every millisecond, until the context is done
+func (w *Watchdog) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(max(interval, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.Check(ctx)
+		}
+	}
+}
+
+// Check alerts the rockets that have become silent for the timeout since the last check
+func (w *Watchdog) Check(ctx context.Context) {
+	w.mu.Lock()
+	now := w.clock.Now()
+	var alerts []notify.Alert
+	for id, seen := range w.rockets {
+		if w.owns != nil && !w.owns(id) {
+			w.forgetLocked(id, seen, fmt.Sprintf("Rocket %s is tracked by another replica", id))
+			continue
+		}
+		silence := now.Sub(seen.at)
+		if seen.lost || silence < w.timeout || w.pins.Pinned(id, seen.mission) {
+			continue
+		}
+		seen.lost = true
+		w.logger.Warn("Rocket lost",
+			logging.Event(logging.EventRocketLost),
+			zap.String("rocket_id", id.String()),
+			zap.Int64("msg_num", seen.messageNumber),
+			zap.Duration("silence", silence),
+		)
+		alerts = append(alerts, notify.Alert{
+			Key:      alertKey(id),
+			Severity: notify.SeverityCritical,
+			Title:    fmt.Sprintf("Rocket %s lost: no telemetry for %s", id, silence.Truncate(time.Second)),
+			Details:  w.details(id, seen),
+			Time:     now.UTC(),
+		})
+	}
+	w.mu.Unlock()
+
+	for _, alert := range alerts {
+		w.notify(ctx, alert)
+	}
+}
+
+// details returns the details of the alerts of the rocket
+func (w *Watchdog) details(id uuid.UUID, seen *rocketSeen) map[string]string {
+	details := map[string]string{
+		"rocket_id":           id.String(),
+		"last_message_number": strconv.FormatInt(seen.messageNumber, 10),
+		"last_seen":           seen.at.UTC().Format(time.RFC3339),
+	}
+	if seen.mission != "" {
+		details["mission"] = string(seen.mission)
+	}
+	return details
+}
+
+// notify delivers the alert, failures are only logged
+func (w *Watchdog) notify(ctx context.Context, alert notify.Alert) {
+	if w.notifier == nil {
+		return
+	}
+	if err := w.notifier.Notify(ctx, alert); err != nil {
+		w.logger.Error("Can't send lost rocket alert", logging.Event(logging.EventDeliveryError), zap.String("key", alert.Key), zap.Error(err))
+	}
+}
+
+// alertKey returns the key of the alerts of the rocket, so pager services deduplicate them per rocket
+func alertKey(id uuid.UUID) string {
+	return "rocket/" + id.String() + "/lost"
+}
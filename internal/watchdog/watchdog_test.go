@@ -0,0 +1,113 @@
+package watchdog
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"rockets/internal/clock"
+	"rockets/internal/notify"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+// recorder - notifier passing the alerts on, resolutions are sent in the background
+type recorder chan notify.Alert
+
+func (r recorder) Notify(_ context.Context, alert notify.Alert) error {
+	r <- alert
+	return nil
+}
+
+func (r recorder) next(t *testing.T) notify.Alert {
+	t.Helper()
+	select {
+	case alert := <-r:
+		return alert
+	case <-time.After(time.Second):
+		t.Fatal("Expected an alert, got none")
+		return notify.Alert{}
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	fake := clock.NewFake(time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC))
+	alerts := make(recorder, 4)
+	silent, pinned, exploded := uuid.New(), uuid.New(), uuid.New()
+	w := NewWatchdog(5*time.Minute, rocket.NewPins([]uuid.UUID{pinned}, nil, fake.Now()), alerts, zap.NewNop())
+	w.UseClock(fake)
+	ctx := context.Background()
+
+	apply := func(id uuid.UUID, n int64, status rocket.Status) {
+		msg := rocket.TelemetryMessage{Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: n}}
+		w.StateChanged(ctx, msg, rocket.State{}, rocket.State{ID: id, Status: status, Mission: "ARTEMIS"})
+	}
+	apply(silent, 1, rocket.StatusLaunched)
+	apply(pinned, 1, rocket.StatusLaunched)
+	apply(exploded, 1, rocket.StatusLaunched)
+	apply(exploded, 2, rocket.StatusExploded)
+
+	fake.Advance(4 * time.Minute)
+	w.Check(ctx)
+	if len(alerts) != 0 {
+		t.Fatalf("Expected no alert within the timeout, got %+v", <-alerts)
+	}
+
+	fake.Advance(2 * time.Minute)
+	w.Check(ctx)
+	w.Check(ctx)
+	lost := alerts.next(t)
+	if lost.Key != "rocket/"+silent.String()+"/lost" || lost.Action != "" || lost.Severity != notify.SeverityCritical || lost.Details["last_message_number"] != "1" {
+		t.Errorf("Expected the silent rocket alerted as lost, got %+v", lost)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("Expected only the silent rocket alerted once, got %+v", <-alerts)
+	}
+
+	apply(silent, 2, rocket.StatusLaunched)
+	if found := alerts.next(t); found.Key != lost.Key || found.Action != notify.ActionResolve {
+		t.Errorf("Expected the alert resolved once the rocket is heard from, got %+v", found)
+	}
+}
+
+func TestWatchdog_Forget(t *testing.T) {
+	fake := clock.NewFake(time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC))
+	alerts := make(recorder, 4)
+	merged, moved, cloned := uuid.New(), uuid.New(), uuid.New()
+	w := NewWatchdog(5*time.Minute, rocket.NewPins(nil, nil, fake.Now()), alerts, zap.NewNop())
+	w.UseClock(fake)
+	w.UseOwner(func(channel uuid.UUID) bool { return channel != moved })
+	ctx := context.Background()
+
+	for _, id := range []uuid.UUID{merged, moved} {
+		msg := rocket.TelemetryMessage{Metadata: rocket.MessageMetadata{Channel: id, MessageNumber: 1}}
+		w.StateChanged(ctx, msg, rocket.State{}, rocket.State{ID: id, Status: rocket.StatusLaunched})
+	}
+	fake.Advance(6 * time.Minute)
+	w.StateRewritten(ctx, rocket.RewriteMerge, rocket.State{ID: merged, Status: rocket.StatusLaunched}, rocket.State{})
+	w.StateRewritten(ctx, rocket.RewriteClone, rocket.State{}, rocket.State{ID: cloned, Status: rocket.StatusLaunched, LastProcessedMessageNumber: 3})
+	w.Check(ctx)
+	if len(alerts) != 0 {
+		t.Fatalf("Expected no alert for the merged, handed-off and just cloned rockets, got %+v", <-alerts)
+	}
+
+	fake.Advance(6 * time.Minute)
+	w.Check(ctx)
+	lost := alerts.next(t)
+	if lost.Key != "rocket/"+cloned.String()+"/lost" || lost.Details["last_message_number"] != "3" {
+		t.Errorf("Expected the cloned rocket alerted as lost, got %+v", lost)
+	}
+	w.StateRewritten(ctx, rocket.RewriteMerge, rocket.State{ID: cloned, Status: rocket.StatusLaunched}, rocket.State{})
+	if resolved := alerts.next(t); resolved.Key != lost.Key || resolved.Action != notify.ActionResolve {
+		t.Errorf("Expected the alert resolved once the rocket is merged away, got %+v", resolved)
+	}
+}
+
+func TestWatchdog_RunZeroInterval(t *testing.T) {
+	w := NewWatchdog(time.Minute, rocket.NewPins(nil, nil, time.Now()), make(recorder, 1), zap.NewNop())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.Run(ctx, 0); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+// Package watchlist keeps the watchlists of the operators: named, ordered lists of rockets whose states are read
+// at once, e.g. by a dashboard following a handful of rockets out of the whole fleet.
+package watchlist
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"rockets/internal/jsonfile"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MaxRockets - most rockets on a watchlist, as many as a batch-get looks up
+const MaxRockets = 100
+
+var (
+	// ErrInvalidWatchlist - the name is empty, too long or has characters other than letters, digits, '.', '_' and
+	// '-', the description is too long or there are too many rockets
+	ErrInvalidWatchlist = errors.New("invalid watchlist")
+	// ErrWatchlistExists - a watchlist with the name already exists
+	ErrWatchlistExists = errors.New("watchlist already exists")
+	// ErrWatchlistNotFound - no watchlist has the name
+	ErrWatchlistNotFound = errors.New("watchlist not found")
+)
+
+// Watchlist - named list of rockets followed together, in the order they are shown
+type Watchlist struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Rockets     []uuid.UUID `json:"rockets"`
+}
+
+// Registry - watchlists by name, unique regardless of case. A rocket may be on several watchlists. A nil registry
+// has no watchlists.
+type Registry struct {
+	mu sync.RWMutex
+	// watchlists - watchlists by the lower-cased name
+	watchlists map[string]Watchlist
+	// file - JSON file the watchlists are saved to on every change, empty keeps them in memory only
+	file string
+}
+
+// NewRegistry creates a registry keeping the watchlists in memory only.
+func NewRegistry() *Registry {
+	return &Registry{watchlists: make(map[string]Watchlist)}
+}
+
+// Open creates a registry saving the watchlists to the file, loading the ones saved before if it exists.
+func Open(file string) (*Registry, error) {
+	r := NewRegistry()
+	r.file = file
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Refresh loads the watchlists from the file again, picking up the changes saved by the leader while in standby.
+// A registry keeping them in memory only is left as it is.
+func (r *Registry) Refresh() error {
+	if r == nil || r.file == "" {
+		return nil
+	}
+	var watchlists []Watchlist
+	if _, err := jsonfile.Load(r.file, "watchlists", &watchlists); err != nil {
+		return err
+	}
+	byName := make(map[string]Watchlist, len(watchlists))
+	for _, w := range watchlists {
+		byName[strings.ToLower(w.Name)] = w
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.watchlists = byName
+	return nil
+}
+
+// Create adds the watchlist. It fails with ErrInvalidWatchlist or ErrWatchlistExists.
+func (r *Registry) Create(w Watchlist) (Watchlist, error) {
+	w, err := normalize(w)
+	if err != nil {
+		return Watchlist{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(w.Name)
+	if _, ok := r.watchlists[key]; ok {
+		return Watchlist{}, fmt.Errorf("%w: %s", ErrWatchlistExists, w.Name)
+	}
+	r.watchlists[key] = w
+	if err := r.saveLocked(); err != nil {
+		delete(r.watchlists, key)
+		return Watchlist{}, err
+	}
+	return w, nil
+}
+
+// Update replaces the description and the rockets of the watchlist with the name, keeping the name.
+// It fails with ErrInvalidWatchlist or ErrWatchlistNotFound.
+func (r *Registry) Update(name string, w Watchlist) (Watchlist, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(name)
+	prev, ok := r.watchlists[key]
+	if !ok {
+		return Watchlist{}, fmt.Errorf("%w: %s", ErrWatchlistNotFound, name)
+	}
+	w.Name = prev.Name
+	w, err := normalize(w)
+	if err != nil {
+		return Watchlist{}, err
+	}
+	r.watchlists[key] = w
+	if err := r.saveLocked(); err != nil {
+		r.watchlists[key] = prev
+		return Watchlist{}, err
+	}
+	return w, nil
+}
+
+// Delete drops the watchlist with the name, returning false if there is none
+func (r *Registry) Delete(name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(name)
+	prev, ok := r.watchlists[key]
+	if !ok {
+		return false, nil
+	}
+	delete(r.watchlists, key)
+	if err := r.saveLocked(); err != nil {
+		r.watchlists[key] = prev
+		return false, err
+	}
+	return true, nil
+}
+
+// Get returns the watchlist with the name, in any case
+func (r *Registry) Get(name string) (Watchlist, bool) {
+	if r == nil {
+		return Watchlist{}, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	w, ok := r.watchlists[strings.ToLower(name)]
+	return w, ok
+}
+
+// List returns the watchlists sorted by name
+func (r *Registry) List() []Watchlist {
+	if r == nil {
+		return []Watchlist{}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.listLocked()
+}
+
+func (r *Registry) listLocked() []Watchlist {
+	watchlists := make([]Watchlist, 0, len(r.watchlists))
+	for _, w := range r.watchlists {
+		watchlists = append(watchlists, w)
+	}
+	sort.Slice(watchlists, func(i, j int) bool {
+		return watchlists[i].Name < watchlists[j].Name
+	})
+	return watchlists
+}
+
+// normalize validates the watchlist and drops repeated rockets, keeping the order of their first occurrence
+func normalize(w Watchlist) (Watchlist, error) {
+	if !jsonfile.ValidName.MatchString(w.Name) {
+		return Watchlist{}, fmt.Errorf("%w: name %q", ErrInvalidWatchlist, w.Name)
+	}
+	if len(w.Description) > 256 {
+		return Watchlist{}, fmt.Errorf("%w: description longer than 256 characters", ErrInvalidWatchlist)
+	}
+	rockets := make([]uuid.UUID, 0, len(w.Rockets))
+	seen := make(map[uuid.UUID]bool, len(w.Rockets))
+	for _, id := range w.Rockets {
+		if !seen[id] {
+			seen[id] = true
+			rockets = append(rockets, id)
+		}
+	}
+	if len(rockets) > MaxRockets {
+		return Watchlist{}, fmt.Errorf("%w: more than %d rockets", ErrInvalidWatchlist, MaxRockets)
+	}
+	w.Rockets = rockets
+	return w, nil
+}
+
+// saveLocked writes the watchlists to the file
+func (r *Registry) saveLocked() error {
+	if r.file == "" {
+		return nil
+	}
+	return jsonfile.Save(r.file, "watchlists", r.listLocked())
+}
@@ -0,0 +1,60 @@
+package watchlist
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "watchlists.json")
+	r, err := Open(file)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first, second := uuid.New(), uuid.New()
+
+	if _, err := r.Create(Watchlist{Name: "Launch-day", Rockets: []uuid.UUID{first}}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := r.Create(Watchlist{Name: "launch-DAY"}); !errors.Is(err, ErrWatchlistExists) {
+		t.Errorf("Expected ErrWatchlistExists for a name taken in another case, got %v", err)
+	}
+	if _, err := r.Create(Watchlist{Name: "no spaces"}); !errors.Is(err, ErrInvalidWatchlist) {
+		t.Errorf("Expected ErrInvalidWatchlist, got %v", err)
+	}
+	tooMany := make([]uuid.UUID, MaxRockets+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+	if _, err := r.Create(Watchlist{Name: "everything", Rockets: tooMany}); !errors.Is(err, ErrInvalidWatchlist) {
+		t.Errorf("Expected ErrInvalidWatchlist for too many rockets, got %v", err)
+	}
+	if _, err := r.Update("recovery", Watchlist{}); !errors.Is(err, ErrWatchlistNotFound) {
+		t.Errorf("Expected ErrWatchlistNotFound, got %v", err)
+	}
+
+	updated, err := r.Update("launch-day", Watchlist{Name: "ignored", Description: "pad 39A", Rockets: []uuid.UUID{second, first, second}})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Name != "Launch-day" || !reflect.DeepEqual(updated.Rockets, []uuid.UUID{second, first}) {
+		t.Errorf("Expected the watchlist updated under its name keeping the order of the rockets, got %+v", updated)
+	}
+
+	reopened, err := Open(file)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if w, ok := reopened.Get("LAUNCH-DAY"); !ok || !reflect.DeepEqual(w, updated) {
+		t.Errorf("Expected the watchlist to survive a restart, got %+v", w)
+	}
+	if deleted, err := reopened.Delete("launch-day"); !deleted || err != nil {
+		t.Errorf("Expected the watchlist deleted, got %v, %v", deleted, err)
+	}
+	if list := reopened.List(); len(list) != 0 {
+		t.Errorf("Expected no watchlists left, got %+v", list)
+	}
+}
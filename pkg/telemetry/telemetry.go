@@ -0,0 +1,162 @@
+// Package telemetry builds rocket telemetry messages in the format accepted by POST /messages.
+// Constructors validate values with the same rules as the service, so a constructed message
+// is never rejected for its content.
+package telemetry
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"rockets/internal/rocket"
+	"time"
+)
+
+// SignatureHeader - header of POST /messages carrying the signature of the body made by Sign
+const SignatureHeader = "X-Rockets-Signature"
+
+// MessageType - telemetry message type
+type MessageType string
+
+const (
+	RocketLaunched       MessageType = "RocketLaunched"
+	RocketSpeedIncreased MessageType = "RocketSpeedIncreased"
+	RocketSpeedDecreased MessageType = "RocketSpeedDecreased"
+	RocketExploded       MessageType = "RocketExploded"
+	RocketMissionChanged MessageType = "RocketMissionChanged"
+	RocketHeartbeat      MessageType = "RocketHeartbeat"
+)
+
+// Metadata - envelope of a telemetry message
+type Metadata struct {
+	Channel       uuid.UUID   `json:"channel"`
+	MessageNumber int64       `json:"messageNumber"`
+	MessageTime   time.Time   `json:"messageTime"`
+	MessageType   MessageType `json:"messageType"`
+}
+
+// Payload - type specific fields of a telemetry message, only those of the message type are set
+type Payload struct {
+	By          *int64  `json:"by,omitempty"`
+	LaunchSpeed *int64  `json:"launchSpeed,omitempty"`
+	Mission     *string `json:"mission,omitempty"`
+	NewMission  *string `json:"newMission,omitempty"`
+	Reason      *string `json:"reason,omitempty"`
+	Type        *string `json:"type,omitempty"`
+}
+
+// Message - telemetry message as accepted by POST /messages
+type Message struct {
+	Metadata Metadata `json:"metadata"`
+	Message  Payload  `json:"message"`
+}
+
+// NewLaunchedMessage builds a RocketLaunched message, the mission name is normalized.
+func NewLaunchedMessage(channel uuid.UUID, rocketType string, speed int64, mission string, n int64, t time.Time) (Message, error) {
+	typ, err := rocket.NewRocketType(rocketType)
+	if err != nil {
+		return Message{}, err
+	}
+	s, err := rocket.NewSpeed(speed)
+	if err != nil {
+		return Message{}, err
+	}
+	m, err := rocket.NewMission(mission)
+	if err != nil {
+		return Message{}, err
+	}
+	return build(channel, RocketLaunched, n, t, Payload{
+		Type:        ptr(string(typ)),
+		LaunchSpeed: ptr(int64(s)),
+		Mission:     ptr(string(m)),
+	})
+}
+
+// NewSpeedIncreasedMessage builds a RocketSpeedIncreased message.
+func NewSpeedIncreasedMessage(channel uuid.UUID, by int64, n int64, t time.Time) (Message, error) {
+	return newSpeedChange(channel, RocketSpeedIncreased, by, n, t)
+}
+
+// NewSpeedDecreasedMessage builds a RocketSpeedDecreased message.
+func NewSpeedDecreasedMessage(channel uuid.UUID, by int64, n int64, t time.Time) (Message, error) {
+	return newSpeedChange(channel, RocketSpeedDecreased, by, n, t)
+}
+
+func newSpeedChange(channel uuid.UUID, typ MessageType, by int64, n int64, t time.Time) (Message, error) {
+	s, err := rocket.NewSpeed(by)
+	if err != nil {
+		return Message{}, err
+	}
+	return build(channel, typ, n, t, Payload{By: ptr(int64(s))})
+}
+
+// NewExplodedMessage builds a RocketExploded message.
+func NewExplodedMessage(channel uuid.UUID, reason string, n int64, t time.Time) (Message, error) {
+	return build(channel, RocketExploded, n, t, Payload{Reason: ptr(reason)})
+}
+
+// NewMissionChangedMessage builds a RocketMissionChanged message, the mission name is normalized.
+func NewMissionChangedMessage(channel uuid.UUID, newMission string, n int64, t time.Time) (Message, error) {
+	m, err := rocket.NewMission(newMission)
+	if err != nil {
+		return Message{}, err
+	}
+	return build(channel, RocketMissionChanged, n, t, Payload{NewMission: ptr(string(m))})
+}
+
+// NewHeartbeatMessage builds a RocketHeartbeat message, keeping the channel alive without changing the state,
+// e.g. while the rocket is coasting.
+func NewHeartbeatMessage(channel uuid.UUID, n int64, t time.Time) (Message, error) {
+	return build(channel, RocketHeartbeat, n, t, Payload{})
+}
+
+// build validates the metadata and assembles the message
+func build(channel uuid.UUID, typ MessageType, n int64, t time.Time, payload Payload) (Message, error) {
+	if channel == uuid.Nil {
+		return Message{}, fmt.Errorf("%w: channel is required", rocket.ErrInvalidMessage)
+	}
+	if n <= 0 {
+		return Message{}, fmt.Errorf("%w: message number %d is not positive", rocket.ErrInvalidMessage, n)
+	}
+	if t.IsZero() {
+		return Message{}, fmt.Errorf("%w: message time is required", rocket.ErrInvalidMessage)
+	}
+	return Message{
+		Metadata: Metadata{
+			Channel:       channel,
+			MessageNumber: n,
+			MessageTime:   t,
+			MessageType:   typ,
+		},
+		Message: payload,
+	}, nil
+}
+
+// Marshal encodes the message as the JSON body of POST /messages.
+func (m Message) Marshal() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("can't marshal telemetry message: %w", err)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes a JSON body of POST /messages.
+func Unmarshal(data []byte) (Message, error) {
+	var m Message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Message{}, fmt.Errorf("can't unmarshal telemetry message: %w", err)
+	}
+	return m, nil
+}
+
+// Sign signs the marshalled body with the private key of the producer, whose public key the service verifies
+// it with. The result is the value of SignatureHeader.
+func Sign(body []byte, key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, body))
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,70 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"reflect"
+	"rockets/internal/http/gen"
+	"rockets/internal/rocket"
+	"testing"
+	"time"
+)
+
+func TestNewLaunchedMessage(t *testing.T) {
+	channel := uuid.New()
+	at := time.Date(2022, 2, 2, 19, 39, 5, 0, time.UTC)
+
+	msg, err := NewLaunchedMessage(channel, "Falcon-9", 500, " artemis ", 1, at)
+	if err != nil {
+		t.Fatalf("NewLaunchedMessage failed: %v", err)
+	}
+	b, err := msg.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	// the encoded message must be accepted by the API
+	var api gen.TelemetryMessage
+	if err := json.Unmarshal(b, &api); err != nil {
+		t.Fatalf("Can't decode as API message: %v", err)
+	}
+	if api.Metadata.Channel != channel || api.Metadata.MessageType != gen.RocketLaunched || api.Metadata.MessageNumber != 1 {
+		t.Errorf("Unexpected metadata: %+v", api.Metadata)
+	}
+	if *api.Message.Type != "Falcon-9" || *api.Message.LaunchSpeed != 500 || *api.Message.Mission != "ARTEMIS" {
+		t.Errorf("Unexpected payload: %s", b)
+	}
+	if api.Message.By != nil || api.Message.NewMission != nil || api.Message.Reason != nil {
+		t.Errorf("Expected only launch fields to be set, got %s", b)
+	}
+
+	decoded, err := Unmarshal(b)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, msg) {
+		t.Errorf("Expected round trip to keep the message.\nExpected: %+v\nGot: %+v", msg, decoded)
+	}
+}
+
+func TestConstructors_Invalid(t *testing.T) {
+	channel := uuid.New()
+	at := time.Now()
+
+	cases := map[string]func() (Message, error){
+		"negative speed":   func() (Message, error) { return NewLaunchedMessage(channel, "Falcon-9", -1, "ARTEMIS", 1, at) },
+		"empty type":       func() (Message, error) { return NewLaunchedMessage(channel, "", 500, "ARTEMIS", 1, at) },
+		"invalid mission":  func() (Message, error) { return NewMissionChangedMessage(channel, "MARS!", 2, at) },
+		"huge delta":       func() (Message, error) { return NewSpeedIncreasedMessage(channel, rocket.MaxSpeed+1, 2, at) },
+		"nil channel":      func() (Message, error) { return NewSpeedDecreasedMessage(uuid.Nil, 10, 2, at) },
+		"zero number":      func() (Message, error) { return NewExplodedMessage(channel, "BOOM", 0, at) },
+		"zero time":        func() (Message, error) { return NewExplodedMessage(channel, "BOOM", 3, time.Time{}) },
+		"heartbeat number": func() (Message, error) { return NewHeartbeatMessage(channel, -1, at) },
+	}
+	for name, construct := range cases {
+		if _, err := construct(); !errors.Is(err, rocket.ErrInvalidMessage) {
+			t.Errorf("%s: expected ErrInvalidMessage, got %v", name, err)
+		}
+	}
+}
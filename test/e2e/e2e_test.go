@@ -0,0 +1,432 @@
+// Package e2e is a black-box test suite of the HTTP API. It starts the server in-process on a random port
+// and talks to it only through real HTTP, serving as an executable spec of the API.
+package e2e
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"io"
+	"net"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"rockets/internal/auth"
+	"rockets/internal/http"
+	"rockets/internal/metrics"
+	"rockets/internal/netacl"
+	"rockets/internal/report"
+	"rockets/internal/rocket"
+	"rockets/internal/usage"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const channel = "193270a9-c9cf-404a-8f83-838e71d9ae67"
+
+// server - in-process instance of the service
+type server struct {
+	url    string
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// start runs the service wired like cmd/main.go with in-memory stores on a random port
+func start(t *testing.T) *server {
+	t.Helper()
+	return startWith(t, http.Listeners{Addrs: []string{"127.0.0.1:0"}}, nil)
+}
+
+// startWith runs the service on the listeners with the access control, the url is of the first listener
+func startWith(t *testing.T, cfg http.Listeners, acl *netacl.ACL) *server {
+	t.Helper()
+	logger := zap.NewNop()
+	history := rocket.NewInMemoryHistoryStore()
+	feed := report.NewFeed(50)
+	svc := rocket.NewRocketService(rocket.NewInMemoryRocketStore(logger), logger)
+	svc.UseHistory(history)
+	svc.AddListener(feed)
+
+	e := http.NewEcho(logger, metrics.NewRegistry(), nil)
+	_, e = http.NewServer(&http.ServerOpts{
+		Echo:     e,
+		Logger:   logger,
+		Rocket:   svc,
+		Missions: report.NewMissionReporter(svc, history),
+		Feed:     feed,
+		Keys:     auth.NewKeys(nil),
+		Usage:    usage.NewMeter(usage.Quota{}),
+		ACL:      acl,
+
+		MaxBodyBytes: 64 << 10,
+	})
+	listeners, err := http.Listen(cfg)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	for _, l := range listeners {
+		g.Go(http.ServeEchoServer(e, l, logger))
+	}
+	g.Go(http.ShutDownEchoServer(ctx, e, logger))
+	s := &server{url: "http://" + listeners[0].Addr().String(), cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		s.done <- g.Wait()
+		close(s.done)
+	}()
+	t.Cleanup(func() {
+		s.stop()
+		_ = s.wait()
+	})
+	return s
+}
+
+// stop starts a graceful shutdown of the server
+func (s *server) stop() {
+	s.cancel()
+}
+
+// wait returns the error the server stopped with
+func (s *server) wait() error {
+	select {
+	case err, ok := <-s.done:
+		if !ok {
+			return nil
+		}
+		return err
+	case <-time.After(15 * time.Second):
+		return fmt.Errorf("server did not shut down")
+	}
+}
+
+func (s *server) do(t *testing.T, method, path, body string, out any) int {
+	t.Helper()
+	req, err := nethttp.NewRequest(method, s.url+path, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Can't create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Can't decode response of %s %s: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func message(number int, messageType, payload string) string {
+	return fmt.Sprintf(`{"metadata":{"channel":"%s","messageNumber":%d,"messageTime":"2022-02-02T19:%02d:05Z","messageType":"%s"},"message":%s}`,
+		channel, number, 39+number, messageType, payload)
+}
+
+func TestAPI_IngestListGet(t *testing.T) {
+	s := start(t)
+
+	messages := []struct {
+		body string
+		code int
+	}{
+		{message(1, "RocketLaunched", `{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`), nethttp.StatusAccepted},
+		{message(2, "RocketSpeedIncreased", `{"by":3000}`), nethttp.StatusAccepted},
+		{message(4, "RocketMissionChanged", `{"newMission":"shuttle mir"}`), nethttp.StatusAccepted},
+		// out of order, ignored
+		{message(3, "RocketSpeedDecreased", `{"by":2500}`), nethttp.StatusConflict},
+	}
+	for _, m := range messages {
+		if code := s.do(t, "POST", "/messages", m.body, nil); code != m.code {
+			t.Fatalf("Expected %d for %s, got %d", m.code, m.body, code)
+		}
+	}
+
+	var rockets []map[string]any
+	if code := s.do(t, "GET", "/v1/rockets?sortBy=speed&sortOrder=desc", "", &rockets); code != nethttp.StatusOK {
+		t.Fatalf("Expected 200 listing rockets, got %d", code)
+	}
+	if len(rockets) != 1 || rockets[0]["id"] != channel {
+		t.Fatalf("Expected the launched rocket to be listed, got %v", rockets)
+	}
+
+	var state map[string]any
+	if code := s.do(t, "GET", "/v1/rockets/"+channel, "", &state); code != nethttp.StatusOK {
+		t.Fatalf("Expected 200 getting the rocket, got %d", code)
+	}
+	expected := map[string]any{
+		"id":                         channel,
+		"type":                       "Falcon-9",
+		"currentSpeed":               float64(3500),
+		"mission":                    "SHUTTLE MIR",
+		"status":                     "LAUNCHED",
+		"lastProcessedMessageNumber": float64(4),
+	}
+	for k, v := range expected {
+		if state[k] != v {
+			t.Errorf("Expected %s to be %v, got %v", k, v, state[k])
+		}
+	}
+
+	if code := s.do(t, "GET", "/v1/missions/shuttle%20mir/report", "", nil); code != nethttp.StatusOK {
+		t.Errorf("Expected 200 for the mission report, got %d", code)
+	}
+}
+
+func TestAPI_Errors(t *testing.T) {
+	s := start(t)
+
+	var errResp map[string]any
+	if code := s.do(t, "GET", "/v1/rockets/00000000-0000-0000-0000-000000000001", "", &errResp); code != nethttp.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown rocket, got %d", code)
+	}
+	if code := s.do(t, "POST", "/messages", `{"metadata":{}}`, &errResp); code != nethttp.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid message, got %d", code)
+	}
+	if errResp["code"] == nil || errResp["message"] == nil {
+		t.Errorf("Expected an error response with code and message, got %v", errResp)
+	}
+}
+
+func TestAPI_Version(t *testing.T) {
+	s := start(t)
+
+	resp, err := nethttp.Get(s.url + "/v1/version")
+	if err != nil {
+		t.Fatalf("GET /v1/version failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var info map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatalf("Can't decode the version: %v", err)
+	}
+	if resp.StatusCode != nethttp.StatusOK || info["version"] == "" || info["goVersion"] != runtime.Version() {
+		t.Errorf("Expected the build of the instance, got %d %v", resp.StatusCode, info)
+	}
+	if got := resp.Header.Get("X-Rockets-Version"); got == "" || got != info["version"] {
+		t.Errorf("Expected the version %v in the response header, got %q", info["version"], got)
+	}
+}
+
+func TestAPI_DryRun(t *testing.T) {
+	s := start(t)
+
+	launch := message(1, "RocketLaunched", `{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`)
+	var result map[string]any
+	if code := s.do(t, "POST", "/messages?dryRun=true", launch, &result); code != nethttp.StatusOK {
+		t.Fatalf("Expected 200 for a dry run, got %d", code)
+	}
+	if result["outcome"] != "applied" || result["current"] != nil || result["next"].(map[string]any)["currentSpeed"] != float64(500) {
+		t.Errorf("Expected the launch to be applied to a new rocket, got %v", result)
+	}
+	if code := s.do(t, "GET", "/v1/rockets/"+channel, "", nil); code != nethttp.StatusNotFound {
+		t.Errorf("Expected the dry run not to create the rocket, got %d", code)
+	}
+
+	if code := s.do(t, "POST", "/messages", launch, nil); code != nethttp.StatusAccepted {
+		t.Fatalf("Expected 202 for the launch, got %d", code)
+	}
+	speedUp := message(2, "RocketSpeedIncreased", `{"by":300}`)
+	if code := s.do(t, "POST", "/messages?dryRun=true", speedUp, &result); code != nethttp.StatusOK {
+		t.Fatalf("Expected 200 for a dry run, got %d", code)
+	}
+	changes := result["changes"].([]any)
+	speed := changes[0].(map[string]any)
+	if len(changes) != 3 || speed["field"] != "currentSpeed" || speed["from"] != float64(500) || speed["to"] != float64(800) {
+		t.Errorf("Expected the speed, time and number to change, got %v", changes)
+	}
+	if code := s.do(t, "POST", "/messages?dryRun=true", launch, &result); code != nethttp.StatusOK || result["outcome"] != "duplicate" {
+		t.Errorf("Expected a duplicate, got %d %v", code, result)
+	}
+	if code := s.do(t, "POST", "/messages?dryRun=true", message(3, "RocketSpeedIncreased", `{}`), nil); code != nethttp.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid message, got %d", code)
+	}
+
+	var state map[string]any
+	s.do(t, "GET", "/v1/rockets/"+channel, "", &state)
+	if state["currentSpeed"] != float64(500) || state["lastProcessedMessageNumber"] != float64(1) {
+		t.Errorf("Expected the dry runs not to change the state, got %v", state)
+	}
+}
+
+func TestAPI_DuplicateMessage(t *testing.T) {
+	s := start(t)
+
+	launch := message(1, "RocketLaunched", `{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`)
+	var result map[string]any
+	if code := s.do(t, "POST", "/messages", launch, &result); code != nethttp.StatusAccepted || result["disposition"] != "applied" || result["version"] != float64(1) {
+		t.Fatalf("Expected the launch to be applied, got %d %v", code, result)
+	}
+
+	var errResp map[string]any
+	if code := s.do(t, "POST", "/messages", launch, &errResp); code != nethttp.StatusConflict || errResp["code"] != "duplicate_message" {
+		t.Errorf("Expected 409 duplicate_message for a duplicate, got %d %v", code, errResp)
+	}
+
+	req, _ := nethttp.NewRequest("POST", s.url+"/messages", bytes.NewBufferString(launch))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Prefer", "handling=lenient")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /messages failed: %v", err)
+	}
+	defer resp.Body.Close()
+	result = nil
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != nethttp.StatusAccepted || result["disposition"] != "duplicate" {
+		t.Errorf("Expected 202 with the duplicate disposition with lenient handling, got %d %v", resp.StatusCode, result)
+	}
+}
+
+func TestAPI_CompressedMessage(t *testing.T) {
+	s := start(t)
+	post := func(encoding string, body []byte) (int, map[string]any) {
+		t.Helper()
+		req, _ := nethttp.NewRequest("POST", s.url+"/messages", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", encoding)
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /messages failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+	compress := func(w io.WriteCloser, payload []byte) {
+		_, _ = w.Write(payload)
+		_ = w.Close()
+	}
+
+	var gz, zl bytes.Buffer
+	compress(gzip.NewWriter(&gz), []byte(message(1, "RocketLaunched", `{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`)))
+	compress(zlib.NewWriter(&zl), []byte(message(2, "RocketSpeedIncreased", `{"by":100}`)))
+	if code, out := post("gzip", gz.Bytes()); code != nethttp.StatusAccepted {
+		t.Errorf("Expected a gzip message to be accepted, got %d %v", code, out)
+	}
+	if code, out := post("deflate", zl.Bytes()); code != nethttp.StatusAccepted {
+		t.Errorf("Expected a deflate message to be accepted, got %d %v", code, out)
+	}
+
+	// a few KiB expanding into 10 MiB
+	var bomb bytes.Buffer
+	compress(gzip.NewWriter(&bomb), bytes.Repeat([]byte(" "), 10<<20))
+	if code, out := post("gzip", bomb.Bytes()); code != nethttp.StatusRequestEntityTooLarge || out["code"] != "payload_too_large" {
+		t.Errorf("Expected 413 payload_too_large for a zip bomb, got %d %v", code, out)
+	}
+	if code, out := post("br", []byte("{}")); code != nethttp.StatusUnsupportedMediaType || out["code"] != "unsupported_encoding" {
+		t.Errorf("Expected 415 unsupported_encoding, got %d %v", code, out)
+	}
+}
+
+func TestAPI_Shutdown(t *testing.T) {
+	s := start(t)
+	if code := s.do(t, "POST", "/messages", message(1, "RocketLaunched", `{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`), nil); code != nethttp.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", code)
+	}
+
+	s.stop()
+	if err := s.wait(); err != nil {
+		t.Fatalf("Expected graceful shutdown, got %v", err)
+	}
+	if _, err := nethttp.Get(s.url + "/ready"); err == nil {
+		t.Errorf("Expected the server to stop accepting connections after shutdown")
+	}
+}
+
+func TestAPI_ReadAfterWrite(t *testing.T) {
+	s := start(t)
+	get := func(prefer string) *nethttp.Response {
+		t.Helper()
+		req, _ := nethttp.NewRequest("GET", s.url+"/v1/rockets/"+channel, nil)
+		req.Header.Set("Prefer", prefer)
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	s.do(t, "POST", "/messages", message(1, "RocketLaunched", `{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`), nil)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		if resp, err := nethttp.Post(s.url+"/messages", "application/json", bytes.NewBufferString(message(2, "RocketSpeedIncreased", `{"by":3000}`))); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// waits for the message sent later
+	if resp := get("read-after-write=2, wait=5"); resp.StatusCode != nethttp.StatusOK || resp.Header.Get("Preference-Applied") != "read-after-write" {
+		t.Errorf("Expected the preference to be applied, got %d %v", resp.StatusCode, resp.Header)
+	}
+	// never sent, the current state is served without waiting
+	if resp := get("read-after-write=3; foo=bar, wait=0"); resp.StatusCode != nethttp.StatusOK || resp.Header.Get("Preference-Applied") != "" {
+		t.Errorf("Expected the preference not to be applied, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := get("read-after-write=abc"); resp.StatusCode != nethttp.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid message number, got %d", resp.StatusCode)
+	}
+
+	// the number only refers to the messages of the rocket's own channel, a later message of another
+	// channel doesn't satisfy it
+	other := strings.Replace(message(3, "RocketLaunched", `{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`),
+		channel, "5d4c3b2a-1f0e-4d9c-8b7a-695847362514", 1)
+	s.do(t, "POST", "/messages", other, nil)
+	if resp := get("read-after-write=3, wait=0"); resp.StatusCode != nethttp.StatusOK || resp.Header.Get("Preference-Applied") != "" {
+		t.Errorf("Expected a message of another channel not to apply the preference, got %d %v", resp.StatusCode, resp.Header)
+	}
+}
+
+func TestAPI_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "rockets.sock")
+	// TCP peers are not allowed to ingest, peers on the socket have no address and are let through
+	acl, err := netacl.New(map[netacl.Group][]string{netacl.GroupIngest: {"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatalf("netacl.New failed: %v", err)
+	}
+	s := startWith(t, http.Listeners{Addrs: []string{"127.0.0.1:0"}, UnixSocket: socket, UnixSocketMode: 0o600}, acl)
+
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("Expected the socket with 0600 permissions, got %v, %v", info, err)
+	}
+	local := &nethttp.Client{Transport: &nethttp.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := local.Post("http://rockets/messages", "application/json", bytes.NewBufferString(message(1, "RocketLaunched", `{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}`)))
+	if err != nil {
+		t.Fatalf("POST over the socket failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusAccepted {
+		t.Errorf("Expected 202 over the socket, got %d", resp.StatusCode)
+	}
+	if code := s.do(t, "POST", "/messages", message(2, "RocketSpeedIncreased", `{"by":3000}`), nil); code != nethttp.StatusForbidden {
+		t.Errorf("Expected 403 over TCP, got %d", code)
+	}
+	if code := s.do(t, "GET", "/v1/rockets/"+channel, "", nil); code != nethttp.StatusOK {
+		t.Errorf("Expected the rocket ingested over the socket to be served over TCP, got %d", code)
+	}
+
+	s.stop()
+	if err := s.wait(); err != nil {
+		t.Fatalf("Expected graceful shutdown, got %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket file to be removed on shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+//go:build integration
+
+// Package integration runs the built binary against real backends. It is excluded from the regular test
+// run, use `go test -tags integration ./test/integration`.
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"rockets/internal/kube"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// binary builds the service binary into the test's temporary directory, with the extra build flags
+func binary(t *testing.T, flags ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rockets")
+	cmd := exec.Command("go", append(append([]string{"build", "-o", path}, flags...), "../../cmd")...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Can't build binary: %v\n%s", err, out)
+	}
+	return path
+}
+
+// freePort returns a TCP port nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Can't find a free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// start runs the binary with the environment and waits until it is ready, returning its base URL and its logs,
+// which may be read once it exited
+func start(t *testing.T, bin string, env ...string) (string, *exec.Cmd, *bytes.Buffer) {
+	t.Helper()
+	port := freePort(t)
+	cmd := exec.Command(bin, "-port", fmt.Sprint(port))
+	cmd.Env = append(os.Environ(), env...)
+	var logs bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &logs, &logs
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Can't start binary: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		if t.Failed() {
+			t.Logf("Server logs:\n%s", logs.String())
+		}
+	})
+
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if resp, err := http.Get(url + "/ready"); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return url, cmd, &logs
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("Server did not become ready")
+	return "", nil, nil
+}
+
+// terminate stops the binary with SIGTERM, like systemd and Kubernetes do, and waits for it to exit cleanly
+func terminate(t *testing.T, cmd *exec.Cmd) {
+	t.Helper()
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("Can't send SIGTERM: %v", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case err := <-exited:
+		if err != nil {
+			t.Fatalf("Expected a clean exit on SIGTERM, got %v", err)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatalf("Binary did not exit on SIGTERM")
+	}
+}
+
+func post(t *testing.T, url string, body string) {
+	t.Helper()
+	resp, err := http.Post(url+"/messages", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("POST /messages failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", resp.StatusCode)
+	}
+}
+
+func getRocket(t *testing.T, url, id string) map[string]any {
+	t.Helper()
+	resp, err := http.Get(url + "/v1/rockets/" + id)
+	if err != nil {
+		t.Fatalf("GET /v1/rockets/%s failed: %v", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	var state map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		t.Fatalf("Can't decode rocket state: %v", err)
+	}
+	return state
+}
+
+func TestBinary_FileStoreSurvivesRestart(t *testing.T) {
+	bin := binary(t)
+	env := []string{"ROCKETS_STORE_FILE=" + filepath.Join(t.TempDir(), "rockets.jsonl")}
+	const id = "193270a9-c9cf-404a-8f83-838e71d9ae67"
+
+	url, cmd, _ := start(t, bin, env...)
+	post(t, url, `{"metadata":{"channel":"`+id+`","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`)
+	post(t, url, `{"metadata":{"channel":"`+id+`","messageNumber":2,"messageTime":"2022-02-02T19:40:05Z","messageType":"RocketSpeedIncreased"},"message":{"by":3000}}`)
+	if state := getRocket(t, url, id); state["currentSpeed"] != float64(3500) {
+		t.Fatalf("Unexpected state before restart: %v", state)
+	}
+
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+
+	url, _, _ = start(t, bin, env...)
+	state := getRocket(t, url, id)
+	if state["currentSpeed"] != float64(3500) || state["mission"] != "ARTEMIS" {
+		t.Errorf("Unexpected state after restart: %v", state)
+	}
+}
+
+// fakeLease - API server of a cluster keeping the one lease the binary competes for
+type fakeLease struct {
+	mu    sync.Mutex
+	lease *kube.Lease
+}
+
+func (f *fakeLease) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var body kube.Lease
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	switch {
+	case r.Method == http.MethodGet && f.lease == nil:
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.lease)
+	case r.Method == http.MethodPost, r.Method == http.MethodPut:
+		f.lease = &body
+		_ = json.NewEncoder(w).Encode(f.lease)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeLease) holder() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lease == nil {
+		return ""
+	}
+	return f.lease.Spec.HolderIdentity
+}
+
+func TestBinary_SIGTERMReleasesLease(t *testing.T) {
+	api := &fakeLease{}
+	server := httptest.NewTLSServer(api)
+	defer server.Close()
+
+	// the service account of the pod, the binary is linked against it instead of the mounted one
+	account := t.TempDir()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	for name, content := range map[string][]byte{"token": []byte("token"), "ca.crt": ca, "namespace": []byte("rockets")} {
+		if err := os.WriteFile(filepath.Join(account, name), content, 0o600); err != nil {
+			t.Fatalf("Can't write the service account: %v", err)
+		}
+	}
+	bin := binary(t, "-ldflags", "-X rockets/internal/kube.serviceAccountDir="+account+"/")
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "https://"))
+	_, cmd, logs := start(t, bin, "KUBERNETES_SERVICE_HOST="+host, "KUBERNETES_SERVICE_PORT="+port, "ROCKETS_K8S_LEASE=jobs", "ROCKETS_K8S_LEASE_DURATION=3s")
+
+	identity, _ := os.Hostname()
+	deadline := time.Now().Add(10 * time.Second)
+	for api.holder() != identity && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if api.holder() != identity {
+		t.Fatalf("Expected the lease to be held by %s, got %q", identity, api.holder())
+	}
+
+	terminate(t, cmd)
+	if api.holder() != "" {
+		t.Errorf("Expected the lease to be released, held by %q", api.holder())
+	}
+	if !strings.Contains(logs.String(), "Service is down gracefully") {
+		t.Errorf("Expected the graceful shutdown to be logged, got:\n%s", logs.String())
+	}
+}
+
+func TestBinary_SIGTERMSyncsPendingCommit(t *testing.T) {
+	bin := binary(t)
+	// acknowledged before the write is synced, and a window long enough for none to be synced before the stop
+	env := []string{
+		"ROCKETS_STORE_FILE=" + filepath.Join(t.TempDir(), "rockets.jsonl"),
+		"ROCKETS_STORE_COMMIT_WINDOW=1m",
+		"ROCKETS_STORE_COMMIT_WAIT=false",
+	}
+	const id = "193270a9-c9cf-404a-8f83-838e71d9ae67"
+
+	url, cmd, _ := start(t, bin, env...)
+	post(t, url, `{"metadata":{"channel":"`+id+`","messageNumber":1,"messageTime":"2022-02-02T19:39:05Z","messageType":"RocketLaunched"},"message":{"type":"Falcon-9","launchSpeed":500,"mission":"ARTEMIS"}}`)
+	post(t, url, `{"metadata":{"channel":"`+id+`","messageNumber":2,"messageTime":"2022-02-02T19:40:05Z","messageType":"RocketSpeedIncreased"},"message":{"by":3000}}`)
+	terminate(t, cmd)
+
+	url, _, _ = start(t, bin, env...)
+	state := getRocket(t, url, id)
+	if state["currentSpeed"] != float64(3500) || state["lastProcessedMessageNumber"] != float64(2) {
+		t.Errorf("Expected the acknowledged messages to survive the restart, got %v", state)
+	}
+}
+
+func TestBinary_SIGTERMNotifiesSystemd(t *testing.T) {
+	bin := binary(t)
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Can't listen on the notify socket: %v", err)
+	}
+	defer conn.Close()
+	states := make(chan string, 16)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				close(states)
+				return
+			}
+			states <- string(buf[:n])
+		}
+	}()
+
+	_, cmd, _ := start(t, bin, "NOTIFY_SOCKET="+socket)
+	terminate(t, cmd)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	var received []string
+	for state := range states {
+		received = append(received, state)
+	}
+	if !slices.Equal(received, []string{"READY=1", "STOPPING=1"}) {
+		t.Errorf("Expected READY=1 and STOPPING=1, got %v", received)
+	}
+}
+
+func TestBinary_SIGTERMRemovesUnixSocket(t *testing.T) {
+	bin := binary(t)
+	socket := filepath.Join(t.TempDir(), "rockets.sock")
+	_, cmd, _ := start(t, bin, "ROCKETS_LISTEN_UNIX_SOCKET="+socket)
+	if _, err := os.Stat(socket); err != nil {
+		t.Fatalf("Expected the socket file, got %v", err)
+	}
+
+	terminate(t, cmd)
+	if _, err := os.Stat(socket); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the socket file to be removed, got %v", err)
+	}
+}